@@ -71,6 +71,10 @@ type Orderer interface {
 	// ConsensusType returns the configured consensus type
 	ConsensusType() string
 
+	// ConsensusState returns the state of the channel, which only orders config
+	// updates while in maintenance
+	ConsensusState() ab.ConsensusType_State
+
 	// BatchSize returns the maximum number of messages to include in a block
 	BatchSize() *ab.BatchSize
 
@@ -85,6 +89,14 @@ type Orderer interface {
 	// used for ordering
 	KafkaBrokers() []string
 
+	// BatchCutting returns the strategy used to cut the batches of the channel,
+	// and the bounds of the adaptive strategy
+	BatchCutting() *ab.BatchCutting
+
+	// RateLimits returns the limits on the rate of the messages broadcast on the
+	// channel by the creators of each MSP
+	RateLimits() *ab.RateLimits
+
 	// Organizations returns the organizations for the ordering service
 	Organizations() map[string]Org
 }
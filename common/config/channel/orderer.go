@@ -48,6 +48,12 @@ const (
 
 	// KafkaBrokersKey is the cb.ConfigItem type key name for the KafkaBrokers message
 	KafkaBrokersKey = "KafkaBrokers"
+
+	// RateLimitsKey is the cb.ConfigItem type key name for the RateLimits message
+	RateLimitsKey = "RateLimits"
+
+	// BatchCuttingKey is the cb.ConfigItem type key name for the BatchCutting message
+	BatchCuttingKey = "BatchCutting"
 )
 
 // OrdererProtos is used as the source of the OrdererConfig
@@ -57,6 +63,8 @@ type OrdererProtos struct {
 	BatchTimeout        *ab.BatchTimeout
 	KafkaBrokers        *ab.KafkaBrokers
 	ChannelRestrictions *ab.ChannelRestrictions
+	RateLimits          *ab.RateLimits
+	BatchCutting        *ab.BatchCutting
 }
 
 // Config is stores the orderer component configuration
@@ -120,6 +128,12 @@ func (oc *OrdererConfig) ConsensusType() string {
 	return oc.protos.ConsensusType.Type
 }
 
+// ConsensusState returns the state of the channel, which only orders config
+// updates while in maintenance
+func (oc *OrdererConfig) ConsensusState() ab.ConsensusType_State {
+	return oc.protos.ConsensusType.State
+}
+
 // BatchSize returns the maximum number of messages to include in a block
 func (oc *OrdererConfig) BatchSize() *ab.BatchSize {
 	return oc.protos.BatchSize
@@ -130,6 +144,12 @@ func (oc *OrdererConfig) BatchTimeout() time.Duration {
 	return oc.batchTimeout
 }
 
+// BatchCutting returns the strategy used to cut the batches of the channel,
+// and the bounds of the adaptive strategy
+func (oc *OrdererConfig) BatchCutting() *ab.BatchCutting {
+	return oc.protos.BatchCutting
+}
+
 // KafkaBrokers returns the addresses (IP:port notation) of a set of "bootstrap"
 // Kafka brokers, i.e. this is not necessarily the entire set of Kafka brokers
 // used for ordering
@@ -142,6 +162,12 @@ func (oc *OrdererConfig) MaxChannelsCount() uint64 {
 	return oc.protos.ChannelRestrictions.MaxCount
 }
 
+// RateLimits returns the limits on the rate of the messages broadcast on the
+// channel by the creators of each MSP
+func (oc *OrdererConfig) RateLimits() *ab.RateLimits {
+	return oc.protos.RateLimits
+}
+
 // Organizations returns a map of the orgs in the channel
 func (oc *OrdererConfig) Organizations() map[string]Org {
 	return oc.orgs
@@ -152,7 +178,9 @@ func (oc *OrdererConfig) Validate(tx interface{}, groups map[string]config.Value
 		oc.validateConsensusType,
 		oc.validateBatchSize,
 		oc.validateBatchTimeout,
+		oc.validateBatchCutting,
 		oc.validateKafkaBrokers,
+		oc.validateRateLimits,
 	} {
 		if err := validator(); err != nil {
 			return err
@@ -172,9 +200,15 @@ func (oc *OrdererConfig) Validate(tx interface{}, groups map[string]config.Value
 }
 
 func (oc *OrdererConfig) validateConsensusType() error {
-	if oc.ordererGroup.OrdererConfig != nil && oc.ordererGroup.ConsensusType() != oc.protos.ConsensusType.Type {
+	if _, ok := ab.ConsensusType_State_name[int32(oc.protos.ConsensusType.State)]; !ok {
+		return fmt.Errorf("Attempted to set the consensus state to an unknown value: %d", oc.protos.ConsensusType.State)
+	}
+	if oc.ordererGroup.OrdererConfig == nil || oc.ordererGroup.ConsensusType() == oc.protos.ConsensusType.Type {
 		// The first config we accept the consensus type regardless
-		return fmt.Errorf("Attempted to change the consensus type from %s to %s after init", oc.ordererGroup.ConsensusType(), oc.protos.ConsensusType.Type)
+		return nil
+	}
+	if oc.ordererGroup.ConsensusState() != ab.ConsensusType_STATE_MAINTENANCE || oc.protos.ConsensusType.State != ab.ConsensusType_STATE_MAINTENANCE {
+		return fmt.Errorf("Attempted to change the consensus type from %s to %s outside of maintenance", oc.ordererGroup.ConsensusType(), oc.protos.ConsensusType.Type)
 	}
 	return nil
 }
@@ -207,6 +241,37 @@ func (oc *OrdererConfig) validateBatchTimeout() error {
 	return nil
 }
 
+func (oc *OrdererConfig) validateBatchCutting() error {
+	batchCutting := oc.protos.BatchCutting
+	switch batchCutting.Strategy {
+	case ab.BatchCutting_FIXED:
+		return nil
+	case ab.BatchCutting_ADAPTIVE:
+	default:
+		return fmt.Errorf("Attempted to set the batch cutting strategy to an unknown value: %d", batchCutting.Strategy)
+	}
+
+	// The blocks cut by the adaptive strategy depend on the arrival times of the messages,
+	// which must be the same for every orderer cutting them: solo has a single orderer, and
+	// kafka takes them from the timestamps of the Kafka messages
+	switch oc.protos.ConsensusType.Type {
+	case "solo", "kafka":
+	default:
+		return fmt.Errorf("Attempted to set the adaptive batch cutting strategy with the %s consensus type, only solo and kafka support it", oc.protos.ConsensusType.Type)
+	}
+	if batchCutting.MinMessageCount == 0 || batchCutting.MinMessageCount > oc.protos.BatchSize.MaxMessageCount {
+		return fmt.Errorf("Attempted to set the batch cutting min message count to %d, not between 1 and the batch size max message count (%d)", batchCutting.MinMessageCount, oc.protos.BatchSize.MaxMessageCount)
+	}
+	minTimeout, err := time.ParseDuration(batchCutting.MinTimeout)
+	if err != nil {
+		return fmt.Errorf("Attempted to set the batch cutting min timeout to a invalid value: %s", err)
+	}
+	if minTimeout <= 0 || minTimeout > oc.batchTimeout {
+		return fmt.Errorf("Attempted to set the batch cutting min timeout to %s, not between 0 and the batch timeout (%s)", minTimeout, oc.batchTimeout)
+	}
+	return nil
+}
+
 func (oc *OrdererConfig) validateKafkaBrokers() error {
 	for _, broker := range oc.protos.KafkaBrokers.Brokers {
 		if !brokerEntrySeemsValid(broker) {
@@ -216,6 +281,20 @@ func (oc *OrdererConfig) validateKafkaBrokers() error {
 	return nil
 }
 
+func (oc *OrdererConfig) validateRateLimits() error {
+	mspIDs := make(map[string]struct{})
+	for _, limit := range oc.protos.RateLimits.Limits {
+		if _, ok := mspIDs[limit.MspId]; ok {
+			return fmt.Errorf("Attempted to set more than one rate limit for MSP '%s'", limit.MspId)
+		}
+		mspIDs[limit.MspId] = struct{}{}
+		if limit.Rate > 0 && limit.Burst == 0 {
+			return fmt.Errorf("Attempted to set the rate limit burst for MSP '%s' to an invalid value: 0", limit.MspId)
+		}
+	}
+	return nil
+}
+
 // This does just a barebones sanity check.
 func brokerEntrySeemsValid(broker string) bool {
 	if !strings.Contains(broker, ":") {
@@ -18,6 +18,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	ab "github.com/hyperledger/fabric/protos/orderer"
 
@@ -44,6 +45,30 @@ func TestConsensusType(t *testing.T) {
 		protos:       &OrdererProtos{ConsensusType: &ab.ConsensusType{Type: "foo"}},
 	}
 	assert.Error(t, oc.validateConsensusType(), "Should have failed to change consensus type")
+
+	maintenance := ab.ConsensusType_STATE_MAINTENANCE
+	for _, testCase := range []struct {
+		name          string
+		current, next ab.ConsensusType_State
+		valid         bool
+	}{
+		{"In maintenance", maintenance, maintenance, true},
+		{"Entering maintenance", ab.ConsensusType_STATE_NORMAL, maintenance, false},
+		{"Leaving maintenance", maintenance, ab.ConsensusType_STATE_NORMAL, false},
+	} {
+		oc = &OrdererConfig{
+			ordererGroup: &OrdererGroup{OrdererConfig: &OrdererConfig{protos: &OrdererProtos{ConsensusType: &ab.ConsensusType{Type: "bar", State: testCase.current}}}},
+			protos:       &OrdererProtos{ConsensusType: &ab.ConsensusType{Type: "foo", State: testCase.next}},
+		}
+		if testCase.valid {
+			assert.NoError(t, oc.validateConsensusType(), testCase.name)
+		} else {
+			assert.Error(t, oc.validateConsensusType(), testCase.name)
+		}
+	}
+
+	oc = &OrdererConfig{ordererGroup: &OrdererGroup{}, protos: &OrdererProtos{ConsensusType: &ab.ConsensusType{Type: "foo", State: 2}}}
+	assert.Error(t, oc.validateConsensusType(), "Should have failed on an unknown consensus state")
 }
 
 func TestBatchSize(t *testing.T) {
@@ -83,3 +108,65 @@ func TestKafkaBrokers(t *testing.T) {
 	oc = &OrdererConfig{protos: &OrdererProtos{KafkaBrokers: &ab.KafkaBrokers{Brokers: []string{"127.0.0.1", "foo.bar", "127.0.0.1:-1", "localhost:65536", "foo.bar.:9092", ".127.0.0.1:9092", "-foo.bar:9092"}}}}
 	assert.Error(t, oc.validateKafkaBrokers(), "Invalid kafka brokers")
 }
+
+func TestRateLimits(t *testing.T) {
+	oc := &OrdererConfig{protos: &OrdererProtos{RateLimits: &ab.RateLimits{}}}
+	assert.NoError(t, oc.validateRateLimits(), "No rate limits")
+
+	oc = &OrdererConfig{protos: &OrdererProtos{RateLimits: &ab.RateLimits{Limits: []*ab.RateLimit{
+		{Rate: 100, Burst: 200},
+		{MspId: "SampleOrg", Rate: 10, Burst: 10},
+		{MspId: "OtherOrg"},
+	}}}}
+	assert.NoError(t, oc.validateRateLimits(), "Valid rate limits")
+
+	oc = &OrdererConfig{protos: &OrdererProtos{RateLimits: &ab.RateLimits{Limits: []*ab.RateLimit{{MspId: "SampleOrg", Rate: 10}}}}}
+	assert.Error(t, oc.validateRateLimits(), "Zero burst")
+
+	oc = &OrdererConfig{protos: &OrdererProtos{RateLimits: &ab.RateLimits{Limits: []*ab.RateLimit{
+		{MspId: "SampleOrg", Rate: 10, Burst: 10},
+		{MspId: "SampleOrg", Rate: 20, Burst: 20},
+	}}}}
+	assert.Error(t, oc.validateRateLimits(), "Duplicate MSP ID")
+}
+
+func TestBatchCutting(t *testing.T) {
+	newOrdererConfig := func(consensusType string, batchCutting *ab.BatchCutting) *OrdererConfig {
+		return &OrdererConfig{
+			protos: &OrdererProtos{
+				ConsensusType: &ab.ConsensusType{Type: consensusType},
+				BatchSize:     &ab.BatchSize{MaxMessageCount: 100},
+				BatchCutting:  batchCutting,
+			},
+			batchTimeout: time.Second,
+		}
+	}
+
+	assert.NoError(t, newOrdererConfig("kafka", &ab.BatchCutting{}).validateBatchCutting(), "Fixed strategy")
+	assert.NoError(t, newOrdererConfig("solo", &ab.BatchCutting{
+		Strategy:        ab.BatchCutting_ADAPTIVE,
+		MinMessageCount: 10,
+		MinTimeout:      "100ms",
+	}).validateBatchCutting(), "Valid adaptive strategy")
+	assert.NoError(t, newOrdererConfig("kafka", &ab.BatchCutting{
+		Strategy:        ab.BatchCutting_ADAPTIVE,
+		MinMessageCount: 10,
+		MinTimeout:      "100ms",
+	}).validateBatchCutting(), "Valid adaptive strategy with kafka")
+
+	for _, testCase := range []struct {
+		name          string
+		consensusType string
+		batchCutting  *ab.BatchCutting
+	}{
+		{"Unknown strategy", "solo", &ab.BatchCutting{Strategy: 2}},
+		{"BFTsmart", "bftsmart", &ab.BatchCutting{Strategy: ab.BatchCutting_ADAPTIVE, MinMessageCount: 10, MinTimeout: "100ms"}},
+		{"Zero min message count", "solo", &ab.BatchCutting{Strategy: ab.BatchCutting_ADAPTIVE, MinTimeout: "100ms"}},
+		{"Min message count over max", "solo", &ab.BatchCutting{Strategy: ab.BatchCutting_ADAPTIVE, MinMessageCount: 101, MinTimeout: "100ms"}},
+		{"Malformed min timeout", "solo", &ab.BatchCutting{Strategy: ab.BatchCutting_ADAPTIVE, MinMessageCount: 10, MinTimeout: "forever"}},
+		{"Zero min timeout", "solo", &ab.BatchCutting{Strategy: ab.BatchCutting_ADAPTIVE, MinMessageCount: 10, MinTimeout: "0s"}},
+		{"Min timeout over batch timeout", "solo", &ab.BatchCutting{Strategy: ab.BatchCutting_ADAPTIVE, MinMessageCount: 10, MinTimeout: "2s"}},
+	} {
+		assert.Error(t, newOrdererConfig(testCase.consensusType, testCase.batchCutting).validateBatchCutting(), testCase.name)
+	}
+}
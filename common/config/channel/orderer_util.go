@@ -51,6 +51,16 @@ func TemplateChannelRestrictions(maxChannels uint64) *cb.ConfigGroup {
 	return ordererConfigGroup(ChannelRestrictionsKey, utils.MarshalOrPanic(&ab.ChannelRestrictions{MaxCount: maxChannels}))
 }
 
+// TemplateRateLimits creates a config group with RateLimits specified
+func TemplateRateLimits(rateLimits *ab.RateLimits) *cb.ConfigGroup {
+	return ordererConfigGroup(RateLimitsKey, utils.MarshalOrPanic(rateLimits))
+}
+
+// TemplateBatchCutting creates a config group with BatchCutting specified
+func TemplateBatchCutting(batchCutting *ab.BatchCutting) *cb.ConfigGroup {
+	return ordererConfigGroup(BatchCuttingKey, utils.MarshalOrPanic(batchCutting))
+}
+
 // TemplateKafkaBrokers creates a headerless config item representing the kafka brokers
 func TemplateKafkaBrokers(brokers []string) *cb.ConfigGroup {
 	return ordererConfigGroup(KafkaBrokersKey, utils.MarshalOrPanic(&ab.KafkaBrokers{Brokers: brokers}))
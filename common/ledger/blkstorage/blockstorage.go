@@ -47,14 +47,23 @@ var (
 	ErrNotFoundInIndex = errors.New("Entry not found in index")
 	// ErrAttrNotIndexed is used to indicate that an attribute is not indexed
 	ErrAttrNotIndexed = errors.New("Attribute not indexed")
+	// ErrPruned is used to indicate that a block has been pruned from the block store
+	ErrPruned = errors.New("Block pruned")
 )
 
 // BlockStoreProvider provides an handle to a BlockStore
 type BlockStoreProvider interface {
 	CreateBlockStore(ledgerid string) (BlockStore, error)
 	OpenBlockStore(ledgerid string) (BlockStore, error)
+	// CreateBlockStoreFromSnapshot creates a block store which starts after the last block of a snapshot, as if
+	// the blocks up to it were pruned. The last block and the blocks listed in retain remain retrievable by number
+	// The IDs of the transactions of the snapshot, returned by txIDs unless nil, are recorded as those of pruned blocks
+	CreateBlockStoreFromSnapshot(ledgerid string, lastBlock *common.Block, retain []*common.Block, txIDs ledger.ResultsIterator) (BlockStore, error)
 	Exists(ledgerid string) (bool, error)
 	List() ([]string, error)
+	Remove(ledgerid string) error // Remove deletes a block store which is not open
+	// Rollback removes the blocks after blockNum from a block store which is not open
+	Rollback(ledgerid string, blockNum uint64) error
 	Close()
 }
 
@@ -71,5 +80,13 @@ type BlockStore interface {
 	RetrieveTxByBlockNumTranNum(blockNum uint64, tranNum uint64) (*common.Envelope, error)
 	RetrieveBlockByTxID(txID string) (*common.Block, error)
 	RetrieveTxValidationCodeByTxID(txID string) (peer.TxValidationCode, error)
+	// GetTxIDsIterator returns an iterator over the IDs, of type string, of the transactions of the blocks
+	// committed so far, including the pruned blocks. The iterator is unaffected by the blocks committed later
+	GetTxIDsIterator() (ledger.ResultsIterator, error)
+	// Prune removes the blocks below blockNum, a whole block file at a time, except
+	// the blocks listed in retain which remain retrievable by number. Unless
+	// archiveDir is empty, the removed block files are copied there first
+	Prune(blockNum uint64, retain []uint64, archiveDir string) error
+	GetFirstBlockNumber() (uint64, error) // number of the oldest block which was not pruned
 	Shutdown()
 }
@@ -51,6 +51,8 @@ type blockfileMgr struct {
 	cpInfoCond        *sync.Cond
 	currentFileWriter *blockfileWriter
 	bcInfo            atomic.Value
+	pruneInfo         atomic.Value
+	pruneLock         sync.Mutex
 }
 
 /*
@@ -122,6 +124,11 @@ func newBlockfileMgr(id string, conf *Conf, indexConfig *blkstorage.IndexConfig,
 	//Verify that the checkpoint stored in db is accurate with what is actually stored in block file system
 	// If not the same, sync the cpInfo and the file system
 	syncCPInfoFromFS(rootDir, cpInfo)
+	// Remove the block files an interrupted rollback left behind, which would
+	// otherwise be appended to once the manager moves to the next file
+	if err := removeBlockfilesAfter(rootDir, cpInfo.latestFileChunkSuffixNum); err != nil {
+		panic(fmt.Sprintf("Could not remove the block files after the current file: %s", err))
+	}
 	//Open a writer to the file identified by the number and truncate it to only contain the latest block
 	// that was completely saved (file system, index, cpinfo, etc)
 	currentFileWriter, err := newBlockfileWriter(deriveBlockfilePath(rootDir, cpInfo.latestFileChunkSuffixNum))
@@ -144,6 +151,14 @@ func newBlockfileMgr(id string, conf *Conf, indexConfig *blkstorage.IndexConfig,
 	// or announcing the occurrence of an event.
 	mgr.cpInfoCond = sync.NewCond(&sync.Mutex{})
 
+	// Load the prune info and remove the block files an interrupted prune left behind
+	pi, err := mgr.loadPruneInfo()
+	if err != nil {
+		panic(fmt.Sprintf("Could not get prune info from db: %s", err))
+	}
+	mgr.pruneInfo.Store(pi)
+	mgr.removePrunedFiles()
+
 	// Verify that the index stored in db is accurate with what is actually stored in block file system
 	// If not the same, sync the index and the file system
 	mgr.syncIndex()
@@ -441,6 +456,10 @@ func (mgr *blockfileMgr) retrieveBlockByNumber(blockNum uint64) (*common.Block,
 		blockNum = mgr.getBlockchainInfo().Height - 1
 	}
 
+	if blockNum < mgr.getPruneInfo().firstBlockNumber {
+		return mgr.retrieveRetainedBlock(blockNum)
+	}
+
 	loc, err := mgr.index.getBlockLocByBlockNum(blockNum)
 	if err != nil {
 		return nil, err
@@ -466,6 +485,13 @@ func (mgr *blockfileMgr) retrieveTxValidationCodeByTxID(txID string) (peer.TxVal
 
 func (mgr *blockfileMgr) retrieveBlockHeaderByNumber(blockNum uint64) (*common.BlockHeader, error) {
 	logger.Debugf("retrieveBlockHeaderByNumber() - blockNum = [%d]", blockNum)
+	if blockNum < mgr.getPruneInfo().firstBlockNumber {
+		block, err := mgr.retrieveRetainedBlock(blockNum)
+		if err != nil {
+			return nil, err
+		}
+		return block.Header, nil
+	}
 	loc, err := mgr.index.getBlockLocByBlockNum(blockNum)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,372 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fsblkstorage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	"github.com/hyperledger/fabric/common/ledger/util"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+const bootstrapTxIDsBatchSize = 1000
+
+var (
+	pruneInfoKey           = []byte("blkMgrPruneInfo")
+	retainedBlockKeyPrefix = []byte("retainedBlock/")
+)
+
+// pruneInfo records the oldest block file left by pruning, and the number of
+// its first block. The blocks below that number are gone, except the retained
+// ones, which are kept in the index db.
+type pruneInfo struct {
+	firstFileSuffixNum int
+	firstBlockNumber   uint64
+}
+
+func (i *pruneInfo) marshal() ([]byte, error) {
+	buffer := proto.NewBuffer([]byte{})
+	if err := buffer.EncodeVarint(uint64(i.firstFileSuffixNum)); err != nil {
+		return nil, err
+	}
+	if err := buffer.EncodeVarint(i.firstBlockNumber); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (i *pruneInfo) unmarshal(b []byte) error {
+	buffer := proto.NewBuffer(b)
+	val, err := buffer.DecodeVarint()
+	if err != nil {
+		return err
+	}
+	i.firstFileSuffixNum = int(val)
+	if i.firstBlockNumber, err = buffer.DecodeVarint(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (i *pruneInfo) String() string {
+	return fmt.Sprintf("firstFileSuffixNum=[%d], firstBlockNumber=[%d]", i.firstFileSuffixNum, i.firstBlockNumber)
+}
+
+func constructRetainedBlockKey(blockNum uint64) []byte {
+	return append(append([]byte{}, retainedBlockKeyPrefix...), util.EncodeOrderPreservingVarUint64(blockNum)...)
+}
+
+// loadPruneInfo returns the prune info stored in the db, or the one of a
+// ledger which was never pruned
+func (mgr *blockfileMgr) loadPruneInfo() (*pruneInfo, error) {
+	i := &pruneInfo{}
+	b, err := mgr.db.Get(pruneInfoKey)
+	if b == nil || err != nil {
+		return i, err
+	}
+	if err = i.unmarshal(b); err != nil {
+		return nil, err
+	}
+	logger.Debugf("loaded pruneInfo:%s", i)
+	return i, nil
+}
+
+func (mgr *blockfileMgr) getPruneInfo() *pruneInfo {
+	return mgr.pruneInfo.Load().(*pruneInfo)
+}
+
+// removePrunedFiles removes the block files which precede the first one, which
+// a crash may have left behind while pruning. Files are removed in ascending
+// order, so the leftovers are the files right below the first one.
+func (mgr *blockfileMgr) removePrunedFiles() {
+	for fileNum := mgr.getPruneInfo().firstFileSuffixNum - 1; fileNum >= 0; fileNum-- {
+		filePath := deriveBlockfilePath(mgr.rootDir, fileNum)
+		exists, _, err := util.FileExists(filePath)
+		if err != nil {
+			panic(fmt.Sprintf("Error in checking whether file [%s] exists: %s", filePath, err))
+		}
+		if !exists {
+			return
+		}
+		logger.Infof("Removing block file [%s] left over by pruning", filePath)
+		if err := os.Remove(filePath); err != nil {
+			panic(fmt.Sprintf("Could not remove pruned block file [%s]: %s", filePath, err))
+		}
+	}
+}
+
+// prune removes the block files whose blocks all precede blockNum. The blocks
+// listed in retain are copied to the db first, and the retained blocks which
+// are no longer listed are dropped. The last block is never pruned. If
+// archiveDir is set, the block files are copied there before being removed.
+func (mgr *blockfileMgr) prune(blockNum uint64, retain []uint64, archiveDir string) error {
+	mgr.pruneLock.Lock()
+	defer mgr.pruneLock.Unlock()
+
+	mgr.cpInfoCond.L.Lock()
+	cpInfo := mgr.cpInfo
+	mgr.cpInfoCond.L.Unlock()
+
+	current := mgr.getPruneInfo()
+	if cpInfo.isChainEmpty || blockNum <= current.firstBlockNumber {
+		return nil
+	}
+	if blockNum > cpInfo.lastBlockNumber {
+		blockNum = cpInfo.lastBlockNumber
+	}
+
+	loc, err := mgr.index.getBlockLocByBlockNum(blockNum)
+	if err != nil {
+		return fmt.Errorf("Could not locate block [%d]: %s", blockNum, err)
+	}
+	lastPrunedFileNum := loc.fileSuffixNum - 1
+	if lastPrunedFileNum < current.firstFileSuffixNum {
+		logger.Debugf("No block file entirely below block [%d] to prune", blockNum)
+		return nil
+	}
+
+	next := &pruneInfo{firstFileSuffixNum: loc.fileSuffixNum}
+	if next.firstBlockNumber, err = mgr.firstBlockNumberInFile(loc.fileSuffixNum); err != nil {
+		return err
+	}
+
+	retained := make(map[uint64]bool)
+	for _, num := range retain {
+		retained[num] = true
+	}
+
+	batch := leveldbhelper.NewUpdateBatch()
+	if err := mgr.dropRetainedBlocks(batch, retained); err != nil {
+		return err
+	}
+
+	stream, err := newBlockStream(mgr.rootDir, current.firstFileSuffixNum, 0, lastPrunedFileNum)
+	if err != nil {
+		return err
+	}
+	defer stream.close()
+	for {
+		blockBytes, _, err := stream.nextBlockBytesAndPlacementInfo()
+		if err != nil {
+			return err
+		}
+		if blockBytes == nil {
+			break
+		}
+		info, err := extractSerializedBlockInfo(blockBytes)
+		if err != nil {
+			return err
+		}
+		num := info.blockHeader.Number
+		if retained[num] {
+			logger.Debugf("Retaining block [%d]", num)
+			batch.Put(constructRetainedBlockKey(num), blockBytes)
+		}
+		err = mgr.index.pruneBlock(batch, &blockIdxInfo{
+			blockNum:  num,
+			blockHash: info.blockHeader.Hash(),
+			txOffsets: info.txOffsets,
+			metadata:  info.metadata,
+		}, func(flp *fileLocPointer) bool {
+			return flp.fileSuffixNum <= lastPrunedFileNum
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if archiveDir != "" {
+		if err := mgr.archiveBlockfiles(archiveDir, current.firstFileSuffixNum, lastPrunedFileNum); err != nil {
+			return err
+		}
+	}
+
+	b, err := next.marshal()
+	if err != nil {
+		return err
+	}
+	batch.Put(pruneInfoKey, b)
+	if err := mgr.db.WriteBatch(batch, true); err != nil {
+		return err
+	}
+	mgr.pruneInfo.Store(next)
+
+	for fileNum := current.firstFileSuffixNum; fileNum <= lastPrunedFileNum; fileNum++ {
+		if err := os.Remove(deriveBlockfilePath(mgr.rootDir, fileNum)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	logger.Infof("Pruned blocks [%d] to [%d], %s", current.firstBlockNumber, next.firstBlockNumber-1, next)
+	return nil
+}
+
+// archiveBlockfiles copies the block files in the given range to archiveDir.
+// The copies are synced before returning, so that the files are archived
+// before the prune takes effect. A file already archived by an interrupted
+// prune is overwritten.
+func (mgr *blockfileMgr) archiveBlockfiles(archiveDir string, firstFileNum, lastFileNum int) error {
+	if _, err := util.CreateDirIfMissing(archiveDir); err != nil {
+		return fmt.Errorf("Could not create archive dir [%s]: %s", archiveDir, err)
+	}
+	for fileNum := firstFileNum; fileNum <= lastFileNum; fileNum++ {
+		src := deriveBlockfilePath(mgr.rootDir, fileNum)
+		dst := filepath.Join(archiveDir, filepath.Base(src))
+		if err := copyFile(src, dst); err != nil {
+			return fmt.Errorf("Could not archive block file [%s]: %s", src, err)
+		}
+		logger.Debugf("Archived block file [%s] to [%s]", src, dst)
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// dropRetainedBlocks adds to the batch the removal of the retained blocks which
+// are no longer listed
+func (mgr *blockfileMgr) dropRetainedBlocks(batch *leveldbhelper.UpdateBatch, retained map[uint64]bool) error {
+	itr := mgr.db.GetIterator(retainedBlockKeyPrefix, constructRetainedBlockKey(mgr.getPruneInfo().firstBlockNumber))
+	defer itr.Release()
+	for itr.Next() {
+		key := itr.Key()
+		num, _ := util.DecodeOrderPreservingVarUint64(key[len(retainedBlockKeyPrefix):])
+		if !retained[num] {
+			logger.Debugf("Dropping retained block [%d]", num)
+			batch.Delete(key)
+		}
+	}
+	return itr.Error()
+}
+
+func (mgr *blockfileMgr) firstBlockNumberInFile(fileNum int) (uint64, error) {
+	stream, err := newBlockfileStream(mgr.rootDir, fileNum, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer stream.close()
+	blockBytes, err := stream.nextBlockBytes()
+	if err != nil {
+		return 0, err
+	}
+	if blockBytes == nil {
+		return 0, fmt.Errorf("Block file [%d] is empty", fileNum)
+	}
+	info, err := extractSerializedBlockInfo(blockBytes)
+	if err != nil {
+		return 0, err
+	}
+	return info.blockHeader.Number, nil
+}
+
+// bootstrapFromSnapshot initializes the index db of a new block store so that
+// the store starts after the last block of a snapshot. The store looks as if
+// the blocks up to the last block were pruned, retaining the last block and
+// the given blocks. The transaction IDs of the snapshot, if any, are recorded
+// as pruned, with the last block as their block, the actual one being unknown.
+// They are written before the checkpoint info, which marks the store as
+// bootstrapped.
+func bootstrapFromSnapshot(db *leveldbhelper.DBHandle, lastBlock *common.Block, retain []*common.Block, txIDs ledger.ResultsIterator) error {
+	b, err := db.Get(blkMgrInfoKey)
+	if err != nil {
+		return err
+	}
+	if b != nil {
+		return fmt.Errorf("block store is not empty")
+	}
+	lastBlockNum := lastBlock.Header.Number
+	if txIDs != nil {
+		if err := bootstrapPrunedTxIDs(db, encodeBlockNum(lastBlockNum), txIDs); err != nil {
+			return err
+		}
+	}
+	batch := leveldbhelper.NewUpdateBatch()
+	for _, block := range append([]*common.Block{lastBlock}, retain...) {
+		if block.Header.Number > lastBlockNum {
+			return fmt.Errorf("Block [%d] to retain is after the last block [%d]", block.Header.Number, lastBlockNum)
+		}
+		blockBytes, _, err := serializeBlock(block)
+		if err != nil {
+			return err
+		}
+		batch.Put(constructRetainedBlockKey(block.Header.Number), blockBytes)
+	}
+	cpInfo := &checkpointInfo{isChainEmpty: false, lastBlockNumber: lastBlockNum}
+	if b, err = cpInfo.marshal(); err != nil {
+		return err
+	}
+	batch.Put(blkMgrInfoKey, b)
+	pi := &pruneInfo{firstFileSuffixNum: 0, firstBlockNumber: lastBlockNum + 1}
+	if b, err = pi.marshal(); err != nil {
+		return err
+	}
+	batch.Put(pruneInfoKey, b)
+	logger.Infof("Bootstrapping block store from snapshot at block [%d], %s", lastBlockNum, pi)
+	return db.WriteBatch(batch, true)
+}
+
+// bootstrapPrunedTxIDs records the given transaction IDs as those of the pruned
+// block whose number is encoded in blockNumBytes
+func bootstrapPrunedTxIDs(db *leveldbhelper.DBHandle, blockNumBytes []byte, txIDs ledger.ResultsIterator) error {
+	batch := leveldbhelper.NewUpdateBatch()
+	numTxIDs := 0
+	for {
+		res, err := txIDs.Next()
+		if err != nil {
+			return err
+		}
+		if res == nil {
+			break
+		}
+		batch.Put(constructPrunedTxIDKey(res.(string)), blockNumBytes)
+		if numTxIDs++; numTxIDs%bootstrapTxIDsBatchSize == 0 {
+			if err := db.WriteBatch(batch, false); err != nil {
+				return err
+			}
+			batch = leveldbhelper.NewUpdateBatch()
+		}
+	}
+	logger.Debugf("Recorded %d transaction ID(s) of the snapshot as pruned", numTxIDs)
+	return db.WriteBatch(batch, true)
+}
+
+// retrieveRetainedBlock returns a block below the first block, which is
+// retrievable only if it was retained
+func (mgr *blockfileMgr) retrieveRetainedBlock(blockNum uint64) (*common.Block, error) {
+	blockBytes, err := mgr.db.Get(constructRetainedBlockKey(blockNum))
+	if err != nil {
+		return nil, err
+	}
+	if blockBytes == nil {
+		return nil, blkstorage.ErrPruned
+	}
+	return deserializeBlock(blockBytes)
+}
@@ -0,0 +1,247 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fsblkstorage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/common/ledger/util"
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+// newPruneTestEnv returns an env whose block files hold about 75 blocks of the given ones
+func newPruneTestEnv(t *testing.T, blocks []*common.Block) *testEnv {
+	size := 0
+	for _, block := range blocks[:100] {
+		by, _, err := serializeBlock(block)
+		testutil.AssertNoError(t, err, "Error while serializing block")
+		size += len(by) + len(proto.EncodeVarint(uint64(len(by))))
+	}
+	return newTestEnv(t, NewConf(testPath(), int(0.75*float64(size))))
+}
+
+func TestBlockfileMgrPrune(t *testing.T) {
+	blocks := testutil.ConstructTestBlocks(t, 300)
+	env := newPruneTestEnv(t, blocks)
+	defer env.Cleanup()
+	ledgerid := "testLedger"
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, ledgerid)
+	mgr := blkfileMgrWrapper.blockfileMgr
+	blkfileMgrWrapper.addBlocks(blocks[:200])
+	testutil.AssertEquals(t, mgr.cpInfo.latestFileChunkSuffixNum, 2)
+
+	// Pruning below the first block file is a no-op
+	testutil.AssertNoError(t, mgr.prune(10, nil, ""), "")
+	testutil.AssertEquals(t, mgr.getPruneInfo().firstBlockNumber, uint64(0))
+
+	loc, err := mgr.index.getBlockLocByBlockNum(160)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, loc.fileSuffixNum, 2)
+	prunedTxID, err := extractTxID(blocks[10].Data.Data[0])
+	testutil.AssertNoError(t, err, "")
+
+	var fileSizes []int64
+	for fileNum := 0; fileNum < 2; fileNum++ {
+		_, size, err := util.FileExists(deriveBlockfilePath(mgr.rootDir, fileNum))
+		testutil.AssertNoError(t, err, "")
+		fileSizes = append(fileSizes, size)
+	}
+
+	archiveDir := filepath.Join(testPath(), "archive")
+	defer os.RemoveAll(filepath.Dir(archiveDir))
+	testutil.AssertNoError(t, mgr.prune(160, []uint64{0, 10}, archiveDir), "")
+	first := mgr.getPruneInfo().firstBlockNumber
+	testutil.AssertEquals(t, first > 100 && first <= 160, true)
+	for fileNum := 0; fileNum < 2; fileNum++ {
+		exists, _, err := util.FileExists(deriveBlockfilePath(mgr.rootDir, fileNum))
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, exists, false)
+		exists, size, err := util.FileExists(deriveBlockfilePath(archiveDir, fileNum))
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, exists, true)
+		testutil.AssertEquals(t, size, fileSizes[fileNum])
+	}
+
+	checkPruned := func(mgr *blockfileMgr, retained ...uint64) {
+		for _, num := range retained {
+			block, err := mgr.retrieveBlockByNumber(num)
+			testutil.AssertNoError(t, err, "Error while retrieving a retained block")
+			testutil.AssertEquals(t, block, blocks[num])
+		}
+		_, err := mgr.retrieveBlockByNumber(first - 1)
+		testutil.AssertSame(t, err, blkstorage.ErrPruned)
+		_, err = mgr.retrieveBlockByHash(blocks[first-1].Header.Hash())
+		testutil.AssertSame(t, err, blkstorage.ErrPruned)
+		_, err = mgr.retrieveTransactionByID(prunedTxID)
+		testutil.AssertSame(t, err, blkstorage.ErrPruned)
+		_, err = mgr.retrieveBlockByTxID(prunedTxID)
+		testutil.AssertSame(t, err, blkstorage.ErrPruned)
+		_, err = mgr.retrieveTxValidationCodeByTxID(prunedTxID)
+		testutil.AssertSame(t, err, blkstorage.ErrPruned)
+		_, err = mgr.retrieveTransactionByID("unknownTxID")
+		testutil.AssertSame(t, err, blkstorage.ErrNotFoundInIndex)
+
+		itr, err := mgr.retrieveBlocks(first - 1)
+		testutil.AssertNoError(t, err, "")
+		_, err = itr.Next()
+		testutil.AssertSame(t, err, blkstorage.ErrPruned)
+		itr.Close()
+
+		testBlockfileMgrBlockIterator(t, mgr, int(first), 199, blocks[first:200])
+	}
+	checkPruned(mgr, 0, 10)
+
+	// The prune survives a restart
+	blkfileMgrWrapper.close()
+	blkfileMgrWrapper = newTestBlockfileWrapper(env, ledgerid)
+	defer blkfileMgrWrapper.close()
+	mgr = blkfileMgrWrapper.blockfileMgr
+	testutil.AssertEquals(t, mgr.getPruneInfo().firstBlockNumber, first)
+	checkPruned(mgr, 0, 10)
+
+	// A retained block no longer listed is dropped by the next prune
+	blkfileMgrWrapper.addBlocks(blocks[200:])
+	testutil.AssertNoError(t, mgr.prune(299, []uint64{0}, ""), "")
+	testutil.AssertEquals(t, mgr.getPruneInfo().firstBlockNumber > first, true)
+	_, err = mgr.retrieveBlockByNumber(10)
+	testutil.AssertSame(t, err, blkstorage.ErrPruned)
+	block, err := mgr.retrieveBlockByNumber(0)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, block, blocks[0])
+}
+
+func TestBlockfileMgrPruneRemovesLeftoverFiles(t *testing.T) {
+	blocks := testutil.ConstructTestBlocks(t, 200)
+	env := newPruneTestEnv(t, blocks)
+	defer env.Cleanup()
+	ledgerid := "testLedger"
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, ledgerid)
+	blkfileMgrWrapper.addBlocks(blocks)
+	mgr := blkfileMgrWrapper.blockfileMgr
+
+	// Simulate a crash after the prune info was saved but before any file was removed
+	loc, err := mgr.index.getBlockLocByBlockNum(199)
+	testutil.AssertNoError(t, err, "")
+	first, err := mgr.firstBlockNumberInFile(loc.fileSuffixNum)
+	testutil.AssertNoError(t, err, "")
+	b, err := (&pruneInfo{firstFileSuffixNum: loc.fileSuffixNum, firstBlockNumber: first}).marshal()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNoError(t, mgr.db.Put(pruneInfoKey, b, true), "")
+	blkfileMgrWrapper.close()
+
+	blkfileMgrWrapper = newTestBlockfileWrapper(env, ledgerid)
+	defer blkfileMgrWrapper.close()
+	for fileNum := 0; fileNum < loc.fileSuffixNum; fileNum++ {
+		exists, _, err := util.FileExists(deriveBlockfilePath(mgr.rootDir, fileNum))
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, exists, false)
+	}
+	testBlockfileMgrBlockIterator(t, blkfileMgrWrapper.blockfileMgr, int(first), 199, blocks[first:])
+	_, err = blkfileMgrWrapper.blockfileMgr.retrieveBlockByNumber(0)
+	testutil.AssertSame(t, err, blkstorage.ErrPruned)
+}
+
+func TestBlockStoreCreateFromSnapshot(t *testing.T) {
+	blocks := testutil.ConstructTestBlocks(t, 20)
+	env := newTestEnv(t, NewConf(testPath(), 0))
+	defer env.Cleanup()
+	ledgerid := "testLedger"
+	snapshotTxID, err := extractTxID(blocks[5].Data.Data[0])
+	testutil.AssertNoError(t, err, "")
+	_, err = env.provider.CreateBlockStoreFromSnapshot(ledgerid, blocks[9], []*common.Block{blocks[2]}, &txIDsSliceItr{[]string{snapshotTxID}})
+	testutil.AssertNoError(t, err, "")
+
+	checkBootstrapped := func(mgr *blockfileMgr, height uint64) {
+		bcInfo := mgr.getBlockchainInfo()
+		testutil.AssertEquals(t, bcInfo.Height, height)
+		testutil.AssertEquals(t, bcInfo.CurrentBlockHash, blocks[height-1].Header.Hash())
+		for _, num := range []uint64{2, 9} {
+			block, err := mgr.retrieveBlockByNumber(num)
+			testutil.AssertNoError(t, err, "")
+			testutil.AssertEquals(t, block, blocks[num])
+		}
+		_, err := mgr.retrieveBlockByNumber(5)
+		testutil.AssertSame(t, err, blkstorage.ErrPruned)
+		_, err = mgr.retrieveTransactionByID(snapshotTxID)
+		testutil.AssertSame(t, err, blkstorage.ErrPruned)
+		_, err = mgr.retrieveTxValidationCodeByTxID(snapshotTxID)
+		testutil.AssertSame(t, err, blkstorage.ErrPruned)
+	}
+
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, ledgerid)
+	checkBootstrapped(blkfileMgrWrapper.blockfileMgr, 10)
+	blkfileMgrWrapper.addBlocks(blocks[10:])
+	checkBootstrapped(blkfileMgrWrapper.blockfileMgr, 20)
+	blkfileMgrWrapper.close()
+
+	// The bootstrapped store survives a restart
+	blkfileMgrWrapper = newTestBlockfileWrapper(env, ledgerid)
+	defer blkfileMgrWrapper.close()
+	checkBootstrapped(blkfileMgrWrapper.blockfileMgr, 20)
+	testBlockfileMgrBlockIterator(t, blkfileMgrWrapper.blockfileMgr, 10, 19, blocks[10:])
+
+	_, err = env.provider.CreateBlockStoreFromSnapshot(ledgerid, blocks[9], nil, nil)
+	testutil.AssertError(t, err, "Expected an error for an existing block store")
+}
+
+func TestBlockfileMgrTxIDsIterator(t *testing.T) {
+	blocks := testutil.ConstructTestBlocks(t, 300)
+	env := newPruneTestEnv(t, blocks)
+	defer env.Cleanup()
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, "testLedger")
+	defer blkfileMgrWrapper.close()
+	mgr := blkfileMgrWrapper.blockfileMgr
+	blkfileMgrWrapper.addBlocks(blocks[:200])
+	testutil.AssertNoError(t, mgr.prune(160, nil, ""), "")
+
+	expected := make(map[string]bool)
+	for _, block := range blocks[:200] {
+		for _, envBytes := range block.Data.Data {
+			txID, err := extractTxID(envBytes)
+			testutil.AssertNoError(t, err, "")
+			expected[txID] = true
+		}
+	}
+
+	// The blocks added after the iterator is created are left out
+	itr := mgr.index.getTxIDsIterator()
+	defer itr.Close()
+	blkfileMgrWrapper.addBlocks(blocks[200:])
+	txIDs := make(map[string]bool)
+	for {
+		res, err := itr.Next()
+		testutil.AssertNoError(t, err, "")
+		if res == nil {
+			break
+		}
+		txIDs[res.(string)] = true
+	}
+	testutil.AssertEquals(t, txIDs, expected)
+}
+
+// txIDsSliceItr returns the transaction IDs of a slice
+type txIDsSliceItr struct {
+	txIDs []string
+}
+
+func (itr *txIDsSliceItr) Next() (ledger.QueryResult, error) {
+	if len(itr.txIDs) == 0 {
+		return nil, nil
+	}
+	txID := itr.txIDs[0]
+	itr.txIDs = itr.txIDs[1:]
+	return txID, nil
+}
+
+func (itr *txIDsSliceItr) Close() {
+}
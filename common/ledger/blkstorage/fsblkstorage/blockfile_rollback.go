@@ -0,0 +1,136 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fsblkstorage
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	"github.com/hyperledger/fabric/common/ledger/util"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+)
+
+// rollback removes the blocks after blockNum from the block store of a ledger,
+// which must not be open. The index entries of the removed blocks are removed,
+// and the checkpoint is moved back to blockNum, in one batch. The block files
+// are truncated afterwards. A rollback interrupted after the batch is written
+// completes when it is run again.
+func rollback(id string, conf *Conf, indexConfig *blkstorage.IndexConfig, indexStore *leveldbhelper.DBHandle, blockNum uint64) error {
+	mgr := newBlockfileMgr(id, conf, indexConfig, indexStore)
+	// only the checkpoint and the index of the manager are used
+	mgr.close()
+
+	cpInfo := mgr.cpInfo
+	if cpInfo.isChainEmpty || blockNum > cpInfo.lastBlockNumber {
+		return fmt.Errorf("Cannot roll back to block [%d], the block store has %d blocks", blockNum, mgr.getBlockchainInfo().Height)
+	}
+	if firstBlockNum := mgr.getPruneInfo().firstBlockNumber; blockNum < firstBlockNum {
+		return fmt.Errorf("Cannot roll back to block [%d], the blocks below [%d] are pruned", blockNum, firstBlockNum)
+	}
+	if blockNum == cpInfo.lastBlockNumber {
+		logger.Infof("Block store is already at block [%d]", blockNum)
+		return nil
+	}
+
+	loc, err := mgr.index.getBlockLocByBlockNum(blockNum + 1)
+	if err != nil {
+		return fmt.Errorf("Could not locate block [%d]: %s", blockNum+1, err)
+	}
+	isRemoved := func(flp *fileLocPointer) bool {
+		return flp.fileSuffixNum > loc.fileSuffixNum ||
+			(flp.fileSuffixNum == loc.fileSuffixNum && flp.offset >= loc.offset)
+	}
+
+	batch := leveldbhelper.NewUpdateBatch()
+	if err := mgr.removeBlocksFrom(batch, loc, cpInfo.latestFileChunkSuffixNum, isRemoved); err != nil {
+		return err
+	}
+
+	next := &checkpointInfo{
+		latestFileChunkSuffixNum: loc.fileSuffixNum,
+		latestFileChunksize:      loc.offset,
+		isChainEmpty:             false,
+		lastBlockNumber:          blockNum,
+	}
+	b, err := next.marshal()
+	if err != nil {
+		return err
+	}
+	batch.Put(blkMgrInfoKey, b)
+	batch.Put(indexCheckpointKey, encodeBlockNum(blockNum))
+	if err := mgr.db.WriteBatch(batch, true); err != nil {
+		return err
+	}
+
+	if err := removeBlockfilesAfter(mgr.rootDir, next.latestFileChunkSuffixNum); err != nil {
+		return err
+	}
+	if err := os.Truncate(deriveBlockfilePath(mgr.rootDir, next.latestFileChunkSuffixNum), int64(next.latestFileChunksize)); err != nil {
+		return err
+	}
+	logger.Infof("Rolled back blocks [%d] to [%d], checkpoint=%s", blockNum+1, cpInfo.lastBlockNumber, next)
+	return nil
+}
+
+// removeBlocksFrom adds to the batch the removal of the index entries of the
+// blocks from the given location up to the end of the block file endFileNum
+func (mgr *blockfileMgr) removeBlocksFrom(batch *leveldbhelper.UpdateBatch, loc *fileLocPointer, endFileNum int,
+	isRemoved func(*fileLocPointer) bool) error {
+	stream, err := newBlockStream(mgr.rootDir, loc.fileSuffixNum, int64(loc.offset), endFileNum)
+	if err != nil {
+		return err
+	}
+	defer stream.close()
+	for {
+		blockBytes, _, err := stream.nextBlockBytesAndPlacementInfo()
+		if err != nil {
+			return err
+		}
+		if blockBytes == nil {
+			return nil
+		}
+		info, err := extractSerializedBlockInfo(blockBytes)
+		if err != nil {
+			return err
+		}
+		err = mgr.index.removeBlock(batch, &blockIdxInfo{
+			blockNum:  info.blockHeader.Number,
+			blockHash: info.blockHeader.Hash(),
+			txOffsets: info.txOffsets,
+			metadata:  info.metadata,
+		}, isRemoved)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// removeBlockfilesAfter removes the block files which follow the given one,
+// which a crash may have left behind while rolling back. Files are removed in
+// descending order, so the leftovers are the files right after the given one.
+func removeBlockfilesAfter(rootDir string, fileNum int) error {
+	lastFileNum := fileNum
+	for {
+		exists, _, err := util.FileExists(deriveBlockfilePath(rootDir, lastFileNum+1))
+		if err != nil {
+			return err
+		}
+		if !exists {
+			break
+		}
+		lastFileNum++
+	}
+	for ; lastFileNum > fileNum; lastFileNum-- {
+		filePath := deriveBlockfilePath(rootDir, lastFileNum)
+		logger.Infof("Removing block file [%s] after the latest one", filePath)
+		if err := os.Remove(filePath); err != nil {
+			return fmt.Errorf("Could not remove block file [%s]: %s", filePath, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fsblkstorage
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/common/ledger/util"
+)
+
+func TestBlockStoreRollback(t *testing.T) {
+	blocks := testutil.ConstructTestBlocks(t, 200)
+	env := newPruneTestEnv(t, blocks)
+	defer env.Cleanup()
+	ledgerid := "testLedger"
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, ledgerid)
+	blkfileMgrWrapper.addBlocks(blocks)
+	mgr := blkfileMgrWrapper.blockfileMgr
+	testutil.AssertEquals(t, mgr.cpInfo.latestFileChunkSuffixNum, 2)
+	loc, err := mgr.index.getBlockLocByBlockNum(81)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, loc.fileSuffixNum, 1)
+	removedTxID, err := extractTxID(blocks[150].Data.Data[0])
+	testutil.AssertNoError(t, err, "")
+	blkfileMgrWrapper.close()
+
+	testutil.AssertError(t, env.provider.Rollback(ledgerid, 200), "Expected an error for a block not in the store")
+	testutil.AssertError(t, env.provider.Rollback("unknownLedger", 10), "Expected an error for an unknown ledger")
+	testutil.AssertNoError(t, env.provider.Rollback(ledgerid, 199), "")
+	testutil.AssertNoError(t, env.provider.Rollback(ledgerid, 80), "")
+
+	exists, size, err := util.FileExists(deriveBlockfilePath(mgr.rootDir, 1))
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, exists, true)
+	testutil.AssertEquals(t, size, int64(loc.offset))
+	exists, _, err = util.FileExists(deriveBlockfilePath(mgr.rootDir, 2))
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, exists, false)
+
+	// Block files left behind by an interrupted rollback are removed on opening
+	for fileNum := 2; fileNum < 4; fileNum++ {
+		testutil.AssertNoError(t, ioutil.WriteFile(deriveBlockfilePath(mgr.rootDir, fileNum), []byte("leftover"), 0644), "")
+	}
+
+	blkfileMgrWrapper = newTestBlockfileWrapper(env, ledgerid)
+	defer blkfileMgrWrapper.close()
+	mgr = blkfileMgrWrapper.blockfileMgr
+	for fileNum := 2; fileNum < 4; fileNum++ {
+		exists, _, err = util.FileExists(deriveBlockfilePath(mgr.rootDir, fileNum))
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, exists, false)
+	}
+	bcInfo := mgr.getBlockchainInfo()
+	testutil.AssertEquals(t, bcInfo.Height, uint64(81))
+	testutil.AssertEquals(t, bcInfo.CurrentBlockHash, blocks[80].Header.Hash())
+	_, err = mgr.retrieveBlockByNumber(81)
+	testutil.AssertError(t, err, "Expected an error for a rolled back block")
+	_, err = mgr.retrieveBlockByHash(blocks[150].Header.Hash())
+	testutil.AssertSame(t, err, blkstorage.ErrNotFoundInIndex)
+	_, err = mgr.retrieveTransactionByID(removedTxID)
+	testutil.AssertSame(t, err, blkstorage.ErrNotFoundInIndex)
+	testBlockfileMgrBlockIterator(t, mgr, 0, 80, blocks[:81])
+
+	// The removed blocks can be added again
+	blkfileMgrWrapper.addBlocks(blocks[81:])
+	testBlockfileMgrBlockIterator(t, mgr, 0, 199, blocks)
+	blkfileMgrWrapper.testGetBlockByHash(blocks)
+}
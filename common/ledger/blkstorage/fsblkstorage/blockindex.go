@@ -22,6 +22,7 @@ import (
 	"fmt"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/util"
 	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
@@ -37,6 +38,8 @@ const (
 	blockNumTranNumIdxKeyPrefix    = 'a'
 	blockTxIDIdxKeyPrefix          = 'b'
 	txValidationResultIdxKeyPrefix = 'v'
+	prunedBlockHashIdxKeyPrefix    = 'p'
+	prunedTxIDIdxKeyPrefix         = 'q'
 	indexCheckpointKeyStr          = "indexCheckpointKey"
 )
 
@@ -46,12 +49,15 @@ var errIndexEmpty = errors.New("NoBlockIndexed")
 type index interface {
 	getLastBlockIndexed() (uint64, error)
 	indexBlock(blockIdxInfo *blockIdxInfo) error
+	removeBlock(batch *leveldbhelper.UpdateBatch, blockIdxInfo *blockIdxInfo, isRemoved func(*fileLocPointer) bool) error
+	pruneBlock(batch *leveldbhelper.UpdateBatch, blockIdxInfo *blockIdxInfo, isRemoved func(*fileLocPointer) bool) error
 	getBlockLocByHash(blockHash []byte) (*fileLocPointer, error)
 	getBlockLocByBlockNum(blockNum uint64) (*fileLocPointer, error)
 	getTxLoc(txID string) (*fileLocPointer, error)
 	getTXLocByBlockNumTranNum(blockNum uint64, tranNum uint64) (*fileLocPointer, error)
 	getBlockLocByTxID(txID string) (*fileLocPointer, error)
 	getTxValidationCodeByTxID(txID string) (peer.TxValidationCode, error)
+	getTxIDsIterator() ledger.ResultsIterator
 }
 
 type blockIdxInfo struct {
@@ -162,6 +168,158 @@ func (index *blockIndex) indexBlock(blockIdxInfo *blockIdxInfo) error {
 	return nil
 }
 
+// removeBlock adds to the batch the removal of the entries of a block which is
+// being rolled back. The entries of a transaction ID are kept unless isRemoved
+// holds for their location, so that a transaction ID which was reused in a
+// block not being removed remains indexed
+func (index *blockIndex) removeBlock(batch *leveldbhelper.UpdateBatch, blockIdxInfo *blockIdxInfo, isRemoved func(*fileLocPointer) bool) error {
+	return index.removeEntries(batch, blockIdxInfo, isRemoved, false)
+}
+
+// pruneBlock is like removeBlock, for a block which is being pruned. The hash
+// and transaction IDs of the block stay known, so that they are reported as
+// pruned rather than not found, and a pruned transaction ID is still detected
+// as a duplicate when it is reused
+func (index *blockIndex) pruneBlock(batch *leveldbhelper.UpdateBatch, blockIdxInfo *blockIdxInfo, isRemoved func(*fileLocPointer) bool) error {
+	return index.removeEntries(batch, blockIdxInfo, isRemoved, true)
+}
+
+func (index *blockIndex) removeEntries(batch *leveldbhelper.UpdateBatch, blockIdxInfo *blockIdxInfo, isRemoved func(*fileLocPointer) bool, pruned bool) error {
+	logger.Debugf("Removing index entries of block [%d]", blockIdxInfo.blockNum)
+	blockNumBytes := encodeBlockNum(blockIdxInfo.blockNum)
+	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrBlockHash]; ok {
+		batch.Delete(constructBlockHashKey(blockIdxInfo.blockHash))
+		if pruned {
+			batch.Put(constructPrunedBlockHashKey(blockIdxInfo.blockHash), blockNumBytes)
+		}
+	}
+
+	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrBlockNum]; ok {
+		batch.Delete(constructBlockNumKey(blockIdxInfo.blockNum))
+	}
+
+	for txNum, txoffset := range blockIdxInfo.txOffsets {
+		if _, ok := index.indexItemsMap[blkstorage.IndexableAttrBlockNumTranNum]; ok {
+			batch.Delete(constructBlockNumTranNumKey(blockIdxInfo.blockNum, uint64(txNum)))
+		}
+
+		removed, err := index.isTxIDRemoved(txoffset.txID, isRemoved)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			continue
+		}
+		if _, ok := index.indexItemsMap[blkstorage.IndexableAttrTxID]; ok {
+			batch.Delete(constructTxIDKey(txoffset.txID))
+		}
+		if _, ok := index.indexItemsMap[blkstorage.IndexableAttrBlockTxID]; ok {
+			batch.Delete(constructBlockTxIDKey(txoffset.txID))
+		}
+		if _, ok := index.indexItemsMap[blkstorage.IndexableAttrTxValidationCode]; ok {
+			batch.Delete(constructTxValidationCodeIDKey(txoffset.txID))
+		}
+		if pruned && index.indexesTxIDs() {
+			batch.Put(constructPrunedTxIDKey(txoffset.txID), blockNumBytes)
+		}
+	}
+	return nil
+}
+
+// indexesTxIDs tells whether any entry is indexed by transaction ID
+func (index *blockIndex) indexesTxIDs() bool {
+	for _, attr := range []blkstorage.IndexableAttr{
+		blkstorage.IndexableAttrTxID,
+		blkstorage.IndexableAttrBlockTxID,
+		blkstorage.IndexableAttrTxValidationCode,
+	} {
+		if _, ok := index.indexItemsMap[attr]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// getTxIDsIterator returns an iterator over the transaction IDs of the blocks present, followed
+// by those of the pruned blocks. Each iterator of the index db reads the db as of its creation:
+// the pruned transaction IDs are read last, so that a transaction ID whose block is pruned in
+// between is returned twice rather than missed
+func (index *blockIndex) getTxIDsIterator() ledger.ResultsIterator {
+	itr := &txIDsItr{}
+	for _, attr := range []struct {
+		attr   blkstorage.IndexableAttr
+		prefix byte
+	}{
+		{blkstorage.IndexableAttrTxID, txIDIdxKeyPrefix},
+		{blkstorage.IndexableAttrBlockTxID, blockTxIDIdxKeyPrefix},
+		{blkstorage.IndexableAttrTxValidationCode, txValidationResultIdxKeyPrefix},
+	} {
+		if _, ok := index.indexItemsMap[attr.attr]; ok {
+			itr.itrs = append(itr.itrs, index.db.GetIterator([]byte{attr.prefix}, []byte{attr.prefix + 1}))
+			break
+		}
+	}
+	itr.itrs = append(itr.itrs, index.db.GetIterator([]byte{prunedTxIDIdxKeyPrefix}, []byte{prunedTxIDIdxKeyPrefix + 1}))
+	return itr
+}
+
+// txIDsItr returns the transaction IDs, of type string, found in the keys of a sequence of
+// iterators over the keys of a single prefix each
+type txIDsItr struct {
+	itrs []*leveldbhelper.Iterator
+}
+
+func (itr *txIDsItr) Next() (ledger.QueryResult, error) {
+	for len(itr.itrs) > 0 {
+		current := itr.itrs[0]
+		if current.Next() {
+			return string(current.Key()[1:]), nil
+		}
+		if err := current.Error(); err != nil {
+			return nil, err
+		}
+		current.Release()
+		itr.itrs = itr.itrs[1:]
+	}
+	return nil, nil
+}
+
+func (itr *txIDsItr) Close() {
+	for _, current := range itr.itrs {
+		current.Release()
+	}
+	itr.itrs = nil
+}
+
+// notFoundErr returns the error for a key which is missing from an index:
+// ErrPruned if the pruned key is present, and ErrNotFoundInIndex otherwise
+func (index *blockIndex) notFoundErr(prunedKey []byte) error {
+	b, err := index.db.Get(prunedKey)
+	if err != nil {
+		return err
+	}
+	if b != nil {
+		return blkstorage.ErrPruned
+	}
+	return blkstorage.ErrNotFoundInIndex
+}
+
+// isTxIDRemoved tells whether the entries of a transaction ID point to a removed location
+func (index *blockIndex) isTxIDRemoved(txID string, isRemoved func(*fileLocPointer) bool) (bool, error) {
+	flp, err := index.getTxLoc(txID)
+	if err == blkstorage.ErrAttrNotIndexed {
+		flp, err = index.getBlockLocByTxID(txID)
+	}
+	switch err {
+	case nil:
+		return isRemoved(flp), nil
+	case blkstorage.ErrAttrNotIndexed, blkstorage.ErrNotFoundInIndex, blkstorage.ErrPruned:
+		return true, nil
+	default:
+		return false, err
+	}
+}
+
 func (index *blockIndex) getBlockLocByHash(blockHash []byte) (*fileLocPointer, error) {
 	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrBlockHash]; !ok {
 		return nil, blkstorage.ErrAttrNotIndexed
@@ -171,7 +329,7 @@ func (index *blockIndex) getBlockLocByHash(blockHash []byte) (*fileLocPointer, e
 		return nil, err
 	}
 	if b == nil {
-		return nil, blkstorage.ErrNotFoundInIndex
+		return nil, index.notFoundErr(constructPrunedBlockHashKey(blockHash))
 	}
 	blkLoc := &fileLocPointer{}
 	blkLoc.unmarshal(b)
@@ -203,7 +361,7 @@ func (index *blockIndex) getTxLoc(txID string) (*fileLocPointer, error) {
 		return nil, err
 	}
 	if b == nil {
-		return nil, blkstorage.ErrNotFoundInIndex
+		return nil, index.notFoundErr(constructPrunedTxIDKey(txID))
 	}
 	txFLP := &fileLocPointer{}
 	txFLP.unmarshal(b)
@@ -219,7 +377,7 @@ func (index *blockIndex) getBlockLocByTxID(txID string) (*fileLocPointer, error)
 		return nil, err
 	}
 	if b == nil {
-		return nil, blkstorage.ErrNotFoundInIndex
+		return nil, index.notFoundErr(constructPrunedTxIDKey(txID))
 	}
 	txFLP := &fileLocPointer{}
 	txFLP.unmarshal(b)
@@ -252,7 +410,7 @@ func (index *blockIndex) getTxValidationCodeByTxID(txID string) (peer.TxValidati
 	if err != nil {
 		return peer.TxValidationCode(-1), err
 	} else if raw == nil {
-		return peer.TxValidationCode(-1), blkstorage.ErrNotFoundInIndex
+		return peer.TxValidationCode(-1), index.notFoundErr(constructPrunedTxIDKey(txID))
 	} else if len(raw) != 1 {
 		return peer.TxValidationCode(-1), errors.New("Invalid value in indexItems")
 	}
@@ -283,6 +441,14 @@ func constructTxValidationCodeIDKey(txID string) []byte {
 	return append([]byte{txValidationResultIdxKeyPrefix}, []byte(txID)...)
 }
 
+func constructPrunedBlockHashKey(blockHash []byte) []byte {
+	return append([]byte{prunedBlockHashIdxKeyPrefix}, blockHash...)
+}
+
+func constructPrunedTxIDKey(txID string) []byte {
+	return append([]byte{prunedTxIDIdxKeyPrefix}, []byte(txID)...)
+}
+
 func constructBlockNumTranNumKey(blockNum uint64, txNum uint64) []byte {
 	blkNumBytes := util.EncodeOrderPreservingVarUint64(blockNum)
 	tranNumBytes := util.EncodeOrderPreservingVarUint64(txNum)
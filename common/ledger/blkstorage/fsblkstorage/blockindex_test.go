@@ -20,8 +20,10 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
 	"github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/peer"
@@ -37,6 +39,12 @@ func (i *noopIndex) getLastBlockIndexed() (uint64, error) {
 func (i *noopIndex) indexBlock(blockIdxInfo *blockIdxInfo) error {
 	return nil
 }
+func (i *noopIndex) removeBlock(batch *leveldbhelper.UpdateBatch, blockIdxInfo *blockIdxInfo, isRemoved func(*fileLocPointer) bool) error {
+	return nil
+}
+func (i *noopIndex) pruneBlock(batch *leveldbhelper.UpdateBatch, blockIdxInfo *blockIdxInfo, isRemoved func(*fileLocPointer) bool) error {
+	return nil
+}
 func (i *noopIndex) getBlockLocByHash(blockHash []byte) (*fileLocPointer, error) {
 	return nil, nil
 }
@@ -58,6 +66,10 @@ func (i *noopIndex) getTxValidationCodeByTxID(txID string) (peer.TxValidationCod
 	return peer.TxValidationCode(-1), nil
 }
 
+func (i *noopIndex) getTxIDsIterator() ledger.ResultsIterator {
+	return nil
+}
+
 func TestBlockIndexSync(t *testing.T) {
 	testBlockIndexSync(t, 10, 5, false)
 	testBlockIndexSync(t, 10, 5, true)
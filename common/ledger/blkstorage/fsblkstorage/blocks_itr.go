@@ -20,6 +20,7 @@ import (
 	"sync"
 
 	"github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 )
 
 // blocksItr - an iterator for iterating over a sequence of blocks
@@ -51,6 +52,9 @@ func (itr *blocksItr) waitForBlock(blockNum uint64) uint64 {
 func (itr *blocksItr) initStream() error {
 	var lp *fileLocPointer
 	var err error
+	if itr.blockNumToRetrieve < itr.mgr.getPruneInfo().firstBlockNumber {
+		return blkstorage.ErrPruned
+	}
 	if lp, err = itr.mgr.index.getBlockLocByBlockNum(itr.blockNumToRetrieve); err != nil {
 		return err
 	}
@@ -97,5 +101,7 @@ func (itr *blocksItr) Close() {
 	itr.mgr.cpInfoCond.L.Lock()
 	defer itr.mgr.cpInfoCond.L.Unlock()
 	itr.mgr.cpInfoCond.Broadcast()
-	itr.stream.close()
+	if itr.stream != nil {
+		itr.stream.close()
+	}
 }
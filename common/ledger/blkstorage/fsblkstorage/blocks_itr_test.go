@@ -74,6 +74,26 @@ func TestBlockItrClose(t *testing.T) {
 	testutil.AssertNil(t, bh)
 }
 
+func TestBlockItrCloseBeforeNext(t *testing.T) {
+	env := newTestEnv(t, NewConf(testPath(), 0))
+	defer env.Cleanup()
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, "testLedger")
+	defer blkfileMgrWrapper.close()
+	blkfileMgr := blkfileMgrWrapper.blockfileMgr
+
+	blocks := testutil.ConstructTestBlocks(t, 5)
+	blkfileMgrWrapper.addBlocks(blocks)
+
+	// The stream of the iterator is only opened by Next
+	itr, err := blkfileMgr.retrieveBlocks(5)
+	testutil.AssertNoError(t, err, "")
+	itr.Close()
+
+	bh, err := itr.Next()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, bh)
+}
+
 func testIterateAndVerify(t *testing.T, itr *blocksItr, blocks []*common.Block, doneChan chan bool) {
 	blocksIterated := 0
 	for {
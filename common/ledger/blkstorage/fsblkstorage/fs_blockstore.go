@@ -86,6 +86,23 @@ func (store *fsBlockStore) RetrieveTxValidationCodeByTxID(txID string) (peer.TxV
 	return store.fileMgr.retrieveTxValidationCodeByTxID(txID)
 }
 
+// GetTxIDsIterator returns an iterator over the IDs of the transactions of the blocks committed so far,
+// including the pruned ones, whose results are of type string
+func (store *fsBlockStore) GetTxIDsIterator() (ledger.ResultsIterator, error) {
+	return store.fileMgr.index.getTxIDsIterator(), nil
+}
+
+// Prune removes the block files whose blocks all precede blockNum, keeping the blocks listed in retain.
+// The block files are copied to archiveDir first, unless it is empty
+func (store *fsBlockStore) Prune(blockNum uint64, retain []uint64, archiveDir string) error {
+	return store.fileMgr.prune(blockNum, retain, archiveDir)
+}
+
+// GetFirstBlockNumber returns the number of the oldest block which was not pruned
+func (store *fsBlockStore) GetFirstBlockNumber() (uint64, error) {
+	return store.fileMgr.getPruneInfo().firstBlockNumber, nil
+}
+
 // Shutdown shuts down the block store
 func (store *fsBlockStore) Shutdown() {
 	logger.Debugf("closing fs blockStore:%s", store.id)
@@ -17,9 +17,14 @@ limitations under the License.
 package fsblkstorage
 
 import (
+	"fmt"
+	"os"
+
+	"github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/util"
 	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	"github.com/hyperledger/fabric/protos/common"
 )
 
 // FsBlockstoreProvider provides handle to block storage - this is not thread-safe
@@ -48,6 +53,23 @@ func (p *FsBlockstoreProvider) OpenBlockStore(ledgerid string) (blkstorage.Block
 	return newFsBlockStore(ledgerid, p.conf, p.indexConfig, indexStoreHandle), nil
 }
 
+// CreateBlockStoreFromSnapshot creates a block store for given ledgerid, which starts after the last block
+// of a snapshot, recording the transaction IDs of the snapshot as pruned. The block store must not exist yet
+func (p *FsBlockstoreProvider) CreateBlockStoreFromSnapshot(ledgerid string, lastBlock *common.Block, retain []*common.Block, txIDs ledger.ResultsIterator) (blkstorage.BlockStore, error) {
+	exists, err := p.Exists(ledgerid)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("block store for ledger [%s] already exists", ledgerid)
+	}
+	indexStoreHandle := p.leveldbProvider.GetDBHandle(ledgerid)
+	if err := bootstrapFromSnapshot(indexStoreHandle, lastBlock, retain, txIDs); err != nil {
+		return nil, err
+	}
+	return newFsBlockStore(ledgerid, p.conf, p.indexConfig, indexStoreHandle), nil
+}
+
 // Exists tells whether the BlockStore with given id exists
 func (p *FsBlockstoreProvider) Exists(ledgerid string) (bool, error) {
 	exists, _, err := util.FileExists(p.conf.getLedgerBlockDir(ledgerid))
@@ -59,6 +81,39 @@ func (p *FsBlockstoreProvider) List() ([]string, error) {
 	return util.ListSubdirs(p.conf.getChainsDir())
 }
 
+// Remove deletes the block store for given ledgerid, its block files and its index.
+// The block store should not be open
+func (p *FsBlockstoreProvider) Remove(ledgerid string) error {
+	indexStoreHandle := p.leveldbProvider.GetDBHandle(ledgerid)
+	itr := indexStoreHandle.GetIterator(nil, nil)
+	batch := leveldbhelper.NewUpdateBatch()
+	for itr.Next() {
+		batch.Delete(itr.Key())
+	}
+	err := itr.Error()
+	itr.Release()
+	if err != nil {
+		return err
+	}
+	if err := indexStoreHandle.WriteBatch(batch, true); err != nil {
+		return err
+	}
+	return os.RemoveAll(p.conf.getLedgerBlockDir(ledgerid))
+}
+
+// Rollback removes the blocks after blockNum from the block store for given ledgerid, along with their
+// index entries. The block store should not be open
+func (p *FsBlockstoreProvider) Rollback(ledgerid string, blockNum uint64) error {
+	exists, err := p.Exists(ledgerid)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("block store for ledger [%s] does not exist", ledgerid)
+	}
+	return rollback(ledgerid, p.conf, p.indexConfig, p.leveldbProvider.GetDBHandle(ledgerid), blockNum)
+}
+
 // Close closes the FsBlockstoreProvider
 func (p *FsBlockstoreProvider) Close() {
 	p.leveldbProvider.Close()
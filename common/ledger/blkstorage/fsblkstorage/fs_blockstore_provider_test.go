@@ -147,6 +147,40 @@ func TestBlockStoreProvider(t *testing.T) {
 
 }
 
+func TestBlockStoreProviderRemove(t *testing.T) {
+	env := newTestEnv(t, NewConf(testPath(), 0))
+	defer env.Cleanup()
+
+	provider := env.provider
+	blocks := testutil.ConstructTestBlocks(t, 10)
+	for i := 0; i < 2; i++ {
+		store, _ := provider.OpenBlockStore(constructLedgerid(i))
+		for _, block := range blocks {
+			testutil.AssertNoError(t, store.AddBlock(block), "")
+		}
+		store.Shutdown()
+	}
+
+	testutil.AssertNoError(t, provider.Remove(constructLedgerid(0)), "")
+	exists, err := provider.Exists(constructLedgerid(0))
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, exists, false)
+	storeNames, _ := provider.List()
+	testutil.AssertEquals(t, storeNames, []string{constructLedgerid(1)})
+
+	// A block store recreated under the same id starts empty
+	store, _ := provider.OpenBlockStore(constructLedgerid(0))
+	defer store.Shutdown()
+	bcInfo, _ := store.GetBlockchainInfo()
+	testutil.AssertEquals(t, bcInfo.Height, uint64(0))
+	_, err = store.RetrieveBlockByHash(blocks[0].Header.Hash())
+	testutil.AssertSame(t, err, blkstorage.ErrNotFoundInIndex)
+
+	store, _ = provider.OpenBlockStore(constructLedgerid(1))
+	defer store.Shutdown()
+	checkBlocks(t, blocks, store)
+}
+
 func constructLedgerid(id int) string {
 	return fmt.Sprintf("ledger_%d", id)
 }
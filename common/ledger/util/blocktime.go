@@ -0,0 +1,58 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// BlockTimestamp returns the latest timestamp among the transactions of the
+// block, or the zero time if none of them carries one
+func BlockTimestamp(block *common.Block) time.Time {
+	var latest time.Time
+	if block.Data == nil {
+		return latest
+	}
+	for _, data := range block.Data.Data {
+		env, err := utils.GetEnvelopeFromBlock(data)
+		if err != nil {
+			continue
+		}
+		chdr, err := utils.ChannelHeader(env)
+		if err != nil || chdr.Timestamp == nil {
+			continue
+		}
+		if ts := time.Unix(chdr.Timestamp.Seconds, int64(chdr.Timestamp.Nanos)); ts.After(latest) {
+			latest = ts
+		}
+	}
+	return latest
+}
+
+// AgeBoundary returns the number of the first block in [low, high) with a
+// transaction at or after the cutoff, or high if there is none. Blocks are
+// retrieved with getBlock.
+// Transaction timestamps are set by clients and need not grow along the chain,
+// so the blocks are scanned in order rather than bisected: a back-dated
+// transaction in a recent block must not make the blocks before it look old.
+// The scan only reads the blocks older than the cutoff, plus one.
+func AgeBoundary(low, high uint64, cutoff time.Time, getBlock func(number uint64) (*common.Block, error)) (uint64, error) {
+	for number := low; number < high; number++ {
+		block, err := getBlock(number)
+		if err != nil {
+			return 0, fmt.Errorf("cannot retrieve block %d: %s", number, err)
+		}
+		if !BlockTimestamp(block).Before(cutoff) {
+			return number, nil
+		}
+	}
+	return high, nil
+}
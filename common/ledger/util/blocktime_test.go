@@ -0,0 +1,76 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func timestampedBlock(number uint64, timestamps ...time.Time) *common.Block {
+	block := common.NewBlock(number, nil)
+	for _, ts := range timestamps {
+		block.Data.Data = append(block.Data.Data, utils.MarshalOrPanic(&common.Envelope{Payload: utils.MarshalOrPanic(&common.Payload{
+			Header: &common.Header{ChannelHeader: utils.MarshalOrPanic(&common.ChannelHeader{
+				Timestamp: &timestamp.Timestamp{Seconds: ts.Unix(), Nanos: int32(ts.Nanosecond())},
+			})},
+		})}))
+	}
+	return block
+}
+
+func TestBlockTimestamp(t *testing.T) {
+	start := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	block := timestampedBlock(0, start.Add(time.Hour), start, start.Add(time.Minute))
+	assert.True(t, BlockTimestamp(block).Equal(start.Add(time.Hour)))
+
+	block.Data.Data = append(block.Data.Data, []byte("garbage"))
+	assert.True(t, BlockTimestamp(block).Equal(start.Add(time.Hour)))
+
+	assert.True(t, BlockTimestamp(common.NewBlock(0, nil)).IsZero())
+}
+
+func TestAgeBoundary(t *testing.T) {
+	start := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	blocks := make([]*common.Block, 10)
+	for i := range blocks {
+		blocks[i] = timestampedBlock(uint64(i), start.Add(time.Duration(i)*day))
+	}
+	var read []uint64
+	getBlock := func(number uint64) (*common.Block, error) {
+		read = append(read, number)
+		return blocks[number], nil
+	}
+
+	boundary, err := AgeBoundary(0, 10, start.Add(7*day), getBlock)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), boundary)
+	assert.Equal(t, []uint64{0, 1, 2, 3, 4, 5, 6, 7}, read)
+
+	boundary, err = AgeBoundary(2, 5, start.Add(7*day), getBlock)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), boundary)
+
+	// A transaction back-dated by its client in a recent block does not make
+	// the blocks before it look old
+	blocks[8] = timestampedBlock(8, start)
+	boundary, err = AgeBoundary(0, 10, start.Add(5*day+time.Hour), getBlock)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), boundary)
+
+	_, err = AgeBoundary(0, 10, start.Add(7*day), func(number uint64) (*common.Block, error) {
+		return nil, errors.New("unreadable")
+	})
+	assert.Error(t, err)
+}
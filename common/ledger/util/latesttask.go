@@ -0,0 +1,49 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util
+
+import "sync"
+
+// LatestTask runs tasks in the background, one at a time. The tasks submitted
+// while one is running are not dropped: the latest of them runs once the
+// current one returns, and the others are skipped. The zero value is ready
+// for use.
+type LatestTask struct {
+	lock    sync.Mutex
+	running bool
+	next    func()
+}
+
+// Run runs the task in the background, or as soon as the running task returns
+func (lt *LatestTask) Run(task func()) {
+	lt.lock.Lock()
+	defer lt.lock.Unlock()
+	if lt.running {
+		lt.next = task
+		return
+	}
+	lt.running = true
+	go lt.loop(task)
+}
+
+func (lt *LatestTask) loop(task func()) {
+	for task != nil {
+		task()
+
+		lt.lock.Lock()
+		task, lt.next = lt.next, nil
+		lt.running = task != nil
+		lt.lock.Unlock()
+	}
+}
+
+// Running tells whether a task is running or waiting to run
+func (lt *LatestTask) Running() bool {
+	lt.lock.Lock()
+	defer lt.lock.Unlock()
+	return lt.running
+}
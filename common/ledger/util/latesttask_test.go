@@ -0,0 +1,44 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatestTask(t *testing.T) {
+	var lt LatestTask
+	assert.False(t, lt.Running())
+
+	release := make(chan struct{})
+	ran := make(chan int, 10)
+	lt.Run(func() {
+		<-release
+		ran <- 1
+	})
+	assert.True(t, lt.Running())
+
+	// Only the latest of the tasks submitted during a run is run after it
+	lt.Run(func() { ran <- 2 })
+	lt.Run(func() { ran <- 3 })
+	close(release)
+
+	assert.Equal(t, 1, <-ran)
+	assert.Equal(t, 3, <-ran)
+	deadline := time.Now().Add(5 * time.Second)
+	for lt.Running() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.False(t, lt.Running())
+	assert.Len(t, ran, 0)
+
+	lt.Run(func() { ran <- 4 })
+	assert.Equal(t, 4, <-ran)
+}
@@ -27,6 +27,9 @@ import (
 var dbNameKeySep = []byte{0x00}
 var lastKeyIndicator = byte(0x01)
 
+// deleteAllBatchSize is the number of keys removed per batch by DeleteAll
+const deleteAllBatchSize = 1000
+
 // Provider enables to use a single leveldb as multiple logical leveldbs
 type Provider struct {
 	db        *DB
@@ -96,6 +99,28 @@ func (h *DBHandle) WriteBatch(batch *UpdateBatch, sync bool) error {
 	return nil
 }
 
+// DeleteAll deletes all the keys of the named db. The keys are deleted in their order, a batch at a time,
+// so that a crash in between leaves only the last keys in place
+func (h *DBHandle) DeleteAll() error {
+	itr := h.GetIterator(nil, nil)
+	defer itr.Release()
+	levelBatch := &leveldb.Batch{}
+	for itr.Next() {
+		levelBatch.Delete(append([]byte{}, itr.Iterator.Key()...))
+		if levelBatch.Len() < deleteAllBatchSize {
+			continue
+		}
+		if err := h.db.WriteBatch(levelBatch, true); err != nil {
+			return err
+		}
+		levelBatch.Reset()
+	}
+	if err := itr.Error(); err != nil {
+		return err
+	}
+	return h.db.WriteBatch(levelBatch, true)
+}
+
 // GetIterator gets an handle to iterator. The iterator should be released after the use.
 // The resultset contains all the keys that are present in the db between the startKey (inclusive) and the endKey (exclusive).
 // A nil startKey represents the first available key and a nil endKey represent a logical key after the last available key
@@ -90,6 +90,32 @@ func TestBatchedUpdates(t *testing.T) {
 	}
 }
 
+func TestDeleteAll(t *testing.T) {
+	env := newTestProviderEnv(t, testDBPath)
+	defer env.cleanup()
+	p := env.provider
+
+	db1 := p.GetDBHandle("db1")
+	db2 := p.GetDBHandle("db2")
+	numKeys := 2*deleteAllBatchSize + 10
+	for i := 0; i < numKeys; i++ {
+		db1.Put([]byte(createTestKey(i)), []byte(createTestValue("db1", i)), false)
+		db2.Put([]byte(createTestKey(i)), []byte(createTestValue("db2", i)), false)
+	}
+
+	testutil.AssertNoError(t, db1.DeleteAll(), "")
+	itr1 := db1.GetIterator(nil, nil)
+	defer itr1.Release()
+	testutil.AssertEquals(t, itr1.Next(), false)
+
+	// The other dbs are left alone
+	itr2 := db2.GetIterator(nil, nil)
+	checkItrResults(t, itr2, createTestKeys(0, numKeys-1), createTestValues("db2", 0, numKeys-1))
+
+	// Deleting an empty db is a no-op
+	testutil.AssertNoError(t, db1.DeleteAll(), "")
+}
+
 func testDBBasicWriteAndReads(t *testing.T, dbNames ...string) {
 	env := newTestProviderEnv(t, testDBPath)
 	defer env.cleanup()
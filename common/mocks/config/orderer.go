@@ -27,6 +27,8 @@ import (
 type Orderer struct {
 	// ConsensusTypeVal is returned as the result of ConsensusType()
 	ConsensusTypeVal string
+	// ConsensusStateVal is returned as the result of ConsensusState()
+	ConsensusStateVal ab.ConsensusType_State
 	// BatchSizeVal is returned as the result of BatchSize()
 	BatchSizeVal *ab.BatchSize
 	// BatchTimeoutVal is returned as the result of BatchTimeout()
@@ -35,6 +37,10 @@ type Orderer struct {
 	KafkaBrokersVal []string
 	// MaxChannelsCountVal is returns as the result of MaxChannelsCount()
 	MaxChannelsCountVal uint64
+	// BatchCuttingVal is returned as the result of BatchCutting()
+	BatchCuttingVal *ab.BatchCutting
+	// RateLimitsVal is returned as the result of RateLimits()
+	RateLimitsVal *ab.RateLimits
 	// OrganizationsVal is returned as the result of Organizations()
 	OrganizationsVal map[string]config.Org
 }
@@ -44,6 +50,11 @@ func (scm *Orderer) ConsensusType() string {
 	return scm.ConsensusTypeVal
 }
 
+// ConsensusState returns the ConsensusStateVal
+func (scm *Orderer) ConsensusState() ab.ConsensusType_State {
+	return scm.ConsensusStateVal
+}
+
 // BatchSize returns the BatchSizeVal
 func (scm *Orderer) BatchSize() *ab.BatchSize {
 	return scm.BatchSizeVal
@@ -64,6 +75,16 @@ func (scm *Orderer) MaxChannelsCount() uint64 {
 	return scm.MaxChannelsCountVal
 }
 
+// BatchCutting returns the BatchCuttingVal
+func (scm *Orderer) BatchCutting() *ab.BatchCutting {
+	return scm.BatchCuttingVal
+}
+
+// RateLimits returns the RateLimitsVal
+func (scm *Orderer) RateLimits() *ab.RateLimits {
+	return scm.RateLimitsVal
+}
+
 // Organizations returns OrganizationsVal
 func (scm *Orderer) Organizations() map[string]config.Org {
 	return scm.OrganizationsVal
@@ -138,10 +138,12 @@ type Orderer struct {
 	Addresses     []string        `yaml:"Addresses"`
 	BatchTimeout  time.Duration   `yaml:"BatchTimeout"`
 	BatchSize     BatchSize       `yaml:"BatchSize"`
+	BatchCutting  BatchCutting    `yaml:"BatchCutting"`
 	Kafka         Kafka           `yaml:"Kafka"`
 	BFTsmart      BFTsmart        `yaml:"BFTsmart"` //JCS: my own options
 	Organizations []*Organization `yaml:"Organizations"`
 	MaxChannels   uint64          `yaml:"MaxChannels"`
+	RateLimits    RateLimits      `yaml:"RateLimits"`
 }
 
 // BatchSize contains configuration affecting the size of batches.
@@ -151,6 +153,29 @@ type BatchSize struct {
 	PreferredMaxBytes uint32 `yaml:"PreferredMaxBytes"`
 }
 
+// BatchCutting contains configuration selecting the strategy used to cut
+// batches, and the lower bounds of the adaptive strategy.
+type BatchCutting struct {
+	Strategy        string        `yaml:"Strategy"`
+	MinMessageCount uint32        `yaml:"MinMessageCount"`
+	MinTimeout      time.Duration `yaml:"MinTimeout"`
+}
+
+// RateLimits contains configuration for limiting the rate of the messages
+// broadcast on a channel.
+type RateLimits struct {
+	PerIdentity bool         `yaml:"PerIdentity"`
+	Limits      []*RateLimit `yaml:"Limits"`
+}
+
+// RateLimit is the token bucket limit applied to the messages of the
+// creators of an MSP, or of every MSP without a limit if MSPID is empty.
+type RateLimit struct {
+	MSPID string `yaml:"MSPID"`
+	Rate  uint32 `yaml:"Rate"`
+	Burst uint32 `yaml:"Burst"`
+}
+
 // Kafka contains configuration for the Kafka-based orderer.
 type Kafka struct {
 	Brokers []string `yaml:"Brokers"`
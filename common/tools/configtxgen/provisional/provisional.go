@@ -18,6 +18,7 @@ package provisional
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hyperledger/fabric/common/cauthdsl"
 	"github.com/hyperledger/fabric/common/config/channel"
@@ -128,6 +129,30 @@ func New(conf *genesisconfig.Profile) Generator {
 			policies.TemplateImplicitMetaMajorityPolicy([]string{config.OrdererGroupKey}, configvaluesmsp.AdminsPolicyKey),
 		}
 
+		if conf.Orderer.BatchCutting.Strategy != "" {
+			strategy, ok := ab.BatchCutting_Strategy_value[strings.ToUpper(conf.Orderer.BatchCutting.Strategy)]
+			if !ok {
+				panic(fmt.Errorf("Wrong batch cutting strategy value given: %s", conf.Orderer.BatchCutting.Strategy))
+			}
+			bs.ordererGroups = append(bs.ordererGroups, config.TemplateBatchCutting(&ab.BatchCutting{
+				Strategy:        ab.BatchCutting_Strategy(strategy),
+				MinMessageCount: conf.Orderer.BatchCutting.MinMessageCount,
+				MinTimeout:      conf.Orderer.BatchCutting.MinTimeout.String(),
+			}))
+		}
+
+		if len(conf.Orderer.RateLimits.Limits) > 0 {
+			rateLimits := &ab.RateLimits{PerIdentity: conf.Orderer.RateLimits.PerIdentity}
+			for _, limit := range conf.Orderer.RateLimits.Limits {
+				rateLimits.Limits = append(rateLimits.Limits, &ab.RateLimit{
+					MspId: limit.MSPID,
+					Rate:  limit.Rate,
+					Burst: limit.Burst,
+				})
+			}
+			bs.ordererGroups = append(bs.ordererGroups, config.TemplateRateLimits(rateLimits))
+		}
+
 		for _, org := range conf.Orderer.Organizations {
 			mspConfig, err := msp.GetVerifyingMspConfig(org.MSPDir, org.ID)
 			if err != nil {
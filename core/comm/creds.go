@@ -8,6 +8,7 @@ package comm
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"net"
 
@@ -23,6 +24,8 @@ var (
 			"not supported")
 	MissingServerConfigError = errors.New(
 		"core/comm: `serverConfig` cannot be nil")
+	MissingServerRootCAsError = errors.New(
+		"core/comm: at least one server root CA is required")
 	// alpnProtoStr are the specified application level protocols for gRPC.
 	alpnProtoStr = []string{"h2"}
 )
@@ -78,3 +81,34 @@ func (sc *serverCreds) Clone() credentials.TransportCredentials {
 func (sc *serverCreds) OverrideServerName(string) error {
 	return OverrrideHostnameNotSupportedError
 }
+
+// NewClientTLSConfig returns a *tls.Config for clients which verify servers
+// against the given PEM-encoded root CAs. If a PEM-encoded certificate and key
+// are given, the client presents them to the server for mutual TLS.
+func NewClientTLSConfig(certificate, key []byte, serverRootCAs [][]byte) (*tls.Config, error) {
+	if len(serverRootCAs) == 0 {
+		return nil, MissingServerRootCAsError
+	}
+
+	rootCAs := x509.NewCertPool()
+	for _, serverRootCA := range serverRootCAs {
+		if !rootCAs.AppendCertsFromPEM(serverRootCA) {
+			return nil, errors.New("core/comm: failed to parse server root CA")
+		}
+	}
+
+	config := &tls.Config{
+		RootCAs:    rootCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if certificate != nil || key != nil {
+		cert, err := tls.X509KeyPair(certificate, key)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
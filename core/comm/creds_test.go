@@ -8,6 +8,8 @@ package comm_test
 
 import (
 	"crypto/tls"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	"google.golang.org/grpc/credentials"
@@ -28,3 +30,41 @@ func TestCreds(t *testing.T) {
 	assert.Equal(t, "1.2", creds.Info().SecurityVersion)
 	assert.Equal(t, "tls", creds.Info().SecurityProtocol)
 }
+
+func TestNewClientTLSConfig(t *testing.T) {
+	rootCA, err := ioutil.ReadFile(filepath.Join("testdata", "certs", "Org1-cert.pem"))
+	assert.NoError(t, err)
+	cert, err := ioutil.ReadFile(filepath.Join("testdata", "certs", "Org1-client1-cert.pem"))
+	assert.NoError(t, err)
+	key, err := ioutil.ReadFile(filepath.Join("testdata", "certs", "Org1-client1-key.pem"))
+	assert.NoError(t, err)
+
+	t.Run("ServerAuth", func(t *testing.T) {
+		config, err := comm.NewClientTLSConfig(nil, nil, [][]byte{rootCA})
+		assert.NoError(t, err)
+		assert.NotNil(t, config.RootCAs)
+		assert.Empty(t, config.Certificates)
+		assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	})
+
+	t.Run("MutualAuth", func(t *testing.T) {
+		config, err := comm.NewClientTLSConfig(cert, key, [][]byte{rootCA})
+		assert.NoError(t, err)
+		assert.Len(t, config.Certificates, 1)
+	})
+
+	t.Run("MissingRootCAs", func(t *testing.T) {
+		_, err := comm.NewClientTLSConfig(cert, key, nil)
+		assert.EqualError(t, err, comm.MissingServerRootCAsError.Error())
+	})
+
+	t.Run("BadRootCA", func(t *testing.T) {
+		_, err := comm.NewClientTLSConfig(cert, key, [][]byte{[]byte("garbage")})
+		assert.Error(t, err)
+	})
+
+	t.Run("BadKeyPair", func(t *testing.T) {
+		_, err := comm.NewClientTLSConfig(cert, nil, [][]byte{rootCA})
+		assert.Error(t, err)
+	})
+}
@@ -40,6 +40,23 @@ type Committer interface {
 	// sequence number
 	GetPvtDataAndBlockByNum(seqNum uint64) (*ledger.BlockAndPvtData, error)
 
+	// GetMissingPvtData returns, the oldest blocks first starting at block fromBlockNum,
+	// up to maxEntries of the private data that was missing when its block was committed
+	GetMissingPvtData(fromBlockNum uint64, maxEntries int) ([]*ledger.MissingPvtData, error)
+
+	// GetMissingPvtDataCount returns the number of transactions, per namespace and
+	// collection, whose private data is missing
+	GetMissingPvtDataCount() (ledger.MissingPvtDataCount, error)
+
+	// CommitPvtDataOfOldBlocks commits the missing private data, keyed by block
+	// number, of already committed blocks
+	CommitPvtDataOfOldBlocks(blocksPvtData map[uint64][]*ledger.TxPvtData) error
+
+	// GetCollectionMemberOrgs returns the MSP IDs of the member organizations of
+	// the given collection of a chaincode, or nil if every organization of the
+	// channel is a member
+	GetCollectionMemberOrgs(ns, coll string) ([]string, error)
+
 	// Get recent block sequence number
 	LedgerHeight() (uint64, error)
 
@@ -19,10 +19,13 @@ package committer
 import (
 	"fmt"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core/committer/txvalidator"
+	"github.com/hyperledger/fabric/core/common/privdata"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/events/producer"
+	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
@@ -35,6 +38,9 @@ import (
 
 var logger *logging.Logger // package-level logger
 
+// lsccNamespace is the namespace under which LSCC stores the collection configurations
+const lsccNamespace = "lscc"
+
 func init() {
 	logger = flogging.MustGetLogger("committer")
 }
@@ -46,6 +52,10 @@ type LedgerCommitter struct {
 	ledger    ledger.PeerLedger
 	validator txvalidator.Validator
 	eventer   ConfigBlockEventer
+
+	// mspID is the MSP ID of the organization of the peer, which tells the
+	// collections whose private data the peer is eligible to
+	mspID string
 }
 
 // ConfigBlockEventer callback function proto type to define action
@@ -62,7 +72,11 @@ func NewLedgerCommitter(ledger ledger.PeerLedger, validator txvalidator.Validato
 // same as way as NewLedgerCommitter, while also provides an option to specify callback to
 // be called upon new configuration block arrival and commit event
 func NewLedgerCommitterReactive(ledger ledger.PeerLedger, validator txvalidator.Validator, eventer ConfigBlockEventer) *LedgerCommitter {
-	return &LedgerCommitter{ledger: ledger, validator: validator, eventer: eventer}
+	mspID, err := mspmgmt.GetLocalMSP().GetIdentifier()
+	if err != nil {
+		logger.Warningf("Could not retrieve the MSP ID of the peer, it is considered eligible to the private data of every collection: %s", err)
+	}
+	return &LedgerCommitter{ledger: ledger, validator: validator, eventer: eventer, mspID: mspID}
 }
 
 // Commit commits block to into the ledger
@@ -75,7 +89,7 @@ func (lc *LedgerCommitter) Commit(block *common.Block) error {
 	}
 
 	// Committing new block
-	if err := lc.ledger.CommitWithPvtData(&ledger.BlockAndPvtData{Block: block}); err != nil {
+	if err := lc.ledger.CommitWithPvtData(&ledger.BlockAndPvtData{Block: block, IsEligible: lc.pvtDataEligibility()}); err != nil {
 		return err
 	}
 
@@ -114,6 +128,10 @@ func (lc *LedgerCommitter) CommitWithPvtData(blockAndPvtData *ledger.BlockAndPvt
 
 	// TODO: Need to validate the hashes of private data with those in the block
 
+	if blockAndPvtData.IsEligible == nil {
+		blockAndPvtData.IsEligible = lc.pvtDataEligibility()
+	}
+
 	// Committing new block
 	if err := lc.ledger.CommitWithPvtData(blockAndPvtData); err != nil {
 		return err
@@ -131,6 +149,79 @@ func (lc *LedgerCommitter) GetPvtDataAndBlockByNum(seqNum uint64) (*ledger.Block
 	return lc.ledger.GetPvtDataAndBlockByNum(seqNum, nil)
 }
 
+// GetMissingPvtData returns up to maxEntries of the missing private data
+func (lc *LedgerCommitter) GetMissingPvtData(fromBlockNum uint64, maxEntries int) ([]*ledger.MissingPvtData, error) {
+	return lc.ledger.GetMissingPvtData(fromBlockNum, maxEntries)
+}
+
+// GetMissingPvtDataCount returns the number of transactions whose private data is missing
+func (lc *LedgerCommitter) GetMissingPvtDataCount() (ledger.MissingPvtDataCount, error) {
+	return lc.ledger.GetMissingPvtDataCount()
+}
+
+// CommitPvtDataOfOldBlocks commits the missing private data of already committed blocks
+func (lc *LedgerCommitter) CommitPvtDataOfOldBlocks(blocksPvtData map[uint64][]*ledger.TxPvtData) error {
+	return lc.ledger.CommitPvtDataOfOldBlocks(blocksPvtData)
+}
+
+// GetCollectionMemberOrgs returns the member organizations of a collection, from
+// the collection configuration of its chaincode that LSCC committed to the state
+func (lc *LedgerCommitter) GetCollectionMemberOrgs(ns, coll string) ([]string, error) {
+	qe, err := lc.ledger.NewQueryExecutor()
+	if err != nil {
+		return nil, err
+	}
+	defer qe.Done()
+
+	collectionConfigBytes, err := qe.GetState(lsccNamespace, privdata.BuildCollectionKVSKey(ns))
+	if err != nil {
+		return nil, err
+	}
+	collectionConfig := &common.CollectionConfigPackage{}
+	if err := proto.Unmarshal(collectionConfigBytes, collectionConfig); err != nil {
+		return nil, fmt.Errorf("invalid collection configuration for chaincode %s: %s", ns, err)
+	}
+	for _, collection := range collectionConfig.Config {
+		if collection.Name == coll {
+			return collection.MemberOrgs, nil
+		}
+	}
+	return nil, nil
+}
+
+// pvtDataEligibility returns the function telling the ledger whether the peer is eligible to
+// the private data of a collection, that is whether its organization is a member of the
+// collection, so that only the private data the peer is eligible to is recorded as missing.
+// The member organizations are looked up once per collection. It returns nil, making the peer
+// eligible to every collection, if the MSP ID of the peer is unknown
+func (lc *LedgerCommitter) pvtDataEligibility() ledger.PvtDataEligibility {
+	if lc.mspID == "" {
+		return nil
+	}
+	eligible := make(map[string]map[string]bool)
+	return func(ns, coll string) (bool, error) {
+		if isEligible, exists := eligible[ns][coll]; exists {
+			return isEligible, nil
+		}
+		memberOrgs, err := lc.GetCollectionMemberOrgs(ns, coll)
+		if err != nil {
+			return false, err
+		}
+		isEligible := len(memberOrgs) == 0
+		for _, org := range memberOrgs {
+			if org == lc.mspID {
+				isEligible = true
+				break
+			}
+		}
+		if eligible[ns] == nil {
+			eligible[ns] = make(map[string]bool)
+		}
+		eligible[ns][coll] = isEligible
+		return isEligible, nil
+	}
+}
+
 // postCommit publish event or handle other tasks once block committed to the ledger
 func (lc *LedgerCommitter) postCommit(block *common.Block) {
 	// send block event *after* the block has been committed
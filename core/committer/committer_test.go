@@ -25,9 +25,11 @@ import (
 	"github.com/hyperledger/fabric/common/tools/configtxgen/localconfig"
 	"github.com/hyperledger/fabric/common/tools/configtxgen/provisional"
 	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/common/privdata"
 	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
 	"github.com/hyperledger/fabric/core/mocks/validator"
 	"github.com/hyperledger/fabric/protos/common"
+	putils "github.com/hyperledger/fabric/protos/utils"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 )
@@ -107,3 +109,58 @@ func TestNewLedgerCommitterReactive(t *testing.T) {
 	committer.Commit(block)
 	assert.Equal(t, int32(1), atomic.LoadInt32(&configArrived))
 }
+
+func TestGetCollectionMemberOrgs(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/tmp/fabric/committertest")
+	ledgermgmt.InitializeTestEnv()
+	defer ledgermgmt.CleanupTestEnv()
+	gb, _ := test.MakeGenesisBlock("TestLedger")
+	ledger, err := ledgermgmt.CreateLedger(gb)
+	assert.NoError(t, err, "Error while creating ledger: %s", err)
+	defer ledger.Close()
+
+	committer := NewLedgerCommitter(ledger, &validator.MockValidator{})
+	memberOrgs, err := committer.GetCollectionMemberOrgs("mycc", "coll1")
+	assert.NoError(t, err)
+	assert.Nil(t, memberOrgs, "Expected every organization to be a member without a collection configuration")
+
+	collectionConfig := putils.MarshalOrPanic(&common.CollectionConfigPackage{Config: []*common.CollectionConfig{
+		{Name: "coll1", MemberOrgs: []string{"Org1MSP", "Org2MSP"}},
+		{Name: "coll2"},
+	}})
+	simulator, _ := ledger.NewTxSimulator(util.GenerateUUID())
+	simulator.SetState(lsccNamespace, privdata.BuildCollectionKVSKey("mycc"), collectionConfig)
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults()
+	simResBytes, _ := simRes.GetPubSimulationBytes()
+	assert.NoError(t, committer.Commit(testutil.ConstructBlock(t, 1, gb.Header.Hash(), [][]byte{simResBytes}, true)))
+
+	memberOrgs, err = committer.GetCollectionMemberOrgs("mycc", "coll1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Org1MSP", "Org2MSP"}, memberOrgs)
+
+	memberOrgs, err = committer.GetCollectionMemberOrgs("mycc", "coll2")
+	assert.NoError(t, err)
+	assert.Nil(t, memberOrgs, "Expected every organization to be a member of a collection without member organizations")
+
+	memberOrgs, err = committer.GetCollectionMemberOrgs("mycc", "coll3")
+	assert.NoError(t, err)
+	assert.Nil(t, memberOrgs)
+
+	// the peer is eligible to the collections its organization is a member of
+	committer.mspID = ""
+	assert.Nil(t, committer.pvtDataEligibility(), "Expected the peer to be eligible to every collection without an MSP ID")
+
+	for mspID, expected := range map[string]map[string]bool{
+		"Org1MSP": {"coll1": true, "coll2": true, "coll3": true},
+		"Org3MSP": {"coll1": false, "coll2": true, "coll3": true},
+	} {
+		committer.mspID = mspID
+		isEligible := committer.pvtDataEligibility()
+		for coll, expectedEligible := range expected {
+			eligible, err := isEligible("mycc", coll)
+			assert.NoError(t, err)
+			assert.Equal(t, expectedEligible, eligible, "Unexpected eligibility of %s to collection %s", mspID, coll)
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/configtx"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	coreUtil "github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
@@ -182,7 +183,8 @@ func (v *txValidator) Validate(block *common.Block) error {
 				if common.HeaderType(chdr.Type) == common.HeaderType_ENDORSER_TRANSACTION {
 					// Check duplicate transactions
 					txID := chdr.TxId
-					if _, err := v.support.Ledger().GetTransactionByID(txID); err == nil {
+					// A pruned transaction is still known, and cannot be replayed
+					if _, err := v.support.Ledger().GetTransactionByID(txID); err == nil || err == blkstorage.ErrPruned {
 						logger.Error("Duplicate transaction found, ", txID, ", skipping")
 						txsfltr.SetFlag(tIdx, peer.TxValidationCode_DUPLICATE_TXID)
 						continue
@@ -25,6 +25,7 @@ import (
 	"github.com/hyperledger/fabric/common/cauthdsl"
 	ctxt "github.com/hyperledger/fabric/common/configtx/test"
 	ledger2 "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	"github.com/hyperledger/fabric/common/mocks/scc"
 	"github.com/hyperledger/fabric/common/util"
@@ -469,11 +470,30 @@ func (m *mockLedger) PrivateDataMinBlockNum() (uint64, error) {
 	return 0, nil
 }
 
+// GetMissingPvtData returns the missing pvt data
+func (m *mockLedger) GetMissingPvtData(fromBlockNum uint64, maxEntries int) ([]*ledger.MissingPvtData, error) {
+	return nil, nil
+}
+
+// GetMissingPvtDataCount returns the count of the missing pvt data
+func (m *mockLedger) GetMissingPvtDataCount() (ledger.MissingPvtDataCount, error) {
+	return nil, nil
+}
+
+// CommitPvtDataOfOldBlocks commits the missing pvt data of old blocks
+func (m *mockLedger) CommitPvtDataOfOldBlocks(blocksPvtData map[uint64][]*ledger.TxPvtData) error {
+	return nil
+}
+
 // Prune prune using policy
 func (m *mockLedger) Prune(policy ledger2.PrunePolicy) error {
 	return nil
 }
 
+func (m *mockLedger) SubmitSnapshotRequest(blockNum uint64) error {
+	return nil
+}
+
 func (m *mockLedger) GetBlockchainInfo() (*common.BlockchainInfo, error) {
 	args := m.Called()
 	return args.Get(0).(*common.BlockchainInfo), nil
@@ -618,6 +638,22 @@ func TestValidationInvalidEndorsing(t *testing.T) {
 	assertInvalid(b, t, peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE)
 }
 
+func TestValidationPrunedTxID(t *testing.T) {
+	theLedger := new(mockLedger)
+	validator := NewTxValidator(&mockSupport{l: theLedger})
+
+	ccID := "mycc"
+	tx := getEnv(ccID, createRWset(t, ccID), t)
+
+	// The transaction ID belongs to a pruned block, so the transaction is a replay
+	theLedger.On("GetTransactionByID", mock.Anything).Return(&peer.ProcessedTransaction{}, blkstorage.ErrPruned)
+
+	b := &common.Block{Data: &common.BlockData{Data: [][]byte{utils.MarshalOrPanic(tx)}}}
+	err := validator.Validate(b)
+	assert.NoError(t, err)
+	assertInvalid(b, t, peer.TxValidationCode_DUPLICATE_TXID)
+}
+
 type ccResultCallback func() (*peer.Response, *peer.ChaincodeEvent, error)
 
 type ccExecuteChaincode struct {
@@ -0,0 +1,29 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package privdata
+
+import "strings"
+
+// collectionSeparator separates the name of a chaincode from the suffix of the
+// key under which LSCC stores the collection configuration of the chaincode
+const collectionSeparator = "~"
+
+// collectionSuffix is the suffix of the key under which LSCC stores the
+// collection configuration of a chaincode
+const collectionSuffix = "collection"
+
+// BuildCollectionKVSKey returns the key under which LSCC stores the
+// collection configuration of the given chaincode
+func BuildCollectionKVSKey(ccname string) string {
+	return ccname + collectionSeparator + collectionSuffix
+}
+
+// IsCollectionConfigKey returns whether the given LSCC key is the key of
+// a collection configuration
+func IsCollectionConfigKey(key string) bool {
+	return strings.HasSuffix(key, collectionSeparator+collectionSuffix)
+}
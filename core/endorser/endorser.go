@@ -11,6 +11,7 @@ import (
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"golang.org/x/net/context"
 
 	"errors"
@@ -420,7 +421,7 @@ func (e *Endorser) ProcessProposal(ctx context.Context, signedProp *pb.SignedPro
 		if lgr == nil {
 			return nil, fmt.Errorf("failure while looking up the ledger %s", chainID)
 		}
-		if _, err := lgr.GetTransactionByID(txid); err == nil {
+		if _, err := lgr.GetTransactionByID(txid); err == nil || err == blkstorage.ErrPruned {
 			return nil, fmt.Errorf("Duplicate transaction found [%s]. Creator [%x]. [%s]", txid, shdr.Creator, err)
 		}
 
@@ -27,6 +27,8 @@ import (
 type HistoryDBProvider interface {
 	// GetDBHandle returns a handle to a HistoryDB
 	GetDBHandle(id string) (HistoryDB, error)
+	// Drop deletes all the data of the HistoryDB with the given id, including its savepoint
+	Drop(id string) error
 	// Close closes all the HistoryDB instances and releases any resources held by HistoryDBProvider
 	Close()
 }
@@ -38,4 +40,7 @@ type HistoryDB interface {
 	GetLastSavepoint() (*version.Height, error)
 	ShouldRecover(lastAvailableBlock uint64) (bool, uint64, error)
 	CommitLostBlock(blockAndPvtdata *ledger.BlockAndPvtData) error
+	// InitSavepoint records the savepoint of a ledger created from a snapshot, whose history starts after the
+	// given height
+	InitSavepoint(height *version.Height) error
 }
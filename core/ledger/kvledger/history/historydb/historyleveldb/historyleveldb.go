@@ -17,6 +17,8 @@ limitations under the License.
 package historyleveldb
 
 import (
+	"fmt"
+
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
@@ -53,6 +55,12 @@ func (provider *HistoryDBProvider) GetDBHandle(dbName string) (historydb.History
 	return newHistoryDB(provider.dbProvider.GetDBHandle(dbName), dbName), nil
 }
 
+// Drop implements method in HistoryDBProvider interface.
+// The savepoint is the first key of the db, hence it is deleted first
+func (provider *HistoryDBProvider) Drop(dbName string) error {
+	return provider.dbProvider.GetDBHandle(dbName).DeleteAll()
+}
+
 // Close closes the underlying db
 func (provider *HistoryDBProvider) Close() {
 	provider.dbProvider.Close()
@@ -192,6 +200,18 @@ func (historyDB *historyDB) GetLastSavepoint() (*version.Height, error) {
 	return height, nil
 }
 
+// InitSavepoint implements method in HistoryDB interface
+func (historyDB *historyDB) InitSavepoint(height *version.Height) error {
+	savepoint, err := historyDB.GetLastSavepoint()
+	if err != nil {
+		return err
+	}
+	if savepoint != nil {
+		return fmt.Errorf("history database for ledger [%s] already has a savepoint", historyDB.dbName)
+	}
+	return historyDB.db.Put(savePointKey, height.ToBytes(), true)
+}
+
 // ShouldRecover implements method in interface kvledger.Recoverer
 func (historyDB *historyDB) ShouldRecover(lastAvailableBlock uint64) (bool, uint64, error) {
 	if !ledgerconfig.IsHistoryDBEnabled() {
@@ -68,16 +68,31 @@ func newHistoryScanner(compositePartialKey []byte, namespace string, key string,
 	return &historyScanner{compositePartialKey, namespace, key, dbItr, blockStore}
 }
 
+// Next returns the next history record. The records of the blocks which were
+// pruned from the block store are skipped.
 func (scanner *historyScanner) Next() (commonledger.QueryResult, error) {
-	if !scanner.dbItr.Next() {
-		return nil, nil
+	firstBlockNum, err := scanner.blockStore.GetFirstBlockNumber()
+	if err != nil {
+		return nil, err
 	}
-	historyKey := scanner.dbItr.Key() // history key is in the form namespace~key~blocknum~trannum
 
-	// SplitCompositeKey(namespace~key~blocknum~trannum, namespace~key~) will return the blocknum~trannum in second position
-	_, blockNumTranNumBytes := historydb.SplitCompositeHistoryKey(historyKey, scanner.compositePartialKey)
-	blockNum, bytesConsumed := util.DecodeOrderPreservingVarUint64(blockNumTranNumBytes[0:])
-	tranNum, _ := util.DecodeOrderPreservingVarUint64(blockNumTranNumBytes[bytesConsumed:])
+	var blockNum, tranNum uint64
+	for {
+		if !scanner.dbItr.Next() {
+			return nil, nil
+		}
+		historyKey := scanner.dbItr.Key() // history key is in the form namespace~key~blocknum~trannum
+
+		// SplitCompositeKey(namespace~key~blocknum~trannum, namespace~key~) will return the blocknum~trannum in second position
+		_, blockNumTranNumBytes := historydb.SplitCompositeHistoryKey(historyKey, scanner.compositePartialKey)
+		var bytesConsumed int
+		blockNum, bytesConsumed = util.DecodeOrderPreservingVarUint64(blockNumTranNumBytes[0:])
+		tranNum, _ = util.DecodeOrderPreservingVarUint64(blockNumTranNumBytes[bytesConsumed:])
+		if blockNum >= firstBlockNum {
+			break
+		}
+		logger.Debugf("Skipping history record for namespace:%s key:%s of pruned block %v", scanner.namespace, scanner.key, blockNum)
+	}
 	logger.Debugf("Found history record for namespace:%s key:%s at blockNumTranNum %v:%v\n",
 		scanner.namespace, scanner.key, blockNum, tranNum)
 
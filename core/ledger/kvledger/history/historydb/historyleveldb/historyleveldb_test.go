@@ -25,6 +25,7 @@ import (
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	util2 "github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
 	"github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/ledger/queryresult"
@@ -101,6 +102,39 @@ func TestSavepoint(t *testing.T) {
 	testutil.AssertEquals(t, blockNum, uint64(3))
 }
 
+func TestInitSavepoint(t *testing.T) {
+	env := newTestHistoryEnv(t)
+	defer env.cleanup()
+
+	testutil.AssertNoError(t, env.testHistoryDB.InitSavepoint(version.NewHeight(10, 2)), "")
+	savepoint, err := env.testHistoryDB.GetLastSavepoint()
+	testutil.AssertNoError(t, err, "Error upon historyDatabase.GetLastSavepoint()")
+	testutil.AssertEquals(t, savepoint, version.NewHeight(10, 2))
+	status, _, err := env.testHistoryDB.ShouldRecover(10)
+	testutil.AssertNoError(t, err, "Error upon historyDatabase.ShouldRecover()")
+	testutil.AssertEquals(t, status, false)
+
+	testutil.AssertError(t, env.testHistoryDB.InitSavepoint(version.NewHeight(12, 0)), "Expected an error for an existing savepoint")
+}
+
+func TestDrop(t *testing.T) {
+	env := newTestHistoryEnv(t)
+	defer env.cleanup()
+
+	_, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	testutil.AssertNoError(t, env.testHistoryDB.Commit(gb), "")
+	testutil.AssertNoError(t, env.testHistoryDBProvider.Drop("TestHistoryDB"), "")
+
+	// Once dropped, the history db is recovered from the first block
+	savepoint, err := env.testHistoryDB.GetLastSavepoint()
+	testutil.AssertNoError(t, err, "Error upon historyDatabase.GetLastSavepoint()")
+	testutil.AssertNil(t, savepoint)
+	status, blockNum, err := env.testHistoryDB.ShouldRecover(0)
+	testutil.AssertNoError(t, err, "Error upon historyDatabase.ShouldRecover()")
+	testutil.AssertEquals(t, status, true)
+	testutil.AssertEquals(t, blockNum, uint64(0))
+}
+
 func TestHistory(t *testing.T) {
 	env := newTestHistoryEnv(t)
 	defer env.cleanup()
@@ -61,7 +61,7 @@ func newTestHistoryEnv(t *testing.T) *levelDBLockBasedHistoryEnv {
 	testTransientStore, err := testTStoreEnv.TestStoreProvider.OpenStore(testLedgerID)
 	testutil.AssertNoError(t, err, "")
 
-	txMgr := lockbasedtxmgr.NewLockBasedTxMgr(testDB, testTransientStore)
+	txMgr := lockbasedtxmgr.NewLockBasedTxMgr(testDB, testTransientStore, nil)
 	testHistoryDBProvider := NewHistoryDBProvider()
 	testHistoryDB, err := testHistoryDBProvider.GetDBHandle("TestHistoryDB")
 	testutil.AssertNoError(t, err, "")
@@ -17,20 +17,24 @@ limitations under the License.
 package kvledger
 
 import (
-	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/flogging"
 	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	ledgerutil "github.com/hyperledger/fabric/common/ledger/util"
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/history/historydb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/pvtstatepurgemgmt"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/txmgr"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/txmgr/pvtdatatxmgr"
 	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/hyperledger/fabric/core/ledger/ledgerstorage"
+	"github.com/hyperledger/fabric/core/ledger/pvtdatastorage"
 	"github.com/hyperledger/fabric/core/transientstore"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/ledger/rwset"
@@ -40,30 +44,52 @@ import (
 
 var logger = flogging.MustGetLogger("kvledger")
 
+// recommitProgressInterval is the number of blocks after which the progress of a recovery is logged
+const recommitProgressInterval = 1000
+
 // KVLedger provides an implementation of `ledger.PeerLedger`.
 // This implementation provides a key-value based data model
 type kvLedger struct {
-	ledgerID       string
-	blockStore     *ledgerstorage.Store
-	txtmgmt        txmgr.TxMgr
-	historyDB      historydb.HistoryDB
-	transientStore transientstore.Store
+	ledgerID         string
+	blockStore       *ledgerstorage.Store
+	versionedDB      privacyenabledstate.DB
+	txtmgmt          txmgr.TxMgr
+	historyDB        historydb.HistoryDB
+	transientStore   transientstore.Store
+	pruner           ledgerutil.LatestTask
+	pruneLock        sync.Mutex
+	closed           bool
+	commitLock       sync.Mutex
+	snapshotRequests map[uint64]struct{}
+	// snapshotExports lists the blocks whose snapshot is being exported in the background
+	snapshotExports map[uint64]struct{}
+	// snapshotExportsDone is waited on by Close for the exports in the background to end
+	snapshotExportsDone sync.WaitGroup
 }
 
 // NewKVLedger constructs new `KVLedger`
 func newKVLedger(ledgerID string, blockStore *ledgerstorage.Store,
 	versionedDB privacyenabledstate.DB, historyDB historydb.HistoryDB,
-	transientStore transientstore.Store) (*kvLedger, error) {
+	transientStore transientstore.Store, purgeMgr pvtstatepurgemgmt.PurgeMgr) (*kvLedger, error) {
 
 	logger.Debugf("Creating KVLedger ledgerID=%s: ", ledgerID)
 
 	//Initialize transaction manager using state database
 	var txmgmt txmgr.TxMgr
-	txmgmt = pvtdatatxmgr.NewLockbasedTxMgr(versionedDB, transientStore)
+	txmgmt = pvtdatatxmgr.NewLockbasedTxMgr(versionedDB, transientStore, purgeMgr)
 
 	// Create a kvLedger for this chain/ledger, which encasulates the underlying
 	// id store, blockstore, txmgr (state database), history database
-	l := &kvLedger{ledgerID, blockStore, txmgmt, historyDB, transientStore}
+	l := &kvLedger{
+		ledgerID:         ledgerID,
+		blockStore:       blockStore,
+		versionedDB:      versionedDB,
+		txtmgmt:          txmgmt,
+		historyDB:        historyDB,
+		transientStore:   transientStore,
+		snapshotRequests: make(map[uint64]struct{}),
+		snapshotExports:  make(map[uint64]struct{}),
+	}
 
 	//Recover both state DB and history DB if they are out of sync with block storage
 	if err := l.recoverDBs(); err != nil {
@@ -119,20 +145,35 @@ func (l *kvLedger) recoverDBs() error {
 		recoverers[0].recoverable, recoverers[1].recoverable)
 }
 
-//recommitLostBlocks retrieves blocks in specified range and commit the write set to either
-//state DB or history DB or both
+//recommitLostBlocks retrieves blocks, along with their pvt data, in specified range and commit
+//the write set to either state DB or history DB or both
 func (l *kvLedger) recommitLostBlocks(firstBlockNum uint64, lastBlockNum uint64, recoverables ...recoverable) error {
 	var err error
 	var block *common.Block
+	var pvtdata []*ledger.TxPvtData
+	logger.Infof("Recommitting blocks [%d] to [%d] of ledger [%s]", firstBlockNum, lastBlockNum, l.ledgerID)
 	for blockNumber := firstBlockNum; blockNumber <= lastBlockNum; blockNumber++ {
 		if block, err = l.GetBlockByNumber(blockNumber); err != nil {
 			return err
 		}
+		// a block may have been added to the block store without its pvt data
+		if pvtdata, err = l.blockStore.GetPvtDataByNum(blockNumber, nil); err != nil {
+			if _, ok := err.(*pvtdatastorage.ErrOutOfRange); !ok {
+				return err
+			}
+		}
+		blockAndPvtdata := &ledger.BlockAndPvtData{Block: block, BlockPvtData: make(map[uint64]*ledger.TxPvtData)}
+		for _, txPvtdata := range pvtdata {
+			blockAndPvtdata.BlockPvtData[txPvtdata.SeqInBlock] = txPvtdata
+		}
 		for _, r := range recoverables {
-			if err := r.CommitLostBlock(&ledger.BlockAndPvtData{Block: block}); err != nil {
+			if err := r.CommitLostBlock(blockAndPvtdata); err != nil {
 				return err
 			}
 		}
+		if (blockNumber+1-firstBlockNum)%recommitProgressInterval == 0 {
+			logger.Infof("Recommitted blocks [%d] to [%d] of ledger [%s]", firstBlockNum, blockNumber, l.ledgerID)
+		}
 	}
 	return nil
 }
@@ -166,7 +207,15 @@ func (l *kvLedger) GetBlockByNumber(blockNumber uint64) (*common.Block, error) {
 // GetBlocksIterator returns an iterator that starts from `startBlockNumber`(inclusive).
 // The iterator is a blocking iterator i.e., it blocks till the next block gets available in the ledger
 // ResultsIterator contains type BlockHolder
+// An iterator cannot start below the blocks left by pruning
 func (l *kvLedger) GetBlocksIterator(startBlockNumber uint64) (commonledger.ResultsIterator, error) {
+	first, err := l.blockStore.GetFirstBlockNumber()
+	if err != nil {
+		return nil, err
+	}
+	if startBlockNumber < first {
+		return nil, blkstorage.ErrPruned
+	}
 	return l.blockStore.RetrieveBlocks(startBlockNumber)
 
 }
@@ -185,11 +234,6 @@ func (l *kvLedger) GetTxValidationCodeByTxID(txID string) (peer.TxValidationCode
 	return l.blockStore.RetrieveTxValidationCodeByTxID(txID)
 }
 
-//Prune prunes the blocks/transactions that satisfy the given policy
-func (l *kvLedger) Prune(policy commonledger.PrunePolicy) error {
-	return errors.New("Not yet implemented")
-}
-
 // NewTxSimulator returns new `ledger.TxSimulator`
 func (l *kvLedger) NewTxSimulator(txid string) (ledger.TxSimulator, error) {
 	return l.txtmgmt.NewTxSimulator(txid)
@@ -223,6 +267,8 @@ func (l *kvLedger) Commit(block *common.Block) error {
 
 // CommitWithPvtData commits the block and the corresponding pvt data in an atomic operation
 func (l *kvLedger) CommitWithPvtData(pvtdataAndBlock *ledger.BlockAndPvtData) error {
+	l.commitLock.Lock()
+	defer l.commitLock.Unlock()
 	var err error
 	block := pvtdataAndBlock.Block
 	blockNo := pvtdataAndBlock.Block.Header.Number
@@ -251,6 +297,9 @@ func (l *kvLedger) CommitWithPvtData(pvtdataAndBlock *ledger.BlockAndPvtData) er
 			panic(fmt.Errorf(`Error during commit to history db:%s`, err))
 		}
 	}
+
+	l.exportSnapshotIfRequested(blockNo)
+	l.startPrune(block)
 	return nil
 }
 
@@ -269,17 +318,22 @@ func (l *kvLedger) GetPvtDataByNum(blockNum uint64, filter ledger.PvtNsCollFilte
 // Purge removes private read-writes set generated by endorsers at block height lesser than
 // a given maxBlockNumToRetain. In other words, Purge only retains private read-write sets
 // that were generated at block height of maxBlockNumToRetain or higher.
+// The pvt data committed with the blocks expires as per the block-to-live of its collection instead
 func (l *kvLedger) PurgePrivateData(maxBlockNumToRetain uint64) error {
-	return fmt.Errorf("not yet implemented")
+	return l.transientStore.Purge(maxBlockNumToRetain)
 }
 
 // PrivateDataMinBlockNum returns the lowest retained endorsement block height
 func (l *kvLedger) PrivateDataMinBlockNum() (uint64, error) {
-	return 0, fmt.Errorf("not yet implemented")
+	return l.transientStore.GetMinEndorsementBlkHt()
 }
 
 // Close closes `KVLedger`
 func (l *kvLedger) Close() {
+	l.pruneLock.Lock()
+	l.closed = true
+	l.pruneLock.Unlock()
+	l.snapshotExportsDone.Wait()
 	l.blockStore.Shutdown()
 	l.txtmgmt.Shutdown()
 	l.transientStore.Shutdown()
@@ -27,8 +27,10 @@ import (
 	"github.com/hyperledger/fabric/core/ledger/kvledger/history/historydb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/history/historydb/historyleveldb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/pvtstatepurgemgmt"
 	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/hyperledger/fabric/core/ledger/ledgerstorage"
+	"github.com/hyperledger/fabric/core/ledger/pvtdatapolicy"
 	"github.com/hyperledger/fabric/core/transientstore"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
@@ -54,6 +56,7 @@ type Provider struct {
 	vdbProvider            privacyenabledstate.DBProvider
 	historydbProvider      historydb.HistoryDBProvider
 	transientStoreProvider transientstore.StoreProvider
+	bookkeepingProvider    *leveldbhelper.Provider
 }
 
 // NewProvider instantiates a new Provider.
@@ -79,8 +82,11 @@ func NewProvider() (ledger.PeerLedgerProvider, error) {
 	var historydbProvider historydb.HistoryDBProvider
 	historydbProvider = historyleveldb.NewHistoryDBProvider()
 
+	// Initialize the bookkeeping database (expiry of the pvt data in the state database)
+	bookkeepingProvider := leveldbhelper.NewProvider(&leveldbhelper.Conf{DBPath: ledgerconfig.GetInternalBookkeeperPath()})
+
 	logger.Info("ledger provider Initialized")
-	provider := &Provider{idStore, ledgerStoreProvider, vdbProvider, historydbProvider, transientStoreProvider}
+	provider := &Provider{idStore, ledgerStoreProvider, vdbProvider, historydbProvider, transientStoreProvider, bookkeepingProvider}
 	provider.recoverUnderConstructionLedger()
 	return provider, nil
 }
@@ -147,6 +153,13 @@ func (provider *Provider) openInternal(ledgerID string) (ledger.PeerLedger, erro
 		return nil, err
 	}
 
+	// The pvt data expires as per the collection configurations in the state database
+	btlPolicy := pvtdatapolicy.NewBTLPolicy(vDB)
+	if err := blockStore.Init(btlPolicy); err != nil {
+		return nil, err
+	}
+	purgeMgr := pvtstatepurgemgmt.NewPurgeMgr(vDB, btlPolicy, provider.bookkeepingProvider.GetDBHandle(ledgerID))
+
 	// Get the history database (index for history of values by key) for a chain/ledger
 	historyDB, err := provider.historydbProvider.GetDBHandle(ledgerID)
 	if err != nil {
@@ -161,7 +174,7 @@ func (provider *Provider) openInternal(ledgerID string) (ledger.PeerLedger, erro
 
 	// Create a kvLedger for this chain/ledger, which encasulates the underlying data stores
 	// (id store, blockstore, state database, history database)
-	l, err := newKVLedger(ledgerID, blockStore, vDB, historyDB, transientStore)
+	l, err := newKVLedger(ledgerID, blockStore, vDB, historyDB, transientStore, purgeMgr)
 	if err != nil {
 		return nil, err
 	}
@@ -185,6 +198,7 @@ func (provider *Provider) Close() {
 	provider.vdbProvider.Close()
 	provider.historydbProvider.Close()
 	provider.transientStoreProvider.Close()
+	provider.bookkeepingProvider.Close()
 }
 
 // recoverUnderConstructionLedger checks whether the under construction flag is set - this would be the case
@@ -204,6 +218,8 @@ func (provider *Provider) recoverUnderConstructionLedger() {
 	panicOnErr(err, "Error while opening under construction ledger [%s]", ledgerID)
 	bcInfo, err := ledger.GetBlockchainInfo()
 	panicOnErr(err, "Error while getting blockchain info for the under construction ledger [%s]", ledgerID)
+	firstBlockNum, err := ledger.(*kvLedger).blockStore.GetFirstBlockNumber()
+	panicOnErr(err, "Error while getting the first block number for the under construction ledger [%s]", ledgerID)
 	ledger.Close()
 
 	switch bcInfo.Height {
@@ -217,6 +233,17 @@ func (provider *Provider) recoverUnderConstructionLedger() {
 		panicOnErr(err, "Error while retrieving genesis block from blockchain for ledger [%s]", ledgerID)
 		panicOnErr(provider.idStore.createLedgerID(ledgerID, genesisBlock), "Error while adding ledgerID [%s] to created list", ledgerID)
 	default:
+		if firstBlockNum == bcInfo.Height {
+			logger.Infof("Block store was bootstrapped from a snapshot. Hence, marking the peer ledger as created")
+			lastBlock, err := ledger.GetBlockByNumber(bcInfo.Height - 1)
+			panicOnErr(err, "Error while retrieving last block from blockchain for ledger [%s]", ledgerID)
+			configIndex, err := utils.GetLastConfigIndexFromBlock(lastBlock)
+			panicOnErr(err, "Error while retrieving last config index for ledger [%s]", ledgerID)
+			configBlock, err := ledger.GetBlockByNumber(configIndex)
+			panicOnErr(err, "Error while retrieving config block from blockchain for ledger [%s]", ledgerID)
+			panicOnErr(provider.idStore.createLedgerID(ledgerID, configBlock), "Error while adding ledgerID [%s] to created list", ledgerID)
+			return
+		}
 		panic(fmt.Errorf(
 			"Data inconsistency: under construction flag is set for ledger [%s] while the height of the blockchain is [%d]",
 			ledgerID, bcInfo.Height))
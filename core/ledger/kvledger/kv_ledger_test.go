@@ -188,6 +188,137 @@ func TestKVLedgerBlockStorageWithPvtdata(t *testing.T) {
 	testutil.AssertNil(t, pvtdataAndBlock.BlockPvtData)
 }
 
+func TestKVLedgerPvtdataExpiry(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	defer provider.Close()
+
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, _ := provider.Create(gb)
+	defer ledger.Close()
+
+	commitTx := func(simulate func(lgr.TxSimulator)) {
+		txid := util.GenerateUUID()
+		simulator, _ := ledger.NewTxSimulator(txid)
+		simulate(simulator)
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		pubSimBytes, _ := simRes.GetPubSimulationBytes()
+		testutil.AssertNoError(t, ledger.Commit(bg.NextBlockWithTxid([][]byte{pubSimBytes}, []string{txid})), "")
+	}
+	assertPvtdata := func(coll string, expected bool) {
+		pvtdata, err := ledger.GetPvtDataByNum(2, nil)
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, len(pvtdata) == 1 && pvtdata[0].Has("ns1", coll), expected)
+		qe, _ := ledger.NewQueryExecutor()
+		defer qe.Done()
+		value, err := qe.GetPrivateData("ns1", coll, "key2")
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, value != nil, expected)
+	}
+
+	// block 1 configures a BTL of 1 for ns1/coll1, block 2 commits pvt data
+	collectionConfig := &common.CollectionConfigPackage{Config: []*common.CollectionConfig{{Name: "coll1", BlockToLive: 1}}}
+	commitTx(func(simulator lgr.TxSimulator) {
+		simulator.SetState("lscc", "ns1~collection", putils.MarshalOrPanic(collectionConfig))
+	})
+	commitTx(func(simulator lgr.TxSimulator) {
+		simulator.SetPrivateData("ns1", "coll1", "key2", []byte("value2"))
+		simulator.SetPrivateData("ns1", "coll2", "key2", []byte("value3"))
+	})
+
+	commitTx(func(simulator lgr.TxSimulator) { simulator.SetState("ns1", "key1", []byte("value1")) })
+	assertPvtdata("coll1", true)
+	assertPvtdata("coll2", true)
+
+	// the pvt data of ns1/coll1 expires by block 4
+	commitTx(func(simulator lgr.TxSimulator) { simulator.SetState("ns1", "key1", []byte("value4")) })
+	assertPvtdata("coll1", false)
+	assertPvtdata("coll2", true)
+}
+
+func TestKVLedgerCommitPvtDataOfOldBlocks(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	defer provider.Close()
+
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, _ := provider.Create(gb)
+	defer ledger.Close()
+
+	simulate := func(coll1Value, coll2Value string) *lgr.TxSimulationResults {
+		simulator, _ := ledger.NewTxSimulator(util.GenerateUUID())
+		simulator.SetPrivateData("ns1", "coll1", "key1", []byte(coll1Value))
+		simulator.SetPrivateData("ns1", "coll2", "key1", []byte(coll2Value))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		return simRes
+	}
+	assertPvtValue := func(coll string, expected []byte) {
+		qe, _ := ledger.NewQueryExecutor()
+		defer qe.Done()
+		value, err := qe.GetPrivateData("ns1", coll, "key1")
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, value, expected)
+	}
+
+	// block 1 is committed without its pvt data
+	simRes := simulate("value1", "value2")
+	pubSimBytes, _ := simRes.GetPubSimulationBytes()
+	block1 := bg.NextBlock([][]byte{pubSimBytes})
+	testutil.AssertNoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block1}), "")
+	missing, err := ledger.GetMissingPvtData(0, 0)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, missing, []*lgr.MissingPvtData{
+		{BlockNum: 1, SeqInBlock: 0, Namespace: "ns1", Collection: "coll1"},
+		{BlockNum: 1, SeqInBlock: 0, Namespace: "ns1", Collection: "coll2"},
+	})
+	assertPvtValue("coll1", nil)
+
+	// the pvt data of coll2 does not match with its hash in the block, and is dropped
+	tamperedSimRes := simulate("value1", "tampered-value2")
+	testutil.AssertNoError(t, ledger.CommitPvtDataOfOldBlocks(map[uint64][]*lgr.TxPvtData{
+		1: {{SeqInBlock: 0, WriteSet: tamperedSimRes.PvtSimulationResults}},
+	}), "")
+	assertPvtValue("coll1", []byte("value1"))
+	assertPvtValue("coll2", nil)
+	count, err := ledger.GetMissingPvtDataCount()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, count, lgr.MissingPvtDataCount{"ns1": {"coll2": 1}})
+
+	// a later write is not overwritten by the pvt data of the old block
+	simRes = simulate("value3", "value4")
+	pubSimBytes, _ = simRes.GetPubSimulationBytes()
+	block2 := bg.NextBlock([][]byte{pubSimBytes})
+	testutil.AssertNoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block2,
+		BlockPvtData: map[uint64]*lgr.TxPvtData{0: {SeqInBlock: 0, WriteSet: simRes.PvtSimulationResults}}}), "")
+	oldSimRes := simulate("value1", "value2")
+	testutil.AssertNoError(t, ledger.CommitPvtDataOfOldBlocks(map[uint64][]*lgr.TxPvtData{
+		1: {{SeqInBlock: 0, WriteSet: oldSimRes.PvtSimulationResults}},
+	}), "")
+	assertPvtValue("coll2", []byte("value4"))
+	missing, err = ledger.GetMissingPvtData(0, 0)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, len(missing), 0)
+	pvtdata, err := ledger.GetPvtDataByNum(1, nil)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, len(pvtdata) == 1 && pvtdata[0].Has("ns1", "coll1") && pvtdata[0].Has("ns1", "coll2"), true)
+
+	// the collections the peer is not eligible to are not recorded as missing
+	simRes = simulate("value5", "value6")
+	pubSimBytes, _ = simRes.GetPubSimulationBytes()
+	block3 := bg.NextBlock([][]byte{pubSimBytes})
+	testutil.AssertNoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block3,
+		IsEligible: func(ns, coll string) (bool, error) { return coll == "coll1", nil }}), "")
+	missing, err = ledger.GetMissingPvtData(0, 0)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, missing, []*lgr.MissingPvtData{
+		{BlockNum: 3, SeqInBlock: 0, Namespace: "ns1", Collection: "coll1"},
+	})
+}
+
 func TestKVLedgerDBRecovery(t *testing.T) {
 	ledgertestutil.SetupCoreYAMLConfig()
 	env := newTestEnv(t)
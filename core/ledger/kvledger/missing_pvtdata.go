@@ -0,0 +1,126 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"bytes"
+
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/rwset"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// GetMissingPvtData returns, the oldest blocks first starting at block fromBlockNum, up to maxEntries of the missing pvt data
+func (l *kvLedger) GetMissingPvtData(fromBlockNum uint64, maxEntries int) ([]*ledger.MissingPvtData, error) {
+	return l.blockStore.GetMissingPvtData(fromBlockNum, maxEntries)
+}
+
+// GetMissingPvtDataCount returns the number of transactions, per namespace and collection, whose pvt data is missing
+func (l *kvLedger) GetMissingPvtDataCount() (ledger.MissingPvtDataCount, error) {
+	return l.blockStore.GetMissingPvtDataCount()
+}
+
+// CommitPvtDataOfOldBlocks commits the pvt data that was missing when the blocks were committed.
+// The collections whose hash does not match with the one present in the public read-write set are dropped.
+// The state is updated before the pvt data store, so that a crash in between leaves the pvt data recorded as
+// missing and it is committed again
+func (l *kvLedger) CommitPvtDataOfOldBlocks(blocksPvtData map[uint64][]*ledger.TxPvtData) error {
+	l.commitLock.Lock()
+	defer l.commitLock.Unlock()
+
+	verifiedPvtData := make(map[uint64][]*ledger.TxPvtData)
+	for blockNum, blockPvtData := range blocksPvtData {
+		block, err := l.blockStore.RetrieveBlockByNumber(blockNum)
+		if err != nil {
+			return err
+		}
+		for _, txPvtData := range blockPvtData {
+			verified, err := verifyPvtData(block, txPvtData)
+			if err != nil {
+				return err
+			}
+			if verified != nil {
+				verifiedPvtData[blockNum] = append(verifiedPvtData[blockNum], verified)
+			}
+		}
+	}
+	if len(verifiedPvtData) == 0 {
+		return nil
+	}
+
+	logger.Debugf("Channel [%s]: Committing pvt data of [%d] old block(s) to state database", l.ledgerID, len(verifiedPvtData))
+	if err := l.txtmgmt.CommitPvtDataOfOldBlocks(verifiedPvtData); err != nil {
+		return err
+	}
+	return l.blockStore.CommitPvtDataOfOldBlocks(verifiedPvtData)
+}
+
+// verifyPvtData returns the pvt data trimmed to the collections whose hash matches with the one present in the
+// public read-write set of the transaction, or nil if there is none. The pvt data of an invalid transaction is dropped
+func verifyPvtData(block *common.Block, txPvtData *ledger.TxPvtData) (*ledger.TxPvtData, error) {
+	blockNum := block.Header.Number
+	if txPvtData.WriteSet == nil || txPvtData.SeqInBlock >= uint64(len(block.Data.Data)) {
+		logger.Warningf("Ignoring pvt data of the transaction tx num [%d] not present in block [%d]", txPvtData.SeqInBlock, blockNum)
+		return nil, nil
+	}
+	txsFilter := util.TxValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	if txsFilter.IsInvalid(int(txPvtData.SeqInBlock)) {
+		logger.Debugf("Ignoring pvt data of the invalid transaction tx num [%d] of block [%d]", txPvtData.SeqInBlock, blockNum)
+		return nil, nil
+	}
+	respPayload, err := utils.GetActionFromEnvelope(block.Data.Data[txPvtData.SeqInBlock])
+	if err != nil {
+		return nil, err
+	}
+	txRWSet := &rwsetutil.TxRwSet{}
+	if err := txRWSet.FromProtoBytes(respPayload.Results); err != nil {
+		return nil, err
+	}
+
+	var verifiedNsPvtRwSets []*rwset.NsPvtReadWriteSet
+	for _, nsPvtRwSet := range txPvtData.WriteSet.NsPvtRwset {
+		var verifiedCollPvtRwSets []*rwset.CollectionPvtReadWriteSet
+		for _, collPvtRwSet := range nsPvtRwSet.CollectionPvtRwset {
+			hashInPubdata := retrievePvtRwSetHash(txRWSet, nsPvtRwSet.Namespace, collPvtRwSet.CollectionName)
+			if hashInPubdata == nil || !bytes.Equal(util.ComputeHash(collPvtRwSet.Rwset), hashInPubdata) {
+				logger.Warningf("Hash of pvt data for collection [%s:%s] of the transaction tx num [%d] of block [%d] "+
+					"does not match with the corresponding hash in the public data, ignoring it",
+					nsPvtRwSet.Namespace, collPvtRwSet.CollectionName, txPvtData.SeqInBlock, blockNum)
+				continue
+			}
+			verifiedCollPvtRwSets = append(verifiedCollPvtRwSets, collPvtRwSet)
+		}
+		if verifiedCollPvtRwSets != nil {
+			verifiedNsPvtRwSets = append(verifiedNsPvtRwSets,
+				&rwset.NsPvtReadWriteSet{Namespace: nsPvtRwSet.Namespace, CollectionPvtRwset: verifiedCollPvtRwSets})
+		}
+	}
+	if verifiedNsPvtRwSets == nil {
+		return nil, nil
+	}
+	return &ledger.TxPvtData{
+		SeqInBlock: txPvtData.SeqInBlock,
+		WriteSet:   &rwset.TxPvtReadWriteSet{DataModel: txPvtData.WriteSet.DataModel, NsPvtRwset: verifiedNsPvtRwSets},
+	}, nil
+}
+
+func retrievePvtRwSetHash(txRWSet *rwsetutil.TxRwSet, ns string, coll string) []byte {
+	for _, nsRwSet := range txRWSet.NsRwSets {
+		if nsRwSet.NameSpace != ns {
+			continue
+		}
+		for _, collHashedRwSet := range nsRwSet.CollHashedRwSets {
+			if collHashedRwSet.CollectionName == coll {
+				return collHashedRwSet.PvtRwSetHash
+			}
+		}
+	}
+	return nil
+}
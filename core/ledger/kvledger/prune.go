@@ -0,0 +1,155 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	commonledger "github.com/hyperledger/fabric/common/ledger"
+	ledgerutil "github.com/hyperledger/fabric/common/ledger/util"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// Prune prunes the blocks which the given policy, a ledger.BlockPrunePolicy, no longer requires
+func (l *kvLedger) Prune(policy commonledger.PrunePolicy) error {
+	var p ledger.BlockPrunePolicy
+	switch policy := policy.(type) {
+	case ledger.BlockPrunePolicy:
+		p = policy
+	case *ledger.BlockPrunePolicy:
+		p = *policy
+	default:
+		return fmt.Errorf("unsupported prune policy of type %T", policy)
+	}
+
+	l.pruneLock.Lock()
+	defer l.pruneLock.Unlock()
+	if l.closed {
+		return errors.New("ledger is closed")
+	}
+	return l.prune(p, time.Now())
+}
+
+// startPrune prunes the ledger in the background according to the configured
+// block retention. Blocks committed while a prune is in progress get pruned as
+// soon as it ends.
+func (l *kvLedger) startPrune(lastBlock *common.Block) {
+	policy, enabled := configuredPrunePolicy(lastBlock.Header.Number + 1)
+	if !enabled {
+		return
+	}
+	l.pruner.Run(func() {
+		if err := l.Prune(policy); err != nil {
+			logger.Warningf("Channel [%s]: Failed pruning the ledger: %s", l.ledgerID, err)
+		}
+	})
+}
+
+// configuredPrunePolicy returns the prune policy which enforces the configured
+// block retention on a ledger of the given height, and whether there is any
+func configuredPrunePolicy(height uint64) (ledger.BlockPrunePolicy, bool) {
+	policy := ledger.BlockPrunePolicy{
+		Age:        ledgerconfig.GetBlockRetentionAge(),
+		ArchiveDir: ledgerconfig.GetBlockArchivePath(),
+	}
+	blocks := ledgerconfig.GetBlockRetentionBlocks()
+	if blocks > 0 && height > blocks {
+		policy.Height = height - blocks
+	}
+	return policy, blocks > 0 || policy.Age > 0
+}
+
+// prune removes the blocks the policy no longer requires as of the given time.
+// The pruned block files are archived in a directory of the ledger under the
+// archive dir of the policy.
+func (l *kvLedger) prune(policy ledger.BlockPrunePolicy, now time.Time) error {
+	info, err := l.blockStore.GetBlockchainInfo()
+	if err != nil {
+		return err
+	}
+	if info.Height == 0 {
+		return nil
+	}
+	lastBlock, err := l.blockStore.RetrieveBlockByNumber(info.Height - 1)
+	if err != nil {
+		return err
+	}
+	retain, err := retainedBlocks(lastBlock)
+	if err != nil {
+		return err
+	}
+	boundary, err := l.pruneBoundary(policy, info.Height, now)
+	if err != nil {
+		return err
+	}
+
+	archiveDir := ""
+	if policy.ArchiveDir != "" {
+		archiveDir = filepath.Join(policy.ArchiveDir, l.ledgerID)
+	}
+	logger.Debugf("Channel [%s]: Pruning blocks below [%d]", l.ledgerID, boundary)
+	return l.blockStore.Prune(boundary, retain, archiveDir)
+}
+
+// pruneBoundary returns the number of the oldest block the policy requires a
+// ledger of the given height to keep. The last block is always kept, as are the
+// blocks which the state and history databases would need to recover.
+func (l *kvLedger) pruneBoundary(policy ledger.BlockPrunePolicy, height uint64, now time.Time) (uint64, error) {
+	first, err := l.blockStore.GetFirstBlockNumber()
+	if err != nil {
+		return 0, err
+	}
+	lastBlockNum := height - 1
+
+	boundary := first
+	if policy.Height > boundary {
+		boundary = policy.Height
+	}
+	if policy.Age > 0 {
+		aged, err := ledgerutil.AgeBoundary(first, lastBlockNum, now.Add(-policy.Age), l.blockStore.RetrieveBlockByNumber)
+		if err != nil {
+			return 0, err
+		}
+		if aged > boundary {
+			boundary = aged
+		}
+	}
+	if boundary > lastBlockNum {
+		boundary = lastBlockNum
+	}
+
+	for _, r := range []recoverable{l.txtmgmt, l.historyDB} {
+		recoverFlag, firstBlockNum, err := r.ShouldRecover(lastBlockNum)
+		if err != nil {
+			return 0, err
+		}
+		if recoverFlag && firstBlockNum < boundary {
+			boundary = firstBlockNum
+		}
+	}
+	return boundary, nil
+}
+
+// retainedBlocks returns the numbers of the blocks which are kept whatever the
+// policy: the genesis block and the latest config block, which the peer reads
+// to join the channel again on restart
+func retainedBlocks(lastBlock *common.Block) ([]uint64, error) {
+	index, err := utils.GetLastConfigIndexFromBlock(lastBlock)
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine the latest config block: %s", err)
+	}
+	if index == 0 {
+		return []uint64{0}, nil
+	}
+	return []uint64{0, index}, nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	lgr "github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPruneBoundary(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	defer provider.Close()
+
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+	defer ledger.Close()
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, ledger.Commit(bg.NextBlock([][]byte{[]byte("tx")})))
+	}
+	l := ledger.(*kvLedger)
+	now := time.Now()
+
+	for _, test := range []struct {
+		name     string
+		policy   lgr.BlockPrunePolicy
+		now      time.Time
+		boundary uint64
+	}{
+		{"NoLimit", lgr.BlockPrunePolicy{}, now, 0},
+		{"Height", lgr.BlockPrunePolicy{Height: 3}, now, 3},
+		{"HeightAboveLastBlock", lgr.BlockPrunePolicy{Height: 100}, now, 4},
+		{"RecentBlocks", lgr.BlockPrunePolicy{Age: time.Hour}, now, 0},
+		{"OldBlocks", lgr.BlockPrunePolicy{Age: time.Hour}, now.Add(2 * time.Hour), 4},
+		{"EitherLimit", lgr.BlockPrunePolicy{Height: 2, Age: time.Hour}, now, 2},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			boundary, err := l.pruneBoundary(test.policy, 5, test.now)
+			assert.NoError(t, err)
+			assert.Equal(t, test.boundary, boundary)
+		})
+	}
+
+	// Nothing is pruned as long as all the blocks share the first block file
+	assert.NoError(t, ledger.Prune(&lgr.BlockPrunePolicy{Height: 4}))
+	block, err := ledger.GetBlockByNumber(1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), block.Header.Number)
+	itr, err := ledger.GetBlocksIterator(1)
+	assert.NoError(t, err)
+	itr.Close()
+
+	assert.Error(t, ledger.Prune("bad policy"))
+}
+
+func TestPruneBoundaryBackdatedTx(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	defer provider.Close()
+
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	backdate(t, gb, old)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+	defer ledger.Close()
+	// Blocks 0 to 2 are old, block 3 is recent and block 4 carries a
+	// transaction back-dated by its client
+	for i := 1; i <= 5; i++ {
+		block := bg.NextBlock([][]byte{[]byte("tx")})
+		if i <= 2 || i == 4 {
+			backdate(t, block, old)
+		}
+		assert.NoError(t, ledger.Commit(block))
+	}
+
+	boundary, err := ledger.(*kvLedger).pruneBoundary(lgr.BlockPrunePolicy{Age: time.Hour}, 6, now)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), boundary)
+}
+
+// backdate sets the timestamp of all the transactions of the block
+func backdate(t *testing.T, block *common.Block, ts time.Time) {
+	for i, data := range block.Data.Data {
+		env, err := utils.GetEnvelopeFromBlock(data)
+		assert.NoError(t, err)
+		payload, err := utils.GetPayload(env)
+		assert.NoError(t, err)
+		chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+		assert.NoError(t, err)
+		chdr.Timestamp = &timestamp.Timestamp{Seconds: ts.Unix(), Nanos: int32(ts.Nanosecond())}
+		payload.Header.ChannelHeader = utils.MarshalOrPanic(chdr)
+		env.Payload = utils.MarshalOrPanic(payload)
+		block.Data.Data[i] = utils.MarshalOrPanic(env)
+	}
+}
+
+func TestConfiguredPrunePolicy(t *testing.T) {
+	defer viper.Set("ledger.blockchain.retention", nil)
+
+	_, enabled := configuredPrunePolicy(10)
+	assert.False(t, enabled)
+
+	viper.Set("ledger.blockchain.retention.blocks", 4)
+	policy, enabled := configuredPrunePolicy(10)
+	assert.True(t, enabled)
+	assert.Equal(t, lgr.BlockPrunePolicy{Height: 6}, policy)
+	policy, _ = configuredPrunePolicy(3)
+	assert.Equal(t, uint64(0), policy.Height)
+
+	viper.Set("ledger.blockchain.retention.age", "1h")
+	viper.Set("ledger.blockchain.retention.archiveDir", "/tmp/archive")
+	policy, _ = configuredPrunePolicy(10)
+	assert.Equal(t, lgr.BlockPrunePolicy{Height: 6, Age: time.Hour, ArchiveDir: "/tmp/archive"}, policy)
+}
@@ -0,0 +1,198 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+)
+
+// verifyProgressInterval is the number of blocks after which the progress of a verification is logged
+const verifyProgressInterval = 1000
+
+// RollbackLedger removes the blocks after blockNum from a ledger, along with their pvt data, and rebuilds
+// the state and history databases of the ledger up to blockNum. The peer is expected to be stopped.
+// The databases are dropped before any block is removed, so that a rollback which is interrupted
+// leaves them to be rebuilt. An interrupted rollback is completed by running it again
+func RollbackLedger(ledgerID string, blockNum uint64) error {
+	provider, err := openProviderOffline(ledgerID)
+	if err != nil {
+		return err
+	}
+	defer provider.Close()
+	bcInfo, err := provider.checkRebuildable(ledgerID)
+	if err != nil {
+		return err
+	}
+	if blockNum >= bcInfo.Height {
+		return fmt.Errorf("cannot roll back ledger [%s] to block [%d], the height of the ledger is [%d]", ledgerID, blockNum, bcInfo.Height)
+	}
+	if err := provider.dropDBs(ledgerID); err != nil {
+		return err
+	}
+	logger.Infof("Rolling back the block store of ledger [%s] from block [%d] to block [%d]", ledgerID, bcInfo.Height-1, blockNum)
+	if err := provider.ledgerStoreProvider.Rollback(ledgerID, blockNum); err != nil {
+		return err
+	}
+	return provider.rebuildDBs(ledgerID)
+}
+
+// RebuildDBs drops the state and history databases of a ledger and rebuilds them from the blocks,
+// and their pvt data, in the block store. The peer is expected to be stopped. An interrupted rebuild
+// is completed when the ledger is opened, or by running it again
+func RebuildDBs(ledgerID string) error {
+	provider, err := openProviderOffline(ledgerID)
+	if err != nil {
+		return err
+	}
+	defer provider.Close()
+	if _, err := provider.checkRebuildable(ledgerID); err != nil {
+		return err
+	}
+	if err := provider.dropDBs(ledgerID); err != nil {
+		return err
+	}
+	return provider.rebuildDBs(ledgerID)
+}
+
+// VerifyLedger verifies the hash chain of the blocks of a ledger, from the first block which was not
+// pruned up to the last block. The data hash in the header of every block is checked against the data
+// of the block, and the previous hash against the header of the previous block. The peer is expected
+// to be stopped. Nothing is written to the ledger
+func VerifyLedger(ledgerID string) error {
+	provider, err := openProviderOffline(ledgerID)
+	if err != nil {
+		return err
+	}
+	defer provider.Close()
+	store, err := provider.ledgerStoreProvider.Open(ledgerID)
+	if err != nil {
+		return err
+	}
+	defer store.Shutdown()
+	bcInfo, err := store.GetBlockchainInfo()
+	if err != nil {
+		return err
+	}
+	firstBlockNum, err := store.GetFirstBlockNumber()
+	if err != nil {
+		return err
+	}
+	if firstBlockNum >= bcInfo.Height {
+		logger.Infof("Ledger [%s] has no block to verify after the blocks below [%d]", ledgerID, firstBlockNum)
+		return nil
+	}
+
+	logger.Infof("Verifying blocks [%d] to [%d] of ledger [%s]", firstBlockNum, bcInfo.Height-1, ledgerID)
+	itr, err := store.RetrieveBlocks(firstBlockNum)
+	if err != nil {
+		return err
+	}
+	defer itr.Close()
+	var previousHash []byte
+	for blockNum := firstBlockNum; blockNum < bcInfo.Height; blockNum++ {
+		res, err := itr.Next()
+		if err != nil {
+			return fmt.Errorf("could not read block [%d]: %s", blockNum, err)
+		}
+		block := res.(*common.Block)
+		if block.Header.Number != blockNum {
+			return fmt.Errorf("block [%d] found in place of block [%d]", block.Header.Number, blockNum)
+		}
+		if !bytes.Equal(block.Header.DataHash, block.Data.Hash()) {
+			return fmt.Errorf("the data hash in the header of block [%d] does not match its data", blockNum)
+		}
+		if blockNum > firstBlockNum && !bytes.Equal(block.Header.PreviousHash, previousHash) {
+			return fmt.Errorf("the previous hash in the header of block [%d] does not match the header of block [%d]", blockNum, blockNum-1)
+		}
+		previousHash = block.Header.Hash()
+		if (blockNum+1-firstBlockNum)%verifyProgressInterval == 0 {
+			logger.Infof("Verified blocks [%d] to [%d] of ledger [%s]", firstBlockNum, blockNum, ledgerID)
+		}
+	}
+	if !bytes.Equal(previousHash, bcInfo.CurrentBlockHash) {
+		return fmt.Errorf("the hash of the last block [%d] does not match the blockchain info of ledger [%s]", bcInfo.Height-1, ledgerID)
+	}
+	logger.Infof("Verified blocks [%d] to [%d] of ledger [%s]", firstBlockNum, bcInfo.Height-1, ledgerID)
+	return nil
+}
+
+// openProviderOffline opens the ledger provider for the tools which run while the peer is stopped,
+// and makes sure the ledger exists. A running peer holds the lock of the id store, which fails the open
+func openProviderOffline(ledgerID string) (*Provider, error) {
+	path := ledgerconfig.GetLedgerProviderPath()
+	db, err := leveldb.OpenFile(path, &opt.Options{ErrorIfMissing: true})
+	if err != nil {
+		return nil, fmt.Errorf("could not open the ledger id store [%s], make sure the peer is stopped: %s", path, err)
+	}
+	exists, err := db.Has(append(ledgerKeyPrefix, []byte(ledgerID)...), nil)
+	db.Close()
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNonExistingLedgerID
+	}
+	provider, err := NewProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.(*Provider), nil
+}
+
+// checkRebuildable returns the blockchain info of a ledger whose databases can be rebuilt from its block
+// store, i.e., of a ledger which still has all its blocks since the genesis block
+func (provider *Provider) checkRebuildable(ledgerID string) (*common.BlockchainInfo, error) {
+	store, err := provider.ledgerStoreProvider.Open(ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Shutdown()
+	bcInfo, err := store.GetBlockchainInfo()
+	if err != nil {
+		return nil, err
+	}
+	firstBlockNum, err := store.GetFirstBlockNumber()
+	if err != nil {
+		return nil, err
+	}
+	if bcInfo.Height == 0 || firstBlockNum > 0 {
+		return nil, fmt.Errorf("cannot rebuild the databases of ledger [%s], its blocks below [%d] are pruned "+
+			"or were not part of the snapshot the ledger was created from", ledgerID, firstBlockNum)
+	}
+	return bcInfo, nil
+}
+
+// dropDBs drops the state database, the history database and the bookkeeping of the expiry
+// of the pvt data of a ledger
+func (provider *Provider) dropDBs(ledgerID string) error {
+	logger.Infof("Dropping the state and history databases of ledger [%s]", ledgerID)
+	if err := provider.vdbProvider.Drop(ledgerID); err != nil {
+		return err
+	}
+	if err := provider.historydbProvider.Drop(ledgerID); err != nil {
+		return err
+	}
+	return provider.bookkeepingProvider.GetDBHandle(ledgerID).DeleteAll()
+}
+
+// rebuildDBs opens the ledger, which recommits the blocks missing from its dropped databases
+func (provider *Provider) rebuildDBs(ledgerID string) error {
+	logger.Infof("Rebuilding the state and history databases of ledger [%s] from the block store", ledgerID)
+	l, err := provider.openInternal(ledgerID)
+	if err != nil {
+		return err
+	}
+	l.Close()
+	logger.Infof("Rebuilt the state and history databases of ledger [%s]", ledgerID)
+	return nil
+}
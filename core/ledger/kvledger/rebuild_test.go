@@ -0,0 +1,114 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/common/util"
+	lgr "github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollbackRebuildAndVerify(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	viper.Set("ledger.history.enableHistoryDatabase", true)
+
+	provider, _ := NewProvider()
+	_, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+
+	commit := func(ledger lgr.PeerLedger, key, value string, mutate func(*common.Block)) {
+		simulator, _ := ledger.NewTxSimulator(util.GenerateUUID())
+		simulator.SetState("ns1", key, []byte(value))
+		simulator.SetPrivateData("ns1", "coll1", key, []byte("pvt-"+value))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		pubSimBytes, _ := simRes.GetPubSimulationBytes()
+		bcInfo, _ := ledger.GetBlockchainInfo()
+		block := testutil.ConstructBlock(t, bcInfo.Height, bcInfo.CurrentBlockHash, [][]byte{pubSimBytes}, false)
+		if mutate != nil {
+			mutate(block)
+		}
+		assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block,
+			BlockPvtData: map[uint64]*lgr.TxPvtData{0: {SeqInBlock: 0, WriteSet: simRes.PvtSimulationResults}}}))
+	}
+	checkState := func(ledger lgr.PeerLedger, key, value string, numHistoryEntries int) {
+		qe, _ := ledger.NewQueryExecutor()
+		defer qe.Done()
+		v, err := qe.GetState("ns1", key)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(value), v)
+		v, err = qe.GetPrivateData("ns1", "coll1", key)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("pvt-"+value), v)
+
+		hqe, _ := ledger.NewHistoryQueryExecutor()
+		itr, err := hqe.GetHistoryForKey("ns1", key)
+		assert.NoError(t, err)
+		defer itr.Close()
+		var values []string
+		for {
+			res, _ := itr.Next()
+			if res == nil {
+				break
+			}
+			values = append(values, string(res.(*queryresult.KeyModification).Value))
+		}
+		assert.Len(t, values, numHistoryEntries)
+	}
+	commit(ledger, "key1", "value1", nil)
+	commit(ledger, "key2", "value2", nil)
+	rollbackBlock, _ := ledger.GetBlockByNumber(2)
+	commit(ledger, "key1", "value3", nil)
+
+	// The tools do not run while the peer holds the ledgers
+	assert.Error(t, RebuildDBs("testLedger"))
+	ledger.Close()
+	provider.Close()
+
+	assert.Equal(t, ErrNonExistingLedgerID, VerifyLedger("unknownLedger"))
+	assert.NoError(t, VerifyLedger("testLedger"))
+	assert.NoError(t, RebuildDBs("testLedger"))
+
+	provider, _ = NewProvider()
+	ledger, err = provider.Open("testLedger")
+	assert.NoError(t, err)
+	checkState(ledger, "key1", "value3", 2)
+	checkState(ledger, "key2", "value2", 1)
+	ledger.Close()
+	provider.Close()
+
+	assert.Error(t, RollbackLedger("testLedger", 4))
+	assert.NoError(t, RollbackLedger("testLedger", 2))
+	assert.NoError(t, VerifyLedger("testLedger"))
+
+	provider, _ = NewProvider()
+	ledger, err = provider.Open("testLedger")
+	assert.NoError(t, err)
+	bcInfo, _ := ledger.GetBlockchainInfo()
+	assert.Equal(t, uint64(3), bcInfo.Height)
+	assert.Equal(t, rollbackBlock.Header.Hash(), bcInfo.CurrentBlockHash)
+	checkState(ledger, "key1", "value1", 1)
+	checkState(ledger, "key2", "value2", 1)
+
+	// The ledger continues after the rolled back block, and a broken hash chain is detected
+	commit(ledger, "key1", "value4", nil)
+	checkState(ledger, "key1", "value4", 2)
+	commit(ledger, "key2", "value5", func(block *common.Block) {
+		block.Header.PreviousHash = []byte("previous hash")
+	})
+	ledger.Close()
+	provider.Close()
+	assert.Error(t, VerifyLedger("testLedger"))
+}
@@ -0,0 +1,650 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/pvtstatepurgemgmt"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/hyperledger/fabric/core/ledger/pvtdatapolicy"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+const (
+	snapshotManifestFile    = "manifest.json"
+	snapshotPubStateFile    = "public_state.data"
+	snapshotHashedStateFile = "private_state_hashes.data"
+	snapshotLastBlockFile   = "last_block.data"
+	snapshotConfigBlockFile = "config_block.data"
+	snapshotTxIDsFile       = "txids.data"
+	snapshotImportBatchSize = 1000
+)
+
+// snapshotManifest describes a snapshot of a ledger as of its last block. It lists
+// the SHA256 hash of each file of the snapshot. The collection configurations are
+// part of the public state, in the namespace of the lifecycle chaincode. The TxIDs
+// of the blocks up to the last block are listed, but not the blocks themselves, nor
+// the history of the keys: a ledger created from the snapshot detects duplicate
+// TxIDs, while its history queries only cover the blocks after the snapshot
+type snapshotManifest struct {
+	ChannelName       string            `json:"channel_name"`
+	LastBlockNumber   uint64            `json:"last_block_number"`
+	LastBlockHash     string            `json:"last_block_hash"`
+	PreviousBlockHash string            `json:"previous_block_hash"`
+	ConfigBlockNumber uint64            `json:"config_block_number"`
+	FileHashes        map[string]string `json:"file_hashes"`
+}
+
+// snapshot is a snapshot loaded from its directory, whose files were verified
+// against the manifest
+type snapshot struct {
+	dir         string
+	manifest    *snapshotManifest
+	lastBlock   *common.Block
+	configBlock *common.Block
+}
+
+// SubmitSnapshotRequest implements the corresponding method from interface ledger.PeerLedger.
+// A snapshot of the last committed block is exported right away, a snapshot of a later block
+// once the block is committed
+func (l *kvLedger) SubmitSnapshotRequest(blockNum uint64) error {
+	l.commitLock.Lock()
+	defer l.commitLock.Unlock()
+	info, err := l.blockStore.GetBlockchainInfo()
+	if err != nil {
+		return err
+	}
+	if info.Height == 0 {
+		return fmt.Errorf("ledger [%s] has no block to snapshot", l.ledgerID)
+	}
+	lastBlockNum := info.Height - 1
+	if blockNum == 0 {
+		blockNum = lastBlockNum
+	}
+	switch {
+	case blockNum < lastBlockNum:
+		return fmt.Errorf("block [%d] is already committed, the last block being [%d]", blockNum, lastBlockNum)
+	case blockNum > lastBlockNum:
+		l.snapshotRequests[blockNum] = struct{}{}
+		logger.Infof("Channel [%s]: Snapshot to be exported once block [%d] is committed", l.ledgerID, blockNum)
+		return nil
+	}
+	return l.startSnapshotExport(blockNum)
+}
+
+// exportSnapshotIfRequested exports a snapshot of the given block, just committed,
+// if one was requested. It is to be invoked with the commit lock held
+func (l *kvLedger) exportSnapshotIfRequested(blockNum uint64) {
+	if _, ok := l.snapshotRequests[blockNum]; !ok {
+		return
+	}
+	delete(l.snapshotRequests, blockNum)
+	if err := l.startSnapshotExport(blockNum); err != nil {
+		logger.Errorf("Channel [%s]: Failed exporting snapshot of block [%d]: %s", l.ledgerID, blockNum, err)
+	}
+}
+
+// snapshotSource holds what a snapshot of a block is exported from, obtained while the block
+// is the last one committed. The state and the TxIDs are read through iterators, the latter
+// being pinned to the block. The state iterator is pinned to the block too, unless the state
+// db cannot pin its iterators
+type snapshotSource struct {
+	dir         string
+	blockNum    uint64
+	lastBlock   *common.Block
+	configBlock *common.Block
+	stateItr    statedb.ResultsIterator
+	statePinned bool
+	txIDsItr    commonledger.ResultsIterator
+}
+
+func (s *snapshotSource) close() {
+	s.stateItr.Close()
+	s.txIDsItr.Close()
+}
+
+// startSnapshotExport exports a snapshot of the given block, the last one committed, in a directory
+// of the ledger under the snapshots dir. It is to be invoked with the commit lock held. The export
+// continues in the background, without holding up the commits, when the state iterator is pinned.
+// Otherwise, as with CouchDB, the snapshot is exported before returning, the commits waiting for it
+func (l *kvLedger) startSnapshotExport(blockNum uint64) error {
+	source, err := l.openSnapshotSource(blockNum)
+	if err != nil {
+		return err
+	}
+	if !source.statePinned {
+		defer source.close()
+		return l.exportSnapshot(source)
+	}
+	l.pruneLock.Lock()
+	if l.closed {
+		l.pruneLock.Unlock()
+		source.close()
+		return errors.New("ledger is closed")
+	}
+	l.snapshotExportsDone.Add(1)
+	l.pruneLock.Unlock()
+	l.snapshotExports[blockNum] = struct{}{}
+	go func() {
+		defer func() {
+			source.close()
+			l.commitLock.Lock()
+			delete(l.snapshotExports, blockNum)
+			l.commitLock.Unlock()
+			l.snapshotExportsDone.Done()
+		}()
+		if err := l.exportSnapshot(source); err != nil {
+			logger.Errorf("Channel [%s]: Failed exporting snapshot of block [%d]: %s", l.ledgerID, blockNum, err)
+		}
+	}()
+	return nil
+}
+
+func (l *kvLedger) openSnapshotSource(blockNum uint64) (*snapshotSource, error) {
+	dir := filepath.Join(ledgerconfig.GetSnapshotsPath(), l.ledgerID, strconv.FormatUint(blockNum, 10))
+	if _, exporting := l.snapshotExports[blockNum]; exporting {
+		return nil, fmt.Errorf("snapshot [%s] is being exported", dir)
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("snapshot [%s] already exists", dir)
+	}
+	lastBlock, err := l.blockStore.RetrieveBlockByNumber(blockNum)
+	if err != nil {
+		return nil, err
+	}
+	configIndex, err := utils.GetLastConfigIndexFromBlock(lastBlock)
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine the latest config block: %s", err)
+	}
+	configBlock, err := l.blockStore.RetrieveBlockByNumber(configIndex)
+	if err != nil {
+		return nil, err
+	}
+	stateItr, statePinned, err := l.versionedDB.GetFullScanIterator()
+	if err != nil {
+		return nil, err
+	}
+	txIDsItr, err := l.blockStore.GetTxIDsIterator()
+	if err != nil {
+		stateItr.Close()
+		return nil, err
+	}
+	return &snapshotSource{
+		dir:         dir,
+		blockNum:    blockNum,
+		lastBlock:   lastBlock,
+		configBlock: configBlock,
+		stateItr:    stateItr,
+		statePinned: statePinned,
+		txIDsItr:    txIDsItr,
+	}, nil
+}
+
+// exportSnapshot exports a snapshot of the ledger from the given source. The files are written in a
+// temporary directory first, which is renamed once complete
+func (l *kvLedger) exportSnapshot(source *snapshotSource) error {
+	logger.Infof("Channel [%s]: Exporting snapshot of block [%d] to [%s]", l.ledgerID, source.blockNum, source.dir)
+	tmpDir := source.dir + ".tmp"
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return err
+	}
+	manifest := &snapshotManifest{
+		ChannelName:       l.ledgerID,
+		LastBlockNumber:   source.blockNum,
+		LastBlockHash:     hex.EncodeToString(source.lastBlock.Header.Hash()),
+		PreviousBlockHash: hex.EncodeToString(source.lastBlock.Header.PreviousHash),
+		ConfigBlockNumber: source.configBlock.Header.Number,
+		FileHashes:        make(map[string]string),
+	}
+	if err := exportState(tmpDir, l.versionedDB, source.stateItr, manifest.FileHashes); err != nil {
+		return err
+	}
+	if err := exportTxIDs(tmpDir, source.txIDsItr, manifest.FileHashes); err != nil {
+		return err
+	}
+	for fileName, block := range map[string]*common.Block{snapshotLastBlockFile: source.lastBlock, snapshotConfigBlockFile: source.configBlock} {
+		blockBytes, err := proto.Marshal(block)
+		if err != nil {
+			return err
+		}
+		if err := writeSnapshotFile(tmpDir, fileName, blockBytes, manifest.FileHashes); err != nil {
+			return err
+		}
+	}
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeSnapshotFile(tmpDir, snapshotManifestFile, manifestBytes, nil); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpDir, source.dir); err != nil {
+		return err
+	}
+	logger.Infof("Channel [%s]: Exported snapshot of block [%d]", l.ledgerID, source.blockNum)
+	return nil
+}
+
+// exportState writes the public state and the hashed state, read through the given iterator, to their files
+func exportState(dir string, vdb privacyenabledstate.DB, itr statedb.ResultsIterator, fileHashes map[string]string) error {
+	pubWriter, err := newSnapshotRecordWriter(dir, snapshotPubStateFile)
+	if err != nil {
+		return err
+	}
+	defer pubWriter.close()
+	hashedWriter, err := newSnapshotRecordWriter(dir, snapshotHashedStateFile)
+	if err != nil {
+		return err
+	}
+	defer hashedWriter.close()
+
+	err = vdb.ExportPubAndHashedState(itr,
+		func(kv *statedb.VersionedKV) error {
+			return pubWriter.write([]byte(kv.Namespace), []byte(kv.Key), kv.Value, kv.Version.ToBytes())
+		},
+		func(ns, coll string, keyHash []byte, vv *statedb.VersionedValue) error {
+			return hashedWriter.write([]byte(ns), []byte(coll), keyHash, vv.Value, vv.Version.ToBytes())
+		})
+	if err != nil {
+		return err
+	}
+	if fileHashes[snapshotPubStateFile], err = pubWriter.done(); err != nil {
+		return err
+	}
+	fileHashes[snapshotHashedStateFile], err = hashedWriter.done()
+	return err
+}
+
+// exportTxIDs writes the TxIDs of the blocks up to the last block of the snapshot to their file, so that
+// a ledger created from the snapshot still detects the duplicate TxIDs. The file may list a TxID twice
+func exportTxIDs(dir string, itr commonledger.ResultsIterator, fileHashes map[string]string) error {
+	writer, err := newSnapshotRecordWriter(dir, snapshotTxIDsFile)
+	if err != nil {
+		return err
+	}
+	defer writer.close()
+	for {
+		res, err := itr.Next()
+		if err != nil {
+			return err
+		}
+		if res == nil {
+			break
+		}
+		if err := writer.write([]byte(res.(string))); err != nil {
+			return err
+		}
+	}
+	fileHashes[snapshotTxIDsFile], err = writer.done()
+	return err
+}
+
+// CreateFromSnapshot implements the corresponding method from interface ledger.PeerLedgerProvider.
+// Like function `Create`, it sets the under construction flag first. The state, the history and the
+// bookkeeping of the pvt data expiry are initialized before the block store, so that a ledger found
+// under construction with a bootstrapped block store was completely imported
+func (provider *Provider) CreateFromSnapshot(snapshotDir string) (ledger.PeerLedger, error) {
+	s, err := loadSnapshot(snapshotDir)
+	if err != nil {
+		return nil, err
+	}
+	ledgerID := s.manifest.ChannelName
+	exists, err := provider.idStore.ledgerIDExists(ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrLedgerIDExists
+	}
+	if err = provider.idStore.setUnderConstructionFlag(ledgerID); err != nil {
+		return nil, err
+	}
+	if err := provider.importSnapshot(ledgerID, s); err != nil {
+		logger.Errorf("Error in importing snapshot [%s]. Unsetting under construction flag. Err: %s", snapshotDir, err)
+		panicOnErr(provider.runCleanup(ledgerID), "Error while running cleanup for ledger id [%s]", ledgerID)
+		panicOnErr(provider.idStore.unsetUnderConstructionFlag(), "Error while unsetting under construction flag")
+		return nil, err
+	}
+	l, err := provider.openInternal(ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	panicOnErr(provider.idStore.createLedgerID(ledgerID, s.configBlock), "Error while marking ledger as created")
+	logger.Infof("Created ledger [%s] from snapshot of block [%d]", ledgerID, s.manifest.LastBlockNumber)
+	return l, nil
+}
+
+func (provider *Provider) importSnapshot(ledgerID string, s *snapshot) error {
+	vDB, err := provider.vdbProvider.GetDBHandle(ledgerID)
+	if err != nil {
+		return err
+	}
+	if err := checkStateEmpty(ledgerID, vDB); err != nil {
+		return err
+	}
+	savepoint := version.NewHeight(s.lastBlock.Header.Number, uint64(len(s.lastBlock.Data.Data)-1))
+
+	// The public state goes first, as the expiry of the hashed state depends upon the collection configurations
+	err = importSnapshotRecords(s.dir, snapshotPubStateFile, 4, func(updates *privacyenabledstate.UpdateBatch, fields [][]byte) error {
+		ver, _ := version.NewHeightFromBytes(fields[3])
+		updates.PubUpdates.Put(string(fields[0]), string(fields[1]), fields[2], ver)
+		return nil
+	}, func(updates *privacyenabledstate.UpdateBatch) error {
+		return vDB.ApplyPrivacyAwareUpdates(updates, savepoint)
+	})
+	if err != nil {
+		return err
+	}
+
+	purgeMgr := pvtstatepurgemgmt.NewPurgeMgr(vDB, pvtdatapolicy.NewBTLPolicy(vDB), provider.bookkeepingProvider.GetDBHandle(ledgerID))
+	err = importSnapshotRecords(s.dir, snapshotHashedStateFile, 5, func(updates *privacyenabledstate.UpdateBatch, fields [][]byte) error {
+		ver, _ := version.NewHeightFromBytes(fields[4])
+		updates.HashUpdates.Put(string(fields[0]), string(fields[1]), fields[2], fields[3], ver)
+		return nil
+	}, func(updates *privacyenabledstate.UpdateBatch) error {
+		if err := vDB.ApplyPrivacyAwareUpdates(updates, savepoint); err != nil {
+			return err
+		}
+		return purgeMgr.UpdateBookkeepingForImportedHashes(updates.HashUpdates)
+	})
+	if err != nil {
+		return err
+	}
+
+	historyDB, err := provider.historydbProvider.GetDBHandle(ledgerID)
+	if err != nil {
+		return err
+	}
+	if err := historyDB.InitSavepoint(savepoint); err != nil {
+		return err
+	}
+	txIDs, err := newSnapshotTxIDsItr(s.dir)
+	if err != nil {
+		return err
+	}
+	defer txIDs.Close()
+	return provider.ledgerStoreProvider.BootstrapFromSnapshot(ledgerID, s.lastBlock, []*common.Block{s.configBlock}, txIDs)
+}
+
+// checkStateEmpty makes sure that no state, left over by an earlier attempt, exists for the ledger
+func checkStateEmpty(ledgerID string, vDB privacyenabledstate.DB) error {
+	itr, _, err := vDB.GetFullScanIterator()
+	if err != nil {
+		return err
+	}
+	defer itr.Close()
+	res, err := itr.Next()
+	if err != nil {
+		return err
+	}
+	if res != nil {
+		return fmt.Errorf("state database of ledger [%s] is not empty", ledgerID)
+	}
+	return nil
+}
+
+// importSnapshotRecords adds each record of the file, which has the given number of fields, to an update
+// batch, which is applied every snapshotImportBatchSize records
+func importSnapshotRecords(dir, fileName string, numFields int,
+	add func(*privacyenabledstate.UpdateBatch, [][]byte) error,
+	apply func(*privacyenabledstate.UpdateBatch) error) error {
+
+	reader, err := newSnapshotRecordReader(dir, fileName)
+	if err != nil {
+		return err
+	}
+	defer reader.close()
+	updates := privacyenabledstate.NewUpdateBatch()
+	numRecords := 0
+	for {
+		fields, err := reader.next(numFields)
+		if err != nil {
+			return err
+		}
+		if fields == nil {
+			break
+		}
+		if err := add(updates, fields); err != nil {
+			return err
+		}
+		if numRecords++; numRecords%snapshotImportBatchSize == 0 {
+			if err := apply(updates); err != nil {
+				return err
+			}
+			updates = privacyenabledstate.NewUpdateBatch()
+		}
+	}
+	logger.Debugf("Imported %d record(s) of snapshot file [%s]", numRecords, fileName)
+	return apply(updates)
+}
+
+// loadSnapshot reads the manifest and the blocks of a snapshot, after verifying the hashes of its files
+func loadSnapshot(dir string) (*snapshot, error) {
+	manifestBytes, err := ioutil.ReadFile(filepath.Join(dir, snapshotManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	manifest := &snapshotManifest{}
+	if err := json.Unmarshal(manifestBytes, manifest); err != nil {
+		return nil, fmt.Errorf("invalid snapshot manifest: %s", err)
+	}
+	for _, fileName := range []string{snapshotPubStateFile, snapshotHashedStateFile, snapshotTxIDsFile, snapshotLastBlockFile, snapshotConfigBlockFile} {
+		if err := verifySnapshotFile(dir, fileName, manifest.FileHashes[fileName]); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &snapshot{dir: dir, manifest: manifest}
+	if s.lastBlock, err = readSnapshotBlock(dir, snapshotLastBlockFile); err != nil {
+		return nil, err
+	}
+	if s.configBlock, err = readSnapshotBlock(dir, snapshotConfigBlockFile); err != nil {
+		return nil, err
+	}
+	if s.lastBlock.Header.Number != manifest.LastBlockNumber ||
+		hex.EncodeToString(s.lastBlock.Header.Hash()) != manifest.LastBlockHash {
+		return nil, fmt.Errorf("last block of the snapshot does not match with the manifest")
+	}
+	if !bytes.Equal(s.lastBlock.Header.DataHash, s.lastBlock.Data.Hash()) {
+		return nil, fmt.Errorf("data hash of the last block of the snapshot does not match with its header")
+	}
+	configIndex, err := utils.GetLastConfigIndexFromBlock(s.lastBlock)
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine the latest config block: %s", err)
+	}
+	if s.configBlock.Header.Number != configIndex || configIndex != manifest.ConfigBlockNumber {
+		return nil, fmt.Errorf("config block [%d] of the snapshot is not the latest config block [%d]",
+			s.configBlock.Header.Number, configIndex)
+	}
+	channelID, err := utils.GetChainIDFromBlock(s.configBlock)
+	if err != nil {
+		return nil, err
+	}
+	if channelID != manifest.ChannelName {
+		return nil, fmt.Errorf("config block of the snapshot is for channel [%s], not [%s]", channelID, manifest.ChannelName)
+	}
+	return s, nil
+}
+
+func verifySnapshotFile(dir, fileName, expectedHash string) error {
+	f, err := os.Open(filepath.Join(dir, fileName))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if hex.EncodeToString(h.Sum(nil)) != expectedHash {
+		return fmt.Errorf("hash of snapshot file [%s] does not match with the manifest", fileName)
+	}
+	return nil
+}
+
+func readSnapshotBlock(dir, fileName string) (*common.Block, error) {
+	blockBytes, err := ioutil.ReadFile(filepath.Join(dir, fileName))
+	if err != nil {
+		return nil, err
+	}
+	block := &common.Block{}
+	if err := proto.Unmarshal(blockBytes, block); err != nil {
+		return nil, err
+	}
+	if block.Header == nil || block.Data == nil {
+		return nil, fmt.Errorf("snapshot file [%s] does not hold a valid block", fileName)
+	}
+	return block, nil
+}
+
+// writeSnapshotFile writes the file and records its hash, if fileHashes is not nil
+func writeSnapshotFile(dir, fileName string, content []byte, fileHashes map[string]string) error {
+	if err := ioutil.WriteFile(filepath.Join(dir, fileName), content, 0644); err != nil {
+		return err
+	}
+	if fileHashes != nil {
+		sum := sha256.Sum256(content)
+		fileHashes[fileName] = hex.EncodeToString(sum[:])
+	}
+	return nil
+}
+
+// snapshotRecordWriter writes records made of length prefixed fields, each
+// record being prefixed by its length, and computes the hash of the file
+type snapshotRecordWriter struct {
+	file   *os.File
+	writer *bufio.Writer
+	hash   hash.Hash
+}
+
+func newSnapshotRecordWriter(dir, fileName string) (*snapshotRecordWriter, error) {
+	f, err := os.Create(filepath.Join(dir, fileName))
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	return &snapshotRecordWriter{f, bufio.NewWriter(io.MultiWriter(f, h)), h}, nil
+}
+
+func (w *snapshotRecordWriter) write(fields ...[]byte) error {
+	buffer := proto.NewBuffer(nil)
+	for _, field := range fields {
+		if err := buffer.EncodeRawBytes(field); err != nil {
+			return err
+		}
+	}
+	if _, err := w.writer.Write(proto.EncodeVarint(uint64(len(buffer.Bytes())))); err != nil {
+		return err
+	}
+	_, err := w.writer.Write(buffer.Bytes())
+	return err
+}
+
+// done flushes and syncs the file, and returns its hash
+func (w *snapshotRecordWriter) done() (string, error) {
+	if err := w.writer.Flush(); err != nil {
+		return "", err
+	}
+	if err := w.file.Sync(); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(w.hash.Sum(nil)), nil
+}
+
+func (w *snapshotRecordWriter) close() {
+	w.file.Close()
+}
+
+// snapshotRecordReader reads the records written by a snapshotRecordWriter
+type snapshotRecordReader struct {
+	file   *os.File
+	reader *bufio.Reader
+}
+
+func newSnapshotRecordReader(dir, fileName string) (*snapshotRecordReader, error) {
+	f, err := os.Open(filepath.Join(dir, fileName))
+	if err != nil {
+		return nil, err
+	}
+	return &snapshotRecordReader{f, bufio.NewReader(f)}, nil
+}
+
+// next returns the fields of the next record, or nil at the end of the file
+func (r *snapshotRecordReader) next(numFields int) ([][]byte, error) {
+	recordLen, err := binary.ReadUvarint(r.reader)
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	record := make([]byte, recordLen)
+	if _, err := io.ReadFull(r.reader, record); err != nil {
+		return nil, fmt.Errorf("truncated snapshot record: %s", err)
+	}
+	buffer := proto.NewBuffer(record)
+	fields := make([][]byte, numFields)
+	for i := range fields {
+		if fields[i], err = buffer.DecodeRawBytes(false); err != nil {
+			return nil, fmt.Errorf("invalid snapshot record: %s", err)
+		}
+	}
+	return fields, nil
+}
+
+func (r *snapshotRecordReader) close() {
+	r.file.Close()
+}
+
+// snapshotTxIDsItr returns the TxIDs, of type string, listed in the TxIDs file of a snapshot
+type snapshotTxIDsItr struct {
+	reader *snapshotRecordReader
+}
+
+func newSnapshotTxIDsItr(dir string) (*snapshotTxIDsItr, error) {
+	reader, err := newSnapshotRecordReader(dir, snapshotTxIDsFile)
+	if err != nil {
+		return nil, err
+	}
+	return &snapshotTxIDsItr{reader}, nil
+}
+
+func (itr *snapshotTxIDsItr) Next() (commonledger.QueryResult, error) {
+	fields, err := itr.reader.next(1)
+	if fields == nil || err != nil {
+		return nil, err
+	}
+	return string(fields[0]), nil
+}
+
+func (itr *snapshotTxIDsItr) Close() {
+	itr.reader.close()
+}
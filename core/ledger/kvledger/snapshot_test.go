@@ -0,0 +1,230 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kvledger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/common/util"
+	lgr "github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	ledgerutil "github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/protos/common"
+	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotExportAndImport(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	snapshotsDir, err := ioutil.TempDir("", "snapshots")
+	assert.NoError(t, err)
+	defer os.RemoveAll(snapshotsDir)
+	viper.Set("ledger.snapshots.rootDir", snapshotsDir)
+	defer viper.Set("ledger.snapshots.rootDir", "")
+
+	provider, _ := NewProvider()
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+
+	commit := func(ledger lgr.PeerLedger, key, value string) *common.Block {
+		simulator, _ := ledger.NewTxSimulator(util.GenerateUUID())
+		simulator.SetState("ns1", key, []byte(value))
+		simulator.SetPrivateData("ns1", "coll1", key, []byte("pvt-"+value))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		pubSimBytes, _ := simRes.GetPubSimulationBytes()
+		block := bg.NextBlock([][]byte{pubSimBytes})
+		assert.NoError(t, ledger.CommitWithPvtData(&lgr.BlockAndPvtData{Block: block,
+			BlockPvtData: map[uint64]*lgr.TxPvtData{0: {SeqInBlock: 0, WriteSet: simRes.PvtSimulationResults}}}))
+		return block
+	}
+	txID := txIDOf(t, commit(ledger, "key1", "value1"))
+	commit(ledger, "key2", "value2")
+
+	// A committed block other than the last one cannot be snapshotted
+	assert.Error(t, ledger.SubmitSnapshotRequest(1))
+	// The snapshot of a future block is exported once the block is committed
+	assert.NoError(t, ledger.SubmitSnapshotRequest(3))
+	commit(ledger, "key1", "value3")
+	snapshotDir := filepath.Join(ledgerconfig.GetSnapshotsPath(), "testLedger", "3")
+	waitForSnapshot(t, snapshotDir)
+	bcInfo, _ := ledger.GetBlockchainInfo()
+	ledger.Close()
+	provider.Close()
+
+	// A new peer creates the ledger from the snapshot
+	env.cleanup()
+	provider, _ = NewProvider()
+	ledger, err = provider.CreateFromSnapshot(snapshotDir)
+	assert.NoError(t, err)
+	importedInfo, err := ledger.GetBlockchainInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, bcInfo, importedInfo)
+	_, err = ledger.GetBlockByNumber(1)
+	assert.Equal(t, blkstorage.ErrPruned, err)
+	// The TxIDs of the snapshot are known, to detect the duplicate ones
+	_, err = ledger.GetTransactionByID(txID)
+	assert.Equal(t, blkstorage.ErrPruned, err)
+	_, err = ledger.GetTransactionByID("unknownTxID")
+	assert.Equal(t, blkstorage.ErrNotFoundInIndex, err)
+
+	qe, _ := ledger.NewQueryExecutor()
+	value, err := qe.GetState("ns1", "key1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value3"), value)
+	value, err = qe.GetState("ns1", "key2")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value2"), value)
+	// Only the hashes of the pvt data are part of the snapshot
+	value, err = qe.GetPrivateData("ns1", "coll1", "key2")
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+	qe.Done()
+	vv, err := ledger.(*kvLedger).versionedDB.GetValueHash("ns1", "coll1", ledgerutil.ComputeStringHash("key2"))
+	assert.NoError(t, err)
+	assert.Equal(t, ledgerutil.ComputeStringHash("pvt-value2"), vv.Value)
+
+	// The ledger continues from the next block, also after a restart
+	commit(ledger, "key3", "value4")
+	ledger.Close()
+	_, err = provider.CreateFromSnapshot(snapshotDir)
+	assert.Equal(t, ErrLedgerIDExists, err)
+	provider.Close()
+
+	provider, _ = NewProvider()
+	ledgerIds, _ := provider.List()
+	assert.Equal(t, []string{"testLedger"}, ledgerIds)
+	ledger, err = provider.Open("testLedger")
+	assert.NoError(t, err)
+	bcInfo, _ = ledger.GetBlockchainInfo()
+	assert.Equal(t, uint64(5), bcInfo.Height)
+	ledger.Close()
+	provider.Close()
+
+	// A snapshot whose files do not match with the manifest is rejected
+	env.cleanup()
+	stateFile := filepath.Join(snapshotDir, snapshotPubStateFile)
+	content, _ := ioutil.ReadFile(stateFile)
+	assert.NoError(t, ioutil.WriteFile(stateFile, append(content, 0x00), 0644))
+	provider, _ = NewProvider()
+	defer provider.Close()
+	_, err = provider.CreateFromSnapshot(snapshotDir)
+	assert.Error(t, err)
+	ledgerIds, _ = provider.List()
+	assert.Empty(t, ledgerIds)
+}
+
+func TestSnapshotExportDoesNotHoldCommits(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	snapshotsDir, err := ioutil.TempDir("", "snapshots")
+	assert.NoError(t, err)
+	defer os.RemoveAll(snapshotsDir)
+	viper.Set("ledger.snapshots.rootDir", snapshotsDir)
+	defer viper.Set("ledger.snapshots.rootDir", "")
+
+	provider, _ := NewProvider()
+	defer provider.Close()
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+	defer ledger.Close()
+
+	commit := func(value string) {
+		simulator, _ := ledger.NewTxSimulator(util.GenerateUUID())
+		simulator.SetState("ns1", "key1", []byte(value))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		pubSimBytes, _ := simRes.GetPubSimulationBytes()
+		assert.NoError(t, ledger.Commit(bg.NextBlock([][]byte{pubSimBytes})))
+	}
+	commit("value1")
+
+	// The blocks committed while the snapshot is exported do not make it into the snapshot
+	assert.NoError(t, ledger.SubmitSnapshotRequest(0))
+	commit("value2")
+	snapshotDir := filepath.Join(ledgerconfig.GetSnapshotsPath(), "testLedger", "1")
+	waitForSnapshot(t, snapshotDir)
+	assert.Error(t, ledger.SubmitSnapshotRequest(1), "Expected an error for a committed block other than the last one")
+
+	s, err := loadSnapshot(snapshotDir)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), s.manifest.LastBlockNumber)
+	reader, err := newSnapshotRecordReader(snapshotDir, snapshotPubStateFile)
+	assert.NoError(t, err)
+	defer reader.close()
+	values := make(map[string]string)
+	for {
+		fields, err := reader.next(4)
+		assert.NoError(t, err)
+		if fields == nil {
+			break
+		}
+		values[string(fields[0])+"/"+string(fields[1])] = string(fields[2])
+	}
+	assert.Equal(t, "value1", values["ns1/key1"])
+}
+
+func TestCloseWaitsForSnapshotExport(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	snapshotsDir, err := ioutil.TempDir("", "snapshots")
+	assert.NoError(t, err)
+	defer os.RemoveAll(snapshotsDir)
+	viper.Set("ledger.snapshots.rootDir", snapshotsDir)
+	defer viper.Set("ledger.snapshots.rootDir", "")
+
+	provider, _ := NewProvider()
+	defer provider.Close()
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+
+	simulator, _ := ledger.NewTxSimulator(util.GenerateUUID())
+	simulator.SetState("ns1", "key1", []byte("value1"))
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults()
+	pubSimBytes, _ := simRes.GetPubSimulationBytes()
+	assert.NoError(t, ledger.SubmitSnapshotRequest(1))
+	assert.NoError(t, ledger.Commit(bg.NextBlock([][]byte{pubSimBytes})))
+	ledger.Close()
+
+	// The export started by the commit has ended by the time the ledger is closed
+	snapshotDir := filepath.Join(ledgerconfig.GetSnapshotsPath(), "testLedger", "1")
+	_, err = loadSnapshot(snapshotDir)
+	assert.NoError(t, err)
+}
+
+// waitForSnapshot waits until the snapshot, exported in the background, is in its directory
+func waitForSnapshot(t *testing.T, snapshotDir string) {
+	for i := 0; i < 100; i++ {
+		if _, err := os.Stat(snapshotDir); err == nil {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("Snapshot [%s] was not exported", snapshotDir)
+}
+
+func txIDOf(t *testing.T, block *common.Block) string {
+	env, err := putils.GetEnvelopeFromBlock(block.Data.Data[0])
+	assert.NoError(t, err)
+	payload, err := putils.GetPayload(env)
+	assert.NoError(t, err)
+	chdr, err := putils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	assert.NoError(t, err)
+	return chdr.TxId
+}
@@ -9,6 +9,7 @@ package privacyenabledstate
 import (
 	"encoding/base64"
 	"fmt"
+	"strings"
 
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/statecouchdb"
@@ -110,6 +111,38 @@ func (s *CommonStorageDB) ApplyPrivacyAwareUpdates(updates *UpdateBatch, height
 	return s.VersionedDB.ApplyUpdates(updates.PubUpdates.UpdateBatch, height)
 }
 
+// ExportPubAndHashedState implements corresponding function in interface DB
+func (s *CommonStorageDB) ExportPubAndHashedState(itr statedb.ResultsIterator, pubFunc func(*statedb.VersionedKV) error, hashedFunc HashedKVFunc) error {
+	for {
+		res, err := itr.Next()
+		if err != nil {
+			return err
+		}
+		if res == nil {
+			return nil
+		}
+		kv := res.(*statedb.VersionedKV)
+		ns, coll, isHashed, isPvt := splitDerivedNs(kv.Namespace)
+		switch {
+		case isPvt:
+			continue
+		case isHashed:
+			keyHash := []byte(kv.Key)
+			if !s.BytesKeySuppoted() {
+				if keyHash, err = base64.StdEncoding.DecodeString(kv.Key); err != nil {
+					return err
+				}
+			}
+			err = hashedFunc(ns, coll, keyHash, &kv.VersionedValue)
+		default:
+			err = pubFunc(kv)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
 func derivePvtDataNs(namespace, collection string) string {
 	return namespace + nsJoiner + pvtDataPrefix + collection
 }
@@ -118,6 +151,23 @@ func deriveHashedDataNs(namespace, collection string) string {
 	return namespace + nsJoiner + hashDataPrefix + collection
 }
 
+// splitDerivedNs splits a namespace derived for the private data or the hashed data
+// into the namespace and the collection. Chaincode names cannot contain the joiner
+func splitDerivedNs(derivedNs string) (ns, coll string, isHashed, isPvt bool) {
+	i := strings.Index(derivedNs, nsJoiner)
+	if i < 0 || i+1 == len(derivedNs) {
+		return derivedNs, "", false, false
+	}
+	ns, coll = derivedNs[:i], derivedNs[i+2:]
+	switch derivedNs[i+1 : i+2] {
+	case hashDataPrefix:
+		return ns, coll, true, false
+	case pvtDataPrefix:
+		return ns, coll, false, true
+	}
+	return derivedNs, "", false, false
+}
+
 func addPvtUpdates(pubUpdateBatch *PubUpdateBatch, pvtUpdateBatch *PvtUpdateBatch) {
 	for ns, nsBatch := range pvtUpdateBatch.UpdateMap {
 		for _, coll := range nsBatch.GetCollectionNames() {
@@ -15,6 +15,8 @@ import (
 type DBProvider interface {
 	// GetDBHandle returns a handle to a PvtVersionedDB
 	GetDBHandle(id string) (DB, error)
+	// Drop deletes all the data, public, hashed and private, of the DB with the given id
+	Drop(id string) error
 	// Close closes all the PvtVersionedDB instances and releases any resources held by VersionedDBProvider
 	Close()
 }
@@ -28,8 +30,15 @@ type DB interface {
 	GetPrivateDataRangeScanIterator(namespace, collection, startKey, endKey string) (statedb.ResultsIterator, error)
 	ExecuteQueryOnPrivateData(namespace, collection, query string) (statedb.ResultsIterator, error)
 	ApplyPrivacyAwareUpdates(updates *UpdateBatch, height *version.Height) error
+	// ExportPubAndHashedState invokes pubFunc for each key of the public data and hashedFunc for each
+	// key hash of the hashed data returned by itr, obtained via function GetFullScanIterator, in the
+	// order of the underlying db. The private data is left out
+	ExportPubAndHashedState(itr statedb.ResultsIterator, pubFunc func(*statedb.VersionedKV) error, hashedFunc HashedKVFunc) error
 }
 
+// HashedKVFunc is invoked for a key hash of the hashed data of a collection
+type HashedKVFunc func(namespace, collection string, keyHash []byte, vv *statedb.VersionedValue) error
+
 // UpdateBatch encapsulates the updates to Public, Private, and Hashed data.
 // This is expected to contain a consistent set of updates
 type UpdateBatch struct {
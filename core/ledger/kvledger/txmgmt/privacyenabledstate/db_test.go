@@ -118,6 +118,58 @@ func testDB(t *testing.T, env TestEnv) {
 	assert.Nil(t, vv)
 }
 
+func TestExportPubAndHashedState(t *testing.T) {
+	for _, env := range testEnvs {
+		t.Run(env.GetName(), func(t *testing.T) {
+			testExportPubAndHashedState(t, env)
+		})
+	}
+}
+
+func testExportPubAndHashedState(t *testing.T, env TestEnv) {
+	env.Init(t)
+	defer env.Cleanup()
+	db := env.GetDBHandle("test-ledger-id")
+
+	updates := NewUpdateBatch()
+	updates.PubUpdates.Put("ns1", "key1", []byte("value1"), version.NewHeight(1, 1))
+	updates.PubUpdates.Put("ns2", "key2", []byte("value2"), version.NewHeight(1, 2))
+	putPvtUpdates(t, updates, "ns1", "coll1", "key1", []byte("pvt_value1"), version.NewHeight(1, 3))
+	putPvtUpdates(t, updates, "ns1", "coll2", "key2", []byte("pvt_value2"), version.NewHeight(1, 4))
+	assert.NoError(t, db.ApplyPrivacyAwareUpdates(updates, version.NewHeight(1, 4)))
+
+	pubKVs := make(map[statedb.CompositeKey]*statedb.VersionedValue)
+	hashedKVs := make(map[string]*statedb.VersionedValue)
+	itr, pinned, err := db.GetFullScanIterator()
+	assert.NoError(t, err)
+	defer itr.Close()
+	// The updates applied while a pinned iterator is open do not show up
+	if pinned {
+		updates = NewUpdateBatch()
+		updates.PubUpdates.Put("ns1", "key1", []byte("value3"), version.NewHeight(2, 1))
+		putPvtUpdates(t, updates, "ns1", "coll3", "key3", []byte("pvt_value3"), version.NewHeight(2, 1))
+		assert.NoError(t, db.ApplyPrivacyAwareUpdates(updates, version.NewHeight(2, 1)))
+	}
+	err = db.ExportPubAndHashedState(itr,
+		func(kv *statedb.VersionedKV) error {
+			pubKVs[kv.CompositeKey] = &kv.VersionedValue
+			return nil
+		},
+		func(ns, coll string, keyHash []byte, vv *statedb.VersionedValue) error {
+			hashedKVs[ns+"/"+coll+"/"+string(keyHash)] = vv
+			return nil
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, map[statedb.CompositeKey]*statedb.VersionedValue{
+		{Namespace: "ns1", Key: "key1"}: {Value: []byte("value1"), Version: version.NewHeight(1, 1)},
+		{Namespace: "ns2", Key: "key2"}: {Value: []byte("value2"), Version: version.NewHeight(1, 2)},
+	}, pubKVs)
+	assert.Equal(t, map[string]*statedb.VersionedValue{
+		"ns1/coll1/" + string(util.ComputeStringHash("key1")): {Value: util.ComputeStringHash("pvt_value1"), Version: version.NewHeight(1, 3)},
+		"ns1/coll2/" + string(util.ComputeStringHash("key2")): {Value: util.ComputeStringHash("pvt_value2"), Version: version.NewHeight(1, 4)},
+	}, hashedKVs)
+}
+
 //TODO add tests for functions GetPrivateStateMultipleKeys and GetPrivateStateRangeScanIterator
 
 func putPvtUpdates(t *testing.T, updates *UpdateBatch, ns, coll, key string, value []byte, ver *version.Height) {
@@ -0,0 +1,230 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pvtstatepurgemgmt
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/core/ledger/pvtdatapolicy"
+	"github.com/hyperledger/fabric/core/ledger/util"
+)
+
+var logger = flogging.MustGetLogger("pvtstatepurgemgmt")
+
+// PurgeMgr manages the purging of the expired pvt data from the private
+// state and the hashed state. It keeps track, in a bookkeeping database, of
+// when each committed key of a collection expires as per the BTL policy
+type PurgeMgr interface {
+	// DeleteExpiredAndUpdateBookkeeping adds to the updates the deletion of the keys which expire by the given block,
+	// unless the updates write them again, and schedules the expiry of the keys the updates write
+	DeleteExpiredAndUpdateBookkeeping(updates *privacyenabledstate.UpdateBatch, blockNum uint64) error
+	// BlockCommitDone is to be invoked once the updates passed to the last call to
+	// `DeleteExpiredAndUpdateBookkeeping` are committed to the state database
+	BlockCommitDone() error
+	// UpdateBookkeepingForPvtDataOfOldBlocks records the keys that the pvt data of old blocks, committed
+	// after the blocks, writes to the private state, so that they are purged along with their hashes
+	UpdateBookkeepingForPvtDataOfOldBlocks(pvtUpdates *privacyenabledstate.PvtUpdateBatch) error
+	// UpdateBookkeepingForImportedHashes records the keys of the hashed state imported from a snapshot, as of
+	// the blocks which wrote them, so that they are purged when they expire
+	UpdateBookkeepingForImportedHashes(hashUpdates *privacyenabledstate.HashedUpdateBatch) error
+}
+
+type purgeMgr struct {
+	db         privacyenabledstate.DB
+	btlPolicy  pvtdatapolicy.BTLPolicy
+	bookkeeper *leveldbhelper.DBHandle
+	processed  [][]byte
+}
+
+// expiryEntry is the bookkeeping of a key of a collection, written by the
+// committing block, which expires by the expiring block
+type expiryEntry struct {
+	expiringBlk   uint64
+	committingBlk uint64
+	ns, coll      string
+	keyHash       []byte
+}
+
+// NewPurgeMgr constructs a PurgeMgr for the given state database, which keeps
+// its bookkeeping in the given database handle
+func NewPurgeMgr(db privacyenabledstate.DB, btlPolicy pvtdatapolicy.BTLPolicy, bookkeeper *leveldbhelper.DBHandle) PurgeMgr {
+	return &purgeMgr{db: db, btlPolicy: btlPolicy, bookkeeper: bookkeeper}
+}
+
+// DeleteExpiredAndUpdateBookkeeping implements function in the interface `PurgeMgr`
+func (p *purgeMgr) DeleteExpiredAndUpdateBookkeeping(updates *privacyenabledstate.UpdateBatch, blockNum uint64) error {
+	p.processed = nil
+	if err := p.deleteExpired(updates, blockNum); err != nil {
+		return err
+	}
+	return p.scheduleExpiry(updates, blockNum)
+}
+
+// BlockCommitDone implements function in the interface `PurgeMgr`
+func (p *purgeMgr) BlockCommitDone() error {
+	if len(p.processed) == 0 {
+		return nil
+	}
+	batch := leveldbhelper.NewUpdateBatch()
+	for _, key := range p.processed {
+		batch.Delete(key)
+	}
+	p.processed = nil
+	return p.bookkeeper.WriteBatch(batch, true)
+}
+
+// UpdateBookkeepingForPvtDataOfOldBlocks implements function in the interface `PurgeMgr`
+func (p *purgeMgr) UpdateBookkeepingForPvtDataOfOldBlocks(pvtUpdates *privacyenabledstate.PvtUpdateBatch) error {
+	batch := leveldbhelper.NewUpdateBatch()
+	for ns, nsBatch := range pvtUpdates.UpdateMap {
+		for _, coll := range nsBatch.GetCollectionNames() {
+			for key, vv := range nsBatch.GetUpdates(coll) {
+				if vv.Value == nil {
+					continue
+				}
+				expiringBlk, err := p.btlPolicy.GetExpiringBlock(ns, coll, vv.Version.BlockNum)
+				if err != nil {
+					return err
+				}
+				if expiringBlk == math.MaxUint64 {
+					continue
+				}
+				entry := &expiryEntry{expiringBlk, vv.Version.BlockNum, ns, coll, util.ComputeStringHash(key)}
+				batch.Put(encodeExpiryKey(entry), []byte(key))
+			}
+		}
+	}
+	return p.bookkeeper.WriteBatch(batch, true)
+}
+
+// UpdateBookkeepingForImportedHashes implements function in the interface `PurgeMgr`.
+// The pvt data is not part of a snapshot, hence only the hashes are recorded
+func (p *purgeMgr) UpdateBookkeepingForImportedHashes(hashUpdates *privacyenabledstate.HashedUpdateBatch) error {
+	batch := leveldbhelper.NewUpdateBatch()
+	for ns, nsBatch := range hashUpdates.UpdateMap {
+		for _, coll := range nsBatch.GetCollectionNames() {
+			for keyHash, vv := range nsBatch.GetUpdates(coll) {
+				if vv.Value == nil {
+					continue
+				}
+				expiringBlk, err := p.btlPolicy.GetExpiringBlock(ns, coll, vv.Version.BlockNum)
+				if err != nil {
+					return err
+				}
+				if expiringBlk == math.MaxUint64 {
+					continue
+				}
+				entry := &expiryEntry{expiringBlk, vv.Version.BlockNum, ns, coll, []byte(keyHash)}
+				batch.Put(encodeExpiryKey(entry), []byte{})
+			}
+		}
+	}
+	return p.bookkeeper.WriteBatch(batch, true)
+}
+
+// deleteExpired adds to the updates the deletion of the keys which expire by
+// the given block. A key is left alone if it was written again since the block
+// the expiry entry was made for, as the later write is scheduled on its own
+func (p *purgeMgr) deleteExpired(updates *privacyenabledstate.UpdateBatch, blockNum uint64) error {
+	itr := p.bookkeeper.GetIterator(nil, version.NewHeight(blockNum+1, 0).ToBytes())
+	defer itr.Release()
+
+	deleteVersion := version.NewHeight(blockNum, 0)
+	for itr.Next() {
+		key := append([]byte{}, itr.Key()...)
+		p.processed = append(p.processed, key)
+		entry, err := decodeExpiryKey(key)
+		if err != nil {
+			return err
+		}
+		if updates.HashUpdates.Contains(entry.ns, entry.coll, entry.keyHash) {
+			continue
+		}
+		vv, err := p.db.GetValueHash(entry.ns, entry.coll, entry.keyHash)
+		if err != nil {
+			return err
+		}
+		if vv == nil || vv.Version.BlockNum != entry.committingBlk {
+			continue
+		}
+		logger.Debugf("Purging expired key of [%s:%s] written by block [%d]", entry.ns, entry.coll, entry.committingBlk)
+		updates.HashUpdates.Delete(entry.ns, entry.coll, entry.keyHash, deleteVersion)
+		if pvtKey := itr.Value(); len(pvtKey) > 0 {
+			updates.PvtUpdates.Delete(entry.ns, entry.coll, string(pvtKey), deleteVersion)
+		}
+	}
+	return nil
+}
+
+// scheduleExpiry records the expiry of the keys that the updates write to the
+// collections with a BTL. The key itself is recorded along with the hash when
+// the updates carry the pvt data, so that it can be deleted from the private state
+func (p *purgeMgr) scheduleExpiry(updates *privacyenabledstate.UpdateBatch, blockNum uint64) error {
+	batch := leveldbhelper.NewUpdateBatch()
+	for ns, nsBatch := range updates.HashUpdates.UpdateMap {
+		for _, coll := range nsBatch.GetCollectionNames() {
+			expiringBlk, err := p.btlPolicy.GetExpiringBlock(ns, coll, blockNum)
+			if err != nil {
+				return err
+			}
+			if expiringBlk == math.MaxUint64 {
+				continue
+			}
+			pvtKeys := pvtKeysByHash(updates.PvtUpdates, ns, coll)
+			for keyHash, vv := range nsBatch.GetUpdates(coll) {
+				if vv.Value == nil {
+					continue
+				}
+				entry := &expiryEntry{expiringBlk, blockNum, ns, coll, []byte(keyHash)}
+				batch.Put(encodeExpiryKey(entry), []byte(pvtKeys[keyHash]))
+			}
+		}
+	}
+	return p.bookkeeper.WriteBatch(batch, true)
+}
+
+// pvtKeysByHash maps the hashes of the keys that the updates write to the
+// given collection to the keys
+func pvtKeysByHash(pvtUpdates *privacyenabledstate.PvtUpdateBatch, ns, coll string) map[string]string {
+	pvtKeys := make(map[string]string)
+	nsBatch, ok := pvtUpdates.UpdateMap[ns]
+	if !ok {
+		return pvtKeys
+	}
+	for key := range nsBatch.GetUpdates(coll) {
+		pvtKeys[string(util.ComputeStringHash(key))] = key
+	}
+	return pvtKeys
+}
+
+var separator = []byte{0x00}
+
+// encodeExpiryKey encodes an expiry entry so that the entries sort by the
+// block by which they expire
+func encodeExpiryKey(entry *expiryEntry) []byte {
+	key := version.NewHeight(entry.expiringBlk, entry.committingBlk).ToBytes()
+	key = append(key, []byte(entry.ns)...)
+	key = append(key, separator...)
+	key = append(key, []byte(entry.coll)...)
+	key = append(key, separator...)
+	return append(key, entry.keyHash...)
+}
+
+func decodeExpiryKey(key []byte) (*expiryEntry, error) {
+	height, n := version.NewHeightFromBytes(key)
+	parts := bytes.SplitN(key[n:], separator, 3)
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid expiry key %#v", key)
+	}
+	return &expiryEntry{height.BlockNum, height.TxNum, string(parts[0]), string(parts[1]), parts[2]}, nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pvtstatepurgemgmt
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/hyperledger/fabric/core/ledger/pvtdatapolicy"
+	"github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	viper.Set("peer.fileSystemPath", "/tmp/fabric/ledgertests/kvledger/txmgmt/pvtstatepurgemgmt")
+	os.Exit(m.Run())
+}
+
+type testBTLPolicy map[string]uint64
+
+func (p testBTLPolicy) GetBTL(ns string, coll string) (uint64, error) {
+	return p[ns+"/"+coll], nil
+}
+
+func (p testBTLPolicy) GetExpiringBlock(ns string, coll string, committingBlock uint64) (uint64, error) {
+	return pvtdatapolicy.ComputeExpiringBlock(committingBlock, p[ns+"/"+coll]), nil
+}
+
+func TestPurgeMgr(t *testing.T) {
+	dbEnv := &privacyenabledstate.LevelDBCommonStorageTestEnv{}
+	dbEnv.Init(t)
+	defer dbEnv.Cleanup()
+	db := dbEnv.GetDBHandle("testledger")
+	assert.NoError(t, db.Open())
+
+	bookkeeperPath := ledgerconfig.GetInternalBookkeeperPath()
+	assert.NoError(t, os.RemoveAll(bookkeeperPath))
+	defer os.RemoveAll(bookkeeperPath)
+	bookkeepingProvider := leveldbhelper.NewProvider(&leveldbhelper.Conf{DBPath: bookkeeperPath})
+	defer bookkeepingProvider.Close()
+
+	purgeMgr := NewPurgeMgr(db, testBTLPolicy{"ns1/coll1": 1}, bookkeepingProvider.GetDBHandle("testledger"))
+	commit := func(blockNum uint64, updates *privacyenabledstate.UpdateBatch) {
+		assert.NoError(t, purgeMgr.DeleteExpiredAndUpdateBookkeeping(updates, blockNum))
+		assert.NoError(t, db.ApplyPrivacyAwareUpdates(updates, version.NewHeight(blockNum, 1)))
+		assert.NoError(t, purgeMgr.BlockCommitDone())
+	}
+	assertExists := func(ns, coll, key string, expected bool) {
+		vv, err := db.GetPrivateData(ns, coll, key)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, vv != nil, "pvt data of key %s", key)
+		vv, err = db.GetValueHash(ns, coll, util.ComputeStringHash(key))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, vv != nil, "hashed data of key %s", key)
+	}
+
+	updates := privacyenabledstate.NewUpdateBatch()
+	putPvtAndHash(updates, "ns1", "coll1", "key1", []byte("value1"), version.NewHeight(1, 1))
+	putPvtAndHash(updates, "ns1", "coll1", "key2", []byte("value2"), version.NewHeight(1, 1))
+	putPvtAndHash(updates, "ns1", "coll2", "key3", []byte("value3"), version.NewHeight(1, 1))
+	commit(1, updates)
+
+	// key2 is written again, so that it expires later
+	updates = privacyenabledstate.NewUpdateBatch()
+	putPvtAndHash(updates, "ns1", "coll1", "key2", []byte("value2-2"), version.NewHeight(2, 1))
+	commit(2, updates)
+	assertExists("ns1", "coll1", "key1", true)
+
+	commit(3, privacyenabledstate.NewUpdateBatch())
+	assertExists("ns1", "coll1", "key1", false)
+	assertExists("ns1", "coll1", "key2", true)
+	assertExists("ns1", "coll2", "key3", true)
+
+	commit(4, privacyenabledstate.NewUpdateBatch())
+	assertExists("ns1", "coll1", "key2", false)
+	assertExists("ns1", "coll2", "key3", true)
+
+	// the bookkeeping of the expired keys is gone
+	itr := bookkeepingProvider.GetDBHandle("testledger").GetIterator(nil, nil)
+	defer itr.Release()
+	assert.False(t, itr.Next())
+}
+
+func TestPurgeMgrImportedHashes(t *testing.T) {
+	dbEnv := &privacyenabledstate.LevelDBCommonStorageTestEnv{}
+	dbEnv.Init(t)
+	defer dbEnv.Cleanup()
+	db := dbEnv.GetDBHandle("testledger")
+
+	bookkeeperPath := ledgerconfig.GetInternalBookkeeperPath()
+	assert.NoError(t, os.RemoveAll(bookkeeperPath))
+	defer os.RemoveAll(bookkeeperPath)
+	bookkeepingProvider := leveldbhelper.NewProvider(&leveldbhelper.Conf{DBPath: bookkeeperPath})
+	defer bookkeepingProvider.Close()
+
+	// The hashes are imported as of block 5, key1 having been written by block 4 and key2 by block 5
+	purgeMgr := NewPurgeMgr(db, testBTLPolicy{"ns1/coll1": 1}, bookkeepingProvider.GetDBHandle("testledger"))
+	imported := privacyenabledstate.NewUpdateBatch()
+	imported.HashUpdates.Put("ns1", "coll1", util.ComputeStringHash("key1"), util.ComputeHash([]byte("value1")), version.NewHeight(4, 0))
+	imported.HashUpdates.Put("ns1", "coll1", util.ComputeStringHash("key2"), util.ComputeHash([]byte("value2")), version.NewHeight(5, 0))
+	assert.NoError(t, db.ApplyPrivacyAwareUpdates(imported, version.NewHeight(5, 0)))
+	assert.NoError(t, purgeMgr.UpdateBookkeepingForImportedHashes(imported.HashUpdates))
+
+	commit := func(blockNum uint64) {
+		updates := privacyenabledstate.NewUpdateBatch()
+		assert.NoError(t, purgeMgr.DeleteExpiredAndUpdateBookkeeping(updates, blockNum))
+		assert.NoError(t, db.ApplyPrivacyAwareUpdates(updates, version.NewHeight(blockNum, 0)))
+		assert.NoError(t, purgeMgr.BlockCommitDone())
+	}
+	assertHashExists := func(key string, expected bool) {
+		vv, err := db.GetValueHash("ns1", "coll1", util.ComputeStringHash(key))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, vv != nil, "hashed data of key %s", key)
+	}
+
+	commit(6)
+	assertHashExists("key1", false)
+	assertHashExists("key2", true)
+	commit(7)
+	assertHashExists("key2", false)
+}
+
+func TestExpiryKeyEncoding(t *testing.T) {
+	entry := &expiryEntry{expiringBlk: 12, committingBlk: 2, ns: "ns", coll: "coll", keyHash: []byte{0x00, 0x01}}
+	decoded, err := decodeExpiryKey(encodeExpiryKey(entry))
+	assert.NoError(t, err)
+	assert.Equal(t, entry, decoded)
+
+	_, err = decodeExpiryKey(version.NewHeight(1, 1).ToBytes())
+	assert.Error(t, err)
+}
+
+func putPvtAndHash(updates *privacyenabledstate.UpdateBatch, ns, coll, key string, value []byte, ver *version.Height) {
+	updates.PvtUpdates.Put(ns, coll, key, value, ver)
+	updates.HashUpdates.Put(ns, coll, util.ComputeStringHash(key), util.ComputeHash(value), ver)
+}
@@ -210,6 +210,45 @@ func testItr(t *testing.T, itr statedb.ResultsIterator, expectedKeys []string) {
 	testutil.AssertNil(t, last)
 }
 
+// TestDrop tests dropping a db
+func TestDrop(t *testing.T, dbProvider statedb.VersionedDBProvider) {
+	db1, err := dbProvider.GetDBHandle("testdrop")
+	testutil.AssertNoError(t, err, "")
+	db2, err := dbProvider.GetDBHandle("testdrop2")
+	testutil.AssertNoError(t, err, "")
+
+	batch := statedb.NewUpdateBatch()
+	batch.Put("ns1", "key1", []byte("value1"), version.NewHeight(1, 1))
+	batch.Put("ns2", "key2", []byte("value2"), version.NewHeight(1, 2))
+	savePoint := version.NewHeight(1, 2)
+	testutil.AssertNoError(t, db1.ApplyUpdates(batch, savePoint), "")
+	testutil.AssertNoError(t, db2.ApplyUpdates(batch, savePoint), "")
+
+	testutil.AssertNoError(t, dbProvider.Drop("testdrop"), "")
+	db1, err = dbProvider.GetDBHandle("testdrop")
+	testutil.AssertNoError(t, err, "")
+	sp, err := db1.GetLatestSavePoint()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, sp)
+	vv, err := db1.GetState("ns1", "key1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, vv)
+
+	// The other dbs are left alone
+	vv, err = db2.GetState("ns2", "key2")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, vv.Value, []byte("value2"))
+	sp, err = db2.GetLatestSavePoint()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, sp, savePoint)
+
+	// The dropped db can be used again
+	testutil.AssertNoError(t, db1.ApplyUpdates(batch, savePoint), "")
+	vv, err = db1.GetState("ns1", "key1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, vv.Value, []byte("value1"))
+}
+
 // TestQuery tests queries
 func TestQuery(t *testing.T, dbProvider statedb.VersionedDBProvider) {
 	db, err := dbProvider.GetDBHandle("testquery")
@@ -92,6 +92,26 @@ func (provider *VersionedDBProvider) GetDBHandle(dbName string) (statedb.Version
 	return vdb, nil
 }
 
+// Drop implements method in VersionedDBProvider interface.
+// The couch database is dropped, and created again by the next call to GetDBHandle
+func (provider *VersionedDBProvider) Drop(dbName string) error {
+	provider.mux.Lock()
+	defer provider.mux.Unlock()
+
+	vdb := provider.databases[dbName]
+	if vdb == nil {
+		var err error
+		if vdb, err = newVersionedDB(provider.couchInstance, dbName); err != nil {
+			return err
+		}
+	}
+	if _, err := vdb.db.DropDatabase(); err != nil {
+		return err
+	}
+	delete(provider.databases, dbName)
+	return nil
+}
+
 // Close closes the underlying db instance
 func (provider *VersionedDBProvider) Close() {
 	// No close needed on Couch
@@ -297,6 +317,13 @@ func (vdb *VersionedDB) ExecuteQuery(namespace, query string) (statedb.ResultsIt
 	return newQueryScanner(*queryResult), nil
 }
 
+// GetFullScanIterator implements method in VersionedDB interface.
+// The documents are read a page at a time, the page size being the query limit from core.yaml.
+// CouchDB has no snapshot of a database, so the iterator is not pinned
+func (vdb *VersionedDB) GetFullScanIterator() (statedb.ResultsIterator, bool, error) {
+	return &fullScanner{db: vdb.db, pageSize: ledgerconfig.GetQueryLimit()}, false, nil
+}
+
 // ApplyUpdates implements method in VersionedDB interface
 func (vdb *VersionedDB) ApplyUpdates(batch *statedb.UpdateBatch, height *version.Height) error {
 
@@ -726,3 +753,48 @@ func (scanner *queryScanner) Next() (statedb.QueryResult, error) {
 func (scanner *queryScanner) Close() {
 	scanner = nil
 }
+
+// fullScanner iterates over the documents of all the namespaces, leaving out
+// the savepoint and the design documents, whose ids carry no namespace
+type fullScanner struct {
+	db       *couchdb.CouchDatabase
+	pageSize int
+	skip     int
+	cursor   int
+	results  []couchdb.QueryResult
+	done     bool
+}
+
+func (scanner *fullScanner) Next() (statedb.QueryResult, error) {
+	for {
+		if scanner.cursor >= len(scanner.results) {
+			if scanner.done {
+				return nil, nil
+			}
+			queryResult, err := scanner.db.ReadDocRange("", "", scanner.pageSize, scanner.skip)
+			if err != nil {
+				logger.Debugf("Error calling ReadDocRange(): %s\n", err.Error())
+				return nil, err
+			}
+			scanner.results = *queryResult
+			scanner.cursor = 0
+			scanner.skip += len(scanner.results)
+			scanner.done = len(scanner.results) == 0 || len(scanner.results) < scanner.pageSize
+			continue
+		}
+		selectedKV := scanner.results[scanner.cursor]
+		scanner.cursor++
+		if !strings.Contains(selectedKV.ID, string(compositeKeySep)) {
+			continue
+		}
+		namespace, key := splitCompositeKey([]byte(selectedKV.ID))
+		returnValue, returnVersion := removeDataWrapper(selectedKV.Value, selectedKV.Attachments)
+		return &statedb.VersionedKV{
+			CompositeKey:   statedb.CompositeKey{Namespace: namespace, Key: key},
+			VersionedValue: statedb.VersionedValue{Value: returnValue, Version: returnVersion}}, nil
+	}
+}
+
+func (scanner *fullScanner) Close() {
+	scanner.results = nil
+}
@@ -27,6 +27,9 @@ import (
 type VersionedDBProvider interface {
 	// GetDBHandle returns a handle to a VersionedDB
 	GetDBHandle(id string) (VersionedDB, error)
+	// Drop deletes all the data of the VersionedDB with the given id, including its savepoint.
+	// The VersionedDB is expected not to be in use
+	Drop(id string) error
 	// Close closes all the VersionedDB instances and releases any resources held by VersionedDBProvider
 	Close()
 }
@@ -46,6 +49,10 @@ type VersionedDB interface {
 	GetStateRangeScanIterator(namespace string, startKey string, endKey string) (ResultsIterator, error)
 	// ExecuteQuery executes the given query and returns an iterator that contains results of type *VersionedKV.
 	ExecuteQuery(namespace, query string) (ResultsIterator, error)
+	// GetFullScanIterator returns an iterator over all the key-values of all the namespaces, leaving out the savepoint.
+	// The returned ResultsIterator contains results of type *VersionedKV. The returned bool tells whether the iterator
+	// is pinned to the state as of the call, the updates applied while it is open not showing up in the results
+	GetFullScanIterator() (ResultsIterator, bool, error)
 	// ApplyUpdates applies the batch to the underlying db.
 	// height is the height of the highest transaction in the Batch that
 	// a state db implementation is expected to ues as a save point
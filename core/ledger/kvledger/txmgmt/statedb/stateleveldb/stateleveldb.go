@@ -52,6 +52,12 @@ func (provider *VersionedDBProvider) GetDBHandle(dbName string) (statedb.Version
 	return newVersionedDB(provider.dbProvider.GetDBHandle(dbName), dbName), nil
 }
 
+// Drop implements method in VersionedDBProvider interface.
+// The savepoint is the first key of the db, hence it is deleted first
+func (provider *VersionedDBProvider) Drop(dbName string) error {
+	return provider.dbProvider.GetDBHandle(dbName).DeleteAll()
+}
+
 // Close closes the underlying db
 func (provider *VersionedDBProvider) Close() {
 	provider.dbProvider.Close()
@@ -147,6 +153,12 @@ func (vdb *versionedDB) ExecuteQuery(namespace, query string) (statedb.ResultsIt
 	return nil, errors.New("ExecuteQuery not supported for leveldb")
 }
 
+// GetFullScanIterator implements method in VersionedDB interface.
+// A leveldb iterator reads a snapshot of the db taken when the iterator is created
+func (vdb *versionedDB) GetFullScanIterator() (statedb.ResultsIterator, bool, error) {
+	return &fullScanner{vdb.db.GetIterator(nil, nil)}, true, nil
+}
+
 // ApplyUpdates implements method in VersionedDB interface
 func (vdb *versionedDB) ApplyUpdates(batch *statedb.UpdateBatch, height *version.Height) error {
 	dbBatch := leveldbhelper.NewUpdateBatch()
@@ -220,3 +232,30 @@ func (scanner *kvScanner) Next() (statedb.QueryResult, error) {
 func (scanner *kvScanner) Close() {
 	scanner.dbItr.Release()
 }
+
+// fullScanner iterates over the keys of all the namespaces
+type fullScanner struct {
+	dbItr iterator.Iterator
+}
+
+func (scanner *fullScanner) Next() (statedb.QueryResult, error) {
+	for scanner.dbItr.Next() {
+		dbKey := scanner.dbItr.Key()
+		if bytes.Equal(dbKey, savePointKey) {
+			continue
+		}
+		dbVal := scanner.dbItr.Value()
+		dbValCopy := make([]byte, len(dbVal))
+		copy(dbValCopy, dbVal)
+		namespace, key := splitCompositeKey(dbKey)
+		value, version := statedb.DecodeValue(dbValCopy)
+		return &statedb.VersionedKV{
+			CompositeKey:   statedb.CompositeKey{Namespace: namespace, Key: key},
+			VersionedValue: statedb.VersionedValue{Value: value, Version: version}}, nil
+	}
+	return nil, nil
+}
+
+func (scanner *fullScanner) Close() {
+	scanner.dbItr.Release()
+}
@@ -56,6 +56,12 @@ func TestIterator(t *testing.T) {
 	commontests.TestIterator(t, env.DBProvider)
 }
 
+func TestDrop(t *testing.T) {
+	env := NewTestVDBEnv(t)
+	defer env.Cleanup()
+	commontests.TestDrop(t, env.DBProvider)
+}
+
 func TestEncodeDecodeValueAndVersion(t *testing.T) {
 	testValueAndVersionEncoding(t, []byte("value1"), version.NewHeight(1, 2))
 	testValueAndVersionEncoding(t, []byte{}, version.NewHeight(50, 50))
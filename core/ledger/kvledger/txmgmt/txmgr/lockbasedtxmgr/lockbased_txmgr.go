@@ -22,9 +22,12 @@ import (
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/pvtstatepurgemgmt"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/validator"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/validator/valimpl"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric/core/transientstore"
 	"github.com/hyperledger/fabric/protos/common"
 )
@@ -39,12 +42,14 @@ type LockBasedTxMgr struct {
 	batch        *privacyenabledstate.UpdateBatch
 	currentBlock *common.Block
 	commitRWLock sync.RWMutex
+	purgeMgr     pvtstatepurgemgmt.PurgeMgr
 }
 
-// NewLockBasedTxMgr constructs a new instance of NewLockBasedTxMgr
-func NewLockBasedTxMgr(db privacyenabledstate.DB, tStore transientstore.Store) *LockBasedTxMgr {
+// NewLockBasedTxMgr constructs a new instance of NewLockBasedTxMgr.
+// The purge manager, if any, purges the expired pvt data from the state along with each block
+func NewLockBasedTxMgr(db privacyenabledstate.DB, tStore transientstore.Store, purgeMgr pvtstatepurgemgmt.PurgeMgr) *LockBasedTxMgr {
 	db.Open()
-	txmgr := &LockBasedTxMgr{db: db}
+	txmgr := &LockBasedTxMgr{db: db, purgeMgr: purgeMgr}
 	txmgr.validator = valimpl.NewStatebasedValidator(txmgr, db)
 	return txmgr
 }
@@ -81,6 +86,11 @@ func (txmgr *LockBasedTxMgr) ValidateAndPrepare(blockAndPvtdata *ledger.BlockAnd
 	if err != nil {
 		return err
 	}
+	if txmgr.purgeMgr != nil {
+		if err := txmgr.purgeMgr.DeleteExpiredAndUpdateBookkeeping(batch, block.Header.Number); err != nil {
+			return err
+		}
+	}
 	txmgr.currentBlock = block
 	txmgr.batch = batch
 	return err
@@ -105,6 +115,11 @@ func (txmgr *LockBasedTxMgr) Commit() error {
 		version.NewHeight(txmgr.currentBlock.Header.Number, uint64(len(txmgr.currentBlock.Data.Data)-1))); err != nil {
 		return err
 	}
+	if txmgr.purgeMgr != nil {
+		if err := txmgr.purgeMgr.BlockCommitDone(); err != nil {
+			return err
+		}
+	}
 	logger.Debugf("Updates committed to state database")
 	return nil
 }
@@ -139,3 +154,68 @@ func (txmgr *LockBasedTxMgr) CommitLostBlock(blockAndPvtdata *ledger.BlockAndPvt
 	}
 	return nil
 }
+
+// CommitPvtDataOfOldBlocks implements method in interface `txmgmt.TxMgr`.
+// A write of the pvt data is applied to the state only if it is still the latest one, i.e., if the version
+// of the corresponding hashed key is the one of the transaction, or, for a delete, if the hashed key is deleted
+// and the private state holds an older version of the key
+func (txmgr *LockBasedTxMgr) CommitPvtDataOfOldBlocks(blocksPvtData map[uint64][]*ledger.TxPvtData) error {
+	txmgr.commitRWLock.Lock()
+	defer txmgr.commitRWLock.Unlock()
+	savepoint, err := txmgr.GetLastSavepoint()
+	if err != nil || savepoint == nil {
+		return err
+	}
+	updates := privacyenabledstate.NewUpdateBatch()
+	for blockNum, blockPvtData := range blocksPvtData {
+		for _, txPvtData := range blockPvtData {
+			pvtRWSet, err := rwsetutil.TxPvtRwSetFromProtoMsg(txPvtData.WriteSet)
+			if err != nil {
+				return err
+			}
+			if err := txmgr.addLatestPvtWrites(updates.PvtUpdates, pvtRWSet, version.NewHeight(blockNum, txPvtData.SeqInBlock)); err != nil {
+				return err
+			}
+		}
+	}
+	if updates.PvtUpdates.IsEmpty() {
+		return nil
+	}
+	if txmgr.purgeMgr != nil {
+		if err := txmgr.purgeMgr.UpdateBookkeepingForPvtDataOfOldBlocks(updates.PvtUpdates); err != nil {
+			return err
+		}
+	}
+	return txmgr.db.ApplyPrivacyAwareUpdates(updates, savepoint)
+}
+
+func (txmgr *LockBasedTxMgr) addLatestPvtWrites(pvtUpdates *privacyenabledstate.PvtUpdateBatch,
+	pvtRWSet *rwsetutil.TxPvtRwSet, ver *version.Height) error {
+	for _, ns := range pvtRWSet.NsPvtRwSet {
+		for _, coll := range ns.CollPvtRwSets {
+			for _, kvwrite := range coll.KvRwSet.Writes {
+				hashedVV, err := txmgr.db.GetValueHash(ns.NameSpace, coll.CollectionName, util.ComputeStringHash(kvwrite.Key))
+				if err != nil {
+					return err
+				}
+				if !kvwrite.IsDelete {
+					if hashedVV != nil && version.AreSame(hashedVV.Version, ver) {
+						pvtUpdates.Put(ns.NameSpace, coll.CollectionName, kvwrite.Key, kvwrite.Value, ver)
+					}
+					continue
+				}
+				if hashedVV != nil {
+					continue
+				}
+				pvtVV, err := txmgr.db.GetPrivateData(ns.NameSpace, coll.CollectionName, kvwrite.Key)
+				if err != nil {
+					return err
+				}
+				if pvtVV != nil && pvtVV.Version.Compare(ver) < 0 {
+					pvtUpdates.Delete(ns.NameSpace, coll.CollectionName, kvwrite.Key, ver)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -78,7 +78,7 @@ func (env *lockBasedEnv) init(t *testing.T, testLedgerID string) {
 	env.testTStoreEnv = transientstore.NewTestStoreEnv(t)
 	testTransientStore, err := env.testTStoreEnv.TestStoreProvider.OpenStore(testLedgerID)
 	testutil.AssertNoError(t, err, "")
-	env.txmgr = NewLockBasedTxMgr(env.testDB, testTransientStore)
+	env.txmgr = NewLockBasedTxMgr(env.testDB, testTransientStore, nil)
 }
 
 func (env *lockBasedEnv) getTxMgr() txmgr.TxMgr {
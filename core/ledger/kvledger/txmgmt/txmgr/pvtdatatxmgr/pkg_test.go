@@ -50,7 +50,7 @@ func (env *TestEnv) Init(t *testing.T, testLedgerID string) {
 	var err error
 	env.TStore, err = env.TStoreEnv.TestStoreProvider.OpenStore(testLedgerID)
 	testutil.AssertNoError(t, err, "")
-	env.Txmgr = NewLockbasedTxMgr(env.DB, env.TStore)
+	env.Txmgr = NewLockbasedTxMgr(env.DB, env.TStore, nil)
 }
 
 // Cleanup cleansup the test environment
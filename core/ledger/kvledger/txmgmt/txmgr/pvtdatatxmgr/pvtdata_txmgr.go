@@ -10,6 +10,7 @@ import (
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/privacyenabledstate"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/pvtstatepurgemgmt"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/txmgr"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/txmgr/lockbasedtxmgr"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
@@ -27,8 +28,8 @@ type TransientHandlerTxMgr struct {
 }
 
 // NewLockbasedTxMgr constructs a new instance of TransientHandlerTxMgr
-func NewLockbasedTxMgr(db privacyenabledstate.DB, tStore transientstore.Store) *TransientHandlerTxMgr {
-	return &TransientHandlerTxMgr{lockbasedtxmgr.NewLockBasedTxMgr(db, tStore), tStore}
+func NewLockbasedTxMgr(db privacyenabledstate.DB, tStore transientstore.Store, purgeMgr pvtstatepurgemgmt.PurgeMgr) *TransientHandlerTxMgr {
+	return &TransientHandlerTxMgr{lockbasedtxmgr.NewLockBasedTxMgr(db, tStore, purgeMgr), tStore}
 }
 
 // NewTxSimulator extends the implementation of this function in the wrapped txmgr.
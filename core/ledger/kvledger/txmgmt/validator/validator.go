@@ -26,23 +26,12 @@ type Validator interface {
 	ValidateAndPrepareBatch(blockAndPvtdata *ledger.BlockAndPvtData, doMVCCValidation bool) (*privacyenabledstate.UpdateBatch, error)
 }
 
-// ErrMissingPvtdata is to be thrown if a collection is present in the public read-write set
-// but the corresponding pvt data is missing in the pvt data supplied with the block for validation
-// TODO When we implement functionalities planned for the phase-2, this assumption may not be true
-type ErrMissingPvtdata struct {
-	Msg string
-}
-
 // ErrPvtdataHashMissmatch is to be thrown if the hash of a collection present in the public read-write set
 // does not match with the corresponding pvt data  supplied with the block for validation
 type ErrPvtdataHashMissmatch struct {
 	Msg string
 }
 
-func (e *ErrMissingPvtdata) Error() string {
-	return e.Msg
-}
-
 func (e *ErrPvtdataHashMissmatch) Error() string {
 	return e.Msg
 }
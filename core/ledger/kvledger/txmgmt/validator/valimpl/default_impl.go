@@ -41,6 +41,7 @@ func (impl *DefaultImpl) ValidateAndPrepareBatch(blockAndPvtdata *ledger.BlockAn
 	var internalBlock *valinternal.Block
 	var pubAndHashUpdates *valinternal.PubAndHashUpdates
 	var pvtUpdates *privacyenabledstate.PvtUpdateBatch
+	var missingPvtData []*ledger.MissingPvtData
 	var err error
 
 	logger.Debug("preprocessing ProtoBlock...")
@@ -52,9 +53,10 @@ func (impl *DefaultImpl) ValidateAndPrepareBatch(blockAndPvtdata *ledger.BlockAn
 		return nil, err
 	}
 	logger.Debug("validating rwset...")
-	if pvtUpdates, err = validateAndPreparePvtBatch(internalBlock, blockAndPvtdata.BlockPvtData); err != nil {
+	if pvtUpdates, missingPvtData, err = validateAndPreparePvtBatch(internalBlock, blockAndPvtdata.BlockPvtData, blockAndPvtdata.IsEligible); err != nil {
 		return nil, err
 	}
+	blockAndPvtdata.MissingPvtData = missingPvtData
 	logger.Debug("postprocessing ProtoBlock...")
 	postprocessProtoBlock(block, internalBlock)
 	logger.Debug("ValidateAndPrepareBatch() complete")
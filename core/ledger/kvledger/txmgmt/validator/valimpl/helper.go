@@ -27,9 +27,12 @@ import (
 
 // validateAndPreparePvtBatch pulls out the private write-set from transient store for the transactions that are marked as valid
 // by the internal public data validator. Finally, it validates (if not already self-endorsed) the pvt rwset against the
-// corresponding hash present in the public rwset
-func validateAndPreparePvtBatch(block *valinternal.Block, pvtdata map[uint64]*ledger.TxPvtData) (*privacyenabledstate.PvtUpdateBatch, error) {
+// corresponding hash present in the public rwset. The collections of the valid transactions for which no pvt data
+// is supplied, and which the peer is eligible to, are returned as missing, so that the block can be committed without them
+func validateAndPreparePvtBatch(block *valinternal.Block, pvtdata map[uint64]*ledger.TxPvtData,
+	isEligible ledger.PvtDataEligibility) (*privacyenabledstate.PvtUpdateBatch, []*ledger.MissingPvtData, error) {
 	pvtUpdates := privacyenabledstate.NewPvtUpdateBatch()
+	var missingPvtData []*ledger.MissingPvtData
 	for _, tx := range block.Txs {
 		if tx.ValidationCode != peer.TxValidationCode_VALID {
 			continue
@@ -38,23 +41,64 @@ func validateAndPreparePvtBatch(block *valinternal.Block, pvtdata map[uint64]*le
 			continue
 		}
 		txPvtdata := pvtdata[uint64(tx.IndexInBlock)]
+		txMissingPvtData, err := findMissingPvtData(block.Num, tx, txPvtdata, isEligible)
+		if err != nil {
+			return nil, nil, err
+		}
+		missingPvtData = append(missingPvtData, txMissingPvtData...)
 		if txPvtdata == nil {
-			return nil,
-				&validator.ErrMissingPvtdata{Msg: fmt.Sprintf("Pvt data missing for the transaction tx num [%d]", tx.IndexInBlock)}
+			continue
 		}
 		if requiresPvtdataValidation(txPvtdata) {
 			if err := validatePvtdata(tx, txPvtdata); err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 		}
 		var pvtRWSet *rwsetutil.TxPvtRwSet
-		var err error
 		if pvtRWSet, err = rwsetutil.TxPvtRwSetFromProtoMsg(txPvtdata.WriteSet); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		addPvtRWSetToPvtUpdateBatch(pvtRWSet, pvtUpdates, version.NewHeight(block.Num, uint64(tx.IndexInBlock)))
 	}
-	return pvtUpdates, nil
+	return pvtUpdates, missingPvtData, nil
+}
+
+// findMissingPvtData returns the collections, present in the public read-write set of the transaction, whose
+// pvt data is not present in the supplied pvt data. The collections the peer is not eligible to are left out,
+// as their pvt data is never to be received
+func findMissingPvtData(blockNum uint64, tx *valinternal.Transaction, txPvtdata *ledger.TxPvtData,
+	isEligible ledger.PvtDataEligibility) ([]*ledger.MissingPvtData, error) {
+	var missingPvtData []*ledger.MissingPvtData
+	for _, ns := range tx.RWSet.NsRwSets {
+		for _, coll := range ns.CollHashedRwSets {
+			if coll.PvtRwSetHash == nil {
+				continue
+			}
+			if txPvtdata != nil && txPvtdata.Has(ns.NameSpace, coll.CollectionName) {
+				continue
+			}
+			if isEligible != nil {
+				eligible, err := isEligible(ns.NameSpace, coll.CollectionName)
+				if err != nil {
+					return nil, err
+				}
+				if !eligible {
+					logger.Debugf("Not eligible to pvt data for collection [%s:%s] of the transaction tx num [%d] of block [%d]",
+						ns.NameSpace, coll.CollectionName, tx.IndexInBlock, blockNum)
+					continue
+				}
+			}
+			logger.Debugf("Pvt data for collection [%s:%s] missing for the transaction tx num [%d] of block [%d]",
+				ns.NameSpace, coll.CollectionName, tx.IndexInBlock, blockNum)
+			missingPvtData = append(missingPvtData, &ledger.MissingPvtData{
+				BlockNum:   blockNum,
+				SeqInBlock: uint64(tx.IndexInBlock),
+				Namespace:  ns.NameSpace,
+				Collection: coll.CollectionName,
+			})
+		}
+	}
+	return missingPvtData, nil
 }
 
 // requiresPvtdataValidation returns whether or not a hashes of the collection should be computed
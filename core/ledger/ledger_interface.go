@@ -17,6 +17,8 @@ limitations under the License.
 package ledger
 
 import (
+	"time"
+
 	"github.com/golang/protobuf/proto"
 	commonledger "github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/protos/common"
@@ -36,6 +38,10 @@ type PeerLedgerProvider interface {
 	Exists(ledgerID string) (bool, error)
 	// List lists the ids of the existing ledgers
 	List() ([]string, error)
+	// CreateFromSnapshot creates a new ledger from a snapshot exported by a peer of the channel.
+	// The ledger starts at the height of the snapshot and the blocks before it are not available,
+	// apart from the last block and the latest config block
+	CreateFromSnapshot(snapshotDir string) (PeerLedger, error)
 	// Close closes the PeerLedgerProvider
 	Close()
 }
@@ -80,8 +86,39 @@ type PeerLedger interface {
 	PurgePrivateData(maxBlockNumToRetain uint64) error
 	// PrivateDataMinBlockNum returns the lowest retained endorsement block height
 	PrivateDataMinBlockNum() (uint64, error)
-	//Prune prunes the blocks/transactions that satisfy the given policy
+	// GetMissingPvtData returns, the oldest blocks first starting at block fromBlockNum, up to maxEntries of the
+	// collections whose pvt data was not available when their block was committed and is still missing.
+	// A maxEntries of 0 does not limit the results
+	GetMissingPvtData(fromBlockNum uint64, maxEntries int) ([]*MissingPvtData, error)
+	// GetMissingPvtDataCount returns the number of transactions, per namespace and collection, whose pvt data is missing
+	GetMissingPvtDataCount() (MissingPvtDataCount, error)
+	// CommitPvtDataOfOldBlocks commits the pvt data, keyed by block number, that was missing when the blocks
+	// were committed. Only the collections still missing whose hash matches with the one present in
+	// the public read-write set of the transaction are committed
+	CommitPvtDataOfOldBlocks(blocksPvtData map[uint64][]*TxPvtData) error
+	//Prune prunes the blocks/transactions that satisfy the given policy, which is a BlockPrunePolicy
 	Prune(policy commonledger.PrunePolicy) error
+	// SubmitSnapshotRequest requests the export of a snapshot of the ledger as of the given block, which
+	// is taken once the block is committed. A blockNum of 0 stands for the last committed block.
+	// The commits are paused while the snapshot is exported
+	SubmitSnapshotRequest(blockNum uint64) error
+}
+
+// BlockPrunePolicy is the policy PeerLedger.Prune expects. A block is pruned once
+// it is below Height or older than Age, a whole block file at a time. The genesis
+// block and the latest config block are always kept, as are the blocks the state
+// and history databases have not caught up with.
+// Once pruned, the transactions of a block are no longer found by their ID, so the
+// ledger no longer detects the reuse of their IDs.
+type BlockPrunePolicy struct {
+	// Height is the number of the oldest block to keep, or 0 for no limit
+	Height uint64
+	// Age is how long a block is kept, based on the timestamps of its
+	// transactions, or 0 for no limit
+	Age time.Duration
+	// ArchiveDir is the directory the pruned block files are copied to before
+	// their removal, if set
+	ArchiveDir string
 }
 
 // ValidatedLedger represents the 'final ledger' after filtering out invalid transactions from PeerLedger.
@@ -175,10 +212,40 @@ type TxPvtData struct {
 }
 
 // BlockAndPvtData encapsultes the block and a map that contains the tuples <seqInBlock, *TxPvtData>
-// The map is expected to contain the entries only for the transactions that has associated pvt data
+// The map is expected to contain the entries only for the transactions that has associated pvt data.
+// The field 'MissingPvtData' is filled in during the validation of the block with the collections of the
+// valid transactions whose pvt data is not present in the map and which the peer is eligible to, as
+// told by the field 'IsEligible'. If 'IsEligible' is nil, the peer is eligible to every collection
 type BlockAndPvtData struct {
-	Block        *common.Block
-	BlockPvtData map[uint64]*TxPvtData
+	Block          *common.Block
+	BlockPvtData   map[uint64]*TxPvtData
+	MissingPvtData []*MissingPvtData
+	IsEligible     PvtDataEligibility
+}
+
+// PvtDataEligibility tells whether the peer is eligible to the pvt data of the collection <ns,coll>,
+// that is whether its organization is a member of the collection
+type PvtDataEligibility func(ns string, coll string) (bool, error)
+
+// MissingPvtData identifies a collection, written by a transaction of a block, whose pvt data is missing
+type MissingPvtData struct {
+	BlockNum   uint64
+	SeqInBlock uint64
+	Namespace  string
+	Collection string
+}
+
+// MissingPvtDataCount captures, per namespace and collection, the number of transactions whose pvt data is missing
+type MissingPvtDataCount map[string]map[string]uint64
+
+// Add counts a transaction whose pvt data of the collection <ns,coll> is missing
+func (count MissingPvtDataCount) Add(ns string, coll string) {
+	collCount, ok := count[ns]
+	if !ok {
+		collCount = make(map[string]uint64)
+		count[ns] = collCount
+	}
+	collCount[coll]++
 }
 
 // PvtCollFilter represents the set of the collection names (as keys of the map with value 'true')
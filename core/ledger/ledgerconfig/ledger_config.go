@@ -18,6 +18,7 @@ package ledgerconfig
 
 import (
 	"path/filepath"
+	"time"
 
 	"github.com/hyperledger/fabric/core/config"
 	"github.com/spf13/viper"
@@ -74,11 +75,46 @@ func GetPvtdataStorePath() string {
 	return filepath.Join(GetRootPath(), "pvtdataStore")
 }
 
+// GetInternalBookkeeperPath returns the filesystem path that is used by the ledger for its internal bookkeeping, such as the expiry of the pvt data
+func GetInternalBookkeeperPath() string {
+	return filepath.Join(GetRootPath(), "bookkeeper")
+}
+
 // GetMaxBlockfileSize returns maximum size of the block file
 func GetMaxBlockfileSize() int {
 	return 64 * 1024 * 1024
 }
 
+// GetBlockRetentionBlocks returns the number of most recent blocks each ledger
+// keeps when pruning, or 0 for no limit
+func GetBlockRetentionBlocks() uint64 {
+	blocks := viper.GetInt("ledger.blockchain.retention.blocks")
+	if blocks < 0 {
+		return 0
+	}
+	return uint64(blocks)
+}
+
+// GetBlockRetentionAge returns how long each ledger keeps a block when
+// pruning, or 0 for no limit
+func GetBlockRetentionAge() time.Duration {
+	return viper.GetDuration("ledger.blockchain.retention.age")
+}
+
+// GetBlockArchivePath returns the filesystem path the pruned block files are
+// archived to, or the empty string if they are discarded
+func GetBlockArchivePath() string {
+	return config.GetPath("ledger.blockchain.retention.archiveDir")
+}
+
+// GetSnapshotsPath returns the filesystem path the ledger snapshots are exported to
+func GetSnapshotsPath() string {
+	if path := config.GetPath("ledger.snapshots.rootDir"); path != "" {
+		return path
+	}
+	return filepath.Join(GetRootPath(), "snapshots")
+}
+
 //GetQueryLimit exposes the queryLimit variable
 func GetQueryLimit() int {
 	queryLimit := viper.GetInt("ledger.state.couchDBConfig.queryLimit")
@@ -18,6 +18,7 @@ package ledgermgmt
 
 import (
 	"errors"
+	"math"
 	"sync"
 
 	"fmt"
@@ -91,6 +92,36 @@ func CreateLedger(genesisBlock *common.Block) (ledger.PeerLedger, error) {
 	return l, nil
 }
 
+// CreateLedgerFromSnapshot creates a new ledger from the snapshot in the given directory.
+// The ledger continues from the block after the last block of the snapshot
+func CreateLedgerFromSnapshot(snapshotDir string) (ledger.PeerLedger, error) {
+	lock.Lock()
+	defer lock.Unlock()
+	if !initialized {
+		return nil, ErrLedgerMgmtNotInitialized
+	}
+
+	logger.Infof("Creating ledger from snapshot [%s]", snapshotDir)
+	l, err := ledgerProvider.CreateFromSnapshot(snapshotDir)
+	if err != nil {
+		return nil, err
+	}
+	lastBlock, err := l.GetBlockByNumber(math.MaxUint64)
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	id, err := utils.GetChainIDFromBlock(lastBlock)
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	l = wrapLedger(id, l)
+	openedLedgers[id] = l
+	logger.Infof("Created ledger [%s] from snapshot", id)
+	return l, nil
+}
+
 // OpenLedger returns a ledger for the given id
 func OpenLedger(id string) (ledger.PeerLedger, error) {
 	logger.Infof("Opening ledger with id = %s", id)
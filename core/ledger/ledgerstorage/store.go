@@ -20,10 +20,12 @@ import (
 	"fmt"
 	"sync"
 
+	commonledger "github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage/fsblkstorage"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/hyperledger/fabric/core/ledger/pvtdatapolicy"
 	"github.com/hyperledger/fabric/core/ledger/pvtdatastorage"
 	"github.com/hyperledger/fabric/protos/common"
 )
@@ -61,7 +63,7 @@ func NewProvider() *Provider {
 	return &Provider{blockStoreProvider, pvtStoreProvider}
 }
 
-// Open opens the store
+// Open opens the store. The store is expected to be initialized, via function `Init`, before use
 func (p *Provider) Open(ledgerid string) (*Store, error) {
 	var blockStore blkstorage.BlockStore
 	var pvtdataStore pvtdatastorage.Store
@@ -73,11 +75,34 @@ func (p *Provider) Open(ledgerid string) (*Store, error) {
 	if pvtdataStore, err = p.pvtdataStoreProvider.OpenStore(ledgerid); err != nil {
 		return nil, err
 	}
-	store := &Store{blockStore, pvtdataStore, &sync.RWMutex{}}
-	if err := store.init(); err != nil {
-		return nil, err
+	return &Store{blockStore, pvtdataStore, &sync.RWMutex{}}, nil
+}
+
+// BootstrapFromSnapshot creates the block store of a ledger created from a snapshot, which starts after the
+// last block of the snapshot. The store is to be opened, via function `Open`, afterwards. The pvt data store
+// is brought up to the last block when the store is initialized. The transaction IDs of the snapshot, returned
+// by txIDs, are recorded as those of pruned blocks
+func (p *Provider) BootstrapFromSnapshot(ledgerid string, lastBlock *common.Block, retain []*common.Block, txIDs commonledger.ResultsIterator) error {
+	blockStore, err := p.blkStoreProvider.CreateBlockStoreFromSnapshot(ledgerid, lastBlock, retain, txIDs)
+	if err != nil {
+		return err
+	}
+	blockStore.Shutdown()
+	return nil
+}
+
+// Rollback removes the blocks after blockNum, along with their pvt data, from the stores of a ledger
+// which are not open. The block store is rolled back first, the pvt data store follows it
+func (p *Provider) Rollback(ledgerid string, blockNum uint64) error {
+	if err := p.blkStoreProvider.Rollback(ledgerid, blockNum); err != nil {
+		return err
+	}
+	pvtdataStore, err := p.pvtdataStoreProvider.OpenStore(ledgerid)
+	if err != nil {
+		return err
 	}
-	return store, nil
+	defer pvtdataStore.Shutdown()
+	return pvtdataStore.RollbackToBlock(blockNum)
 }
 
 // Close closes the provider
@@ -86,6 +111,13 @@ func (p *Provider) Close() {
 	p.pvtdataStoreProvider.Close()
 }
 
+// Init sets the BTL policy by which the pvt data expires and brings the pvt data
+// store in sync with the block store
+func (s *Store) Init(btlPolicy pvtdatapolicy.BTLPolicy) error {
+	s.pvtdataStore.Init(btlPolicy)
+	return s.init()
+}
+
 // CommitWithPvtData commits the block and the corresponding pvt data in an atomic operation
 func (s *Store) CommitWithPvtData(blockAndPvtdata *ledger.BlockAndPvtData) error {
 	s.rwlock.Lock()
@@ -94,7 +126,7 @@ func (s *Store) CommitWithPvtData(blockAndPvtdata *ledger.BlockAndPvtData) error
 	for _, v := range blockAndPvtdata.BlockPvtData {
 		pvtdata = append(pvtdata, v)
 	}
-	if err := s.pvtdataStore.Prepare(blockAndPvtdata.Block.Header.Number, pvtdata); err != nil {
+	if err := s.pvtdataStore.Prepare(blockAndPvtdata.Block.Header.Number, pvtdata, blockAndPvtdata.MissingPvtData); err != nil {
 		return err
 	}
 	if err := s.AddBlock(blockAndPvtdata.Block); err != nil {
@@ -137,6 +169,27 @@ func (s *Store) GetPvtDataByNum(blockNum uint64, filter ledger.PvtNsCollFilter)
 	return pvtdata, nil
 }
 
+// GetMissingPvtData returns, the oldest blocks first starting at block fromBlockNum, up to maxEntries of the missing pvt data
+func (s *Store) GetMissingPvtData(fromBlockNum uint64, maxEntries int) ([]*ledger.MissingPvtData, error) {
+	s.rwlock.RLock()
+	defer s.rwlock.RUnlock()
+	return s.pvtdataStore.GetMissingPvtData(fromBlockNum, maxEntries)
+}
+
+// GetMissingPvtDataCount returns the number of transactions, per namespace and collection, whose pvt data is missing
+func (s *Store) GetMissingPvtDataCount() (ledger.MissingPvtDataCount, error) {
+	s.rwlock.RLock()
+	defer s.rwlock.RUnlock()
+	return s.pvtdataStore.GetMissingPvtDataCount()
+}
+
+// CommitPvtDataOfOldBlocks commits the missing pvt data of the already committed blocks
+func (s *Store) CommitPvtDataOfOldBlocks(blocksPvtData map[uint64][]*ledger.TxPvtData) error {
+	s.rwlock.Lock()
+	defer s.rwlock.Unlock()
+	return s.pvtdataStore.CommitPvtDataOfOldBlocks(blocksPvtData)
+}
+
 // init first invokes function `initFromExistingBlockchain`
 // in order to check whether the pvtdata store is present because of an upgrade
 // of peer from 1.0 and need to be updated with the existing blockchain. If, this is
@@ -47,6 +47,7 @@ func TestStore(t *testing.T) {
 	defer store.Shutdown()
 
 	assert.NoError(t, err)
+	assert.NoError(t, store.Init(nil))
 	sampleData := sampleData(t)
 	for _, sampleDatum := range sampleData {
 		assert.NoError(t, store.CommitWithPvtData(sampleDatum))
@@ -137,6 +138,8 @@ func TestStoreWithExistingBlockchain(t *testing.T) {
 	defer provider.Close()
 	store, err := provider.Open(testLedgerid)
 	defer store.Shutdown()
+	assert.NoError(t, err)
+	assert.NoError(t, store.Init(nil))
 
 	// test that pvtdata store is updated with info from existing block storage
 	pvtdataBlockHt, err := store.pvtdataStore.LastCommittedBlockHeight()
@@ -151,6 +154,46 @@ func TestStoreWithExistingBlockchain(t *testing.T) {
 	assert.Equal(t, uint64(10), pvtdataBlockHt)
 }
 
+func TestStoreRollback(t *testing.T) {
+	testEnv := newTestEnv(t)
+	defer testEnv.cleanup()
+	provider := NewProvider()
+	defer provider.Close()
+	store, err := provider.Open("testLedger")
+	assert.NoError(t, err)
+	assert.NoError(t, store.Init(nil))
+	sampleData := sampleData(t)
+	for _, sampleDatum := range sampleData {
+		assert.NoError(t, store.CommitWithPvtData(sampleDatum))
+	}
+	store.Shutdown()
+
+	assert.NoError(t, provider.Rollback("testLedger", 2))
+	assert.Error(t, provider.Rollback("testLedger", 5))
+
+	store, err = provider.Open("testLedger")
+	assert.NoError(t, err)
+	defer store.Shutdown()
+	assert.NoError(t, store.Init(nil))
+	bcInfo, err := store.GetBlockchainInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), bcInfo.Height)
+	pvtdataBlockHt, err := store.pvtdataStore.LastCommittedBlockHeight()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), pvtdataBlockHt)
+	blockAndPvtdata, err := store.GetPvtDataAndBlockByNum(2, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, sampleData[2], blockAndPvtdata)
+
+	// the rolled back blocks are committed again
+	for _, sampleDatum := range sampleData[3:] {
+		assert.NoError(t, store.CommitWithPvtData(sampleDatum))
+	}
+	blockAndPvtdata, err = store.GetPvtDataAndBlockByNum(3, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, sampleData[3], blockAndPvtdata)
+}
+
 func sampleData(t *testing.T) []*ledger.BlockAndPvtData {
 	var blockAndpvtdata []*ledger.BlockAndPvtData
 	blocks := testutil.ConstructTestBlocks(t, 10)
@@ -0,0 +1,88 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pvtdatapolicy
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/common/privdata"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+// lsccNamespace is the namespace under which LSCC stores the collection configurations
+const lsccNamespace = "lscc"
+
+// BTLPolicy BlockToLive policy for the pvt data
+type BTLPolicy interface {
+	// GetBTL returns BlockToLive for a given namespace and collection. A BTL
+	// of 0 means that the pvt data of the collection never expires
+	GetBTL(ns string, coll string) (uint64, error)
+	// GetExpiringBlock returns the block number by which the pvtdata for given namespace,collection, and committingBlock expires.
+	// The pvt data is available in the blocks up to, and excluding, the expiring block
+	GetExpiringBlock(ns string, coll string, committingBlock uint64) (uint64, error)
+}
+
+// StateGetter retrieves the committed value of a key from the state database
+type StateGetter interface {
+	GetState(namespace string, key string) (*statedb.VersionedValue, error)
+}
+
+// LSCCBasedBTLPolicy implements interface BTLPolicy.
+// This implementation reads the BTL of the collections from the collection
+// configurations that LSCC stores in the state database. A collection without
+// configuration is treated as one whose pvt data never expires
+type LSCCBasedBTLPolicy struct {
+	stateGetter StateGetter
+}
+
+// NewBTLPolicy constructs an instance of LSCCBasedBTLPolicy
+func NewBTLPolicy(stateGetter StateGetter) BTLPolicy {
+	return &LSCCBasedBTLPolicy{stateGetter: stateGetter}
+}
+
+// GetBTL implements corresponding function in interface `BTLPolicy`
+func (p *LSCCBasedBTLPolicy) GetBTL(ns string, coll string) (uint64, error) {
+	vv, err := p.stateGetter.GetState(lsccNamespace, privdata.BuildCollectionKVSKey(ns))
+	if err != nil {
+		return 0, err
+	}
+	if vv == nil || len(vv.Value) == 0 {
+		return 0, nil
+	}
+	collectionConfig := &common.CollectionConfigPackage{}
+	if err := proto.Unmarshal(vv.Value, collectionConfig); err != nil {
+		return 0, fmt.Errorf("invalid collection configuration for namespace [%s]: %s", ns, err)
+	}
+	for _, collection := range collectionConfig.Config {
+		if collection.Name == coll {
+			return collection.BlockToLive, nil
+		}
+	}
+	return 0, nil
+}
+
+// GetExpiringBlock implements function from the interface `BTLPolicy`
+func (p *LSCCBasedBTLPolicy) GetExpiringBlock(ns string, coll string, committingBlock uint64) (uint64, error) {
+	btl, err := p.GetBTL(ns, coll)
+	if err != nil {
+		return 0, err
+	}
+	return ComputeExpiringBlock(committingBlock, btl), nil
+}
+
+// ComputeExpiringBlock returns the block by which the pvt data committed with
+// the given block expires under the given BTL, or math.MaxUint64 if it never does
+func ComputeExpiringBlock(committingBlock uint64, btl uint64) uint64 {
+	expiringBlock := committingBlock + btl + 1
+	if btl == 0 || expiringBlock <= committingBlock {
+		return math.MaxUint64
+	}
+	return expiringBlock
+}
@@ -0,0 +1,58 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pvtdatapolicy
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStateGetter map[string][]byte
+
+func (m mockStateGetter) GetState(namespace string, key string) (*statedb.VersionedValue, error) {
+	value, ok := m[namespace+"/"+key]
+	if !ok {
+		return nil, nil
+	}
+	return &statedb.VersionedValue{Value: value, Version: version.NewHeight(1, 1)}, nil
+}
+
+func TestBTLPolicy(t *testing.T) {
+	collectionConfig := &common.CollectionConfigPackage{Config: []*common.CollectionConfig{
+		{Name: "coll1", BlockToLive: 10},
+		{Name: "coll2"},
+	}}
+	btlPolicy := NewBTLPolicy(mockStateGetter{
+		"lscc/ns1~collection": utils.MarshalOrPanic(collectionConfig),
+		"lscc/ns2~collection": []byte("garbage"),
+	})
+
+	btl, err := btlPolicy.GetBTL("ns1", "coll1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), btl)
+	expiringBlock, err := btlPolicy.GetExpiringBlock("ns1", "coll1", 50)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(61), expiringBlock)
+
+	for _, coll := range []struct{ ns, coll string }{{"ns1", "coll2"}, {"ns1", "coll3"}, {"ns3", "coll1"}} {
+		btl, err = btlPolicy.GetBTL(coll.ns, coll.coll)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(0), btl)
+		expiringBlock, err = btlPolicy.GetExpiringBlock(coll.ns, coll.coll, 50)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(math.MaxUint64), expiringBlock)
+	}
+
+	_, err = btlPolicy.GetBTL("ns2", "coll1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,185 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pvtdatastorage
+
+import (
+	"math"
+	"sort"
+
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	"github.com/hyperledger/fabric/protos/ledger/rwset"
+)
+
+// nsColl identifies a collection of a namespace
+type nsColl struct {
+	ns, coll string
+}
+
+// expiryData lists, for the collections whose pvt data committed with a block
+// expires by the same block, the numbers of the transactions carrying that data
+type expiryData map[nsColl][]uint64
+
+func (data expiryData) add(ns, coll string, txNum uint64) {
+	key := nsColl{ns, coll}
+	data[key] = append(data[key], txNum)
+}
+
+func (data expiryData) sortedNsColls() []nsColl {
+	var nsColls []nsColl
+	for key := range data {
+		nsColls = append(nsColls, key)
+	}
+	sort.Slice(nsColls, func(i, j int) bool {
+		if nsColls[i].ns != nsColls[j].ns {
+			return nsColls[i].ns < nsColls[j].ns
+		}
+		return nsColls[i].coll < nsColls[j].coll
+	})
+	return nsColls
+}
+
+// addExpiryEntries adds to the batch an entry for every block by which some
+// of the pending pvt data of the committing block expires. The missing pvt data
+// of the block is scheduled as well, so that it is no longer sought once expired
+func (s *store) addExpiryEntries(batch *leveldbhelper.UpdateBatch, committingBlk uint64) error {
+	entries := make(map[uint64]expiryData)
+	schedule := func(ns, coll string, txNum uint64) error {
+		expiringBlk, err := s.btlPolicy.GetExpiringBlock(ns, coll, committingBlk)
+		if err != nil {
+			return err
+		}
+		if expiringBlk == math.MaxUint64 {
+			return nil
+		}
+		if entries[expiringBlk] == nil {
+			entries[expiringBlk] = make(expiryData)
+		}
+		entries[expiringBlk].add(ns, coll, txNum)
+		return nil
+	}
+
+	itr := s.db.GetIterator(getKeysForRangeScanByBlockNum(committingBlk))
+	defer itr.Release()
+	for itr.Next() {
+		_, txNum := decodePK(itr.Key())
+		pvtWSet, err := decodePvtRwSet(itr.Value())
+		if err != nil {
+			return err
+		}
+		for _, ns := range pvtWSet.NsPvtRwset {
+			for _, coll := range ns.CollectionPvtRwset {
+				if err := schedule(ns.Namespace, coll.CollectionName, txNum); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	missingDataItr := s.db.GetIterator(getMissingDataKeysForRangeScanByBlockNum(committingBlk))
+	defer missingDataItr.Release()
+	for missingDataItr.Next() {
+		missing, err := decodeMissingDataKey(missingDataItr.Key())
+		if err != nil {
+			return err
+		}
+		if err := schedule(missing.Namespace, missing.Collection, missing.SeqInBlock); err != nil {
+			return err
+		}
+	}
+
+	for expiringBlk, data := range entries {
+		value, err := encodeExpiryData(data)
+		if err != nil {
+			return err
+		}
+		logger.Debugf("Scheduling expiry of pvt data of block [%d] by block [%d]", committingBlk, expiringBlk)
+		batch.Put(encodeExpiryKey(expiringBlk, committingBlk), value)
+	}
+	return nil
+}
+
+// purgeExpiredData adds to the batch the removal of the collections whose pvt
+// data expires by the committing block, along with their expiry entries and
+// missing data entries. A transaction whose every collection expired is removed
+// altogether
+func (s *store) purgeExpiredData(batch *leveldbhelper.UpdateBatch, committingBlk uint64) error {
+	itr := s.db.GetIterator(expiryKeyPrefix, encodeExpiryKey(committingBlk+1, 0))
+	defer itr.Release()
+
+	// the write sets trimmed so far, as several entries may concern the same transaction
+	trimmed := make(map[string]*rwset.TxPvtReadWriteSet)
+	for itr.Next() {
+		expiryKey := append([]byte{}, itr.Key()...)
+		_, blkNum := decodeExpiryKey(expiryKey)
+		data, err := decodeExpiryData(itr.Value())
+		if err != nil {
+			return err
+		}
+		for key, txNums := range data {
+			for _, txNum := range txNums {
+				batch.Delete(encodeMissingDataKey(blkNum, txNum, key.ns, key.coll))
+				dataKey := encodePK(blkNum, txNum)
+				pvtWSet, ok := trimmed[string(dataKey)]
+				if !ok {
+					if pvtWSet, err = s.getPvtRwSet(dataKey); err != nil {
+						return err
+					}
+					if pvtWSet == nil {
+						continue
+					}
+					trimmed[string(dataKey)] = pvtWSet
+				}
+				removeCollection(pvtWSet, key.ns, key.coll)
+			}
+		}
+		logger.Debugf("Purging pvt data of block [%d] which expires by block [%d]", blkNum, committingBlk)
+		batch.Delete(expiryKey)
+	}
+
+	for dataKey, pvtWSet := range trimmed {
+		if len(pvtWSet.NsPvtRwset) == 0 {
+			batch.Delete([]byte(dataKey))
+			continue
+		}
+		value, err := encodePvtRwSet(pvtWSet)
+		if err != nil {
+			return err
+		}
+		batch.Put([]byte(dataKey), value)
+	}
+	return nil
+}
+
+func (s *store) getPvtRwSet(key blkTranNumKey) (*rwset.TxPvtReadWriteSet, error) {
+	value, err := s.db.Get(key)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	return decodePvtRwSet(value)
+}
+
+// removeCollection removes the given collection, and its namespace if it was
+// the last collection of it, from the write set
+func removeCollection(pvtWSet *rwset.TxPvtReadWriteSet, ns, coll string) {
+	var nsPvtRwSets []*rwset.NsPvtReadWriteSet
+	for _, nsPvtRwSet := range pvtWSet.NsPvtRwset {
+		if nsPvtRwSet.Namespace == ns {
+			var collPvtRwSets []*rwset.CollectionPvtReadWriteSet
+			for _, collPvtRwSet := range nsPvtRwSet.CollectionPvtRwset {
+				if collPvtRwSet.CollectionName != coll {
+					collPvtRwSets = append(collPvtRwSets, collPvtRwSet)
+				}
+			}
+			if len(collPvtRwSets) == 0 {
+				continue
+			}
+			nsPvtRwSet.CollectionPvtRwset = collPvtRwSets
+		}
+		nsPvtRwSets = append(nsPvtRwSets, nsPvtRwSet)
+	}
+	pvtWSet.NsPvtRwset = nsPvtRwSets
+}
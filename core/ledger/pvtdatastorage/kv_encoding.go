@@ -7,19 +7,25 @@ SPDX-License-Identifier: Apache-2.0
 package pvtdatastorage
 
 import (
+	"bytes"
+	"fmt"
 	"math"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
 	"github.com/hyperledger/fabric/protos/ledger/rwset"
 )
 
 var (
-	pendingCommitKey    = []byte{0}
-	lastCommittedBlkkey = []byte{1}
-	pvtDataKeyPrefix    = []byte{2}
+	pendingCommitKey     = []byte{0}
+	lastCommittedBlkkey  = []byte{1}
+	pvtDataKeyPrefix     = []byte{2}
+	expiryKeyPrefix      = []byte{3}
+	missingDataKeyPrefix = []byte{4}
 
-	emptyValue = []byte{}
+	emptyValue      = []byte{}
+	nsCollSeparator = []byte{0x00}
 )
 
 func encodePK(blockNum uint64, tranNum uint64) blkTranNumKey {
@@ -37,6 +43,117 @@ func getKeysForRangeScanByBlockNum(blockNum uint64) (startKey []byte, endKey []b
 	return
 }
 
+func getKeysForRangeScanAfterBlockNum(blockNum uint64) (startKey []byte, endKey []byte) {
+	startKey = encodePK(blockNum+1, 0)
+	endKey = encodePK(math.MaxUint64, math.MaxUint64)
+	return
+}
+
+func encodeExpiryKey(expiringBlk uint64, committingBlk uint64) []byte {
+	return append(expiryKeyPrefix, version.NewHeight(expiringBlk, committingBlk).ToBytes()...)
+}
+
+func decodeExpiryKey(key []byte) (expiringBlk uint64, committingBlk uint64) {
+	height, _ := version.NewHeightFromBytes(key[1:])
+	return height.BlockNum, height.TxNum
+}
+
+func encodeMissingDataKey(blockNum uint64, tranNum uint64, ns string, coll string) []byte {
+	key := append(missingDataKeyPrefix, version.NewHeight(blockNum, tranNum).ToBytes()...)
+	key = append(key, []byte(ns)...)
+	key = append(key, nsCollSeparator...)
+	return append(key, []byte(coll)...)
+}
+
+func decodeMissingDataKey(key []byte) (*ledger.MissingPvtData, error) {
+	height, n := version.NewHeightFromBytes(key[1:])
+	parts := bytes.SplitN(key[1+n:], nsCollSeparator, 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid missing data key %#v", key)
+	}
+	return &ledger.MissingPvtData{
+		BlockNum:   height.BlockNum,
+		SeqInBlock: height.TxNum,
+		Namespace:  string(parts[0]),
+		Collection: string(parts[1]),
+	}, nil
+}
+
+func getMissingDataKeysForRangeScanByBlockNum(blockNum uint64) (startKey []byte, endKey []byte) {
+	startKey = append(missingDataKeyPrefix, version.NewHeight(blockNum, 0).ToBytes()...)
+	endKey = append(missingDataKeyPrefix, version.NewHeight(blockNum+1, 0).ToBytes()...)
+	return
+}
+
+func getMissingDataKeysForRangeScanFromBlockNum(blockNum uint64) (startKey []byte, endKey []byte) {
+	startKey = append(missingDataKeyPrefix, version.NewHeight(blockNum, 0).ToBytes()...)
+	endKey = []byte{missingDataKeyPrefix[0] + 1}
+	return
+}
+
+func getMissingDataKeysForRangeScanAfterBlockNum(blockNum uint64) (startKey []byte, endKey []byte) {
+	startKey = append(missingDataKeyPrefix, version.NewHeight(blockNum+1, 0).ToBytes()...)
+	endKey = append(missingDataKeyPrefix, version.NewHeight(math.MaxUint64, math.MaxUint64).ToBytes()...)
+	return
+}
+
+// encodeExpiryData encodes the expiry data in the order of its namespaces and
+// collections, so that the same data is always encoded the same way
+func encodeExpiryData(data expiryData) ([]byte, error) {
+	buf := proto.NewBuffer(nil)
+	if err := buf.EncodeVarint(uint64(len(data))); err != nil {
+		return nil, err
+	}
+	for _, nsColl := range data.sortedNsColls() {
+		if err := buf.EncodeStringBytes(nsColl.ns); err != nil {
+			return nil, err
+		}
+		if err := buf.EncodeStringBytes(nsColl.coll); err != nil {
+			return nil, err
+		}
+		txNums := data[nsColl]
+		if err := buf.EncodeVarint(uint64(len(txNums))); err != nil {
+			return nil, err
+		}
+		for _, txNum := range txNums {
+			if err := buf.EncodeVarint(txNum); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeExpiryData(encodedBytes []byte) (expiryData, error) {
+	buf := proto.NewBuffer(encodedBytes)
+	numNsColls, err := buf.DecodeVarint()
+	if err != nil {
+		return nil, err
+	}
+	data := make(expiryData)
+	for i := uint64(0); i < numNsColls; i++ {
+		var key nsColl
+		if key.ns, err = buf.DecodeStringBytes(); err != nil {
+			return nil, err
+		}
+		if key.coll, err = buf.DecodeStringBytes(); err != nil {
+			return nil, err
+		}
+		numTxNums, err := buf.DecodeVarint()
+		if err != nil {
+			return nil, err
+		}
+		for j := uint64(0); j < numTxNums; j++ {
+			txNum, err := buf.DecodeVarint()
+			if err != nil {
+				return nil, err
+			}
+			data[key] = append(data[key], txNum)
+		}
+	}
+	return data, nil
+}
+
 func encodePvtRwSet(txPvtRwSet *rwset.TxPvtReadWriteSet) ([]byte, error) {
 	return proto.Marshal(txPvtRwSet)
 }
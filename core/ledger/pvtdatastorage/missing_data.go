@@ -0,0 +1,128 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package pvtdatastorage
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/protos/ledger/rwset"
+)
+
+// GetMissingPvtData implements the function in the interface `Store`
+func (s *store) GetMissingPvtData(fromBlockNum uint64, maxEntries int) ([]*ledger.MissingPvtData, error) {
+	var missingPvtData []*ledger.MissingPvtData
+	err := s.iterateMissingData(fromBlockNum, func(missing *ledger.MissingPvtData) bool {
+		missingPvtData = append(missingPvtData, missing)
+		return maxEntries == 0 || len(missingPvtData) < maxEntries
+	})
+	return missingPvtData, err
+}
+
+// GetMissingPvtDataCount implements the function in the interface `Store`
+func (s *store) GetMissingPvtDataCount() (ledger.MissingPvtDataCount, error) {
+	count := make(ledger.MissingPvtDataCount)
+	err := s.iterateMissingData(0, func(missing *ledger.MissingPvtData) bool {
+		count.Add(missing.Namespace, missing.Collection)
+		return true
+	})
+	return count, err
+}
+
+// CommitPvtDataOfOldBlocks implements the function in the interface `Store`.
+// The collections are merged into the pvt data already stored for their transaction
+func (s *store) CommitPvtDataOfOldBlocks(blocksPvtData map[uint64][]*ledger.TxPvtData) error {
+	if s.batchPending {
+		return &ErrIllegalCall{"A pending batch exists. Pvt data of old blocks cannot be committed before it is committed or rolled back"}
+	}
+	batch := leveldbhelper.NewUpdateBatch()
+	for blockNum, blockPvtData := range blocksPvtData {
+		if s.isEmpty || blockNum > s.lastCommittedBlock {
+			return &ErrIllegalArgs{fmt.Sprintf("Pvt data supplied for block number=%d, which is not committed yet", blockNum)}
+		}
+		for _, txPvtData := range blockPvtData {
+			if err := s.addMissingPvtData(batch, blockNum, txPvtData); err != nil {
+				return err
+			}
+		}
+	}
+	return s.db.WriteBatch(batch, true)
+}
+
+// addMissingPvtData adds to the batch the collections of the pvt data that are
+// recorded as missing for the transaction, along with the removal of their
+// missing data entries
+func (s *store) addMissingPvtData(batch *leveldbhelper.UpdateBatch, blockNum uint64, txPvtData *ledger.TxPvtData) error {
+	if txPvtData.WriteSet == nil {
+		return nil
+	}
+	dataKey := encodePK(blockNum, txPvtData.SeqInBlock)
+	pvtWSet, err := s.getPvtRwSet(dataKey)
+	if err != nil {
+		return err
+	}
+	if pvtWSet == nil {
+		pvtWSet = &rwset.TxPvtReadWriteSet{DataModel: txPvtData.WriteSet.DataModel}
+	}
+	added := false
+	for _, ns := range txPvtData.WriteSet.NsPvtRwset {
+		for _, coll := range ns.CollectionPvtRwset {
+			missingDataKey := encodeMissingDataKey(blockNum, txPvtData.SeqInBlock, ns.Namespace, coll.CollectionName)
+			value, err := s.db.Get(missingDataKey)
+			if err != nil {
+				return err
+			}
+			if value == nil {
+				logger.Debugf("Pvt data of collection [%s:%s] for blockNum=%d, tranNum=%d is not missing, ignoring it",
+					ns.Namespace, coll.CollectionName, blockNum, txPvtData.SeqInBlock)
+				continue
+			}
+			logger.Debugf("Adding missing pvt data of collection [%s:%s] for blockNum=%d, tranNum=%d",
+				ns.Namespace, coll.CollectionName, blockNum, txPvtData.SeqInBlock)
+			addCollection(pvtWSet, ns.Namespace, coll)
+			batch.Delete(missingDataKey)
+			added = true
+		}
+	}
+	if !added {
+		return nil
+	}
+	value, err := encodePvtRwSet(pvtWSet)
+	if err != nil {
+		return err
+	}
+	batch.Put(dataKey, value)
+	return nil
+}
+
+func (s *store) iterateMissingData(fromBlockNum uint64, f func(missing *ledger.MissingPvtData) bool) error {
+	itr := s.db.GetIterator(getMissingDataKeysForRangeScanFromBlockNum(fromBlockNum))
+	defer itr.Release()
+	for itr.Next() {
+		missing, err := decodeMissingDataKey(itr.Key())
+		if err != nil {
+			return err
+		}
+		if !f(missing) {
+			break
+		}
+	}
+	return nil
+}
+
+// addCollection adds the given collection, and its namespace if not present, to the write set
+func addCollection(pvtWSet *rwset.TxPvtReadWriteSet, ns string, coll *rwset.CollectionPvtReadWriteSet) {
+	for _, nsPvtRwSet := range pvtWSet.NsPvtRwset {
+		if nsPvtRwSet.Namespace == ns {
+			nsPvtRwSet.CollectionPvtRwset = append(nsPvtRwSet.CollectionPvtRwset, coll)
+			return
+		}
+	}
+	pvtWSet.NsPvtRwset = append(pvtWSet.NsPvtRwset,
+		&rwset.NsPvtReadWriteSet{Namespace: ns, CollectionPvtRwset: []*rwset.CollectionPvtReadWriteSet{coll}})
+}
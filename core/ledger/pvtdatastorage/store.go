@@ -8,6 +8,7 @@ package pvtdatastorage
 
 import (
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/pvtdatapolicy"
 )
 
 // Provider provides handle to specific 'Store' that in turn manages
@@ -28,6 +29,10 @@ type Provider interface {
 // on whether the block was written successfully or not. The store implementation
 // is expected to survive a server crash between the call to `Prepare` and `Commit`/`Rollback`
 type Store interface {
+	// Init initializes the store. This function is expected to be invoked before using the store.
+	// The pvt data committed from then on expires as per the given BTL policy. A nil policy
+	// makes the pvt data never expire
+	Init(btlPolicy pvtdatapolicy.BTLPolicy)
 	// InitLastCommittedBlockHeight sets the last commited block height into the pvt data store
 	// This function is used in a special case where the peer is started up with the blockchain
 	// from an earlier version of a peer when the pvt data feature (and hence this store) was not
@@ -44,12 +49,25 @@ type Store interface {
 	// Subsequently, the caller is expected to call either `Commit` or `Rollback` function.
 	// Return from this should ensure that enough preparation is done such that `Commit` function invoked afterwards
 	// can commit the data and the store is capable of surviving a crash between this function call and the next
-	// invoke to the `Commit`
-	Prepare(blockNum uint64, pvtData []*ledger.TxPvtData) error
-	// Commit commits the pvt data passed in the previous invoke to the `Prepare` function
+	// invoke to the `Commit`. The missing pvt data of the block is recorded along with the pvt data
+	Prepare(blockNum uint64, pvtData []*ledger.TxPvtData, missingPvtData []*ledger.MissingPvtData) error
+	// Commit commits the pvt data passed in the previous invoke to the `Prepare` function.
+	// Along with it, the pvt data which expires by the committed block is purged
 	Commit() error
 	// Rollback rolls back the pvt data passed in the previous invoke to the `Prepare` function
 	Rollback() error
+	// RollbackToBlock removes the pvt data, including the recorded missing pvt data, of the blocks
+	// after the given block, which becomes the last committed block. A pending batch is removed as well.
+	// The pvt data of the retained blocks, which was purged on expiry by the removed blocks, is not restored
+	RollbackToBlock(blockNum uint64) error
+	// GetMissingPvtData returns, the oldest blocks first starting at block fromBlockNum, up to maxEntries of the
+	// recorded missing pvt data. A maxEntries of 0 does not limit the results
+	GetMissingPvtData(fromBlockNum uint64, maxEntries int) ([]*ledger.MissingPvtData, error)
+	// GetMissingPvtDataCount returns the number of transactions, per namespace and collection, whose pvt data is missing
+	GetMissingPvtDataCount() (ledger.MissingPvtDataCount, error)
+	// CommitPvtDataOfOldBlocks commits the pvt data, keyed by block number, of the already committed blocks.
+	// Only the collections recorded as missing are committed, and they are no longer recorded as missing
+	CommitPvtDataOfOldBlocks(blocksPvtData map[uint64][]*ledger.TxPvtData) error
 	// IsEmpty returns true if the store does not have any block committed yet
 	IsEmpty() (bool, error)
 	// LastCommittedBlockHeight returns the height of the last committed block
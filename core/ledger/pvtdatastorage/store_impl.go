@@ -13,6 +13,7 @@ import (
 	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/hyperledger/fabric/core/ledger/pvtdatapolicy"
 	"github.com/hyperledger/fabric/protos/ledger/rwset"
 )
 
@@ -28,6 +29,7 @@ type store struct {
 	isEmpty            bool
 	lastCommittedBlock uint64
 	batchPending       bool
+	btlPolicy          pvtdatapolicy.BTLPolicy
 }
 
 type blkTranNumKey []byte
@@ -65,8 +67,13 @@ func (s *store) initState() error {
 	return nil
 }
 
+// Init implements the function in the interface `Store`
+func (s *store) Init(btlPolicy pvtdatapolicy.BTLPolicy) {
+	s.btlPolicy = btlPolicy
+}
+
 // Prepare implements the function in the interface `Store`
-func (s *store) Prepare(blockNum uint64, pvtData []*ledger.TxPvtData) error {
+func (s *store) Prepare(blockNum uint64, pvtData []*ledger.TxPvtData, missingPvtData []*ledger.MissingPvtData) error {
 	if s.batchPending {
 		return &ErrIllegalCall{`A pending batch exists as as result of last invoke to "Prepare" call.
 			 Invoke "Commit" or "Rollback" on the pending batch before invoking "Prepare" function`}
@@ -87,6 +94,11 @@ func (s *store) Prepare(blockNum uint64, pvtData []*ledger.TxPvtData) error {
 		logger.Debugf("Adding private data to batch blockNum=%d, tranNum=%d", blockNum, txPvtData.SeqInBlock)
 		batch.Put(key, value)
 	}
+	for _, missing := range missingPvtData {
+		logger.Debugf("Adding missing pvt data of collection [%s:%s] to batch blockNum=%d, tranNum=%d",
+			missing.Namespace, missing.Collection, blockNum, missing.SeqInBlock)
+		batch.Put(encodeMissingDataKey(blockNum, missing.SeqInBlock, missing.Namespace, missing.Collection), emptyValue)
+	}
 	batch.Put(pendingCommitKey, emptyValue)
 	if err := s.db.WriteBatch(batch, true); err != nil {
 		return err
@@ -103,6 +115,14 @@ func (s *store) Commit() error {
 	committingBlockNum := s.nextBlockNum()
 	logger.Debugf("Committing pvt data for block = %d", committingBlockNum)
 	batch := leveldbhelper.NewUpdateBatch()
+	if s.btlPolicy != nil {
+		if err := s.addExpiryEntries(batch, committingBlockNum); err != nil {
+			return err
+		}
+		if err := s.purgeExpiredData(batch, committingBlockNum); err != nil {
+			return err
+		}
+	}
 	batch.Delete(pendingCommitKey)
 	batch.Put(lastCommittedBlkkey, encodeBlockNum(committingBlockNum))
 	if err := s.db.WriteBatch(batch, true); err != nil {
@@ -117,7 +137,7 @@ func (s *store) Commit() error {
 
 // Rollback implements the function in the interface `Store`
 func (s *store) Rollback() error {
-	var pendingBatchKeys []blkTranNumKey
+	var pendingBatchKeys [][]byte
 	var err error
 	if !s.batchPending {
 		return &ErrIllegalCall{"No pending batch to rollback"}
@@ -189,6 +209,34 @@ func (s *store) InitLastCommittedBlock(blockNum uint64) error {
 	return nil
 }
 
+// RollbackToBlock implements the function in the interface `Store`
+func (s *store) RollbackToBlock(blockNum uint64) error {
+	if s.isEmpty || blockNum > s.lastCommittedBlock {
+		return &ErrIllegalArgs{fmt.Sprintf("Last committed block=%d, cannot roll back to block=%d", s.lastCommittedBlock, blockNum)}
+	}
+	logger.Debugf("Rolling back pvt data store to block = %d", blockNum)
+	batch := leveldbhelper.NewUpdateBatch()
+	removedKeys := s.retrieveKeys(getKeysForRangeScanAfterBlockNum(blockNum))
+	removedKeys = append(removedKeys, s.retrieveKeys(getMissingDataKeysForRangeScanAfterBlockNum(blockNum))...)
+	for _, key := range s.retrieveKeys(expiryKeyPrefix, missingDataKeyPrefix) {
+		if _, committingBlk := decodeExpiryKey(key); committingBlk > blockNum {
+			removedKeys = append(removedKeys, key)
+		}
+	}
+	for _, key := range removedKeys {
+		batch.Delete(key)
+	}
+	batch.Delete(pendingCommitKey)
+	batch.Put(lastCommittedBlkkey, encodeBlockNum(blockNum))
+	if err := s.db.WriteBatch(batch, true); err != nil {
+		return err
+	}
+	s.batchPending = false
+	s.lastCommittedBlock = blockNum
+	logger.Debugf("Rolled back pvt data store to block = %d, %d entries removed", blockNum, len(removedKeys))
+	return nil
+}
+
 // LastCommittedBlockHeight implements the function in the interface `Store`
 func (s *store) LastCommittedBlockHeight() (uint64, error) {
 	if s.isEmpty {
@@ -219,13 +267,20 @@ func (s *store) nextBlockNum() uint64 {
 	return s.lastCommittedBlock + 1
 }
 
-func (s *store) retrievePendingBatchKeys() ([]blkTranNumKey, error) {
-	var pendingBatchKeys []blkTranNumKey
-	itr := s.db.GetIterator(encodePK(s.nextBlockNum(), 0), nil)
+func (s *store) retrievePendingBatchKeys() ([][]byte, error) {
+	blockNum := s.nextBlockNum()
+	pendingBatchKeys := s.retrieveKeys(getKeysForRangeScanByBlockNum(blockNum))
+	return append(pendingBatchKeys, s.retrieveKeys(getMissingDataKeysForRangeScanByBlockNum(blockNum))...), nil
+}
+
+func (s *store) retrieveKeys(startKey []byte, endKey []byte) [][]byte {
+	var keys [][]byte
+	itr := s.db.GetIterator(startKey, endKey)
+	defer itr.Release()
 	for itr.Next() {
-		pendingBatchKeys = append(pendingBatchKeys, itr.Key())
+		keys = append(keys, append([]byte{}, itr.Key()...))
 	}
-	return pendingBatchKeys, nil
+	return keys
 }
 
 func (s *store) hasPendingCommit() (bool, error) {
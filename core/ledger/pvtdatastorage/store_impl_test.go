@@ -12,6 +12,7 @@ import (
 
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/pvtdatapolicy"
 	"github.com/hyperledger/fabric/protos/ledger/rwset"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -40,15 +41,15 @@ func TestStoreBasicCommitAndRetrieval(t *testing.T) {
 	testData := samplePvtData(t, []uint64{2, 4})
 
 	// no pvt data with block 0
-	assert.NoError(store.Prepare(0, nil))
+	assert.NoError(store.Prepare(0, nil, nil))
 	assert.NoError(store.Commit())
 
 	// pvt data with block 1 - commit
-	assert.NoError(store.Prepare(1, testData))
+	assert.NoError(store.Prepare(1, testData, nil))
 	assert.NoError(store.Commit())
 
 	// pvt data with block 2 - rollback
-	assert.NoError(store.Prepare(2, testData))
+	assert.NoError(store.Prepare(2, testData, nil))
 	assert.NoError(store.Rollback())
 
 	// pvt data retrieval for block 0 should return nil
@@ -86,14 +87,14 @@ func TestStoreState(t *testing.T) {
 	store := env.TestStore
 	testData := samplePvtData(t, []uint64{0})
 
-	_, ok := store.Prepare(1, testData).(*ErrIllegalArgs)
+	_, ok := store.Prepare(1, testData, nil).(*ErrIllegalArgs)
 	assert.True(ok)
 
-	assert.Nil(store.Prepare(0, testData))
+	assert.Nil(store.Prepare(0, testData, nil))
 	assert.NoError(store.Commit())
 
-	assert.Nil(store.Prepare(1, testData))
-	_, ok = store.Prepare(2, testData).(*ErrIllegalCall)
+	assert.Nil(store.Prepare(1, testData, nil))
+	_, ok = store.Prepare(2, testData, nil).(*ErrIllegalCall)
 	assert.True(ok)
 }
 
@@ -119,8 +120,210 @@ func TestInitLastCommittedBlock(t *testing.T) {
 	assert.True(ok)
 }
 
+func TestStoreExpiry(t *testing.T) {
+	env := NewTestStoreEnv(t)
+	defer env.Cleanup()
+	assert := assert.New(t)
+	store := env.TestStore
+	store.Init(testBTLPolicy{"ns-1/coll-1": 1, "ns-2/coll-1": 2})
+	testData := samplePvtData(t, []uint64{2, 4})
+
+	assert.NoError(store.Prepare(0, nil, nil))
+	assert.NoError(store.Commit())
+	assert.NoError(store.Prepare(1, testData, nil))
+	assert.NoError(store.Commit())
+
+	// a rolled back block leaves the scheduled expiry alone
+	assert.NoError(store.Prepare(2, testData, nil))
+	assert.NoError(store.Rollback())
+	assert.NoError(store.Prepare(2, nil, nil))
+	assert.NoError(store.Commit())
+	retrievedData, err := store.GetPvtDataByBlockNum(1, nil)
+	assert.NoError(err)
+	assert.Equal(testData, retrievedData)
+
+	// ns-1/coll-1 expires by block 3
+	assert.NoError(store.Prepare(3, nil, nil))
+	assert.NoError(store.Commit())
+	retrievedData, err = store.GetPvtDataByBlockNum(1, nil)
+	assert.NoError(err)
+	assert.Len(retrievedData, 2)
+	for _, txPvtData := range retrievedData {
+		assert.False(txPvtData.Has("ns-1", "coll-1"))
+		assert.True(txPvtData.Has("ns-1", "coll-2"))
+		assert.True(txPvtData.Has("ns-2", "coll-1"))
+		assert.True(txPvtData.Has("ns-2", "coll-2"))
+	}
+
+	// ns-2/coll-1 expires by block 4, which survives reopening the store
+	env.CloseAndReopen()
+	store = env.TestStore
+	store.Init(testBTLPolicy{"ns-1/coll-1": 1, "ns-2/coll-1": 2})
+	assert.NoError(store.Prepare(4, nil, nil))
+	assert.NoError(store.Commit())
+	retrievedData, err = store.GetPvtDataByBlockNum(1, nil)
+	assert.NoError(err)
+	assert.Len(retrievedData, 2)
+	for _, txPvtData := range retrievedData {
+		assert.False(txPvtData.Has("ns-1", "coll-1"))
+		assert.True(txPvtData.Has("ns-1", "coll-2"))
+		assert.False(txPvtData.Has("ns-2", "coll-1"))
+		assert.True(txPvtData.Has("ns-2", "coll-2"))
+	}
+}
+
+func TestStoreMissingPvtData(t *testing.T) {
+	env := NewTestStoreEnv(t)
+	defer env.Cleanup()
+	assert := assert.New(t)
+	store := env.TestStore
+	missingPvtData := []*ledger.MissingPvtData{
+		{BlockNum: 1, SeqInBlock: 4, Namespace: "ns-1", Collection: "coll-1"},
+		{BlockNum: 1, SeqInBlock: 4, Namespace: "ns-2", Collection: "coll-2"},
+	}
+
+	assert.NoError(store.Prepare(0, nil, nil))
+	assert.NoError(store.Commit())
+	assert.NoError(store.Prepare(1, samplePvtData(t, []uint64{2}), missingPvtData))
+	assert.NoError(store.Commit())
+
+	// the missing data of a rolled back block is not recorded
+	assert.NoError(store.Prepare(2, nil, []*ledger.MissingPvtData{
+		{BlockNum: 2, SeqInBlock: 0, Namespace: "ns-1", Collection: "coll-1"},
+	}))
+	assert.NoError(store.Rollback())
+
+	missing, err := store.GetMissingPvtData(0, 0)
+	assert.NoError(err)
+	assert.Equal(missingPvtData, missing)
+	missing, err = store.GetMissingPvtData(0, 1)
+	assert.NoError(err)
+	assert.Equal(missingPvtData[:1], missing)
+	// the missing data of the blocks before the given one is skipped
+	missing, err = store.GetMissingPvtData(1, 0)
+	assert.NoError(err)
+	assert.Equal(missingPvtData, missing)
+	missing, err = store.GetMissingPvtData(2, 0)
+	assert.NoError(err)
+	assert.Empty(missing)
+	count, err := store.GetMissingPvtDataCount()
+	assert.NoError(err)
+	assert.Equal(ledger.MissingPvtDataCount{"ns-1": {"coll-1": 1}, "ns-2": {"coll-2": 1}}, count)
+
+	// pvt data of a block not committed yet cannot be committed
+	err = store.CommitPvtDataOfOldBlocks(map[uint64][]*ledger.TxPvtData{2: samplePvtData(t, []uint64{4})})
+	_, ok := err.(*ErrIllegalArgs)
+	assert.True(ok)
+
+	// only the collections that are missing are committed
+	assert.NoError(store.CommitPvtDataOfOldBlocks(map[uint64][]*ledger.TxPvtData{1: samplePvtData(t, []uint64{4})}))
+	retrievedData, err := store.GetPvtDataByBlockNum(1, nil)
+	assert.NoError(err)
+	assert.Len(retrievedData, 2)
+	assert.Equal(samplePvtData(t, []uint64{2})[0], retrievedData[0])
+	assert.Equal(uint64(4), retrievedData[1].SeqInBlock)
+	assert.True(retrievedData[1].Has("ns-1", "coll-1"))
+	assert.False(retrievedData[1].Has("ns-1", "coll-2"))
+	assert.False(retrievedData[1].Has("ns-2", "coll-1"))
+	assert.True(retrievedData[1].Has("ns-2", "coll-2"))
+
+	missing, err = store.GetMissingPvtData(0, 0)
+	assert.NoError(err)
+	assert.Empty(missing)
+	count, err = store.GetMissingPvtDataCount()
+	assert.NoError(err)
+	assert.Empty(count)
+}
+
+func TestStoreRollbackToBlock(t *testing.T) {
+	env := NewTestStoreEnv(t)
+	defer env.Cleanup()
+	assert := assert.New(t)
+	store := env.TestStore
+	store.Init(testBTLPolicy{"ns-1/coll-1": 1, "ns-2/coll-1": 2})
+	testData := samplePvtData(t, []uint64{2, 4})
+	missingPvtData := func(blockNum uint64) []*ledger.MissingPvtData {
+		return []*ledger.MissingPvtData{{BlockNum: blockNum, SeqInBlock: 6, Namespace: "ns-1", Collection: "coll-2"}}
+	}
+
+	_, ok := store.RollbackToBlock(0).(*ErrIllegalArgs)
+	assert.True(ok)
+
+	assert.NoError(store.Prepare(0, nil, nil))
+	assert.NoError(store.Commit())
+	for blockNum := uint64(1); blockNum <= 3; blockNum++ {
+		assert.NoError(store.Prepare(blockNum, testData, missingPvtData(blockNum)))
+		assert.NoError(store.Commit())
+	}
+	assert.NoError(store.Prepare(4, testData, missingPvtData(4)))
+
+	_, ok = store.RollbackToBlock(4).(*ErrIllegalArgs)
+	assert.True(ok)
+	assert.NoError(store.RollbackToBlock(1))
+	testPendingBatch(false, assert, store)
+	testLastCommittedBlockHeight(2, assert, store)
+
+	// the pvt data purged by the removed blocks is not restored
+	retrievedData, err := store.GetPvtDataByBlockNum(1, nil)
+	assert.NoError(err)
+	assert.Len(retrievedData, 2)
+	for _, txPvtData := range retrievedData {
+		assert.False(txPvtData.Has("ns-1", "coll-1"))
+		assert.True(txPvtData.Has("ns-2", "coll-1"))
+	}
+	_, err = store.GetPvtDataByBlockNum(2, nil)
+	_, ok = err.(*ErrOutOfRange)
+	assert.True(ok)
+	missing, err := store.GetMissingPvtData(0, 0)
+	assert.NoError(err)
+	assert.Equal(missingPvtData(1), missing)
+	// only the expiry of the pvt data of the retained blocks is scheduled
+	for _, key := range retrieveExpiryKeys(store) {
+		_, committingBlk := decodeExpiryKey(key)
+		assert.True(committingBlk <= 1)
+	}
+
+	// the rolled back state survives reopening the store and the blocks can be committed again
+	env.CloseAndReopen()
+	store = env.TestStore
+	store.Init(testBTLPolicy{"ns-1/coll-1": 1, "ns-2/coll-1": 2})
+	testLastCommittedBlockHeight(2, assert, store)
+	assert.NoError(store.Prepare(2, nil, nil))
+	assert.NoError(store.Commit())
+	retrievedData, err = store.GetPvtDataByBlockNum(2, nil)
+	assert.NoError(err)
+	assert.Nil(retrievedData)
+}
+
+func TestMissingDataKeyEncoding(t *testing.T) {
+	missing := &ledger.MissingPvtData{BlockNum: 300, SeqInBlock: 12, Namespace: "ns-1", Collection: "coll-1"}
+	decoded, err := decodeMissingDataKey(encodeMissingDataKey(300, 12, "ns-1", "coll-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, missing, decoded)
+}
+
+func TestExpiryDataEncoding(t *testing.T) {
+	data := make(expiryData)
+	data.add("ns-2", "coll-1", 3)
+	data.add("ns-1", "coll-2", 1)
+	data.add("ns-1", "coll-2", 5)
+	encoded, err := encodeExpiryData(data)
+	assert.NoError(t, err)
+	decoded, err := decodeExpiryData(encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, data, decoded)
+
+	expiringBlk, committingBlk := decodeExpiryKey(encodeExpiryKey(300, 12))
+	assert.Equal(t, uint64(300), expiringBlk)
+	assert.Equal(t, uint64(12), committingBlk)
+}
+
 // TODO Add tests for simulating a crash between calls `Prepare` and `Commit`/`Rollback`
 
+func retrieveExpiryKeys(s Store) [][]byte {
+	return s.(*store).retrieveKeys(expiryKeyPrefix, missingDataKeyPrefix)
+}
+
 func testEmpty(expectedEmpty bool, assert *assert.Assertions, store Store) {
 	isEmpty, err := store.IsEmpty()
 	assert.NoError(err)
@@ -139,6 +342,16 @@ func testLastCommittedBlockHeight(expectedBlockHt uint64, assert *assert.Asserti
 	assert.Equal(expectedBlockHt, blkHt)
 }
 
+type testBTLPolicy map[string]uint64
+
+func (p testBTLPolicy) GetBTL(ns string, coll string) (uint64, error) {
+	return p[ns+"/"+coll], nil
+}
+
+func (p testBTLPolicy) GetExpiringBlock(ns string, coll string, committingBlock uint64) (uint64, error) {
+	return pvtdatapolicy.ComputeExpiringBlock(committingBlock, p[ns+"/"+coll]), nil
+}
+
 func samplePvtData(t *testing.T, txNums []uint64) []*ledger.TxPvtData {
 	pvtWriteSet := &rwset.TxPvtReadWriteSet{DataModel: rwset.TxReadWriteSet_KV}
 	pvtWriteSet.NsPvtRwset = []*rwset.NsPvtReadWriteSet{
@@ -24,6 +24,7 @@ package cscc
 import (
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/config/channel"
@@ -53,6 +54,7 @@ const (
 	JoinChain      string = "JoinChain"
 	GetConfigBlock string = "GetConfigBlock"
 	GetChannels    string = "GetChannels"
+	ExportSnapshot string = "ExportSnapshot"
 )
 
 // Init is called once per chain when the chain is created.
@@ -75,11 +77,14 @@ func (e *PeerConfiger) Init(stub shim.ChaincodeStubInterface) pb.Response {
 // # to process joining a chain (called by app as a transaction proposal)
 // # to get the current configuration block (called by app)
 // # to update the configuration block (called by committer)
+// # to export a snapshot of the ledger of a chain (called by app)
 // Peer calls this function with 2 arguments:
-// # args[0] is the function name, which must be JoinChain, GetConfigBlock or
-// UpdateConfigBlock
+// # args[0] is the function name, which must be JoinChain, GetConfigBlock,
+// UpdateConfigBlock or ExportSnapshot
 // # args[1] is a configuration Block if args[0] is JoinChain or
 // UpdateConfigBlock; otherwise it is the chain id
+// ExportSnapshot takes an optional third argument, the number of the block
+// to snapshot, which defaults to the last block
 // TODO: Improve the scc interface to avoid marshal/unmarshal args
 func (e *PeerConfiger) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 	args := stub.GetArgs()
@@ -146,6 +151,17 @@ func (e *PeerConfiger) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 
 		return getChannels()
 
+	case ExportSnapshot:
+		// 2. check local MSP Admins policy
+		if err = e.policyChecker.CheckPolicyNoChannel(mgmt.Admins, sp); err != nil {
+			return shim.Error(fmt.Sprintf("\"ExportSnapshot\" request failed authorization check "+
+				"for channel [%s]: [%s]", args[1], err))
+		}
+		var blockNum []byte
+		if len(args) > 2 {
+			blockNum = args[2]
+		}
+		return exportSnapshot(string(args[1]), blockNum)
 	}
 	return shim.Error(fmt.Sprintf("Requested function %s not found.", fname))
 }
@@ -219,6 +235,26 @@ func getConfigBlock(chainID []byte) pb.Response {
 	return shim.Success(blockBytes)
 }
 
+// exportSnapshot requests the export of a snapshot of the ledger of the specified
+// chainID as of the given block. A missing block number stands for the last block
+func exportSnapshot(chainID string, blockNumBytes []byte) pb.Response {
+	var blockNum uint64
+	if len(blockNumBytes) > 0 {
+		var err error
+		if blockNum, err = strconv.ParseUint(string(blockNumBytes), 10, 64); err != nil {
+			return shim.Error(fmt.Sprintf("Invalid block number %s: %s", blockNumBytes, err))
+		}
+	}
+	l := peer.GetLedger(chainID)
+	if l == nil {
+		return shim.Error(fmt.Sprintf("Unknown chain ID, %s", chainID))
+	}
+	if err := l.SubmitSnapshotRequest(blockNum); err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(nil)
+}
+
 // getChannels returns information about all channels for this peer
 func getChannels() pb.Response {
 	channelInfoArray := peer.GetChannelsInfo()
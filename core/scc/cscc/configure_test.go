@@ -115,6 +115,11 @@ func TestConfigerInvokeInvalidParameters(t *testing.T) {
 	assert.Equal(t, res.Status, int32(shim.ERROR), "CSCC invoke expected to fail no signed proposal provided")
 	assert.Contains(t, res.Message, "failed authorization check")
 
+	args = [][]byte{[]byte("ExportSnapshot"), []byte("testChainID")}
+	res = stub.MockInvokeWithSignedProposal("5", args, nil)
+	assert.Equal(t, res.Status, int32(shim.ERROR), "CSCC invoke expected to fail no signed proposal provided")
+	assert.Contains(t, res.Message, "failed authorization check")
+
 	args = [][]byte{[]byte("fooFunction"), []byte("testChainID")}
 	res = stub.MockInvoke("5", args)
 	assert.Equal(t, res.Status, int32(shim.ERROR), "CSCC invoke expected wrong function name provided")
@@ -276,6 +281,21 @@ func TestConfigerInvokeJoinChainCorrectParams(t *testing.T) {
 	if len(cqr.GetChannels()) != 1 {
 		t.FailNow()
 	}
+
+	// request the snapshot of a block not committed yet
+	args = [][]byte{[]byte(ExportSnapshot), []byte(chainID), []byte("5")}
+	res = stub.MockInvokeWithSignedProposal("2", args, sProp)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+
+	args = [][]byte{[]byte(ExportSnapshot), []byte(chainID), []byte("last")}
+	res = stub.MockInvokeWithSignedProposal("2", args, sProp)
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Contains(t, res.Message, "Invalid block number")
+
+	args = [][]byte{[]byte(ExportSnapshot), []byte("unknownchain")}
+	res = stub.MockInvokeWithSignedProposal("2", args, sProp)
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.Equal(t, "Unknown chain ID, unknownchain", res.Message)
 }
 
 func TestPeerConfiger_SubmittingOrdererGenesis(t *testing.T) {
@@ -26,6 +26,7 @@ import (
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/common/privdata"
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
 	"github.com/hyperledger/fabric/core/peer"
 	"github.com/hyperledger/fabric/core/policy"
@@ -236,6 +237,13 @@ func (f InstantiationPolicyMissing) Error() string {
 	return "instantiation policy missing"
 }
 
+//InvalidCollectionConfigErr invalid collection configuration error
+type InvalidCollectionConfigErr string
+
+func (f InvalidCollectionConfigErr) Error() string {
+	return fmt.Sprintf("invalid collection configuration(%s)", string(f))
+}
+
 //-------------- helper functions ------------------
 //create the chaincode on the given chain
 func (lscc *LifeCycleSysCC) createChaincode(stub shim.ChaincodeStubInterface, cd *ccprovider.ChaincodeData) error {
@@ -271,6 +279,32 @@ func (lscc *LifeCycleSysCC) putChaincodeData(stub shim.ChaincodeStubInterface, c
 	return err
 }
 
+//putChaincodeCollectionData stores the collection configuration of the
+//chaincode, if any. Without one, an existing configuration is left as is
+func (lscc *LifeCycleSysCC) putChaincodeCollectionData(stub shim.ChaincodeStubInterface, cd *ccprovider.ChaincodeData, collectionConfigBytes []byte) error {
+	if len(collectionConfigBytes) == 0 {
+		return nil
+	}
+
+	collectionConfig := &common.CollectionConfigPackage{}
+	if err := proto.Unmarshal(collectionConfigBytes, collectionConfig); err != nil {
+		return InvalidCollectionConfigErr(err.Error())
+	}
+
+	names := make(map[string]bool)
+	for _, collection := range collectionConfig.Config {
+		if collection == nil || collection.Name == "" {
+			return InvalidCollectionConfigErr("collection without a name")
+		}
+		if names[collection.Name] {
+			return InvalidCollectionConfigErr(fmt.Sprintf("duplicate collection %s", collection.Name))
+		}
+		names[collection.Name] = true
+	}
+
+	return stub.PutState(privdata.BuildCollectionKVSKey(cd.Name), collectionConfigBytes)
+}
+
 //checks for existence of chaincode on the given channel
 func (lscc *LifeCycleSysCC) getCCInstance(stub shim.ChaincodeStubInterface, ccname string) ([]byte, error) {
 	cdbytes, err := stub.GetState(ccname)
@@ -548,7 +582,7 @@ func (lscc *LifeCycleSysCC) checkInstantiationPolicy(stub shim.ChaincodeStubInte
 }
 
 // executeDeploy implements the "instantiate" Invoke transaction
-func (lscc *LifeCycleSysCC) executeDeploy(stub shim.ChaincodeStubInterface, chainname string, depSpec []byte, policy []byte, escc []byte, vscc []byte) (*ccprovider.ChaincodeData, error) {
+func (lscc *LifeCycleSysCC) executeDeploy(stub shim.ChaincodeStubInterface, chainname string, depSpec []byte, policy []byte, escc []byte, vscc []byte, collectionConfig []byte) (*ccprovider.ChaincodeData, error) {
 	cds, err := utils.GetChaincodeDeploymentSpec(depSpec)
 
 	if err != nil {
@@ -598,12 +632,20 @@ func (lscc *LifeCycleSysCC) executeDeploy(stub shim.ChaincodeStubInterface, chai
 	}
 
 	err = lscc.createChaincode(stub, cd)
+	if err != nil {
+		return nil, err
+	}
 
-	return cd, err
+	err = lscc.putChaincodeCollectionData(stub, cd, collectionConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return cd, nil
 }
 
 // executeUpgrade implements the "upgrade" Invoke transaction.
-func (lscc *LifeCycleSysCC) executeUpgrade(stub shim.ChaincodeStubInterface, chainName string, depSpec []byte, policy []byte, escc []byte, vscc []byte) (*ccprovider.ChaincodeData, error) {
+func (lscc *LifeCycleSysCC) executeUpgrade(stub shim.ChaincodeStubInterface, chainName string, depSpec []byte, policy []byte, escc []byte, vscc []byte, collectionConfig []byte) (*ccprovider.ChaincodeData, error) {
 	cds, err := utils.GetChaincodeDeploymentSpec(depSpec)
 	if err != nil {
 		return nil, err
@@ -678,6 +720,11 @@ func (lscc *LifeCycleSysCC) executeUpgrade(stub shim.ChaincodeStubInterface, cha
 		return nil, err
 	}
 
+	err = lscc.putChaincodeCollectionData(stub, cd, collectionConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	return cd, nil
 }
 
@@ -732,7 +779,7 @@ func (lscc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 		}
 		return shim.Success([]byte("OK"))
 	case DEPLOY:
-		if len(args) < 3 || len(args) > 6 {
+		if len(args) < 3 || len(args) > 7 {
 			return shim.Error(InvalidArgsLenErr(len(args)).Error())
 		}
 
@@ -753,6 +800,7 @@ func (lscc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 		// args[3] is a marshalled SignaturePolicyEnvelope representing the endorsement policy
 		// args[4] is the name of escc
 		// args[5] is the name of vscc
+		// args[6] is a marshalled CollectionConfigPackage
 		var policy []byte
 		if len(args) > 3 && len(args[3]) > 0 {
 			policy = args[3]
@@ -778,7 +826,12 @@ func (lscc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 			vscc = []byte("vscc")
 		}
 
-		cd, err := lscc.executeDeploy(stub, chainname, depSpec, policy, escc, vscc)
+		var collectionConfig []byte
+		if len(args) > 6 && args[6] != nil {
+			collectionConfig = args[6]
+		}
+
+		cd, err := lscc.executeDeploy(stub, chainname, depSpec, policy, escc, vscc, collectionConfig)
 		if err != nil {
 			return shim.Error(err.Error())
 		}
@@ -788,7 +841,7 @@ func (lscc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 		}
 		return shim.Success(cdbytes)
 	case UPGRADE:
-		if len(args) < 3 || len(args) > 6 {
+		if len(args) < 3 || len(args) > 7 {
 			return shim.Error(InvalidArgsLenErr(len(args)).Error())
 		}
 
@@ -806,6 +859,7 @@ func (lscc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 		// args[3] is a marshalled SignaturePolicyEnvelope representing the endorsement policy
 		// args[4] is the name of escc
 		// args[5] is the name of vscc
+		// args[6] is a marshalled CollectionConfigPackage
 		var policy []byte
 		if len(args) > 3 && len(args[3]) > 0 {
 			policy = args[3]
@@ -831,7 +885,12 @@ func (lscc *LifeCycleSysCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response
 			vscc = []byte("vscc")
 		}
 
-		cd, err := lscc.executeUpgrade(stub, chainname, depSpec, policy, escc, vscc)
+		var collectionConfig []byte
+		if len(args) > 6 && args[6] != nil {
+			collectionConfig = args[6]
+		}
+
+		cd, err := lscc.executeUpgrade(stub, chainname, depSpec, policy, escc, vscc, collectionConfig)
 		if err != nil {
 			return shim.Error(err.Error())
 		}
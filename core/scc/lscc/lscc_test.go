@@ -371,6 +371,44 @@ func TestRedeploy(t *testing.T) {
 	}
 }
 
+//TestDeployWithCollectionConfig tests that the collection configuration of a
+//chaincode is validated and stored upon deploy
+func TestDeployWithCollectionConfig(t *testing.T) {
+	scc := new(LifeCycleSysCC)
+	stub := shim.NewMockStub("lscc", scc)
+
+	res := stub.MockInit("1", nil)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+
+	cds, err := constructDeploymentSpec("example02", "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02", "0", [][]byte{[]byte("init"), []byte("a"), []byte("100"), []byte("b"), []byte("200")}, true)
+	assert.NoError(t, err)
+	defer os.Remove(lscctestpath + "/example02.0")
+	b := putils.MarshalOrPanic(cds)
+
+	duplicate := putils.MarshalOrPanic(&common.CollectionConfigPackage{Config: []*common.CollectionConfig{
+		{Name: "coll1", BlockToLive: 10},
+		{Name: "coll1"},
+	}})
+	sProp, _ := putils.MockSignedEndorserProposal2OrPanic(chainid, &pb.ChaincodeSpec{}, id)
+	args := [][]byte{[]byte(DEPLOY), []byte("test"), b, nil, nil, nil, duplicate}
+	res = stub.MockInvokeWithSignedProposal("1", args, sProp)
+	assert.NotEqual(t, int32(shim.OK), res.Status)
+	assert.Equal(t, InvalidCollectionConfigErr("duplicate collection coll1").Error(), res.Message)
+
+	// the mock stub does not discard the writes of a failed invocation
+	stub = shim.NewMockStub("lscc", scc)
+	res = stub.MockInit("1", nil)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+	collectionConfig := putils.MarshalOrPanic(&common.CollectionConfigPackage{Config: []*common.CollectionConfig{
+		{Name: "coll1", BlockToLive: 10},
+		{Name: "coll2"},
+	}})
+	args = [][]byte{[]byte(DEPLOY), []byte("test"), b, nil, nil, nil, collectionConfig}
+	res = stub.MockInvokeWithSignedProposal("1", args, sProp)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+	assert.Equal(t, collectionConfig, stub.State["example02~collection"])
+}
+
 //TestMultipleDeploy tests deploying multiple chaincodeschaincodes
 func TestMultipleDeploy(t *testing.T) {
 	scc := new(LifeCycleSysCC)
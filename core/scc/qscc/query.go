@@ -21,6 +21,7 @@ import (
 	"strconv"
 
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
@@ -129,6 +130,9 @@ func getTransactionByID(vledger ledger.PeerLedger, tid []byte) pb.Response {
 	}
 
 	processedTran, err := vledger.GetTransactionByID(string(tid))
+	if err == blkstorage.ErrPruned {
+		return shim.Error(fmt.Sprintf("Failed to get transaction with id %s, the transaction has been pruned", string(tid)))
+	}
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Failed to get transaction with id %s, error %s", string(tid), err))
 	}
@@ -150,6 +154,9 @@ func getBlockByNumber(vledger ledger.PeerLedger, number []byte) pb.Response {
 		return shim.Error(fmt.Sprintf("Failed to parse block number with error %s", err))
 	}
 	block, err := vledger.GetBlockByNumber(bnum)
+	if err == blkstorage.ErrPruned {
+		return shim.Error(fmt.Sprintf("Failed to get block number %d, the block has been pruned", bnum))
+	}
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Failed to get block number %d, error %s", bnum, err))
 	}
@@ -171,6 +178,9 @@ func getBlockByHash(vledger ledger.PeerLedger, hash []byte) pb.Response {
 		return shim.Error("Block hash must not be nil.")
 	}
 	block, err := vledger.GetBlockByHash(hash)
+	if err == blkstorage.ErrPruned {
+		return shim.Error(fmt.Sprintf("Failed to get block hash %s, the block has been pruned", string(hash)))
+	}
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Failed to get block hash %s, error %s", string(hash), err))
 	}
@@ -203,7 +213,9 @@ func getChainInfo(vledger ledger.PeerLedger) pb.Response {
 func getBlockByTxID(vledger ledger.PeerLedger, rawTxID []byte) pb.Response {
 	txID := string(rawTxID)
 	block, err := vledger.GetBlockByTxID(txID)
-
+	if err == blkstorage.ErrPruned {
+		return shim.Error(fmt.Sprintf("Failed to get block for txID %s, the block has been pruned", txID))
+	}
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Failed to get block for txID %s, error %s", txID, err))
 	}
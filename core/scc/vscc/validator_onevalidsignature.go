@@ -17,6 +17,7 @@ limitations under the License.
 package vscc
 
 import (
+	"bytes"
 	"fmt"
 
 	"errors"
@@ -27,6 +28,7 @@ import (
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/common/privdata"
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
 	"github.com/hyperledger/fabric/core/scc/lscc"
@@ -243,8 +245,8 @@ func (vscc *ValidatorOneValidSignature) ValidateLSCCInvocation(stub shim.Chainco
 	case lscc.UPGRADE, lscc.DEPLOY:
 		logger.Debugf("VSCC info: validating invocation of lscc function %s on arguments %#v", lsccFunc, lsccArgs)
 
-		if len(lsccArgs) < 2 || len(lsccArgs) > 5 {
-			return fmt.Errorf("Wrong number of arguments for invocation lscc(%s): expected between 2 and 5, received %d", lsccFunc, len(lsccArgs))
+		if len(lsccArgs) < 2 || len(lsccArgs) > 6 {
+			return fmt.Errorf("Wrong number of arguments for invocation lscc(%s): expected between 2 and 6, received %d", lsccFunc, len(lsccArgs))
 		}
 
 		cdsArgs, err := utils.GetChaincodeDeploymentSpec(lsccArgs[1])
@@ -297,17 +299,41 @@ func (vscc *ValidatorOneValidSignature) ValidateLSCCInvocation(stub shim.Chainco
 		if lsccrwset == nil {
 			return errors.New("No read write set for lscc was found")
 		}
-		// there can only be a single one
-		if len(lsccrwset.Writes) != 1 {
-			return errors.New("LSCC can only issue a single putState upon deploy/upgrade")
+		// there can only be one for the chaincode data and, if the
+		// invocation carries a collection configuration, one for it
+		var collectionConfig []byte
+		if len(lsccArgs) > 5 {
+			collectionConfig = lsccArgs[5]
 		}
-		// the key name must be the chaincode id
-		if lsccrwset.Writes[0].Key != cdsArgs.ChaincodeSpec.ChaincodeId.Name {
-			return fmt.Errorf("Expected key %s, found %s", cdsArgs.ChaincodeSpec.ChaincodeId.Name, lsccrwset.Writes[0].Key)
+		expectedWrites := 1
+		if len(collectionConfig) > 0 {
+			expectedWrites = 2
+		}
+		if len(lsccrwset.Writes) != expectedWrites {
+			return fmt.Errorf("LSCC can only issue %d putState upon deploy/upgrade, found %d", expectedWrites, len(lsccrwset.Writes))
+		}
+		var cdWrite *kvrwset.KVWrite
+		for _, write := range lsccrwset.Writes {
+			switch {
+			// the key name must be the chaincode id
+			case write.Key == cdsArgs.ChaincodeSpec.ChaincodeId.Name:
+				cdWrite = write
+			// or the key of its collection configuration, which must
+			// be the one of the invocation
+			case write.Key == privdata.BuildCollectionKVSKey(cdsArgs.ChaincodeSpec.ChaincodeId.Name) && len(collectionConfig) > 0:
+				if !bytes.Equal(write.Value, collectionConfig) {
+					return errors.New("LSCC wrote a collection configuration which differs from the one of the invocation")
+				}
+			default:
+				return fmt.Errorf("Expected key %s, found %s", cdsArgs.ChaincodeSpec.ChaincodeId.Name, write.Key)
+			}
+		}
+		if cdWrite == nil {
+			return fmt.Errorf("Expected key %s, found none", cdsArgs.ChaincodeSpec.ChaincodeId.Name)
 		}
 		// the value must be a ChaincodeData struct
 		cdRWSet := &ccprovider.ChaincodeData{}
-		err = proto.Unmarshal(lsccrwset.Writes[0].Value, cdRWSet)
+		err = proto.Unmarshal(cdWrite.Value, cdRWSet)
 		if err != nil {
 			return fmt.Errorf("Unmarhsalling of ChaincodeData failed, error %s", err)
 		}
@@ -34,6 +34,7 @@ import (
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/common/ccpackage"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/core/common/privdata"
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
 	cutils "github.com/hyperledger/fabric/core/container/util"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
@@ -959,6 +960,86 @@ func TestValidateDeployOK(t *testing.T) {
 	}
 }
 
+func TestValidateDeployWithCollectionConfig(t *testing.T) {
+	v := new(ValidatorOneValidSignature)
+	stub := shim.NewMockStub("validatoronevalidsignature", v)
+
+	lccc := new(lscc.LifeCycleSysCC)
+	stublccc := shim.NewMockStub("lscc", lccc)
+
+	State := make(map[string]map[string][]byte)
+	State["lscc"] = stublccc.State
+	sysccprovider.RegisterSystemChaincodeProviderFactory(&scc.MocksccProviderFactory{Qe: lm.NewMockQueryExecutor(State)})
+	stub.MockPeerChaincode("lscc", stublccc)
+
+	r1 := stub.MockInit("1", [][]byte{})
+	assert.Equal(t, int32(shim.OK), r1.Status, r1.Message)
+	r := stublccc.MockInit("1", [][]byte{})
+	assert.Equal(t, int32(shim.OK), r.Status, r.Message)
+
+	ccname := "mycc"
+	ccver := "1"
+	defaultPolicy, err := getSignedByMSPAdminPolicy(mspid)
+	assert.NoError(t, err)
+	policy, err := getSignedByMSPMemberPolicy(mspid)
+	assert.NoError(t, err)
+	collectionConfig := utils.MarshalOrPanic(&common.CollectionConfigPackage{
+		Config: []*common.CollectionConfig{{Name: "coll", BlockToLive: 5}},
+	})
+	otherCollectionConfig := utils.MarshalOrPanic(&common.CollectionConfigPackage{
+		Config: []*common.CollectionConfig{{Name: "coll", BlockToLive: 500}},
+	})
+
+	validate := func(written, invoked []byte) peer.Response {
+		cdbytes := utils.MarshalOrPanic(&ccprovider.ChaincodeData{Name: ccname, Version: ccver, InstantiationPolicy: defaultPolicy})
+		rwsetBuilder := rwsetutil.NewRWSetBuilder()
+		rwsetBuilder.AddToWriteSet("lscc", ccname, cdbytes)
+		if written != nil {
+			rwsetBuilder.AddToWriteSet("lscc", privdata.BuildCollectionKVSKey(ccname), written)
+		}
+		sr, err := rwsetBuilder.GetTxSimulationResults()
+		assert.NoError(t, err)
+		res, err := sr.GetPubSimulationBytes()
+		assert.NoError(t, err)
+
+		cdsBytes := utils.MarshalOrPanic(&peer.ChaincodeDeploymentSpec{
+			ChaincodeSpec: &peer.ChaincodeSpec{
+				ChaincodeId: &peer.ChaincodeID{Name: ccname, Version: ccver},
+				Type:        peer.ChaincodeSpec_GOLANG,
+			},
+		})
+		cis := &peer.ChaincodeInvocationSpec{
+			ChaincodeSpec: &peer.ChaincodeSpec{
+				ChaincodeId: &peer.ChaincodeID{Name: "lscc"},
+				Input: &peer.ChaincodeInput{
+					Args: [][]byte{[]byte(lscc.DEPLOY), []byte("barf"), cdsBytes, nil, nil, nil, invoked},
+				},
+				Type: peer.ChaincodeSpec_GOLANG,
+			},
+		}
+		prop, _, err := utils.CreateProposalFromCIS(common.HeaderType_ENDORSER_TRANSACTION, util.GetTestChainID(), cis, sid)
+		assert.NoError(t, err)
+		presp, err := utils.CreateProposalResponse(prop.Header, prop.Payload, &peer.Response{Status: 200}, res, nil, &peer.ChaincodeID{Name: ccname, Version: ccver}, nil, id)
+		assert.NoError(t, err)
+		tx, err := utils.CreateSignedTx(prop, id, presp)
+		assert.NoError(t, err)
+
+		return stub.MockInvoke("1", [][]byte{[]byte("dv"), utils.MarshalOrPanic(tx), policy})
+	}
+
+	res := validate(collectionConfig, collectionConfig)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+
+	res = validate(otherCollectionConfig, collectionConfig)
+	assert.NotEqual(t, int32(shim.OK), res.Status)
+
+	res = validate(collectionConfig, nil)
+	assert.NotEqual(t, int32(shim.OK), res.Status)
+
+	res = validate(nil, collectionConfig)
+	assert.NotEqual(t, int32(shim.OK), res.Status)
+}
+
 func TestValidateDeployWithPolicies(t *testing.T) {
 	v := new(ValidatorOneValidSignature)
 	stub := shim.NewMockStub("validatoronevalidsignature", v)
@@ -31,6 +31,10 @@ type Gossip interface {
 	// and also subscribed to the channel given
 	PeersOfChannel(common.ChainID) []discovery.NetworkMember
 
+	// GetOrgOfPeer returns the organization identifier of a certain peer,
+	// or nil if its identity is unknown
+	GetOrgOfPeer(pkiID common.PKIidType) api.OrgIdentityType
+
 	// UpdateMetadata updates the self metadata of the discovery layer
 	// the peer publishes to other peers
 	UpdateMetadata(metadata []byte)
@@ -302,6 +302,22 @@ func (li *mockLedgerInfo) GetPvtDataAndBlockByNum(seqNum uint64) (*ledger.BlockA
 	panic("implement me")
 }
 
+func (li *mockLedgerInfo) GetMissingPvtData(fromBlockNum uint64, maxEntries int) ([]*ledger.MissingPvtData, error) {
+	return nil, nil
+}
+
+func (li *mockLedgerInfo) GetMissingPvtDataCount() (ledger.MissingPvtDataCount, error) {
+	return nil, nil
+}
+
+func (li *mockLedgerInfo) GetCollectionMemberOrgs(ns, coll string) ([]string, error) {
+	return nil, nil
+}
+
+func (li *mockLedgerInfo) CommitPvtDataOfOldBlocks(blocksPvtData map[uint64][]*ledger.TxPvtData) error {
+	panic("implement me")
+}
+
 // LedgerHeight returns mocked value to the ledger height
 func (li *mockLedgerInfo) LedgerHeight() (uint64, error) {
 	return li.Height, nil
@@ -54,6 +54,10 @@ func (*gossipMock) PeersOfChannel(common.ChainID) []discovery.NetworkMember {
 	panic("implement me")
 }
 
+func (*gossipMock) GetOrgOfPeer(pkiID common.PKIidType) api.OrgIdentityType {
+	panic("implement me")
+}
+
 func (*gossipMock) UpdateMetadata(metadata []byte) {
 	panic("implement me")
 }
@@ -88,6 +88,22 @@ type Coordinator interface {
 	// GetBlockByNum returns block and related to the block private data
 	GetBlockByNum(seqNum uint64) (*common.Block, error)
 
+	// GetMissingPvtData returns, the oldest blocks first starting at block fromBlockNum,
+	// up to maxEntries of the private data that was missing when its block was committed
+	GetMissingPvtData(fromBlockNum uint64, maxEntries int) ([]*ledger.MissingPvtData, error)
+
+	// GetMissingPvtDataCount returns the number of transactions, per chaincode and
+	// collection, whose private data is missing
+	GetMissingPvtDataCount() (ledger.MissingPvtDataCount, error)
+
+	// GetCollectionMemberOrgs returns the MSP IDs of the member organizations of the
+	// given collection of the given chaincode, or nil if every organization is a member
+	GetCollectionMemberOrgs(ns, coll string) ([]string, error)
+
+	// CommitPvtDataOfOldBlocks commits the missing private data, keyed by block
+	// number, of already committed blocks
+	CommitPvtDataOfOldBlocks(blocksPvtData map[uint64][]*ledger.TxPvtData) error
+
 	// Get recent block sequence number
 	LedgerHeight() (uint64, error)
 
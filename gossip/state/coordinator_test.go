@@ -31,6 +31,26 @@ func (mock *committerMock) GetPvtDataAndBlockByNum(seqNum uint64) (*ledger.Block
 	return args.Get(0).(*ledger.BlockAndPvtData), args.Error(1)
 }
 
+func (mock *committerMock) GetMissingPvtData(fromBlockNum uint64, maxEntries int) ([]*ledger.MissingPvtData, error) {
+	args := mock.Called(fromBlockNum, maxEntries)
+	return args.Get(0).([]*ledger.MissingPvtData), args.Error(1)
+}
+
+func (mock *committerMock) GetMissingPvtDataCount() (ledger.MissingPvtDataCount, error) {
+	args := mock.Called()
+	return args.Get(0).(ledger.MissingPvtDataCount), args.Error(1)
+}
+
+func (mock *committerMock) GetCollectionMemberOrgs(ns, coll string) ([]string, error) {
+	args := mock.Called(ns, coll)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (mock *committerMock) CommitPvtDataOfOldBlocks(blocksPvtData map[uint64][]*ledger.TxPvtData) error {
+	args := mock.Called(blocksPvtData)
+	return args.Error(0)
+}
+
 func (mock *committerMock) Commit(block *common.Block) error {
 	args := mock.Called(block)
 	return args.Error(0)
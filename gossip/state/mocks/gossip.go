@@ -37,6 +37,14 @@ func (g *GossipMock) PeersOfChannel(chainID common.ChainID) []discovery.NetworkM
 	return args.Get(0).([]discovery.NetworkMember)
 }
 
+func (g *GossipMock) GetOrgOfPeer(pkiID common.PKIidType) api.OrgIdentityType {
+	args := g.Called(pkiID)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(api.OrgIdentityType)
+}
+
 func (g *GossipMock) UpdateMetadata(metadata []byte) {
 	g.Called(metadata)
 }
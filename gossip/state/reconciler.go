@@ -0,0 +1,265 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package state
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger/fabric/common/metrics"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/filter"
+	"github.com/hyperledger/fabric/gossip/util"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/hyperledger/fabric/protos/ledger/rwset"
+	"github.com/pkg/errors"
+)
+
+const (
+	defReconcileInterval  = time.Minute
+	defReconcileBatchSize = 100
+)
+
+// reconcilePvtData periodically fetches from other peers of the channel the private
+// data that was missing when its block was committed, and commits it into the ledger
+func (s *GossipStateProviderImpl) reconcilePvtData() {
+	defer s.done.Done()
+	defer logger.Debug("State Provider stopped, stopping private data reconciliation.")
+
+	interval := util.GetDurationOrDefault("peer.gossip.pvtData.reconcileInterval", defReconcileInterval)
+	for {
+		select {
+		case <-s.stopCh:
+			s.stopCh <- struct{}{}
+			return
+		case <-time.After(interval):
+			if err := s.reconcile(); err != nil {
+				logger.Errorf("Failed reconciling missing private data, due to %+v", errors.WithStack(err))
+			}
+		}
+	}
+}
+
+// reconcile makes a pass over a batch of the missing private data, a block at a time,
+// starting where the previous pass stopped, and from the oldest missing private data
+// once all of it was passed over. The ledger verifies the fetched private data against
+// the hashes present in the block before committing it
+func (s *GossipStateProviderImpl) reconcile() error {
+	missing, err := s.coordinator.GetMissingPvtData(s.reconcileFrom, defReconcileBatchSize)
+	if err != nil {
+		return err
+	}
+
+	// The missing private data comes ordered by block
+	var blockNums []uint64
+	missingByBlock := make(map[uint64][]*ledger.MissingPvtData)
+	for _, each := range missing {
+		if _, exists := missingByBlock[each.BlockNum]; !exists {
+			blockNums = append(blockNums, each.BlockNum)
+		}
+		missingByBlock[each.BlockNum] = append(missingByBlock[each.BlockNum], each)
+	}
+
+	switch {
+	case len(missing) < defReconcileBatchSize:
+		s.reconcileFrom = 0
+	case len(blockNums) > 1:
+		// The batch may end in the middle of the last block, which the next pass starts at
+		s.reconcileFrom = blockNums[len(blockNums)-1]
+		blockNums = blockNums[:len(blockNums)-1]
+	default:
+		s.reconcileFrom = blockNums[0] + 1
+	}
+
+	memberOrgs := make(map[string]map[string][]string)
+	for _, blockNum := range blockNums {
+		isMember, err := s.collectionMembers(missingByBlock[blockNum], memberOrgs)
+		if err != nil {
+			return err
+		}
+		pvtData, running := s.fetchMissingPvtData(blockNum, missingByBlock[blockNum], isMember)
+		if !running {
+			return nil
+		}
+		if len(pvtData) == 0 {
+			logger.Debugf("No peer provided the missing private data of block %d", blockNum)
+			continue
+		}
+		logger.Debugf("Committing missing private data of %d transaction(s) of block %d", len(pvtData), blockNum)
+		if err := s.coordinator.CommitPvtDataOfOldBlocks(map[uint64][]*ledger.TxPvtData{blockNum: pvtData}); err != nil {
+			return err
+		}
+	}
+	return s.reportMissingPvtDataCount()
+}
+
+// collectionMembers returns a predicate accepting the peers of the member organizations
+// of any of the missing collections, which alone are given their private data. The member
+// organizations are cached, per chaincode and collection, in the given map
+func (s *GossipStateProviderImpl) collectionMembers(missing []*ledger.MissingPvtData, memberOrgs map[string]map[string][]string) (filter.RoutingFilter, error) {
+	orgs := make(map[string]struct{})
+	for _, each := range missing {
+		collOrgs, exists := memberOrgs[each.Namespace][each.Collection]
+		if !exists {
+			var err error
+			if collOrgs, err = s.coordinator.GetCollectionMemberOrgs(each.Namespace, each.Collection); err != nil {
+				return nil, errors.Wrapf(err, "failed retrieving member organizations of collection [%s:%s]", each.Namespace, each.Collection)
+			}
+			if memberOrgs[each.Namespace] == nil {
+				memberOrgs[each.Namespace] = make(map[string][]string)
+			}
+			memberOrgs[each.Namespace][each.Collection] = collOrgs
+		}
+		if len(collOrgs) == 0 {
+			// Every organization of the channel is a member of the collection
+			return func(discovery.NetworkMember) bool { return true }, nil
+		}
+		for _, org := range collOrgs {
+			orgs[org] = struct{}{}
+		}
+	}
+	return func(peer discovery.NetworkMember) bool {
+		_, isMember := orgs[string(s.mediator.GetOrgOfPeer(peer.PKIid))]
+		return isMember
+	}, nil
+}
+
+// fetchMissingPvtData asks peers of the member organizations of the missing collections,
+// whose ledger has the given block, for its private data, until one of them provides some
+// of the missing collections, and returns the private data of the missing collections.
+// It returns false if the state provider was stopped
+func (s *GossipStateProviderImpl) fetchMissingPvtData(blockNum uint64, missing []*ledger.MissingPvtData, isMember filter.RoutingFilter) (PvtDataCollections, bool) {
+	defer atomic.StoreUint64(&s.reconcileNonce, 0)
+
+	peers := s.filterPeers(filter.CombineRoutingFilters(s.hasRequiredHeight(blockNum+1), isMember))
+	for tryCounts := 0; tryCounts <= defAntiEntropyMaxRetries && len(peers) > 0; tryCounts++ {
+		i := util.RandomInt(len(peers))
+		peer := peers[i]
+		peers = append(peers[:i], peers[i+1:]...)
+
+		gossipMsg := s.stateRequestMessage(blockNum, blockNum)
+		atomic.StoreUint64(&s.reconcileNonce, gossipMsg.Nonce)
+		logger.Debugf("Private data reconciliation, with peer %s, requesting block %d, for chainID %s",
+			peer.Endpoint, blockNum, s.chainID)
+		s.mediator.Send(gossipMsg, peer)
+
+		select {
+		case msg := <-s.reconcileResponseCh:
+			if msg.GetGossipMessage().Nonce != gossipMsg.Nonce {
+				continue
+			}
+			pvtData, err := selectMissingPvtData(msg.GetGossipMessage().GetStateResponse(), blockNum, missing)
+			if err != nil {
+				logger.Warningf("Wasn't able to process private data of block %d from peer %s, due to %+v",
+					blockNum, peer.Endpoint, errors.WithStack(err))
+				continue
+			}
+			if len(pvtData) > 0 {
+				return pvtData, true
+			}
+		case <-time.After(defAntiEntropyStateResponseTimeout):
+		case <-s.stopCh:
+			s.stopCh <- struct{}{}
+			return nil, false
+		}
+	}
+	return nil, true
+}
+
+// reportMissingPvtDataCount publishes, per chaincode and collection, the number of
+// transactions whose private data is still missing, resetting the count of the
+// collections reported in the previous pass and no longer missing
+func (s *GossipStateProviderImpl) reportMissingPvtDataCount() error {
+	count, err := s.coordinator.GetMissingPvtDataCount()
+	if err != nil {
+		return err
+	}
+	for ns, collCount := range count {
+		for coll, numTxs := range collCount {
+			logger.Debugf("Channel [%s]: private data of collection [%s:%s] still missing for %d transaction(s)",
+				s.chainID, ns, coll, numTxs)
+			s.missingPvtDataGauge(ns, coll).Update(float64(numTxs))
+			delete(s.reportedMissingPvt[ns], coll)
+		}
+	}
+	for ns, colls := range s.reportedMissingPvt {
+		for coll := range colls {
+			s.missingPvtDataGauge(ns, coll).Update(0)
+		}
+	}
+
+	s.reportedMissingPvt = make(map[string]map[string]struct{})
+	for ns, collCount := range count {
+		s.reportedMissingPvt[ns] = make(map[string]struct{})
+		for coll := range collCount {
+			s.reportedMissingPvt[ns][coll] = struct{}{}
+		}
+	}
+	return nil
+}
+
+func (s *GossipStateProviderImpl) missingPvtDataGauge(ns, coll string) metrics.Gauge {
+	return s.metricsScope.Tagged(map[string]string{
+		"chaincode":  ns,
+		"collection": coll,
+	}).Gauge("missing_pvtdata_transactions")
+}
+
+// selectMissingPvtData returns the private data of the missing collections of the
+// block present in the state response
+func selectMissingPvtData(response *proto.RemoteStateResponse, blockNum uint64, missing []*ledger.MissingPvtData) (PvtDataCollections, error) {
+	filters := make(map[uint64]ledger.PvtNsCollFilter)
+	for _, each := range missing {
+		if _, exists := filters[each.SeqInBlock]; !exists {
+			filters[each.SeqInBlock] = ledger.NewPvtNsCollFilter()
+		}
+		filters[each.SeqInBlock].Add(each.Namespace, each.Collection)
+	}
+
+	var selected PvtDataCollections
+	for _, payload := range response.GetPayloads() {
+		if payload.SeqNum != blockNum {
+			continue
+		}
+		var pvtData PvtDataCollections
+		if err := pvtData.Unmarshal(payload.PrivateData); err != nil {
+			return nil, err
+		}
+		for _, txPvtData := range pvtData {
+			filter, exists := filters[txPvtData.SeqInBlock]
+			if !exists {
+				continue
+			}
+			if writeSet := filterPvtWriteSet(txPvtData.WriteSet, filter); writeSet != nil {
+				selected = append(selected, &ledger.TxPvtData{SeqInBlock: txPvtData.SeqInBlock, WriteSet: writeSet})
+			}
+		}
+	}
+	return selected, nil
+}
+
+// filterPvtWriteSet returns the collections of the write set present in the filter,
+// or nil if there is none
+func filterPvtWriteSet(writeSet *rwset.TxPvtReadWriteSet, filter ledger.PvtNsCollFilter) *rwset.TxPvtReadWriteSet {
+	var nsPvtRwSets []*rwset.NsPvtReadWriteSet
+	for _, ns := range writeSet.NsPvtRwset {
+		var collPvtRwSets []*rwset.CollectionPvtReadWriteSet
+		for _, coll := range ns.CollectionPvtRwset {
+			if filter.Has(ns.Namespace, coll.CollectionName) {
+				collPvtRwSets = append(collPvtRwSets, coll)
+			}
+		}
+		if collPvtRwSets != nil {
+			nsPvtRwSets = append(nsPvtRwSets, &rwset.NsPvtReadWriteSet{Namespace: ns.Namespace, CollectionPvtRwset: collPvtRwSets})
+		}
+	}
+	if nsPvtRwSets == nil {
+		return nil
+	}
+	return &rwset.TxPvtReadWriteSet{DataModel: writeSet.DataModel, NsPvtRwset: nsPvtRwSets}
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	pb "github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/metrics"
 	"github.com/hyperledger/fabric/core/committer"
 	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/gossip/comm"
@@ -73,6 +74,10 @@ type GossipAdapter interface {
 	// PeersOfChannel returns the NetworkMembers considered alive
 	// and also subscribed to the channel given
 	PeersOfChannel(common2.ChainID) []discovery.NetworkMember
+
+	// GetOrgOfPeer returns the organization identifier of a certain peer,
+	// or nil if its identity is unknown
+	GetOrgOfPeer(pkiID common2.PKIidType) api.OrgIdentityType
 }
 
 // MCSAdapter adapter of message crypto service interface to bound
@@ -121,6 +126,21 @@ type GossipStateProviderImpl struct {
 
 	stateRequestCh chan proto.ReceivedMessage
 
+	// Channel to read the responses to the requests for missing private data from
+	reconcileResponseCh chan proto.ReceivedMessage
+
+	// Nonce of the pending request for missing private data, if any
+	reconcileNonce uint64
+
+	// Block from which the next pass over the missing private data starts, so that
+	// the passes move past the private data no peer provides
+	reconcileFrom uint64
+
+	// Scope of the metrics of the channel, and the collections, per chaincode,
+	// whose private data was last reported missing
+	metricsScope       metrics.Scope
+	reportedMissingPvt map[string]map[string]struct{}
+
 	stopCh chan struct{}
 
 	done sync.WaitGroup
@@ -207,6 +227,12 @@ func NewGossipCoordinatedStateProvider(chainID string, services *ServicesMediato
 
 		stateRequestCh: make(chan proto.ReceivedMessage, defChannelBufferSize),
 
+		reconcileResponseCh: make(chan proto.ReceivedMessage, 1),
+
+		metricsScope: metrics.NewRootScope().SubScope("gossip").Tagged(map[string]string{
+			"channel": chainID,
+		}),
+
 		stopCh: make(chan struct{}, 1),
 
 		stateTransferActive: 0,
@@ -227,7 +253,7 @@ func NewGossipCoordinatedStateProvider(chainID string, services *ServicesMediato
 		logger.Errorf("Unable to serialize node meta nodeMetastate, error = %+v", errors.WithStack(err))
 	}
 
-	s.done.Add(4)
+	s.done.Add(5)
 
 	// Listen for incoming communication
 	go s.listen()
@@ -237,6 +263,8 @@ func NewGossipCoordinatedStateProvider(chainID string, services *ServicesMediato
 	go s.antiEntropy()
 	// Taking care of state request messages
 	go s.processStateRequests()
+	// Fetch the private data missing from the ledger
+	go s.reconcilePvtData()
 
 	return s
 }
@@ -290,6 +318,15 @@ func (s *GossipStateProviderImpl) directMessage(msg proto.ReceivedMessage) {
 			s.stateRequestCh <- msg
 		}
 	} else if incoming.GetStateResponse() != nil {
+		if nonce := atomic.LoadUint64(&s.reconcileNonce); nonce != 0 && incoming.Nonce == nonce {
+			// Response to a request for missing private data, drop it
+			// if a response for the same request is already pending
+			select {
+			case s.reconcileResponseCh <- msg:
+			default:
+			}
+			return
+		}
 		// If no state transfer procedure activate there is
 		// no reason to process the message
 		if atomic.LoadInt32(&s.stateTransferActive) == 1 {
@@ -433,6 +470,7 @@ func (s *GossipStateProviderImpl) Stop() {
 		s.coordinator.Close()
 		close(s.stateRequestCh)
 		close(s.stateResponseCh)
+		close(s.reconcileResponseCh)
 		close(s.stopCh)
 	})
 }
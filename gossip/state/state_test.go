@@ -18,6 +18,7 @@ import (
 
 	pb "github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/configtx/test"
+	"github.com/hyperledger/fabric/common/metrics"
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/committer"
 	"github.com/hyperledger/fabric/core/ledger"
@@ -174,6 +175,22 @@ func (mc *mockCommitter) GetPvtDataAndBlockByNum(seqNum uint64) (*ledger.BlockAn
 	return args.Get(0).(*ledger.BlockAndPvtData), args.Error(1)
 }
 
+func (mc *mockCommitter) GetMissingPvtData(fromBlockNum uint64, maxEntries int) ([]*ledger.MissingPvtData, error) {
+	return nil, nil
+}
+
+func (mc *mockCommitter) GetMissingPvtDataCount() (ledger.MissingPvtDataCount, error) {
+	return nil, nil
+}
+
+func (mc *mockCommitter) GetCollectionMemberOrgs(ns, coll string) ([]string, error) {
+	return nil, nil
+}
+
+func (mc *mockCommitter) CommitPvtDataOfOldBlocks(blocksPvtData map[uint64][]*ledger.TxPvtData) error {
+	return nil
+}
+
 func (mc *mockCommitter) Commit(block *pcomm.Block) error {
 	mc.Lock()
 	m := mc.Mock
@@ -975,6 +992,29 @@ func (mock *coordinatorMock) StoreBlock(block *pcomm.Block, data PvtDataCollecti
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (mock *coordinatorMock) GetMissingPvtData(fromBlockNum uint64, maxEntries int) ([]*ledger.MissingPvtData, error) {
+	args := mock.Called(fromBlockNum, maxEntries)
+	return args.Get(0).([]*ledger.MissingPvtData), args.Error(1)
+}
+
+func (mock *coordinatorMock) GetMissingPvtDataCount() (ledger.MissingPvtDataCount, error) {
+	args := mock.Called()
+	return args.Get(0).(ledger.MissingPvtDataCount), args.Error(1)
+}
+
+func (mock *coordinatorMock) GetCollectionMemberOrgs(ns, coll string) ([]string, error) {
+	args := mock.Called(ns, coll)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (mock *coordinatorMock) CommitPvtDataOfOldBlocks(blocksPvtData map[uint64][]*ledger.TxPvtData) error {
+	args := mock.Called(blocksPvtData)
+	return args.Error(0)
+}
+
 func (mock *coordinatorMock) LedgerHeight() (uint64, error) {
 	args := mock.Called()
 	return args.Get(0).(uint64), args.Error(1)
@@ -1367,6 +1407,266 @@ func TestTransferOfPvtDataBetweenPeers(t *testing.T) {
 	}
 }
 
+func TestReconcileMissingPvtData(t *testing.T) {
+	/*
+	   There are two peers: "peer1" and "peer2", having the same ledger height, while peer2
+	   misses the private data of one collection of a transaction of block 1. A third peer
+	   "peer3", of an organization which isn't a member of the collection, also has block 1.
+
+	   Test going to check that peer2 fetches the private data of block 1 from peer1, never
+	   asks peer3, and commits only the private data of the missing collection.
+	*/
+	viper.Set("peer.gossip.pvtData.reconcileInterval", 100*time.Millisecond)
+	defer viper.Set("peer.gossip.pvtData.reconcileInterval", nil)
+
+	chainID := "testChainID"
+	reconcilePeers := map[string]testPeer{}
+	for _, id := range []string{"peer1", "peer2"} {
+		peer := testPeer{
+			id:            id,
+			gossipChannel: make(chan *proto.GossipMessage),
+			commChannel:   make(chan proto.ReceivedMessage),
+			GossipMock:    &mocks.GossipMock{},
+			coord:         new(coordinatorMock),
+		}
+		peer.On("Accept", mock.Anything, false).Return(peer.Gossip(), nil)
+		peer.On("Accept", mock.Anything, true).Return(nil, peer.Comm())
+		peer.On("UpdateChannelMetadata", mock.Anything, mock.Anything)
+		peer.coord.On("LedgerHeight", mock.Anything).Return(uint64(2), nil)
+		peer.coord.On("GetMissingPvtDataCount").Return(ledger.MissingPvtDataCount{}, nil)
+		peer.coord.On("Close")
+		peer.On("Close")
+		reconcilePeers[id] = peer
+	}
+
+	pvtWriteSet := func(collections ...string) *rwset.TxPvtReadWriteSet {
+		nsPvtRwSet := &rwset.NsPvtReadWriteSet{Namespace: "myCC:v1"}
+		for _, coll := range collections {
+			nsPvtRwSet.CollectionPvtRwset = append(nsPvtRwSet.CollectionPvtRwset,
+				&rwset.CollectionPvtReadWriteSet{CollectionName: coll, Rwset: []byte(coll)})
+		}
+		return &rwset.TxPvtReadWriteSet{DataModel: rwset.TxReadWriteSet_KV, NsPvtRwset: []*rwset.NsPvtReadWriteSet{nsPvtRwSet}}
+	}
+
+	peer1, peer2 := reconcilePeers["peer1"], reconcilePeers["peer2"]
+	peer1.coord.On("GetMissingPvtData", mock.Anything, mock.Anything).Return([]*ledger.MissingPvtData{}, nil)
+	peer1.coord.On("GetPvtDataAndBlockByNum", uint64(1)).Return(&pcomm.Block{
+		Header: &pcomm.BlockHeader{Number: 1},
+		Data:   &pcomm.BlockData{Data: [][]byte{{1}, {2}}},
+	}, PvtDataCollections{&ledger.TxPvtData{SeqInBlock: 1, WriteSet: pvtWriteSet("coll1", "coll2")}}, nil)
+
+	peer2.coord.On("GetMissingPvtData", mock.Anything, mock.Anything).Return([]*ledger.MissingPvtData{
+		{BlockNum: 1, SeqInBlock: 1, Namespace: "myCC:v1", Collection: "coll2"},
+	}, nil).Once()
+	peer2.coord.On("GetMissingPvtData", mock.Anything, mock.Anything).Return([]*ledger.MissingPvtData{}, nil)
+	peer2.coord.On("GetCollectionMemberOrgs", "myCC:v1", "coll2").Return([]string{"Org1MSP", "Org2MSP"}, nil)
+	peer2.On("GetOrgOfPeer", common.PKIidType([]byte{1})).Return(api.OrgIdentityType("Org1MSP"))
+	peer2.On("GetOrgOfPeer", common.PKIidType([]byte{3})).Return(api.OrgIdentityType("Org3MSP"))
+	committed := make(chan map[uint64][]*ledger.TxPvtData, 1)
+	peer2.coord.On("CommitPvtDataOfOldBlocks", mock.Anything).Run(func(args mock.Arguments) {
+		committed <- args.Get(0).(map[uint64][]*ledger.TxPvtData)
+	}).Return(nil)
+
+	// Return membership of the peers
+	metastate := &common.NodeMetastate{LedgerHeight: uint64(2)}
+	metaBytes, err := metastate.Bytes()
+	assert.NoError(t, err)
+	peer1.On("PeersOfChannel", mock.Anything).Return([]discovery.NetworkMember{{
+		PKIid: common.PKIidType([]byte{2}), Endpoint: "peer2:7051", InternalEndpoint: "peer2:7051", Metadata: metaBytes,
+	}})
+	peer2.On("PeersOfChannel", mock.Anything).Return([]discovery.NetworkMember{{
+		PKIid: common.PKIidType([]byte{1}), Endpoint: "peer1:7051", InternalEndpoint: "peer1:7051", Metadata: metaBytes,
+	}, {
+		PKIid: common.PKIidType([]byte{3}), Endpoint: "peer3:7051", InternalEndpoint: "peer3:7051", Metadata: metaBytes,
+	}})
+
+	peer2.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		for _, remotePeer := range args.Get(1).([]*comm.RemotePeer) {
+			assert.Equal(t, "peer1:7051", remotePeer.Endpoint, "Private data requested from a peer of a non member organization")
+		}
+		request := args.Get(0).(*proto.GossipMessage)
+		requestMsg := new(receivedMessageMock)
+		msg, _ := request.NoopSign()
+		requestMsg.On("GetGossipMessage").Return(msg)
+
+		requestMsg.On("Respond", mock.Anything).Run(func(args mock.Arguments) {
+			response := args.Get(0).(*proto.GossipMessage)
+			receivedMsg := new(receivedMessageMock)
+			msg, _ := response.NoopSign()
+			receivedMsg.On("GetGossipMessage").Return(msg)
+			// Send response back to the peer
+			peer2.commChannel <- receivedMsg
+		})
+
+		peer1.commChannel <- requestMsg
+	})
+
+	cryptoService := &cryptoServiceMock{acceptor: noopPeerIdentityAcceptor}
+
+	mediator := &ServicesMediator{GossipAdapter: peer1, MCSAdapter: cryptoService}
+	peer1State := NewGossipCoordinatedStateProvider(chainID, mediator, peer1.coord)
+	defer peer1State.Stop()
+
+	mediator = &ServicesMediator{GossipAdapter: peer2, MCSAdapter: cryptoService}
+	peer2State := NewGossipCoordinatedStateProvider(chainID, mediator, peer2.coord)
+	defer peer2State.Stop()
+
+	select {
+	case blocksPvtData := <-committed:
+		assert.Equal(t, map[uint64][]*ledger.TxPvtData{
+			1: {{SeqInBlock: 1, WriteSet: pvtWriteSet("coll2")}},
+		}, blocksPvtData)
+	case <-time.After(30 * time.Second):
+		t.Fatal("Missing private data wasn't reconciled")
+	}
+}
+
+func TestReconcileMovesPastUnavailablePvtData(t *testing.T) {
+	coord := new(coordinatorMock)
+	g := &mocks.GossipMock{}
+	// No peer provides the missing private data
+	g.On("PeersOfChannel", mock.Anything).Return([]discovery.NetworkMember{})
+	s := &GossipStateProviderImpl{chainID: "testchainid", coordinator: coord, mediator: &ServicesMediator{GossipAdapter: g}}
+	missing := func(blockNum uint64, numTxs int) []*ledger.MissingPvtData {
+		var res []*ledger.MissingPvtData
+		for i := 0; i < numTxs; i++ {
+			res = append(res, &ledger.MissingPvtData{BlockNum: blockNum, SeqInBlock: uint64(i), Namespace: "myCC", Collection: "coll1"})
+		}
+		return res
+	}
+	coord.On("GetCollectionMemberOrgs", "myCC", "coll1").Return(nil, nil)
+	coord.On("GetMissingPvtDataCount").Return(ledger.MissingPvtDataCount{}, nil)
+
+	// The batch ends in the middle of block 2, which the next pass starts at
+	coord.On("GetMissingPvtData", uint64(0), defReconcileBatchSize).Return(append(missing(1, 60), missing(2, 40)...), nil).Once()
+	assert.NoError(t, s.reconcile())
+	assert.Equal(t, uint64(2), s.reconcileFrom)
+
+	// A batch of a single block is passed over entirely
+	coord.On("GetMissingPvtData", uint64(2), defReconcileBatchSize).Return(missing(2, defReconcileBatchSize), nil).Once()
+	assert.NoError(t, s.reconcile())
+	assert.Equal(t, uint64(3), s.reconcileFrom)
+
+	// Once all of the missing private data was passed over, the next pass starts from the oldest
+	coord.On("GetMissingPvtData", uint64(3), defReconcileBatchSize).Return(missing(4, 10), nil).Once()
+	assert.NoError(t, s.reconcile())
+	assert.Equal(t, uint64(0), s.reconcileFrom)
+	coord.AssertExpectations(t)
+}
+
+func TestReconcileCollectionMembers(t *testing.T) {
+	coord := new(coordinatorMock)
+	coord.On("GetCollectionMemberOrgs", "myCC", "coll1").Return([]string{"Org1MSP"}, nil)
+	coord.On("GetCollectionMemberOrgs", "myCC", "coll2").Return([]string{"Org2MSP"}, nil)
+	coord.On("GetCollectionMemberOrgs", "myCC", "coll3").Return(nil, nil)
+	coord.On("GetCollectionMemberOrgs", "myCC", "broken").Return(nil, errors.New("no lscc entry"))
+	g := &mocks.GossipMock{}
+	for i, org := range []string{"Org1MSP", "Org2MSP", "Org3MSP"} {
+		g.On("GetOrgOfPeer", common.PKIidType([]byte{byte(i + 1)})).Return(api.OrgIdentityType(org))
+	}
+	s := &GossipStateProviderImpl{coordinator: coord, mediator: &ServicesMediator{GossipAdapter: g}}
+	peer := func(id byte) discovery.NetworkMember {
+		return discovery.NetworkMember{PKIid: common.PKIidType([]byte{id})}
+	}
+	missing := func(colls ...string) []*ledger.MissingPvtData {
+		var res []*ledger.MissingPvtData
+		for _, coll := range colls {
+			res = append(res, &ledger.MissingPvtData{Namespace: "myCC", Collection: coll})
+		}
+		return res
+	}
+
+	memberOrgs := make(map[string]map[string][]string)
+	isMember, err := s.collectionMembers(missing("coll1"), memberOrgs)
+	assert.NoError(t, err)
+	assert.True(t, isMember(peer(1)))
+	assert.False(t, isMember(peer(2)))
+	assert.False(t, isMember(peer(3)))
+
+	isMember, err = s.collectionMembers(missing("coll1", "coll2"), memberOrgs)
+	assert.NoError(t, err)
+	assert.True(t, isMember(peer(1)))
+	assert.True(t, isMember(peer(2)))
+	assert.False(t, isMember(peer(3)))
+
+	// Every organization is a member of a collection without member organizations
+	isMember, err = s.collectionMembers(missing("coll1", "coll3"), memberOrgs)
+	assert.NoError(t, err)
+	assert.True(t, isMember(peer(3)))
+
+	// The member organizations are retrieved once per collection
+	coord.AssertNumberOfCalls(t, "GetCollectionMemberOrgs", 3)
+
+	_, err = s.collectionMembers(missing("broken"), memberOrgs)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "myCC:broken")
+}
+
+// gaugeScope records the values of the gauges, keyed by name, chaincode and collection
+type gaugeScope struct {
+	tags   map[string]string
+	gauges map[string]float64
+}
+
+func (s *gaugeScope) Counter(name string) metrics.Counter {
+	panic("implement me")
+}
+
+func (s *gaugeScope) Gauge(name string) metrics.Gauge {
+	return gaugeFunc(func(value float64) {
+		s.gauges[fmt.Sprintf("%s.%s.%s", name, s.tags["chaincode"], s.tags["collection"])] = value
+	})
+}
+
+func (s *gaugeScope) Tagged(tags map[string]string) metrics.Scope {
+	return &gaugeScope{tags: tags, gauges: s.gauges}
+}
+
+func (s *gaugeScope) SubScope(name string) metrics.Scope {
+	panic("implement me")
+}
+
+type gaugeFunc func(value float64)
+
+func (f gaugeFunc) Update(value float64) {
+	f(value)
+}
+
+func TestReportMissingPvtDataCount(t *testing.T) {
+	coord := new(coordinatorMock)
+	coord.On("GetMissingPvtDataCount").Return(ledger.MissingPvtDataCount{
+		"myCC": {"coll1": 3, "coll2": 1},
+	}, nil).Once()
+	coord.On("GetMissingPvtDataCount").Return(ledger.MissingPvtDataCount{
+		"myCC": {"coll1": 2},
+	}, nil).Once()
+	coord.On("GetMissingPvtDataCount").Return(ledger.MissingPvtDataCount{}, nil).Once()
+	coord.On("GetMissingPvtDataCount").Return(ledger.MissingPvtDataCount(nil), errors.New("ledger closed"))
+	scope := &gaugeScope{gauges: make(map[string]float64)}
+	s := &GossipStateProviderImpl{chainID: "testChainID", coordinator: coord, metricsScope: scope}
+
+	assert.NoError(t, s.reportMissingPvtDataCount())
+	assert.Equal(t, map[string]float64{
+		"missing_pvtdata_transactions.myCC.coll1": 3,
+		"missing_pvtdata_transactions.myCC.coll2": 1,
+	}, scope.gauges)
+
+	// The collections no longer missing are reset
+	assert.NoError(t, s.reportMissingPvtDataCount())
+	assert.Equal(t, map[string]float64{
+		"missing_pvtdata_transactions.myCC.coll1": 2,
+		"missing_pvtdata_transactions.myCC.coll2": 0,
+	}, scope.gauges)
+
+	assert.NoError(t, s.reportMissingPvtDataCount())
+	assert.Equal(t, map[string]float64{
+		"missing_pvtdata_transactions.myCC.coll1": 0,
+		"missing_pvtdata_transactions.myCC.coll2": 0,
+	}, scope.gauges)
+
+	assert.Error(t, s.reportMissingPvtDataCount())
+}
+
 func waitUntilTrueOrTimeout(t *testing.T, predicate func() bool, timeout time.Duration) {
 	ch := make(chan struct{})
 	go func() {
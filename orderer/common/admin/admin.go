@@ -0,0 +1,216 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package admin implements the admin gRPC service of the orderer, which
+// reports the channels served by the orderer to the admins of its local MSP.
+package admin
+
+import (
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/hyperledger/fabric/orderer/common/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+var logger = logging.MustGetLogger("orderer/common/admin")
+
+// maxClockSkew bounds how far the timestamp of a request may be from the
+// clock of the orderer, so that captured requests cannot be replayed forever
+const maxClockSkew = 15 * time.Minute
+
+// Support provides the channel resources needed by the admin service
+type Support interface {
+	// SystemChannelID returns the ID of the system channel
+	SystemChannelID() string
+
+	// ChannelIDs returns the IDs of the channels served by the orderer
+	ChannelIDs() []string
+
+	// GetChain returns the resources of a channel and whether it exists
+	GetChain(chainID string) (ChainSupport, bool)
+}
+
+// ChainSupport provides the resources of a channel needed by the admin service
+type ChainSupport interface {
+	// Reader returns the ledger of the channel
+	Reader() ledger.Reader
+
+	// SharedConfig returns the orderer config of the channel
+	SharedConfig() channelconfig.Orderer
+
+	// Sequence returns the current config sequence of the channel
+	Sequence() uint64
+
+	// ConfigEnvelope returns the current config of the channel
+	ConfigEnvelope() *cb.ConfigEnvelope
+
+	// Errored returns a channel which is closed when the consenter has errored
+	Errored() <-chan struct{}
+}
+
+type handlerImpl struct {
+	support         Support
+	deserializer    msp.IdentityDeserializer
+	principalGetter mgmt.MSPPrincipalGetter
+}
+
+// NewHandlerImpl constructs an ab.AdminServer which authorizes the requests
+// signed by identities satisfying the Admins principal of the local MSP
+func NewHandlerImpl(support Support, deserializer msp.IdentityDeserializer, principalGetter mgmt.MSPPrincipalGetter) ab.AdminServer {
+	return &handlerImpl{
+		support:         support,
+		deserializer:    deserializer,
+		principalGetter: principalGetter,
+	}
+}
+
+// ListChannels returns the channels served by the orderer, sorted by ID
+func (h *handlerImpl) ListChannels(ctx context.Context, env *cb.Envelope) (*ab.ChannelList, error) {
+	if _, err := h.authorize(env); err != nil {
+		logger.Warningf("Rejecting channel list request: %s", err)
+		return nil, err
+	}
+
+	list := &ab.ChannelList{}
+	for _, chainID := range h.support.ChannelIDs() {
+		cs, ok := h.support.GetChain(chainID)
+		if !ok {
+			// The channel list is not taken atomically with the chains
+			continue
+		}
+		info, err := h.channelInfo(chainID, cs)
+		if err != nil {
+			return nil, err
+		}
+		list.Channels = append(list.Channels, info)
+	}
+
+	return list, nil
+}
+
+// GetChannelConfig returns a channel along with its current config
+func (h *handlerImpl) GetChannelConfig(ctx context.Context, env *cb.Envelope) (*ab.ChannelConfigResponse, error) {
+	payload, err := h.authorize(env)
+	if err != nil {
+		logger.Warningf("Rejecting channel config request: %s", err)
+		return nil, err
+	}
+
+	request := &ab.ChannelConfigRequest{}
+	if err := proto.Unmarshal(payload.Data, request); err != nil {
+		return nil, errors.Wrap(err, "malformed channel config request")
+	}
+
+	cs, ok := h.support.GetChain(request.ChannelId)
+	if !ok {
+		return nil, errors.Errorf("channel %s not found", request.ChannelId)
+	}
+
+	info, err := h.channelInfo(request.ChannelId, cs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ab.ChannelConfigResponse{
+		Info:   info,
+		Config: cs.ConfigEnvelope().Config,
+	}, nil
+}
+
+func (h *handlerImpl) channelInfo(chainID string, cs ChainSupport) (*ab.ChannelInfo, error) {
+	reader := cs.Reader()
+	height := reader.Height()
+	lastBlock := ledger.GetBlock(reader, height-1)
+	if lastBlock == nil {
+		return nil, errors.Errorf("[channel: %s] could not retrieve block %d", chainID, height-1)
+	}
+
+	lastConfig, err := utils.GetLastConfigIndexFromBlock(lastBlock)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[channel: %s] could not retrieve the last config index", chainID)
+	}
+
+	info := &ab.ChannelInfo{
+		ChannelId:       chainID,
+		Height:          height,
+		ConsensusType:   cs.SharedConfig().ConsensusType(),
+		LastConfigBlock: lastConfig,
+		ConfigSequence:  cs.Sequence(),
+		SystemChannel:   chainID == h.support.SystemChannelID(),
+	}
+
+	select {
+	case <-cs.Errored():
+		info.Errored = true
+	default:
+	}
+
+	return info, nil
+}
+
+// authorize checks that the envelope is recent and signed by an admin of the
+// local MSP, and returns its payload
+func (h *handlerImpl) authorize(env *cb.Envelope) (*cb.Payload, error) {
+	if env == nil {
+		return nil, errors.New("nil envelope")
+	}
+
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed payload")
+	}
+
+	if payload.Header == nil {
+		return nil, errors.New("missing header")
+	}
+
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed channel header")
+	}
+
+	if chdr.Timestamp == nil {
+		return nil, errors.New("missing timestamp")
+	}
+	timestamp := time.Unix(chdr.Timestamp.Seconds, int64(chdr.Timestamp.Nanos))
+	if skew := time.Since(timestamp); skew > maxClockSkew || skew < -maxClockSkew {
+		return nil, errors.Errorf("timestamp %s is more than %s away from the orderer clock", timestamp, maxClockSkew)
+	}
+
+	shdr, err := utils.GetSignatureHeader(payload.Header.SignatureHeader)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed signature header")
+	}
+
+	id, err := h.deserializer.DeserializeIdentity(shdr.Creator)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed deserializing the creator")
+	}
+
+	principal, err := h.principalGetter.Get(mgmt.Admins)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed getting the local MSP admin principal")
+	}
+
+	if err := id.SatisfiesPrincipal(principal); err != nil {
+		return nil, errors.Wrap(err, "the creator is not an admin of the local MSP")
+	}
+
+	if err := id.Verify(env.Payload, env.Signature); err != nil {
+		return nil, errors.Wrap(err, "invalid signature")
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,188 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package admin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	"github.com/hyperledger/fabric/core/policy/mocks"
+	"github.com/hyperledger/fabric/orderer/common/ledger"
+	ramledger "github.com/hyperledger/fabric/orderer/common/ledger/ram"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+const (
+	systemChainID = "systemchain"
+	appChainID    = "appchain"
+)
+
+var adminIdentity = []byte("admin")
+
+type mockChainSupport struct {
+	ledger   ledger.ReadWriter
+	sequence uint64
+	errored  chan struct{}
+}
+
+func newMockChainSupport(chainID string) *mockChainSupport {
+	rl, _ := ramledger.New(10).GetOrCreate(chainID)
+
+	genesis := cb.NewBlock(0, nil)
+	genesis.Data.Data = [][]byte{utils.MarshalOrPanic(&cb.Envelope{})}
+	genesis.Header.DataHash = genesis.Data.Hash()
+	genesis.Metadata.Metadata[cb.BlockMetadataIndex_LAST_CONFIG] = utils.MarshalOrPanic(&cb.Metadata{
+		Value: utils.MarshalOrPanic(&cb.LastConfig{Index: 0}),
+	})
+	rl.Append(genesis)
+
+	return &mockChainSupport{ledger: rl, errored: make(chan struct{})}
+}
+
+func (mcs *mockChainSupport) Reader() ledger.Reader {
+	return mcs.ledger
+}
+
+func (mcs *mockChainSupport) SharedConfig() channelconfig.Orderer {
+	return &mockconfig.Orderer{ConsensusTypeVal: "solo"}
+}
+
+func (mcs *mockChainSupport) Sequence() uint64 {
+	return mcs.sequence
+}
+
+func (mcs *mockChainSupport) ConfigEnvelope() *cb.ConfigEnvelope {
+	return &cb.ConfigEnvelope{Config: &cb.Config{Sequence: mcs.sequence}}
+}
+
+func (mcs *mockChainSupport) Errored() <-chan struct{} {
+	return mcs.errored
+}
+
+type mockSupport struct {
+	chains map[string]*mockChainSupport
+}
+
+func newMockSupport() *mockSupport {
+	return &mockSupport{
+		chains: map[string]*mockChainSupport{
+			systemChainID: newMockChainSupport(systemChainID),
+			appChainID:    newMockChainSupport(appChainID),
+		},
+	}
+}
+
+func (ms *mockSupport) SystemChannelID() string {
+	return systemChainID
+}
+
+func (ms *mockSupport) ChannelIDs() []string {
+	return []string{appChainID, systemChainID}
+}
+
+func (ms *mockSupport) GetChain(chainID string) (ChainSupport, bool) {
+	cs, ok := ms.chains[chainID]
+	return cs, ok
+}
+
+// newHandler returns a handler which authorizes the given envelope, signed by
+// the admin identity, and nothing else
+func newHandler(support Support, env *cb.Envelope) ab.AdminServer {
+	return NewHandlerImpl(
+		support,
+		&mocks.MockIdentityDeserializer{Identity: adminIdentity, Msg: env.Payload},
+		&mocks.MockMSPPrincipalGetter{Principal: adminIdentity},
+	)
+}
+
+func makeEnvelope(creator []byte, at time.Time, data []byte) *cb.Envelope {
+	payload := utils.MarshalOrPanic(&cb.Payload{
+		Header: &cb.Header{
+			ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+				Type:      int32(cb.HeaderType_MESSAGE),
+				Timestamp: &timestamp.Timestamp{Seconds: at.Unix()},
+			}),
+			SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{Creator: creator}),
+		},
+		Data: data,
+	})
+	// The mock identities sign with the identity function
+	return &cb.Envelope{Payload: payload, Signature: payload}
+}
+
+func TestListChannels(t *testing.T) {
+	support := newMockSupport()
+	support.chains[appChainID].sequence = 3
+	close(support.chains[appChainID].errored)
+
+	env := makeEnvelope(adminIdentity, time.Now(), nil)
+	list, err := newHandler(support, env).ListChannels(context.Background(), env)
+	assert.NoError(t, err)
+	assert.Equal(t, []*ab.ChannelInfo{
+		{ChannelId: appChainID, Height: 1, ConsensusType: "solo", ConfigSequence: 3, Errored: true},
+		{ChannelId: systemChainID, Height: 1, ConsensusType: "solo", SystemChannel: true},
+	}, list.Channels)
+}
+
+func TestGetChannelConfig(t *testing.T) {
+	support := newMockSupport()
+	support.chains[appChainID].sequence = 3
+
+	env := makeEnvelope(adminIdentity, time.Now(), utils.MarshalOrPanic(&ab.ChannelConfigRequest{ChannelId: appChainID}))
+	response, err := newHandler(support, env).GetChannelConfig(context.Background(), env)
+	assert.NoError(t, err)
+	assert.Equal(t, appChainID, response.Info.ChannelId)
+	assert.Equal(t, uint64(3), response.Config.Sequence)
+
+	env = makeEnvelope(adminIdentity, time.Now(), utils.MarshalOrPanic(&ab.ChannelConfigRequest{ChannelId: "missing"}))
+	_, err = newHandler(support, env).GetChannelConfig(context.Background(), env)
+	assert.Error(t, err, "Expected an unknown channel to be reported")
+
+	env = makeEnvelope(adminIdentity, time.Now(), []byte("garbage"))
+	_, err = newHandler(support, env).GetChannelConfig(context.Background(), env)
+	assert.Error(t, err, "Expected a malformed request to be rejected")
+}
+
+func TestAuthorization(t *testing.T) {
+	support := newMockSupport()
+
+	t.Run("NotAdmin", func(t *testing.T) {
+		env := makeEnvelope([]byte("member"), time.Now(), nil)
+		_, err := newHandler(support, env).ListChannels(context.Background(), env)
+		assert.Error(t, err)
+	})
+
+	t.Run("BadSignature", func(t *testing.T) {
+		env := makeEnvelope(adminIdentity, time.Now(), nil)
+		handler := newHandler(support, env)
+		env.Signature = []byte("forged")
+		_, err := handler.ListChannels(context.Background(), env)
+		assert.Error(t, err)
+	})
+
+	t.Run("Stale", func(t *testing.T) {
+		env := makeEnvelope(adminIdentity, time.Now().Add(-time.Hour), nil)
+		_, err := newHandler(support, env).ListChannels(context.Background(), env)
+		assert.Error(t, err)
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		env := &cb.Envelope{Payload: []byte("garbage")}
+		_, err := newHandler(support, env).ListChannels(context.Background(), env)
+		assert.Error(t, err)
+
+		_, err = newHandler(support, env).ListChannels(context.Background(), nil)
+		assert.Error(t, err)
+	})
+}
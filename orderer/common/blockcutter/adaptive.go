@@ -0,0 +1,116 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blockcutter
+
+import (
+	"math"
+	"time"
+
+	"github.com/hyperledger/fabric/common/config/channel"
+)
+
+// smoothingFactor is the weight of the last interarrival time in the moving
+// average of the interarrival times
+const smoothingFactor = 0.2
+
+// adaptiveStrategy adjusts the batch size and timeout to the arrival rate of
+// the messages, estimated by an exponentially weighted moving average of their
+// interarrival times. It sizes the batches to the number of messages expected
+// within the BatchTimeout, and waits for as long as the batch is expected to
+// take to fill, both within the bounds of the BatchCutting config. When fewer
+// messages than the lower bound are expected, waiting would not fill the batch
+// any further and only delay it, so the lower bound of the timeout applies.
+type adaptiveStrategy struct {
+	sharedConfigManager config.Orderer
+
+	lastArrival  time.Time
+	meanInterval float64 // in seconds, once estimated
+	estimated    bool
+}
+
+func newAdaptiveStrategy(sharedConfigManager config.Orderer) cutStrategy {
+	return &adaptiveStrategy{sharedConfigManager: sharedConfigManager}
+}
+
+func (s *adaptiveStrategy) observe(arrival time.Time) {
+	if !s.lastArrival.IsZero() {
+		interval := math.Max(arrival.Sub(s.lastArrival).Seconds(), 0)
+		if s.estimated {
+			s.meanInterval += smoothingFactor * (interval - s.meanInterval)
+		} else {
+			s.meanInterval = interval
+			s.estimated = true
+		}
+	}
+	s.lastArrival = arrival
+}
+
+func (s *adaptiveStrategy) maxMessageCount() uint32 {
+	minCount, maxCount := s.messageCountBounds()
+	expected, ok := s.expectedMessages()
+	if !ok || expected >= float64(maxCount) {
+		return maxCount
+	}
+	return uint32(math.Max(math.Ceil(expected), float64(minCount)))
+}
+
+func (s *adaptiveStrategy) batchTimeout() time.Duration {
+	minTimeout, maxTimeout := s.timeoutBounds()
+	expected, ok := s.expectedMessages()
+	if !ok {
+		return maxTimeout
+	}
+	minCount, _ := s.messageCountBounds()
+	if expected < float64(minCount) {
+		return minTimeout
+	}
+
+	fillTime := time.Duration(float64(s.maxMessageCount()) * s.meanInterval * float64(time.Second))
+	switch {
+	case fillTime < minTimeout:
+		return minTimeout
+	case fillTime > maxTimeout:
+		return maxTimeout
+	default:
+		return fillTime
+	}
+}
+
+// expectedMessages returns the number of messages expected to arrive within
+// the BatchTimeout at the estimated arrival rate, or false until the rate is
+// estimated
+func (s *adaptiveStrategy) expectedMessages() (float64, bool) {
+	if !s.estimated {
+		return 0, false
+	}
+	if s.meanInterval == 0 {
+		return math.Inf(1), true
+	}
+	return s.sharedConfigManager.BatchTimeout().Seconds() / s.meanInterval, true
+}
+
+// messageCountBounds returns the bounds of the effective max message count, the
+// upper one being the BatchSize.MaxMessageCount
+func (s *adaptiveStrategy) messageCountBounds() (uint32, uint32) {
+	maxCount := s.sharedConfigManager.BatchSize().MaxMessageCount
+	minCount := s.sharedConfigManager.BatchCutting().GetMinMessageCount()
+	if minCount == 0 || minCount > maxCount {
+		minCount = maxCount
+	}
+	return minCount, maxCount
+}
+
+// timeoutBounds returns the bounds of the effective batch timeout, the upper
+// one being the BatchTimeout
+func (s *adaptiveStrategy) timeoutBounds() (time.Duration, time.Duration) {
+	maxTimeout := s.sharedConfigManager.BatchTimeout()
+	minTimeout, err := time.ParseDuration(s.sharedConfigManager.BatchCutting().GetMinTimeout())
+	if err != nil || minTimeout <= 0 || minTimeout > maxTimeout {
+		minTimeout = maxTimeout
+	}
+	return minTimeout, maxTimeout
+}
@@ -0,0 +1,113 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blockcutter
+
+import (
+	"testing"
+	"time"
+
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAdaptiveReceiver() (*receiver, *mockconfig.Orderer, *time.Time) {
+	support := &mockconfig.Orderer{
+		BatchSizeVal:    &ab.BatchSize{MaxMessageCount: 100, AbsoluteMaxBytes: 100000, PreferredMaxBytes: 100000},
+		BatchTimeoutVal: time.Second,
+		BatchCuttingVal: &ab.BatchCutting{Strategy: ab.BatchCutting_ADAPTIVE, MinMessageCount: 10, MinTimeout: "100ms"},
+	}
+	r := NewReceiverImpl(support).(*receiver)
+	now := time.Unix(1000, 0)
+	r.now = func() time.Time { return now }
+	return r, support, &now
+}
+
+// orderEvery orders count messages arriving at the given interval and returns
+// the batches cut
+func orderEvery(r *receiver, now *time.Time, interval time.Duration, count int) [][]*cb.Envelope {
+	var batches [][]*cb.Envelope
+	for i := 0; i < count; i++ {
+		*now = now.Add(interval)
+		messageBatches, _ := r.Ordered(tx)
+		batches = append(batches, messageBatches...)
+	}
+	return batches
+}
+
+func TestAdaptiveBatch(t *testing.T) {
+	r, _, now := newTestAdaptiveReceiver()
+
+	orderEvery(r, now, 0, 1)
+	assert.Equal(t, time.Second, r.BatchTimeout(), "Expected the upper bounds until the rate is estimated")
+	assert.Equal(t, uint32(100), r.strategy().maxMessageCount())
+
+	// A burst fills the batches up to the max message count, and waits no longer than they take to fill
+	batches := orderEvery(r, now, time.Millisecond, 249)
+	assert.Len(t, batches, 2)
+	assert.Len(t, batches[0], 100)
+	assert.Len(t, batches[1], 100)
+	assert.Equal(t, 100*time.Millisecond, r.BatchTimeout())
+	assert.Len(t, r.Cut(), 50)
+
+	// Sparse messages are cut early by the min timeout, as the batches would not fill anyway
+	orderEvery(r, now, 500*time.Millisecond, 20)
+	r.Cut()
+	assert.Equal(t, uint32(10), r.strategy().maxMessageCount())
+	assert.Equal(t, 100*time.Millisecond, r.BatchTimeout())
+
+	// Otherwise the batches are sized to the messages expected within the batch timeout
+	orderEvery(r, now, 50*time.Millisecond, 50)
+	r.Cut()
+	assert.Equal(t, uint32(20), r.strategy().maxMessageCount())
+	assert.InDelta(t, float64(time.Second), float64(r.BatchTimeout()), float64(time.Millisecond))
+	batches = orderEvery(r, now, 50*time.Millisecond, 20)
+	assert.Len(t, batches, 1)
+	assert.Len(t, batches[0], 20)
+}
+
+func TestAdaptiveBatchStrategyChange(t *testing.T) {
+	r, support, now := newTestAdaptiveReceiver()
+
+	orderEvery(r, now, 500*time.Millisecond, 20)
+	r.Cut()
+	assert.Equal(t, uint32(10), r.strategy().maxMessageCount())
+	assert.Equal(t, 100*time.Millisecond, r.BatchTimeout())
+
+	support.BatchCuttingVal = &ab.BatchCutting{}
+	assert.Equal(t, uint32(100), r.strategy().maxMessageCount(), "Expected the fixed strategy to use the batch size")
+	assert.Equal(t, time.Second, r.BatchTimeout(), "Expected the fixed strategy to use the batch timeout")
+
+	support.BatchCuttingVal = &ab.BatchCutting{Strategy: ab.BatchCutting_ADAPTIVE, MinMessageCount: 10, MinTimeout: "100ms"}
+	assert.Equal(t, time.Second, r.BatchTimeout(), "Expected the arrival rate to be estimated anew")
+}
+
+func TestAdaptiveBatchOrderedAt(t *testing.T) {
+	r, _, now := newTestAdaptiveReceiver()
+
+	// The arrival times given are observed, rather than the local time
+	arrival := time.Unix(2000, 0)
+	for i := 0; i < 20; i++ {
+		arrival = arrival.Add(500 * time.Millisecond)
+		*now = now.Add(time.Millisecond)
+		r.OrderedAt(tx, arrival)
+	}
+	r.Cut()
+	assert.Equal(t, uint32(10), r.strategy().maxMessageCount())
+	assert.Equal(t, 100*time.Millisecond, r.BatchTimeout())
+
+	// Without arrival times, as with Kafka versions older than 0.10, the rate is never
+	// estimated and the upper bounds apply
+	r, _, _ = newTestAdaptiveReceiver()
+	for i := 0; i < 20; i++ {
+		r.OrderedAt(tx, time.Time{})
+	}
+	assert.Equal(t, uint32(100), r.strategy().maxMessageCount())
+	assert.Equal(t, time.Second, r.BatchTimeout())
+}
@@ -17,8 +17,11 @@ limitations under the License.
 package blockcutter
 
 import (
+	"time"
+
 	"github.com/hyperledger/fabric/common/config/channel"
 	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
 
 	"github.com/op/go-logging"
 )
@@ -33,21 +36,74 @@ type Receiver interface {
 	// is useful for Kafka orderer to determine the `LastOffsetPersisted` of block.
 	Ordered(msg *cb.Envelope) (messageBatches [][]*cb.Envelope, pending bool)
 
+	// OrderedAt is Ordered for a message which arrived at the given time rather
+	// than now. It lets consenters whose orderers cut blocks independently, like
+	// Kafka, observe the same arrival times on every orderer.
+	OrderedAt(msg *cb.Envelope, arrival time.Time) (messageBatches [][]*cb.Envelope, pending bool)
+
 	// Cut returns the current batch and starts a new one
 	Cut() []*cb.Envelope
+
+	// BatchTimeout returns how long the consenter should wait before cutting the
+	// pending batch
+	BatchTimeout() time.Duration
+}
+
+// cutStrategy decides the effective limits of the batches cut by the receiver
+type cutStrategy interface {
+	// observe is invoked with the arrival time of each message ordered
+	observe(arrival time.Time)
+
+	// maxMessageCount returns the message count at which the pending batch is cut
+	maxMessageCount() uint32
+
+	// batchTimeout returns how long the pending batch waits before it is cut
+	batchTimeout() time.Duration
+}
+
+// strategies holds the constructor of the cutting strategy of each value of the
+// BatchCutting strategy of the channel config
+var strategies = map[ab.BatchCutting_Strategy]func(sharedConfigManager config.Orderer) cutStrategy{
+	ab.BatchCutting_FIXED:    newFixedStrategy,
+	ab.BatchCutting_ADAPTIVE: newAdaptiveStrategy,
 }
 
 type receiver struct {
 	sharedConfigManager   config.Orderer
 	pendingBatch          []*cb.Envelope
 	pendingBatchSizeBytes uint32
+
+	strategyType ab.BatchCutting_Strategy
+	cutStrategy  cutStrategy
+	now          func() time.Time
 }
 
-// NewReceiverImpl creates a Receiver implementation based on the given configtxorderer manager
+// NewReceiverImpl creates a Receiver implementation based on the given configtxorderer manager.
+// It cuts the batches with the strategy selected by the BatchCutting of the current config.
 func NewReceiverImpl(sharedConfigManager config.Orderer) Receiver {
 	return &receiver{
 		sharedConfigManager: sharedConfigManager,
+		now:                 time.Now,
+	}
+}
+
+// strategy returns the cutting strategy selected by the current config, creating
+// a new one whenever the selection changes
+func (r *receiver) strategy() cutStrategy {
+	strategyType := r.sharedConfigManager.BatchCutting().GetStrategy()
+	if r.cutStrategy != nil && strategyType == r.strategyType {
+		return r.cutStrategy
+	}
+
+	newStrategy, ok := strategies[strategyType]
+	if !ok {
+		logger.Warningf("Unknown batch cutting strategy %s, falling back to %s", strategyType, ab.BatchCutting_FIXED)
+		newStrategy = newFixedStrategy
 	}
+	logger.Debugf("Cutting batches with the %s strategy", strategyType)
+	r.strategyType = strategyType
+	r.cutStrategy = newStrategy(r.sharedConfigManager)
+	return r.cutStrategy
 }
 
 // Ordered should be invoked sequentially as messages are ordered
@@ -57,7 +113,7 @@ func NewReceiverImpl(sharedConfigManager config.Orderer) Receiver {
 // messageBatches length: 0, pending: true
 //   - no batch is cut and there are messages pending
 // messageBatches length: 1, pending: false
-//   - the message count reaches BatchSize.MaxMessageCount
+//   - the message count reaches the max message count of the strategy, BatchSize.MaxMessageCount by default
 // messageBatches length: 1, pending: true
 //   - the current message will cause the pending batch size in bytes to exceed BatchSize.PreferredMaxBytes.
 // messageBatches length: 2, pending: false
@@ -67,6 +123,15 @@ func NewReceiverImpl(sharedConfigManager config.Orderer) Receiver {
 //
 // Note that messageBatches can not be greater than 2.
 func (r *receiver) Ordered(msg *cb.Envelope) (messageBatches [][]*cb.Envelope, pending bool) {
+	return r.OrderedAt(msg, r.now())
+}
+
+// OrderedAt should be invoked sequentially as messages are ordered, with the
+// time at which each message arrived
+func (r *receiver) OrderedAt(msg *cb.Envelope, arrival time.Time) (messageBatches [][]*cb.Envelope, pending bool) {
+	strategy := r.strategy()
+	strategy.observe(arrival)
+
 	messageSizeBytes := messageSizeBytes(msg)
 	if messageSizeBytes > r.sharedConfigManager.BatchSize().PreferredMaxBytes {
 		logger.Debugf("The current message, with %v bytes, is larger than the preferred batch size of %v bytes and will be isolated.", messageSizeBytes, r.sharedConfigManager.BatchSize().PreferredMaxBytes)
@@ -97,7 +162,7 @@ func (r *receiver) Ordered(msg *cb.Envelope) (messageBatches [][]*cb.Envelope, p
 	r.pendingBatchSizeBytes += messageSizeBytes
 	pending = true
 
-	if uint32(len(r.pendingBatch)) >= r.sharedConfigManager.BatchSize().MaxMessageCount {
+	if uint32(len(r.pendingBatch)) >= strategy.maxMessageCount() {
 		logger.Debugf("Batch size met, cutting batch")
 		messageBatch := r.Cut()
 		messageBatches = append(messageBatches, messageBatch)
@@ -115,6 +180,31 @@ func (r *receiver) Cut() []*cb.Envelope {
 	return batch
 }
 
+// BatchTimeout returns how long the consenter should wait before cutting the
+// pending batch, as decided by the strategy
+func (r *receiver) BatchTimeout() time.Duration {
+	return r.strategy().batchTimeout()
+}
+
 func messageSizeBytes(message *cb.Envelope) uint32 {
 	return uint32(len(message.Payload) + len(message.Signature))
 }
+
+// fixedStrategy cuts the batches on the BatchSize and BatchTimeout of the channel
+type fixedStrategy struct {
+	sharedConfigManager config.Orderer
+}
+
+func newFixedStrategy(sharedConfigManager config.Orderer) cutStrategy {
+	return &fixedStrategy{sharedConfigManager: sharedConfigManager}
+}
+
+func (s *fixedStrategy) observe(arrival time.Time) {}
+
+func (s *fixedStrategy) maxMessageCount() uint32 {
+	return s.sharedConfigManager.BatchSize().MaxMessageCount
+}
+
+func (s *fixedStrategy) batchTimeout() time.Duration {
+	return s.sharedConfigManager.BatchTimeout()
+}
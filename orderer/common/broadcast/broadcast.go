@@ -10,6 +10,7 @@ import (
 	"io"
 
 	"github.com/hyperledger/fabric/orderer/common/msgprocessor"
+	"github.com/hyperledger/fabric/orderer/consensus"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 
@@ -92,8 +93,22 @@ func (bh *handlerImpl) Handle(srv ab.AtomicBroadcast_BroadcastServer) error {
 				return srv.Send(&ab.BroadcastResponse{Status: ClassifyError(err), Info: err.Error()})
 			}
 
+			// The creator is charged once the message is known to be well signed
+			err = processor.ChargeRateLimit(msg)
+			if err != nil {
+				logger.Warningf("[channel: %s] Rejecting broadcast of normal message from %s because of error: %s", chdr.ChannelId, addr, err)
+				return srv.Send(&ab.BroadcastResponse{Status: ClassifyError(err), Info: err.Error()})
+			}
+
+			err = processor.ClaimTxID(chdr.TxId)
+			if err != nil {
+				logger.Warningf("[channel: %s] Rejecting broadcast of normal message from %s because of error: %s", chdr.ChannelId, addr, err)
+				return srv.Send(&ab.BroadcastResponse{Status: ClassifyError(err), Info: err.Error()})
+			}
+
 			err = processor.Order(msg, configSeq)
 			if err != nil {
+				processor.ReleaseTxID(chdr.TxId)
 				logger.Warningf("[channel: %s] Rejecting broadcast of normal message from %s with SERVICE_UNAVAILABLE: rejected by Order: %s", chdr.ChannelId, addr, err)
 				return srv.Send(&ab.BroadcastResponse{Status: cb.Status_SERVICE_UNAVAILABLE, Info: err.Error()})
 			}
@@ -106,6 +121,18 @@ func (bh *handlerImpl) Handle(srv ab.AtomicBroadcast_BroadcastServer) error {
 				return srv.Send(&ab.BroadcastResponse{Status: ClassifyError(err), Info: err.Error()})
 			}
 
+			err = processor.ChargeRateLimit(msg)
+			if err != nil {
+				logger.Warningf("[channel: %s] Rejecting broadcast of config message from %s because of error: %s", chdr.ChannelId, addr, err)
+				return srv.Send(&ab.BroadcastResponse{Status: ClassifyError(err), Info: err.Error()})
+			}
+
+			err = processor.ValidateMigration(config)
+			if err != nil {
+				logger.Warningf("[channel: %s] Rejecting broadcast of config message from %s because of error: %s", chdr.ChannelId, addr, err)
+				return srv.Send(&ab.BroadcastResponse{Status: ClassifyError(err), Info: err.Error()})
+			}
+
 			err = processor.Configure(msg, config, configSeq)
 			if err != nil {
 				logger.Warningf("[channel: %s] Rejecting broadcast of config message from %s with SERVICE_UNAVAILABLE: rejected by Configure: %s", chdr.ChannelId, addr, err)
@@ -132,6 +159,10 @@ func ClassifyError(err error) cb.Status {
 		return cb.Status_NOT_FOUND
 	case msgprocessor.ErrPermissionDenied:
 		return cb.Status_FORBIDDEN
+	case msgprocessor.ErrDuplicateTxID:
+		return cb.Status_CONFLICT
+	case consensus.ErrServiceUnavailable, msgprocessor.ErrRateLimited, msgprocessor.ErrMaintenance:
+		return cb.Status_SERVICE_UNAVAILABLE
 	default:
 		return cb.Status_BAD_REQUEST
 	}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/hyperledger/fabric/orderer/common/msgprocessor"
+	"github.com/hyperledger/fabric/orderer/consensus"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	logging "github.com/op/go-logging"
@@ -108,7 +109,12 @@ type mockSupport struct {
 	ProcessConfigEnv *cb.Envelope
 	ProcessConfigSeq uint64
 	ProcessErr       error
+	ClaimTxIDErr     error
+	RateLimitErr     error
+	MigrationErr     error
+	charged          int
 	rejectEnqueue    bool
+	released         int
 }
 
 // Order sends a message for ordering
@@ -132,6 +138,23 @@ func (ms *mockSupport) ProcessNormalMsg(msg *cb.Envelope) (uint64, error) {
 	return ms.ProcessConfigSeq, ms.ProcessErr
 }
 
+func (ms *mockSupport) ClaimTxID(txID string) error {
+	return ms.ClaimTxIDErr
+}
+
+func (ms *mockSupport) ReleaseTxID(txID string) {
+	ms.released++
+}
+
+func (ms *mockSupport) ChargeRateLimit(msg *cb.Envelope) error {
+	ms.charged++
+	return ms.RateLimitErr
+}
+
+func (ms *mockSupport) ValidateMigration(config *cb.Envelope) error {
+	return ms.MigrationErr
+}
+
 func (ms *mockSupport) ProcessConfigUpdateMsg(msg *cb.Envelope) (*cb.Envelope, uint64, error) {
 	return ms.ProcessConfigEnv, ms.ProcessConfigSeq, ms.ProcessErr
 }
@@ -167,6 +190,7 @@ func TestEnqueueFailure(t *testing.T) {
 	if reply.Status != cb.Status_SERVICE_UNAVAILABLE {
 		t.Fatalf("Should not have successfully queued the message")
 	}
+	assert.Equal(t, 1, mm.MsgProcessorVal.released, "Should have released the TxID of the message not queued")
 
 	select {
 	case <-done:
@@ -182,6 +206,18 @@ func TestClassifyError(t *testing.T) {
 	t.Run("Forbidden", func(t *testing.T) {
 		assert.Equal(t, cb.Status_FORBIDDEN, ClassifyError(msgprocessor.ErrPermissionDenied))
 	})
+	t.Run("ServiceUnavailable", func(t *testing.T) {
+		assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, ClassifyError(errors.Wrap(consensus.ErrServiceUnavailable, "too many messages in flight")))
+	})
+	t.Run("Conflict", func(t *testing.T) {
+		assert.Equal(t, cb.Status_CONFLICT, ClassifyError(errors.Wrap(msgprocessor.ErrDuplicateTxID, "txid 'foo' was already submitted")))
+	})
+	t.Run("RateLimited", func(t *testing.T) {
+		assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, ClassifyError(errors.Wrap(msgprocessor.ErrRateLimited, "creator of MSP SampleOrg exceeded the limit")))
+	})
+	t.Run("Maintenance", func(t *testing.T) {
+		assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, ClassifyError(errors.Wrap(msgprocessor.ErrMaintenance, "message of type ENDORSER_TRANSACTION rejected")))
+	})
 	t.Run("WrappedErr", func(t *testing.T) {
 		assert.Equal(t, cb.Status_NOT_FOUND, ClassifyError(errors.Wrap(msgprocessor.ErrChannelDoesNotExist, "A wrapped error")))
 	})
@@ -215,6 +251,41 @@ func TestBadChannelId(t *testing.T) {
 	}
 }
 
+func TestDuplicateTxID(t *testing.T) {
+	mm := getMockSupportManager()
+	mm.MsgProcessorVal.ClaimTxIDErr = msgprocessor.ErrDuplicateTxID
+	bh := NewHandlerImpl(mm)
+	m := newMockB()
+	defer close(m.recvChan)
+	go bh.Handle(m)
+
+	m.recvChan <- nil
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_CONFLICT, reply.Status, "Should have rejected a duplicate TxID")
+	assert.Equal(t, 0, mm.MsgProcessorVal.released, "Should not have released a TxID it did not claim")
+}
+
+func TestRateLimited(t *testing.T) {
+	for _, isConfig := range []bool{false, true} {
+		mm := getMockSupportManager()
+		mm.MsgProcessorIsConfig = isConfig
+		bh := NewHandlerImpl(mm)
+		m := newMockB()
+		go bh.Handle(m)
+
+		m.recvChan <- nil
+		reply := <-m.sendChan
+		assert.Equal(t, cb.Status_SUCCESS, reply.Status)
+
+		mm.MsgProcessorVal.RateLimitErr = errors.Wrap(msgprocessor.ErrRateLimited, "creator of MSP SampleOrg exceeded the limit")
+		m.recvChan <- nil
+		reply = <-m.sendChan
+		assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, reply.Status, "Should have rejected a rate limited message")
+		assert.Equal(t, 2, mm.MsgProcessorVal.charged, "Should have charged each message once")
+		close(m.recvChan)
+	}
+}
+
 func TestGoodConfigUpdate(t *testing.T) {
 	mm := getMockSupportManager()
 	mm.MsgProcessorIsConfig = true
@@ -242,6 +313,20 @@ func TestBadConfigUpdate(t *testing.T) {
 	assert.NotEqual(t, cb.Status_SUCCESS, reply.Status, "Should have rejected CONFIG_UPDATE")
 }
 
+func TestRejectedMigration(t *testing.T) {
+	mm := getMockSupportManager()
+	mm.MsgProcessorIsConfig = true
+	mm.MsgProcessorVal.MigrationErr = fmt.Errorf("cannot migrate to consensus type kafka")
+	bh := NewHandlerImpl(mm)
+	m := newMockB()
+	defer close(m.recvChan)
+	go bh.Handle(m)
+
+	m.recvChan <- nil
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_BAD_REQUEST, reply.Status, "Should have rejected a CONFIG_UPDATE whose migration is not valid")
+}
+
 func TestGracefulShutdown(t *testing.T) {
 	bh := NewHandlerImpl(nil)
 	m := newMockB()
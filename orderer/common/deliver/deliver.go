@@ -17,6 +17,7 @@ limitations under the License.
 package deliver
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/hyperledger/fabric/common/policies"
@@ -148,6 +149,16 @@ func (ds *deliverServer) deliverBlocks(srv ab.AtomicBroadcast_DeliverServer, env
 		return sendStatusReply(srv, cb.Status_BAD_REQUEST)
 	}
 
+	if !validTimestamp(seekInfo.Start) || !validTimestamp(seekInfo.Stop) {
+		logger.Warningf("[channel: %s] Received seekInfo message from %s with a timestamp position missing its timestamp", chdr.ChannelId, addr)
+		return sendStatusReply(srv, cb.Status_BAD_REQUEST)
+	}
+
+	if _, ok := ab.SeekInfo_SeekContentType_name[int32(seekInfo.ContentType)]; !ok {
+		logger.Warningf("[channel: %s] Received seekInfo message from %s with unknown content type %d", chdr.ChannelId, addr, seekInfo.ContentType)
+		return sendStatusReply(srv, cb.Status_BAD_REQUEST)
+	}
+
 	logger.Debugf("[channel: %s] Received seekInfo (%p) %v from %s", chdr.ChannelId, seekInfo, seekInfo, addr)
 
 	cursor, number := chain.Reader().Iterator(seekInfo.Start)
@@ -164,6 +175,16 @@ func (ds *deliverServer) deliverBlocks(srv ab.AtomicBroadcast_DeliverServer, env
 			logger.Warningf("[channel: %s] Received invalid seekInfo message from %s: start number %d greater than stop number %d", chdr.ChannelId, addr, number, stopNum)
 			return sendStatusReply(srv, cb.Status_BAD_REQUEST)
 		}
+	case *ab.SeekPosition_Timestamp:
+		// Stop at the first block at or after the stop time, as a start
+		// position with the same timestamp would
+		stopCursor, stopTimestampNum := chain.Reader().Iterator(seekInfo.Stop)
+		stopCursor.Close()
+		stopNum = stopTimestampNum
+		if stopNum < number {
+			logger.Warningf("[channel: %s] Received invalid seekInfo message from %s: start number %d greater than stop number %d", chdr.ChannelId, addr, number, stopNum)
+			return sendStatusReply(srv, cb.Status_BAD_REQUEST)
+		}
 	}
 
 	for {
@@ -192,6 +213,10 @@ func (ds *deliverServer) deliverBlocks(srv ab.AtomicBroadcast_DeliverServer, env
 		}
 
 		block, status := cursor.Next()
+		if status == cb.Status_NOT_FOUND {
+			logger.Warningf("[channel: %s] Block requested by %s is not available, it may have been pruned", chdr.ChannelId, addr)
+			return sendStatusReply(srv, status)
+		}
 		if status != cb.Status_SUCCESS {
 			logger.Errorf("[channel: %s] Error reading from channel, cause was: %v", chdr.ChannelId, status)
 			return sendStatusReply(srv, status)
@@ -199,7 +224,17 @@ func (ds *deliverServer) deliverBlocks(srv ab.AtomicBroadcast_DeliverServer, env
 
 		logger.Debugf("[channel: %s] Delivering block for (%p) for %s", chdr.ChannelId, seekInfo, addr)
 
-		if err := sendBlockReply(srv, block); err != nil {
+		if seekInfo.ContentType == ab.SeekInfo_BLOCK {
+			err = sendBlockReply(srv, block)
+		} else {
+			filteredBlock, ferr := filterBlock(block, seekInfo.ContentType)
+			if ferr != nil {
+				logger.Errorf("[channel: %s] Error filtering block %d: %s", chdr.ChannelId, block.Header.Number, ferr)
+				return sendStatusReply(srv, cb.Status_INTERNAL_SERVER_ERROR)
+			}
+			err = sendFilteredBlockReply(srv, filteredBlock)
+		}
+		if err != nil {
 			logger.Warningf("[channel: %s] Error sending to %s: %s", chdr.ChannelId, addr, err)
 			return err
 		}
@@ -232,3 +267,56 @@ func sendBlockReply(srv ab.AtomicBroadcast_DeliverServer, block *cb.Block) error
 		Type: &ab.DeliverResponse_Block{Block: block},
 	})
 }
+
+// validTimestamp returns false for timestamp positions without a timestamp
+func validTimestamp(position *ab.SeekPosition) bool {
+	if ts, ok := position.Type.(*ab.SeekPosition_Timestamp); ok {
+		return ts.Timestamp.GetTimestamp() != nil
+	}
+	return true
+}
+
+func sendFilteredBlockReply(srv ab.AtomicBroadcast_DeliverServer, filteredBlock *ab.FilteredBlock) error {
+	return srv.Send(&ab.DeliverResponse{
+		Type: &ab.DeliverResponse_FilteredBlock{FilteredBlock: filteredBlock},
+	})
+}
+
+// filterBlock strips a block down to what is delivered for the given content
+// type: its header and signatures, and for FILTERED_BLOCK the ID, type and
+// channel of each of its transactions.
+func filterBlock(block *cb.Block, contentType ab.SeekInfo_SeekContentType) (*ab.FilteredBlock, error) {
+	if block.Header == nil || block.Data == nil || block.Metadata == nil {
+		return nil, fmt.Errorf("block is missing its header, data or metadata")
+	}
+
+	signatures, err := utils.GetMetadataFromBlock(block, cb.BlockMetadataIndex_SIGNATURES)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal signatures metadata: %s", err)
+	}
+
+	filteredBlock := &ab.FilteredBlock{
+		Header:     block.Header,
+		Signatures: signatures,
+	}
+	if contentType == ab.SeekInfo_HEADER_WITH_SIG {
+		return filteredBlock, nil
+	}
+
+	for i, data := range block.Data.Data {
+		env, err := utils.GetEnvelopeFromBlock(data)
+		if err != nil {
+			return nil, fmt.Errorf("cannot unmarshal transaction %d: %s", i, err)
+		}
+		chdr, err := utils.ChannelHeader(env)
+		if err != nil {
+			return nil, fmt.Errorf("cannot extract channel header of transaction %d: %s", i, err)
+		}
+		filteredBlock.FilteredTransactions = append(filteredBlock.FilteredTransactions, &ab.FilteredTransaction{
+			Txid:      chdr.TxId,
+			Type:      cb.HeaderType(chdr.Type),
+			ChannelId: chdr.ChannelId,
+		})
+	}
+	return filteredBlock, nil
+}
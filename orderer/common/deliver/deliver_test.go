@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/ptypes/timestamp"
 	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/common/tools/configtxgen/provisional"
@@ -682,3 +683,254 @@ func TestMissingSeekPosition(t *testing.T) {
 		t.Fatalf("Timed out waiting to get all blocks")
 	}
 }
+
+func TestFilteredBlockSeek(t *testing.T) {
+	mm := newMockMultichainManager()
+	l := mm.chains[systemChainID].ledger
+	for i := 1; i < ledgerSize; i++ {
+		env := &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+				Type:      int32(cb.HeaderType_ENDORSER_TRANSACTION),
+				ChannelId: systemChainID,
+				TxId:      fmt.Sprintf("tx%d", i),
+			})},
+			Data: []byte(fmt.Sprintf("%d", i)),
+		})}
+		block := ledger.CreateNextBlock(l, []*cb.Envelope{env})
+		block.Metadata.Metadata[cb.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(&cb.Metadata{
+			Signatures: []*cb.MetadataSignature{{Signature: []byte(fmt.Sprintf("signature%d", i))}},
+		})
+		l.Append(block)
+	}
+
+	ds := NewHandlerImpl(mm)
+	seek := func(t *testing.T, contentType ab.SeekInfo_SeekContentType) []*ab.FilteredBlock {
+		m := newMockD()
+		defer close(m.recvChan)
+		go ds.Handle(m)
+
+		m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekSpecified(1), Stop: seekNewest, Behavior: ab.SeekInfo_BLOCK_UNTIL_READY, ContentType: contentType})
+
+		var filteredBlocks []*ab.FilteredBlock
+		for {
+			select {
+			case deliverReply := <-m.sendChan:
+				assert.Nil(t, deliverReply.GetBlock(), "Expected no full block to be delivered")
+				if deliverReply.GetFilteredBlock() == nil {
+					assert.Equal(t, cb.Status_SUCCESS, deliverReply.GetStatus())
+					return filteredBlocks
+				}
+				filteredBlocks = append(filteredBlocks, deliverReply.GetFilteredBlock())
+			case <-time.After(time.Second):
+				t.Fatalf("Timed out waiting to get all blocks")
+			}
+		}
+	}
+
+	t.Run("Filtered", func(t *testing.T) {
+		filteredBlocks := seek(t, ab.SeekInfo_FILTERED_BLOCK)
+		assert.Len(t, filteredBlocks, ledgerSize-1)
+		for i, filteredBlock := range filteredBlocks {
+			number := uint64(i + 1)
+			assert.Equal(t, number, filteredBlock.Header.Number)
+			assert.Equal(t, []byte(fmt.Sprintf("signature%d", number)), filteredBlock.Signatures.Signatures[0].Signature)
+			assert.Equal(t, []*ab.FilteredTransaction{{
+				Txid:      fmt.Sprintf("tx%d", number),
+				Type:      cb.HeaderType_ENDORSER_TRANSACTION,
+				ChannelId: systemChainID,
+			}}, filteredBlock.FilteredTransactions)
+		}
+	})
+
+	t.Run("HeaderWithSig", func(t *testing.T) {
+		filteredBlocks := seek(t, ab.SeekInfo_HEADER_WITH_SIG)
+		assert.Len(t, filteredBlocks, ledgerSize-1)
+		for i, filteredBlock := range filteredBlocks {
+			number := uint64(i + 1)
+			assert.Equal(t, number, filteredBlock.Header.Number)
+			assert.Equal(t, []byte(fmt.Sprintf("signature%d", number)), filteredBlock.Signatures.Signatures[0].Signature)
+			assert.Empty(t, filteredBlock.FilteredTransactions)
+		}
+	})
+}
+
+func TestFilteredBlockSeekMalformedTransaction(t *testing.T) {
+	m := newMockD()
+	defer close(m.recvChan)
+
+	ds := initializeDeliverHandler()
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekSpecified(1), Stop: seekSpecified(1), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY, ContentType: ab.SeekInfo_FILTERED_BLOCK})
+
+	select {
+	case deliverReply := <-m.sendChan:
+		assert.Equal(t, cb.Status_INTERNAL_SERVER_ERROR, deliverReply.GetStatus(), "Expected the transaction without a channel header to fail the filtering")
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting to get all blocks")
+	}
+}
+
+func TestUnknownContentTypeSeek(t *testing.T) {
+	m := newMockD()
+	defer close(m.recvChan)
+
+	ds := initializeDeliverHandler()
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekOldest, Stop: seekNewest, Behavior: ab.SeekInfo_BLOCK_UNTIL_READY, ContentType: ab.SeekInfo_SeekContentType(42)})
+
+	select {
+	case deliverReply := <-m.sendChan:
+		assert.Equal(t, cb.Status_BAD_REQUEST, deliverReply.GetStatus(), "Received wrong error on the reply channel")
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting to get all blocks")
+	}
+}
+
+func TestRevokedAuthorizationFilteredSeek(t *testing.T) {
+	mm := newMockMultichainManager()
+	l := mm.chains[systemChainID].ledger
+
+	m := newMockD()
+	defer close(m.recvChan)
+	ds := NewHandlerImpl(mm)
+
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekSpecified(0), Stop: seekSpecified(1), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY, ContentType: ab.SeekInfo_HEADER_WITH_SIG})
+
+	select {
+	case deliverReply := <-m.sendChan:
+		assert.NotNil(t, deliverReply.GetFilteredBlock(), "First should succeed")
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting to get all blocks")
+	}
+
+	mm.chains[systemChainID].policyManager.Policy.Err = fmt.Errorf("Fail to evaluate policy")
+	mm.chains[systemChainID].configSeq++
+	l.Append(ledger.CreateNextBlock(l, []*cb.Envelope{&cb.Envelope{Payload: []byte("1")}}))
+
+	select {
+	case deliverReply := <-m.sendChan:
+		assert.Equal(t, cb.Status_FORBIDDEN, deliverReply.GetStatus(), "Second should been forbidden ")
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting to get all blocks")
+	}
+}
+
+func TestTimestampSeek(t *testing.T) {
+	mm := newMockMultichainManager()
+	l := mm.chains[systemChainID].ledger
+	base := time.Now().Add(time.Hour)
+	at := func(minutes int) *timestamp.Timestamp {
+		ts := base.Add(time.Duration(minutes) * time.Minute)
+		return &timestamp.Timestamp{Seconds: ts.Unix(), Nanos: int32(ts.Nanosecond())}
+	}
+	for i := 1; i < ledgerSize; i++ {
+		l.Append(ledger.CreateNextBlock(l, []*cb.Envelope{{Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{Timestamp: at(i)})},
+		})}}))
+	}
+	seekTimestamp := func(minutes int) *ab.SeekPosition {
+		return &ab.SeekPosition{Type: &ab.SeekPosition_Timestamp{Timestamp: &ab.SeekTimestamp{Timestamp: at(minutes)}}}
+	}
+
+	m := newMockD()
+	defer close(m.recvChan)
+	ds := NewHandlerImpl(mm)
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekTimestamp(3), Stop: seekTimestamp(6), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})
+
+	expected := uint64(3)
+	for {
+		select {
+		case deliverReply := <-m.sendChan:
+			if deliverReply.GetBlock() == nil {
+				assert.Equal(t, cb.Status_SUCCESS, deliverReply.GetStatus())
+				assert.Equal(t, uint64(7), expected, "Expected blocks 3 to 6 to be delivered")
+				return
+			}
+			assert.Equal(t, expected, deliverReply.GetBlock().Header.Number)
+			expected++
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting to get all blocks")
+		}
+	}
+}
+
+func TestMissingTimestampSeek(t *testing.T) {
+	m := newMockD()
+	defer close(m.recvChan)
+
+	ds := initializeDeliverHandler()
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: &ab.SeekPosition{Type: &ab.SeekPosition_Timestamp{Timestamp: &ab.SeekTimestamp{}}}, Stop: seekNewest, Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})
+
+	select {
+	case deliverReply := <-m.sendChan:
+		assert.Equal(t, cb.Status_BAD_REQUEST, deliverReply.GetStatus(), "Received wrong error on the reply channel")
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting to get all blocks")
+	}
+}
+
+// prunedReader serves its first block, which was retained by pruning, and
+// reports the following blocks as not found
+type prunedReader struct {
+	ledger.Reader
+}
+
+func (pr *prunedReader) Iterator(startType *ab.SeekPosition) (ledger.Iterator, uint64) {
+	return &prunedIterator{}, 0
+}
+
+type prunedIterator struct {
+	served bool
+}
+
+func (pi *prunedIterator) Next() (*cb.Block, cb.Status) {
+	if pi.served {
+		return nil, cb.Status_NOT_FOUND
+	}
+	pi.served = true
+	return genesisBlock, cb.Status_SUCCESS
+}
+
+func (pi *prunedIterator) ReadyChan() <-chan struct{} {
+	ready := make(chan struct{})
+	close(ready)
+	return ready
+}
+
+func (pi *prunedIterator) Close() {}
+
+func TestPrunedRangeSeek(t *testing.T) {
+	m := newMockD()
+	defer close(m.recvChan)
+
+	mm := newMockMultichainManager()
+	mm.chains[systemChainID].ledger = struct {
+		ledger.Reader
+		ledger.Writer
+	}{&prunedReader{Reader: mm.chains[systemChainID].ledger}, mm.chains[systemChainID].ledger}
+	ds := NewHandlerImpl(mm)
+	go ds.Handle(m)
+
+	m.recvChan <- makeSeek(systemChainID, &ab.SeekInfo{Start: seekSpecified(0), Stop: seekSpecified(5), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY})
+
+	for _, expectBlock := range []bool{true, false} {
+		select {
+		case deliverReply := <-m.sendChan:
+			if expectBlock {
+				assert.NotNil(t, deliverReply.GetBlock(), "Expected the retained block")
+			} else {
+				assert.Equal(t, cb.Status_NOT_FOUND, deliverReply.GetStatus(), "Expected the pruned range not to be found")
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for the reply")
+		}
+	}
+}
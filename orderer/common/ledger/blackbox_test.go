@@ -20,10 +20,13 @@ import (
 	"bytes"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/ptypes/timestamp"
 	. "github.com/hyperledger/fabric/orderer/common/ledger"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
 )
 
 type ledgerTestable interface {
@@ -203,6 +206,67 @@ func testBlockedRetrieval(lf ledgerTestFactory, t *testing.T) {
 	}
 }
 
+func timestampedEnvelope(ts time.Time) *cb.Envelope {
+	return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+			Timestamp: &timestamp.Timestamp{Seconds: ts.Unix(), Nanos: int32(ts.Nanosecond())},
+		})},
+	})}
+}
+
+func seekTimestamp(ts time.Time) *ab.SeekPosition {
+	return &ab.SeekPosition{Type: &ab.SeekPosition_Timestamp{Timestamp: &ab.SeekTimestamp{
+		Timestamp: &timestamp.Timestamp{Seconds: ts.Unix(), Nanos: int32(ts.Nanosecond())},
+	}}}
+}
+
+func TestTimestampRetrieval(t *testing.T) {
+	allTest(t, testTimestampRetrieval)
+}
+
+func testTimestampRetrieval(lf ledgerTestFactory, t *testing.T) {
+	_, li := lf.New()
+	base := time.Now().Add(time.Hour)
+	for i := 1; i <= 3; i++ {
+		li.Append(CreateNextBlock(li, []*cb.Envelope{
+			timestampedEnvelope(base.Add(time.Duration(i) * time.Minute)),
+			timestampedEnvelope(base.Add(time.Duration(i)*time.Minute - time.Second)),
+		}))
+	}
+
+	for _, tc := range []struct {
+		ts       time.Time
+		expected uint64
+	}{
+		{base, 1},
+		{base.Add(time.Minute), 1},
+		{base.Add(time.Minute + time.Nanosecond), 2},
+		{base.Add(3 * time.Minute), 3},
+		{base.Add(time.Hour), 4},
+	} {
+		it, num := li.Iterator(seekTimestamp(tc.ts))
+		if num != tc.expected {
+			t.Fatalf("Expected block iterator at %d for %s, but got %d", tc.expected, tc.ts, num)
+		}
+		if num < li.Height() {
+			block, status := it.Next()
+			if status != cb.Status_SUCCESS {
+				t.Fatalf("Expected to successfully read block %d", num)
+			}
+			if block.Header.Number != num {
+				t.Fatalf("Expected to retrieve block %d, but got %d", num, block.Header.Number)
+			}
+		}
+		it.Close()
+	}
+
+	it, num := li.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Timestamp{Timestamp: &ab.SeekTimestamp{}}})
+	defer it.Close()
+	if _, status := it.Next(); status != cb.Status_NOT_FOUND || num != 0 {
+		t.Fatalf("Expected a missing timestamp not to be found")
+	}
+}
+
 func TestMultichain(t *testing.T) {
 	allTest(t, testMultichain)
 }
@@ -258,3 +322,104 @@ func testMultichain(lf ledgerTestFactory, t *testing.T) {
 		t.Fatalf("Did not properly store block 1 on chain 1")
 	}
 }
+
+func TestRemove(t *testing.T) {
+	allTest(t, testRemove)
+}
+
+func testRemove(lf ledgerTestFactory, t *testing.T) {
+	f, _ := lf.New()
+	c1, err := f.GetOrCreate("chain1")
+	if err != nil {
+		t.Fatalf("Error creating chain1: %s", err)
+	}
+	c1.Append(CreateNextBlock(c1, []*cb.Envelope{&cb.Envelope{Payload: []byte("My Data")}}))
+	numChains := len(f.ChainIDs())
+
+	if err := f.Remove("chain1"); err != nil {
+		t.Fatalf("Error removing chain1: %s", err)
+	}
+	for _, chainID := range f.ChainIDs() {
+		if chainID == "chain1" {
+			t.Fatalf("Removed chain should no longer be listed")
+		}
+	}
+	if len(f.ChainIDs()) != numChains-1 {
+		t.Fatalf("Only the removed chain should no longer be listed")
+	}
+
+	c1, err = f.GetOrCreate("chain1")
+	if err != nil {
+		t.Fatalf("Error recreating chain1: %s", err)
+	}
+	if c1.Height() != 0 {
+		t.Fatalf("Recreated chain should be empty, but had height %d", c1.Height())
+	}
+}
+
+func TestPruneBoundary(t *testing.T) {
+	start := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	blocks := make([]*cb.Block, 10)
+	for i := range blocks {
+		// One block per day
+		blocks[i] = cb.NewBlock(uint64(i), nil)
+		blocks[i].Data.Data = [][]byte{utils.MarshalOrPanic(timestampedEnvelope(start.Add(time.Duration(i) * 24 * time.Hour)))}
+	}
+	getBlock := func(number uint64) (*cb.Block, error) {
+		return blocks[number], nil
+	}
+	now := start.Add(10 * 24 * time.Hour)
+
+	for _, tc := range []struct {
+		name     string
+		policy   RetentionPolicy
+		first    uint64
+		expected uint64
+	}{
+		{"Disabled", RetentionPolicy{}, 0, 0},
+		{"Blocks", RetentionPolicy{Blocks: 3}, 0, 7},
+		{"TooFewBlocks", RetentionPolicy{Blocks: 20}, 2, 2},
+		{"Age", RetentionPolicy{Age: 72 * time.Hour}, 0, 7},
+		{"AgeKeepsLastBlock", RetentionPolicy{Age: time.Hour}, 0, 9},
+		{"BlocksOutlastAge", RetentionPolicy{Blocks: 5, Age: 72 * time.Hour}, 0, 5},
+		{"AgeOutlastsBlocks", RetentionPolicy{Blocks: 2, Age: 72 * time.Hour}, 0, 7},
+		{"AlreadyPruned", RetentionPolicy{Blocks: 5}, 8, 8},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			boundary, err := PruneBoundary(tc.policy, tc.first, uint64(len(blocks)), now, getBlock)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if boundary != tc.expected {
+				t.Fatalf("Expected boundary %d, got %d", tc.expected, boundary)
+			}
+		})
+	}
+
+	// A transaction back-dated by its client in a recent block must not get the
+	// blocks before it pruned early
+	blocks[7].Data.Data = [][]byte{utils.MarshalOrPanic(timestampedEnvelope(start))}
+	boundary, err := PruneBoundary(RetentionPolicy{Age: 4*24*time.Hour + 23*time.Hour}, 0, uint64(len(blocks)), now, getBlock)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if boundary != 6 {
+		t.Fatalf("Expected boundary 6 despite the back-dated block, got %d", boundary)
+	}
+}
+
+func TestRetainedBlocks(t *testing.T) {
+	block := cb.NewBlock(7, nil)
+	block.Metadata.Metadata[cb.BlockMetadataIndex_LAST_CONFIG] = utils.MarshalOrPanic(&cb.Metadata{
+		Value: utils.MarshalOrPanic(&cb.LastConfig{Index: 4}),
+	})
+	retain, err := RetainedBlocks(block)
+	if err != nil || !reflect.DeepEqual(retain, []uint64{0, 4}) {
+		t.Fatalf("Expected the genesis and the last config blocks to be retained, got %v, %v", retain, err)
+	}
+
+	block.Metadata.Metadata[cb.BlockMetadataIndex_LAST_CONFIG] = []byte("garbage")
+	if _, err := RetainedBlocks(block); err == nil {
+		t.Fatalf("Expected a malformed last config to be reported")
+	}
+}
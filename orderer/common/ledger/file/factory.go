@@ -26,6 +26,7 @@ import (
 
 type fileLedgerFactory struct {
 	blkstorageProvider blkstorage.BlockStoreProvider
+	retention          ledger.RetentionPolicy
 	ledgers            map[string]ledger.ReadWriter
 	mutex              sync.Mutex
 }
@@ -46,7 +47,7 @@ func (flf *fileLedgerFactory) GetOrCreate(chainID string) (ledger.ReadWriter, er
 	if err != nil {
 		return nil, err
 	}
-	ledger = &fileLedger{blockStore: blockStore, signal: make(chan struct{})}
+	ledger = &fileLedger{blockStore: blockStore, signal: make(chan struct{}), retention: flf.retention}
 	flf.ledgers[key] = ledger
 	return ledger, nil
 }
@@ -60,19 +61,37 @@ func (flf *fileLedgerFactory) ChainIDs() []string {
 	return chainIDs
 }
 
+// Remove closes the ledger of a chain if it is open, and deletes it
+func (flf *fileLedgerFactory) Remove(chainID string) error {
+	flf.mutex.Lock()
+	defer flf.mutex.Unlock()
+
+	if l, ok := flf.ledgers[chainID]; ok {
+		l.(*fileLedger).blockStore.Shutdown()
+		delete(flf.ledgers, chainID)
+	}
+	return flf.blkstorageProvider.Remove(chainID)
+}
+
 // Close releases all resources acquired by the factory
 func (flf *fileLedgerFactory) Close() {
 	flf.blkstorageProvider.Close()
 }
 
-// New creates a new ledger factory
-func New(directory string) ledger.Factory {
+// New creates a new ledger factory, whose ledgers are pruned according to the
+// given retention policy
+func New(directory string, retention ledger.RetentionPolicy) ledger.Factory {
+	return newFactory(directory, -1, retention)
+}
+
+func newFactory(directory string, maxBlockfileSize int, retention ledger.RetentionPolicy) *fileLedgerFactory {
 	return &fileLedgerFactory{
 		blkstorageProvider: fsblkstorage.NewProvider(
-			fsblkstorage.NewConf(directory, -1),
+			fsblkstorage.NewConf(directory, maxBlockfileSize),
 			&blkstorage.IndexConfig{
 				AttrsToIndex: []blkstorage.IndexableAttr{blkstorage.IndexableAttrBlockNum}},
 		),
-		ledgers: make(map[string]ledger.ReadWriter),
+		retention: retention,
+		ledgers:   make(map[string]ledger.ReadWriter),
 	}
 }
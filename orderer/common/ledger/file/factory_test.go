@@ -21,9 +21,11 @@ import (
 	"io/ioutil"
 	"testing"
 
+	cl "github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/tools/configtxgen/provisional"
 	"github.com/hyperledger/fabric/orderer/common/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -42,6 +44,10 @@ func (mbsp *mockBlockStoreProvider) OpenBlockStore(ledgerid string) (blkstorage.
 	return mbsp.blockstore, mbsp.error
 }
 
+func (mbsp *mockBlockStoreProvider) CreateBlockStoreFromSnapshot(ledgerid string, lastBlock *cb.Block, retain []*cb.Block, txIDs cl.ResultsIterator) (blkstorage.BlockStore, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
 func (mbsp *mockBlockStoreProvider) Exists(ledgerid string) (bool, error) {
 	return mbsp.exists, mbsp.error
 }
@@ -50,6 +56,14 @@ func (mbsp *mockBlockStoreProvider) List() ([]string, error) {
 	return mbsp.list, mbsp.error
 }
 
+func (mbsp *mockBlockStoreProvider) Remove(ledgerid string) error {
+	return mbsp.error
+}
+
+func (mbsp *mockBlockStoreProvider) Rollback(ledgerid string, blockNum uint64) error {
+	return mbsp.error
+}
+
 func (mbsp *mockBlockStoreProvider) Close() {
 }
 
@@ -72,19 +86,19 @@ func TestMultiReinitialization(t *testing.T) {
 	dir, err := ioutil.TempDir("", "hyperledger_fabric")
 	assert.NoError(t, err, "Error creating temp dir: %s", err)
 
-	flf := New(dir)
+	flf := New(dir, ledger.RetentionPolicy{})
 	_, err = flf.GetOrCreate(provisional.TestChainID)
 	assert.NoError(t, err, "Error GetOrCreate chain")
 	assert.Equal(t, 1, len(flf.ChainIDs()), "Expected 1 chain")
 	flf.Close()
 
-	flf = New(dir)
+	flf = New(dir, ledger.RetentionPolicy{})
 	_, err = flf.GetOrCreate("foo")
 	assert.NoError(t, err, "Error creating chain")
 	assert.Equal(t, 2, len(flf.ChainIDs()), "Expected chain to be recovered")
 	flf.Close()
 
-	flf = New(dir)
+	flf = New(dir, ledger.RetentionPolicy{})
 	_, err = flf.GetOrCreate("bar")
 	assert.NoError(t, err, "Error creating chain")
 	assert.Equal(t, 3, len(flf.ChainIDs()), "Expected chain to be recovered")
@@ -17,8 +17,11 @@ limitations under the License.
 package fileledger
 
 import (
+	"time"
+
 	cl "github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	ledgerutil "github.com/hyperledger/fabric/common/ledger/util"
 	ledger "github.com/hyperledger/fabric/orderer/common/ledger"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
@@ -36,6 +39,8 @@ func init() {
 type fileLedger struct {
 	blockStore blkstorage.BlockStore
 	signal     chan struct{}
+	retention  ledger.RetentionPolicy
+	pruner     ledgerutil.LatestTask
 }
 
 type fileLedgerIterator struct {
@@ -49,7 +54,20 @@ type fileLedgerIterator struct {
 func (i *fileLedgerIterator) Next() (*cb.Block, cb.Status) {
 	for {
 		if i.blockNumber < i.ledger.Height() {
+			if i.commonIterator == nil {
+				if i.blockNumber < i.ledger.firstBlockNumber() {
+					// Only the retained blocks remain below the first block
+					return i.nextRetained()
+				}
+				var err error
+				if i.commonIterator, err = i.ledger.blockStore.RetrieveBlocks(i.blockNumber); err != nil {
+					return nil, cb.Status_SERVICE_UNAVAILABLE
+				}
+			}
 			result, err := i.commonIterator.Next()
+			if err == blkstorage.ErrPruned {
+				return nil, cb.Status_NOT_FOUND
+			}
 			if err != nil {
 				return nil, cb.Status_SERVICE_UNAVAILABLE
 			}
@@ -60,6 +78,18 @@ func (i *fileLedgerIterator) Next() (*cb.Block, cb.Status) {
 	}
 }
 
+func (i *fileLedgerIterator) nextRetained() (*cb.Block, cb.Status) {
+	block, err := i.ledger.blockStore.RetrieveBlockByNumber(i.blockNumber)
+	if err == blkstorage.ErrPruned {
+		return nil, cb.Status_NOT_FOUND
+	}
+	if err != nil {
+		return nil, cb.Status_SERVICE_UNAVAILABLE
+	}
+	i.blockNumber++
+	return block, cb.Status_SUCCESS
+}
+
 // ReadyChan supplies a channel which will block until Next will not block
 func (i *fileLedgerIterator) ReadyChan() <-chan struct{} {
 	signal := i.ledger.signal
@@ -71,7 +101,9 @@ func (i *fileLedgerIterator) ReadyChan() <-chan struct{} {
 
 // Close releases resources acquired by the Iterator
 func (i *fileLedgerIterator) Close() {
-	i.commonIterator.Close()
+	if i.commonIterator != nil {
+		i.commonIterator.Close()
+	}
 }
 
 // Iterator returns an Iterator, as specified by a cb.SeekInfo message, and its
@@ -80,7 +112,7 @@ func (fl *fileLedger) Iterator(startPosition *ab.SeekPosition) (ledger.Iterator,
 	var startingBlockNumber uint64
 	switch start := startPosition.Type.(type) {
 	case *ab.SeekPosition_Oldest:
-		startingBlockNumber = 0
+		startingBlockNumber = fl.firstBlockNumber()
 	case *ab.SeekPosition_Newest:
 		info, err := fl.blockStore.GetBlockchainInfo()
 		if err != nil {
@@ -94,16 +126,33 @@ func (fl *fileLedger) Iterator(startPosition *ab.SeekPosition) (ledger.Iterator,
 		if startingBlockNumber > height {
 			return &ledger.NotFoundErrorIterator{}, 0
 		}
+		if startingBlockNumber < fl.firstBlockNumber() {
+			if _, err := fl.blockStore.RetrieveBlockByNumber(startingBlockNumber); err != nil {
+				logger.Debugf("Block %d is not available: %s", startingBlockNumber, err)
+				return &ledger.NotFoundErrorIterator{}, 0
+			}
+		}
+	case *ab.SeekPosition_Timestamp:
+		var err error
+		startingBlockNumber, err = ledger.SearchTimestamp(fl.firstBlockNumber(), fl.Height(), start.Timestamp.GetTimestamp(), fl.blockStore.RetrieveBlockByNumber)
+		if err != nil {
+			logger.Warningf("Cannot seek to timestamp %v: %s", start.Timestamp.GetTimestamp(), err)
+			return &ledger.NotFoundErrorIterator{}, 0
+		}
 	default:
 		return &ledger.NotFoundErrorIterator{}, 0
 	}
 
-	iterator, err := fl.blockStore.RetrieveBlocks(startingBlockNumber)
+	return &fileLedgerIterator{ledger: fl, blockNumber: startingBlockNumber}, startingBlockNumber
+}
+
+// firstBlockNumber returns the number of the oldest block which was not pruned
+func (fl *fileLedger) firstBlockNumber() uint64 {
+	first, err := fl.blockStore.GetFirstBlockNumber()
 	if err != nil {
-		return &ledger.NotFoundErrorIterator{}, 0
+		logger.Panic(err)
 	}
-
-	return &fileLedgerIterator{ledger: fl, blockNumber: startingBlockNumber, commonIterator: iterator}, startingBlockNumber
+	return first
 }
 
 // Height returns the number of blocks on the ledger
@@ -121,6 +170,35 @@ func (fl *fileLedger) Append(block *cb.Block) error {
 	if err == nil {
 		close(fl.signal)
 		fl.signal = make(chan struct{})
+		fl.startPrune(block)
 	}
 	return err
 }
+
+// startPrune prunes the ledger in the background according to its retention
+// policy.  Blocks appended while a prune is in progress get pruned as soon as
+// it ends.
+func (fl *fileLedger) startPrune(lastBlock *cb.Block) {
+	if !fl.retention.Enabled() {
+		return
+	}
+	fl.pruner.Run(func() {
+		if err := fl.prune(lastBlock, time.Now()); err != nil {
+			logger.Warningf("Failed pruning the ledger: %s", err)
+		}
+	})
+}
+
+// prune removes the blocks which the retention policy no longer requires, as
+// of the given time and last block
+func (fl *fileLedger) prune(lastBlock *cb.Block, now time.Time) error {
+	retain, err := ledger.RetainedBlocks(lastBlock)
+	if err != nil {
+		return err
+	}
+	boundary, err := ledger.PruneBoundary(fl.retention, fl.firstBlockNumber(), lastBlock.Header.Number+1, now, fl.blockStore.RetrieveBlockByNumber)
+	if err != nil {
+		return err
+	}
+	return fl.blockStore.Prune(boundary, retain, "")
+}
@@ -21,14 +21,19 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	cl "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage/fsblkstorage"
 	"github.com/hyperledger/fabric/common/tools/configtxgen/provisional"
 	"github.com/hyperledger/fabric/orderer/common/ledger"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
 	logging "github.com/op/go-logging"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -50,7 +55,7 @@ func initialize(t *testing.T) (*testEnv, *fileLedger) {
 	name, err := ioutil.TempDir("", "hyperledger_fabric")
 	assert.NoError(t, err, "Error creating temp dir: %s", err)
 
-	flf := New(name).(*fileLedgerFactory)
+	flf := New(name, ledger.RetentionPolicy{}).(*fileLedgerFactory)
 	fl, err := flf.GetOrCreate(provisional.TestChainID)
 	assert.NoError(t, err, "Error GetOrCreate chain")
 
@@ -117,6 +122,18 @@ func (mbs *mockBlockStore) RetrieveTxValidationCodeByTxID(txID string) (peer.TxV
 	return mbs.txValidationCode, mbs.defaultError
 }
 
+func (mbs *mockBlockStore) GetTxIDsIterator() (cl.ResultsIterator, error) {
+	return nil, mbs.defaultError
+}
+
+func (mbs *mockBlockStore) Prune(blockNum uint64, retain []uint64, archiveDir string) error {
+	return mbs.defaultError
+}
+
+func (mbs *mockBlockStore) GetFirstBlockNumber() (uint64, error) {
+	return 0, nil
+}
+
 func (*mockBlockStore) Shutdown() {
 }
 
@@ -167,7 +184,7 @@ func TestReinitialization(t *testing.T) {
 	tev.shutDown()
 
 	// re-initialize the ledger provider (not the test ledger itself!)
-	provider2 := New(tev.location)
+	provider2 := New(tev.location, ledger.RetentionPolicy{})
 
 	// assert expected ledgers exist
 	chains := provider2.ChainIDs()
@@ -340,3 +357,107 @@ func TestBlockstoreError(t *testing.T) {
 		assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, status, "Expected service unavailable error")
 	}
 }
+
+// appendConfigChain appends blocks to the ledger up to the given height, block
+// 5 being the last config block
+func appendConfigChain(fl ledger.ReadWriter, height uint64) []*cb.Block {
+	var blocks []*cb.Block
+	for fl.Height() < height {
+		block := ledger.CreateNextBlock(fl, []*cb.Envelope{{Payload: []byte("My Data")}})
+		lastConfig := uint64(0)
+		if block.Header.Number >= 5 {
+			lastConfig = 5
+		}
+		block.Metadata.Metadata[cb.BlockMetadataIndex_LAST_CONFIG] = utils.MarshalOrPanic(&cb.Metadata{
+			Value: utils.MarshalOrPanic(&cb.LastConfig{Index: lastConfig}),
+		})
+		fl.Append(block)
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func TestPrune(t *testing.T) {
+	name, err := ioutil.TempDir("", "hyperledger_fabric")
+	assert.NoError(t, err, "Error creating temp dir: %s", err)
+	defer os.RemoveAll(name)
+	// Small block files, so that a few blocks fill each
+	flf := newFactory(name, 1000, ledger.RetentionPolicy{})
+	defer flf.Close()
+	rl, err := flf.GetOrCreate(provisional.TestChainID)
+	assert.NoError(t, err, "Error GetOrCreate chain")
+	fl := rl.(*fileLedger)
+
+	blocks := appendConfigChain(fl, 100)
+	fl.retention = ledger.RetentionPolicy{Blocks: 20}
+	assert.NoError(t, fl.prune(blocks[99], time.Now()))
+
+	first := fl.firstBlockNumber()
+	assert.True(t, first > 5 && first <= 80, "Expected whole block files below block 80 to be pruned, first block is %d", first)
+	assert.Equal(t, uint64(100), fl.Height(), "Pruning should not change the height")
+
+	it, num := fl.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Oldest{}})
+	assert.Equal(t, first, num, "Expected the oldest position to be the oldest available block")
+	block, status := it.Next()
+	assert.Equal(t, cb.Status_SUCCESS, status)
+	assert.Equal(t, first, block.Header.Number)
+	it.Close()
+
+	for _, retained := range []uint64{0, 5} {
+		it, num = fl.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: retained}}})
+		assert.Equal(t, retained, num)
+		block, status = it.Next()
+		assert.Equal(t, cb.Status_SUCCESS, status, "Expected retained block %d to be available", retained)
+		assert.Equal(t, blocks[retained].Header.Hash(), block.Header.Hash())
+		_, status = it.Next()
+		assert.Equal(t, cb.Status_NOT_FOUND, status, "Expected the block following retained block %d to be pruned", retained)
+		it.Close()
+	}
+
+	it, _ = fl.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: first - 1}}})
+	assert.IsType(t, &ledger.NotFoundErrorIterator{}, it, "Expected a pruned block not to be found")
+	_, err = fl.blockStore.RetrieveBlockByNumber(first - 1)
+	assert.Equal(t, blkstorage.ErrPruned, err)
+
+	// The prune survives a restart
+	fl.blockStore.Shutdown()
+	flf.Close()
+	flf = newFactory(name, 1000, ledger.RetentionPolicy{})
+	rl, err = flf.GetOrCreate(provisional.TestChainID)
+	assert.NoError(t, err, "Error GetOrCreate chain")
+	assert.Equal(t, first, rl.(*fileLedger).firstBlockNumber())
+	assert.NotNil(t, ledger.GetBlock(rl, 5), "Expected the last config block to be retained")
+}
+
+func TestPruneOnAppend(t *testing.T) {
+	name, err := ioutil.TempDir("", "hyperledger_fabric")
+	assert.NoError(t, err, "Error creating temp dir: %s", err)
+	defer os.RemoveAll(name)
+	flf := newFactory(name, 1000, ledger.RetentionPolicy{Blocks: 10})
+	defer flf.Close()
+	rl, err := flf.GetOrCreate(provisional.TestChainID)
+	assert.NoError(t, err, "Error GetOrCreate chain")
+	fl := rl.(*fileLedger)
+
+	appendConfigChain(fl, 50)
+	deadline := time.Now().Add(5 * time.Second)
+	for fl.firstBlockNumber() <= 5 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.True(t, fl.firstBlockNumber() > 5, "Expected appending blocks to prune the ledger")
+}
+
+func TestRemove(t *testing.T) {
+	tev, fl := initialize(t)
+	defer tev.tearDown()
+	fl.Append(ledger.CreateNextBlock(fl, []*cb.Envelope{{Payload: []byte("My Data")}}))
+
+	assert.NoError(t, tev.flf.Remove(provisional.TestChainID))
+	assert.Empty(t, tev.flf.ChainIDs(), "Expected the removed chain to be gone")
+	_, err := os.Stat(filepath.Join(tev.location, fsblkstorage.ChainsDir, provisional.TestChainID))
+	assert.True(t, os.IsNotExist(err), "Expected the block files to be removed")
+
+	rl, err := tev.flf.GetOrCreate(provisional.TestChainID)
+	assert.NoError(t, err, "Error recreating chain")
+	assert.Zero(t, rl.Height(), "Expected the recreated chain to be empty")
+}
@@ -29,6 +29,7 @@ import (
 
 type jsonLedgerFactory struct {
 	directory string
+	retention ledger.RetentionPolicy
 	ledgers   map[string]ledger.ReadWriter
 	mutex     sync.Mutex
 }
@@ -54,31 +55,38 @@ func (jlf *jsonLedgerFactory) GetOrCreate(chainID string) (ledger.ReadWriter, er
 		return nil, err
 	}
 
-	ch := newChain(directory)
+	ch := newChain(directory, jlf.retention)
 	jlf.ledgers[key] = ch
 	return ch, nil
 }
 
 // newChain creates a new chain backed by a JSON ledger
-func newChain(directory string) ledger.ReadWriter {
+func newChain(directory string, retention ledger.RetentionPolicy) ledger.ReadWriter {
 	jl := &jsonLedger{
 		directory: directory,
 		signal:    make(chan struct{}),
 		marshaler: &jsonpb.Marshaler{Indent: "  "},
+		retention: retention,
 	}
 	jl.initializeBlockHeight()
 	logger.Debugf("Initialized to block height %d with hash %x", jl.height-1, jl.lastHash)
 	return jl
 }
 
-// initializeBlockHeight verifies that all blocks exist between 0 and the block
-// height, and populates the lastHash
+// initializeBlockHeight verifies that all blocks exist between the first block
+// left by pruning and the block height, and populates the lastHash
 func (jl *jsonLedger) initializeBlockHeight() {
+	first, err := jl.readFirstBlockNumber()
+	if err != nil {
+		logger.Panicf("Error reading the first block number: %s", err)
+	}
+	jl.first = first
+
 	infos, err := ioutil.ReadDir(jl.directory)
 	if err != nil {
 		logger.Panic(err)
 	}
-	nextNumber := uint64(0)
+	nextNumber := first
 	for _, info := range infos {
 		if info.IsDir() {
 			continue
@@ -88,6 +96,10 @@ func (jl *jsonLedger) initializeBlockHeight() {
 		if err != nil {
 			continue
 		}
+		if number < first {
+			// A block retained by pruning
+			continue
+		}
 		if number != nextNumber {
 			logger.Panicf("Missing block %d in the chain", nextNumber)
 		}
@@ -122,13 +134,23 @@ func (jlf *jsonLedgerFactory) ChainIDs() []string {
 	return ids
 }
 
+// Remove deletes the ledger of a chain
+func (jlf *jsonLedgerFactory) Remove(chainID string) error {
+	jlf.mutex.Lock()
+	defer jlf.mutex.Unlock()
+
+	delete(jlf.ledgers, chainID)
+	return os.RemoveAll(filepath.Join(jlf.directory, fmt.Sprintf(chainDirectoryFormatString, chainID)))
+}
+
 // Close is a no-op for the JSON ledger
 func (jlf *jsonLedgerFactory) Close() {
 	return // nothing to do
 }
 
-// New creates a new ledger factory
-func New(directory string) ledger.Factory {
+// New creates a new ledger factory, whose ledgers are pruned according to the
+// given retention policy
+func New(directory string, retention ledger.RetentionPolicy) ledger.Factory {
 	logger.Debugf("Initializing ledger at: %s", directory)
 	if err := os.MkdirAll(directory, 0700); err != nil {
 		logger.Panicf("Could not create directory %s: %s", directory, err)
@@ -136,6 +158,7 @@ func New(directory string) ledger.Factory {
 
 	jlf := &jsonLedgerFactory{
 		directory: directory,
+		retention: retention,
 		ledgers:   make(map[string]ledger.ReadWriter),
 	}
 
@@ -23,6 +23,7 @@ import (
 	"path"
 	"testing"
 
+	"github.com/hyperledger/fabric/orderer/common/ledger"
 	logging "github.com/op/go-logging"
 	"github.com/stretchr/testify/assert"
 )
@@ -39,7 +40,7 @@ func TestErrorMkdir(t *testing.T) {
 	ledgerPath := path.Join(name, "jsonledger")
 	assert.NoError(t, ioutil.WriteFile(ledgerPath, nil, 0700))
 
-	assert.Panics(t, func() { New(ledgerPath) }, "Should have failed to create factory")
+	assert.Panics(t, func() { New(ledgerPath, ledger.RetentionPolicy{}) }, "Should have failed to create factory")
 }
 
 // This test checks that factory initialization should ignore dir with invalid name and files.
@@ -56,7 +57,7 @@ func TestIgnoreInvalidObjectInDir(t *testing.T) {
 	_, err = ioutil.TempDir(name, "invalid_chain_")
 	assert.Nil(t, err, "Error creating temp dir: %s", err)
 
-	jlf := New(name)
+	jlf := New(name, ledger.RetentionPolicy{})
 	assert.Empty(t, jlf.ChainIDs(), "Expected invalid objects to be ignored while restoring chains from directory")
 }
 
@@ -74,13 +75,13 @@ func TestInvalidChain(t *testing.T) {
 	assert.NoError(t, ioutil.WriteFile(secondBlock, nil, 0700))
 
 	t.Run("MissingBlock", func(t *testing.T) {
-		assert.Panics(t, func() { New(name) }, "Expected initialization panics if block is missing")
+		assert.Panics(t, func() { New(name, ledger.RetentionPolicy{}) }, "Expected initialization panics if block is missing")
 	})
 
 	t.Run("SkipDir", func(t *testing.T) {
 		invalidBlock := path.Join(chainDir, fmt.Sprintf(blockFileFormatString, 0))
 		assert.NoError(t, os.Mkdir(invalidBlock, 0700))
-		assert.Panics(t, func() { New(name) }, "Expected initialization skips directory in chain dir")
+		assert.Panics(t, func() { New(name, ledger.RetentionPolicy{}) }, "Expected initialization skips directory in chain dir")
 		assert.NoError(t, os.RemoveAll(invalidBlock))
 	})
 
@@ -88,7 +89,7 @@ func TestInvalidChain(t *testing.T) {
 	assert.NoError(t, ioutil.WriteFile(firstBlock, nil, 0700))
 
 	t.Run("MalformedBlock", func(t *testing.T) {
-		assert.Panics(t, func() { New(name) }, "Expected initialization panics if block is malformed")
+		assert.Panics(t, func() { New(name, ledger.RetentionPolicy{}) }, "Expected initialization panics if block is malformed")
 	})
 }
 
@@ -103,7 +104,7 @@ func TestIgnoreInvalidBlockFileName(t *testing.T) {
 
 	invalidBlock := path.Join(chainDir, "invalid_block")
 	assert.NoError(t, ioutil.WriteFile(invalidBlock, nil, 0700))
-	jfl := New(name)
+	jfl := New(name, ledger.RetentionPolicy{})
 	assert.Equal(t, 1, len(jfl.ChainIDs()), "Expected factory initialized with 1 chain")
 
 	chain, err := jfl.GetOrCreate(jfl.ChainIDs()[0])
@@ -116,6 +117,6 @@ func TestClose(t *testing.T) {
 	assert.Nil(t, err, "Error creating temp dir: %s", err)
 	defer os.RemoveAll(name)
 
-	jlf := New(name)
+	jlf := New(name, ledger.RetentionPolicy{})
 	assert.NotPanics(t, func() { jlf.Close() }, "Noop should not pannic")
 }
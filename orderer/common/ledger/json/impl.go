@@ -19,10 +19,15 @@ package jsonledger
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	ledgerutil "github.com/hyperledger/fabric/common/ledger/util"
 	ledger "github.com/hyperledger/fabric/orderer/common/ledger"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
@@ -43,6 +48,7 @@ func init() {
 const (
 	blockFileFormatString      = "block_%020d.json"
 	chainDirectoryFormatString = "chain_%s"
+	firstBlockFileName         = "first_block"
 )
 
 type cursor struct {
@@ -51,11 +57,14 @@ type cursor struct {
 }
 
 type jsonLedger struct {
+	first     uint64 // accessed atomically, kept first for alignment
 	directory string
 	height    uint64
 	signal    chan struct{}
 	lastHash  []byte
 	marshaler *jsonpb.Marshaler
+	retention ledger.RetentionPolicy
+	pruner    ledgerutil.LatestTask
 }
 
 // readBlock returns the block or nil, and whether the block was found or not, (nil,true) generally indicates an irrecoverable problem
@@ -95,6 +104,9 @@ func (cu *cursor) Next() (*cb.Block, cb.Status) {
 			cu.blockNumber++
 			return block, cb.Status_SUCCESS
 		}
+		if cu.blockNumber < cu.jl.firstBlockNumber() {
+			return nil, cb.Status_NOT_FOUND
+		}
 		<-cu.jl.signal
 	}
 }
@@ -102,7 +114,7 @@ func (cu *cursor) Next() (*cb.Block, cb.Status) {
 // ReadyChan supplies a channel which will block until Next will not block
 func (cu *cursor) ReadyChan() <-chan struct{} {
 	signal := cu.jl.signal
-	if _, err := os.Stat(cu.jl.blockFilename(cu.blockNumber)); os.IsNotExist(err) {
+	if _, err := os.Stat(cu.jl.blockFilename(cu.blockNumber)); os.IsNotExist(err) && cu.blockNumber >= cu.jl.firstBlockNumber() {
 		return signal
 	}
 	return closedChan
@@ -115,7 +127,8 @@ func (cu *cursor) Close() {}
 func (jl *jsonLedger) Iterator(startPosition *ab.SeekPosition) (ledger.Iterator, uint64) {
 	switch start := startPosition.Type.(type) {
 	case *ab.SeekPosition_Oldest:
-		return &cursor{jl: jl, blockNumber: 0}, 0
+		first := jl.firstBlockNumber()
+		return &cursor{jl: jl, blockNumber: first}, first
 	case *ab.SeekPosition_Newest:
 		high := jl.height - 1
 		return &cursor{jl: jl, blockNumber: high}, high
@@ -123,7 +136,20 @@ func (jl *jsonLedger) Iterator(startPosition *ab.SeekPosition) (ledger.Iterator,
 		if start.Specified.Number > jl.height {
 			return &ledger.NotFoundErrorIterator{}, 0
 		}
+		if start.Specified.Number < jl.firstBlockNumber() {
+			// Only the retained blocks remain below the first block
+			if _, err := os.Stat(jl.blockFilename(start.Specified.Number)); err != nil {
+				return &ledger.NotFoundErrorIterator{}, 0
+			}
+		}
 		return &cursor{jl: jl, blockNumber: start.Specified.Number}, start.Specified.Number
+	case *ab.SeekPosition_Timestamp:
+		number, err := ledger.SearchTimestamp(jl.firstBlockNumber(), jl.height, start.Timestamp.GetTimestamp(), jl.getBlock)
+		if err != nil {
+			logger.Warningf("Cannot seek to timestamp %v: %s", start.Timestamp.GetTimestamp(), err)
+			return &ledger.NotFoundErrorIterator{}, 0
+		}
+		return &cursor{jl: jl, blockNumber: number}, number
 	default:
 		return &ledger.NotFoundErrorIterator{}, 0
 	}
@@ -149,9 +175,110 @@ func (jl *jsonLedger) Append(block *cb.Block) error {
 	jl.height++
 	close(jl.signal)
 	jl.signal = make(chan struct{})
+	jl.startPrune(block)
+	return nil
+}
+
+// getBlock returns a block, or an error if it cannot be read
+func (jl *jsonLedger) getBlock(number uint64) (*cb.Block, error) {
+	block, _ := jl.readBlock(number)
+	if block == nil {
+		return nil, fmt.Errorf("block %d is missing or corrupted", number)
+	}
+	return block, nil
+}
+
+// firstBlockNumber returns the number of the oldest block which was not pruned
+func (jl *jsonLedger) firstBlockNumber() uint64 {
+	return atomic.LoadUint64(&jl.first)
+}
+
+// startPrune prunes the ledger in the background according to its retention
+// policy.  Blocks appended while a prune is in progress get pruned as soon as
+// it ends.
+func (jl *jsonLedger) startPrune(lastBlock *cb.Block) {
+	if !jl.retention.Enabled() {
+		return
+	}
+	jl.pruner.Run(func() {
+		if err := jl.prune(lastBlock, time.Now()); err != nil {
+			logger.Warningf("Failed pruning the ledger at %s: %s", jl.directory, err)
+		}
+	})
+}
+
+// prune removes the blocks which the retention policy no longer requires, as
+// of the given time and last block.  The new first block number is persisted
+// before the block files are removed.
+func (jl *jsonLedger) prune(lastBlock *cb.Block, now time.Time) error {
+	retain, err := ledger.RetainedBlocks(lastBlock)
+	if err != nil {
+		return err
+	}
+	first := jl.firstBlockNumber()
+	boundary, err := ledger.PruneBoundary(jl.retention, first, lastBlock.Header.Number+1, now, jl.getBlock)
+	if err != nil {
+		return err
+	}
+	if boundary <= first {
+		return nil
+	}
+
+	if err := jl.writeFirstBlockNumber(boundary); err != nil {
+		return err
+	}
+	atomic.StoreUint64(&jl.first, boundary)
+
+	retained := make(map[uint64]bool)
+	for _, number := range retain {
+		retained[number] = true
+	}
+	infos, err := ioutil.ReadDir(jl.directory)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		var number uint64
+		if _, err := fmt.Sscanf(info.Name(), blockFileFormatString, &number); err != nil {
+			continue
+		}
+		if number >= boundary {
+			break
+		}
+		if retained[number] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(jl.directory, info.Name())); err != nil {
+			return err
+		}
+	}
+	logger.Infof("Pruned the blocks before block %d at %s", boundary, jl.directory)
 	return nil
 }
 
+// readFirstBlockNumber returns the first block number persisted by the last
+// prune, or 0 if the ledger was never pruned
+func (jl *jsonLedger) readFirstBlockNumber() (uint64, error) {
+	b, err := ioutil.ReadFile(filepath.Join(jl.directory, firstBlockFileName))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(string(b), 10, 64)
+}
+
+// writeFirstBlockNumber persists the first block number, replacing the file so
+// that a crash leaves either the former or the new number
+func (jl *jsonLedger) writeFirstBlockNumber(number uint64) error {
+	name := filepath.Join(jl.directory, firstBlockFileName)
+	if err := ioutil.WriteFile(name+".tmp", []byte(strconv.FormatUint(number, 10)), 0600); err != nil {
+		return err
+	}
+	return os.Rename(name+".tmp", name)
+}
+
 // writeBlock commits a block to disk
 func (jl *jsonLedger) writeBlock(block *cb.Block) {
 	name := jl.blockFilename(block.Header.Number)
@@ -26,6 +26,7 @@ import (
 	"github.com/hyperledger/fabric/orderer/common/ledger"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
 
 	logging "github.com/op/go-logging"
 	"github.com/stretchr/testify/assert"
@@ -47,7 +48,7 @@ func initialize(t *testing.T) (*testEnv, *jsonLedger) {
 	if err != nil {
 		t.Fatalf("Error creating temp dir: %s", err)
 	}
-	flf := New(name).(*jsonLedgerFactory)
+	flf := New(name, ledger.RetentionPolicy{}).(*jsonLedgerFactory)
 	fl, err := flf.GetOrCreate(provisional.TestChainID)
 	if err != nil {
 		panic(err)
@@ -80,7 +81,7 @@ func TestReinitialization(t *testing.T) {
 	tev, ofl := initialize(t)
 	defer tev.tearDown()
 	ofl.Append(ledger.CreateNextBlock(ofl, []*cb.Envelope{&cb.Envelope{Payload: []byte("My Data")}}))
-	flf := New(tev.location)
+	flf := New(tev.location, ledger.RetentionPolicy{})
 	chains := flf.ChainIDs()
 	assert.Len(t, chains, 1, "Should have recovered the chain")
 
@@ -99,7 +100,7 @@ func TestReinitialization(t *testing.T) {
 func TestMultiReinitialization(t *testing.T) {
 	tev, _ := initialize(t)
 	defer tev.tearDown()
-	flf := New(tev.location)
+	flf := New(tev.location, ledger.RetentionPolicy{})
 
 	_, err := flf.GetOrCreate("foo")
 	assert.Nil(t, err, "Error creating chain")
@@ -107,7 +108,7 @@ func TestMultiReinitialization(t *testing.T) {
 	_, err = flf.GetOrCreate("bar")
 	assert.Nil(t, err, "Error creating chain")
 
-	flf = New(tev.location)
+	flf = New(tev.location, ledger.RetentionPolicy{})
 	assert.Len(t, flf.ChainIDs(), 3, "Should have recovered the chains")
 }
 
@@ -178,6 +179,7 @@ func TestRaceCondition(t *testing.T) {
 	<-complete
 
 	assert.Equal(t, cb.Status_SUCCESS, status, "Expected to successfully read the block")
+	assert.NotNil(t, block, "Expected to successfully read the block")
 }
 
 func TestBlockedRetrieval(t *testing.T) {
@@ -271,3 +273,70 @@ func TestInvalidAddition(t *testing.T) {
 		assert.Error(t, fl.Append(block), "Addition of block with invalid previousHash should fail")
 	}
 }
+
+func TestPrune(t *testing.T) {
+	tev, fl := initialize(t)
+	defer tev.tearDown()
+
+	// Block 5 is the last config block
+	var blocks []*cb.Block
+	for fl.height < 30 {
+		block := ledger.CreateNextBlock(fl, []*cb.Envelope{{Payload: []byte("My Data")}})
+		lastConfig := uint64(0)
+		if block.Header.Number >= 5 {
+			lastConfig = 5
+		}
+		block.Metadata.Metadata[cb.BlockMetadataIndex_LAST_CONFIG] = utils.MarshalOrPanic(&cb.Metadata{
+			Value: utils.MarshalOrPanic(&cb.LastConfig{Index: lastConfig}),
+		})
+		assert.NoError(t, fl.Append(block))
+		blocks = append(blocks, block)
+	}
+	fl.retention = ledger.RetentionPolicy{Blocks: 10}
+	assert.NoError(t, fl.prune(blocks[len(blocks)-1], time.Now()))
+	assert.Equal(t, uint64(20), fl.firstBlockNumber())
+
+	it, num := fl.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Oldest{}})
+	assert.Equal(t, uint64(20), num, "Expected the oldest position to be the oldest available block")
+	block, status := it.Next()
+	assert.Equal(t, cb.Status_SUCCESS, status)
+	assert.Equal(t, uint64(20), block.Header.Number)
+
+	it, num = fl.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: 5}}})
+	assert.Equal(t, uint64(5), num)
+	select {
+	case <-it.ReadyChan():
+	default:
+		t.Fatalf("Should be ready for block read")
+	}
+	_, status = it.Next()
+	assert.Equal(t, cb.Status_SUCCESS, status, "Expected the last config block to be retained")
+	select {
+	case <-it.ReadyChan():
+	default:
+		t.Fatalf("Should not block on a pruned block")
+	}
+	_, status = it.Next()
+	assert.Equal(t, cb.Status_NOT_FOUND, status, "Expected the block following the config block to be pruned")
+
+	it, _ = fl.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: 19}}})
+	assert.IsType(t, &ledger.NotFoundErrorIterator{}, it, "Expected a pruned block not to be found")
+
+	// The prune survives a restart
+	jlf := New(tev.location, ledger.RetentionPolicy{})
+	rl, err := jlf.GetOrCreate(provisional.TestChainID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(30), rl.Height())
+	assert.Equal(t, uint64(20), rl.(*jsonLedger).firstBlockNumber())
+	assert.NotNil(t, ledger.GetBlock(rl, 0), "Expected the genesis block to be retained")
+}
+
+func TestRemove(t *testing.T) {
+	tev, _ := initialize(t)
+	defer tev.tearDown()
+
+	jlf := New(tev.location, ledger.RetentionPolicy{})
+	assert.NoError(t, jlf.Remove(provisional.TestChainID))
+	assert.Empty(t, jlf.ChainIDs())
+	assert.Empty(t, New(tev.location, ledger.RetentionPolicy{}).ChainIDs(), "Expected the chain directory to be removed")
+}
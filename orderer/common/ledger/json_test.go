@@ -62,7 +62,7 @@ func (env *jsonLedgerTestFactory) Persistent() bool {
 }
 
 func (env *jsonLedgerTestFactory) New() (Factory, ReadWriter) {
-	flf := jsonledger.New(env.location)
+	flf := jsonledger.New(env.location, RetentionPolicy{})
 	fl, err := flf.GetOrCreate(provisional.TestChainID)
 	if err != nil {
 		panic(err)
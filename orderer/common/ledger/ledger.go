@@ -17,6 +17,8 @@ limitations under the License.
 package ledger
 
 import (
+	"time"
+
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 )
@@ -30,6 +32,9 @@ type Factory interface {
 	// ChainIDs returns the chain IDs the Factory is aware of
 	ChainIDs() []string
 
+	// Remove deletes the ledger of a chain, which must no longer be in use
+	Remove(chainID string) error
+
 	// Close releases all resources acquired by the factory
 	Close()
 }
@@ -48,7 +53,8 @@ type Iterator interface {
 // Reader allows the caller to inspect the ledger
 type Reader interface {
 	// Iterator returns an Iterator, as specified by a cb.SeekInfo message, and
-	// its starting block number
+	// its starting block number.  The oldest position is the oldest block which
+	// was not pruned
 	Iterator(startType *ab.SeekPosition) (Iterator, uint64)
 	// Height returns the number of blocks on the ledger
 	Height() uint64
@@ -65,3 +71,19 @@ type ReadWriter interface {
 	Reader
 	Writer
 }
+
+// RetentionPolicy bounds the blocks a ledger keeps.  A block is pruned only once
+// it is outside of all the limits set.  The genesis block and the latest config
+// block are always kept.
+type RetentionPolicy struct {
+	// Blocks is the number of most recent blocks to keep, or 0 for no limit
+	Blocks uint64
+	// Age is how long a block is kept, based on the timestamps of its
+	// transactions, or 0 for no limit
+	Age time.Duration
+}
+
+// Enabled returns whether the policy prunes blocks at all
+func (rp RetentionPolicy) Enabled() bool {
+	return rp.Blocks > 0 || rp.Age > 0
+}
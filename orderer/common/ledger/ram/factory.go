@@ -81,6 +81,15 @@ func (rlf *ramLedgerFactory) ChainIDs() []string {
 	return ids
 }
 
+// Remove deletes the ledger of a chain
+func (rlf *ramLedgerFactory) Remove(chainID string) error {
+	rlf.mutex.Lock()
+	defer rlf.mutex.Unlock()
+
+	delete(rlf.ledgers, chainID)
+	return nil
+}
+
 // Close is a no-op for the RAM ledger
 func (rlf *ramLedgerFactory) Close() {
 	return // nothing to do
@@ -116,6 +116,38 @@ func (rl *ramLedger) Iterator(startPosition *ab.SeekPosition) (ledger.Iterator,
 			}
 			list = list.next // No need for nil check, because of range check above
 		}
+	case *ab.SeekPosition_Timestamp:
+		var blocks []*cb.Block
+		for item := rl.oldest; item != nil; item = item.next {
+			if item.block.Header.Number != ^uint64(0) { // Skip the 'preGenesis' block
+				blocks = append(blocks, item.block)
+			}
+		}
+		first := rl.newest.block.Header.Number + 1 - uint64(len(blocks))
+		number, err := ledger.SearchTimestamp(first, first+uint64(len(blocks)), start.Timestamp.GetTimestamp(), func(number uint64) (*cb.Block, error) {
+			return blocks[number-first], nil
+		})
+		if err != nil {
+			logger.Warningf("Cannot seek to timestamp %v: %s", start.Timestamp.GetTimestamp(), err)
+			return &ledger.NotFoundErrorIterator{}, 0
+		}
+
+		// Start from the block preceding the one found, or from a stand-in
+		// for it if the oldest block was found
+		if number == rl.oldest.block.Header.Number {
+			list = &simpleList{
+				block:  &cb.Block{Header: &cb.BlockHeader{Number: number - 1}},
+				next:   rl.oldest,
+				signal: make(chan struct{}),
+			}
+			close(list.signal)
+			break
+		}
+
+		list = rl.oldest
+		for list.block.Header.Number != number-1 {
+			list = list.next
+		}
 	}
 	cursor := &cursor{list: list}
 	blockNum := list.block.Header.Number + 1
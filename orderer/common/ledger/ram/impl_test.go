@@ -19,10 +19,12 @@ package ramledger
 import (
 	"testing"
 
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/hyperledger/fabric/common/tools/configtxgen/provisional"
 	"github.com/hyperledger/fabric/orderer/common/ledger"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
 
 	logging "github.com/op/go-logging"
 )
@@ -212,3 +214,31 @@ func TestAppendBadBLock(t *testing.T) {
 		}
 	})
 }
+
+// TestIteratorTimestampTruncated ensures that timestamp positions resolve among
+// the blocks which are still stored
+func TestIteratorTimestampTruncated(t *testing.T) {
+	rl := newTestChain(2)
+	for i := 1; i <= 3; i++ {
+		rl.Append(ledger.CreateNextBlock(rl, []*cb.Envelope{{Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+				Timestamp: &timestamp.Timestamp{Seconds: int64(i)},
+			})},
+		})}}))
+	}
+
+	for seconds, expected := range map[int64]uint64{0: 2, 2: 2, 3: 3, 4: 4} {
+		it, num := rl.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Timestamp{Timestamp: &ab.SeekTimestamp{
+			Timestamp: &timestamp.Timestamp{Seconds: seconds},
+		}}})
+		if num != expected {
+			t.Fatalf("Expected block iterator at %d for %d seconds, but got %d", expected, seconds, num)
+		}
+		if num < rl.Height() {
+			block, status := it.Next()
+			if status != cb.Status_SUCCESS || block.Header.Number != num {
+				t.Fatalf("Expected to retrieve block %d", num)
+			}
+		}
+	}
+}
@@ -17,9 +17,15 @@ limitations under the License.
 package ledger
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	ledgerutil "github.com/hyperledger/fabric/common/ledger/util"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
 )
 
 var closedChan chan struct{}
@@ -106,3 +112,72 @@ func GetBlock(rl Reader, index uint64) *cb.Block {
 		return nil
 	}
 }
+
+// SearchTimestamp returns the number of the first block in [low, high) with a
+// transaction at or after the given time, or high if there is none.  Blocks are
+// retrieved with getBlock.  The search is a binary one, as transaction
+// timestamps are expected to grow along the chain, which is why this is only
+// fit for seeking and not for deciding which blocks to prune.
+func SearchTimestamp(low, high uint64, ts *timestamp.Timestamp, getBlock func(number uint64) (*cb.Block, error)) (uint64, error) {
+	if ts == nil {
+		return 0, fmt.Errorf("missing timestamp")
+	}
+	target := time.Unix(ts.Seconds, int64(ts.Nanos))
+
+	for low < high {
+		middle := low + (high-low)/2
+		block, err := getBlock(middle)
+		if err != nil {
+			return 0, fmt.Errorf("cannot retrieve block %d: %s", middle, err)
+		}
+		if ledgerutil.BlockTimestamp(block).Before(target) {
+			low = middle + 1
+		} else {
+			high = middle
+		}
+	}
+	return low, nil
+}
+
+// PruneBoundary returns the number of the oldest block the retention policy
+// requires a ledger of the given height to keep, the blocks before first being
+// already pruned.  The last block is always kept.  Blocks are retrieved with
+// getBlock.
+func PruneBoundary(policy RetentionPolicy, first, height uint64, now time.Time, getBlock func(number uint64) (*cb.Block, error)) (uint64, error) {
+	if !policy.Enabled() || height <= first {
+		return first, nil
+	}
+
+	boundary := height - 1
+	if policy.Blocks > 0 {
+		if height <= policy.Blocks {
+			return first, nil
+		}
+		boundary = height - policy.Blocks
+	}
+	if policy.Age > 0 {
+		var err error
+		boundary, err = ledgerutil.AgeBoundary(first, boundary, now.Add(-policy.Age), getBlock)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if boundary < first {
+		return first, nil
+	}
+	return boundary, nil
+}
+
+// RetainedBlocks returns the numbers of the blocks which are kept whatever the
+// retention policy: the genesis block and the latest config block, as
+// referenced by the last block of the ledger
+func RetainedBlocks(lastBlock *cb.Block) ([]uint64, error) {
+	index, err := utils.GetLastConfigIndexFromBlock(lastBlock)
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine the latest config block: %s", err)
+	}
+	if index == 0 {
+		return []uint64{0}, nil
+	}
+	return []uint64{0, index}, nil
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package config
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -84,6 +85,7 @@ type General struct {
 	LogFormat      string
 	LocalMSPDir    string
 	LocalMSPID     string
+	TxIDCacheSize  int
 	BCCSP          *bccsp.FactoryOpts
 }
 
@@ -105,8 +107,15 @@ type Profile struct {
 
 // FileLedger contains configuration for the file-based ledger.
 type FileLedger struct {
-	Location string
-	Prefix   string
+	Location  string
+	Prefix    string
+	Retention Retention
+}
+
+// Retention contains configuration for the pruning of the file-based ledgers.
+type Retention struct {
+	Blocks uint64
+	Age    time.Duration
 }
 
 // RAMLedger contains configuration for the RAM ledger.
@@ -118,6 +127,25 @@ type RAMLedger struct {
 type BFTsmart struct {
 	ConnectionPoolSize uint
 	RecvPort           uint
+	// SendAddress is where the java component accepts the connection pool,
+	// either unix:///path/to/socket or tcp://host:port.
+	SendAddress string
+	// RecvAddress is where the java component delivers blocks, either
+	// unix:///path/to/socket or tcp://host:port. Defaults to
+	// tcp://localhost:RecvPort.
+	RecvAddress string
+	// TLS applies to the tcp addresses only.
+	TLS TLS
+	// Replicas is the number of replicas of the ordering service. Blocks must
+	// carry valid signatures from 2f+1 of them, with f = (Replicas-1)/3.
+	Replicas uint32
+	// MaxInFlight is the number of envelopes of a channel which may wait for
+	// the acknowledgement of the java component at any time. Envelopes beyond
+	// it are rejected with SERVICE_UNAVAILABLE.
+	MaxInFlight uint
+	// AckTimeout is how long an envelope may wait for the acknowledgement of
+	// the java component before being rejected with SERVICE_UNAVAILABLE.
+	AckTimeout time.Duration
 }
 
 // Kafka contains configuration for the Kafka-based orderer.
@@ -190,11 +218,12 @@ var defaults = TopLevel{
 			Enabled: false,
 			Address: "0.0.0.0:6060",
 		},
-		LogLevel:    "INFO",
-		LogFormat:   "%{color}%{time:2006-01-02 15:04:05.000 MST} [%{module}] %{shortfunc} -> %{level:.4s} %{id:03x}%{color:reset} %{message}",
-		LocalMSPDir: "msp",
-		LocalMSPID:  "DEFAULT",
-		BCCSP:       bccsp.GetDefaultOpts(),
+		LogLevel:      "INFO",
+		LogFormat:     "%{color}%{time:2006-01-02 15:04:05.000 MST} [%{module}] %{shortfunc} -> %{level:.4s} %{id:03x}%{color:reset} %{message}",
+		LocalMSPDir:   "msp",
+		LocalMSPID:    "DEFAULT",
+		TxIDCacheSize: 100000,
+		BCCSP:         bccsp.GetDefaultOpts(),
 	},
 	RAMLedger: RAMLedger{
 		HistorySize: 10000,
@@ -207,6 +236,13 @@ var defaults = TopLevel{
 
 		ConnectionPoolSize: 20,
 		RecvPort:           9999,
+		SendAddress:        "unix:///tmp/hlf-pool.sock",
+		Replicas:           4,
+		MaxInFlight:        1000,
+		AckTimeout:         5 * time.Second,
+		TLS: TLS{
+			Enabled: false,
+		},
 	},
 	Kafka: Kafka{
 		Retry: Retry{
@@ -328,6 +364,10 @@ func (c *TopLevel) completeInitialization(configDir string) {
 			logger.Infof("General.LocalMSPID unset, setting to %s", defaults.General.LocalMSPID)
 			c.General.LocalMSPID = defaults.General.LocalMSPID
 
+		case c.General.TxIDCacheSize == 0:
+			logger.Infof("General.TxIDCacheSize unset, setting to %d", defaults.General.TxIDCacheSize)
+			c.General.TxIDCacheSize = defaults.General.TxIDCacheSize
+
 		case c.FileLedger.Prefix == "":
 			logger.Infof("FileLedger.Prefix unset, setting to %s", defaults.FileLedger.Prefix)
 			c.FileLedger.Prefix = defaults.FileLedger.Prefix
@@ -384,6 +424,27 @@ func (c *TopLevel) completeInitialization(configDir string) {
 		case c.BFTsmart.RecvPort == 0:
 			logger.Infof("BFTsmart.RecvPort unset, setting to %v", defaults.BFTsmart.RecvPort)
 			c.BFTsmart.RecvPort = defaults.BFTsmart.RecvPort
+		case c.BFTsmart.SendAddress == "":
+			logger.Infof("BFTsmart.SendAddress unset, setting to %s", defaults.BFTsmart.SendAddress)
+			c.BFTsmart.SendAddress = defaults.BFTsmart.SendAddress
+		case c.BFTsmart.RecvAddress == "":
+			c.BFTsmart.RecvAddress = fmt.Sprintf("tcp://localhost:%d", c.BFTsmart.RecvPort)
+			logger.Infof("BFTsmart.RecvAddress unset, setting to %s", c.BFTsmart.RecvAddress)
+		case c.BFTsmart.Replicas == 0:
+			logger.Infof("BFTsmart.Replicas unset, setting to %v", defaults.BFTsmart.Replicas)
+			c.BFTsmart.Replicas = defaults.BFTsmart.Replicas
+		case c.BFTsmart.MaxInFlight == 0:
+			logger.Infof("BFTsmart.MaxInFlight unset, setting to %v", defaults.BFTsmart.MaxInFlight)
+			c.BFTsmart.MaxInFlight = defaults.BFTsmart.MaxInFlight
+		case c.BFTsmart.AckTimeout == 0:
+			logger.Infof("BFTsmart.AckTimeout unset, setting to %s", defaults.BFTsmart.AckTimeout)
+			c.BFTsmart.AckTimeout = defaults.BFTsmart.AckTimeout
+		case c.BFTsmart.TLS.Enabled && c.BFTsmart.TLS.Certificate == "":
+			logger.Panicf("BFTsmart.TLS.Certificate must be set if BFTsmart.TLS.Enabled is set to true.")
+		case c.BFTsmart.TLS.Enabled && c.BFTsmart.TLS.PrivateKey == "":
+			logger.Panicf("BFTsmart.TLS.PrivateKey must be set if BFTsmart.TLS.Enabled is set to true.")
+		case c.BFTsmart.TLS.Enabled && c.BFTsmart.TLS.RootCAs == nil:
+			logger.Panicf("BFTsmart.TLS.RootCAs must be set if BFTsmart.TLS.Enabled is set to true.")
 
 		default:
 			return
@@ -112,6 +112,39 @@ func TestKafkaTLSConfig(t *testing.T) {
 	}
 }
 
+func TestBFTsmartConfig(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		uconf := &TopLevel{BFTsmart: BFTsmart{RecvPort: 8888}}
+		uconf.completeInitialization(DummyPath)
+		assert.Equal(t, defaults.BFTsmart.SendAddress, uconf.BFTsmart.SendAddress)
+		assert.Equal(t, "tcp://localhost:8888", uconf.BFTsmart.RecvAddress, "RecvAddress should default to RecvPort on localhost")
+		assert.Equal(t, defaults.BFTsmart.Replicas, uconf.BFTsmart.Replicas)
+		assert.Equal(t, defaults.BFTsmart.MaxInFlight, uconf.BFTsmart.MaxInFlight)
+		assert.Equal(t, defaults.BFTsmart.AckTimeout, uconf.BFTsmart.AckTimeout)
+	})
+
+	testCases := []struct {
+		name        string
+		tls         TLS
+		shouldPanic bool
+	}{
+		{"Disabled", TLS{Enabled: false}, false},
+		{"EnabledNoPrivateKey", TLS{Enabled: true, Certificate: "public.key"}, true},
+		{"EnabledNoPublicKey", TLS{Enabled: true, PrivateKey: "private.key"}, true},
+		{"EnabledNoTrustedRoots", TLS{Enabled: true, PrivateKey: "private.key", Certificate: "public.key"}, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			uconf := &TopLevel{BFTsmart: BFTsmart{TLS: tc.tls}}
+			if tc.shouldPanic {
+				assert.Panics(t, func() { uconf.completeInitialization(DummyPath) }, "should panic")
+			} else {
+				assert.NotPanics(t, func() { uconf.completeInitialization(DummyPath) }, "should not panic")
+			}
+		})
+	}
+}
+
 func TestSystemChannel(t *testing.T) {
 	conf := Load()
 	assert.Equal(t, provisional.TestChainID, conf.General.SystemChannel, "System channel ID should be '%s' by default", provisional.TestChainID)
@@ -122,3 +155,13 @@ func TestProfileConfig(t *testing.T) {
 	uconf.completeInitialization(DummyPath)
 	assert.Equal(t, defaults.General.Profile.Address, uconf.General.Profile.Address, "Expected profile address to be filled with default value")
 }
+
+func TestTxIDCacheSizeConfig(t *testing.T) {
+	uconf := &TopLevel{}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, defaults.General.TxIDCacheSize, uconf.General.TxIDCacheSize, "Expected TxID cache size to be filled with default value")
+
+	uconf = &TopLevel{General: General{TxIDCacheSize: -1}}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, -1, uconf.General.TxIDCacheSize, "Expected a negative TxID cache size to be kept, disabling the cache")
+}
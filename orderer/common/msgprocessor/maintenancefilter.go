@@ -0,0 +1,55 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgprocessor
+
+import (
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/pkg/errors"
+)
+
+// ErrMaintenance is returned by the maintenance filter for the messages which
+// are not config updates, while the channel is in maintenance.
+var ErrMaintenance = errors.New("channel is in maintenance")
+
+// MaintenanceSupport defines the subset of the channel support required to create this filter
+type MaintenanceSupport interface {
+	ConsensusState() ab.ConsensusType_State
+}
+
+// MaintenanceRule implements the Rule interface. While the channel is in
+// maintenance, e.g. to migrate it to another consensus type, it only accepts
+// the config updates of the channel itself.
+type MaintenanceRule struct {
+	support MaintenanceSupport
+}
+
+// NewMaintenanceFilter creates a maintenance filter
+func NewMaintenanceFilter(support MaintenanceSupport) *MaintenanceRule {
+	return &MaintenanceRule{support: support}
+}
+
+// Apply returns ErrMaintenance if the channel is in maintenance and the
+// message is neither a CONFIG_UPDATE nor a CONFIG message.
+func (r *MaintenanceRule) Apply(message *cb.Envelope) error {
+	if r.support.ConsensusState() != ab.ConsensusType_STATE_MAINTENANCE {
+		return nil
+	}
+
+	chdr, err := utils.ChannelHeader(message)
+	if err != nil {
+		return errors.WithMessage(err, "could not determine the type of the message")
+	}
+	switch cb.HeaderType(chdr.Type) {
+	case cb.HeaderType_CONFIG_UPDATE, cb.HeaderType_CONFIG:
+		return nil
+	default:
+		return errors.Wrapf(ErrMaintenance, "message of type %s rejected", cb.HeaderType(chdr.Type))
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgprocessor
+
+import (
+	"testing"
+
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeMessageOfType(headerType cb.HeaderType) *cb.Envelope {
+	return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: &cb.Header{
+			ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{Type: int32(headerType), ChannelId: "foo"}),
+		},
+	})}
+}
+
+func TestMaintenanceRule(t *testing.T) {
+	support := &mockconfig.Orderer{}
+	rule := NewMaintenanceFilter(support)
+
+	for _, headerType := range []cb.HeaderType{cb.HeaderType_ENDORSER_TRANSACTION, cb.HeaderType_ORDERER_TRANSACTION, cb.HeaderType_CONFIG_UPDATE} {
+		assert.NoError(t, rule.Apply(makeMessageOfType(headerType)), "Expected %s to be accepted outside of maintenance", headerType)
+	}
+
+	support.ConsensusStateVal = ab.ConsensusType_STATE_MAINTENANCE
+	for _, headerType := range []cb.HeaderType{cb.HeaderType_ENDORSER_TRANSACTION, cb.HeaderType_ORDERER_TRANSACTION} {
+		assert.Equal(t, ErrMaintenance, errors.Cause(rule.Apply(makeMessageOfType(headerType))), "Expected %s to be rejected in maintenance", headerType)
+	}
+	for _, headerType := range []cb.HeaderType{cb.HeaderType_CONFIG_UPDATE, cb.HeaderType_CONFIG} {
+		assert.NoError(t, rule.Apply(makeMessageOfType(headerType)), "Expected %s to be accepted in maintenance", headerType)
+	}
+
+	err := rule.Apply(&cb.Envelope{Payload: []byte("garbage")})
+	assert.Error(t, err)
+	assert.NotEqual(t, ErrMaintenance, errors.Cause(err))
+}
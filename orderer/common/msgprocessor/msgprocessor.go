@@ -54,8 +54,29 @@ type Processor interface {
 	// configuration sequence number and nil on success, or an error if the message is not valid
 	ProcessNormalMsg(env *cb.Envelope) (configSeq uint64, err error)
 
+	// ClaimTxID is invoked at ingress for the normal messages accepted by ProcessNormalMsg, before ordering them.
+	// It returns ErrDuplicateTxID if the TxID was already ordered on the channel, or claimed by a message not yet
+	// ordered.  As its outcome depends on the history of this orderer, unlike ProcessNormalMsg it must not be
+	// invoked by the consenters to revalidate messages after ordering.
+	ClaimTxID(txID string) error
+
+	// ReleaseTxID gives up the claim on a TxID, for a message which could not be ordered
+	ReleaseTxID(txID string)
+
+	// ChargeRateLimit is invoked once at ingress for the messages accepted by ProcessNormalMsg or
+	// ProcessConfigUpdateMsg, before ordering them.  It returns ErrRateLimited if the creator of the
+	// message exceeded the rate allowed on the channel.  Like ClaimTxID, it must not be invoked by the
+	// consenters to revalidate messages after ordering.
+	ChargeRateLimit(env *cb.Envelope) error
+
 	// ProcessConfigUpdateMsg will attempt to apply the config update to the current configuration, and if successful
 	// return the resulting config message and the configSeq the config was computed from.  If the config update message
 	// is invalid, an error is returned.
 	ProcessConfigUpdateMsg(env *cb.Envelope) (config *cb.Envelope, configSeq uint64, err error)
+
+	// ValidateMigration is invoked once at ingress for the config messages returned by ProcessConfigUpdateMsg, before
+	// ordering them.  It returns an error if the config migrates the channel to a consenter which cannot take it over
+	// in its current state.  As its outcome may depend on systems outside of the orderer, like ClaimTxID it must not
+	// be invoked by the consenters to revalidate messages after ordering.
+	ValidateMigration(config *cb.Envelope) error
 }
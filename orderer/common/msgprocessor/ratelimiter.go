@@ -0,0 +1,208 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgprocessor
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
+	"github.com/hyperledger/fabric/common/metrics"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/msp"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/pkg/errors"
+)
+
+// ErrRateLimited is returned by the rate limiter for messages whose creator
+// exceeded the rate allowed on the channel.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// bucketSweepInterval is how often the buckets which refilled are dropped
+const bucketSweepInterval = time.Minute
+
+// RateLimitSupport defines the subset of the channel support required to create a rate limiter
+type RateLimitSupport interface {
+	RateLimits() *ab.RateLimits
+	Organizations() map[string]channelconfig.Org
+}
+
+// RateLimiter charges each message to the token bucket of its creator, as
+// configured in the RateLimits of the channel.  As the state of the buckets
+// depends on the history of this orderer, the limiter is not a Rule: it must
+// only be applied once at ingress, and never by the consenters to revalidate
+// messages after ordering.
+type RateLimiter struct {
+	support RateLimitSupport
+	scope   metrics.Scope
+	now     func() time.Time
+
+	lock      sync.Mutex
+	limits    *ab.RateLimits
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// NewRateLimiter creates a rate limiter which reports the state of its
+// buckets to the given metrics scope
+func NewRateLimiter(support RateLimitSupport, scope metrics.Scope) *RateLimiter {
+	return &RateLimiter{
+		support: support,
+		scope:   scope.SubScope("ratelimit"),
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// Charge takes a token from the bucket of the creator of the message, and
+// returns ErrRateLimited if there is none left.  The messages created by the
+// ordering organizations are never charged.  A nil limiter charges nothing.
+func (r *RateLimiter) Charge(message *cb.Envelope) error {
+	if r == nil {
+		return nil
+	}
+	limits := r.support.RateLimits()
+	if len(limits.GetLimits()) == 0 {
+		return nil
+	}
+
+	creator, err := messageCreator(message)
+	if err != nil {
+		return errors.WithMessage(err, "could not determine the creator of the message")
+	}
+	for _, org := range r.support.Organizations() {
+		if org.MSPID() == creator.Mspid {
+			return nil
+		}
+	}
+	limit := rateLimitFor(limits, creator.Mspid)
+	if limit.GetRate() == 0 {
+		return nil
+	}
+
+	key := creator.Mspid
+	if limits.PerIdentity {
+		key += "\x00" + string(creator.IdBytes)
+	}
+
+	r.lock.Lock()
+	now := r.now()
+	if !proto.Equal(limits, r.limits) {
+		logger.Debugf("Rate limits changed to %s, resetting the buckets", limits)
+		r.limits = limits
+		r.buckets = make(map[string]*tokenBucket)
+	}
+	r.sweep(now)
+	bucket, ok := r.buckets[key]
+	if !ok {
+		bucket = newTokenBucket(limit, now)
+		r.buckets[key] = bucket
+	}
+	allowed := bucket.take(now)
+	tokens := bucket.tokens
+	numBuckets := len(r.buckets)
+	r.lock.Unlock()
+
+	scope := r.scope.Tagged(map[string]string{"msp": creator.Mspid})
+	if !limits.PerIdentity {
+		scope.Gauge("tokens").Update(tokens)
+	}
+	r.scope.Gauge("buckets").Update(float64(numBuckets))
+	if !allowed {
+		scope.Counter("rejected").Inc(1)
+		return errors.Wrapf(ErrRateLimited, "creator of MSP %s exceeded the limit of %d messages per second", creator.Mspid, limit.Rate)
+	}
+	scope.Counter("allowed").Inc(1)
+	return nil
+}
+
+// sweep drops the buckets which refilled since they were last used, to bound
+// the number of buckets kept when each identity has a bucket of its own
+func (r *RateLimiter) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < bucketSweepInterval {
+		return
+	}
+	r.lastSweep = now
+	for key, bucket := range r.buckets {
+		if bucket.refill(now) == bucket.burst {
+			delete(r.buckets, key)
+		}
+	}
+}
+
+// rateLimitFor returns the limit of the given MSP, or the default limit if it
+// has none
+func rateLimitFor(limits *ab.RateLimits, mspID string) *ab.RateLimit {
+	var defaultLimit *ab.RateLimit
+	for _, limit := range limits.Limits {
+		switch limit.MspId {
+		case mspID:
+			return limit
+		case "":
+			defaultLimit = limit
+		}
+	}
+	return defaultLimit
+}
+
+func messageCreator(message *cb.Envelope) (*msp.SerializedIdentity, error) {
+	payload, err := utils.UnmarshalPayload(message.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if payload.Header == nil {
+		return nil, errors.New("missing header")
+	}
+	shdr, err := utils.GetSignatureHeader(payload.Header.SignatureHeader)
+	if err != nil {
+		return nil, err
+	}
+	creator := &msp.SerializedIdentity{}
+	if err := proto.Unmarshal(shdr.Creator, creator); err != nil {
+		return nil, errors.Wrap(err, "malformed creator")
+	}
+	return creator, nil
+}
+
+// tokenBucket holds up to burst tokens, and is refilled with rate tokens per second
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit *ab.RateLimit, now time.Time) *tokenBucket {
+	burst := math.Max(float64(limit.Burst), 1)
+	return &tokenBucket{
+		rate:   float64(limit.Rate),
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) float64 {
+	if now.After(b.last) {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+	return b.tokens
+}
+
+// take removes a token from the bucket, returning false if there is none
+func (b *tokenBucket) take(now time.Time) bool {
+	if b.refill(now) < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
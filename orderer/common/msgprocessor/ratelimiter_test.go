@@ -0,0 +1,148 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgprocessor
+
+import (
+	"testing"
+	"time"
+
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
+	"github.com/hyperledger/fabric/common/metrics"
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/msp"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeMessageFrom(mspID string, id string) *cb.Envelope {
+	creator := utils.MarshalOrPanic(&msp.SerializedIdentity{Mspid: mspID, IdBytes: []byte(id)})
+	return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: &cb.Header{
+			SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{Creator: creator}),
+		},
+	})}
+}
+
+func newTestRateLimiter(limits *ab.RateLimits) (*RateLimiter, *mockconfig.Orderer, *time.Time) {
+	support := &mockconfig.Orderer{RateLimitsVal: limits}
+	limiter := NewRateLimiter(support, metrics.NewRootScope())
+	now := time.Unix(1000, 0)
+	limiter.now = func() time.Time { return now }
+	return limiter, support, &now
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter, _, now := newTestRateLimiter(&ab.RateLimits{Limits: []*ab.RateLimit{
+		{MspId: "SampleOrg", Rate: 2, Burst: 3},
+		{MspId: "UnlimitedOrg"},
+	}})
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, limiter.Charge(makeMessageFrom("SampleOrg", "alice")))
+	}
+	err := limiter.Charge(makeMessageFrom("SampleOrg", "bob"))
+	assert.Equal(t, ErrRateLimited, errors.Cause(err), "Expected the MSP bucket to be shared by its identities")
+	assert.Contains(t, err.Error(), "SampleOrg")
+
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, limiter.Charge(makeMessageFrom("UnlimitedOrg", "carol")), "MSP with a zero rate")
+		assert.NoError(t, limiter.Charge(makeMessageFrom("OtherOrg", "dave")), "MSP without a limit and no default")
+	}
+
+	*now = now.Add(time.Second)
+	assert.NoError(t, limiter.Charge(makeMessageFrom("SampleOrg", "alice")))
+	assert.NoError(t, limiter.Charge(makeMessageFrom("SampleOrg", "alice")))
+	assert.Equal(t, ErrRateLimited, errors.Cause(limiter.Charge(makeMessageFrom("SampleOrg", "alice"))))
+
+	*now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, limiter.Charge(makeMessageFrom("SampleOrg", "alice")), "Expected the bucket to hold no more than burst")
+	}
+	assert.Equal(t, ErrRateLimited, errors.Cause(limiter.Charge(makeMessageFrom("SampleOrg", "alice"))))
+}
+
+func TestRateLimiterDefault(t *testing.T) {
+	limiter, _, _ := newTestRateLimiter(&ab.RateLimits{Limits: []*ab.RateLimit{
+		{Rate: 1, Burst: 1},
+		{MspId: "SampleOrg", Rate: 1, Burst: 2},
+	}})
+
+	assert.NoError(t, limiter.Charge(makeMessageFrom("OtherOrg", "alice")))
+	assert.Equal(t, ErrRateLimited, errors.Cause(limiter.Charge(makeMessageFrom("OtherOrg", "alice"))))
+	assert.NoError(t, limiter.Charge(makeMessageFrom("AnotherOrg", "bob")), "Expected each MSP to have a bucket of its own")
+	assert.NoError(t, limiter.Charge(makeMessageFrom("SampleOrg", "carol")))
+	assert.NoError(t, limiter.Charge(makeMessageFrom("SampleOrg", "carol")))
+	assert.Equal(t, ErrRateLimited, errors.Cause(limiter.Charge(makeMessageFrom("SampleOrg", "carol"))))
+}
+
+func TestRateLimiterPerIdentity(t *testing.T) {
+	limiter, _, now := newTestRateLimiter(&ab.RateLimits{
+		PerIdentity: true,
+		Limits:      []*ab.RateLimit{{MspId: "SampleOrg", Rate: 1, Burst: 1}},
+	})
+
+	assert.NoError(t, limiter.Charge(makeMessageFrom("SampleOrg", "alice")))
+	assert.Equal(t, ErrRateLimited, errors.Cause(limiter.Charge(makeMessageFrom("SampleOrg", "alice"))))
+	assert.NoError(t, limiter.Charge(makeMessageFrom("SampleOrg", "bob")))
+	assert.Len(t, limiter.buckets, 2)
+
+	// The buckets which refilled are dropped by the next sweep
+	*now = now.Add(bucketSweepInterval)
+	assert.NoError(t, limiter.Charge(makeMessageFrom("SampleOrg", "alice")))
+	assert.Len(t, limiter.buckets, 1)
+}
+
+func TestRateLimiterConfigChange(t *testing.T) {
+	limiter, support, _ := newTestRateLimiter(&ab.RateLimits{Limits: []*ab.RateLimit{{MspId: "SampleOrg", Rate: 1, Burst: 1}}})
+
+	assert.NoError(t, limiter.Charge(makeMessageFrom("SampleOrg", "alice")))
+	assert.Equal(t, ErrRateLimited, errors.Cause(limiter.Charge(makeMessageFrom("SampleOrg", "alice"))))
+
+	support.RateLimitsVal = &ab.RateLimits{Limits: []*ab.RateLimit{{MspId: "SampleOrg", Rate: 1, Burst: 2}}}
+	assert.NoError(t, limiter.Charge(makeMessageFrom("SampleOrg", "alice")))
+	assert.NoError(t, limiter.Charge(makeMessageFrom("SampleOrg", "alice")))
+	assert.Equal(t, ErrRateLimited, errors.Cause(limiter.Charge(makeMessageFrom("SampleOrg", "alice"))))
+
+	support.RateLimitsVal = &ab.RateLimits{}
+	assert.NoError(t, limiter.Charge(makeMessageFrom("SampleOrg", "alice")), "Expected no limit once the limits are removed")
+}
+
+type mockOrg string
+
+func (o mockOrg) Name() string  { return string(o) }
+func (o mockOrg) MSPID() string { return string(o) }
+
+func TestRateLimiterOrdererOrg(t *testing.T) {
+	limiter, support, _ := newTestRateLimiter(&ab.RateLimits{Limits: []*ab.RateLimit{{Rate: 1, Burst: 1}}})
+	support.OrganizationsVal = map[string]channelconfig.Org{"OrdererOrg": mockOrg("OrdererMSP")}
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, limiter.Charge(makeMessageFrom("OrdererMSP", "orderer")), "Expected the orderers never to be charged")
+	}
+	assert.NoError(t, limiter.Charge(makeMessageFrom("SampleOrg", "alice")))
+	assert.Equal(t, ErrRateLimited, errors.Cause(limiter.Charge(makeMessageFrom("SampleOrg", "alice"))))
+}
+
+func TestRateLimiterNil(t *testing.T) {
+	var limiter *RateLimiter
+	assert.NoError(t, limiter.Charge(makeMessageFrom("SampleOrg", "alice")))
+}
+
+func TestRateLimiterMalformed(t *testing.T) {
+	limiter, _, _ := newTestRateLimiter(&ab.RateLimits{Limits: []*ab.RateLimit{{Rate: 1, Burst: 1}}})
+
+	err := limiter.Charge(&cb.Envelope{Payload: []byte("garbage")})
+	assert.Error(t, err)
+	assert.NotEqual(t, ErrRateLimited, errors.Cause(err))
+
+	err = limiter.Charge(makeMessage([]byte("no header")))
+	assert.Error(t, err)
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/hyperledger/fabric/common/policies"
 	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/pkg/errors"
 )
 
 // StandardChannelSupport includes the resources needed for the StandardChannel processor.
@@ -28,19 +30,29 @@ type StandardChannelSupport interface {
 	// ProposeConfigUpdate takes in an Envelope of type CONFIG_UPDATE and produces a
 	// ConfigEnvelope to be used as the Envelope Payload Data of a CONFIG message
 	ProposeConfigUpdate(configtx *cb.Envelope) (*cb.ConfigEnvelope, error)
+
+	// TxIDCache returns the cache of the TxIDs ordered on the channel, or nil
+	TxIDCache() *TxIDCache
+
+	// RateLimiter returns the rate limiter of the channel, or nil
+	RateLimiter() *RateLimiter
 }
 
 // StandardChannel implements the Processor interface for standard extant channels
 type StandardChannel struct {
-	support StandardChannelSupport
-	filters *RuleSet
+	support     StandardChannelSupport
+	filters     *RuleSet
+	txIDs       *TxIDCache
+	rateLimiter *RateLimiter
 }
 
 // NewStandardChannel creates a new standard message processor
 func NewStandardChannel(support StandardChannelSupport, filters *RuleSet) *StandardChannel {
 	return &StandardChannel{
-		filters: filters,
-		support: support,
+		filters:     filters,
+		support:     support,
+		txIDs:       support.TxIDCache(),
+		rateLimiter: support.RateLimiter(),
 	}
 }
 
@@ -52,6 +64,7 @@ func CreateStandardChannelFilters(filterSupport channelconfig.Resources) *RuleSe
 	}
 	return NewRuleSet([]Rule{
 		EmptyRejectRule,
+		NewMaintenanceFilter(ordererConfig),
 		NewSizeFilter(ordererConfig),
 		NewSigFilter(policies.ChannelWriters, filterSupport.PolicyManager()),
 	})
@@ -83,6 +96,30 @@ func (s *StandardChannel) ProcessNormalMsg(env *cb.Envelope) (configSeq uint64,
 	return
 }
 
+// ClaimTxID returns ErrDuplicateTxID if the TxID was already ordered on the channel, or claimed by a message
+// not yet ordered, and claims it otherwise
+func (s *StandardChannel) ClaimTxID(txID string) error {
+	if err := s.txIDs.Claim(txID); err != nil {
+		return errors.Wrapf(err, "txid '%s' was already submitted", txID)
+	}
+	return nil
+}
+
+// ReleaseTxID gives up the claim on a TxID, for a message which could not be ordered
+func (s *StandardChannel) ReleaseTxID(txID string) {
+	s.txIDs.Release(txID)
+}
+
+// ChargeRateLimit returns ErrRateLimited if the creator of the message exceeded the rate allowed on the channel
+func (s *StandardChannel) ChargeRateLimit(env *cb.Envelope) error {
+	return s.rateLimiter.Charge(env)
+}
+
+// ValidateMigration returns nil, as the consenters which may take over the channel are not known to the channel processor
+func (s *StandardChannel) ValidateMigration(config *cb.Envelope) error {
+	return nil
+}
+
 // ProcessConfigUpdateMsg will attempt to apply the config impetus msg to the current configuration, and if successful
 // return the resulting config message and the configSeq the config was computed from.  If the config impetus message
 // is invalid, an error is returned.
@@ -12,7 +12,9 @@ import (
 
 	"github.com/hyperledger/fabric/common/crypto"
 	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,6 +24,8 @@ type mockSystemChannelFilterSupport struct {
 	ProposeConfigUpdateVal *cb.ConfigEnvelope
 	ProposeConfigUpdateErr error
 	SequenceVal            uint64
+	TxIDCacheVal           *TxIDCache
+	RateLimiterVal         *RateLimiter
 }
 
 func (ms *mockSystemChannelFilterSupport) ProposeConfigUpdate(env *cb.Envelope) (*cb.ConfigEnvelope, error) {
@@ -40,6 +44,14 @@ func (ms *mockSystemChannelFilterSupport) ChainID() string {
 	return testChannelID
 }
 
+func (ms *mockSystemChannelFilterSupport) TxIDCache() *TxIDCache {
+	return ms.TxIDCacheVal
+}
+
+func (ms *mockSystemChannelFilterSupport) RateLimiter() *RateLimiter {
+	return ms.RateLimiterVal
+}
+
 func TestClassifyMsg(t *testing.T) {
 	t.Run("ConfigUpdate", func(t *testing.T) {
 		class, err := (&StandardChannel{}).ClassifyMsg(&cb.ChannelHeader{Type: int32(cb.HeaderType_CONFIG_UPDATE)})
@@ -72,6 +84,44 @@ func TestProcessNormalMsg(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestClaimTxID(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		sc := NewStandardChannel(&mockSystemChannelFilterSupport{}, NewRuleSet([]Rule{AcceptRule}))
+		assert.NoError(t, sc.ClaimTxID("foo"))
+		assert.NoError(t, sc.ClaimTxID("foo"))
+	})
+	t.Run("Duplicate", func(t *testing.T) {
+		ms := &mockSystemChannelFilterSupport{TxIDCacheVal: NewTxIDCache(10, DefaultTxIDClaimTimeout)}
+		sc := NewStandardChannel(ms, NewRuleSet([]Rule{AcceptRule}))
+		assert.NoError(t, sc.ClaimTxID("foo"))
+		err := sc.ClaimTxID("foo")
+		assert.Equal(t, ErrDuplicateTxID, errors.Cause(err))
+		assert.Contains(t, err.Error(), "foo")
+
+		sc.ReleaseTxID("foo")
+		assert.NoError(t, sc.ClaimTxID("foo"))
+	})
+}
+
+func TestChargeRateLimit(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		sc := NewStandardChannel(&mockSystemChannelFilterSupport{}, NewRuleSet([]Rule{AcceptRule}))
+		assert.NoError(t, sc.ChargeRateLimit(makeMessageFrom("SampleOrg", "alice")))
+		assert.NoError(t, sc.ChargeRateLimit(makeMessageFrom("SampleOrg", "alice")))
+	})
+	t.Run("Limited", func(t *testing.T) {
+		limiter, _, _ := newTestRateLimiter(&ab.RateLimits{Limits: []*ab.RateLimit{{Rate: 1, Burst: 1}}})
+		sc := NewStandardChannel(&mockSystemChannelFilterSupport{RateLimiterVal: limiter}, NewRuleSet([]Rule{AcceptRule}))
+		msg := makeMessageFrom("SampleOrg", "alice")
+		assert.NoError(t, sc.ChargeRateLimit(msg))
+		assert.Equal(t, ErrRateLimited, errors.Cause(sc.ChargeRateLimit(msg)))
+
+		// Revalidating the message does not charge its creator again
+		_, err := sc.ProcessNormalMsg(msg)
+		assert.NoError(t, err)
+	})
+}
+
 func TestConfigUpdateMsg(t *testing.T) {
 	t.Run("BadMsg", func(t *testing.T) {
 		ms := &mockSystemChannelFilterSupport{
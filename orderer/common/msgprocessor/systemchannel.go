@@ -49,6 +49,7 @@ func CreateSystemChannelFilters(chainCreator ChainCreator, ledgerResources chann
 	}
 	return NewRuleSet([]Rule{
 		EmptyRejectRule,
+		NewMaintenanceFilter(ordererConfig),
 		NewSizeFilter(ordererConfig),
 		NewSigFilter(policies.ChannelWriters, ledgerResources.PolicyManager()),
 		NewSystemChannelFilter(ledgerResources, chainCreator),
@@ -0,0 +1,158 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgprocessor
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// ErrDuplicateTxID is returned at ingress for messages whose TxID was already
+// ordered on the channel, or is being ordered.
+var ErrDuplicateTxID = errors.New("duplicate transaction ID")
+
+// DefaultTxIDClaimTimeout is how long a TxID claimed at ingress is held for a
+// message which is neither ordered nor released, e.g. because it was dropped
+// when revalidated by the consenter.
+const DefaultTxIDClaimTimeout = time.Minute
+
+// TxIDCache remembers the TxIDs of the last messages ordered on a channel, up
+// to a bound, along with the TxIDs claimed at ingress by messages not yet
+// ordered. A nil *TxIDCache remembers nothing.
+type TxIDCache struct {
+	size         int
+	claimTimeout time.Duration
+	now          func() time.Time
+
+	lock      sync.Mutex
+	ordered   map[string]struct{}
+	ring      []string
+	next      int
+	claimed   map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewTxIDCache creates a cache holding the last size ordered TxIDs, or returns
+// nil if size is not positive.
+func NewTxIDCache(size int, claimTimeout time.Duration) *TxIDCache {
+	if size <= 0 {
+		return nil
+	}
+	return &TxIDCache{
+		size:         size,
+		claimTimeout: claimTimeout,
+		now:          time.Now,
+		ordered:      make(map[string]struct{}),
+		claimed:      make(map[string]time.Time),
+	}
+}
+
+// Seed adds the TxIDs of the last blocks of the ledger, until the cache is full
+// or a block cannot be read, e.g. because it was pruned.
+func (c *TxIDCache) Seed(reader ledger.Reader) {
+	if c == nil {
+		return
+	}
+
+	var blocks []*cb.Block
+	count := 0
+	for num := reader.Height(); num > 0 && count < c.size; num-- {
+		block := ledger.GetBlock(reader, num-1)
+		if block == nil {
+			break
+		}
+		blocks = append(blocks, block)
+		count += len(block.GetData().GetData())
+	}
+	for i := len(blocks) - 1; i >= 0; i-- {
+		c.AddBlock(blocks[i])
+	}
+	logger.Debugf("Seeded the TxID cache with %d TxIDs from the last %d blocks", len(c.ordered), len(blocks))
+}
+
+// AddBlock records the TxIDs of the messages of a block written to the ledger,
+// evicting the oldest TxIDs once the cache is full.
+func (c *TxIDCache) AddBlock(block *cb.Block) {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, data := range block.GetData().GetData() {
+		env, err := utils.UnmarshalEnvelope(data)
+		if err != nil {
+			continue
+		}
+		chdr, err := utils.ChannelHeader(env)
+		if err != nil || chdr.TxId == "" {
+			continue
+		}
+		delete(c.claimed, chdr.TxId)
+		if _, ok := c.ordered[chdr.TxId]; ok {
+			continue
+		}
+		if len(c.ring) < c.size {
+			c.ring = append(c.ring, chdr.TxId)
+		} else {
+			delete(c.ordered, c.ring[c.next])
+			c.ring[c.next] = chdr.TxId
+			c.next = (c.next + 1) % c.size
+		}
+		c.ordered[chdr.TxId] = struct{}{}
+	}
+}
+
+// Claim returns ErrDuplicateTxID if the TxID was ordered, or claimed and not
+// released, and claims it otherwise.
+func (c *TxIDCache) Claim(txID string) error {
+	if c == nil || txID == "" {
+		return nil
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := c.now()
+	c.sweep(now)
+	if _, ok := c.ordered[txID]; ok {
+		return ErrDuplicateTxID
+	}
+	if claimedAt, ok := c.claimed[txID]; ok && now.Sub(claimedAt) < c.claimTimeout {
+		return ErrDuplicateTxID
+	}
+	c.claimed[txID] = now
+	return nil
+}
+
+// Release gives up the claim on a TxID, for a message which was not ordered.
+func (c *TxIDCache) Release(txID string) {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.claimed, txID)
+}
+
+// sweep drops the claims which timed out
+func (c *TxIDCache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < c.claimTimeout {
+		return
+	}
+	c.lastSweep = now
+	for txID, claimedAt := range c.claimed {
+		if now.Sub(claimedAt) >= c.claimTimeout {
+			delete(c.claimed, txID)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msgprocessor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/ledger"
+	ramledger "github.com/hyperledger/fabric/orderer/common/ledger/ram"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeTxIDEnvelopes(txIDs ...string) []*cb.Envelope {
+	var envs []*cb.Envelope
+	for _, txID := range txIDs {
+		envs = append(envs, &cb.Envelope{
+			Payload: utils.MarshalOrPanic(&cb.Payload{
+				Header: &cb.Header{
+					ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{ChannelId: testChannelID, TxId: txID}),
+				},
+			}),
+		})
+	}
+	return envs
+}
+
+func makeTxIDBlock(number uint64, txIDs ...string) *cb.Block {
+	block := cb.NewBlock(number, nil)
+	for _, env := range makeTxIDEnvelopes(txIDs...) {
+		block.Data.Data = append(block.Data.Data, utils.MarshalOrPanic(env))
+	}
+	return block
+}
+
+func TestTxIDCacheDisabled(t *testing.T) {
+	var c *TxIDCache
+	assert.Nil(t, NewTxIDCache(0, time.Minute))
+	c.AddBlock(makeTxIDBlock(0, "foo"))
+	assert.NoError(t, c.Claim("foo"))
+	c.Release("foo")
+}
+
+func TestTxIDCacheOrdered(t *testing.T) {
+	c := NewTxIDCache(3, time.Minute)
+	c.AddBlock(makeTxIDBlock(0, "a", "b", ""))
+	assert.Equal(t, ErrDuplicateTxID, c.Claim("a"))
+	assert.Equal(t, ErrDuplicateTxID, c.Claim("b"))
+	assert.NoError(t, c.Claim(""), "Expected an empty TxID never to be a duplicate")
+	assert.NoError(t, c.Claim(""))
+
+	// The oldest TxIDs are evicted once the cache is full
+	c.AddBlock(makeTxIDBlock(1, "c", "d"))
+	assert.NoError(t, c.Claim("a"))
+	assert.Equal(t, ErrDuplicateTxID, c.Claim("b"))
+	assert.Equal(t, ErrDuplicateTxID, c.Claim("d"))
+	assert.Len(t, c.ordered, 3)
+}
+
+func TestTxIDCacheClaimed(t *testing.T) {
+	c := NewTxIDCache(10, time.Minute)
+	now := time.Unix(1000, 0)
+	c.now = func() time.Time { return now }
+
+	assert.NoError(t, c.Claim("a"))
+	assert.Equal(t, ErrDuplicateTxID, c.Claim("a"))
+
+	// Ordering the TxID turns the claim into an ordered TxID
+	c.AddBlock(makeTxIDBlock(0, "a"))
+	assert.Empty(t, c.claimed)
+	assert.Equal(t, ErrDuplicateTxID, c.Claim("a"))
+
+	// A released claim may be claimed again
+	assert.NoError(t, c.Claim("b"))
+	c.Release("b")
+	assert.NoError(t, c.Claim("b"))
+
+	// A claim which timed out may be claimed again, and is eventually dropped
+	now = now.Add(time.Minute)
+	assert.NoError(t, c.Claim("b"))
+	assert.NoError(t, c.Claim("c"))
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, c.Claim("d"))
+	assert.Len(t, c.claimed, 1)
+}
+
+func TestTxIDCacheSeed(t *testing.T) {
+	rl, _ := ramledger.New(10).GetOrCreate(testChannelID)
+	rl.Append(makeTxIDBlock(0))
+	for i := 1; i <= 5; i++ {
+		assert.NoError(t, rl.Append(ledger.CreateNextBlock(rl, makeTxIDEnvelopes(fmt.Sprintf("tx%da", i), fmt.Sprintf("tx%db", i)))))
+	}
+
+	c := NewTxIDCache(5, time.Minute)
+	c.Seed(rl)
+	assert.Len(t, c.ordered, 5)
+	for _, txID := range []string{"tx3b", "tx4a", "tx4b", "tx5a", "tx5b"} {
+		assert.Equal(t, ErrDuplicateTxID, c.Claim(txID), "Expected %s to be seeded", txID)
+	}
+	assert.NoError(t, c.Claim("tx3a"), "Expected the oldest TxIDs to be evicted")
+
+	c = NewTxIDCache(100, time.Minute)
+	c.Seed(rl)
+	assert.Len(t, c.ordered, 10)
+}
@@ -7,14 +7,22 @@ SPDX-License-Identifier: Apache-2.0
 package multichannel
 
 import (
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
+	"github.com/hyperledger/fabric/common/configtx"
 	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
 	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/metrics"
 	"github.com/hyperledger/fabric/orderer/common/blockcutter"
 	"github.com/hyperledger/fabric/orderer/common/ledger"
 	"github.com/hyperledger/fabric/orderer/common/msgprocessor"
 	"github.com/hyperledger/fabric/orderer/consensus"
 	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
 )
 
 // ChainSupport holds the resources for a particular channel.
@@ -23,9 +31,24 @@ type ChainSupport struct {
 	configtxapi.Manager
 	msgprocessor.Processor
 	*BlockWriter
-	consensus.Chain
 	cutter blockcutter.Receiver
 	crypto.LocalSigner
+	txIDs       *msgprocessor.TxIDCache
+	rateLimiter *msgprocessor.RateLimiter
+	consenters  map[string]consensus.Consenter
+
+	// chainLock protects the chain, which is replaced when the channel migrates
+	// to another consensus type, and the pending hand off
+	chainLock      sync.RWMutex
+	chain          consensus.Chain
+	pendingHandOff *handOff
+}
+
+// handOff records the config block migrating the channel to another consensus
+// type, after which the chain is handed to the consenter of that type
+type handOff struct {
+	blockNumber   uint64
+	consensusType string
 }
 
 func newChainSupport(
@@ -50,7 +73,13 @@ func newChainSupport(
 		LocalSigner:     signer,
 		cutter:          blockcutter.NewReceiverImpl(ledgerResources.SharedConfig()),
 		Manager:         ledgerResources.ConfigtxManager(),
+		txIDs:           msgprocessor.NewTxIDCache(registrar.txIDCacheSize, msgprocessor.DefaultTxIDClaimTimeout),
+		consenters:      consenters,
 	}
+	cs.txIDs.Seed(ledgerResources)
+	cs.rateLimiter = msgprocessor.NewRateLimiter(ledgerResources.SharedConfig(), metrics.NewRootScope().SubScope("broadcast").Tagged(map[string]string{
+		"channel": cs.ChainID(),
+	}))
 
 	// Set up the msgprocessor
 	cs.Processor = msgprocessor.NewStandardChannel(cs, msgprocessor.CreateStandardChannelFilters(cs))
@@ -65,7 +94,7 @@ func newChainSupport(
 		logger.Panicf("Error retrieving consenter of type: %s", consenterType)
 	}
 
-	cs.Chain, err = consenter.HandleChain(cs, metadata)
+	cs.chain, err = consenter.HandleChain(cs, metadata)
 	if err != nil {
 		logger.Panicf("[channel: %s] Error creating consenter: %s", cs.ChainID(), err)
 	}
@@ -79,13 +108,236 @@ func (cs *ChainSupport) Reader() ledger.Reader {
 	return cs
 }
 
+// TxIDCache returns the cache of the TxIDs ordered on this channel, or nil if disabled.
+func (cs *ChainSupport) TxIDCache() *msgprocessor.TxIDCache {
+	return cs.txIDs
+}
+
+// RateLimiter returns the rate limiter of the messages broadcast on this channel.
+func (cs *ChainSupport) RateLimiter() *msgprocessor.RateLimiter {
+	return cs.rateLimiter
+}
+
+// Append writes a block to the ledger, and records its TxIDs in the cache.  Once the config block
+// migrating the channel to another consensus type is written, it hands the chain to the new consenter.
+func (cs *ChainSupport) Append(block *cb.Block) error {
+	if err := cs.ledgerResources.Append(block); err != nil {
+		return err
+	}
+	cs.txIDs.AddBlock(block)
+
+	cs.chainLock.Lock()
+	pending := cs.pendingHandOff
+	if pending != nil && pending.blockNumber == block.Header.Number {
+		cs.pendingHandOff = nil
+	} else {
+		pending = nil
+	}
+	cs.chainLock.Unlock()
+
+	if pending != nil {
+		// The current consenter may be the one appending the block, so it is halted asynchronously
+		go cs.handOffTo(pending.consensusType, block)
+	}
+	return nil
+}
+
+// ProcessConfigUpdateMsg validates a config update as the channel processor does, and additionally
+// rejects it if it migrates the channel to a consensus type this orderer has no consenter for.
+func (cs *ChainSupport) ProcessConfigUpdateMsg(env *cb.Envelope) (*cb.Envelope, uint64, error) {
+	config, configSeq, err := cs.Processor.ProcessConfigUpdateMsg(env)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if _, _, err := cs.migrationTarget(config); err != nil {
+		return nil, 0, err
+	}
+	return config, configSeq, nil
+}
+
+// ValidateMigration rejects a config migrating the channel to a consenter which cannot take over the
+// channel.  Unlike ProcessConfigUpdateMsg, it is only invoked at ingress, as the consenter may have to
+// reach external systems to tell, so the consenters revalidating config messages never invoke it.
+func (cs *ChainSupport) ValidateMigration(config *cb.Envelope) error {
+	consensusType, consenter, err := cs.migrationTarget(config)
+	if err != nil || consenter == nil {
+		return err
+	}
+	validator, ok := consenter.(consensus.MigrationValidator)
+	if !ok {
+		return nil
+	}
+
+	proposed, err := channelconfig.New(config, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create config manager and handlers")
+	}
+	ordererConfig, ok := proposed.OrdererConfig()
+	if !ok {
+		return errors.New("config update has no orderer config")
+	}
+	if err := validator.ValidateMigration(cs, ordererConfig); err != nil {
+		return errors.Wrapf(err, "cannot migrate to consensus type %s", consensusType)
+	}
+	return nil
+}
+
+// migrationTarget returns the consensus type the config migrates the channel to, and its consenter,
+// or a nil consenter if the config does not change the consensus type of the channel.  It returns an
+// error if this orderer has no consenter for the consensus type.
+func (cs *ChainSupport) migrationTarget(config *cb.Envelope) (string, consensus.Consenter, error) {
+	consensusType, err := consensusTypeOf(config)
+	if err != nil {
+		return "", nil, err
+	}
+	if consensusType == "" || consensusType == cs.SharedConfig().ConsensusType() {
+		return "", nil, nil
+	}
+	consenter, ok := cs.consenters[consensusType]
+	if !ok {
+		return "", nil, errors.Errorf("cannot migrate to consensus type %s, which this orderer does not support", consensusType)
+	}
+	return consensusType, consenter, nil
+}
+
+// consensusTypeOf returns the consensus type set by a CONFIG message, or the empty string for the
+// other messages, such as the ORDERER_TRANSACTION messages creating new channels
+func consensusTypeOf(config *cb.Envelope) (string, error) {
+	payload, err := utils.UnmarshalPayload(config.Payload)
+	if err != nil {
+		return "", err
+	}
+	if payload.Header == nil {
+		return "", errors.New("config message has no header")
+	}
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return "", err
+	}
+	if chdr.Type != int32(cb.HeaderType_CONFIG) {
+		return "", nil
+	}
+
+	configEnvelope, err := configtx.UnmarshalConfigEnvelope(payload.Data)
+	if err != nil {
+		return "", err
+	}
+	ordererGroup, ok := configEnvelope.GetConfig().GetChannelGroup().GetGroups()[channelconfig.OrdererGroupKey]
+	if !ok {
+		return "", nil
+	}
+	value, ok := ordererGroup.Values[channelconfig.ConsensusTypeKey]
+	if !ok {
+		return "", nil
+	}
+	consensusType := &ab.ConsensusType{}
+	if err := proto.Unmarshal(value.Value, consensusType); err != nil {
+		return "", errors.Wrap(err, "config message has an invalid consensus type")
+	}
+	return consensusType.Type, nil
+}
+
+// ProcessConfigBlock applies the config inside a config block, which the consenter then appends.
+func (cs *ChainSupport) ProcessConfigBlock(block *cb.Block) {
+	cs.processConfigBlock(block)
+}
+
+// WriteConfigBlock applies the config inside a config block, and writes the block asynchronously.
+func (cs *ChainSupport) WriteConfigBlock(block *cb.Block, encodedMetadataValue []byte) {
+	if cs.processConfigBlock(block) {
+		encodedMetadataValue = nil
+	}
+	cs.BlockWriter.WriteBlock(block, encodedMetadataValue)
+}
+
+// processConfigBlock applies the config inside a config block, and returns whether it migrates the
+// channel to another consensus type.  If so, this block is the last one of the current consenter:
+// its consenter metadata is dropped, as the new consenter could not make sense of it, and the chain
+// is handed off once the block is appended.
+func (cs *ChainSupport) processConfigBlock(block *cb.Block) bool {
+	consensusType := cs.SharedConfig().ConsensusType()
+	cs.BlockWriter.ProcessConfigBlock(block)
+	newConsensusType := cs.SharedConfig().ConsensusType()
+	if newConsensusType == consensusType {
+		return false
+	}
+
+	logger.Infof("[channel: %s] Block %d migrates the channel from consensus type %s to %s", cs.ChainID(), block.Header.Number, consensusType, newConsensusType)
+	block.Metadata.Metadata[cb.BlockMetadataIndex_ORDERER] = nil
+
+	cs.chainLock.Lock()
+	cs.pendingHandOff = &handOff{blockNumber: block.Header.Number, consensusType: newConsensusType}
+	cs.chainLock.Unlock()
+	return true
+}
+
+// handOffTo halts the current consenter, and hands the chain to the consenter of the given type,
+// which resumes from the metadata of the last block.
+func (cs *ChainSupport) handOffTo(consensusType string, lastBlock *cb.Block) {
+	consenter, ok := cs.consenters[consensusType]
+	if !ok {
+		logger.Panicf("[channel: %s] Error retrieving consenter of type: %s", cs.ChainID(), consensusType)
+	}
+
+	metadata, err := utils.GetMetadataFromBlock(lastBlock, cb.BlockMetadataIndex_ORDERER)
+	if err != nil {
+		logger.Panicf("[channel: %s] Error extracting orderer metadata: %s", cs.ChainID(), err)
+	}
+
+	cs.currentChain().Halt()
+
+	chain, err := consenter.HandleChain(cs, metadata)
+	if err != nil {
+		logger.Panicf("[channel: %s] Error creating consenter: %s", cs.ChainID(), err)
+	}
+
+	cs.chainLock.Lock()
+	cs.chain = chain
+	cs.chainLock.Unlock()
+
+	chain.Start()
+	logger.Infof("[channel: %s] Handed the chain to the %s consenter after block %d", cs.ChainID(), consensusType, lastBlock.Header.Number)
+}
+
+func (cs *ChainSupport) currentChain() consensus.Chain {
+	cs.chainLock.RLock()
+	defer cs.chainLock.RUnlock()
+	return cs.chain
+}
+
+// Order passes a normal message to the consenter of the channel.
+func (cs *ChainSupport) Order(env *cb.Envelope, configSeq uint64) error {
+	return cs.currentChain().Order(env, configSeq)
+}
+
+// Configure passes a config message to the consenter of the channel.
+func (cs *ChainSupport) Configure(configUpdate, config *cb.Envelope, configSeq uint64) error {
+	return cs.currentChain().Configure(configUpdate, config, configSeq)
+}
+
+// Errored returns a channel which closes when the consenter of the channel has errored.
+func (cs *ChainSupport) Errored() <-chan struct{} {
+	return cs.currentChain().Errored()
+}
+
+// Start starts the consenter of the channel.
+func (cs *ChainSupport) Start() {
+	cs.currentChain().Start()
+}
+
+// Halt halts the consenter of the channel.
+func (cs *ChainSupport) Halt() {
+	cs.currentChain().Halt()
+}
+
 // Signer returns the crypto.Localsigner for this channel.
 func (cs *ChainSupport) Signer() crypto.LocalSigner {
 	return cs
 }
 
 func (cs *ChainSupport) start() {
-	cs.Chain.Start()
+	cs.Start()
 }
 
 // BlockCutter returns the blockcutter.Receiver instance for this channel.
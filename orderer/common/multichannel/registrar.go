@@ -11,6 +11,7 @@ package multichannel
 
 import (
 	"fmt"
+	"sort"
 
 	channelconfig "github.com/hyperledger/fabric/common/config/channel"
 	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
@@ -58,6 +59,7 @@ type Registrar struct {
 	systemChannelID string
 	systemChannel   *ChainSupport
 	templator       msgprocessor.ChannelConfigTemplator
+	txIDCacheSize   int
 }
 
 func getConfigTx(reader ledger.Reader) *cb.Envelope {
@@ -74,13 +76,15 @@ func getConfigTx(reader ledger.Reader) *cb.Envelope {
 	return utils.ExtractEnvelopeOrPanic(configBlock, 0)
 }
 
-// NewRegistrar produces an instance of a *Registrar.
-func NewRegistrar(ledgerFactory ledger.Factory, consenters map[string]consensus.Consenter, signer crypto.LocalSigner) *Registrar {
+// NewRegistrar produces an instance of a *Registrar.  Each channel caches up to txIDCacheSize of its last
+// TxIDs to reject duplicates at ingress, none if it is not positive.
+func NewRegistrar(ledgerFactory ledger.Factory, consenters map[string]consensus.Consenter, signer crypto.LocalSigner, txIDCacheSize int) *Registrar {
 	r := &Registrar{
 		chains:        make(map[string]*ChainSupport),
 		ledgerFactory: ledgerFactory,
 		consenters:    consenters,
 		signer:        signer,
+		txIDCacheSize: txIDCacheSize,
 	}
 
 	existingChains := ledgerFactory.ChainIDs()
@@ -109,7 +113,7 @@ func NewRegistrar(ledgerFactory ledger.Factory, consenters map[string]consensus.
 			chain.Processor = msgprocessor.NewSystemChannel(chain, r.templator, msgprocessor.CreateSystemChannelFilters(r, chain))
 
 			// Retrieve genesis block to log its hash. See FAB-5450 for the purpose
-			iter, pos := rl.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Oldest{Oldest: &ab.SeekOldest{}}})
+			iter, pos := rl.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: 0}}})
 			defer iter.Close()
 			if pos != uint64(0) {
 				logger.Panicf("Error iterating over system channel: '%s', expected position 0, got %d", chainID, pos)
@@ -230,6 +234,17 @@ func (r *Registrar) ChannelsCount() int {
 	return len(r.chains)
 }
 
+// ChannelIDs returns the IDs of the current channels, sorted.
+func (r *Registrar) ChannelIDs() []string {
+	chains := r.chains
+	chainIDs := make([]string, 0, len(chains))
+	for chainID := range chains {
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Strings(chainIDs)
+	return chainIDs
+}
+
 // NewChannelConfig produces a new template channel configuration based on the system channel's current config.
 func (r *Registrar) NewChannelConfig(envConfigUpdate *cb.Envelope) (configtxapi.Manager, error) {
 	return r.templator.NewChannelConfig(envConfigUpdate)
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package multichannel
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
@@ -17,9 +18,11 @@ import (
 	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
 	genesisconfig "github.com/hyperledger/fabric/common/tools/configtxgen/localconfig"
 	"github.com/hyperledger/fabric/common/tools/configtxgen/provisional"
+	"github.com/hyperledger/fabric/common/tools/configtxlator/update"
 	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/orderer/common/ledger"
 	ramledger "github.com/hyperledger/fabric/orderer/common/ledger/ram"
+	"github.com/hyperledger/fabric/orderer/common/msgprocessor"
 	"github.com/hyperledger/fabric/orderer/consensus"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
@@ -27,6 +30,7 @@ import (
 
 	mmsp "github.com/hyperledger/fabric/common/mocks/msp"
 	logging "github.com/op/go-logging"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -108,7 +112,7 @@ func TestNoSystemChain(t *testing.T) {
 	consenters := make(map[string]consensus.Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
 
-	assert.Panics(t, func() { NewRegistrar(lf, consenters, mockCrypto()) }, "Should have panicked when starting without a system chain")
+	assert.Panics(t, func() { NewRegistrar(lf, consenters, mockCrypto(), 0) }, "Should have panicked when starting without a system chain")
 }
 
 // This test checks to make sure that the orderer refuses to come up if there are multiple system channels
@@ -126,7 +130,7 @@ func TestMultiSystemChannel(t *testing.T) {
 	consenters := make(map[string]consensus.Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
 
-	assert.Panics(t, func() { NewRegistrar(lf, consenters, mockCrypto()) }, "Two system channels should have caused panic")
+	assert.Panics(t, func() { NewRegistrar(lf, consenters, mockCrypto(), 0) }, "Two system channels should have caused panic")
 }
 
 // This test essentially brings the entire system up and is ultimately what main.go will replicate
@@ -136,7 +140,7 @@ func TestManagerImpl(t *testing.T) {
 	consenters := make(map[string]consensus.Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
 
-	manager := NewRegistrar(lf, consenters, mockCrypto())
+	manager := NewRegistrar(lf, consenters, mockCrypto(), 0)
 
 	_, ok := manager.GetChain("Fake")
 	assert.False(t, ok, "Should not have found a chain that was not created")
@@ -167,6 +171,42 @@ func TestManagerImpl(t *testing.T) {
 	}
 }
 
+// This test checks that the TxIDs of the blocks written are rejected as duplicates, also once restarted
+func TestDuplicateTxID(t *testing.T) {
+	lf, rl := NewRAMLedgerAndFactory(10)
+
+	consenters := make(map[string]consensus.Consenter)
+	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
+
+	manager := NewRegistrar(lf, consenters, mockCrypto(), 100)
+	chainSupport, _ := manager.GetChain(provisional.TestChainID)
+
+	for i := 0; i < int(conf.Orderer.BatchSize.MaxMessageCount); i++ {
+		assert.NoError(t, chainSupport.ClaimTxID(fmt.Sprintf("tx%d", i)))
+		chainSupport.Order(makeNormalTx(provisional.TestChainID, i), 0)
+	}
+	assert.Equal(t, msgprocessor.ErrDuplicateTxID, errors.Cause(chainSupport.ClaimTxID("tx0")), "Should have rejected a TxID being ordered")
+
+	it, _ := rl.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: 1}}})
+	defer it.Close()
+	select {
+	case <-it.ReadyChan():
+	case <-time.After(time.Second):
+		t.Fatalf("Block 1 not produced after timeout")
+	}
+	// Wait for the block to be committed
+	chainSupport.BlockWriter.committingBlock.Lock()
+	chainSupport.BlockWriter.committingBlock.Unlock()
+
+	chainSupport.ReleaseTxID("tx0")
+	assert.Equal(t, msgprocessor.ErrDuplicateTxID, errors.Cause(chainSupport.ClaimTxID("tx0")), "Should have rejected a TxID ordered")
+
+	manager = NewRegistrar(lf, consenters, mockCrypto(), 100)
+	chainSupport, _ = manager.GetChain(provisional.TestChainID)
+	assert.Equal(t, msgprocessor.ErrDuplicateTxID, errors.Cause(chainSupport.ClaimTxID("tx1")), "Should have seeded the TxIDs from the ledger")
+	assert.NoError(t, chainSupport.ClaimTxID("unknown"))
+}
+
 // This test brings up the entire system, with the mock consenter, including the broadcasters etc. and creates a new chain
 func TestNewChain(t *testing.T) {
 	expectedLastConfigBlockNumber := uint64(0)
@@ -178,7 +218,7 @@ func TestNewChain(t *testing.T) {
 	consenters := make(map[string]consensus.Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
 
-	manager := NewRegistrar(lf, consenters, mockCrypto())
+	manager := NewRegistrar(lf, consenters, mockCrypto(), 0)
 
 	envConfigUpdate, err := channelconfig.MakeChainCreationTransaction(newChainID, genesisconfig.SampleConsortiumName, mockSigningIdentity)
 	assert.NoError(t, err, "Constructing chain creation tx")
@@ -223,6 +263,7 @@ func TestNewChain(t *testing.T) {
 	if !ok {
 		t.Fatalf("Should have gotten new chain which was created")
 	}
+	assert.Equal(t, []string{newChainID, provisional.TestChainID}, manager.ChannelIDs(), "Expected the new chain to be listed")
 
 	messages := make([]*cb.Envelope, conf.Orderer.BatchSize.MaxMessageCount)
 	for i := 0; i < int(conf.Orderer.BatchSize.MaxMessageCount); i++ {
@@ -280,3 +321,192 @@ func testLastConfigBlockNumber(t *testing.T, block *cb.Block, expectedBlockNumbe
 	assert.NoError(t, err, "LAST_CONFIG metadata item should carry last config value")
 	assert.Equal(t, expectedBlockNumber, lastConfig.Index, "LAST_CONFIG value should point to last config block")
 }
+
+type handOffConsenter struct {
+	mockConsenter
+	metadata chan *cb.Metadata
+}
+
+func (hc *handOffConsenter) HandleChain(support consensus.ConsenterSupport, metadata *cb.Metadata) (consensus.Chain, error) {
+	hc.metadata <- metadata
+	return hc.mockConsenter.HandleChain(support, metadata)
+}
+
+// validatingConsenter only takes over migrated channels if it has no leftovers from a previous period
+type validatingConsenter struct {
+	handOffConsenter
+	leftovers   bool
+	validations int
+}
+
+func (vc *validatingConsenter) ValidateMigration(support consensus.ConsenterSupport, ordererConfig channelconfig.Orderer) error {
+	vc.validations++
+	if vc.leftovers {
+		return fmt.Errorf("channel %s has leftovers", support.ChainID())
+	}
+	return nil
+}
+
+// updateConsensusType proposes a config update setting the consensus type of the channel, and orders it
+// as the broadcast handler does
+func updateConsensusType(cs *ChainSupport, consensusType *ab.ConsensusType) error {
+	configUpdateEnv, err := consensusTypeUpdate(cs, consensusType)
+	if err != nil {
+		return err
+	}
+
+	config, configSeq, err := cs.ProcessConfigUpdateMsg(configUpdateEnv)
+	if err != nil {
+		return err
+	}
+	if err := cs.ValidateMigration(config); err != nil {
+		return err
+	}
+	return cs.Configure(configUpdateEnv, config, configSeq)
+}
+
+// consensusTypeUpdate returns a config update setting the consensus type of the channel
+func consensusTypeUpdate(cs *ChainSupport, consensusType *ab.ConsensusType) (*cb.Envelope, error) {
+	original := cs.ConfigEnvelope().Config
+	updated := proto.Clone(original).(*cb.Config)
+	updated.ChannelGroup.Groups[channelconfig.OrdererGroupKey].Values[channelconfig.ConsensusTypeKey].Value = utils.MarshalOrPanic(consensusType)
+
+	configUpdate, err := update.Compute(original, updated)
+	if err != nil {
+		return nil, err
+	}
+	configUpdate.ChannelId = cs.ChainID()
+	return utils.CreateSignedEnvelope(cb.HeaderType_CONFIG_UPDATE, cs.ChainID(), mockCrypto(), &cb.ConfigUpdateEnvelope{
+		ConfigUpdate: utils.MarshalOrPanic(configUpdate),
+	}, msgVersion, epoch)
+}
+
+func waitForBlock(t *testing.T, rl ledger.Reader, number uint64) *cb.Block {
+	it, _ := rl.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: number}}})
+	defer it.Close()
+	select {
+	case <-it.ReadyChan():
+		block, status := it.Next()
+		assert.Equal(t, cb.Status_SUCCESS, status, "Could not retrieve block %d", number)
+		return block
+	case <-time.After(time.Second):
+		t.Fatalf("Block %d not produced after timeout", number)
+		return nil
+	}
+}
+
+// This test migrates a channel to another consensus type, which is only possible in maintenance
+func TestConsensusTypeMigration(t *testing.T) {
+	lf, rl := NewRAMLedgerAndFactory(10)
+
+	migrated := &handOffConsenter{metadata: make(chan *cb.Metadata, 1)}
+	consenters := make(map[string]consensus.Consenter)
+	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
+	consenters["migrated"] = migrated
+
+	manager := NewRegistrar(lf, consenters, mockCrypto(), 0)
+	chainSupport, _ := manager.GetChain(provisional.TestChainID)
+
+	err := updateConsensusType(chainSupport, &ab.ConsensusType{Type: "migrated"})
+	assert.Error(t, err, "Should not migrate outside of maintenance")
+
+	assert.NoError(t, updateConsensusType(chainSupport, &ab.ConsensusType{Type: conf.Orderer.OrdererType, State: ab.ConsensusType_STATE_MAINTENANCE}))
+	waitForBlock(t, rl, 1)
+	_, err = chainSupport.ProcessNormalMsg(makeNormalTx(provisional.TestChainID, 0))
+	assert.Equal(t, msgprocessor.ErrMaintenance, errors.Cause(err), "Should reject normal messages in maintenance")
+
+	err = updateConsensusType(chainSupport, &ab.ConsensusType{Type: "unknown", State: ab.ConsensusType_STATE_MAINTENANCE})
+	assert.Error(t, err, "Should not migrate to an unsupported consensus type")
+
+	assert.NoError(t, updateConsensusType(chainSupport, &ab.ConsensusType{Type: "migrated", State: ab.ConsensusType_STATE_MAINTENANCE}))
+	block := waitForBlock(t, rl, 2)
+	assert.Empty(t, block.Metadata.Metadata[cb.BlockMetadataIndex_ORDERER], "Should not carry the metadata of the previous consenter")
+	select {
+	case metadata := <-migrated.metadata:
+		assert.Empty(t, metadata.Value, "Should hand off the chain with empty metadata")
+	case <-time.After(time.Second):
+		t.Fatalf("Chain not handed off after timeout")
+	}
+	assert.Equal(t, "migrated", chainSupport.SharedConfig().ConsensusType())
+
+	assert.NoError(t, updateConsensusType(chainSupport, &ab.ConsensusType{Type: "migrated"}))
+	waitForBlock(t, rl, 3)
+	for i := 0; i < int(conf.Orderer.BatchSize.MaxMessageCount); i++ {
+		assert.NoError(t, chainSupport.Order(makeNormalTx(provisional.TestChainID, i), 0))
+	}
+	block = waitForBlock(t, rl, 4)
+	assert.Len(t, block.Data.Data, int(conf.Orderer.BatchSize.MaxMessageCount), "Should order through the new consenter")
+
+	NewRegistrar(lf, consenters, mockCrypto(), 0)
+	select {
+	case <-migrated.metadata:
+	case <-time.After(time.Second):
+		t.Fatalf("Chain not restarted on the new consenter after timeout")
+	}
+}
+
+// This test migrates a channel to a consenter which validates the migration before taking it over
+func TestConsensusTypeMigrationValidated(t *testing.T) {
+	lf, rl := NewRAMLedgerAndFactory(10)
+
+	migrated := &validatingConsenter{handOffConsenter: handOffConsenter{metadata: make(chan *cb.Metadata, 1)}, leftovers: true}
+	consenters := make(map[string]consensus.Consenter)
+	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
+	consenters["migrated"] = migrated
+
+	manager := NewRegistrar(lf, consenters, mockCrypto(), 0)
+	chainSupport, _ := manager.GetChain(provisional.TestChainID)
+
+	assert.NoError(t, updateConsensusType(chainSupport, &ab.ConsensusType{Type: conf.Orderer.OrdererType, State: ab.ConsensusType_STATE_MAINTENANCE}))
+	waitForBlock(t, rl, 1)
+
+	err := updateConsensusType(chainSupport, &ab.ConsensusType{Type: "migrated", State: ab.ConsensusType_STATE_MAINTENANCE})
+	assert.Error(t, err, "Should not migrate to a consenter which cannot take over the channel")
+	assert.Equal(t, uint64(2), rl.Height(), "Should not order the rejected migration")
+
+	migrated.leftovers = false
+	assert.NoError(t, updateConsensusType(chainSupport, &ab.ConsensusType{Type: "migrated", State: ab.ConsensusType_STATE_MAINTENANCE}))
+	waitForBlock(t, rl, 2)
+	select {
+	case <-migrated.metadata:
+	case <-time.After(time.Second):
+		t.Fatalf("Chain not handed off after timeout")
+	}
+	assert.Equal(t, "migrated", chainSupport.SharedConfig().ConsensusType())
+}
+
+// This test checks that the migration is only validated at ingress, so that the consenters revalidating the
+// config update after ordering it reach the same outcome on every orderer
+func TestConsensusTypeMigrationRevalidated(t *testing.T) {
+	lf, rl := NewRAMLedgerAndFactory(10)
+
+	migrated := &validatingConsenter{handOffConsenter: handOffConsenter{metadata: make(chan *cb.Metadata, 1)}}
+	consenters := make(map[string]consensus.Consenter)
+	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
+	consenters["migrated"] = migrated
+
+	manager := NewRegistrar(lf, consenters, mockCrypto(), 0)
+	chainSupport, _ := manager.GetChain(provisional.TestChainID)
+
+	assert.NoError(t, updateConsensusType(chainSupport, &ab.ConsensusType{Type: conf.Orderer.OrdererType, State: ab.ConsensusType_STATE_MAINTENANCE}))
+	waitForBlock(t, rl, 1)
+
+	configUpdateEnv, err := consensusTypeUpdate(chainSupport, &ab.ConsensusType{Type: "migrated", State: ab.ConsensusType_STATE_MAINTENANCE})
+	assert.NoError(t, err)
+	config, _, err := chainSupport.ProcessConfigUpdateMsg(configUpdateEnv)
+	assert.NoError(t, err)
+	assert.NoError(t, chainSupport.ValidateMigration(config))
+	assert.Equal(t, 1, migrated.validations, "Should validate the migration at ingress")
+
+	// The leftovers appearing once the config update is ordered do not change the outcome of the revalidation
+	migrated.leftovers = true
+	_, _, err = chainSupport.ProcessConfigUpdateMsg(configUpdateEnv)
+	assert.NoError(t, err, "Should not validate the migration again on revalidation")
+	assert.Equal(t, 1, migrated.validations, "Should not validate the migration again on revalidation")
+
+	// A migration to a consensus type this orderer does not support is still rejected on revalidation
+	configUpdateEnv, err = consensusTypeUpdate(chainSupport, &ab.ConsensusType{Type: "unknown", State: ab.ConsensusType_STATE_MAINTENANCE})
+	assert.NoError(t, err)
+	_, _, err = chainSupport.ProcessConfigUpdateMsg(configUpdateEnv)
+	assert.Error(t, err)
+}
@@ -157,6 +157,7 @@ func makeNormalTx(chainID string, i int) *cb.Envelope {
 			ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
 				Type:      int32(cb.HeaderType_ENDORSER_TRANSACTION),
 				ChannelId: chainID,
+				TxId:      fmt.Sprintf("tx%d", i),
 			}),
 			SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{}),
 		},
@@ -12,9 +12,7 @@ import (
 	"sync"
 	"time"
 
-	channelconfig "github.com/hyperledger/fabric/common/config/channel"
 	"github.com/hyperledger/fabric/common/localmsp"
-	genesisconfig "github.com/hyperledger/fabric/common/tools/configtxgen/localconfig"
 	"github.com/hyperledger/fabric/msp"
 	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/orderer/common/localconfig"
@@ -106,18 +104,9 @@ func RandomID(num int) string {
 
 // CreateChannel creates a channel with randomly generated ID of length 10
 func CreateChannel(server *BenchmarkServer) string {
-	client := server.CreateBroadcastClient()
-	defer client.Close()
-
-	channelID := RandomID(10)
-	createChannelTx, _ := channelconfig.MakeChainCreationTransaction(
-		channelID,
-		genesisconfig.SampleConsortiumName,
-		signer,
-		genesisconfig.SampleOrgName)
-	client.SendRequest(createChannelTx)
-	if client.GetResponse().Status != cb.Status_SUCCESS {
-		logger.Panicf("Failed to create channel: %s", channelID)
+	channelID, err := createChannel(server)
+	if err != nil {
+		logger.Panicf("Failed to create channel: %s", err)
 	}
 	return channelID
 }
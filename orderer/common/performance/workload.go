@@ -0,0 +1,471 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package performance
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
+	"github.com/hyperledger/fabric/common/localmsp"
+	genesisconfig "github.com/hyperledger/fabric/common/tools/configtxgen/localconfig"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	protosutils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+)
+
+// Workload describes the load RunWorkload generates against a server
+type Workload struct {
+	Channels         int `json:"channels"`         // channels created for the run
+	BroadcastClients int `json:"broadcastClients"` // concurrent broadcast clients per channel
+	DeliverClients   int `json:"deliverClients"`   // concurrent deliver clients per channel, reading every block once ordered
+	MessageSize      int `json:"messageSizeKB"`    // size of the transactions in KB
+	Transactions     int `json:"transactions"`     // transactions in total, evenly spread among the broadcast clients
+}
+
+// Validate checks that the workload can be run
+func (w Workload) Validate() error {
+	switch {
+	case w.Channels < 1:
+		return errors.New("at least one channel is required")
+	case w.BroadcastClients < 1:
+		return errors.New("at least one broadcast client per channel is required")
+	case w.DeliverClients < 0:
+		return errors.New("the number of deliver clients cannot be negative")
+	case w.MessageSize < 1:
+		return errors.New("the message size must be at least 1 KB")
+	case w.Transactions < w.Channels*w.BroadcastClients:
+		return errors.Errorf("at least one transaction per broadcast client, %d in total, is required", w.Channels*w.BroadcastClients)
+	}
+	return nil
+}
+
+// Latency holds the percentiles of the time elapsed between the broadcast of
+// the transactions and their delivery in a block, in milliseconds
+type Latency struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+// BlockFill describes how full the blocks carrying the transactions were
+type BlockFill struct {
+	Blocks           int     `json:"blocks"`
+	MaxMessageCount  uint32  `json:"maxMessageCount"`
+	MinTransactions  int     `json:"minTransactions"`
+	MeanTransactions float64 `json:"meanTransactions"`
+	MaxTransactions  int     `json:"maxTransactions"`
+	MeanFill         float64 `json:"meanFill"` // mean ratio of the transactions to the MaxMessageCount
+}
+
+// DeliverStats describes the reading of the blocks by the deliver clients
+type DeliverStats struct {
+	Blocks     int     `json:"blocks"`
+	Elapsed    float64 `json:"elapsedSeconds"`
+	Throughput float64 `json:"blocksPerSecond"`
+}
+
+// Report is the outcome of a workload run
+type Report struct {
+	ConsensusType string `json:"consensusType"`
+	Workload
+	Elapsed    float64       `json:"elapsedSeconds"`
+	Throughput float64       `json:"transactionsPerSecond"`
+	Latency    Latency       `json:"latencyMilliseconds"`
+	BlockFill  BlockFill     `json:"blockFill"`
+	Deliver    *DeliverStats `json:"deliver,omitempty"`
+}
+
+// Write writes the report to w, either as "text" or as "json"
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	case "text":
+	default:
+		return errors.Errorf("unknown output format %s", format)
+	}
+
+	_, err := fmt.Fprintf(w,
+		"Consensus type:      %s\n"+
+			"Workload:            %d tx of %d KB over %d channels, %d broadcast and %d deliver clients per channel\n"+
+			"Throughput:          %.1f tx/s (%.2fs)\n"+
+			"Latency:             p50 %.1fms  p90 %.1fms  p99 %.1fms  max %.1fms\n"+
+			"Blocks:              %d, %.1f tx per block (min %d, max %d), %.1f%% full of %d messages\n",
+		r.ConsensusType,
+		r.Transactions, r.MessageSize, r.Channels, r.BroadcastClients, r.DeliverClients,
+		r.Throughput, r.Elapsed,
+		r.Latency.P50, r.Latency.P90, r.Latency.P99, r.Latency.Max,
+		r.BlockFill.Blocks, r.BlockFill.MeanTransactions, r.BlockFill.MinTransactions, r.BlockFill.MaxTransactions, 100*r.BlockFill.MeanFill, r.BlockFill.MaxMessageCount)
+	if err != nil || r.Deliver == nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "Deliver throughput:  %.1f blk/s (%d blocks in %.2fs)\n", r.Deliver.Throughput, r.Deliver.Blocks, r.Deliver.Elapsed)
+	return err
+}
+
+// channelResult is what the tracker of a channel observed
+type channelResult struct {
+	channelID    string
+	lastBlock    uint64
+	transactions []int // per block
+	latencies    []time.Duration
+}
+
+// RunWorkload creates the channels of the workload on the server, broadcasts
+// the transactions and waits for all of them to be delivered in blocks, then
+// reads the blocks with the deliver clients. The system channel of the server
+// must be ready, and its consortium must allow the sample organization to
+// create channels.
+func RunWorkload(server *BenchmarkServer, workload Workload) (*Report, error) {
+	if err := workload.Validate(); err != nil {
+		return nil, err
+	}
+
+	clients := workload.Channels * workload.BroadcastClients
+	txPerClient := workload.Transactions / clients
+	// The transactions are evenly spread, so the remainder is not sent
+	workload.Transactions = txPerClient * clients
+	txPerChannel := txPerClient * workload.BroadcastClients
+
+	channelIDs := make([]string, workload.Channels)
+	for i := range channelIDs {
+		channelID, err := createChannel(server)
+		if err != nil {
+			return nil, err
+		}
+		channelIDs[i] = channelID
+	}
+	WaitForChannels(server, stoi(channelIDs)...)
+
+	ordererConfig, err := fetchOrdererConfig(server, channelIDs[0])
+	if err != nil {
+		return nil, err
+	}
+
+	// The transactions are signed ahead, so that the signing does not weigh on the measures
+	txs := make([][]*cb.Envelope, clients)
+	for c := range txs {
+		channelID := channelIDs[c/workload.BroadcastClients]
+		txs[c] = make([]*cb.Envelope, txPerClient)
+		for i := range txs[c] {
+			txs[c][i] = makeWorkloadTx(channelID, uint64(c*txPerClient+i), workload.MessageSize)
+		}
+	}
+	sent := make([]time.Time, workload.Transactions)
+
+	errs := make(chan error, clients+workload.Channels)
+	results := make(chan *channelResult, workload.Channels)
+	start := time.Now()
+
+	for _, channelID := range channelIDs {
+		go func(channelID string) {
+			result, err := trackChannel(server, channelID, txPerChannel, sent)
+			if err != nil {
+				errs <- errors.WithMessage(err, fmt.Sprintf("failed tracking channel %s", channelID))
+				return
+			}
+			results <- result
+		}(channelID)
+	}
+
+	for c := range txs {
+		go func(c int) {
+			client := server.CreateBroadcastClient()
+			defer func() {
+				client.Close()
+				<-client.Errors()
+			}()
+
+			for i, tx := range txs[c] {
+				sent[c*txPerClient+i] = time.Now()
+				client.SendRequest(tx)
+				if status := client.GetResponse().Status; status != cb.Status_SUCCESS {
+					errs <- errors.Errorf("broadcast rejected a transaction with status %s", status)
+					return
+				}
+			}
+		}(c)
+	}
+
+	var channelResults []*channelResult
+	for len(channelResults) < workload.Channels {
+		select {
+		case result := <-results:
+			channelResults = append(channelResults, result)
+		case err := <-errs:
+			return nil, err
+		}
+	}
+	elapsed := time.Since(start)
+
+	report := &Report{
+		ConsensusType: ordererConfig.ConsensusType(),
+		Workload:      workload,
+		Elapsed:       elapsed.Seconds(),
+		Throughput:    float64(workload.Transactions) / elapsed.Seconds(),
+		Latency:       latencyPercentiles(channelResults),
+		BlockFill:     blockFill(channelResults, ordererConfig.BatchSize().MaxMessageCount),
+	}
+
+	if workload.DeliverClients > 0 {
+		report.Deliver, err = deliverAll(server, workload.DeliverClients, channelResults)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// createChannel creates a channel with randomly generated ID of length 10
+func createChannel(server *BenchmarkServer) (string, error) {
+	client := server.CreateBroadcastClient()
+	defer func() {
+		client.Close()
+		<-client.Errors()
+	}()
+
+	channelID := RandomID(10)
+	createChannelTx, err := channelconfig.MakeChainCreationTransaction(
+		channelID,
+		genesisconfig.SampleConsortiumName,
+		signer,
+		genesisconfig.SampleOrgName)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to make the channel creation transaction")
+	}
+	client.SendRequest(createChannelTx)
+	if status := client.GetResponse().Status; status != cb.Status_SUCCESS {
+		return "", errors.Errorf("failed to create channel %s: %s", channelID, status)
+	}
+	return channelID, nil
+}
+
+// makeWorkloadTx creates a transaction of the given size in KB, whose payload
+// starts with its sequence number in the workload
+func makeWorkloadTx(channelID string, seq uint64, size int) *cb.Envelope {
+	payload := make([]byte, size*Kilo)
+	binary.BigEndian.PutUint64(payload, seq)
+	env, err := protosutils.CreateSignedEnvelope(
+		cb.HeaderType_ENDORSER_TRANSACTION,
+		channelID,
+		localmsp.NewSigner(),
+		&cb.Envelope{Payload: payload},
+		0,
+		0,
+	)
+	if err != nil {
+		panic(fmt.Errorf("Failed to create signed envelope because: %s", err))
+	}
+	return env
+}
+
+// workloadTxSeq returns the sequence number of a transaction made by makeWorkloadTx
+func workloadTxSeq(envBytes []byte) (uint64, error) {
+	env, err := protosutils.UnmarshalEnvelope(envBytes)
+	if err != nil {
+		return 0, err
+	}
+	payload, err := protosutils.UnmarshalPayload(env.Payload)
+	if err != nil {
+		return 0, err
+	}
+	tx, err := protosutils.UnmarshalEnvelope(payload.Data)
+	if err != nil {
+		return 0, err
+	}
+	if len(tx.Payload) < 8 {
+		return 0, errors.New("transaction does not carry a sequence number")
+	}
+	return binary.BigEndian.Uint64(tx.Payload), nil
+}
+
+// seekBlock seeks the single block of the given number, waiting for it to be written
+func seekBlock(client *DeliverClient, channelID string, number uint64) (*cb.Block, error) {
+	env, err := protosutils.CreateSignedEnvelope(
+		cb.HeaderType_DELIVER_SEEK_INFO,
+		channelID,
+		localmsp.NewSigner(),
+		&ab.SeekInfo{Start: seekSpecified(number), Stop: seekSpecified(number), Behavior: ab.SeekInfo_BLOCK_UNTIL_READY},
+		0,
+		0,
+	)
+	if err != nil {
+		return nil, err
+	}
+	client.SendRequest(env)
+
+	var block *cb.Block
+	for {
+		select {
+		case reply := <-client.ResponseChan:
+			if reply.GetBlock() != nil {
+				block = reply.GetBlock()
+				continue
+			}
+			if reply.GetStatus() != cb.Status_SUCCESS || block == nil {
+				return nil, errors.Errorf("deliver replied %s to the seek of block %d", reply.GetStatus(), number)
+			}
+			return block, nil
+		case err := <-client.ResultChan:
+			return nil, errors.Errorf("deliver ended before block %d: %v", number, err)
+		}
+	}
+}
+
+// fetchOrdererConfig returns the orderer config of a channel, from its genesis block
+func fetchOrdererConfig(server *BenchmarkServer, channelID string) (channelconfig.Orderer, error) {
+	client := server.CreateDeliverClient()
+	block, err := seekBlock(client, channelID, 0)
+	if err != nil {
+		return nil, err
+	}
+	closeDeliverClient(client)
+
+	configTx, err := protosutils.ExtractEnvelope(block, 0)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := channelconfig.New(configTx, nil)
+	if err != nil {
+		return nil, err
+	}
+	ordererConfig, ok := resources.OrdererConfig()
+	if !ok {
+		return nil, errors.Errorf("channel %s has no orderer config", channelID)
+	}
+	return ordererConfig, nil
+}
+
+// trackChannel reads the blocks of a channel as they are written, until the given
+// number of transactions was delivered, and measures their latency
+func trackChannel(server *BenchmarkServer, channelID string, transactions int, sent []time.Time) (*channelResult, error) {
+	client := server.CreateDeliverClient()
+	result := &channelResult{channelID: channelID}
+	for delivered := 0; delivered < transactions; {
+		result.lastBlock++
+		block, err := seekBlock(client, channelID, result.lastBlock)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+
+		for _, envBytes := range block.Data.Data {
+			seq, err := workloadTxSeq(envBytes)
+			if err != nil || seq >= uint64(len(sent)) {
+				return nil, errors.Errorf("block %d carries a transaction which is not part of the workload", block.Header.Number)
+			}
+			result.latencies = append(result.latencies, now.Sub(sent[seq]))
+		}
+		result.transactions = append(result.transactions, len(block.Data.Data))
+		delivered += len(block.Data.Data)
+	}
+	closeDeliverClient(client)
+	return result, nil
+}
+
+// closeDeliverClient closes a deliver client and waits for its handler to return
+func closeDeliverClient(client *DeliverClient) {
+	client.Close()
+	<-client.ResultChan
+}
+
+func latencyPercentiles(results []*channelResult) Latency {
+	var latencies []time.Duration
+	for _, result := range results {
+		latencies = append(latencies, result.latencies...)
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	percentile := func(p float64) float64 {
+		if len(latencies) == 0 {
+			return 0
+		}
+		i := int(math.Ceil(p*float64(len(latencies)))) - 1
+		if i < 0 {
+			i = 0
+		}
+		return latencies[i].Seconds() * 1000
+	}
+	return Latency{P50: percentile(0.5), P90: percentile(0.9), P99: percentile(0.99), Max: percentile(1)}
+}
+
+func blockFill(results []*channelResult, maxMessageCount uint32) BlockFill {
+	fill := BlockFill{MaxMessageCount: maxMessageCount, MinTransactions: math.MaxInt32}
+	total := 0
+	for _, result := range results {
+		for _, transactions := range result.transactions {
+			fill.Blocks++
+			total += transactions
+			if transactions < fill.MinTransactions {
+				fill.MinTransactions = transactions
+			}
+			if transactions > fill.MaxTransactions {
+				fill.MaxTransactions = transactions
+			}
+		}
+	}
+	if fill.Blocks == 0 {
+		fill.MinTransactions = 0
+		return fill
+	}
+	fill.MeanTransactions = float64(total) / float64(fill.Blocks)
+	if maxMessageCount > 0 {
+		fill.MeanFill = fill.MeanTransactions / float64(maxMessageCount)
+	}
+	return fill
+}
+
+// deliverAll reads every block of the channels with the given number of
+// concurrent deliver clients per channel
+func deliverAll(server *BenchmarkServer, clientsPerChannel int, results []*channelResult) (*DeliverStats, error) {
+	var wg sync.WaitGroup
+	errs := make(chan error, clientsPerChannel*len(results))
+	stats := &DeliverStats{}
+	start := time.Now()
+	for _, result := range results {
+		stats.Blocks += clientsPerChannel * int(result.lastBlock+1)
+		for c := 0; c < clientsPerChannel; c++ {
+			wg.Add(1)
+			go func(channelID string, lastBlock uint64) {
+				defer wg.Done()
+				status, err := SeekAllBlocks(server.CreateDeliverClient(), channelID, lastBlock)
+				if err != nil || status != cb.Status_SUCCESS {
+					errs <- errors.Errorf("failed reading the blocks of channel %s: %s %v", channelID, status, err)
+				}
+			}(result.channelID, result.lastBlock)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	if err := <-errs; err != nil {
+		return nil, err
+	}
+
+	elapsed := time.Since(start)
+	stats.Elapsed = elapsed.Seconds()
+	stats.Throughput = float64(stats.Blocks) / elapsed.Seconds()
+	return stats, nil
+}
+
+func stoi(s []string) []interface{} {
+	ret := make([]interface{}, len(s))
+	for i, d := range s {
+		ret[i] = d
+	}
+	return ret
+}
@@ -0,0 +1,138 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package performance
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkloadValidate(t *testing.T) {
+	valid := Workload{Channels: 2, BroadcastClients: 3, DeliverClients: 1, MessageSize: 1, Transactions: 6}
+	assert.NoError(t, valid.Validate())
+
+	for name, mutate := range map[string]func(w *Workload){
+		"NoChannel":           func(w *Workload) { w.Channels = 0 },
+		"NoBroadcastClient":   func(w *Workload) { w.BroadcastClients = 0 },
+		"NegativeDeliver":     func(w *Workload) { w.DeliverClients = -1 },
+		"EmptyMessages":       func(w *Workload) { w.MessageSize = 0 },
+		"IdleBroadcastClient": func(w *Workload) { w.Transactions = 5 },
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := valid
+			mutate(&w)
+			assert.Error(t, w.Validate())
+		})
+	}
+}
+
+func TestLatencyPercentiles(t *testing.T) {
+	assert.Equal(t, Latency{}, latencyPercentiles(nil), "Expected zero latencies without transactions")
+
+	// 1ms to 100ms, split among two channels in no particular order
+	var results [2]*channelResult
+	for i := range results {
+		results[i] = &channelResult{}
+	}
+	for ms := 100; ms > 0; ms-- {
+		result := results[ms%2]
+		result.latencies = append(result.latencies, time.Duration(ms)*time.Millisecond)
+	}
+
+	latency := latencyPercentiles(results[:])
+	assert.Equal(t, Latency{P50: 50, P90: 90, P99: 99, Max: 100}, latency)
+
+	single := latencyPercentiles([]*channelResult{{latencies: []time.Duration{3 * time.Millisecond}}})
+	assert.Equal(t, Latency{P50: 3, P90: 3, P99: 3, Max: 3}, single)
+}
+
+func TestBlockFill(t *testing.T) {
+	results := []*channelResult{
+		{transactions: []int{10, 10, 4}},
+		{transactions: []int{8}},
+	}
+	fill := blockFill(results, 10)
+	assert.Equal(t, 4, fill.Blocks)
+	assert.Equal(t, uint32(10), fill.MaxMessageCount)
+	assert.Equal(t, 4, fill.MinTransactions)
+	assert.Equal(t, 10, fill.MaxTransactions)
+	assert.Equal(t, 8.0, fill.MeanTransactions)
+	assert.Equal(t, 0.8, fill.MeanFill)
+
+	empty := blockFill(nil, 10)
+	assert.Equal(t, BlockFill{MaxMessageCount: 10}, empty, "Expected zero counts without blocks")
+
+	unbounded := blockFill(results, 0)
+	assert.Equal(t, 8.0, unbounded.MeanTransactions)
+	assert.Zero(t, unbounded.MeanFill, "Expected no fill ratio without a max message count")
+}
+
+func sampleReport() *Report {
+	return &Report{
+		ConsensusType: "solo",
+		Workload:      Workload{Channels: 2, BroadcastClients: 3, DeliverClients: 1, MessageSize: 1, Transactions: 60},
+		Elapsed:       2,
+		Throughput:    30,
+		Latency:       Latency{P50: 5, P90: 9, P99: 12.5, Max: 20},
+		BlockFill:     BlockFill{Blocks: 8, MaxMessageCount: 10, MinTransactions: 5, MeanTransactions: 7.5, MaxTransactions: 10, MeanFill: 0.75},
+	}
+}
+
+func TestReportWriteText(t *testing.T) {
+	report := sampleReport()
+
+	var out bytes.Buffer
+	assert.NoError(t, report.Write(&out, "text"))
+	assert.Equal(t, ""+
+		"Consensus type:      solo\n"+
+		"Workload:            60 tx of 1 KB over 2 channels, 3 broadcast and 1 deliver clients per channel\n"+
+		"Throughput:          30.0 tx/s (2.00s)\n"+
+		"Latency:             p50 5.0ms  p90 9.0ms  p99 12.5ms  max 20.0ms\n"+
+		"Blocks:              8, 7.5 tx per block (min 5, max 10), 75.0% full of 10 messages\n",
+		out.String())
+
+	report.Deliver = &DeliverStats{Blocks: 20, Elapsed: 0.5, Throughput: 40}
+	out.Reset()
+	assert.NoError(t, report.Write(&out, "text"))
+	assert.Contains(t, out.String(), "Deliver throughput:  40.0 blk/s (20 blocks in 0.50s)\n")
+}
+
+func TestReportWriteJSON(t *testing.T) {
+	report := sampleReport()
+
+	var out bytes.Buffer
+	assert.NoError(t, report.Write(&out, "json"))
+	fields := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &fields))
+	assert.Equal(t, "solo", fields["consensusType"])
+	assert.Equal(t, 60.0, fields["transactions"], "Expected the workload to be inlined")
+	assert.Equal(t, 30.0, fields["transactionsPerSecond"])
+	assert.Equal(t, 12.5, fields["latencyMilliseconds"].(map[string]interface{})["p99"])
+	assert.Equal(t, 0.75, fields["blockFill"].(map[string]interface{})["meanFill"])
+	assert.NotContains(t, fields, "deliver", "Expected no deliver stats without deliver clients")
+
+	decoded := &Report{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), decoded))
+	assert.Equal(t, report, decoded)
+
+	report.Deliver = &DeliverStats{Blocks: 20, Elapsed: 0.5, Throughput: 40}
+	out.Reset()
+	assert.NoError(t, report.Write(&out, "json"))
+	decoded = &Report{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), decoded))
+	assert.Equal(t, report.Deliver, decoded.Deliver)
+}
+
+func TestReportWriteUnknownFormat(t *testing.T) {
+	var out bytes.Buffer
+	assert.Error(t, sampleReport().Write(&out, "yaml"))
+	assert.Zero(t, out.Len(), "Expected nothing written in an unknown format")
+}
@@ -0,0 +1,71 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/hyperledger/fabric/common/localmsp"
+	"github.com/hyperledger/fabric/orderer/common/localconfig"
+	"github.com/hyperledger/fabric/orderer/common/performance"
+	"github.com/pkg/errors"
+)
+
+// runBenchmark runs the workload against an in-process orderer and writes the
+// report to out. The orderer bootstraps a ledger of its own, so that the
+// channels of the configured ledger are left alone. With standIn, the bftsmart
+// consenter connects to an in-process stand-in for the java component, which
+// signs the blocks with the local MSP. The stand-in is only built into orderers
+// built with the bftsmartstandin tag.
+func runBenchmark(conf *config.TopLevel, workload performance.Workload, standIn bool, format string, out io.Writer) error {
+	if err := workload.Validate(); err != nil {
+		return err
+	}
+
+	localConf := *conf
+	if localConf.General.LedgerType != "ram" {
+		tempDir, err := ioutil.TempDir("", "orderer-benchmark-")
+		if err != nil {
+			return errors.Wrap(err, "failed to create the benchmark ledger")
+		}
+		defer os.RemoveAll(tempDir)
+		localConf.FileLedger.Location = tempDir
+	}
+
+	if standIn {
+		stop, err := startBFTsmartStandIn(&localConf)
+		if err != nil {
+			return errors.Wrap(err, "failed to start the bftsmart stand-in")
+		}
+		defer stop()
+	}
+
+	performance.InitializeServerPool(1)
+	server, systemChannelID := newBenchmarkOrderer(&localConf)
+	performance.WaitForChannels(server, systemChannelID)
+
+	logger.Infof("Running benchmark workload of %d transactions over %d channels", workload.Transactions, workload.Channels)
+	report, err := performance.RunWorkload(server, workload)
+	if err != nil {
+		return err
+	}
+	return report.Write(out, format)
+}
+
+// newBenchmarkOrderer starts an orderer serving the next server of the
+// benchmark pool, and returns that server along with the ID of the system
+// channel of the orderer.
+func newBenchmarkOrderer(conf *config.TopLevel) (*performance.BenchmarkServer, string) {
+	logger.Info("Starting orderer in benchmark mode")
+	signer := localmsp.NewSigner()
+	manager := initializeMultichannelRegistrar(conf, signer)
+	server := performance.GetBenchmarkServer()
+	server.RegisterService(NewServer(manager, signer, &conf.Debug))
+	return server, manager.SystemChannelID()
+}
@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package server
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -133,6 +135,55 @@ func TestOrdererBenchmark(t *testing.T) {
 	})
 }
 
+// TestRunBenchmark runs the workload of the benchmark command against a solo
+// orderer, and checks the reports it writes
+func TestRunBenchmark(t *testing.T) {
+	os.Setenv(localconfig.Prefix+"_ORDERER_ORDERERTYPE", provisional.ConsensusTypeSolo)
+	defer os.Unsetenv(localconfig.Prefix + "_ORDERER_ORDERERTYPE")
+	os.Setenv("ORDERER_GENERAL_GENESISMETHOD", "provisional")
+	defer os.Unsetenv("ORDERER_GENERAL_GENESISMETHOD")
+
+	for key, value := range envvars {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+
+	conf := config.Load()
+	initializeLoggingLevel(conf)
+	initializeLocalMsp(conf)
+	conf.General.SystemChannel = "system-channel-" + perf.RandomID(5)
+	workload := perf.Workload{Channels: 2, BroadcastClients: 2, DeliverClients: 1, MessageSize: 1, Transactions: 41}
+
+	t.Run("Text", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, runBenchmark(conf, workload, false, "text", &out))
+		assert.Contains(t, out.String(), "Consensus type:      solo\n")
+		assert.Contains(t, out.String(), "Workload:            40 tx of 1 KB over 2 channels, 2 broadcast and 1 deliver clients per channel\n", "Expected the remainder of the transactions not to be sent")
+		assert.Contains(t, out.String(), "Deliver throughput:")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, runBenchmark(conf, workload, false, "json", &out))
+		report := &perf.Report{}
+		assert.NoError(t, json.Unmarshal(out.Bytes(), report))
+		assert.Equal(t, provisional.ConsensusTypeSolo, report.ConsensusType)
+		assert.Equal(t, 40, report.Transactions)
+		assert.Equal(t, uint32(MaxMessageCount), report.BlockFill.MaxMessageCount)
+		assert.True(t, report.BlockFill.Blocks > 0, "Expected the transactions to be delivered in blocks")
+		assert.True(t, report.Latency.P50 <= report.Latency.Max, "Expected ordered latency percentiles")
+		assert.NotNil(t, report.Deliver)
+	})
+
+	t.Run("InvalidWorkload", func(t *testing.T) {
+		invalid := workload
+		invalid.Transactions = 3
+		var out bytes.Buffer
+		assert.Error(t, runBenchmark(conf, invalid, false, "text", &out))
+		assert.Zero(t, out.Len())
+	})
+}
+
 // Benchmark broadcast API in Solo mode
 func TestOrdererBenchmarkSoloBroadcast(t *testing.T) {
 	if os.Getenv("BENCHMARK") == "" {
@@ -384,7 +435,10 @@ func benchmarkOrderer(
 			defer os.RemoveAll(tempDir)
 		}
 
-		go Start("benchmark", &localConf)
+		go func() {
+			server, _ := newBenchmarkOrderer(&localConf)
+			server.Start()
+		}()
 	}
 
 	defer perf.OrdererExec(perf.Halt)
@@ -0,0 +1,20 @@
+// +build !bftsmartstandin
+
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	"github.com/hyperledger/fabric/orderer/common/localconfig"
+	"github.com/pkg/errors"
+)
+
+// startBFTsmartStandIn fails, as the stand-in for the java component, which
+// signs blocks on behalf of the replicas, is left out of production orderers.
+func startBFTsmartStandIn(conf *config.TopLevel) (func(), error) {
+	return nil, errors.New("the orderer was built without the bftsmart stand-in, build it with the bftsmartstandin tag")
+}
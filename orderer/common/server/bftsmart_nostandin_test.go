@@ -0,0 +1,23 @@
+// +build !bftsmartstandin
+
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/orderer/common/localconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBFTsmartStandInLeftOut(t *testing.T) {
+	conf := &config.TopLevel{}
+	_, err := startBFTsmartStandIn(conf)
+	assert.Error(t, err)
+	assert.Empty(t, conf.BFTsmart.SendAddress, "Expected the bftsmart configuration to be left alone")
+}
@@ -0,0 +1,32 @@
+// +build bftsmartstandin
+
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/hyperledger/fabric/orderer/common/localconfig"
+	"github.com/hyperledger/fabric/orderer/consensus/bftsmart/standin"
+)
+
+// startBFTsmartStandIn starts an in-process stand-in for the java component,
+// signing the blocks with the local MSP, and points the bftsmart configuration
+// to it. The returned function stops the stand-in.
+func startBFTsmartStandIn(conf *config.TopLevel) (func(), error) {
+	proxy, err := standin.New(mspmgmt.GetLocalSigningIdentityOrPanic())
+	if err != nil {
+		return nil, err
+	}
+
+	standInConf := proxy.Config()
+	conf.BFTsmart.SendAddress = standInConf.SendAddress
+	conf.BFTsmart.RecvAddress = standInConf.RecvAddress
+	conf.BFTsmart.Replicas = standInConf.Replicas
+	conf.BFTsmart.TLS = standInConf.TLS
+	return proxy.Close, nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"text/tabwriter"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/localmsp"
+	"github.com/hyperledger/fabric/common/tools/protolator"
+	"github.com/hyperledger/fabric/core/comm"
+	config "github.com/hyperledger/fabric/orderer/common/localconfig"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+const adminRequestTimeout = 10 * time.Second
+
+// printChannels queries the admin service of the orderer at the given address,
+// or at the configured listen address if empty, and writes the channels, or
+// the config of the given channel, to out
+func printChannels(conf *config.TopLevel, address string, channelID string, out io.Writer) error {
+	if address == "" {
+		address = fmt.Sprintf("%s:%d", conf.General.ListenAddress, conf.General.ListenPort)
+	}
+
+	dialOpts := []grpc.DialOption{grpc.WithBlock(), grpc.WithTimeout(adminRequestTimeout)}
+	if conf.General.TLS.Enabled {
+		creds, err := adminClientCredentials(conf.General.TLS)
+		if err != nil {
+			return err
+		}
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(creds))
+	} else {
+		dialOpts = append(dialOpts, grpc.WithInsecure())
+	}
+
+	conn, err := grpc.Dial(address, dialOpts...)
+	if err != nil {
+		return fmt.Errorf("failed connecting to %s: %s", address, err)
+	}
+	defer conn.Close()
+
+	return writeChannels(ab.NewAdminClient(conn), localmsp.NewSigner(), channelID, out)
+}
+
+func adminClientCredentials(tlsConf config.TLS) (credentials.TransportCredentials, error) {
+	var rootCAs [][]byte
+	for _, rootCA := range tlsConf.RootCAs {
+		root, err := ioutil.ReadFile(rootCA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load root CA file '%s': %s", rootCA, err)
+		}
+		rootCAs = append(rootCAs, root)
+	}
+
+	var certificate, key []byte
+	if tlsConf.ClientAuthEnabled {
+		var err error
+		if certificate, err = ioutil.ReadFile(tlsConf.Certificate); err != nil {
+			return nil, fmt.Errorf("failed to load certificate file '%s': %s", tlsConf.Certificate, err)
+		}
+		if key, err = ioutil.ReadFile(tlsConf.PrivateKey); err != nil {
+			return nil, fmt.Errorf("failed to load private key file '%s': %s", tlsConf.PrivateKey, err)
+		}
+	}
+
+	tlsConfig, err := comm.NewClientTLSConfig(certificate, key, rootCAs)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+func writeChannels(client ab.AdminClient, signer crypto.LocalSigner, channelID string, out io.Writer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), adminRequestTimeout)
+	defer cancel()
+
+	if channelID != "" {
+		env, err := utils.CreateSignedEnvelope(cb.HeaderType_MESSAGE, channelID, signer, &ab.ChannelConfigRequest{ChannelId: channelID}, int32(0), uint64(0))
+		if err != nil {
+			return err
+		}
+		response, err := client.GetChannelConfig(ctx, env)
+		if err != nil {
+			return err
+		}
+		if err := writeChannelInfos(out, response.Info); err != nil {
+			return err
+		}
+		return protolator.DeepMarshalJSON(out, response.Config)
+	}
+
+	env, err := utils.CreateSignedEnvelope(cb.HeaderType_MESSAGE, "", signer, &empty.Empty{}, int32(0), uint64(0))
+	if err != nil {
+		return err
+	}
+	list, err := client.ListChannels(ctx, env)
+	if err != nil {
+		return err
+	}
+	return writeChannelInfos(out, list.Channels...)
+}
+
+func writeChannelInfos(out io.Writer, infos ...*ab.ChannelInfo) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "CHANNEL\tHEIGHT\tCONSENSUS\tLAST CONFIG\tCONFIG SEQUENCE\tSTATUS")
+	for _, info := range infos {
+		name := info.ChannelId
+		if info.SystemChannel {
+			name += " (system)"
+		}
+		status := "OK"
+		if info.Errored {
+			status = "ERRORED"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%d\t%s\n", name, info.Height, info.ConsensusType, info.LastConfigBlock, info.ConfigSequence, status)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,84 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+type mockAdminClient struct {
+	requests []*cb.Envelope
+	err      error
+}
+
+func (mac *mockAdminClient) ListChannels(ctx context.Context, env *cb.Envelope, opts ...grpc.CallOption) (*ab.ChannelList, error) {
+	mac.requests = append(mac.requests, env)
+	if mac.err != nil {
+		return nil, mac.err
+	}
+	return &ab.ChannelList{Channels: []*ab.ChannelInfo{
+		{ChannelId: "appchannel", Height: 5, ConsensusType: "kafka", LastConfigBlock: 3, ConfigSequence: 2, Errored: true},
+		{ChannelId: "syschannel", Height: 2, ConsensusType: "kafka", LastConfigBlock: 1, ConfigSequence: 1, SystemChannel: true},
+	}}, nil
+}
+
+func (mac *mockAdminClient) GetChannelConfig(ctx context.Context, env *cb.Envelope, opts ...grpc.CallOption) (*ab.ChannelConfigResponse, error) {
+	mac.requests = append(mac.requests, env)
+	if mac.err != nil {
+		return nil, mac.err
+	}
+	return &ab.ChannelConfigResponse{
+		Info:   &ab.ChannelInfo{ChannelId: "appchannel", Height: 5, ConsensusType: "kafka", LastConfigBlock: 3, ConfigSequence: 2},
+		Config: &cb.Config{Sequence: 2, ChannelGroup: cb.NewConfigGroup()},
+	}, nil
+}
+
+func TestWriteChannels(t *testing.T) {
+	client := &mockAdminClient{}
+	out := &bytes.Buffer{}
+	assert.NoError(t, writeChannels(client, mockcrypto.FakeLocalSigner, "", out))
+	assert.Contains(t, out.String(), "appchannel")
+	assert.Contains(t, out.String(), "ERRORED")
+	assert.Contains(t, out.String(), "syschannel (system)")
+
+	assert.Len(t, client.requests, 1)
+	payload := utils.UnmarshalPayloadOrPanic(client.requests[0].Payload)
+	assert.Empty(t, payload.Data, "Expected the channel list request to carry no data")
+	shdr, err := utils.GetSignatureHeader(payload.Header.SignatureHeader)
+	assert.NoError(t, err)
+	assert.Equal(t, mockcrypto.FakeLocalSigner.Identity, shdr.Creator)
+}
+
+func TestWriteChannelConfig(t *testing.T) {
+	client := &mockAdminClient{}
+	out := &bytes.Buffer{}
+	assert.NoError(t, writeChannels(client, mockcrypto.FakeLocalSigner, "appchannel", out))
+	assert.Contains(t, out.String(), "appchannel")
+	assert.Contains(t, out.String(), `"sequence": "2"`)
+
+	request := &ab.ChannelConfigRequest{}
+	chdr, err := utils.UnmarshalEnvelopeOfType(client.requests[0], cb.HeaderType_MESSAGE, request)
+	assert.NoError(t, err)
+	assert.Equal(t, "appchannel", chdr.ChannelId)
+	assert.Equal(t, "appchannel", request.ChannelId)
+}
+
+func TestWriteChannelsError(t *testing.T) {
+	client := &mockAdminClient{err: fmt.Errorf("access denied")}
+	assert.Error(t, writeChannels(client, mockcrypto.FakeLocalSigner, "", &bytes.Buffer{}))
+	assert.Error(t, writeChannels(client, mockcrypto.FakeLocalSigner, "appchannel", &bytes.Buffer{}))
+}
@@ -20,6 +20,7 @@ import (
 	genesisconfig "github.com/hyperledger/fabric/common/tools/configtxgen/localconfig"
 	"github.com/hyperledger/fabric/common/tools/configtxgen/provisional"
 	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/orderer/common/admin"
 	"github.com/hyperledger/fabric/orderer/common/bootstrap/file"
 	"github.com/hyperledger/fabric/orderer/common/ledger"
 	"github.com/hyperledger/fabric/orderer/common/localconfig"
@@ -49,7 +50,19 @@ var (
 
 	start     = app.Command("start", "Start the orderer node").Default()
 	version   = app.Command("version", "Show version information")
-	benchmark = app.Command("benchmark", "Run orderer in benchmark mode")
+	benchmark = app.Command("benchmark", "Run a workload against an in-process orderer, with the configured consenter, and report its performance")
+
+	benchmarkChannels         = benchmark.Flag("channels", "The number of channels to create").Default("1").Int()
+	benchmarkBroadcastClients = benchmark.Flag("broadcastClients", "The number of concurrent broadcast clients per channel").Default("1").Int()
+	benchmarkDeliverClients   = benchmark.Flag("deliverClients", "The number of concurrent deliver clients per channel, reading every block once the transactions are ordered").Default("0").Int()
+	benchmarkMessageSize      = benchmark.Flag("messageSize", "The size of the transactions in KB").Default("1").Int()
+	benchmarkTransactions     = benchmark.Flag("transactions", "The number of transactions to send in total").Default("10000").Int()
+	benchmarkOutput           = benchmark.Flag("output", "The format of the report, text or json").Default("text").Enum("text", "json")
+	benchmarkStandIn          = benchmark.Flag("bftsmartStandIn", "Connect the bftsmart consenter to an in-process stand-in for the java component (requires an orderer built with the bftsmartstandin tag)").Bool()
+
+	channels          = app.Command("channels", "List the channels of a running orderer, signing the request with the local MSP, which must be an admin one")
+	channelsAddress   = channels.Flag("address", "The address of the orderer, defaults to the configured listen address").String()
+	channelsChannelID = channels.Flag("channelID", "Print the current config of this channel instead of listing the channels").String()
 )
 
 // Main is the entry point of orderer process
@@ -66,29 +79,45 @@ func Main() {
 	initializeLoggingLevel(conf)
 	initializeLocalMsp(conf)
 
-	Start(fullCmd, conf)
+	// "channels" command
+	if fullCmd == channels.FullCommand() {
+		if err := printChannels(conf, *channelsAddress, *channelsChannelID, os.Stdout); err != nil {
+			logger.Fatal("Failed to retrieve the channels:", err)
+		}
+		return
+	}
+
+	// "benchmark" command
+	if fullCmd == benchmark.FullCommand() {
+		workload := performance.Workload{
+			Channels:         *benchmarkChannels,
+			BroadcastClients: *benchmarkBroadcastClients,
+			DeliverClients:   *benchmarkDeliverClients,
+			MessageSize:      *benchmarkMessageSize,
+			Transactions:     *benchmarkTransactions,
+		}
+		if err := runBenchmark(conf, workload, *benchmarkStandIn, *benchmarkOutput, os.Stdout); err != nil {
+			logger.Fatal("Failed to run the benchmark:", err)
+		}
+		return
+	}
+
+	Start(conf)
 }
 
-// Start provides a layer of abstraction for benchmark test
-func Start(cmd string, conf *config.TopLevel) {
+// Start starts the orderer, and serves requests until the process exits
+func Start(conf *config.TopLevel) {
 	signer := localmsp.NewSigner()
 	manager := initializeMultichannelRegistrar(conf, signer)
 	server := NewServer(manager, signer, &conf.Debug)
 
-	switch cmd {
-	case start.FullCommand(): // "start" command
-		logger.Infof("Starting %s", metadata.GetVersionInfo())
-		initializeProfilingService(conf)
-		grpcServer := initializeGrpcServer(conf)
-		ab.RegisterAtomicBroadcastServer(grpcServer.Server(), server)
-		logger.Info("Beginning to serve requests")
-		grpcServer.Start()
-	case benchmark.FullCommand(): // "benchmark" command
-		logger.Info("Starting orderer in benchmark mode")
-		benchmarkServer := performance.GetBenchmarkServer()
-		benchmarkServer.RegisterService(server)
-		benchmarkServer.Start()
-	}
+	logger.Infof("Starting %s", metadata.GetVersionInfo())
+	initializeProfilingService(conf)
+	grpcServer := initializeGrpcServer(conf)
+	ab.RegisterAtomicBroadcastServer(grpcServer.Server(), server)
+	ab.RegisterAdminServer(grpcServer.Server(), admin.NewHandlerImpl(adminSupport{Registrar: manager}, mspmgmt.GetLocalMSP(), mspmgmt.NewLocalMSPPrincipalGetter()))
+	logger.Info("Beginning to serve requests")
+	grpcServer.Start()
 }
 
 // Set the logging level
@@ -223,7 +252,13 @@ func initializeMultichannelRegistrar(conf *config.TopLevel, signer crypto.LocalS
 	consenters := make(map[string]consensus.Consenter)
 	consenters["solo"] = solo.New()
 	consenters["kafka"] = kafka.New(conf.Kafka.TLS, conf.Kafka.Retry, conf.Kafka.Version)
-	consenters["bftsmart"] = bftsmart.New(conf.BFTsmart) //JCS: create my own consenter
+	bftsmartConsenter := bftsmart.New(conf.BFTsmart) //JCS: create my own consenter
+	consenters["bftsmart"] = bftsmartConsenter
+
+	if conf.General.Profile.Enabled {
+		// served along with pprof, see initializeProfilingService
+		http.Handle(bftsmart.StatusPath, bftsmartConsenter.(http.Handler))
+	}
 
-	return multichannel.NewRegistrar(lf, consenters, signer)
+	return multichannel.NewRegistrar(lf, consenters, signer, conf.General.TxIDCacheSize)
 }
@@ -15,6 +15,7 @@ import (
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/orderer/common/admin"
 	"github.com/hyperledger/fabric/orderer/common/broadcast"
 	"github.com/hyperledger/fabric/orderer/common/deliver"
 	localconfig "github.com/hyperledger/fabric/orderer/common/localconfig"
@@ -39,6 +40,14 @@ func (bs deliverSupport) GetChain(chainID string) (deliver.Support, bool) {
 	return bs.Registrar.GetChain(chainID)
 }
 
+type adminSupport struct {
+	*multichannel.Registrar
+}
+
+func (as adminSupport) GetChain(chainID string) (admin.ChainSupport, bool) {
+	return as.Registrar.GetChain(chainID)
+}
+
 type server struct {
 	bh    broadcast.Handler
 	dh    deliver.Handler
@@ -29,7 +29,7 @@ func createLedgerFactory(conf *config.TopLevel) (ledger.Factory, string) {
 			ld = createTempDir(conf.FileLedger.Prefix)
 		}
 		logger.Debug("Ledger dir:", ld)
-		lf = fileledger.New(ld)
+		lf = fileledger.New(ld, retentionPolicy(conf.FileLedger.Retention))
 		// The file-based ledger stores the blocks for each channel
 		// in a fsblkstorage.ChainsDir sub-directory that we have
 		// to create separately. Otherwise the call to the ledger
@@ -41,7 +41,7 @@ func createLedgerFactory(conf *config.TopLevel) (ledger.Factory, string) {
 			ld = createTempDir(conf.FileLedger.Prefix)
 		}
 		logger.Debug("Ledger dir:", ld)
-		lf = jsonledger.New(ld)
+		lf = jsonledger.New(ld, retentionPolicy(conf.FileLedger.Retention))
 	case "ram":
 		fallthrough
 	default:
@@ -50,6 +50,10 @@ func createLedgerFactory(conf *config.TopLevel) (ledger.Factory, string) {
 	return lf, ld
 }
 
+func retentionPolicy(retention config.Retention) ledger.RetentionPolicy {
+	return ledger.RetentionPolicy{Blocks: retention.Blocks, Age: retention.Age}
+}
+
 func createTempDir(dirPrefix string) string {
 	dirPath, err := ioutil.TempDir("", dirPrefix)
 	if err != nil {
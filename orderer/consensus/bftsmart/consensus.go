@@ -19,345 +19,430 @@ package bftsmart
 
 import (
 	"fmt"
+	"net"
 	"sync"
 	"time"
 
-	cb "github.com/hyperledger/fabric/protos/common"
-	"github.com/op/go-logging"
-
-	"encoding/binary"
-	"io"
-	"net"
-
+	"github.com/golang/protobuf/proto"
 	localconfig "github.com/hyperledger/fabric/orderer/common/localconfig"
 	"github.com/hyperledger/fabric/orderer/consensus"
+	"github.com/hyperledger/fabric/orderer/consensus/bftsmart/protocol"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+	"github.com/pkg/errors"
 )
 
 var logger = logging.MustGetLogger("orderer/bftsmart")
-var poolsize uint = 0
-var poolindex uint = 0
-var recvport uint = 0
-var sendProxy net.Conn
-var sendPool []net.Conn
-var mutex []*sync.Mutex
-var batchTimeout time.Duration
 
 type consenter struct {
-	createSystemChannel bool
+	// recvEndpoint is where the chains connect to receive their blocks
+	recvEndpoint *endpoint
+
+	// control carries the batch configuration of every chain
+	control *proxyConn
+
+	// pool carries the envelopes of every chain
+	pool *connPool
+
+	// replicas is the number of replicas whose signatures are expected on blocks
+	replicas uint32
+
+	// maxInFlight and ackTimeout bound the envelopes of each chain waiting
+	// for the acknowledgement of the java component
+	maxInFlight uint
+	ackTimeout  time.Duration
+
+	// chains holds the last chain created for each channel, whose status is
+	// reported by Status
+	chainsLock sync.Mutex
+	chains     map[string]*chain
 }
 
 type chain struct {
-	recvProxy       net.Conn
-	isSystemChannel bool
+	// lock protects recvProxy, which Halt closes to interrupt connLoop, and
+	// the fields reported by Status
+	lock              sync.Mutex
+	recvProxy         net.Conn
+	errorChan         chan struct{}
+	connectedReplicas uint32
+	lastBlock         uint64
+
+	// done tracks connLoop and appendToChain, which Halt waits for
+	done sync.WaitGroup
 
 	support         consensus.ConsenterSupport
+	recvEndpoint    *endpoint
+	control         *proxyConn
+	pool            *connPool
 	sendChanRegular chan *cb.Block
 	sendChanConfig  chan *cb.Block
 	exitChan        chan struct{}
-}
 
-// New creates a new consenter for the bftsmart consensus scheme.
-func New(config localconfig.BFTsmart) consensus.Consenter {
+	// verifier checks the blocks received from the java component
+	verifier *blockVerifier
 
-	poolsize = config.ConnectionPoolSize
-	recvport = config.RecvPort
-	return &consenter{
-		createSystemChannel: true,
-	}
-}
+	// lastMetadata is the consensus metadata of the last block committed when
+	// the chain was created, nil if it carries none
+	lastMetadata *ab.BFTsmartMetadata
 
-func (bftsmart *consenter) HandleChain(support consensus.ConsenterSupport, metadata *cb.Metadata) (consensus.Chain, error) {
-	isSysChan := bftsmart.createSystemChannel
-	bftsmart.createSystemChannel = false
-	return newChain(isSysChan, support), nil
-}
+	// inflight holds a token for every envelope waiting for the
+	// acknowledgement of the java component
+	inflight   chan struct{}
+	ackTimeout time.Duration
 
-func newChain(isSysChan bool, support consensus.ConsenterSupport) *chain {
+	// configApplied is signaled by appendToChain once a config block is
+	// applied, so that the blocks following it are verified against the
+	// new configuration
+	configApplied chan struct{}
 
-	logger.Infof("Creating new bftsmart chain with ID '%s'\n", support.ChainID())
+	// configLock is held for writing while a config block is applied and its
+	// batch configuration is acknowledged by the java component, so that no
+	// envelope is sent in the meantime
+	configLock sync.RWMutex
+}
 
-	return &chain{
-		support:         support,
-		isSystemChannel: isSysChan,
+// New creates a new consenter for the bftsmart consensus scheme.
+func New(config localconfig.BFTsmart) consensus.Consenter {
 
-		sendChanRegular: make(chan *cb.Block),
-		sendChanConfig:  make(chan *cb.Block),
-		exitChan:        make(chan struct{}),
+	bftsmart, err := newConsenter(config)
+	if err != nil {
+		logger.Panicf("Could not create bftsmart consenter: %s", err)
 	}
 
+	return bftsmart
 }
 
-func (ch *chain) Start() {
-
-	logger.Infof("Starting new bftsmart chain with ID '%s'\n", ch.support.ChainID())
+func newConsenter(config localconfig.BFTsmart) (*consenter, error) {
 
-	if ch.isSystemChannel {
-
-		conn, err := net.Dial("unix", "/tmp/hlf-pool.sock")
-
-		if err != nil {
-			panic(fmt.Sprintf("Could not start connection pool to java component: %s", err))
-			return
-		}
-
-		sendProxy = conn
-
-		sendPool = make([]net.Conn, poolsize)
-		mutex = make([]*sync.Mutex, poolsize)
-
-		//create connection pool
-		for i := uint(0); i < poolsize; i++ {
-
-			conn, err := net.Dial("unix", "/tmp/hlf-pool.sock")
-
-			if err != nil {
-				panic(fmt.Sprintf("Could not create all connection pool to java component: %s", err))
-				//return
-			} else {
-				logger.Debug(fmt.Sprintf("Created connection #%v\n", i))
-				//conn.SetNoDelay(true)
-				sendPool[i] = conn
-				mutex[i] = &sync.Mutex{}
-			}
-		}
-
-		logger.Info("Created connection pool to java component")
-
-		batchTimeout = ch.support.SharedConfig().BatchTimeout()
-
-		//Sending batch configuration
-		_, err = sendUint32(ch.support.SharedConfig().BatchSize().PreferredMaxBytes, sendProxy)
-
-		if err != nil {
-			logger.Info("Error while sending PreferredMaxBytes:", err)
-			return
-		}
-
-		_, err = sendUint32(ch.support.SharedConfig().BatchSize().MaxMessageCount, sendProxy)
-
-		if err != nil {
-			logger.Info("Error while sending MaxMessageCount:", err)
-			return
-		}
-		_, err = sendUint64(uint64(time.Duration.Nanoseconds(batchTimeout)), sendProxy)
+	if config.ConnectionPoolSize == 0 {
+		return nil, fmt.Errorf("BFTsmart.ConnectionPoolSize must be greater than zero")
+	}
 
-		if err != nil {
-			logger.Info("Error while sending BatchTimeout:", err)
-			return
-		}
+	if config.Replicas == 0 {
+		return nil, fmt.Errorf("BFTsmart.Replicas must be greater than zero")
+	}
 
+	if config.MaxInFlight == 0 {
+		return nil, fmt.Errorf("BFTsmart.MaxInFlight must be greater than zero")
 	}
 
-	addr := fmt.Sprintf("localhost:%d", recvport)
-	conn, err := net.Dial("tcp", addr)
+	if config.AckTimeout <= 0 {
+		return nil, fmt.Errorf("BFTsmart.AckTimeout must be greater than zero")
+	}
 
+	tlsConfig, err := newTLSConfig(config.TLS)
 	if err != nil {
-		logger.Info("Error while connecting to java component:", err)
-		return
+		return nil, fmt.Errorf("unable to set up TLS for the connections to the java component: %s", err)
 	}
 
-	ch.recvProxy = conn
-
-	_, err = sendString(ch.support.ChainID(), sendProxy)
-
+	// the control connection and the connection pool share the send address
+	sendEndpoint, err := newEndpoint(config.SendAddress, tlsConfig)
 	if err != nil {
-		logger.Info("Error while sending chain ID:", err)
-		return
+		return nil, fmt.Errorf("invalid BFTsmart.SendAddress: %s", err)
 	}
 
-	lastBlock := ch.support.GetLastBlock()
-	header := lastBlock.Header
-
-	_, err = sendHeaderToBFTProxy(header)
-
+	recvEndpoint, err := newEndpoint(config.RecvAddress, tlsConfig)
 	if err != nil {
-		logger.Info("Error while sending chain ID:", err)
-		return
+		return nil, fmt.Errorf("invalid BFTsmart.RecvAddress: %s", err)
 	}
 
-	// starting loops
-	go ch.connLoop() // my own loop
+	// The connections are established lazily, and re-established whenever
+	// they break, so chains may be created in any order.
+	return &consenter{
+		recvEndpoint: recvEndpoint,
+		control:      newProxyConn(sendEndpoint, protocol.RoleControl, "control connection"),
+		pool:         newConnPool(sendEndpoint, config.ConnectionPoolSize),
+		replicas:     config.Replicas,
+		maxInFlight:  config.MaxInFlight,
+		ackTimeout:   config.AckTimeout,
+		chains:       make(map[string]*chain),
+	}, nil
+}
 
-	go ch.appendToChain()
+func (bftsmart *consenter) HandleChain(support consensus.ConsenterSupport, metadata *cb.Metadata) (consensus.Chain, error) {
+	lastMetadata, err := getLastMetadata(metadata.GetValue())
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal orderer metadata in most recent block of channel %s: %s", support.ChainID(), err)
+	}
+	return newChain(bftsmart, support, lastMetadata), nil
 }
 
-func (ch *chain) Halt() {
+// getLastMetadata decodes the consensus metadata stored in the ORDERER slot of
+// the last block, which is nil for new chains.
+func getLastMetadata(metadataValue []byte) (*ab.BFTsmartMetadata, error) {
+	if len(metadataValue) == 0 {
+		return nil, nil
+	}
 
-	select {
-	case <-ch.exitChan:
-		// Allow multiple halts without panic
-	default:
-		close(ch.exitChan)
+	metadata := &ab.BFTsmartMetadata{}
+	if err := proto.Unmarshal(metadataValue, metadata); err != nil {
+		return nil, err
 	}
+	return metadata, nil
 }
 
-// Errored only closes on exit
-func (ch *chain) Errored() <-chan struct{} {
-	return ch.exitChan
-}
+func newChain(bftsmart *consenter, support consensus.ConsenterSupport, lastMetadata *ab.BFTsmartMetadata) *chain {
+
+	logger.Infof("Creating new bftsmart chain with ID '%s'\n", support.ChainID())
 
-func sendLength(length int, conn net.Conn) (int, error) {
+	// Errored is closed until the chain connects to the java component
+	errorChan := make(chan struct{})
+	close(errorChan)
+
+	ch := &chain{
+		errorChan:    errorChan,
+		lastBlock:    support.GetLastBlock().Header.Number,
+		support:      support,
+		recvEndpoint: bftsmart.recvEndpoint,
+		control:      bftsmart.control,
+		pool:         bftsmart.pool,
+		verifier:     newBlockVerifier(support, bftsmart.replicas),
+		lastMetadata: lastMetadata,
+		inflight:     make(chan struct{}, bftsmart.maxInFlight),
+		ackTimeout:   bftsmart.ackTimeout,
 
-	var buf [8]byte
+		sendChanRegular: make(chan *cb.Block),
+		sendChanConfig:  make(chan *cb.Block),
+		configApplied:   make(chan struct{}),
+		exitChan:        make(chan struct{}),
+	}
 
-	binary.BigEndian.PutUint64(buf[:], uint64(length))
+	bftsmart.chainsLock.Lock()
+	bftsmart.chains[support.ChainID()] = ch
+	bftsmart.chainsLock.Unlock()
 
-	return conn.Write(buf[:])
+	return ch
 }
 
-func sendUint64(length uint64, conn net.Conn) (int, error) {
+func (ch *chain) Start() {
 
-	var buf [8]byte
+	logger.Infof("Starting new bftsmart chain with ID '%s'\n", ch.support.ChainID())
 
-	binary.BigEndian.PutUint64(buf[:], uint64(length))
+	// starting loops; the connection to the java component is established
+	// (and re-established) by connLoop
+	ch.done.Add(2)
+	go ch.connLoop()
 
-	return conn.Write(buf[:])
+	go ch.appendToChain()
 }
 
-func sendUint32(length uint32, conn net.Conn) (int, error) {
-
-	var buf [4]byte
-
-	binary.BigEndian.PutUint32(buf[:], uint32(length))
-
-	return conn.Write(buf[:])
+// Halt stops the chain, closing its connection to the java component, and
+// returns once both loops of the chain have exited.
+func (ch *chain) Halt() {
+	ch.halt()
+	ch.done.Wait()
 }
 
-func sendBoolean(boolean bool, conn net.Conn) (int, error) {
+// halt signals the loops of the chain to exit without waiting for them, so
+// that they may call it themselves.
+func (ch *chain) halt() {
 
-	var buf [1]byte
+	ch.lock.Lock()
+	defer ch.lock.Unlock()
 
-	if boolean {
-		buf[0] = 1
-	} else {
-		buf[0] = 0
+	select {
+	case <-ch.exitChan:
+		// Allow multiple halts without panic
+		return
+	default:
 	}
 
-	status, err := sendLength(1, conn)
+	logger.Infof("[channel: %s] Halting bftsmart chain", ch.support.ChainID())
+	close(ch.exitChan)
+	ch.setErrored()
 
-	if err != nil {
-		return status, err
+	// interrupts connLoop if it is waiting for a block
+	if ch.recvProxy != nil {
+		ch.recvProxy.Close()
 	}
+}
 
-	return conn.Write(buf[:])
-
+// Errored returns a channel which is closed while the chain is not connected
+// to the java component, and once it is halted, so that deliver clients are
+// disconnected.
+func (ch *chain) Errored() <-chan struct{} {
+	ch.lock.Lock()
+	defer ch.lock.Unlock()
+	return ch.errorChan
 }
 
-func sendString(str string, conn net.Conn) (int, error) {
+// setErrored closes errorChan, if it is not already. It must be called with
+// the lock held.
+func (ch *chain) setErrored() {
+	select {
+	case <-ch.errorChan:
+	default:
+		close(ch.errorChan)
+	}
+}
 
-	status, err := sendLength(len(str), conn)
+// sendBatchConfig sends the current batch configuration of this chain over
+// the control connection, and waits for the java component to acknowledge it.
+// Like envelopes, the whole request is bounded by the acknowledgement timeout,
+// after which errDeadlineExceeded is returned.
+func (ch *chain) sendBatchConfig() error {
 
-	if err != nil {
-		return status, err
-	}
+	deadline := time.Now().Add(ch.ackTimeout)
 
-	return conn.Write([]byte(str))
+	batchSize := ch.support.SharedConfig().BatchSize()
+	batchTimeout := ch.support.SharedConfig().BatchTimeout()
+	f := protocol.NewBatchConfigFrame(ch.support.ChainID(), batchSize.PreferredMaxBytes, batchSize.MaxMessageCount, batchTimeout)
 
-}
+	err := ch.control.do(deadline, ch.exitChan, func(conn net.Conn) error {
+		if err := protocol.WriteFrame(conn, f); err != nil {
+			return err
+		}
 
-func sendBytes(bytes []byte, conn net.Conn) (int, error) {
+		conn.SetReadDeadline(deadline)
+		defer conn.SetReadDeadline(time.Time{})
 
-	status, err := sendLength(len(bytes), conn)
+		return protocol.WaitForAck(conn, f.ID, protocol.DefaultMaxFrameSize)
+	})
 
 	if err != nil {
-		return status, err
+		return err
 	}
 
-	return conn.Write(bytes)
-
+	logger.Debugf("[channel: %s] Java component acknowledged batch configuration (PreferredMaxBytes=%d, MaxMessageCount=%d, BatchTimeout=%s)",
+		ch.support.ChainID(), batchSize.PreferredMaxBytes, batchSize.MaxMessageCount, batchTimeout)
+	return nil
 }
 
-func sendEnvToBFTProxy(isConfig bool, chainID string, env *cb.Envelope, index uint) (int, error) {
+// sendEnvToBFTProxy writes the envelope to the next connection of the pool,
+// and waits for the java component to acknowledge it. If too many envelopes
+// of the chain are already waiting, or if the java component cannot be
+// reached, written to and heard back from within the acknowledgement timeout,
+// ErrServiceUnavailable is returned so that the client retries later.
+func (ch *chain) sendEnvToBFTProxy(isConfig bool, env *cb.Envelope) error {
 
-	mutex[index].Lock()
+	select {
+	case <-ch.exitChan:
+		return fmt.Errorf("Exiting")
+	default:
+	}
 
-	//send channel id
-	status, err := sendString(chainID, sendPool[index])
+	select {
+	case ch.inflight <- struct{}{}:
+		defer func() { <-ch.inflight }()
+	default:
+		return errors.Wrapf(consensus.ErrServiceUnavailable, "%d envelopes are already waiting for the java component", cap(ch.inflight))
+	}
 
-	//send isConfig
-	status, err = sendBoolean(isConfig, sendPool[index])
+	// the timeout bounds the whole request, connection included
+	deadline := time.Now().Add(ch.ackTimeout)
 
-	//send envelope
 	bytes, err := utils.Marshal(env)
 	if err != nil {
-		return -1, err
+		return err
 	}
-	status, err = sendBytes(bytes, sendPool[index])
 
-	mutex[index].Unlock()
-
-	return status, err
-}
+	kind := protocol.MsgEnvelope
+	if isConfig {
+		kind = protocol.MsgConfigEnvelope
+	}
 
-func sendHeaderToBFTProxy(header *cb.BlockHeader) (int, error) {
-	bytes, err := utils.Marshal(header)
+	f := protocol.NewFrame(kind, protocol.NextCorrelationID()).AddString(protocol.FieldChainID, ch.support.ChainID()).AddBytes(protocol.FieldPayload, bytes)
 
-	if err != nil {
-		return -1, err
-	}
+	ack := ch.pool.expectAck(f.ID)
+	defer ch.pool.forget(f.ID)
 
-	status, err := sendLength(len(bytes), sendProxy)
+	// no envelope is sent while a config block is being applied
+	ch.configLock.RLock()
+	err = ch.pool.get().do(deadline, ch.exitChan, func(conn net.Conn) error {
+		return protocol.WriteFrame(conn, f)
+	})
+	ch.configLock.RUnlock()
 
+	if err == errDeadlineExceeded {
+		return errors.Wrapf(consensus.ErrServiceUnavailable, "java component could not be reached within %s", ch.ackTimeout)
+	}
 	if err != nil {
-		return status, err
+		return err
 	}
 
-	return sendProxy.Write(bytes)
-}
-
-func (ch *chain) recvLength() (int64, error) {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
 
-	var size int64
-	err := binary.Read(ch.recvProxy, binary.BigEndian, &size)
-	return size, err
+	select {
+	case err := <-ack:
+		return err
+	case <-timer.C:
+		return errors.Wrapf(consensus.ErrServiceUnavailable, "java component did not acknowledge envelope within %s", ch.ackTimeout)
+	case <-ch.exitChan:
+		return fmt.Errorf("Exiting")
+	}
 }
 
-func (ch *chain) recvBytes() ([]byte, error) {
-
-	size, err := ch.recvLength()
+// connect (re)establishes the connection on which the java component delivers
+// the blocks of this chain. The handshake carries the chain ID, the header of
+// the last block received and, if known, its consensus metadata, so that the
+// java component resumes delivery at the following block, and the replica
+// transfers the state it misses. The batch configuration of the chain is then
+// (re)sent.
+func (ch *chain) connect(lastHeader *cb.BlockHeader, lastMetadata *ab.BFTsmartMetadata) error {
 
+	header, err := utils.Marshal(lastHeader)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	buf := make([]byte, size)
+	hello := protocol.NewHandshakeFrame(protocol.RoleDeliver).
+		AddString(protocol.FieldChainID, ch.support.ChainID()).
+		AddBytes(protocol.FieldHeader, header)
 
-	_, err = io.ReadFull(ch.recvProxy, buf)
-
-	if err != nil {
-		return nil, err
+	if lastMetadata != nil {
+		hello.AddConsensusMetadata(lastMetadata)
 	}
 
-	return buf, nil
-}
-
-func (ch *chain) recvEnvFromBFTProxy() (*cb.Envelope, error) {
-
-	size, err := ch.recvLength()
-
+	conn, err := dialBFTProxy(ch.recvEndpoint, hello, time.Time{}, ch.exitChan)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	buf := make([]byte, size)
+	if err := ch.sendBatchConfig(); err != nil {
+		if _, ok := err.(*protocol.Error); !ok {
+			conn.Close()
+			return err
+		}
+		// the java component keeps using its previous batch configuration
+		logger.Errorf("[channel: %s] Java component rejected batch configuration: %s", ch.support.ChainID(), err)
+	}
 
-	_, err = io.ReadFull(ch.recvProxy, buf)
+	ch.lock.Lock()
+	defer ch.lock.Unlock()
 
-	if err != nil {
-		return nil, err
+	select {
+	case <-ch.exitChan:
+		conn.Close()
+		return fmt.Errorf("Exiting")
+	default:
 	}
 
-	env, err := utils.UnmarshalEnvelope(buf)
+	logger.Infof("[channel: %s] Connected to java component, resuming after block %d", ch.support.ChainID(), lastHeader.Number)
+	ch.recvProxy = conn
+	ch.connectedReplicas = 0
+	ch.errorChan = make(chan struct{})
+	return nil
+}
 
-	if err != nil {
-		return nil, err
-	}
+// disconnect closes the receive connection so that connLoop re-establishes
+// it. Errored is closed until then.
+func (ch *chain) disconnect() {
+	ch.lock.Lock()
+	defer ch.lock.Unlock()
 
-	return env, nil
+	if ch.recvProxy != nil {
+		ch.recvProxy.Close()
+		ch.recvProxy = nil
+	}
+	ch.connectedReplicas = 0
+	ch.setErrored()
 }
 
-// Order accepts a message and returns true on acceptance, or false on shutdown
+// Order sends a message to the java component, and returns once the java
+// component acknowledged it
 func (ch *chain) Order(env *cb.Envelope, configSeq uint64) error {
 
 	//perform usual msg processing
@@ -372,25 +457,7 @@ func (ch *chain) Order(env *cb.Envelope, configSeq uint64) error {
 	}
 
 	//if everything ok, proceed
-	poolindex = (poolindex + 1) % poolsize
-
-	_, err := sendEnvToBFTProxy(false, ch.support.ChainID(), env, poolindex)
-
-	if err != nil {
-
-		return err
-	}
-
-	// I want the orderer to wait for reception on the main loop
-	select {
-
-	case <-ch.exitChan:
-		return fmt.Errorf("Exiting")
-	default: // avoid blocking
-		return nil
-	}
-
-	//return true
+	return ch.sendEnvToBFTProxy(false, env)
 }
 
 // Configure accepts configuration update messages for ordering
@@ -409,63 +476,228 @@ func (ch *chain) Configure(impetus *cb.Envelope, config *cb.Envelope, configSeq
 	}
 
 	//if everything ok, proceed
-	poolindex = (poolindex + 1) % poolsize
+	return ch.sendEnvToBFTProxy(true, msg)
+}
+
+// recvFrame returns the next frame sent by the java component on the receive
+// connection, answering heartbeats along the way.
+func (ch *chain) recvFrame() (*protocol.Frame, error) {
+
+	for {
+		f, err := protocol.ReadFrame(ch.recvProxy, protocol.DefaultMaxFrameSize)
+		if err != nil {
+			return nil, err
+		}
 
-	_, err := sendEnvToBFTProxy(true, ch.support.ChainID(), msg, poolindex)
+		switch f.Kind {
+		case protocol.MsgHeartbeat:
+			// heartbeats may tell how many replicas the java component reaches
+			if replicas, err := f.GetUint32(protocol.FieldConnectedReplicas); err == nil {
+				ch.lock.Lock()
+				ch.connectedReplicas = replicas
+				ch.lock.Unlock()
+			}
+			if err := protocol.WriteFrame(ch.recvProxy, protocol.NewFrame(protocol.MsgHeartbeat, f.ID)); err != nil {
+				return nil, err
+			}
+		case protocol.MsgError:
+			return nil, f.AsError()
+		default:
+			return f, nil
+		}
+	}
+}
+
+// delivery is a block received from the java component, along with what the
+// block type frame tells about it.
+type delivery struct {
+	block    *cb.Block
+	isConfig bool
+	metadata *ab.BFTsmartMetadata
+
+	// id is the ID of the request, so that the block can be rejected
+	id uint64
+}
 
+// recvBlock receives a block frame, followed by the block type frame answering
+// the same request, which carries the consensus metadata of the block.
+func (ch *chain) recvBlock() (*delivery, error) {
+
+	f, err := ch.recvFrame()
 	if err != nil {
+		return nil, fmt.Errorf("error while receiving block: %s", err)
+	}
 
-		return err
+	if f.Kind != protocol.MsgBlock {
+		err = fmt.Errorf("expected %s frame but received %s frame", protocol.MsgBlock, f.Kind)
+		protocol.RejectFrame(ch.recvProxy, f.ID, err)
+		return nil, err
 	}
 
-	select {
+	bytes, err := f.GetBytes(protocol.FieldPayload)
+	if err != nil {
+		protocol.RejectFrame(ch.recvProxy, f.ID, err)
+		return nil, err
+	}
 
-	case <-ch.exitChan:
-		return fmt.Errorf("Exiting")
-	default: //avoid blocking
-		return nil
+	block, err := utils.GetBlockFromBlockBytes(bytes)
+	if err != nil {
+		err = fmt.Errorf("error while unmarshaling block: %s", err)
+		protocol.RejectFrame(ch.recvProxy, f.ID, err)
+		return nil, err
+	}
+
+	if block.Header == nil {
+		err = fmt.Errorf("received block without header")
+		protocol.RejectFrame(ch.recvProxy, f.ID, err)
+		return nil, err
+	}
+
+	t, err := ch.recvFrame()
+	if err != nil {
+		return nil, fmt.Errorf("error while receiving block type: %s", err)
+	}
+
+	if t.Kind != protocol.MsgBlockType || t.ID != f.ID {
+		err = fmt.Errorf("expected %s frame for request %d but received %s frame for request %d", protocol.MsgBlockType, f.ID, t.Kind, t.ID)
+		protocol.RejectFrame(ch.recvProxy, t.ID, err)
+		return nil, err
+	}
+
+	isConfig, err := t.GetBool(protocol.FieldIsConfig)
+	if err != nil {
+		protocol.RejectFrame(ch.recvProxy, t.ID, err)
+		return nil, err
+	}
+
+	metadata, err := t.GetConsensusMetadata()
+	if err != nil {
+		protocol.RejectFrame(ch.recvProxy, t.ID, err)
+		return nil, err
 	}
 
+	return &delivery{block: block, isConfig: isConfig, metadata: metadata, id: f.ID}, nil
 }
 
 func (ch *chain) connLoop() {
 
+	defer ch.done.Done()
+	defer ch.disconnect()
+
+	// lastHeader is the header of the last block handed to appendToChain, which
+	// is where delivery must resume after a reconnection
+	lastHeader := ch.support.GetLastBlock().Header
+	lastMetadata := ch.lastMetadata
+
+	// rejected blocks are likely to be sent again, so reconnections following
+	// a rejection are delayed
+	bo := newBackoff(minReconnectInterval, maxReconnectInterval)
+
 	for {
 
-		//receive a marshalled block
-		bytes, err := ch.recvBytes()
+		if ch.recvProxy == nil {
+			if err := ch.connect(lastHeader, lastMetadata); err != nil {
+				select {
+				case <-ch.exitChan:
+					logger.Infof("[channel: %s] Exiting receive loop: %s", ch.support.ChainID(), err)
+					return
+				default:
+				}
+
+				// the batch configuration may not have been acknowledged in time
+				logger.Warningf("[channel: %s] Could not connect to java component, retrying: %s", ch.support.ChainID(), err)
+				if !sleep(bo.next(), ch.exitChan) {
+					logger.Debugf("[channel: %s] Exiting receive loop", ch.support.ChainID())
+					return
+				}
+				continue
+			}
+		}
+
+		d, err := ch.recvBlock()
 		if err != nil {
-			logger.Debugf("Error while receiving block from java component: %v\n", err)
+			select {
+			case <-ch.exitChan:
+				logger.Debugf("[channel: %s] Exiting receive loop", ch.support.ChainID())
+				return
+			default:
+			}
+
+			logger.Warningf("[channel: %s] Lost connection to java component, reconnecting: %s", ch.support.ChainID(), err)
+			ch.disconnect()
 			continue
 		}
 
-		block, err := utils.GetBlockFromBlockBytes(bytes)
-		if err != nil {
-			logger.Debugf("Error while unmarshaling block from java component: %v\n", err)
+		block := d.block
+
+		switch {
+		case block.Header.Number <= lastHeader.Number:
+			logger.Debugf("[channel: %s] Discarding block %d, it was already received", ch.support.ChainID(), block.Header.Number)
+			continue
+		case block.Header.Number != lastHeader.Number+1:
+			logger.Warningf("[channel: %s] Expected block %d but received block %d, resynchronizing", ch.support.ChainID(), lastHeader.Number+1, block.Header.Number)
+			ch.disconnect()
 			continue
 		}
 
-		//receive block type
-		bytes, err = ch.recvBytes()
+		err = ch.verifier.verify(block, lastHeader)
+		if err == nil {
+			err = verifyConsensusMetadata(d.metadata, lastMetadata)
+		}
 		if err != nil {
-			logger.Debugf("Error while receiving block type from java component: %v\n", err)
+			logger.Errorf("[channel: %s] Rejecting block %d received from java component: %s", ch.support.ChainID(), block.Header.Number, err)
+			protocol.RejectFrame(ch.recvProxy, d.id, err)
+			ch.disconnect()
+
+			if !sleep(bo.next(), ch.exitChan) {
+				logger.Debugf("[channel: %s] Exiting receive loop", ch.support.ChainID())
+				return
+			}
 			continue
 		}
+		bo.reset()
+
+		// the consensus metadata is persisted with the block, for the chain
+		// to resume from it after a restart
+		block.Metadata.Metadata[cb.BlockMetadataIndex_ORDERER] = utils.MarshalOrPanic(&cb.Metadata{Value: utils.MarshalOrPanic(d.metadata)})
+
+		sendChan := ch.sendChanRegular
+		if d.isConfig {
+			sendChan = ch.sendChanConfig
+		}
 
-		if bytes[0] == 1 {
+		select {
+		case sendChan <- block:
+			lastHeader = block.Header
+			lastMetadata = d.metadata
 
-			ch.sendChanConfig <- block
-		} else {
+			ch.lock.Lock()
+			ch.lastBlock = block.Header.Number
+			ch.lock.Unlock()
+		case <-ch.exitChan:
+			logger.Debugf("[channel: %s] Exiting receive loop", ch.support.ChainID())
+			return
+		}
 
-			ch.sendChanRegular <- block
+		if !d.isConfig {
+			continue
 		}
 
+		// the next block must be verified against the new configuration
+		select {
+		case <-ch.configApplied:
+		case <-ch.exitChan:
+			logger.Debugf("[channel: %s] Exiting receive loop", ch.support.ChainID())
+			return
+		}
 	}
 }
 
 func (ch *chain) appendToChain() {
 	//var timer <-chan time.Time //original timer to flush the blockcutter
 
+	defer ch.done.Done()
+
 	for {
 
 		select {
@@ -473,17 +705,48 @@ func (ch *chain) appendToChain() {
 		//I want the orderer to wait for reception from the java component
 		case block := <-ch.sendChanRegular:
 
-			err := ch.support.AppendBlock(block)
-			if err != nil {
-				logger.Panicf("Could not append regular block: %s", err)
+			if err := ch.support.AppendBlock(block); err != nil {
+				logger.Errorf("[channel: %s] Could not append block %d, halting: %s", ch.support.ChainID(), block.Header.Number, err)
+				ch.halt()
+				return
 			}
 
 		case block := <-ch.sendChanConfig:
 
+			ch.configLock.Lock()
+
 			ch.support.ProcessConfigBlock(block)
-			err := ch.support.AppendBlock(block)
+			if err := ch.support.AppendBlock(block); err != nil {
+				ch.configLock.Unlock()
+				logger.Errorf("[channel: %s] Could not append config block %d, halting: %s", ch.support.ChainID(), block.Header.Number, err)
+				ch.halt()
+				return
+			}
+
+			// the config block may have changed the batch configuration of the
+			// channel; sending it is bounded by the acknowledgement timeout, so
+			// that envelopes are not held back for longer
+			err := ch.sendBatchConfig()
+
+			ch.configLock.Unlock()
+
 			if err != nil {
-				logger.Panicf("Could not append configuration block: %s", err)
+				if _, ok := err.(*protocol.Error); !ok {
+					// the java component would keep cutting batches with the
+					// previous configuration, which other orderers may not do
+					logger.Errorf("[channel: %s] Could not send batch configuration of config block %d to java component, halting: %s", ch.support.ChainID(), block.Header.Number, err)
+					ch.halt()
+					return
+				}
+				// the java component keeps using its previous batch configuration
+				logger.Errorf("[channel: %s] Java component rejected batch configuration: %s", ch.support.ChainID(), err)
+			}
+
+			select {
+			case ch.configApplied <- struct{}{}:
+			case <-ch.exitChan:
+				logger.Debugf("Exiting...")
+				return
 			}
 
 		case <-ch.exitChan:
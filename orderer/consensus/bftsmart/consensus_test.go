@@ -0,0 +1,1148 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bftsmart
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/config/channel"
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	"github.com/hyperledger/fabric/msp"
+	localconfig "github.com/hyperledger/fabric/orderer/common/localconfig"
+	"github.com/hyperledger/fabric/orderer/consensus"
+	"github.com/hyperledger/fabric/orderer/consensus/bftsmart/protocol"
+	"github.com/hyperledger/fabric/orderer/consensus/bftsmart/standin"
+	mockmultichannel "github.com/hyperledger/fabric/orderer/mocks/common/multichannel"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const testChainID = "foo"
+
+func newTestEnvelope(chainID string, data string) *cb.Envelope {
+	return &cb.Envelope{
+		Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{ChannelId: chainID})},
+			Data:   []byte(data),
+		}),
+	}
+}
+
+func newTestSharedConfig(maxMessageCount uint32, batchTimeout time.Duration) *mockconfig.Orderer {
+	return &mockconfig.Orderer{
+		BatchSizeVal: &ab.BatchSize{
+			MaxMessageCount:   maxMessageCount,
+			AbsoluteMaxBytes:  10 * 1024 * 1024,
+			PreferredMaxBytes: 1024 * 1024,
+		},
+		BatchTimeoutVal:  batchTimeout,
+		OrganizationsVal: testOrganizations,
+	}
+}
+
+func newMockSupport(chainID string) *mockmultichannel.ConsenterSupport {
+	genesis := cb.NewBlock(0, nil)
+	genesis.Header.DataHash = genesis.Data.Hash()
+
+	return &mockmultichannel.ConsenterSupport{
+		ChainIDVal:      chainID,
+		Blocks:          make(chan *cb.Block, 100),
+		HeightVal:       1,
+		LastBlockVal:    genesis,
+		SharedConfigVal: newTestSharedConfig(2, time.Hour),
+		MSPManagerVal:   newTestMSPManager(testReplicas...),
+	}
+}
+
+// reconfigurableSupport switches to a new orderer configuration when it
+// processes a config block, like the real ConsenterSupport does.
+type reconfigurableSupport struct {
+	*mockmultichannel.ConsenterSupport
+
+	lock         sync.Mutex
+	sharedConfig *mockconfig.Orderer
+	nextConfig   *mockconfig.Orderer
+}
+
+func (rs *reconfigurableSupport) SharedConfig() config.Orderer {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+	return rs.sharedConfig
+}
+
+func (rs *reconfigurableSupport) ProcessConfigBlock(block *cb.Block) {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+	rs.sharedConfig = rs.nextConfig
+	rs.ConsenterSupport.ProcessConfigBlock(block)
+}
+
+func newTestConsenter(t *testing.T, p *standin.StandIn) *consenter {
+	// the replicas signing the blocks may be fewer than the ordering service has
+	config := p.Config()
+	config.Replicas = uint32(len(testReplicas))
+	bftsmart, err := newConsenter(config)
+	if err != nil {
+		t.Fatalf("Could not create consenter: %s", err)
+	}
+	return bftsmart
+}
+
+func (bftsmart *consenter) closeConns() {
+	bftsmart.control.close()
+	bftsmart.pool.close()
+}
+
+func newTestProxy(t *testing.T, signers ...msp.SigningIdentity) *standin.StandIn {
+	if len(signers) == 0 {
+		signers = testReplicas
+	}
+
+	p, err := standin.New(signers...)
+	if err != nil {
+		t.Fatalf("Could not start stand-in proxy: %s", err)
+	}
+	return p
+}
+
+func expectBlock(t *testing.T, support *mockmultichannel.ConsenterSupport) *cb.Block {
+	select {
+	case block := <-support.Blocks:
+		return block
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected a block to be appended")
+		return nil
+	}
+}
+
+func expectNoBlock(t *testing.T, support *mockmultichannel.ConsenterSupport) {
+	select {
+	case block := <-support.Blocks:
+		t.Fatalf("Expected no block to be appended, got block %d", block.Header.Number)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+// waitConnected waits for the chain to be connected to the java component.
+func waitConnected(t *testing.T, ch *chain) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !ch.Status().Connected {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the chain to connect to the java component")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func expectBatchConfig(t *testing.T, p *standin.StandIn) *protocol.Frame {
+	select {
+	case f := <-p.BatchConfigs():
+		return f
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected a batch configuration to be sent")
+		return nil
+	}
+}
+
+// assertNextBlock checks that block follows previous, and carries the given envelopes.
+func assertNextBlock(t *testing.T, previous *cb.Block, block *cb.Block, envs ...*cb.Envelope) {
+	assert.Equal(t, previous.Header.Number+1, block.Header.Number, "Unexpected block number")
+	assert.Equal(t, previous.Header.Hash(), block.Header.PreviousHash, "Unexpected previous hash")
+	assert.Equal(t, block.Data.Hash(), block.Header.DataHash, "Unexpected data hash")
+
+	if assert.Len(t, block.Data.Data, len(envs)) {
+		for i, env := range envs {
+			assert.True(t, bytes.Equal(utils.MarshalOrPanic(env), block.Data.Data[i]), "Unexpected envelope %d in block %d", i, block.Header.Number)
+		}
+	}
+}
+
+func TestOrder(t *testing.T) {
+	p := newTestProxy(t)
+	defer p.Close()
+
+	bftsmart := newTestConsenter(t, p)
+	defer bftsmart.closeConns()
+
+	support := newMockSupport(testChainID)
+	genesis := support.LastBlockVal
+	ch := newChain(bftsmart, support, nil)
+	ch.Start()
+	defer ch.Halt()
+
+	f := expectBatchConfig(t, p)
+	maxMessageCount, _ := f.GetUint32(protocol.FieldMaxMessageCount)
+	assert.Equal(t, uint32(2), maxMessageCount, "Expected the batch configuration of the chain to be sent on connection")
+
+	envs := make([]*cb.Envelope, 4)
+	for i := range envs {
+		envs[i] = newTestEnvelope(testChainID, fmt.Sprintf("message %d", i))
+		assert.NoError(t, ch.Order(envs[i], 0))
+	}
+
+	block1 := expectBlock(t, support)
+	assertNextBlock(t, genesis, block1, envs[0], envs[1])
+
+	block2 := expectBlock(t, support)
+	assertNextBlock(t, block1, block2, envs[2], envs[3])
+
+	assert.Nil(t, support.ConfigBlockVal, "Expected no config block to be processed")
+}
+
+func TestBatchTimeout(t *testing.T) {
+	p := newTestProxy(t)
+	defer p.Close()
+
+	bftsmart := newTestConsenter(t, p)
+	defer bftsmart.closeConns()
+
+	support := newMockSupport(testChainID)
+	support.SharedConfigVal = newTestSharedConfig(10, 50*time.Millisecond)
+	genesis := support.LastBlockVal
+	ch := newChain(bftsmart, support, nil)
+	ch.Start()
+	defer ch.Halt()
+
+	env := newTestEnvelope(testChainID, "lonely message")
+	assert.NoError(t, ch.Order(env, 0))
+
+	assertNextBlock(t, genesis, expectBlock(t, support), env)
+}
+
+func TestMultipleChains(t *testing.T) {
+	p := newTestProxy(t)
+	defer p.Close()
+
+	bftsmart := newTestConsenter(t, p)
+	defer bftsmart.closeConns()
+
+	supports := []*mockmultichannel.ConsenterSupport{newMockSupport("foo"), newMockSupport("bar")}
+	chains := make([]*chain, len(supports))
+	for i, support := range supports {
+		chains[i] = newChain(bftsmart, support, nil)
+		chains[i].Start()
+		defer chains[i].Halt()
+	}
+
+	for i, support := range supports {
+		genesis := support.LastBlockVal
+		env1 := newTestEnvelope(support.ChainIDVal, "first")
+		env2 := newTestEnvelope(support.ChainIDVal, "second")
+		assert.NoError(t, chains[i].Order(env1, 0))
+		assert.NoError(t, chains[i].Order(env2, 0))
+
+		assertNextBlock(t, genesis, expectBlock(t, support), env1, env2)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	t.Run("Proper", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+
+		bftsmart := newTestConsenter(t, p)
+		defer bftsmart.closeConns()
+
+		support := newMockSupport(testChainID)
+		genesis := support.LastBlockVal
+		ch := newChain(bftsmart, support, nil)
+		ch.Start()
+		defer ch.Halt()
+
+		expectBatchConfig(t, p)
+
+		env := newTestEnvelope(testChainID, "pending message")
+		configEnv := newTestEnvelope(testChainID, "config message")
+		assert.NoError(t, ch.Order(env, 0))
+		assert.NoError(t, ch.Configure(configEnv, configEnv, 0))
+
+		block1 := expectBlock(t, support)
+		assertNextBlock(t, genesis, block1, env)
+
+		block2 := expectBlock(t, support)
+		assertNextBlock(t, block1, block2, configEnv)
+		assert.Equal(t, block2, support.ConfigBlockVal, "Expected the config block to be processed")
+
+		expectBatchConfig(t, p)
+	})
+
+	t.Run("BatchSizeUpdate", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+
+		bftsmart := newTestConsenter(t, p)
+		defer bftsmart.closeConns()
+
+		support := &reconfigurableSupport{
+			ConsenterSupport: newMockSupport(testChainID),
+			sharedConfig:     newTestSharedConfig(1, time.Hour),
+			nextConfig:       newTestSharedConfig(3, time.Hour),
+		}
+		ch := newChain(bftsmart, support, nil)
+		ch.Start()
+		defer ch.Halt()
+
+		configEnv := newTestEnvelope(testChainID, "config message")
+		assert.NoError(t, ch.Configure(configEnv, configEnv, 0))
+		configBlock := expectBlock(t, support.ConsenterSupport)
+
+		envs := make([]*cb.Envelope, 3)
+		for i := range envs {
+			envs[i] = newTestEnvelope(testChainID, fmt.Sprintf("message %d", i))
+			assert.NoError(t, ch.Order(envs[i], 0))
+		}
+
+		assertNextBlock(t, configBlock, expectBlock(t, support.ConsenterSupport), envs...)
+	})
+
+	t.Run("Revalidated", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+
+		bftsmart := newTestConsenter(t, p)
+		defer bftsmart.closeConns()
+
+		support := newMockSupport(testChainID)
+		support.SequenceVal = 1
+		support.ProcessConfigUpdateMsgVal = newTestEnvelope(testChainID, "revalidated config message")
+		genesis := support.LastBlockVal
+		ch := newChain(bftsmart, support, nil)
+		ch.Start()
+		defer ch.Halt()
+
+		staleEnv := newTestEnvelope(testChainID, "stale config message")
+		assert.NoError(t, ch.Configure(staleEnv, staleEnv, 0))
+
+		assertNextBlock(t, genesis, expectBlock(t, support), support.ProcessConfigUpdateMsgVal)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+
+		bftsmart := newTestConsenter(t, p)
+		defer bftsmart.closeConns()
+
+		support := newMockSupport(testChainID)
+		support.SequenceVal = 1
+		support.ProcessConfigUpdateMsgErr = fmt.Errorf("invalid config update")
+		ch := newChain(bftsmart, support, nil)
+		ch.Start()
+		defer ch.Halt()
+
+		staleEnv := newTestEnvelope(testChainID, "stale config message")
+		assert.NoError(t, ch.Configure(staleEnv, staleEnv, 0))
+
+		expectNoBlock(t, support)
+	})
+}
+
+func TestHaltBeforeStart(t *testing.T) {
+	p := newTestProxy(t)
+	defer p.Close()
+
+	bftsmart := newTestConsenter(t, p)
+	defer bftsmart.closeConns()
+
+	ch := newChain(bftsmart, newMockSupport(testChainID), nil)
+
+	select {
+	case <-ch.Errored():
+	default:
+		t.Fatalf("Expected Errored to be closed until the chain connects")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ch.Halt()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected Halt to return")
+	}
+}
+
+func TestErrored(t *testing.T) {
+	p := newTestProxy(t)
+	defer p.Close()
+
+	bftsmart := newTestConsenter(t, p)
+	defer bftsmart.closeConns()
+
+	support := newMockSupport(testChainID)
+	genesis := support.LastBlockVal
+	ch := newChain(bftsmart, support, nil)
+	ch.Start()
+	defer ch.Halt()
+
+	expectBatchConfig(t, p)
+	waitConnected(t, ch)
+	errored := ch.Errored()
+
+	p.Stop()
+
+	select {
+	case <-errored:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected Errored to be closed once the connection to the java component is lost")
+	}
+
+	assert.NoError(t, p.Restart())
+	expectBatchConfig(t, p)
+	waitConnected(t, ch)
+
+	select {
+	case <-ch.Errored():
+		t.Fatalf("Expected Errored to be open again once reconnected")
+	default:
+	}
+
+	env1 := newTestEnvelope(testChainID, "first")
+	env2 := newTestEnvelope(testChainID, "second")
+	assert.NoError(t, ch.Order(env1, 0))
+	assert.NoError(t, ch.Order(env2, 0))
+	assertNextBlock(t, genesis, expectBlock(t, support), env1, env2)
+}
+
+func TestStatus(t *testing.T) {
+	p := newTestProxy(t)
+	defer p.Close()
+	p.SetWithholdAcks(true)
+
+	config := p.Config()
+	config.AckTimeout = time.Hour
+	bftsmart, err := newConsenter(config)
+	assert.NoError(t, err)
+	defer bftsmart.closeConns()
+
+	support := newMockSupport(testChainID)
+	ch := newChain(bftsmart, support, nil)
+	other := newChain(bftsmart, newMockSupport("bar"), nil)
+
+	status := ch.Status()
+	assert.Equal(t, Status{ChainID: testChainID}, status, "Expected a chain which is not started to be disconnected")
+
+	ch.Start()
+	defer ch.Halt()
+
+	expectBatchConfig(t, p)
+	waitConnected(t, ch)
+
+	assert.NoError(t, p.Inject(testChainID, protocol.NewFrame(protocol.MsgHeartbeat, protocol.NextCorrelationID()).AddUint32(protocol.FieldConnectedReplicas, 3)))
+
+	// the envelopes are ordered, but never acknowledged
+	for i := 0; i < 2; i++ {
+		go ch.Order(newTestEnvelope(testChainID, fmt.Sprintf("message %d", i)), 0)
+	}
+	expectBlock(t, support)
+
+	assert.Equal(t, Status{
+		ChainID:           testChainID,
+		Connected:         true,
+		ConnectedReplicas: 3,
+		LastBlock:         1,
+		InFlight:          2,
+	}, ch.Status())
+
+	assert.Equal(t, []Status{other.Status(), ch.Status()}, bftsmart.Status(), "Expected the chains to be sorted by ID")
+
+	t.Run("HTTP", func(t *testing.T) {
+		server := httptest.NewServer(bftsmart)
+		defer server.Close()
+
+		resp, err := http.Get(server.URL)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var statuses []Status
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&statuses))
+		assert.Equal(t, bftsmart.Status(), statuses)
+
+		resp, err = http.Post(server.URL, "application/json", nil)
+		if assert.NoError(t, err) {
+			resp.Body.Close()
+			assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+		}
+	})
+
+	ch.Halt()
+	assert.False(t, ch.Status().Connected)
+	assert.Equal(t, uint32(0), ch.Status().ConnectedReplicas)
+}
+
+func TestOrderRevalidation(t *testing.T) {
+	p := newTestProxy(t)
+	defer p.Close()
+
+	bftsmart := newTestConsenter(t, p)
+	defer bftsmart.closeConns()
+
+	support := newMockSupport(testChainID)
+	support.SharedConfigVal = newTestSharedConfig(1, time.Hour)
+	support.SequenceVal = 1
+	support.ProcessNormalMsgErr = fmt.Errorf("invalid message")
+	genesis := support.LastBlockVal
+	ch := newChain(bftsmart, support, nil)
+	ch.Start()
+	defer ch.Halt()
+
+	staleEnv := newTestEnvelope(testChainID, "stale message")
+	assert.NoError(t, ch.Order(staleEnv, 0), "Expected invalid messages to be discarded silently")
+
+	freshEnv := newTestEnvelope(testChainID, "fresh message")
+	assert.NoError(t, ch.Order(freshEnv, 1))
+
+	assertNextBlock(t, genesis, expectBlock(t, support), freshEnv)
+	expectNoBlock(t, support)
+}
+
+func TestHalt(t *testing.T) {
+	p := newTestProxy(t)
+	defer p.Close()
+
+	bftsmart := newTestConsenter(t, p)
+	defer bftsmart.closeConns()
+
+	support := newMockSupport(testChainID)
+	ch := newChain(bftsmart, support, nil)
+	ch.Start()
+
+	expectBatchConfig(t, p)
+	waitConnected(t, ch)
+
+	select {
+	case <-ch.Errored():
+		t.Fatalf("Expected Errored to remain open while the chain runs")
+	default:
+	}
+
+	// Halt returns once both loops exited, having closed the connection
+	ch.Halt()
+	assert.NotPanics(t, ch.Halt, "Expected multiple halts to be allowed")
+	assert.Nil(t, ch.recvProxy, "Expected the receive connection to be closed")
+	assert.False(t, ch.Status().Connected)
+
+	select {
+	case <-ch.Errored():
+	default:
+		t.Fatalf("Expected Errored to be closed once halted")
+	}
+
+	assert.Error(t, ch.Order(newTestEnvelope(testChainID, "late message"), 0))
+	assert.Error(t, ch.Configure(newTestEnvelope(testChainID, "late config"), newTestEnvelope(testChainID, "late config"), 0))
+}
+
+func TestProxyFailure(t *testing.T) {
+	t.Run("Restart", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+
+		bftsmart := newTestConsenter(t, p)
+		defer bftsmart.closeConns()
+
+		support := newMockSupport(testChainID)
+		genesis := support.LastBlockVal
+		ch := newChain(bftsmart, support, nil)
+		ch.Start()
+		defer ch.Halt()
+
+		expectBatchConfig(t, p)
+
+		envs := make([]*cb.Envelope, 4)
+		for i := range envs {
+			envs[i] = newTestEnvelope(testChainID, fmt.Sprintf("message %d", i))
+		}
+
+		assert.NoError(t, ch.Order(envs[0], 0))
+		assert.NoError(t, ch.Order(envs[1], 0))
+		block1 := expectBlock(t, support)
+		assertNextBlock(t, genesis, block1, envs[0], envs[1])
+
+		p.Stop()
+		time.Sleep(100 * time.Millisecond)
+		assert.NoError(t, p.Restart())
+
+		expectBatchConfig(t, p)
+
+		assert.NoError(t, ch.Order(envs[2], 0))
+		assert.NoError(t, ch.Order(envs[3], 0))
+		assertNextBlock(t, block1, expectBlock(t, support), envs[2], envs[3])
+		expectNoBlock(t, support)
+	})
+
+	t.Run("Unavailable", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+
+		bftsmart := newTestConsenter(t, p)
+		defer bftsmart.closeConns()
+
+		p.Stop()
+
+		support := newMockSupport(testChainID)
+		genesis := support.LastBlockVal
+		ch := newChain(bftsmart, support, nil)
+		ch.Start()
+		defer ch.Halt()
+
+		env1 := newTestEnvelope(testChainID, "first")
+		env2 := newTestEnvelope(testChainID, "second")
+		done := make(chan error, 2)
+		go func() {
+			done <- ch.Order(env1, 0)
+			done <- ch.Order(env2, 0)
+		}()
+
+		time.Sleep(200 * time.Millisecond)
+		assert.NoError(t, p.Restart())
+
+		assert.NoError(t, <-done)
+		assert.NoError(t, <-done)
+		assertNextBlock(t, genesis, expectBlock(t, support), env1, env2)
+	})
+
+	t.Run("Down", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+
+		config := p.Config()
+		config.AckTimeout = 500 * time.Millisecond
+		bftsmart, err := newConsenter(config)
+		assert.NoError(t, err)
+		defer bftsmart.closeConns()
+
+		p.Stop()
+
+		ch := newChain(bftsmart, newMockSupport(testChainID), nil)
+		ch.Start()
+		defer ch.Halt()
+
+		start := time.Now()
+		err = ch.Order(newTestEnvelope(testChainID, "first"), 0)
+		if assert.Error(t, err, "Expected envelopes to be rejected while the java component is down") {
+			assert.Equal(t, consensus.ErrServiceUnavailable, errors.Cause(err))
+		}
+		assert.True(t, time.Since(start) < 2*config.AckTimeout, "Expected Order to return within the acknowledgement timeout, took %s", time.Since(start))
+		assert.Len(t, ch.inflight, 0, "Expected the envelope to leave the window")
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+
+		bftsmart := newTestConsenter(t, p)
+		defer bftsmart.closeConns()
+
+		support := newMockSupport(testChainID)
+		ch := newChain(bftsmart, support, nil)
+		ch.Start()
+
+		expectBatchConfig(t, p)
+
+		for i := 0; i < 4; i++ {
+			assert.NoError(t, ch.Order(newTestEnvelope(testChainID, fmt.Sprintf("message %d", i)), 0))
+		}
+		block1 := expectBlock(t, support)
+		block2 := expectBlock(t, support)
+		ch.Halt()
+
+		// an orderer restarting from block 1 is sent the following blocks only
+		restarted := newMockSupport(testChainID)
+		restarted.LastBlockVal = block1
+		ch = newChain(bftsmart, restarted, nil)
+		ch.Start()
+		defer ch.Halt()
+
+		assert.Equal(t, block2, expectBlock(t, restarted))
+		expectNoBlock(t, restarted)
+	})
+
+	t.Run("HandshakeRejected", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+		p.SetVersion(protocol.Version + 1)
+
+		bftsmart := newTestConsenter(t, p)
+		defer bftsmart.closeConns()
+
+		support := newMockSupport(testChainID)
+		ch := newChain(bftsmart, support, nil)
+		ch.Start()
+
+		done := make(chan error)
+		go func() {
+			done <- ch.Order(newTestEnvelope(testChainID, "message"), 0)
+		}()
+
+		expectNoBlock(t, support)
+		assert.Empty(t, p.Delivered(testChainID))
+
+		ch.Halt()
+		select {
+		case err := <-done:
+			assert.Error(t, err, "Expected ordering to fail once halted")
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected ordering to give up once halted")
+		}
+	})
+
+	t.Run("BatchConfigRejected", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+		p.SetRejectBatchConfig(true)
+
+		bftsmart := newTestConsenter(t, p)
+		defer bftsmart.closeConns()
+
+		support := newMockSupport(testChainID)
+		genesis := support.LastBlockVal
+		ch := newChain(bftsmart, support, nil)
+		ch.Start()
+		defer ch.Halt()
+
+		expectBatchConfig(t, p)
+
+		// the proxy keeps cutting batches of 10 messages, or after a second
+		env := newTestEnvelope(testChainID, "message")
+		assert.NoError(t, ch.Order(env, 0))
+		expectNoBlock(t, support)
+		assertNextBlock(t, genesis, expectBlock(t, support), env)
+	})
+
+	t.Run("DuplicateAndGap", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+
+		bftsmart := newTestConsenter(t, p)
+		defer bftsmart.closeConns()
+
+		support := newMockSupport(testChainID)
+		ch := newChain(bftsmart, support, nil)
+		ch.Start()
+		defer ch.Halt()
+
+		expectBatchConfig(t, p)
+
+		env1 := newTestEnvelope(testChainID, "first")
+		env2 := newTestEnvelope(testChainID, "second")
+		assert.NoError(t, ch.Order(env1, 0))
+		assert.NoError(t, ch.Order(env2, 0))
+		block1 := expectBlock(t, support)
+
+		id := protocol.NextCorrelationID()
+		assert.NoError(t, p.Inject(testChainID,
+			protocol.NewFrame(protocol.MsgBlock, id).AddBytes(protocol.FieldPayload, utils.MarshalOrPanic(block1)),
+			protocol.NewFrame(protocol.MsgBlockType, id).AddBool(protocol.FieldIsConfig, false).AddConsensusMetadata(&ab.BFTsmartMetadata{})))
+		expectNoBlock(t, support)
+
+		future := cb.NewBlock(block1.Header.Number+5, block1.Header.Hash())
+		id = protocol.NextCorrelationID()
+		assert.NoError(t, p.Inject(testChainID,
+			protocol.NewFrame(protocol.MsgBlock, id).AddBytes(protocol.FieldPayload, utils.MarshalOrPanic(future)),
+			protocol.NewFrame(protocol.MsgBlockType, id).AddBool(protocol.FieldIsConfig, false).AddConsensusMetadata(&ab.BFTsmartMetadata{})))
+		expectNoBlock(t, support)
+
+		// the chain resynchronized after the gap, and keeps ordering
+		expectBatchConfig(t, p)
+		env3 := newTestEnvelope(testChainID, "third")
+		env4 := newTestEnvelope(testChainID, "fourth")
+		assert.NoError(t, ch.Order(env3, 0))
+		assert.NoError(t, ch.Order(env4, 0))
+		assertNextBlock(t, block1, expectBlock(t, support), env3, env4)
+	})
+
+	t.Run("CorruptBlock", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+
+		bftsmart := newTestConsenter(t, p)
+		defer bftsmart.closeConns()
+
+		support := newMockSupport(testChainID)
+		genesis := support.LastBlockVal
+		ch := newChain(bftsmart, support, nil)
+		ch.Start()
+		defer ch.Halt()
+
+		expectBatchConfig(t, p)
+
+		id := protocol.NextCorrelationID()
+		assert.NoError(t, p.Inject(testChainID, protocol.NewFrame(protocol.MsgBlock, id).AddBytes(protocol.FieldPayload, []byte("garbage"))))
+
+		select {
+		case f := <-p.Rejections():
+			assert.Equal(t, id, f.ID, "Expected the corrupt block to be rejected")
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected the corrupt block to be rejected")
+		}
+
+		// the chain reconnected, and keeps ordering
+		expectBatchConfig(t, p)
+		env1 := newTestEnvelope(testChainID, "first")
+		env2 := newTestEnvelope(testChainID, "second")
+		assert.NoError(t, ch.Order(env1, 0))
+		assert.NoError(t, ch.Order(env2, 0))
+		assertNextBlock(t, genesis, expectBlock(t, support), env1, env2)
+	})
+}
+
+func TestConsensusMetadata(t *testing.T) {
+	getMetadata := func(t *testing.T, block *cb.Block) *ab.BFTsmartMetadata {
+		md, err := utils.GetMetadataFromBlock(block, cb.BlockMetadataIndex_ORDERER)
+		assert.NoError(t, err)
+		metadata, err := getLastMetadata(md.Value)
+		assert.NoError(t, err)
+		return metadata
+	}
+
+	t.Run("Persisted", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+
+		bftsmart := newTestConsenter(t, p)
+		defer bftsmart.closeConns()
+
+		support := newMockSupport(testChainID)
+		ch, err := bftsmart.HandleChain(support, &cb.Metadata{})
+		assert.NoError(t, err)
+		ch.Start()
+		defer ch.Halt()
+
+		expectBatchConfig(t, p)
+		assert.Nil(t, p.Announced(testChainID), "Expected a new chain to announce no consensus metadata")
+
+		for i := 0; i < 4; i++ {
+			assert.NoError(t, ch.Order(newTestEnvelope(testChainID, fmt.Sprintf("message %d", i)), 0))
+		}
+
+		for seq := uint64(1); seq <= 2; seq++ {
+			metadata := getMetadata(t, expectBlock(t, support))
+			if assert.NotNil(t, metadata, "Expected the consensus metadata to be persisted") {
+				assert.Equal(t, seq, metadata.Sequence)
+				assert.Equal(t, standin.ReplicaID, metadata.ReplicaId)
+			}
+		}
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+
+		bftsmart := newTestConsenter(t, p)
+		defer bftsmart.closeConns()
+
+		support := newMockSupport(testChainID)
+		ch := newChain(bftsmart, support, nil)
+		ch.Start()
+
+		expectBatchConfig(t, p)
+		assert.NoError(t, ch.Order(newTestEnvelope(testChainID, "first"), 0))
+		assert.NoError(t, ch.Order(newTestEnvelope(testChainID, "second"), 0))
+		block1 := expectBlock(t, support)
+		ch.Halt()
+
+		// the orderer restarts from its ledger
+		restarted := newMockSupport(testChainID)
+		restarted.LastBlockVal = block1
+		restarted.HeightVal = 2
+		resumed, err := bftsmart.HandleChain(restarted, utils.GetMetadataFromBlockOrPanic(block1, cb.BlockMetadataIndex_ORDERER))
+		assert.NoError(t, err)
+		resumed.Start()
+		defer resumed.Halt()
+
+		expectBatchConfig(t, p)
+		announced := p.Announced(testChainID)
+		assert.True(t, proto.Equal(getMetadata(t, block1), announced), "Expected the handshake to carry the consensus metadata of the last block, got %v", announced)
+
+		env3 := newTestEnvelope(testChainID, "third")
+		env4 := newTestEnvelope(testChainID, "fourth")
+		assert.NoError(t, resumed.Order(env3, 0))
+		assert.NoError(t, resumed.Order(env4, 0))
+		block2 := expectBlock(t, restarted)
+		assertNextBlock(t, block1, block2, env3, env4)
+		assert.Equal(t, uint64(2), getMetadata(t, block2).Sequence)
+	})
+
+	t.Run("Regression", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+
+		bftsmart := newTestConsenter(t, p)
+		defer bftsmart.closeConns()
+
+		support := newMockSupport(testChainID)
+		ch := newChain(bftsmart, support, nil)
+		ch.Start()
+		defer ch.Halt()
+
+		expectBatchConfig(t, p)
+		assert.NoError(t, ch.Order(newTestEnvelope(testChainID, "first"), 0))
+		assert.NoError(t, ch.Order(newTestEnvelope(testChainID, "second"), 0))
+		block1 := expectBlock(t, support)
+
+		block := cb.NewBlock(block1.Header.Number+1, block1.Header.Hash())
+		block.Data.Data = [][]byte{utils.MarshalOrPanic(newTestEnvelope(testChainID, "replayed"))}
+		block.Header.DataHash = block.Data.Hash()
+		standin.SignBlock(block, testReplicas...)
+
+		for _, metadata := range []*ab.BFTsmartMetadata{getMetadata(t, block1), nil} {
+			id := protocol.NextCorrelationID()
+			blockType := protocol.NewFrame(protocol.MsgBlockType, id).AddBool(protocol.FieldIsConfig, false)
+			if metadata != nil {
+				blockType.AddConsensusMetadata(metadata)
+			}
+			assert.NoError(t, p.Inject(testChainID, protocol.NewFrame(protocol.MsgBlock, id).AddBytes(protocol.FieldPayload, utils.MarshalOrPanic(block)), blockType))
+
+			select {
+			case f := <-p.Rejections():
+				assert.Equal(t, id, f.ID, "Expected the block to be rejected")
+			case <-time.After(5 * time.Second):
+				t.Fatalf("Expected the block to be rejected")
+			}
+			expectNoBlock(t, support)
+			expectBatchConfig(t, p)
+		}
+	})
+
+	t.Run("Corrupt", func(t *testing.T) {
+		bftsmart, err := newConsenter(localconfig.BFTsmart{
+			ConnectionPoolSize: 1,
+			Replicas:           4,
+			MaxInFlight:        1,
+			AckTimeout:         time.Second,
+			SendAddress:        "tcp://127.0.0.1:1",
+			RecvAddress:        "tcp://127.0.0.1:1",
+		})
+		assert.NoError(t, err)
+
+		_, err = bftsmart.HandleChain(newMockSupport(testChainID), &cb.Metadata{Value: []byte("garbage")})
+		assert.Error(t, err)
+	})
+}
+
+func TestBadBlock(t *testing.T) {
+	t.Run("NotEnoughSignatures", func(t *testing.T) {
+		// only f+1 of the replicas sign, as if the proxy forged the others
+		p := newTestProxy(t, testReplicas[0], testReplicas[1])
+		defer p.Close()
+
+		bftsmart := newTestConsenter(t, p)
+		defer bftsmart.closeConns()
+
+		support := newMockSupport(testChainID)
+		ch := newChain(bftsmart, support, nil)
+		ch.Start()
+		defer ch.Halt()
+
+		expectBatchConfig(t, p)
+
+		assert.NoError(t, ch.Order(newTestEnvelope(testChainID, "first"), 0))
+		assert.NoError(t, ch.Order(newTestEnvelope(testChainID, "second"), 0))
+
+		select {
+		case f := <-p.Rejections():
+			msg, _ := f.GetString(protocol.FieldMessage)
+			assert.Contains(t, msg, "valid signatures")
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected the block to be rejected")
+		}
+		expectNoBlock(t, support)
+
+		// the connection is dropped, but the chain is not halted
+		select {
+		case <-ch.exitChan:
+			t.Fatalf("Expected the chain to keep running")
+		default:
+		}
+	})
+
+	t.Run("AppendFailure", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+
+		bftsmart := newTestConsenter(t, p)
+		defer bftsmart.closeConns()
+
+		support := newMockSupport(testChainID)
+		support.AppendBlockErr = fmt.Errorf("ledger is read-only")
+		ch := newChain(bftsmart, support, nil)
+		ch.Start()
+		defer ch.Halt()
+
+		expectBatchConfig(t, p)
+
+		assert.NoError(t, ch.Order(newTestEnvelope(testChainID, "first"), 0))
+		// the chain may halt before the second envelope is acknowledged
+		ch.Order(newTestEnvelope(testChainID, "second"), 0)
+
+		select {
+		case <-ch.Errored():
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected the chain to halt when the block cannot be appended")
+		}
+	})
+}
+
+func TestBackpressure(t *testing.T) {
+	t.Run("Overload", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+		p.SetWithholdAcks(true)
+
+		config := p.Config()
+		config.MaxInFlight = 1
+		bftsmart, err := newConsenter(config)
+		assert.NoError(t, err)
+		defer bftsmart.closeConns()
+
+		support := newMockSupport(testChainID)
+		ch := newChain(bftsmart, support, nil)
+		ch.Start()
+		defer ch.Halt()
+
+		done := make(chan error)
+		go func() {
+			done <- ch.Order(newTestEnvelope(testChainID, "first"), 0)
+		}()
+
+		for len(ch.inflight) == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		err = ch.Order(newTestEnvelope(testChainID, "second"), 0)
+		if assert.Error(t, err, "Expected envelopes beyond the window to be rejected") {
+			assert.Equal(t, consensus.ErrServiceUnavailable, errors.Cause(err))
+		}
+
+		ch.Halt()
+		assert.Error(t, <-done)
+	})
+
+	t.Run("AckTimeout", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+		p.SetWithholdAcks(true)
+
+		config := p.Config()
+		config.AckTimeout = 100 * time.Millisecond
+		bftsmart, err := newConsenter(config)
+		assert.NoError(t, err)
+		defer bftsmart.closeConns()
+
+		support := newMockSupport(testChainID)
+		ch := newChain(bftsmart, support, nil)
+		ch.Start()
+		defer ch.Halt()
+
+		err = ch.Order(newTestEnvelope(testChainID, "first"), 0)
+		if assert.Error(t, err, "Expected unacknowledged envelopes to be reported") {
+			assert.Equal(t, consensus.ErrServiceUnavailable, errors.Cause(err))
+		}
+		assert.Len(t, ch.inflight, 0, "Expected the envelope to leave the window")
+	})
+
+	t.Run("BatchConfigAckTimeout", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+		p.SetWithholdBatchConfigAcks(true)
+
+		config := p.Config()
+		config.AckTimeout = 100 * time.Millisecond
+		bftsmart, err := newConsenter(config)
+		assert.NoError(t, err)
+		defer bftsmart.closeConns()
+
+		ch := newChain(bftsmart, newMockSupport(testChainID), nil)
+		defer ch.Halt()
+		done := make(chan error)
+		go func() {
+			done <- ch.sendBatchConfig()
+		}()
+
+		// the unacknowledged batch configuration is given up once the
+		// configured timeout expires
+		expectBatchConfig(t, p)
+		select {
+		case err := <-done:
+			assert.Equal(t, errDeadlineExceeded, err)
+		case <-time.After(protocol.DefaultAckTimeout):
+			t.Fatalf("Expected the batch configuration to time out within %s", config.AckTimeout)
+		}
+	})
+
+	t.Run("ConfigBlockBatchConfigAckTimeout", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+
+		config := p.Config()
+		config.AckTimeout = 200 * time.Millisecond
+		bftsmart, err := newConsenter(config)
+		assert.NoError(t, err)
+		defer bftsmart.closeConns()
+
+		support := newMockSupport(testChainID)
+		ch := newChain(bftsmart, support, nil)
+		ch.Start()
+		defer ch.Halt()
+
+		expectBatchConfig(t, p)
+		p.SetWithholdBatchConfigAcks(true)
+
+		configEnv := newTestEnvelope(testChainID, "config message")
+		assert.NoError(t, ch.Configure(configEnv, configEnv, 0))
+		expectBlock(t, support)
+
+		// envelopes are not held back while the batch configuration of the
+		// config block is left unacknowledged, and the chain halts
+		select {
+		case <-ch.exitChan:
+		case <-time.After(protocol.DefaultAckTimeout):
+			t.Fatalf("Expected the chain to halt once the batch configuration timed out")
+		}
+		assert.Error(t, ch.Order(newTestEnvelope(testChainID, "message"), 0))
+	})
+
+	t.Run("WindowReleased", func(t *testing.T) {
+		p := newTestProxy(t)
+		defer p.Close()
+
+		config := p.Config()
+		config.MaxInFlight = 1
+		bftsmart, err := newConsenter(config)
+		assert.NoError(t, err)
+		defer bftsmart.closeConns()
+
+		support := newMockSupport(testChainID)
+		genesis := support.LastBlockVal
+		ch := newChain(bftsmart, support, nil)
+		ch.Start()
+		defer ch.Halt()
+
+		env1 := newTestEnvelope(testChainID, "first")
+		env2 := newTestEnvelope(testChainID, "second")
+		assert.NoError(t, ch.Order(env1, 0))
+		assert.NoError(t, ch.Order(env2, 0), "Expected acknowledged envelopes to leave the window")
+		assertNextBlock(t, genesis, expectBlock(t, support), env1, env2)
+	})
+}
@@ -0,0 +1,83 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bftsmart
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/core/comm"
+	localconfig "github.com/hyperledger/fabric/orderer/common/localconfig"
+)
+
+const (
+	unixScheme = "unix://"
+	tcpScheme  = "tcp://"
+)
+
+// endpoint is an address at which the java component accepts connections.
+type endpoint struct {
+	network string
+	address string
+	// tlsConfig is only set for tcp endpoints, when TLS is enabled
+	tlsConfig *tls.Config
+}
+
+// newEndpoint parses an address of the form unix:///path/to/socket or
+// tcp://host:port. TLS is used for tcp endpoints if tlsConfig is not nil.
+func newEndpoint(address string, tlsConfig *tls.Config) (*endpoint, error) {
+	switch {
+	case strings.HasPrefix(address, unixScheme):
+		path := strings.TrimPrefix(address, unixScheme)
+		if path == "" {
+			return nil, fmt.Errorf("missing socket path in address %s", address)
+		}
+		return &endpoint{network: "unix", address: path}, nil
+
+	case strings.HasPrefix(address, tcpScheme):
+		hostPort := strings.TrimPrefix(address, tcpScheme)
+		if _, _, err := net.SplitHostPort(hostPort); err != nil {
+			return nil, fmt.Errorf("invalid address %s: %s", address, err)
+		}
+		return &endpoint{network: "tcp", address: hostPort, tlsConfig: tlsConfig}, nil
+
+	default:
+		return nil, fmt.Errorf("address %s must start with %s or %s", address, unixScheme, tcpScheme)
+	}
+}
+
+func (ep *endpoint) String() string {
+	return fmt.Sprintf("%s://%s", ep.network, ep.address)
+}
+
+// dial connects to the endpoint, giving up once the deadline passes unless
+// it is zero.
+func (ep *endpoint) dial(deadline time.Time) (net.Conn, error) {
+	dialer := &net.Dialer{Deadline: deadline}
+	if ep.tlsConfig != nil {
+		return tls.DialWithDialer(dialer, ep.network, ep.address, ep.tlsConfig)
+	}
+	return dialer.Dial(ep.network, ep.address)
+}
+
+// newTLSConfig returns the TLS configuration for the tcp connections to the
+// java component, or nil if TLS is disabled.
+func newTLSConfig(tlsConfig localconfig.TLS) (*tls.Config, error) {
+	if !tlsConfig.Enabled {
+		return nil, nil
+	}
+
+	rootCAs := make([][]byte, len(tlsConfig.RootCAs))
+	for i, rootCA := range tlsConfig.RootCAs {
+		rootCAs[i] = []byte(rootCA)
+	}
+
+	return comm.NewClientTLSConfig([]byte(tlsConfig.Certificate), []byte(tlsConfig.PrivateKey), rootCAs)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bftsmart
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	localconfig "github.com/hyperledger/fabric/orderer/common/localconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEndpoint(t *testing.T) {
+	tlsConfig := &tls.Config{}
+
+	testCases := []struct {
+		name     string
+		address  string
+		network  string
+		hostPort string
+		useTLS   bool
+		isErr    bool
+	}{
+		{"Unix", "unix:///tmp/hlf-pool.sock", "unix", "/tmp/hlf-pool.sock", false, false},
+		{"TCP", "tcp://replica0:9999", "tcp", "replica0:9999", true, false},
+		{"MissingPath", "unix://", "", "", false, true},
+		{"MissingPort", "tcp://replica0", "", "", false, true},
+		{"MissingScheme", "replica0:9999", "", "", false, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ep, err := newEndpoint(tc.address, tlsConfig)
+			if tc.isErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.network, ep.network)
+			assert.Equal(t, tc.hostPort, ep.address)
+			assert.Equal(t, tc.useTLS, ep.tlsConfig != nil, "TLS should only apply to tcp endpoints")
+			assert.Equal(t, tc.address, ep.String())
+		})
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	read := func(name string) string {
+		bytes, err := ioutil.ReadFile(filepath.Join("..", "..", "..", "core", "comm", "testdata", "certs", name))
+		assert.NoError(t, err)
+		return string(bytes)
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		tlsConfig, err := newTLSConfig(localconfig.TLS{Enabled: false})
+		assert.NoError(t, err)
+		assert.Nil(t, tlsConfig)
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		tlsConfig, err := newTLSConfig(localconfig.TLS{
+			Enabled:     true,
+			Certificate: read("Org1-client1-cert.pem"),
+			PrivateKey:  read("Org1-client1-key.pem"),
+			RootCAs:     []string{read("Org1-cert.pem")},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, tlsConfig.Certificates, 1, "Expected a client certificate for mutual TLS")
+	})
+
+	t.Run("BadKeyPair", func(t *testing.T) {
+		_, err := newTLSConfig(localconfig.TLS{
+			Enabled:     true,
+			Certificate: read("Org1-client1-cert.pem"),
+			PrivateKey:  read("Org1-client2-key.pem"),
+			RootCAs:     []string{read("Org1-cert.pem")},
+		})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,235 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bftsmart
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/consensus/bftsmart/protocol"
+)
+
+// proxyConn is a connection to the java component with a given role. It is
+// established on first use, and re-established whenever it breaks.
+type proxyConn struct {
+	lock     sync.Mutex
+	endpoint *endpoint
+	role     protocol.ConnRole
+	name     string
+	conn     net.Conn
+
+	// reader, if set, is run in its own goroutine on every new connection to
+	// consume the frames sent by the java component
+	reader func(conn net.Conn)
+}
+
+func newProxyConn(ep *endpoint, role protocol.ConnRole, name string) *proxyConn {
+	return &proxyConn{
+		endpoint: ep,
+		role:     role,
+		name:     name,
+	}
+}
+
+// do invokes fn with the connection, which is held exclusively for the
+// duration of the call. If the connection is not established, or if fn
+// fails, the connection is re-established with backoff and fn is retried,
+// until it succeeds, exit is closed or, unless it is zero, the deadline
+// passes, in which case errDeadlineExceeded is returned. The deadline also
+// bounds the writes of fn. Errors reported by the java component itself are
+// returned without retrying, as the connection is healthy.
+func (pc *proxyConn) do(deadline time.Time, exit <-chan struct{}, fn func(conn net.Conn) error) error {
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+
+	bo := newBackoff(minReconnectInterval, maxReconnectInterval)
+
+	for {
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			return errDeadlineExceeded
+		}
+
+		if pc.conn == nil {
+			conn, err := dialBFTProxy(pc.endpoint, protocol.NewHandshakeFrame(pc.role), deadline, exit)
+			if err != nil {
+				return err
+			}
+
+			logger.Debugf("Created %s to java component", pc.name)
+			pc.conn = conn
+
+			if pc.reader != nil {
+				go pc.reader(conn)
+			}
+		}
+
+		// only the write deadline is set, as the reader may be reading
+		pc.conn.SetWriteDeadline(deadline)
+		err := fn(pc.conn)
+		if err == nil {
+			pc.conn.SetWriteDeadline(time.Time{})
+			return nil
+		}
+
+		if _, ok := err.(*protocol.Error); ok {
+			pc.conn.SetWriteDeadline(time.Time{})
+			return err
+		}
+
+		logger.Warningf("Lost %s to java component, reconnecting: %s", pc.name, err)
+		pc.conn.Close()
+		pc.conn = nil
+
+		if retryErr := retryAfter(bo.next(), deadline, exit); retryErr != nil {
+			if retryErr == errDeadlineExceeded {
+				return retryErr
+			}
+			return err
+		}
+	}
+}
+
+// close closes the connection, if established. It is re-established on next use.
+func (pc *proxyConn) close() {
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+
+	if pc.conn != nil {
+		pc.conn.Close()
+		pc.conn = nil
+	}
+}
+
+// connPool is a set of connections to the java component, handed out in
+// round-robin order. The acknowledgements sent back by the java component on
+// any of them are matched with the requests waiting for them. It is safe for
+// concurrent use.
+type connPool struct {
+	conns []*proxyConn
+	next  uint64
+
+	lock    sync.Mutex
+	waiting map[uint64]chan error
+}
+
+func newConnPool(ep *endpoint, size uint) *connPool {
+	pool := &connPool{
+		conns:   make([]*proxyConn, size),
+		waiting: make(map[uint64]chan error),
+	}
+	for i := range pool.conns {
+		pool.conns[i] = newProxyConn(ep, protocol.RolePool, fmt.Sprintf("pool connection #%d", i))
+		pool.conns[i].reader = pool.readAcks
+	}
+	return pool
+}
+
+// expectAck registers a request, and returns the channel on which the outcome
+// reported by the java component is delivered. forget must be called once the
+// outcome is no longer awaited.
+func (p *connPool) expectAck(id uint64) <-chan error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	outcome := make(chan error, 1)
+	p.waiting[id] = outcome
+	return outcome
+}
+
+func (p *connPool) forget(id uint64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.waiting, id)
+}
+
+// readAcks delivers the acknowledgements and errors received on the given
+// connection to the requests waiting for them. Frames answering requests
+// nobody waits for anymore, such as requests which timed out, are dropped.
+func (p *connPool) readAcks(conn net.Conn) {
+	for {
+		f, err := protocol.ReadFrame(conn, protocol.DefaultMaxFrameSize)
+		if err != nil {
+			// the connection is re-established on the next write
+			conn.Close()
+			return
+		}
+
+		var outcome error
+		switch f.Kind {
+		case protocol.MsgAck:
+		case protocol.MsgError:
+			outcome = f.AsError()
+		default:
+			logger.Warningf("Ignoring unexpected %s frame on pool connection to java component", f.Kind)
+			continue
+		}
+
+		p.lock.Lock()
+		waiting, ok := p.waiting[f.ID]
+		delete(p.waiting, f.ID)
+		p.lock.Unlock()
+
+		if !ok {
+			logger.Debugf("Dropping %s frame for request %d, which is no longer awaited", f.Kind, f.ID)
+			continue
+		}
+		waiting <- outcome
+	}
+}
+
+// get returns the next connection of the pool.
+func (p *connPool) get() *proxyConn {
+	index := atomic.AddUint64(&p.next, 1) % uint64(len(p.conns))
+	return p.conns[index]
+}
+
+func (p *connPool) close() {
+	for _, pc := range p.conns {
+		pc.close()
+	}
+}
+
+// dialBFTProxy connects to the java component and performs the handshake
+// announcing the role of the connection, retrying with backoff until it
+// succeeds, exit is closed or, unless it is zero, the deadline passes.
+func dialBFTProxy(ep *endpoint, hello *protocol.Frame, deadline time.Time, exit <-chan struct{}) (net.Conn, error) {
+
+	bo := newBackoff(minReconnectInterval, maxReconnectInterval)
+
+	for {
+		conn, err := dialWithBackoff(ep, deadline, exit)
+		if err != nil {
+			return nil, err
+		}
+
+		handshakeDeadline := time.Now().Add(protocol.HandshakeTimeout)
+		if !deadline.IsZero() && deadline.Before(handshakeDeadline) {
+			handshakeDeadline = deadline
+		}
+
+		conn.SetDeadline(handshakeDeadline)
+		err = protocol.Handshake(conn, hello, protocol.DefaultMaxFrameSize)
+		if err == nil {
+			conn.SetDeadline(time.Time{})
+			return conn, nil
+		}
+
+		conn.Close()
+		logger.Warningf("Handshake with java component at %s failed: %s", ep, err)
+
+		if retryErr := retryAfter(bo.next(), deadline, exit); retryErr != nil {
+			if retryErr == errDeadlineExceeded {
+				return nil, retryErr
+			}
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bftsmart
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/consensus/bftsmart/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+// frameSink accepts connections, answers their handshake, and collects the
+// frames received on them.
+type frameSink struct {
+	listener net.Listener
+	frames   chan *protocol.Frame
+	roles    chan protocol.ConnRole
+}
+
+func newFrameSink(t *testing.T) *frameSink {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	fs := &frameSink{
+		listener: listener,
+		frames:   make(chan *protocol.Frame, 100),
+		roles:    make(chan protocol.ConnRole, 100),
+	}
+	go fs.accept()
+	return fs
+}
+
+func (fs *frameSink) endpoint() *endpoint {
+	return &endpoint{network: "tcp", address: fs.listener.Addr().String()}
+}
+
+func (fs *frameSink) accept() {
+	for {
+		conn, err := fs.listener.Accept()
+		if err != nil {
+			return
+		}
+		go fs.serve(conn)
+	}
+}
+
+func (fs *frameSink) serve(conn net.Conn) {
+	defer conn.Close()
+
+	hello, err := protocol.ReadFrame(conn, protocol.DefaultMaxFrameSize)
+	if err != nil {
+		return
+	}
+	role, _ := hello.GetUint32(protocol.FieldRole)
+	fs.roles <- protocol.ConnRole(role)
+	if err := protocol.WriteFrame(conn, protocol.NewFrame(protocol.MsgHandshake, hello.ID).AddUint32(protocol.FieldVersion, protocol.Version)); err != nil {
+		return
+	}
+
+	for {
+		f, err := protocol.ReadFrame(conn, protocol.DefaultMaxFrameSize)
+		if err != nil {
+			return
+		}
+		fs.frames <- f
+	}
+}
+
+func TestConnPoolRoundRobin(t *testing.T) {
+	fs := newFrameSink(t)
+	defer fs.listener.Close()
+
+	pool := newConnPool(fs.endpoint(), 3)
+	defer pool.close()
+
+	used := make(map[*proxyConn]int)
+	for i := 0; i < 6; i++ {
+		used[pool.get()]++
+	}
+
+	assert.Len(t, used, 3, "Expected every connection of the pool to be used")
+	for _, count := range used {
+		assert.Equal(t, 2, count, "Expected connections to be used evenly")
+	}
+}
+
+func TestConnPoolConcurrentSend(t *testing.T) {
+	fs := newFrameSink(t)
+	defer fs.listener.Close()
+
+	pool := newConnPool(fs.endpoint(), 4)
+	defer pool.close()
+
+	exit := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, pool.get().do(time.Time{}, exit, func(conn net.Conn) error {
+				return protocol.WriteFrame(conn, protocol.NewFrame(protocol.MsgEnvelope, protocol.NextCorrelationID()).AddString(protocol.FieldChainID, "foo"))
+			}))
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 50; i++ {
+		select {
+		case f := <-fs.frames:
+			assert.Equal(t, protocol.MsgEnvelope, f.Kind)
+		case <-time.After(time.Second):
+			t.Fatalf("Expected 50 frames, received %d", i)
+		}
+	}
+
+	assert.Len(t, fs.roles, 4, "Expected one handshake per pool connection")
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, protocol.RolePool, <-fs.roles)
+	}
+}
+
+func TestProxyConnReconnect(t *testing.T) {
+	fs := newFrameSink(t)
+	defer fs.listener.Close()
+
+	pc := newProxyConn(fs.endpoint(), protocol.RoleControl, "control connection")
+	exit := make(chan struct{})
+
+	attempts := 0
+	err := pc.do(time.Time{}, exit, func(conn net.Conn) error {
+		attempts++
+		if attempts == 1 {
+			// simulate a connection broken by the java component
+			conn.Close()
+		}
+		return protocol.WriteFrame(conn, protocol.NewFrame(protocol.MsgHeartbeat, 1))
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts, "Expected the write to be retried on a new connection")
+	assert.Equal(t, protocol.RoleControl, <-fs.roles)
+	assert.Equal(t, protocol.RoleControl, <-fs.roles)
+
+	t.Run("Exit", func(t *testing.T) {
+		close(exit)
+		err := pc.do(time.Time{}, exit, func(conn net.Conn) error {
+			return net.ErrWriteToConnected
+		})
+		assert.Error(t, err, "Expected retries to stop once asked to exit")
+	})
+}
+
+func TestConnPoolAcks(t *testing.T) {
+	pool := newConnPool(&endpoint{network: "tcp", address: "127.0.0.1:0"}, 1)
+
+	client, server := net.Pipe()
+	defer server.Close()
+	go pool.readAcks(client)
+
+	acked := pool.expectAck(1)
+	rejected := pool.expectAck(2)
+	pool.expectAck(3)
+	pool.forget(3)
+
+	assert.NoError(t, protocol.WriteFrame(server, protocol.NewFrame(protocol.MsgError, 3).AddString(protocol.FieldMessage, "too late")))
+	assert.NoError(t, protocol.WriteFrame(server, protocol.NewFrame(protocol.MsgHeartbeat, 4)))
+	assert.NoError(t, protocol.WriteFrame(server, protocol.NewFrame(protocol.MsgError, 2).AddString(protocol.FieldMessage, "bad envelope")))
+	assert.NoError(t, protocol.WriteFrame(server, protocol.NewFrame(protocol.MsgAck, 1)))
+
+	select {
+	case err := <-acked:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the ack to be delivered")
+	}
+
+	select {
+	case err := <-rejected:
+		if assert.Error(t, err) {
+			assert.IsType(t, &protocol.Error{}, err)
+			assert.Contains(t, err.Error(), "bad envelope")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the error to be delivered")
+	}
+
+	pool.lock.Lock()
+	assert.Empty(t, pool.waiting, "Expected answered requests to be forgotten")
+	pool.lock.Unlock()
+}
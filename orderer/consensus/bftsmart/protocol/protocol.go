@@ -0,0 +1,438 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package protocol implements the wire protocol spoken between the bftsmart
+// consenter and the java component ordering its envelopes.
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("orderer/bftsmart")
+
+// The golang and java components exchange frames, all integers being big-endian:
+//
+//	magic  uint16  always frameMagic
+//	kind   uint8   one of the MsgKind values
+//	id     uint64  correlation ID, echoed back in the frames answering a request
+//	length uint32  length of the body
+//	body   []byte  a sequence of fields
+//
+// Each field of the body is encoded as:
+//
+//	tag    uint16  one of the FieldTag values
+//	length uint32  length of the value
+//	value  []byte
+//
+// Fields with an unknown tag are skipped by the receiver, so that fields can be
+// added without upgrading both components at once. Changes that are not backwards
+// compatible must bump Version, which both sides exchange in the handshake
+// that opens every connection.
+const (
+	Version uint32 = 2
+
+	frameMagic          uint16 = 0xBF75
+	frameHeaderSize            = 15
+	fieldHeaderSize            = 6
+	DefaultMaxFrameSize        = 128 * 1024 * 1024
+
+	HandshakeTimeout  = 10 * time.Second
+	DefaultAckTimeout = 5 * time.Second
+)
+
+// MsgKind is the kind of a frame.
+type MsgKind uint8
+
+const (
+	MsgHandshake MsgKind = iota + 1
+	MsgEnvelope
+	MsgConfigEnvelope
+	MsgBlock
+	MsgBlockType
+	MsgBatchConfig
+	MsgHeartbeat
+	MsgError
+	MsgAck
+)
+
+var msgKindNames = map[MsgKind]string{
+	MsgHandshake:      "HANDSHAKE",
+	MsgEnvelope:       "ENVELOPE",
+	MsgConfigEnvelope: "CONFIG_ENVELOPE",
+	MsgBlock:          "BLOCK",
+	MsgBlockType:      "BLOCK_TYPE",
+	MsgBatchConfig:    "BATCH_CONFIG",
+	MsgHeartbeat:      "HEARTBEAT",
+	MsgError:          "ERROR",
+	MsgAck:            "ACK",
+}
+
+func (k MsgKind) String() string {
+	if name, ok := msgKindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("UNKNOWN(%d)", uint8(k))
+}
+
+// FieldTag identifies a field of a frame.
+type FieldTag uint16
+
+const (
+	FieldVersion FieldTag = iota + 1
+	FieldRole
+	FieldChainID
+	FieldHeader
+	FieldPayload
+	FieldIsConfig
+	FieldPreferredMaxBytes
+	FieldMaxMessageCount
+	FieldBatchTimeout
+	FieldMessage
+	FieldView
+	FieldSequence
+	FieldReplicaID
+	FieldConnectedReplicas
+)
+
+// ConnRole tells the java component, during the handshake, what a connection is used for.
+type ConnRole uint32
+
+const (
+	// RoleControl connections carry the batch configuration
+	RoleControl ConnRole = iota + 1
+	// RolePool connections carry the envelopes to order
+	RolePool
+	// RoleDeliver connections carry the blocks of a single chain
+	RoleDeliver
+)
+
+// lastCorrelationID is used to assign a unique correlation ID to every request
+var lastCorrelationID uint64
+
+// NextCorrelationID returns a correlation ID no other request was assigned.
+func NextCorrelationID() uint64 {
+	return atomic.AddUint64(&lastCorrelationID, 1)
+}
+
+type field struct {
+	tag   FieldTag
+	value []byte
+}
+
+// Frame is a message exchanged with the java component.
+type Frame struct {
+	Kind   MsgKind
+	ID     uint64
+	fields []field
+}
+
+// NewFrame returns a frame of the given kind and correlation ID, without any field.
+func NewFrame(kind MsgKind, id uint64) *Frame {
+	return &Frame{Kind: kind, ID: id}
+}
+
+// AddBytes adds a field with the given value to the frame, and returns the frame.
+func (f *Frame) AddBytes(tag FieldTag, value []byte) *Frame {
+	f.fields = append(f.fields, field{tag: tag, value: value})
+	return f
+}
+
+// AddString adds a field with the given string value.
+func (f *Frame) AddString(tag FieldTag, value string) *Frame {
+	return f.AddBytes(tag, []byte(value))
+}
+
+// AddUint32 adds a field with the given uint32 value.
+func (f *Frame) AddUint32(tag FieldTag, value uint32) *Frame {
+	buf := make([]byte, 4)
+	binary.BigEndian.PutUint32(buf, value)
+	return f.AddBytes(tag, buf)
+}
+
+// AddUint64 adds a field with the given uint64 value.
+func (f *Frame) AddUint64(tag FieldTag, value uint64) *Frame {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, value)
+	return f.AddBytes(tag, buf)
+}
+
+// AddBool adds a field with the given boolean value.
+func (f *Frame) AddBool(tag FieldTag, value bool) *Frame {
+	if value {
+		return f.AddBytes(tag, []byte{1})
+	}
+	return f.AddBytes(tag, []byte{0})
+}
+
+// get returns the value of the first field with the given tag.
+func (f *Frame) get(tag FieldTag) ([]byte, bool) {
+	for _, fd := range f.fields {
+		if fd.tag == tag {
+			return fd.value, true
+		}
+	}
+	return nil, false
+}
+
+// GetBytes returns the value of the field with the given tag, or an error if
+// the frame has no such field.
+func (f *Frame) GetBytes(tag FieldTag) ([]byte, error) {
+	value, ok := f.get(tag)
+	if !ok {
+		return nil, fmt.Errorf("%s frame is missing field %d", f.Kind, tag)
+	}
+	return value, nil
+}
+
+// GetString returns the value of the field with the given tag as a string.
+func (f *Frame) GetString(tag FieldTag) (string, error) {
+	value, err := f.GetBytes(tag)
+	return string(value), err
+}
+
+// GetUint32 returns the value of the field with the given tag as a uint32.
+func (f *Frame) GetUint32(tag FieldTag) (uint32, error) {
+	value, err := f.GetBytes(tag)
+	if err != nil {
+		return 0, err
+	}
+	if len(value) != 4 {
+		return 0, fmt.Errorf("%s frame has field %d of invalid length %d", f.Kind, tag, len(value))
+	}
+	return binary.BigEndian.Uint32(value), nil
+}
+
+// GetUint64 returns the value of the field with the given tag as a uint64.
+func (f *Frame) GetUint64(tag FieldTag) (uint64, error) {
+	value, err := f.GetBytes(tag)
+	if err != nil {
+		return 0, err
+	}
+	if len(value) != 8 {
+		return 0, fmt.Errorf("%s frame has field %d of invalid length %d", f.Kind, tag, len(value))
+	}
+	return binary.BigEndian.Uint64(value), nil
+}
+
+// GetBool returns the value of the field with the given tag as a boolean.
+func (f *Frame) GetBool(tag FieldTag) (bool, error) {
+	value, err := f.GetBytes(tag)
+	if err != nil {
+		return false, err
+	}
+	if len(value) != 1 || value[0] > 1 {
+		return false, fmt.Errorf("%s frame has invalid boolean field %d", f.Kind, tag)
+	}
+	return value[0] == 1, nil
+}
+
+// AddConsensusMetadata adds the view, consensus sequence and replica ID of the
+// given metadata to the frame.
+func (f *Frame) AddConsensusMetadata(metadata *ab.BFTsmartMetadata) *Frame {
+	return f.AddUint64(FieldView, metadata.View).
+		AddUint64(FieldSequence, metadata.Sequence).
+		AddUint32(FieldReplicaID, metadata.ReplicaId)
+}
+
+// GetConsensusMetadata returns the view, consensus sequence and replica ID
+// carried by the frame.
+func (f *Frame) GetConsensusMetadata() (*ab.BFTsmartMetadata, error) {
+	view, err := f.GetUint64(FieldView)
+	if err != nil {
+		return nil, err
+	}
+	sequence, err := f.GetUint64(FieldSequence)
+	if err != nil {
+		return nil, err
+	}
+	replicaID, err := f.GetUint32(FieldReplicaID)
+	if err != nil {
+		return nil, err
+	}
+	return &ab.BFTsmartMetadata{View: view, Sequence: sequence, ReplicaId: replicaID}, nil
+}
+
+// Error is an error reported by the java component in an error frame.
+type Error struct {
+	id  uint64
+	msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("java component reported an error for request %d: %s", e.id, e.msg)
+}
+
+// AsError returns the error carried by an error frame.
+func (f *Frame) AsError() error {
+	msg, _ := f.GetString(FieldMessage)
+	return &Error{id: f.ID, msg: msg}
+}
+
+// WriteFrame encodes the frame and writes it with a single call, so that frames
+// written by different goroutines on the same connection never interleave.
+func WriteFrame(w io.Writer, f *Frame) error {
+	bodySize := 0
+	for _, fd := range f.fields {
+		bodySize += fieldHeaderSize + len(fd.value)
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, frameHeaderSize+bodySize))
+
+	var header [frameHeaderSize]byte
+	binary.BigEndian.PutUint16(header[0:2], frameMagic)
+	header[2] = byte(f.Kind)
+	binary.BigEndian.PutUint64(header[3:11], f.ID)
+	binary.BigEndian.PutUint32(header[11:15], uint32(bodySize))
+	buf.Write(header[:])
+
+	for _, fd := range f.fields {
+		var fieldHeader [fieldHeaderSize]byte
+		binary.BigEndian.PutUint16(fieldHeader[0:2], uint16(fd.tag))
+		binary.BigEndian.PutUint32(fieldHeader[2:6], uint32(len(fd.value)))
+		buf.Write(fieldHeader[:])
+		buf.Write(fd.value)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// ReadFrame reads and decodes the next frame. Frames with a bad magic number, an
+// unknown kind, a body larger than maxSize, or malformed fields are rejected
+// before their body is allocated or parsed any further.
+func ReadFrame(r io.Reader, maxSize uint32) (*Frame, error) {
+	var header [frameHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+
+	if magic := binary.BigEndian.Uint16(header[0:2]); magic != frameMagic {
+		return nil, fmt.Errorf("received frame with bad magic number %#x", magic)
+	}
+
+	f := NewFrame(MsgKind(header[2]), binary.BigEndian.Uint64(header[3:11]))
+	if _, ok := msgKindNames[f.Kind]; !ok {
+		return nil, fmt.Errorf("received frame of unknown kind %d", uint8(f.Kind))
+	}
+
+	size := binary.BigEndian.Uint32(header[11:15])
+	if size > maxSize {
+		return nil, fmt.Errorf("received %s frame of %d bytes, exceeding the maximum of %d bytes", f.Kind, size, maxSize)
+	}
+
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+
+	for len(body) > 0 {
+		if len(body) < fieldHeaderSize {
+			return nil, fmt.Errorf("received %s frame with truncated field header", f.Kind)
+		}
+		tag := FieldTag(binary.BigEndian.Uint16(body[0:2]))
+		length := binary.BigEndian.Uint32(body[2:6])
+		body = body[fieldHeaderSize:]
+		if uint64(length) > uint64(len(body)) {
+			return nil, fmt.Errorf("received %s frame with truncated field %d", f.Kind, tag)
+		}
+		f.fields = append(f.fields, field{tag: tag, value: body[:length]})
+		body = body[length:]
+	}
+
+	return f, nil
+}
+
+// NewHandshakeFrame returns the frame opening a connection with the given role.
+func NewHandshakeFrame(role ConnRole) *Frame {
+	return NewFrame(MsgHandshake, NextCorrelationID()).
+		AddUint32(FieldVersion, Version).
+		AddUint32(FieldRole, uint32(role))
+}
+
+// Handshake sends the given handshake frame and waits for the java component
+// to answer with a handshake of the same protocol version.
+func Handshake(rw io.ReadWriter, hello *Frame, maxSize uint32) error {
+	if err := WriteFrame(rw, hello); err != nil {
+		return fmt.Errorf("error while sending handshake: %s", err)
+	}
+
+	reply, err := ReadFrame(rw, maxSize)
+	if err != nil {
+		return fmt.Errorf("error while receiving handshake: %s", err)
+	}
+
+	switch {
+	case reply.Kind == MsgError:
+		return reply.AsError()
+	case reply.Kind != MsgHandshake:
+		return fmt.Errorf("expected %s frame but received %s frame", MsgHandshake, reply.Kind)
+	case reply.ID != hello.ID:
+		return fmt.Errorf("received handshake for request %d, expected %d", reply.ID, hello.ID)
+	}
+
+	version, err := reply.GetUint32(FieldVersion)
+	if err != nil {
+		return err
+	}
+
+	if version != Version {
+		return fmt.Errorf("java component speaks protocol version %d, expected version %d", version, Version)
+	}
+
+	return nil
+}
+
+// RejectFrame tells the other side why the last frame was rejected. It is best
+// effort, as the connection is about to be closed anyway.
+func RejectFrame(w io.Writer, id uint64, reason error) {
+	if err := WriteFrame(w, NewFrame(MsgError, id).AddString(FieldMessage, reason.Error())); err != nil {
+		logger.Debugf("Could not report error to java component: %s", err)
+	}
+}
+
+// WaitForAck reads frames until the one answering the given request, which
+// must be an acknowledgement. Frames answering earlier requests, and
+// heartbeats, are skipped.
+func WaitForAck(r io.Reader, id uint64, maxSize uint32) error {
+	for {
+		reply, err := ReadFrame(r, maxSize)
+		if err != nil {
+			return err
+		}
+
+		if reply.ID != id {
+			logger.Debugf("Skipping %s frame for request %d while waiting for the ack of request %d", reply.Kind, reply.ID, id)
+			continue
+		}
+
+		switch reply.Kind {
+		case MsgAck:
+			return nil
+		case MsgError:
+			return reply.AsError()
+		default:
+			return fmt.Errorf("expected %s frame for request %d but received %s frame", MsgAck, id, reply.Kind)
+		}
+	}
+}
+
+// NewBatchConfigFrame returns the frame carrying the batch configuration of a chain.
+func NewBatchConfigFrame(chainID string, preferredMaxBytes, maxMessageCount uint32, batchTimeout time.Duration) *Frame {
+	return NewFrame(MsgBatchConfig, NextCorrelationID()).
+		AddString(FieldChainID, chainID).
+		AddUint32(FieldPreferredMaxBytes, preferredMaxBytes).
+		AddUint32(FieldMaxMessageCount, maxMessageCount).
+		AddUint64(FieldBatchTimeout, uint64(batchTimeout.Nanoseconds()))
+}
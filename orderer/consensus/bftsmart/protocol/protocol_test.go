@@ -0,0 +1,210 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrameRoundTrip(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	sent := NewBatchConfigFrame("foo", 1024, 10, 2*time.Second).AddBool(FieldIsConfig, true)
+	assert.NoError(t, WriteFrame(buf, sent))
+
+	received, err := ReadFrame(buf, DefaultMaxFrameSize)
+	assert.NoError(t, err)
+	assert.Equal(t, MsgBatchConfig, received.Kind)
+	assert.Equal(t, sent.ID, received.ID)
+
+	chainID, err := received.GetString(FieldChainID)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", chainID)
+
+	preferredMaxBytes, err := received.GetUint32(FieldPreferredMaxBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1024), preferredMaxBytes)
+
+	maxMessageCount, err := received.GetUint32(FieldMaxMessageCount)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(10), maxMessageCount)
+
+	batchTimeout, err := received.GetUint64(FieldBatchTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2*time.Second), batchTimeout)
+
+	isConfig, err := received.GetBool(FieldIsConfig)
+	assert.NoError(t, err)
+	assert.True(t, isConfig)
+
+	_, err = received.GetBytes(FieldPayload)
+	assert.Error(t, err, "Expected missing field to be reported")
+
+	_, err = received.GetUint64(FieldChainID)
+	assert.Error(t, err, "Expected field of the wrong size to be reported")
+}
+
+func TestConsensusMetadataRoundTrip(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	sent := &ab.BFTsmartMetadata{View: 3, Sequence: 42, ReplicaId: 2}
+	assert.NoError(t, WriteFrame(buf, NewFrame(MsgBlockType, 1).AddBool(FieldIsConfig, false).AddConsensusMetadata(sent)))
+
+	received, err := ReadFrame(buf, DefaultMaxFrameSize)
+	assert.NoError(t, err)
+
+	metadata, err := received.GetConsensusMetadata()
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(sent, metadata), "Expected %v, got %v", sent, metadata)
+
+	_, err = NewFrame(MsgBlockType, 2).AddUint64(FieldView, 3).GetConsensusMetadata()
+	assert.Error(t, err, "Expected incomplete metadata to be rejected")
+}
+
+func TestFrameUnknownField(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	assert.NoError(t, WriteFrame(buf, NewFrame(MsgHeartbeat, 7).AddString(FieldTag(999), "from the future").AddString(FieldMessage, "bar")))
+
+	received, err := ReadFrame(buf, DefaultMaxFrameSize)
+	assert.NoError(t, err, "Expected unknown fields to be accepted")
+
+	msg, err := received.GetString(FieldMessage)
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", msg)
+}
+
+func TestFrameRejected(t *testing.T) {
+	encode := func(f *Frame) []byte {
+		buf := &bytes.Buffer{}
+		assert.NoError(t, WriteFrame(buf, f))
+		return buf.Bytes()
+	}
+
+	t.Run("BadMagic", func(t *testing.T) {
+		raw := encode(NewFrame(MsgHeartbeat, 1))
+		binary.BigEndian.PutUint16(raw[0:2], 0xCAFE)
+		_, err := ReadFrame(bytes.NewReader(raw), DefaultMaxFrameSize)
+		assert.Error(t, err)
+	})
+
+	t.Run("UnknownKind", func(t *testing.T) {
+		_, err := ReadFrame(bytes.NewReader(encode(NewFrame(MsgKind(200), 1))), DefaultMaxFrameSize)
+		assert.Error(t, err)
+	})
+
+	t.Run("Oversized", func(t *testing.T) {
+		raw := encode(NewFrame(MsgBlock, 1))
+		binary.BigEndian.PutUint32(raw[11:15], 0xFFFFFFFF)
+		_, err := ReadFrame(bytes.NewReader(raw), DefaultMaxFrameSize)
+		assert.Error(t, err)
+	})
+
+	t.Run("TruncatedField", func(t *testing.T) {
+		raw := encode(NewFrame(MsgBlock, 1).AddBytes(FieldPayload, []byte("block")))
+		// claim the field is longer than the frame body
+		binary.BigEndian.PutUint32(raw[frameHeaderSize+2:frameHeaderSize+6], 100)
+		_, err := ReadFrame(bytes.NewReader(raw), DefaultMaxFrameSize)
+		assert.Error(t, err)
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		raw := encode(NewFrame(MsgBlock, 1).AddBytes(FieldPayload, []byte("block")))
+		_, err := ReadFrame(bytes.NewReader(raw[:len(raw)-1]), DefaultMaxFrameSize)
+		assert.Error(t, err)
+	})
+}
+
+func TestHandshake(t *testing.T) {
+	answer := func(conn net.Conn, reply func(hello *Frame) *Frame) {
+		hello, err := ReadFrame(conn, DefaultMaxFrameSize)
+		if err != nil {
+			return
+		}
+		WriteFrame(conn, reply(hello))
+	}
+
+	t.Run("Proper", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer client.Close()
+		go answer(server, func(hello *Frame) *Frame {
+			return NewFrame(MsgHandshake, hello.ID).AddUint32(FieldVersion, Version)
+		})
+		assert.NoError(t, Handshake(client, NewHandshakeFrame(RolePool), DefaultMaxFrameSize))
+	})
+
+	t.Run("VersionMismatch", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer client.Close()
+		go answer(server, func(hello *Frame) *Frame {
+			return NewFrame(MsgHandshake, hello.ID).AddUint32(FieldVersion, Version+1)
+		})
+		assert.Error(t, Handshake(client, NewHandshakeFrame(RolePool), DefaultMaxFrameSize))
+	})
+
+	t.Run("CorrelationMismatch", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer client.Close()
+		go answer(server, func(hello *Frame) *Frame {
+			return NewFrame(MsgHandshake, hello.ID+1).AddUint32(FieldVersion, Version)
+		})
+		assert.Error(t, Handshake(client, NewHandshakeFrame(RolePool), DefaultMaxFrameSize))
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		client, server := net.Pipe()
+		defer client.Close()
+		go answer(server, func(hello *Frame) *Frame {
+			return NewFrame(MsgError, hello.ID).AddString(FieldMessage, "unsupported role")
+		})
+		err := Handshake(client, NewHandshakeFrame(RolePool), DefaultMaxFrameSize)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "unsupported role")
+		}
+	})
+}
+
+func TestWaitForAck(t *testing.T) {
+	encode := func(frames ...*Frame) *bytes.Buffer {
+		buf := &bytes.Buffer{}
+		for _, f := range frames {
+			assert.NoError(t, WriteFrame(buf, f))
+		}
+		return buf
+	}
+
+	t.Run("Proper", func(t *testing.T) {
+		buf := encode(NewFrame(MsgAck, 4), NewFrame(MsgHeartbeat, 9), NewFrame(MsgAck, 5))
+		assert.NoError(t, WaitForAck(buf, 5, DefaultMaxFrameSize), "Expected frames for other requests to be skipped")
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		buf := encode(NewFrame(MsgError, 5).AddString(FieldMessage, "bad batch size"))
+		err := WaitForAck(buf, 5, DefaultMaxFrameSize)
+		if assert.Error(t, err) {
+			assert.IsType(t, &Error{}, err)
+			assert.Contains(t, err.Error(), "bad batch size")
+		}
+	})
+
+	t.Run("UnexpectedKind", func(t *testing.T) {
+		buf := encode(NewFrame(MsgBlock, 5))
+		assert.Error(t, WaitForAck(buf, 5, DefaultMaxFrameSize))
+	})
+
+	t.Run("Closed", func(t *testing.T) {
+		assert.Error(t, WaitForAck(encode(), 5, DefaultMaxFrameSize))
+	})
+}
@@ -0,0 +1,99 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bftsmart
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+const (
+	minReconnectInterval = 100 * time.Millisecond
+	maxReconnectInterval = 10 * time.Second
+)
+
+var (
+	// errDeadlineExceeded is returned when the java component could not be
+	// reached before the deadline of a request.
+	errDeadlineExceeded = errors.New("deadline exceeded while reaching java component")
+
+	errExiting = errors.New("process asked to exit")
+)
+
+// backoff computes exponentially growing delays between reconnection attempts,
+// capped at max.
+type backoff struct {
+	min, max time.Duration
+	current  time.Duration
+}
+
+func newBackoff(min, max time.Duration) *backoff {
+	return &backoff{min: min, max: max}
+}
+
+// next returns the delay to wait before the next attempt.
+func (b *backoff) next() time.Duration {
+	switch {
+	case b.current == 0:
+		b.current = b.min
+	case b.current < b.max:
+		b.current *= 2
+		if b.current > b.max {
+			b.current = b.max
+		}
+	}
+	return b.current
+}
+
+// reset brings the delay back to its minimum value.
+func (b *backoff) reset() {
+	b.current = 0
+}
+
+// dialWithBackoff keeps trying to connect to the given endpoint until it
+// succeeds, until exit is closed, or until the deadline passes unless it is
+// zero.
+func dialWithBackoff(ep *endpoint, deadline time.Time, exit <-chan struct{}) (net.Conn, error) {
+	bo := newBackoff(minReconnectInterval, maxReconnectInterval)
+	for {
+		conn, err := ep.dial(deadline)
+		if err == nil {
+			return conn, nil
+		}
+
+		delay := bo.next()
+		logger.Warningf("Could not connect to java component at %s, retrying in %s: %s", ep, delay, err)
+
+		if err := retryAfter(delay, deadline, exit); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryAfter waits for the given delay before another attempt. It returns
+// errDeadlineExceeded without waiting if the attempt would start after the
+// deadline, unless it is zero, and errExiting if exit is closed first.
+func retryAfter(delay time.Duration, deadline time.Time, exit <-chan struct{}) error {
+	if !deadline.IsZero() && time.Now().Add(delay).After(deadline) {
+		return errDeadlineExceeded
+	}
+	if !sleep(delay, exit) {
+		return errExiting
+	}
+	return nil
+}
+
+// sleep waits for the given delay, returning false if exit is closed first.
+func sleep(delay time.Duration, exit <-chan struct{}) bool {
+	select {
+	case <-exit:
+		return false
+	case <-time.After(delay):
+		return true
+	}
+}
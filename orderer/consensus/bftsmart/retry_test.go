@@ -0,0 +1,84 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bftsmart
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	bo := newBackoff(time.Second, 5*time.Second)
+
+	assert.Equal(t, time.Second, bo.next())
+	assert.Equal(t, 2*time.Second, bo.next())
+	assert.Equal(t, 4*time.Second, bo.next())
+	assert.Equal(t, 5*time.Second, bo.next(), "Expected delay to be capped")
+	assert.Equal(t, 5*time.Second, bo.next(), "Expected delay to stay capped")
+
+	bo.reset()
+	assert.Equal(t, time.Second, bo.next(), "Expected delay to be reset")
+}
+
+func TestDialWithBackoff(t *testing.T) {
+	t.Run("Proper", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		defer listener.Close()
+
+		conn, err := dialWithBackoff(&endpoint{network: "tcp", address: listener.Addr().String()}, time.Time{}, make(chan struct{}))
+		assert.NoError(t, err)
+		conn.Close()
+	})
+
+	t.Run("Exit", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		addr := listener.Addr().String()
+		listener.Close()
+
+		exit := make(chan struct{})
+		close(exit)
+
+		_, err = dialWithBackoff(&endpoint{network: "tcp", address: addr}, time.Time{}, exit)
+		assert.Error(t, err, "Expected dial to give up once asked to exit")
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		addr := listener.Addr().String()
+		listener.Close()
+
+		start := time.Now()
+		_, err = dialWithBackoff(&endpoint{network: "tcp", address: addr}, start.Add(500*time.Millisecond), make(chan struct{}))
+		assert.Equal(t, errDeadlineExceeded, err, "Expected dial to give up once the deadline passes")
+		assert.True(t, time.Since(start) < time.Second, "Expected dial to give up within the deadline")
+	})
+
+	t.Run("Reconnect", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		addr := listener.Addr().String()
+		listener.Close()
+
+		relisten := make(chan net.Listener, 1)
+		go func() {
+			time.Sleep(2 * minReconnectInterval)
+			listener, _ := net.Listen("tcp", addr)
+			relisten <- listener
+		}()
+
+		conn, err := dialWithBackoff(&endpoint{network: "tcp", address: addr}, time.Time{}, make(chan struct{}))
+		assert.NoError(t, err, "Expected dial to succeed once the listener is back")
+		conn.Close()
+		(<-relisten).Close()
+	})
+}
@@ -0,0 +1,102 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package standin
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/orderer/consensus/bftsmart/protocol"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+)
+
+// The stand-in proxy is driven by the tests through the hooks below, on top of
+// the protocol it implements in standin.go.
+
+// SetVersion sets the protocol version announced in the next handshakes.
+func (p *StandIn) SetVersion(version uint32) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.version = version
+}
+
+// SetRejectBatchConfig makes the proxy answer batch configurations with an error.
+func (p *StandIn) SetRejectBatchConfig(reject bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.rejectBatchConfig = reject
+}
+
+// SetWithholdAcks makes the proxy order envelopes without acknowledging them.
+func (p *StandIn) SetWithholdAcks(withhold bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.withholdAcks = withhold
+}
+
+// SetWithholdBatchConfigAcks makes the proxy apply batch configurations
+// without acknowledging them.
+func (p *StandIn) SetWithholdBatchConfigAcks(withhold bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.withholdBatchConfigAcks = withhold
+}
+
+// BatchConfigs returns the batch configuration frames received by the proxy.
+func (p *StandIn) BatchConfigs() <-chan *protocol.Frame {
+	return p.batchConfigs
+}
+
+// Rejections returns the error frames sent back by the chains.
+func (p *StandIn) Rejections() <-chan *protocol.Frame {
+	return p.rejections
+}
+
+// Delivered returns the blocks ordered so far for the given chain.
+func (p *StandIn) Delivered(chainID string) []*cb.Block {
+	ch := p.chain(chainID)
+
+	ch.lock.Lock()
+	defer ch.lock.Unlock()
+
+	var blocks []*cb.Block
+	for _, fb := range ch.delivered {
+		blocks = append(blocks, fb.block)
+	}
+	return blocks
+}
+
+// Announced returns the consensus metadata carried by the last handshake of
+// the given chain.
+func (p *StandIn) Announced(chainID string) *ab.BFTsmartMetadata {
+	ch := p.chain(chainID)
+
+	ch.lock.Lock()
+	defer ch.lock.Unlock()
+
+	return ch.announced
+}
+
+// Inject writes the given frames on the receive connection of the given chain,
+// as if they had been sent by the replicas.
+func (p *StandIn) Inject(chainID string, frames ...*protocol.Frame) error {
+	ch := p.chain(chainID)
+
+	ch.lock.Lock()
+	defer ch.lock.Unlock()
+
+	if ch.deliver == nil {
+		return fmt.Errorf("chain %s is not connected", chainID)
+	}
+
+	for _, f := range frames {
+		if err := protocol.WriteFrame(ch.deliver, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,624 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package standin provides an in-process stand-in for the java component of
+// the bftsmart consenter, to test and benchmark the consenter without it.
+package standin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/config/channel"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/orderer/common/blockcutter"
+	localconfig "github.com/hyperledger/fabric/orderer/common/localconfig"
+	"github.com/hyperledger/fabric/orderer/consensus/bftsmart/protocol"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// StandIn is an in-process stand-in for the java component. It speaks the
+// same protocol: it accepts envelopes on the send address, cuts them into
+// blocks with a blockcutter, and streams every block, followed by its type,
+// to the chain connected on the receive address. The blocks are signed by the
+// given identities, standing for the replicas.
+//
+// It runs no consensus protocol at all, and keeps every block it ordered in
+// memory: it is only meant to test and benchmark the consenter, and the rest
+// of the orderer, on a single machine.
+type StandIn struct {
+	dir          string
+	sendAddr     string
+	recvAddr     string
+	sendListener net.Listener
+	recvListener net.Listener
+
+	lock   sync.Mutex
+	chains map[string]*standInChain
+	conns  map[net.Conn]struct{}
+
+	// signers sign every block produced
+	signers []msp.SigningIdentity
+
+	// version is the protocol version announced in handshakes
+	version uint32
+
+	// rejectBatchConfig makes the proxy answer batch configurations with an error
+	rejectBatchConfig bool
+
+	// withholdAcks makes the proxy order envelopes without acknowledging them
+	withholdAcks bool
+
+	// withholdBatchConfigAcks makes the proxy apply batch configurations
+	// without acknowledging them
+	withholdBatchConfigAcks bool
+
+	// batchConfigs receives the batch configuration frames received, unless full
+	batchConfigs chan *protocol.Frame
+
+	// rejections receives the error frames sent back by the chains, unless full
+	rejections chan *protocol.Frame
+
+	exit chan struct{}
+}
+
+// ReplicaID is the ID of the replica the stand-in proxy stands for.
+const ReplicaID uint32 = 1
+
+// standInChain is the state kept by the stand-in proxy for a single chain.
+type standInChain struct {
+	chainID   string
+	envelopes chan *standInEnvelope
+
+	// ready is closed once the chain has connected and announced its last block
+	ready chan struct{}
+
+	lock         sync.Mutex
+	sharedConfig *standInConfig
+	cutter       blockcutter.Receiver
+	lastHeader   *cb.BlockHeader
+	lastMetadata *ab.BFTsmartMetadata
+	signers      []msp.SigningIdentity
+	delivered    []*standInBlock
+	deliver      net.Conn
+
+	// announced is the consensus metadata carried by the last handshake, if any
+	announced *ab.BFTsmartMetadata
+}
+
+// standInConfig is the batch configuration of a chain of the stand-in proxy,
+// as the block cutter sees it. The rest of the orderer config is unknown to
+// the proxy, and left unimplemented.
+type standInConfig struct {
+	config.Orderer
+	batchSize    *ab.BatchSize
+	batchTimeout time.Duration
+}
+
+func (sc *standInConfig) BatchSize() *ab.BatchSize {
+	return sc.batchSize
+}
+
+func (sc *standInConfig) BatchTimeout() time.Duration {
+	return sc.batchTimeout
+}
+
+// BatchCutting returns nil, for the blocks to be cut with the default strategy.
+func (sc *standInConfig) BatchCutting() *ab.BatchCutting {
+	return nil
+}
+
+type standInEnvelope struct {
+	env      *cb.Envelope
+	isConfig bool
+}
+
+type standInBlock struct {
+	block    *cb.Block
+	isConfig bool
+	metadata *ab.BFTsmartMetadata
+}
+
+// New starts a stand-in proxy listening on a unix socket in a temporary
+// directory and on a local tcp port, whose blocks are signed by the given
+// identities.
+func New(signers ...msp.SigningIdentity) (*StandIn, error) {
+	dir, err := ioutil.TempDir("", "bftsmart-proxy")
+	if err != nil {
+		return nil, err
+	}
+
+	p := &StandIn{
+		dir:          dir,
+		sendAddr:     filepath.Join(dir, "pool.sock"),
+		recvAddr:     "127.0.0.1:0",
+		chains:       make(map[string]*standInChain),
+		conns:        make(map[net.Conn]struct{}),
+		signers:      signers,
+		version:      protocol.Version,
+		batchConfigs: make(chan *protocol.Frame, 100),
+		rejections:   make(chan *protocol.Frame, 100),
+		exit:         make(chan struct{}),
+	}
+
+	if err := p.Restart(); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	// listen on the same port when restarted
+	p.recvAddr = p.recvListener.Addr().String()
+	return p, nil
+}
+
+// Config returns the configuration of a consenter connecting to this proxy,
+// for an ordering service of as many replicas as the proxy has signers. The
+// pool has a single connection, so that envelopes are ordered in the order
+// they are sent.
+func (p *StandIn) Config() localconfig.BFTsmart {
+	return localconfig.BFTsmart{
+		ConnectionPoolSize: 1,
+		Replicas:           uint32(len(p.signers)),
+		MaxInFlight:        100,
+		AckTimeout:         protocol.DefaultAckTimeout,
+		SendAddress:        "unix://" + p.sendAddr,
+		RecvAddress:        "tcp://" + p.recvAddr,
+	}
+}
+
+// Restart starts listening on the send and receive addresses.
+func (p *StandIn) Restart() error {
+	sendListener, err := net.Listen("unix", p.sendAddr)
+	if err != nil {
+		return err
+	}
+
+	recvListener, err := net.Listen("tcp", p.recvAddr)
+	if err != nil {
+		sendListener.Close()
+		return err
+	}
+
+	p.lock.Lock()
+	p.sendListener = sendListener
+	p.recvListener = recvListener
+	p.lock.Unlock()
+
+	go p.accept(sendListener)
+	go p.accept(recvListener)
+	return nil
+}
+
+// Stop simulates a crash of the java component: the listeners and every
+// connection are closed, but the blocks ordered so far are kept.
+func (p *StandIn) Stop() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.sendListener.Close()
+	p.recvListener.Close()
+	for conn := range p.conns {
+		conn.Close()
+	}
+	p.conns = make(map[net.Conn]struct{})
+}
+
+// Close stops the proxy for good.
+func (p *StandIn) Close() {
+	p.Stop()
+	close(p.exit)
+	os.RemoveAll(p.dir)
+}
+
+// chain returns the state of the given chain, creating it on first use.
+func (p *StandIn) chain(chainID string) *standInChain {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if ch, ok := p.chains[chainID]; ok {
+		return ch
+	}
+
+	sharedConfig := &standInConfig{
+		batchSize: &ab.BatchSize{
+			MaxMessageCount:   10,
+			AbsoluteMaxBytes:  10 * 1024 * 1024,
+			PreferredMaxBytes: 1024 * 1024,
+		},
+		batchTimeout: time.Second,
+	}
+
+	ch := &standInChain{
+		chainID:      chainID,
+		envelopes:    make(chan *standInEnvelope, 100),
+		ready:        make(chan struct{}),
+		sharedConfig: sharedConfig,
+		cutter:       blockcutter.NewReceiverImpl(sharedConfig),
+		signers:      p.signers,
+	}
+	p.chains[chainID] = ch
+
+	go ch.main(p.exit)
+	return ch
+}
+
+func (p *StandIn) accept(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		p.lock.Lock()
+		p.conns[conn] = struct{}{}
+		p.lock.Unlock()
+
+		go p.serve(conn)
+	}
+}
+
+func (p *StandIn) serve(conn net.Conn) {
+	defer func() {
+		p.lock.Lock()
+		delete(p.conns, conn)
+		p.lock.Unlock()
+		conn.Close()
+	}()
+
+	hello, err := protocol.ReadFrame(conn, protocol.DefaultMaxFrameSize)
+	if err != nil || hello.Kind != protocol.MsgHandshake {
+		return
+	}
+
+	p.lock.Lock()
+	version := p.version
+	p.lock.Unlock()
+
+	if err := protocol.WriteFrame(conn, protocol.NewFrame(protocol.MsgHandshake, hello.ID).AddUint32(protocol.FieldVersion, version)); err != nil {
+		return
+	}
+
+	role, err := hello.GetUint32(protocol.FieldRole)
+	if err != nil {
+		return
+	}
+
+	switch protocol.ConnRole(role) {
+	case protocol.RoleControl:
+		p.serveControl(conn)
+	case protocol.RolePool:
+		p.servePool(conn)
+	case protocol.RoleDeliver:
+		p.serveDeliver(conn, hello)
+	default:
+		protocol.RejectFrame(conn, hello.ID, fmt.Errorf("unknown role %d", role))
+	}
+}
+
+func (p *StandIn) serveControl(conn net.Conn) {
+	for {
+		f, err := protocol.ReadFrame(conn, protocol.DefaultMaxFrameSize)
+		if err != nil {
+			return
+		}
+
+		if f.Kind != protocol.MsgBatchConfig {
+			protocol.RejectFrame(conn, f.ID, fmt.Errorf("unexpected %s frame on control connection", f.Kind))
+			continue
+		}
+
+		select {
+		case p.batchConfigs <- f:
+		default:
+		}
+
+		p.lock.Lock()
+		reject := p.rejectBatchConfig
+		withhold := p.withholdBatchConfigAcks
+		p.lock.Unlock()
+
+		if reject {
+			protocol.RejectFrame(conn, f.ID, fmt.Errorf("batch configuration rejected"))
+			continue
+		}
+
+		if err := p.applyBatchConfig(f); err != nil {
+			protocol.RejectFrame(conn, f.ID, err)
+			continue
+		}
+
+		if withhold {
+			continue
+		}
+
+		if err := protocol.WriteFrame(conn, protocol.NewFrame(protocol.MsgAck, f.ID)); err != nil {
+			return
+		}
+	}
+}
+
+func (p *StandIn) applyBatchConfig(f *protocol.Frame) error {
+	chainID, err := f.GetString(protocol.FieldChainID)
+	if err != nil {
+		return err
+	}
+	preferredMaxBytes, err := f.GetUint32(protocol.FieldPreferredMaxBytes)
+	if err != nil {
+		return err
+	}
+	maxMessageCount, err := f.GetUint32(protocol.FieldMaxMessageCount)
+	if err != nil {
+		return err
+	}
+	batchTimeout, err := f.GetUint64(protocol.FieldBatchTimeout)
+	if err != nil {
+		return err
+	}
+
+	ch := p.chain(chainID)
+
+	ch.lock.Lock()
+	defer ch.lock.Unlock()
+
+	ch.sharedConfig.batchSize = &ab.BatchSize{
+		MaxMessageCount:   maxMessageCount,
+		AbsoluteMaxBytes:  ch.sharedConfig.batchSize.AbsoluteMaxBytes,
+		PreferredMaxBytes: preferredMaxBytes,
+	}
+	ch.sharedConfig.batchTimeout = time.Duration(batchTimeout)
+	return nil
+}
+
+func (p *StandIn) servePool(conn net.Conn) {
+	for {
+		f, err := protocol.ReadFrame(conn, protocol.DefaultMaxFrameSize)
+		if err != nil {
+			return
+		}
+
+		if f.Kind != protocol.MsgEnvelope && f.Kind != protocol.MsgConfigEnvelope {
+			protocol.RejectFrame(conn, f.ID, fmt.Errorf("unexpected %s frame on pool connection", f.Kind))
+			continue
+		}
+
+		chainID, err := f.GetString(protocol.FieldChainID)
+		if err != nil {
+			protocol.RejectFrame(conn, f.ID, err)
+			continue
+		}
+
+		payload, err := f.GetBytes(protocol.FieldPayload)
+		if err != nil {
+			protocol.RejectFrame(conn, f.ID, err)
+			continue
+		}
+
+		env, err := utils.UnmarshalEnvelope(payload)
+		if err != nil {
+			protocol.RejectFrame(conn, f.ID, err)
+			continue
+		}
+
+		p.chain(chainID).envelopes <- &standInEnvelope{env: env, isConfig: f.Kind == protocol.MsgConfigEnvelope}
+
+		p.lock.Lock()
+		withhold := p.withholdAcks
+		p.lock.Unlock()
+
+		if withhold {
+			continue
+		}
+
+		if err := protocol.WriteFrame(conn, protocol.NewFrame(protocol.MsgAck, f.ID)); err != nil {
+			return
+		}
+	}
+}
+
+func (p *StandIn) serveDeliver(conn net.Conn, hello *protocol.Frame) {
+	chainID, err := hello.GetString(protocol.FieldChainID)
+	if err != nil {
+		return
+	}
+
+	headerBytes, err := hello.GetBytes(protocol.FieldHeader)
+	if err != nil {
+		return
+	}
+
+	header := &cb.BlockHeader{}
+	if err := proto.Unmarshal(headerBytes, header); err != nil {
+		return
+	}
+
+	// the consensus metadata is only known to chains which committed blocks
+	// delivered by the java component
+	metadata, err := hello.GetConsensusMetadata()
+	if err != nil {
+		metadata = nil
+	}
+
+	ch := p.chain(chainID)
+	if err := ch.attach(conn, header, metadata); err != nil {
+		return
+	}
+
+	// the chain only ever answers heartbeats, or rejects the frames it receives
+	for {
+		f, err := protocol.ReadFrame(conn, protocol.DefaultMaxFrameSize)
+		if err != nil {
+			ch.detach(conn)
+			return
+		}
+
+		if f.Kind == protocol.MsgError {
+			select {
+			case p.rejections <- f:
+			default:
+			}
+		}
+	}
+}
+
+// attach makes conn the receive connection of the chain, and sends it the
+// blocks following the given header. The first chain to attach tells where
+// the consensus protocol resumes.
+func (ch *standInChain) attach(conn net.Conn, header *cb.BlockHeader, metadata *ab.BFTsmartMetadata) error {
+	ch.lock.Lock()
+	defer ch.lock.Unlock()
+
+	ch.announced = metadata
+
+	if ch.lastHeader == nil {
+		ch.lastHeader = header
+		ch.lastMetadata = metadata
+		close(ch.ready)
+	}
+
+	// the previous connection, if any, is left for the chain to close, so
+	// that the frames it sent on it are still received
+	ch.deliver = conn
+
+	for _, fb := range ch.delivered {
+		if fb.block.Header.Number <= header.Number {
+			continue
+		}
+		if err := ch.send(fb); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ch *standInChain) detach(conn net.Conn) {
+	ch.lock.Lock()
+	defer ch.lock.Unlock()
+
+	if ch.deliver == conn {
+		ch.deliver = nil
+	}
+}
+
+// send writes the block, followed by its type, on the receive connection.
+// It must be called with the lock held.
+func (ch *standInChain) send(fb *standInBlock) error {
+	id := protocol.NextCorrelationID()
+
+	if err := protocol.WriteFrame(ch.deliver, protocol.NewFrame(protocol.MsgBlock, id).AddBytes(protocol.FieldPayload, utils.MarshalOrPanic(fb.block))); err != nil {
+		return err
+	}
+
+	return protocol.WriteFrame(ch.deliver, protocol.NewFrame(protocol.MsgBlockType, id).AddBool(protocol.FieldIsConfig, fb.isConfig).AddConsensusMetadata(fb.metadata))
+}
+
+// deliverBatch creates the next block out of the batch, and sends it to the chain
+// if it is connected. It must be called with the lock held.
+func (ch *standInChain) deliverBatch(batch []*cb.Envelope, isConfig bool) {
+	block := cb.NewBlock(ch.lastHeader.Number+1, ch.lastHeader.Hash())
+	for _, env := range batch {
+		block.Data.Data = append(block.Data.Data, utils.MarshalOrPanic(env))
+	}
+	block.Header.DataHash = block.Data.Hash()
+	SignBlock(block, ch.signers...)
+
+	metadata := &ab.BFTsmartMetadata{Sequence: 1, ReplicaId: ReplicaID}
+	if ch.lastMetadata != nil {
+		metadata.View = ch.lastMetadata.View
+		metadata.Sequence = ch.lastMetadata.Sequence + 1
+	}
+
+	fb := &standInBlock{block: block, isConfig: isConfig, metadata: metadata}
+	ch.delivered = append(ch.delivered, fb)
+	ch.lastHeader = block.Header
+	ch.lastMetadata = metadata
+
+	if ch.deliver == nil {
+		return
+	}
+
+	if err := ch.send(fb); err != nil {
+		// the block is sent again once the chain reconnects
+		ch.deliver.Close()
+		ch.deliver = nil
+	}
+}
+
+// main orders the envelopes of the chain, in the same way as the solo consenter.
+func (ch *standInChain) main(exit <-chan struct{}) {
+	select {
+	case <-ch.ready:
+	case <-exit:
+		return
+	}
+
+	var timer <-chan time.Time
+
+	for {
+		select {
+		case msg := <-ch.envelopes:
+			ch.lock.Lock()
+			if msg.isConfig {
+				if batch := ch.cutter.Cut(); len(batch) > 0 {
+					ch.deliverBatch(batch, false)
+				}
+				ch.deliverBatch([]*cb.Envelope{msg.env}, true)
+				timer = nil
+			} else {
+				batches, pending := ch.cutter.Ordered(msg.env)
+				for _, batch := range batches {
+					ch.deliverBatch(batch, false)
+				}
+				switch {
+				case !pending:
+					timer = nil
+				case timer == nil:
+					timer = time.After(ch.sharedConfig.BatchTimeout())
+				}
+			}
+			ch.lock.Unlock()
+
+		case <-timer:
+			timer = nil
+			ch.lock.Lock()
+			if batch := ch.cutter.Cut(); len(batch) > 0 {
+				ch.deliverBatch(batch, false)
+			}
+			ch.lock.Unlock()
+
+		case <-exit:
+			return
+		}
+	}
+}
+
+// SignBlock adds the signatures of the given identities to the block, as the
+// replicas do.
+func SignBlock(block *cb.Block, signers ...msp.SigningIdentity) {
+	metadata := &cb.Metadata{}
+	for _, signer := range signers {
+		creator, err := signer.Serialize()
+		if err != nil {
+			panic(err)
+		}
+		shdr := utils.MarshalOrPanic(&cb.SignatureHeader{Creator: creator, Nonce: []byte(fmt.Sprintf("nonce of %s", creator))})
+		sig, err := signer.Sign(util.ConcatenateBytes(metadata.Value, shdr, block.Header.Bytes()))
+		if err != nil {
+			panic(err)
+		}
+		metadata.Signatures = append(metadata.Signatures, &cb.MetadataSignature{SignatureHeader: shdr, Signature: sig})
+	}
+	block.Metadata.Metadata[cb.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(metadata)
+}
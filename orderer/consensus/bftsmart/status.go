@@ -0,0 +1,90 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bftsmart
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+// StatusPath is the path at which the orderer serves the status of the
+// bftsmart chains.
+const StatusPath = "/bftsmart/status"
+
+// Status describes the state of a bftsmart chain.
+type Status struct {
+	ChainID string `json:"chain_id"`
+
+	// Connected tells whether the chain is connected to the java component
+	Connected bool `json:"connected"`
+
+	// ConnectedReplicas is the number of replicas the java component last
+	// reported to reach, zero while the chain is not connected
+	ConnectedReplicas uint32 `json:"connected_replicas"`
+
+	// LastBlock is the number of the last block received from the java component
+	LastBlock uint64 `json:"last_block"`
+
+	// InFlight is the number of envelopes waiting for the acknowledgement of
+	// the java component
+	InFlight int `json:"in_flight"`
+}
+
+// Status returns the current status of the chain.
+func (ch *chain) Status() Status {
+	ch.lock.Lock()
+	defer ch.lock.Unlock()
+
+	status := Status{
+		ChainID:           ch.support.ChainID(),
+		ConnectedReplicas: ch.connectedReplicas,
+		LastBlock:         ch.lastBlock,
+		InFlight:          len(ch.inflight),
+	}
+
+	select {
+	case <-ch.errorChan:
+	default:
+		status.Connected = true
+	}
+
+	return status
+}
+
+// Status returns the status of the chains of the consenter, sorted by chain ID.
+func (bftsmart *consenter) Status() []Status {
+	bftsmart.chainsLock.Lock()
+	chains := make([]*chain, 0, len(bftsmart.chains))
+	for _, ch := range bftsmart.chains {
+		chains = append(chains, ch)
+	}
+	bftsmart.chainsLock.Unlock()
+
+	statuses := make([]Status, len(chains))
+	for i, ch := range chains {
+		statuses[i] = ch.Status()
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ChainID < statuses[j].ChainID
+	})
+	return statuses
+}
+
+// ServeHTTP writes the status of the chains of the consenter as JSON.
+func (bftsmart *consenter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(bftsmart.Status()); err != nil {
+		logger.Warningf("Could not write bftsmart status: %s", err)
+	}
+}
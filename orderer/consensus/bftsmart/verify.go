@@ -0,0 +1,150 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bftsmart
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/orderer/consensus"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// quorumSize returns the number of replicas that must sign a block, 2f+1,
+// for an ordering service of the given number of replicas.
+func quorumSize(replicas uint32) int {
+	f := (replicas - 1) / 3
+	return int(2*f + 1)
+}
+
+// blockVerifier checks the blocks received from the java component before
+// they are appended to the ledger. The java component runs on the same host
+// as the orderer, but it must not be trusted any more than a single replica:
+// a block is only accepted if a quorum of replicas signed it.
+type blockVerifier struct {
+	support consensus.ConsenterSupport
+	quorum  int
+}
+
+func newBlockVerifier(support consensus.ConsenterSupport, replicas uint32) *blockVerifier {
+	return &blockVerifier{
+		support: support,
+		quorum:  quorumSize(replicas),
+	}
+}
+
+// verify checks that the block follows the given header, that its data
+// matches its header, and that it carries valid signatures from a quorum of
+// distinct identities of the orderer organizations of the channel.
+func (bv *blockVerifier) verify(block *cb.Block, lastHeader *cb.BlockHeader) error {
+	if block.Header.Number != lastHeader.Number+1 {
+		return fmt.Errorf("expected block %d but received block %d", lastHeader.Number+1, block.Header.Number)
+	}
+
+	if !bytes.Equal(block.Header.PreviousHash, lastHeader.Hash()) {
+		return fmt.Errorf("block %d does not point to the hash of block %d", block.Header.Number, lastHeader.Number)
+	}
+
+	if block.Data == nil {
+		return fmt.Errorf("block %d has no data", block.Header.Number)
+	}
+
+	if !bytes.Equal(block.Header.DataHash, block.Data.Hash()) {
+		return fmt.Errorf("block %d has a data hash which does not match its data", block.Header.Number)
+	}
+
+	if block.Metadata == nil || len(block.Metadata.Metadata) < len(cb.BlockMetadataIndex_name) {
+		return fmt.Errorf("block %d has incomplete metadata", block.Header.Number)
+	}
+
+	return bv.verifySignatures(block)
+}
+
+func (bv *blockVerifier) verifySignatures(block *cb.Block) error {
+	metadata, err := utils.GetMetadataFromBlock(block, cb.BlockMetadataIndex_SIGNATURES)
+	if err != nil {
+		return fmt.Errorf("block %d has invalid signature metadata: %s", block.Header.Number, err)
+	}
+
+	ordererMSPs := make(map[string]struct{})
+	for _, org := range bv.support.SharedConfig().Organizations() {
+		ordererMSPs[org.MSPID()] = struct{}{}
+	}
+
+	deserializer := bv.support.MSPManager()
+	headerBytes := block.Header.Bytes()
+
+	// signers holds the creators of the valid signatures, so that a replica
+	// signing twice is only counted once
+	signers := make(map[string]struct{})
+
+	for i, sig := range metadata.Signatures {
+		creator, err := verifySignature(deserializer, ordererMSPs, util.ConcatenateBytes(metadata.Value, sig.SignatureHeader, headerBytes), sig)
+		if err != nil {
+			logger.Warningf("[channel: %s] Ignoring signature %d of block %d: %s", bv.support.ChainID(), i, block.Header.Number, err)
+			continue
+		}
+		signers[string(creator)] = struct{}{}
+	}
+
+	if len(signers) < bv.quorum {
+		return fmt.Errorf("block %d carries %d valid signatures, %d are required", block.Header.Number, len(signers), bv.quorum)
+	}
+
+	return nil
+}
+
+// verifyConsensusMetadata checks that the consensus metadata of a block does
+// not go back in the consensus protocol compared to that of the previous block,
+// which is nil if it is unknown.
+func verifyConsensusMetadata(metadata, last *ab.BFTsmartMetadata) error {
+	if last == nil {
+		return nil
+	}
+
+	if metadata.Sequence <= last.Sequence {
+		return fmt.Errorf("block was decided by consensus instance %d, but the previous block by instance %d", metadata.Sequence, last.Sequence)
+	}
+
+	if metadata.View < last.View {
+		return fmt.Errorf("block was decided in view %d, but the previous block in view %d", metadata.View, last.View)
+	}
+
+	return nil
+}
+
+// verifySignature checks that the signature over the given bytes was made by
+// a valid identity of one of the given MSPs, and returns its serialized form.
+func verifySignature(deserializer msp.IdentityDeserializer, msps map[string]struct{}, signedBytes []byte, sig *cb.MetadataSignature) ([]byte, error) {
+	shdr, err := utils.GetSignatureHeader(sig.SignatureHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := deserializer.DeserializeIdentity(shdr.Creator)
+	if err != nil {
+		return nil, fmt.Errorf("invalid creator: %s", err)
+	}
+
+	if _, ok := msps[identity.GetMSPIdentifier()]; !ok {
+		return nil, fmt.Errorf("creator belongs to %s, which is not an orderer organization", identity.GetMSPIdentifier())
+	}
+
+	if err := identity.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid creator: %s", err)
+	}
+
+	if err := identity.Verify(signedBytes, sig.Signature); err != nil {
+		return nil, fmt.Errorf("invalid signature: %s", err)
+	}
+
+	return shdr.Creator, nil
+}
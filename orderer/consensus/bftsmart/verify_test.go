@@ -0,0 +1,244 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bftsmart
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/config/channel"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/orderer/consensus/bftsmart/standin"
+	cb "github.com/hyperledger/fabric/protos/common"
+	mspproto "github.com/hyperledger/fabric/protos/msp"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+const testOrdererMSPID = "OrdererMSP"
+
+// testOrg is an orderer organization of the channel.
+type testOrg struct {
+	name  string
+	mspID string
+}
+
+func (o *testOrg) Name() string {
+	return o.name
+}
+
+func (o *testOrg) MSPID() string {
+	return o.mspID
+}
+
+var testOrganizations = map[string]config.Org{
+	"OrdererOrg": &testOrg{name: "OrdererOrg", mspID: testOrdererMSPID},
+}
+
+// testIdentity is a signing identity whose signatures are the hash of its
+// name followed by the signed message.
+type testIdentity struct {
+	mspID   string
+	name    string
+	invalid bool
+}
+
+func (id *testIdentity) ExpiresAt() time.Time {
+	return time.Time{}
+}
+
+func (id *testIdentity) GetIdentifier() *msp.IdentityIdentifier {
+	return &msp.IdentityIdentifier{Mspid: id.mspID, Id: id.name}
+}
+
+func (id *testIdentity) GetMSPIdentifier() string {
+	return id.mspID
+}
+
+func (id *testIdentity) Validate() error {
+	if id.invalid {
+		return fmt.Errorf("identity %s is revoked", id.name)
+	}
+	return nil
+}
+
+func (id *testIdentity) GetOrganizationalUnits() []*msp.OUIdentifier {
+	return nil
+}
+
+func (id *testIdentity) Verify(msg []byte, sig []byte) error {
+	expected, _ := id.Sign(msg)
+	if !bytes.Equal(expected, sig) {
+		return fmt.Errorf("signature of %s does not match", id.name)
+	}
+	return nil
+}
+
+func (id *testIdentity) Serialize() ([]byte, error) {
+	return utils.Marshal(&mspproto.SerializedIdentity{Mspid: id.mspID, IdBytes: []byte(id.name)})
+}
+
+func (id *testIdentity) SatisfiesPrincipal(principal *mspproto.MSPPrincipal) error {
+	return nil
+}
+
+func (id *testIdentity) Sign(msg []byte) ([]byte, error) {
+	digest := sha256.Sum256(util.ConcatenateBytes([]byte(id.name), msg))
+	return digest[:], nil
+}
+
+func (id *testIdentity) GetPublicVersion() msp.Identity {
+	return id
+}
+
+// testMSPManager deserializes the identities it was created with.
+type testMSPManager struct {
+	identities map[string]msp.Identity
+}
+
+func newTestMSPManager(identities ...msp.SigningIdentity) *testMSPManager {
+	mgr := &testMSPManager{identities: make(map[string]msp.Identity)}
+	for _, id := range identities {
+		serialized, err := id.Serialize()
+		if err != nil {
+			panic(err)
+		}
+		mgr.identities[string(serialized)] = id.GetPublicVersion()
+	}
+	return mgr
+}
+
+func (mgr *testMSPManager) DeserializeIdentity(serializedIdentity []byte) (msp.Identity, error) {
+	id, ok := mgr.identities[string(serializedIdentity)]
+	if !ok {
+		return nil, fmt.Errorf("unknown identity %x", serializedIdentity)
+	}
+	return id, nil
+}
+
+func (mgr *testMSPManager) Setup(msps []msp.MSP) error {
+	return nil
+}
+
+func (mgr *testMSPManager) GetMSPs() (map[string]msp.MSP, error) {
+	return nil, nil
+}
+
+// testReplicas are the identities of the replicas of the ordering service,
+// which sign the blocks produced by the stand-in proxy.
+var testReplicas = []msp.SigningIdentity{
+	&testIdentity{mspID: testOrdererMSPID, name: "replica0"},
+	&testIdentity{mspID: testOrdererMSPID, name: "replica1"},
+	&testIdentity{mspID: testOrdererMSPID, name: "replica2"},
+	&testIdentity{mspID: testOrdererMSPID, name: "replica3"},
+}
+
+func TestQuorumSize(t *testing.T) {
+	for replicas, quorum := range map[uint32]int{1: 1, 3: 1, 4: 3, 6: 3, 7: 5, 10: 7} {
+		assert.Equal(t, quorum, quorumSize(replicas), "Unexpected quorum for %d replicas", replicas)
+	}
+}
+
+func TestBlockVerifier(t *testing.T) {
+	foreign := &testIdentity{mspID: "PeerMSP", name: "peer0"}
+	revoked := &testIdentity{mspID: testOrdererMSPID, name: "replica4", invalid: true}
+
+	support := newMockSupport(testChainID)
+	support.MSPManagerVal = newTestMSPManager(append([]msp.SigningIdentity{foreign, revoked}, testReplicas...)...)
+	bv := newBlockVerifier(support, uint32(len(testReplicas)))
+
+	last := support.LastBlockVal.Header
+	newBlock := func(signers ...msp.SigningIdentity) *cb.Block {
+		block := cb.NewBlock(last.Number+1, last.Hash())
+		block.Data.Data = [][]byte{[]byte("data")}
+		block.Header.DataHash = block.Data.Hash()
+		standin.SignBlock(block, signers...)
+		return block
+	}
+
+	t.Run("Proper", func(t *testing.T) {
+		assert.NoError(t, bv.verify(newBlock(testReplicas[0], testReplicas[1], testReplicas[2]), last))
+		assert.NoError(t, bv.verify(newBlock(testReplicas[3], testReplicas[1], testReplicas[0], testReplicas[2]), last))
+	})
+
+	t.Run("WrongNumber", func(t *testing.T) {
+		block := newBlock()
+		block.Header.Number++
+		standin.SignBlock(block, testReplicas[0], testReplicas[1], testReplicas[2])
+		assert.Error(t, bv.verify(block, last))
+	})
+
+	t.Run("WrongPreviousHash", func(t *testing.T) {
+		block := newBlock()
+		block.Header.PreviousHash = []byte("forged")
+		standin.SignBlock(block, testReplicas[0], testReplicas[1], testReplicas[2])
+		assert.Error(t, bv.verify(block, last))
+	})
+
+	t.Run("WrongDataHash", func(t *testing.T) {
+		block := newBlock(testReplicas[0], testReplicas[1], testReplicas[2])
+		block.Data.Data = [][]byte{[]byte("forged")}
+		assert.Error(t, bv.verify(block, last))
+	})
+
+	t.Run("NoMetadata", func(t *testing.T) {
+		block := newBlock(testReplicas[0], testReplicas[1], testReplicas[2])
+		block.Metadata = nil
+		assert.Error(t, bv.verify(block, last))
+	})
+
+	t.Run("TamperedHeader", func(t *testing.T) {
+		block := newBlock(testReplicas[0], testReplicas[1], testReplicas[2])
+		block.Data.Data = [][]byte{[]byte("forged")}
+		block.Header.DataHash = block.Data.Hash()
+		assert.Error(t, bv.verify(block, last), "Expected signatures over the original header to be rejected")
+	})
+
+	t.Run("NotEnoughSignatures", func(t *testing.T) {
+		assert.Error(t, bv.verify(newBlock(testReplicas[0], testReplicas[1]), last))
+	})
+
+	t.Run("DuplicateSigner", func(t *testing.T) {
+		assert.Error(t, bv.verify(newBlock(testReplicas[0], testReplicas[1], testReplicas[1]), last))
+	})
+
+	t.Run("ForeignOrganization", func(t *testing.T) {
+		assert.Error(t, bv.verify(newBlock(testReplicas[0], testReplicas[1], foreign), last))
+	})
+
+	t.Run("InvalidIdentity", func(t *testing.T) {
+		assert.Error(t, bv.verify(newBlock(testReplicas[0], testReplicas[1], revoked), last))
+	})
+
+	t.Run("UnknownIdentity", func(t *testing.T) {
+		unknown := &testIdentity{mspID: testOrdererMSPID, name: "stranger"}
+		assert.Error(t, bv.verify(newBlock(testReplicas[0], testReplicas[1], unknown), last))
+	})
+
+	t.Run("ForgedSignature", func(t *testing.T) {
+		block := newBlock(testReplicas[0], testReplicas[1], testReplicas[2])
+		metadata := utils.GetMetadataFromBlockOrPanic(block, cb.BlockMetadataIndex_SIGNATURES)
+		metadata.Signatures[2].Signature = []byte("forged")
+		block.Metadata.Metadata[cb.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(metadata)
+		assert.Error(t, bv.verify(block, last))
+	})
+}
+
+func TestVerifyConsensusMetadata(t *testing.T) {
+	last := &ab.BFTsmartMetadata{View: 2, Sequence: 10, ReplicaId: 1}
+
+	assert.NoError(t, verifyConsensusMetadata(&ab.BFTsmartMetadata{View: 2, Sequence: 11}, last))
+	assert.NoError(t, verifyConsensusMetadata(&ab.BFTsmartMetadata{View: 3, Sequence: 15}, last), "Expected view changes and skipped instances to be accepted")
+	assert.NoError(t, verifyConsensusMetadata(&ab.BFTsmartMetadata{}, nil), "Expected any metadata to follow unknown metadata")
+	assert.Error(t, verifyConsensusMetadata(&ab.BFTsmartMetadata{View: 2, Sequence: 10}, last))
+	assert.Error(t, verifyConsensusMetadata(&ab.BFTsmartMetadata{View: 1, Sequence: 11}, last))
+}
@@ -7,13 +7,21 @@ SPDX-License-Identifier: Apache-2.0
 package consensus
 
 import (
+	"errors"
+
 	"github.com/hyperledger/fabric/common/config/channel"
 	"github.com/hyperledger/fabric/common/crypto"
 	"github.com/hyperledger/fabric/orderer/common/blockcutter"
+	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/orderer/common/msgprocessor"
 	cb "github.com/hyperledger/fabric/protos/common"
 )
 
+// ErrServiceUnavailable is returned, possibly wrapped, by chains which are
+// temporarily unable to accept messages, for instance because too many
+// messages are waiting to be ordered. Clients may retry later.
+var ErrServiceUnavailable = errors.New("service unavailable")
+
 // Consenter defines the backing ordering mechanism.
 type Consenter interface {
 	// HandleChain should create and return a reference to a Chain for the given set of resources.
@@ -25,6 +33,16 @@ type Consenter interface {
 	HandleChain(support ConsenterSupport, metadata *cb.Metadata) (Chain, error)
 }
 
+// MigrationValidator may be implemented by the consenters which can take over a channel migrated
+// from another consensus type only under some conditions.  When a channel migrates to such a
+// consenter, the config update is rejected at ingress if ValidateMigration returns an error,
+// as by the time the chain is handed off every orderer of the channel has already committed to it.
+type MigrationValidator interface {
+	// ValidateMigration checks that the consenter can take over the channel of the given support,
+	// with the orderer config set by the config update migrating the channel.
+	ValidateMigration(support ConsenterSupport, ordererConfig config.Orderer) error
+}
+
 // Chain defines a way to inject messages for ordering.
 // Note, that in order to allow flexibility in the implementation, it is the responsibility of the implementer
 // to take the ordered messages, send them through the blockcutter.Receiver supplied via HandleChain to cut blocks,
@@ -81,6 +99,9 @@ type ConsenterSupport interface {
 	// SharedConfig provides the shared config from the channel's current config block.
 	SharedConfig() config.Orderer
 
+	// MSPManager returns the MSP manager built from the channel's current config block.
+	MSPManager() msp.MSPManager
+
 	GetLastBlock() *cb.Block            //JCS: my own method
 	AppendBlock(block *cb.Block) error  //JCS: my own method
 	ProcessConfigBlock(block *cb.Block) //JCS: my own method
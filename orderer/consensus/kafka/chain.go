@@ -8,6 +8,7 @@ package kafka
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"time"
 
@@ -36,20 +37,21 @@ const (
 	indexExitChanPass
 )
 
-func newChain(consenter commonConsenter, support consensus.ConsenterSupport, lastOffsetPersisted int64) (*chainImpl, error) {
+func newChain(consenter commonConsenter, support consensus.ConsenterSupport, lastOffsetPersisted int64, pendingResubmissions map[int64]uint64) (*chainImpl, error) {
 	lastCutBlockNumber := getLastCutBlockNumber(support.Height())
-	logger.Infof("[channel: %s] Starting chain with last persisted offset %d and last recorded block %d",
-		support.ChainID(), lastOffsetPersisted, lastCutBlockNumber)
+	logger.Infof("[channel: %s] Starting chain with last persisted offset %d, %d pending config re-submissions and last recorded block %d",
+		support.ChainID(), lastOffsetPersisted, len(pendingResubmissions), lastCutBlockNumber)
 
 	errorChan := make(chan struct{})
 	close(errorChan) // We need this closed when starting up
 
 	return &chainImpl{
-		consenter:           consenter,
-		support:             support,
-		channel:             newChannel(support.ChainID(), defaultPartition),
-		lastOffsetPersisted: lastOffsetPersisted,
-		lastCutBlockNumber:  lastCutBlockNumber,
+		consenter:            consenter,
+		support:              support,
+		channel:              newChannel(support.ChainID(), defaultPartition),
+		lastOffsetPersisted:  lastOffsetPersisted,
+		pendingResubmissions: pendingResubmissions,
+		lastCutBlockNumber:   lastCutBlockNumber,
 
 		errorChan: errorChan,
 		haltChan:  make(chan struct{}),
@@ -65,6 +67,12 @@ type chainImpl struct {
 	lastOffsetPersisted int64
 	lastCutBlockNumber  uint64
 
+	// The config sequence of the latest re-submission of each re-submitted
+	// config message which has not made it into a block yet, by original
+	// offset. Copies of a message which is not pending, or which belong to an
+	// earlier re-submission, are discarded.
+	pendingResubmissions map[int64]uint64
+
 	producer        sarama.SyncProducer
 	parentConsumer  sarama.Consumer
 	channelConsumer sarama.PartitionConsumer
@@ -122,7 +130,11 @@ func (chain *chainImpl) Halt() {
 
 // Implements the consensus.Chain interface. Called by Broadcast().
 func (chain *chainImpl) Order(env *cb.Envelope, configSeq uint64) error {
-	if !chain.enqueue(env) {
+	marshaledEnv, err := utils.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("cannot enqueue, unable to marshal envelope = %s", err)
+	}
+	if !chain.enqueue(newNormalMessage(marshaledEnv, configSeq)) {
 		return fmt.Errorf("Could not enqueue")
 	}
 	return nil
@@ -130,11 +142,29 @@ func (chain *chainImpl) Order(env *cb.Envelope, configSeq uint64) error {
 
 // Implements the consensus.Chain interface. Called by Broadcast().
 func (chain *chainImpl) Configure(configUpdate *cb.Envelope, config *cb.Envelope, configSeq uint64) error {
-	return chain.Order(config, configSeq)
+	return chain.configure(configUpdate, config, configSeq, 0)
+}
+
+// configure posts a config message along with the config update it was
+// produced from. originalOffset is the offset at which the message was first
+// consumed if this is a re-submission, and zero otherwise.
+func (chain *chainImpl) configure(configUpdate *cb.Envelope, config *cb.Envelope, configSeq uint64, originalOffset int64) error {
+	marshaledConfig, err := utils.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("cannot enqueue, unable to marshal config = %s", err)
+	}
+	marshaledConfigUpdate, err := utils.Marshal(configUpdate)
+	if err != nil {
+		return fmt.Errorf("cannot enqueue, unable to marshal config update = %s", err)
+	}
+	if !chain.enqueue(newConfigMessage(marshaledConfig, marshaledConfigUpdate, configSeq, originalOffset)) {
+		return fmt.Errorf("Could not enqueue")
+	}
+	return nil
 }
 
 // enqueue accepts a message and returns true on acceptance, or false otheriwse.
-func (chain *chainImpl) enqueue(env *cb.Envelope) bool {
+func (chain *chainImpl) enqueue(kafkaMsg *ab.KafkaMessage) bool {
 	logger.Debugf("[channel: %s] Enqueueing envelope...", chain.support.ChainID())
 	select {
 	case <-chain.startChan: // The Start phase has completed
@@ -143,13 +173,12 @@ func (chain *chainImpl) enqueue(env *cb.Envelope) bool {
 			logger.Warningf("[channel: %s] Will not enqueue, consenter for this channel has been halted", chain.support.ChainID())
 			return false
 		default: // The post path
-			marshaledEnv, err := utils.Marshal(env)
+			payload, err := utils.Marshal(kafkaMsg)
 			if err != nil {
-				logger.Errorf("[channel: %s] cannot enqueue, unable to marshal envelope = %s", chain.support.ChainID(), err)
+				logger.Errorf("[channel: %s] cannot enqueue, unable to marshal message = %s", chain.support.ChainID(), err)
 				return false
 			}
 			// We're good to go
-			payload := utils.MarshalOrPanic(newRegularMessage(marshaledEnv))
 			message := newProducerMessage(chain.channel, payload)
 			if _, _, err := chain.producer.SendMessage(message); err != nil {
 				logger.Errorf("[channel: %s] cannot enqueue envelope = %s", chain.support.ChainID(), err)
@@ -267,7 +296,7 @@ func (chain *chainImpl) processMessagesToBlocks() ([]uint64, error) {
 				_ = processConnect(chain.support.ChainID())
 				counts[indexProcessConnectPass]++
 			case *ab.KafkaMessage_TimeToCut:
-				if err := processTimeToCut(msg.GetTimeToCut(), chain.support, &chain.lastCutBlockNumber, &timer, in.Offset); err != nil {
+				if err := chain.processTimeToCut(msg.GetTimeToCut(), &timer, in.Offset); err != nil {
 					logger.Warningf("[channel: %s] %s", chain.support.ChainID(), err)
 					logger.Criticalf("[channel: %s] Consenter for channel exiting", chain.support.ChainID())
 					counts[indexProcessTimeToCutError]++
@@ -275,7 +304,7 @@ func (chain *chainImpl) processMessagesToBlocks() ([]uint64, error) {
 				}
 				counts[indexProcessTimeToCutPass]++
 			case *ab.KafkaMessage_Regular:
-				if err := processRegular(msg.GetRegular(), chain.support, &timer, in.Offset, &chain.lastCutBlockNumber); err != nil {
+				if err := chain.processRegular(msg.GetRegular(), &timer, in.Offset, in.Timestamp); err != nil {
 					logger.Warningf("[channel: %s] Error when processing incoming message of type REGULAR = %s", chain.support.ChainID(), err)
 					counts[indexProcessRegularError]++
 				} else {
@@ -343,6 +372,21 @@ func getLastOffsetPersisted(metadataValue []byte, chainID string) int64 {
 	return sarama.OffsetOldest - 1 // default
 }
 
+func getPendingResubmissions(metadataValue []byte, chainID string) map[int64]uint64 {
+	pendingResubmissions := make(map[int64]uint64)
+	if metadataValue != nil {
+		kafkaMetadata := &ab.KafkaMetadata{}
+		if err := proto.Unmarshal(metadataValue, kafkaMetadata); err != nil {
+			logger.Panicf("[channel: %s] Ledger may be corrupted:"+
+				"cannot unmarshal orderer metadata in most recent block", chainID)
+		}
+		for _, resubmission := range kafkaMetadata.PendingResubmissions {
+			pendingResubmissions[resubmission.OriginalOffset] = resubmission.ConfigSeq
+		}
+	}
+	return pendingResubmissions
+}
+
 func newConnectMessage() *ab.KafkaMessage {
 	return &ab.KafkaMessage{
 		Type: &ab.KafkaMessage_Connect{
@@ -363,6 +407,32 @@ func newRegularMessage(payload []byte) *ab.KafkaMessage {
 	}
 }
 
+func newNormalMessage(payload []byte, configSeq uint64) *ab.KafkaMessage {
+	return &ab.KafkaMessage{
+		Type: &ab.KafkaMessage_Regular{
+			Regular: &ab.KafkaMessageRegular{
+				Payload:   payload,
+				ConfigSeq: configSeq,
+				Class:     ab.KafkaMessageRegular_NORMAL,
+			},
+		},
+	}
+}
+
+func newConfigMessage(config []byte, configUpdate []byte, configSeq uint64, originalOffset int64) *ab.KafkaMessage {
+	return &ab.KafkaMessage{
+		Type: &ab.KafkaMessage_Regular{
+			Regular: &ab.KafkaMessageRegular{
+				Payload:        config,
+				ConfigSeq:      configSeq,
+				Class:          ab.KafkaMessageRegular_CONFIG,
+				ConfigUpdate:   configUpdate,
+				OriginalOffset: originalOffset,
+			},
+		},
+	}
+}
+
 func newTimeToCutMessage(blockNumber uint64) *ab.KafkaMessage {
 	return &ab.KafkaMessage{
 		Type: &ab.KafkaMessage_TimeToCut{
@@ -386,104 +456,203 @@ func processConnect(channelName string) error {
 	return nil
 }
 
-func processRegular(regularMessage *ab.KafkaMessageRegular, support consensus.ConsenterSupport, timer *<-chan time.Time, receivedOffset int64, lastCutBlockNumber *uint64) error {
+func (chain *chainImpl) processRegular(regularMessage *ab.KafkaMessageRegular, timer *<-chan time.Time, receivedOffset int64, receivedTimestamp time.Time) error {
 	env := new(cb.Envelope)
 	if err := proto.Unmarshal(regularMessage.Payload, env); err != nil {
 		// This shouldn't happen, it should be filtered at ingress
 		return fmt.Errorf("unmarshal/%s", err)
 	}
 
+	seq := chain.support.Sequence()
+
+	switch regularMessage.Class {
+	case ab.KafkaMessageRegular_UNKNOWN:
+		// Posted by an orderer which does not tag its messages, the message
+		// has to be classified and validated again.
+		return chain.processLegacyRegular(env, timer, receivedOffset, receivedTimestamp)
+	case ab.KafkaMessageRegular_NORMAL:
+		if regularMessage.ConfigSeq < seq {
+			// The config has changed since the message was validated
+			if _, err := chain.support.ProcessNormalMsg(env); err != nil {
+				logger.Warningf("[channel: %s] Discarding bad normal message: %s", chain.support.ChainID(), err)
+				return nil
+			}
+		}
+		chain.orderNormal(env, timer, receivedOffset, receivedTimestamp)
+	case ab.KafkaMessageRegular_CONFIG:
+		originalOffset := regularMessage.OriginalOffset
+		if originalOffset != 0 {
+			// Every orderer of the channel posts a copy of each re-submission,
+			// only the first copy of the latest re-submission is processed
+			if configSeq, ok := chain.pendingResubmissions[originalOffset]; !ok || regularMessage.ConfigSeq != configSeq {
+				logger.Debugf("[channel: %s] Discarding config message re-submitted from offset %d, it has been processed or re-submitted again already",
+					chain.support.ChainID(), originalOffset)
+				return nil
+			}
+		}
+
+		if regularMessage.ConfigSeq < seq {
+			// The config message was produced against a stale config, so
+			// apply its config update to the current config and post the
+			// result again. Every orderer of the channel takes this decision
+			// at the same offset, and records the config sequence of the
+			// re-submission against the original offset of the message.
+			configUpdate := new(cb.Envelope)
+			if err := proto.Unmarshal(regularMessage.ConfigUpdate, configUpdate); err != nil {
+				return fmt.Errorf("unmarshal config update/%s", err)
+			}
+			config, configSeq, err := chain.support.ProcessConfigUpdateMsg(configUpdate)
+			if err != nil {
+				logger.Warningf("[channel: %s] Discarding bad config message: %s", chain.support.ChainID(), err)
+				delete(chain.pendingResubmissions, originalOffset)
+				return nil
+			}
+
+			if originalOffset == 0 {
+				originalOffset = receivedOffset
+			}
+			if chain.pendingResubmissions == nil {
+				chain.pendingResubmissions = make(map[int64]uint64)
+			}
+			chain.pendingResubmissions[originalOffset] = configSeq
+			logger.Infof("[channel: %s] Config message at offset %d was produced against config sequence %d (current is %d), re-submitting it",
+				chain.support.ChainID(), receivedOffset, regularMessage.ConfigSeq, seq)
+			if err := chain.configure(configUpdate, config, configSeq, originalOffset); err != nil {
+				return fmt.Errorf("cannot re-submit config message = %s", err)
+			}
+			return nil
+		}
+
+		chain.orderConfig(env, timer, receivedOffset, originalOffset)
+	default:
+		return fmt.Errorf("unsupported regular message class %s", regularMessage.Class)
+	}
+	return nil
+}
+
+// processLegacyRegular handles the regular messages which carry neither a
+// class nor a config sequence.
+func (chain *chainImpl) processLegacyRegular(env *cb.Envelope, timer *<-chan time.Time, receivedOffset int64, receivedTimestamp time.Time) error {
 	chdr, err := utils.ChannelHeader(env)
 	if err != nil {
 		logger.Panicf("If a message has arrived to this point, it should already have had its header inspected once")
 	}
 
-	class, err := support.ClassifyMsg(chdr)
+	class, err := chain.support.ClassifyMsg(chdr)
 	if err != nil {
-		logger.Panicf("[channel: %s] If a message has arrived to this point, it should already have been classified once", support.ChainID())
+		logger.Panicf("[channel: %s] If a message has arrived to this point, it should already have been classified once", chain.support.ChainID())
 	}
 	switch class {
 	case msgprocessor.ConfigUpdateMsg:
-		_, err := support.ProcessNormalMsg(env)
+		_, err := chain.support.ProcessNormalMsg(env)
 		if err != nil {
-			logger.Warningf("[channel: %s] Discarding bad config message: %s", support.ChainID(), err)
+			logger.Warningf("[channel: %s] Discarding bad config message: %s", chain.support.ChainID(), err)
 			break
 		}
-
-		batch := support.BlockCutter().Cut()
-		if batch != nil {
-			block := support.CreateNextBlock(batch)
-			encodedLastOffsetPersisted := utils.MarshalOrPanic(&ab.KafkaMetadata{LastOffsetPersisted: receivedOffset - 1})
-			support.WriteBlock(block, encodedLastOffsetPersisted)
-			*lastCutBlockNumber++
-		}
-		block := support.CreateNextBlock([]*cb.Envelope{env})
-		encodedLastOffsetPersisted := utils.MarshalOrPanic(&ab.KafkaMetadata{LastOffsetPersisted: receivedOffset})
-		support.WriteConfigBlock(block, encodedLastOffsetPersisted)
-		*lastCutBlockNumber++
-		*timer = nil
+		chain.orderConfig(env, timer, receivedOffset, 0)
 	case msgprocessor.NormalMsg:
-		_, err := support.ProcessNormalMsg(env)
+		_, err := chain.support.ProcessNormalMsg(env)
 		if err != nil {
 			logger.Warningf("Discarding bad normal message: %s", err)
 			break
 		}
+		chain.orderNormal(env, timer, receivedOffset, receivedTimestamp)
+	default:
+		logger.Panicf("[channel: %s] Unsupported message classification: %v", chain.support.ChainID(), class)
+	}
+	return nil
+}
 
-		batches, pending := support.BlockCutter().Ordered(env)
-		logger.Debugf("[channel: %s] Ordering results: items in batch = %d, pending = %v", support.ChainID(), len(batches), pending)
-		if len(batches) == 0 && *timer == nil {
-			*timer = time.After(support.SharedConfig().BatchTimeout())
-			logger.Debugf("[channel: %s] Just began %s batch timer", support.ChainID(), support.SharedConfig().BatchTimeout().String())
-			return nil
-		}
+// orderNormal hands a valid normal envelope to the block cutter and writes
+// the blocks it cuts. The envelope arrives at the timestamp of the Kafka
+// message, the same for every orderer, so that they all cut the same blocks
+// whatever the batch cutting strategy. The timestamp is zero with Kafka
+// versions older than 0.10, with which the blocks are cut on the batch size.
+func (chain *chainImpl) orderNormal(env *cb.Envelope, timer *<-chan time.Time, receivedOffset int64, receivedTimestamp time.Time) {
+	batches, pending := chain.support.BlockCutter().OrderedAt(env, receivedTimestamp)
+	logger.Debugf("[channel: %s] Ordering results: items in batch = %d, pending = %v", chain.support.ChainID(), len(batches), pending)
+	if len(batches) == 0 && *timer == nil {
+		batchTimeout := chain.support.BlockCutter().BatchTimeout()
+		*timer = time.After(batchTimeout)
+		logger.Debugf("[channel: %s] Just began %s batch timer", chain.support.ChainID(), batchTimeout.String())
+		return
+	}
 
-		offset := receivedOffset
-		if pending || len(batches) == 2 {
-			// If the newest envelope is not encapsulated into the first batch,
-			// the `LastOffsetPersisted` should be `receivedOffset` - 1.
-			offset--
-		}
+	offset := receivedOffset
+	if pending || len(batches) == 2 {
+		// If the newest envelope is not encapsulated into the first batch,
+		// the `LastOffsetPersisted` should be `receivedOffset` - 1.
+		offset--
+	}
 
-		for _, batch := range batches {
-			block := support.CreateNextBlock(batch)
-			encodedLastOffsetPersisted := utils.MarshalOrPanic(&ab.KafkaMetadata{LastOffsetPersisted: offset})
-			support.WriteBlock(block, encodedLastOffsetPersisted)
-			*lastCutBlockNumber++
-			logger.Debugf("[channel: %s] Batch filled, just cut block %d - last persisted offset is now %d", support.ChainID(), *lastCutBlockNumber, offset)
-			offset++
-		}
+	for _, batch := range batches {
+		block := chain.support.CreateNextBlock(batch)
+		chain.support.WriteBlock(block, chain.encodedMetadata(offset))
+		chain.lastCutBlockNumber++
+		logger.Debugf("[channel: %s] Batch filled, just cut block %d - last persisted offset is now %d", chain.support.ChainID(), chain.lastCutBlockNumber, offset)
+		offset++
+	}
 
-		if len(batches) > 0 {
-			*timer = nil
-		}
-	default:
-		logger.Panicf("[channel: %s] Unsupported message classification: %v", support.ChainID(), class)
+	if len(batches) > 0 {
+		*timer = nil
 	}
-	return nil
 }
 
-func processTimeToCut(ttcMessage *ab.KafkaMessageTimeToCut, support consensus.ConsenterSupport, lastCutBlockNumber *uint64, timer *<-chan time.Time, receivedOffset int64) error {
+// orderConfig cuts the pending batch and writes the valid config envelope in
+// a block of its own. originalOffset is the offset of the message first
+// submitted if the envelope was re-submitted, and zero otherwise.
+func (chain *chainImpl) orderConfig(env *cb.Envelope, timer *<-chan time.Time, receivedOffset int64, originalOffset int64) {
+	batch := chain.support.BlockCutter().Cut()
+	if batch != nil {
+		block := chain.support.CreateNextBlock(batch)
+		chain.support.WriteBlock(block, chain.encodedMetadata(receivedOffset-1))
+		chain.lastCutBlockNumber++
+	}
+	// The re-submission is pending until the config block is written, so that
+	// it is consumed again if the orderer restarts after the previous block
+	delete(chain.pendingResubmissions, originalOffset)
+	block := chain.support.CreateNextBlock([]*cb.Envelope{env})
+	chain.support.WriteConfigBlock(block, chain.encodedMetadata(receivedOffset))
+	chain.lastCutBlockNumber++
+	*timer = nil
+}
+
+// encodedMetadata returns the orderer metadata of a block whose last envelope
+// was consumed at the given offset.
+func (chain *chainImpl) encodedMetadata(lastOffsetPersisted int64) []byte {
+	kafkaMetadata := &ab.KafkaMetadata{LastOffsetPersisted: lastOffsetPersisted}
+	for originalOffset, configSeq := range chain.pendingResubmissions {
+		kafkaMetadata.PendingResubmissions = append(kafkaMetadata.PendingResubmissions,
+			&ab.KafkaResubmission{OriginalOffset: originalOffset, ConfigSeq: configSeq})
+	}
+	// Every orderer writes the same metadata
+	sort.Slice(kafkaMetadata.PendingResubmissions, func(i, j int) bool {
+		return kafkaMetadata.PendingResubmissions[i].OriginalOffset < kafkaMetadata.PendingResubmissions[j].OriginalOffset
+	})
+	return utils.MarshalOrPanic(kafkaMetadata)
+}
+
+func (chain *chainImpl) processTimeToCut(ttcMessage *ab.KafkaMessageTimeToCut, timer *<-chan time.Time, receivedOffset int64) error {
 	ttcNumber := ttcMessage.GetBlockNumber()
-	logger.Debugf("[channel: %s] It's a time-to-cut message for block %d", support.ChainID(), ttcNumber)
-	if ttcNumber == *lastCutBlockNumber+1 {
+	logger.Debugf("[channel: %s] It's a time-to-cut message for block %d", chain.support.ChainID(), ttcNumber)
+	if ttcNumber == chain.lastCutBlockNumber+1 {
 		*timer = nil
-		logger.Debugf("[channel: %s] Nil'd the timer", support.ChainID())
-		batch := support.BlockCutter().Cut()
+		logger.Debugf("[channel: %s] Nil'd the timer", chain.support.ChainID())
+		batch := chain.support.BlockCutter().Cut()
 		if len(batch) == 0 {
 			return fmt.Errorf("got right time-to-cut message (for block %d),"+
-				" no pending requests though; this might indicate a bug", *lastCutBlockNumber+1)
+				" no pending requests though; this might indicate a bug", chain.lastCutBlockNumber+1)
 		}
-		block := support.CreateNextBlock(batch)
-		encodedLastOffsetPersisted := utils.MarshalOrPanic(&ab.KafkaMetadata{LastOffsetPersisted: receivedOffset})
-		support.WriteBlock(block, encodedLastOffsetPersisted)
-		*lastCutBlockNumber++
-		logger.Debugf("[channel: %s] Proper time-to-cut received, just cut block %d", support.ChainID(), *lastCutBlockNumber)
+		block := chain.support.CreateNextBlock(batch)
+		chain.support.WriteBlock(block, chain.encodedMetadata(receivedOffset))
+		chain.lastCutBlockNumber++
+		logger.Debugf("[channel: %s] Proper time-to-cut received, just cut block %d", chain.support.ChainID(), chain.lastCutBlockNumber)
 		return nil
-	} else if ttcNumber > *lastCutBlockNumber+1 {
+	} else if ttcNumber > chain.lastCutBlockNumber+1 {
 		return fmt.Errorf("got larger time-to-cut message (%d) than allowed/expected (%d)"+
-			" - this might indicate a bug", ttcNumber, *lastCutBlockNumber+1)
+			" - this might indicate a bug", ttcNumber, chain.lastCutBlockNumber+1)
 	}
-	logger.Debugf("[channel: %s] Ignoring stale time-to-cut-message for block %d", support.ChainID(), ttcNumber)
+	logger.Debugf("[channel: %s] Ignoring stale time-to-cut-message for block %d", chain.support.ChainID(), ttcNumber)
 	return nil
 }
 
@@ -13,6 +13,7 @@ import (
 
 	"github.com/Shopify/sarama"
 	"github.com/Shopify/sarama/mocks"
+	"github.com/golang/protobuf/proto"
 	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
 	"github.com/hyperledger/fabric/orderer/common/msgprocessor"
 	mockblockcutter "github.com/hyperledger/fabric/orderer/mocks/common/blockcutter"
@@ -64,7 +65,7 @@ func TestChain(t *testing.T) {
 	t.Run("New", func(t *testing.T) {
 		_, mockBroker, mockSupport := newMocks(t)
 		defer func() { mockBroker.Close() }()
-		chain, err := newChain(mockConsenter, mockSupport, newestOffset-1)
+		chain, err := newChain(mockConsenter, mockSupport, newestOffset-1, nil)
 
 		assert.NoError(t, err, "Expected newChain to return without errors")
 		select {
@@ -93,7 +94,7 @@ func TestChain(t *testing.T) {
 		_, mockBroker, mockSupport := newMocks(t)
 		defer func() { mockBroker.Close() }()
 		// Set to -1 because we haven't sent the CONNECT message yet
-		chain, _ := newChain(mockConsenter, mockSupport, newestOffset-1)
+		chain, _ := newChain(mockConsenter, mockSupport, newestOffset-1, nil)
 
 		chain.Start()
 		select {
@@ -110,7 +111,7 @@ func TestChain(t *testing.T) {
 	t.Run("Halt", func(t *testing.T) {
 		_, mockBroker, mockSupport := newMocks(t)
 		defer func() { mockBroker.Close() }()
-		chain, _ := newChain(mockConsenter, mockSupport, newestOffset-1)
+		chain, _ := newChain(mockConsenter, mockSupport, newestOffset-1, nil)
 
 		chain.Start()
 		select {
@@ -141,7 +142,7 @@ func TestChain(t *testing.T) {
 	t.Run("DoubleHalt", func(t *testing.T) {
 		_, mockBroker, mockSupport := newMocks(t)
 		defer func() { mockBroker.Close() }()
-		chain, _ := newChain(mockConsenter, mockSupport, newestOffset-1)
+		chain, _ := newChain(mockConsenter, mockSupport, newestOffset-1, nil)
 
 		chain.Start()
 		select {
@@ -163,7 +164,7 @@ func TestChain(t *testing.T) {
 		mockSupportCopy := *mockSupport
 		mockSupportCopy.SharedConfigVal = &mockconfig.Orderer{KafkaBrokersVal: []string{}}
 
-		chain, _ := newChain(mockConsenter, &mockSupportCopy, newestOffset-1)
+		chain, _ := newChain(mockConsenter, &mockSupportCopy, newestOffset-1, nil)
 
 		// The production path will actually call chain.Start(). This is
 		// functionally equivalent and allows us to run assertions on it.
@@ -177,7 +178,7 @@ func TestChain(t *testing.T) {
 		// - Metadata.Retry.Max
 		mockChannel, mockBroker, mockSupport := newMocks(t)
 		defer func() { mockBroker.Close() }()
-		chain, _ := newChain(mockConsenter, mockSupport, newestOffset-1)
+		chain, _ := newChain(mockConsenter, mockSupport, newestOffset-1, nil)
 
 		// Have the broker return an ErrNotLeaderForPartition error
 		mockBroker.SetHandlerByMap(map[string]sarama.MockResponse{
@@ -199,7 +200,7 @@ func TestChain(t *testing.T) {
 	t.Run("enqueueIfNotStarted", func(t *testing.T) {
 		mockChannel, mockBroker, mockSupport := newMocks(t)
 		defer func() { mockBroker.Close() }()
-		chain, _ := newChain(mockConsenter, mockSupport, newestOffset-1)
+		chain, _ := newChain(mockConsenter, mockSupport, newestOffset-1, nil)
 
 		// As in StartWithConnectMessageError, have the broker return an
 		// ErrNotLeaderForPartition error, i.e. cause an error in the
@@ -217,7 +218,7 @@ func TestChain(t *testing.T) {
 				SetMessage(mockChannel.topic(), mockChannel.partition(), newestOffset, message),
 		})
 
-		assert.False(t, chain.enqueue(newMockEnvelope("fooMessage")), "Expected enqueue call to return false")
+		assert.False(t, chain.enqueue(newNormalMessage(utils.MarshalOrPanic(newMockEnvelope("fooMessage")), uint64(0))), "Expected enqueue call to return false")
 	})
 
 	t.Run("StartWithConsumerForChannelError", func(t *testing.T) {
@@ -230,7 +231,7 @@ func TestChain(t *testing.T) {
 		defer func() { mockBroker.Close() }()
 
 		// Provide an out-of-range offset
-		chain, _ := newChain(mockConsenter, mockSupport, newestOffset)
+		chain, _ := newChain(mockConsenter, mockSupport, newestOffset, nil)
 
 		mockBroker.SetHandlerByMap(map[string]sarama.MockResponse{
 			"MetadataRequest": sarama.NewMockMetadataResponse(t).
@@ -251,7 +252,7 @@ func TestChain(t *testing.T) {
 	t.Run("enqueueProper", func(t *testing.T) {
 		mockChannel, mockBroker, mockSupport := newMocks(t)
 		defer func() { mockBroker.Close() }()
-		chain, _ := newChain(mockConsenter, mockSupport, newestOffset-1)
+		chain, _ := newChain(mockConsenter, mockSupport, newestOffset-1, nil)
 
 		mockBroker.SetHandlerByMap(map[string]sarama.MockResponse{
 			"MetadataRequest": sarama.NewMockMetadataResponse(t).
@@ -276,7 +277,7 @@ func TestChain(t *testing.T) {
 
 		// enqueue should have access to the post path, and its ProduceRequest
 		// should go by without error
-		assert.True(t, chain.enqueue(newMockEnvelope("fooMessage")), "Expected enqueue call to return true")
+		assert.True(t, chain.enqueue(newNormalMessage(utils.MarshalOrPanic(newMockEnvelope("fooMessage")), uint64(0))), "Expected enqueue call to return true")
 
 		chain.Halt()
 	})
@@ -284,7 +285,7 @@ func TestChain(t *testing.T) {
 	t.Run("enqueueIfHalted", func(t *testing.T) {
 		mockChannel, mockBroker, mockSupport := newMocks(t)
 		defer func() { mockBroker.Close() }()
-		chain, _ := newChain(mockConsenter, mockSupport, newestOffset-1)
+		chain, _ := newChain(mockConsenter, mockSupport, newestOffset-1, nil)
 
 		mockBroker.SetHandlerByMap(map[string]sarama.MockResponse{
 			"MetadataRequest": sarama.NewMockMetadataResponse(t).
@@ -309,13 +310,13 @@ func TestChain(t *testing.T) {
 		chain.Halt()
 
 		// haltChan should close access to the post path
-		assert.False(t, chain.enqueue(newMockEnvelope("fooMessage")), "Expected enqueue call to return false")
+		assert.False(t, chain.enqueue(newNormalMessage(utils.MarshalOrPanic(newMockEnvelope("fooMessage")), uint64(0))), "Expected enqueue call to return false")
 	})
 
 	t.Run("enqueueError", func(t *testing.T) {
 		mockChannel, mockBroker, mockSupport := newMocks(t)
 		defer func() { mockBroker.Close() }()
-		chain, _ := newChain(mockConsenter, mockSupport, newestOffset-1)
+		chain, _ := newChain(mockConsenter, mockSupport, newestOffset-1, nil)
 
 		// Use the "good" handler map that allows the Stage to complete without
 		// issues
@@ -347,7 +348,7 @@ func TestChain(t *testing.T) {
 				SetError(mockChannel.topic(), mockChannel.partition(), sarama.ErrNotLeaderForPartition),
 		})
 
-		assert.False(t, chain.enqueue(newMockEnvelope("fooMessage")), "Expected enqueue call to return false")
+		assert.False(t, chain.enqueue(newNormalMessage(utils.MarshalOrPanic(newMockEnvelope("fooMessage")), uint64(0))), "Expected enqueue call to return false")
 	})
 }
 
@@ -576,6 +577,40 @@ func TestGetLastOffsetPersisted(t *testing.T) {
 	}
 }
 
+func TestGetPendingResubmissions(t *testing.T) {
+	mockChannel := newChannel(channelNameForTest(t), defaultPartition)
+	mockMetadata := &cb.Metadata{Value: utils.MarshalOrPanic(&ab.KafkaMetadata{
+		LastOffsetPersisted: int64(5),
+		PendingResubmissions: []*ab.KafkaResubmission{
+			{OriginalOffset: int64(3), ConfigSeq: uint64(1)},
+			{OriginalOffset: int64(4), ConfigSeq: uint64(2)},
+		},
+	})}
+
+	testCases := []struct {
+		name     string
+		md       []byte
+		expected map[int64]uint64
+		panics   bool
+	}{
+		{"Proper", mockMetadata.Value, map[int64]uint64{3: 1, 4: 2}, false},
+		{"Empty", nil, map[int64]uint64{}, false},
+		{"Panics", tamperBytes(mockMetadata.Value), nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if !tc.panics {
+				assert.Equal(t, tc.expected, getPendingResubmissions(tc.md, mockChannel.String()))
+			} else {
+				assert.Panics(t, func() {
+					getPendingResubmissions(tc.md, mockChannel.String())
+				}, "Expected getPendingResubmissions call to panic")
+			}
+		})
+	}
+}
+
 func TestSendConnectMessage(t *testing.T) {
 	mockBroker := sarama.NewMockBroker(t, 0)
 	defer func() { mockBroker.Close() }()
@@ -780,6 +815,7 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 			},
 		}
 		defer close(mockSupport.BlockCutterVal.Block)
+		mockSupport.BlockCutterVal.BatchTimeoutVal = longTimeout
 
 		bareMinimumChain := &chainImpl{
 			parentConsumer:  mockParentConsumer,
@@ -835,6 +871,7 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 			},
 		}
 		defer close(mockSupport.BlockCutterVal.Block)
+		mockSupport.BlockCutterVal.BatchTimeoutVal = longTimeout
 
 		bareMinimumChain := &chainImpl{
 			parentConsumer:  mockParentConsumer,
@@ -859,7 +896,9 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 		mockSupport.BlockCutterVal.CutNext = true
 
 		// This is the wrappedMessage that the for-loop will process
-		mpc.YieldMessage(newMockConsumerMessage(newRegularMessage(utils.MarshalOrPanic(newMockEnvelope("fooMessage")))))
+		consumerMessage := newMockConsumerMessage(newRegularMessage(utils.MarshalOrPanic(newMockEnvelope("fooMessage"))))
+		consumerMessage.Timestamp = time.Unix(1000, 0)
+		mpc.YieldMessage(consumerMessage)
 
 		mockSupport.BlockCutterVal.Block <- struct{}{} // Let the `mockblockcutter.Ordered` call return
 		logger.Debugf("Mock blockcutter's Ordered call has returned")
@@ -874,6 +913,7 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 		assert.Equal(t, uint64(1), counts[indexRecvPass], "Expected 1 message received and unmarshaled")
 		assert.Equal(t, uint64(1), counts[indexProcessRegularPass], "Expected 1 REGULAR message processed")
 		assert.Equal(t, lastCutBlockNumber+1, bareMinimumChain.lastCutBlockNumber, "Expected lastCutBlockNumber to be bumped up by one")
+		assert.Equal(t, consumerMessage.Timestamp, mockSupport.BlockCutterVal.Arrival, "Expected the envelope to arrive at the timestamp of the Kafka message")
 	})
 
 	t.Run("ReceiveTwoRegularAndCutTwoBlocks", func(t *testing.T) {
@@ -897,6 +937,7 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 			},
 		}
 		defer close(mockSupport.BlockCutterVal.Block)
+		mockSupport.BlockCutterVal.BatchTimeoutVal = longTimeout
 
 		bareMinimumChain := &chainImpl{
 			parentConsumer:  mockParentConsumer,
@@ -980,6 +1021,7 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 			},
 		}
 		defer close(mockSupport.BlockCutterVal.Block)
+		mockSupport.BlockCutterVal.BatchTimeoutVal = longTimeout
 
 		bareMinimumChain := &chainImpl{
 			parentConsumer:  mockParentConsumer,
@@ -1069,6 +1111,7 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 			},
 		}
 		defer close(mockSupport.BlockCutterVal.Block)
+		mockSupport.BlockCutterVal.BatchTimeoutVal = extraShortTimeout
 
 		bareMinimumChain := &chainImpl{
 			producer:        producer,
@@ -1144,6 +1187,7 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 			},
 		}
 		defer close(mockSupport.BlockCutterVal.Block)
+		mockSupport.BlockCutterVal.BatchTimeoutVal = extraShortTimeout
 
 		bareMinimumChain := &chainImpl{
 			producer:        producer,
@@ -1498,11 +1542,10 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 			haltChan:  haltChan,
 		}
 
-		var counts []uint64
 		done := make(chan struct{})
 
 		go func() {
-			counts, err = bareMinimumChain.processMessagesToBlocks()
+			_, err = bareMinimumChain.processMessagesToBlocks()
 			done <- struct{}{}
 		}()
 
@@ -1562,6 +1605,7 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 			},
 		}
 		defer close(mockSupport.BlockCutterVal.Block)
+		mockSupport.BlockCutterVal.BatchTimeoutVal = longTimeout
 
 		bareMinimumChain := &chainImpl{
 			parentConsumer:  mockParentConsumer,
@@ -1615,6 +1659,7 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 			},
 		}
 		defer close(mockSupport.BlockCutterVal.Block)
+		mockSupport.BlockCutterVal.BatchTimeoutVal = longTimeout
 
 		bareMinimumChain := &chainImpl{
 			parentConsumer:  mockParentConsumer,
@@ -1673,4 +1718,486 @@ func TestProcessMessagesToBlocks(t *testing.T) {
 		assert.Equal(t, normalBlkOffset, extractEncodedOffset(normalBlk.GetMetadata().Metadata[cb.BlockMetadataIndex_ORDERER]), "Expected encoded offset in first block to be %d", normalBlkOffset)
 		assert.Equal(t, configBlkOffset, extractEncodedOffset(configBlk.GetMetadata().Metadata[cb.BlockMetadataIndex_ORDERER]), "Expected encoded offset in second block to be %d", configBlkOffset)
 	})
+
+	t.Run("ReceiveStaleNormalAndDiscard", func(t *testing.T) {
+		errorChan := make(chan struct{})
+		close(errorChan)
+		haltChan := make(chan struct{})
+
+		lastCutBlockNumber := uint64(3)
+
+		mockSupport := &mockmultichannel.ConsenterSupport{
+			Blocks:              make(chan *cb.Block), // WriteBlock will post here
+			BlockCutterVal:      mockblockcutter.NewReceiver(),
+			ChainIDVal:          mockChannel.topic(),
+			HeightVal:           lastCutBlockNumber, // Incremented during the WriteBlock call
+			SequenceVal:         uint64(1),
+			ProcessNormalMsgErr: fmt.Errorf("Envelope is no longer valid"),
+			SharedConfigVal: &mockconfig.Orderer{
+				BatchTimeoutVal: longTimeout,
+			},
+		}
+		defer close(mockSupport.BlockCutterVal.Block)
+		mockSupport.BlockCutterVal.BatchTimeoutVal = longTimeout
+
+		bareMinimumChain := &chainImpl{
+			parentConsumer:  mockParentConsumer,
+			channelConsumer: mockChannelConsumer,
+
+			channel:            mockChannel,
+			support:            mockSupport,
+			lastCutBlockNumber: lastCutBlockNumber,
+
+			errorChan: errorChan,
+			haltChan:  haltChan,
+		}
+
+		var counts []uint64
+		done := make(chan struct{})
+
+		go func() {
+			counts, err = bareMinimumChain.processMessagesToBlocks()
+			done <- struct{}{}
+		}()
+
+		// This normal message was validated against config sequence 0
+		mpc.YieldMessage(newMockConsumerMessage(newNormalMessage(utils.MarshalOrPanic(newMockEnvelope("fooMessage")), uint64(0))))
+
+		logger.Debug("Closing haltChan to exit the infinite for-loop")
+		// We are guaranteed to hit the haltChan branch after hitting the REGULAR branch at least once
+		close(haltChan) // Identical to chain.Halt()
+		logger.Debug("haltChan closed")
+		<-done
+
+		assert.NoError(t, err, "Expected the processMessagesToBlocks call to return without errors")
+		assert.Equal(t, uint64(1), counts[indexProcessRegularPass], "Expected 1 REGULAR message processed")
+		assert.Empty(t, mockSupport.BlockCutterVal.CurBatch, "Expected the stale message not to reach the blockcutter")
+		assert.Equal(t, lastCutBlockNumber, bareMinimumChain.lastCutBlockNumber, "Expected lastCutBlockNumber not to be incremented")
+	})
+
+	t.Run("ReceiveStaleNormalNotRateLimited", func(t *testing.T) {
+		errorChan := make(chan struct{})
+		close(errorChan)
+		haltChan := make(chan struct{})
+
+		lastCutBlockNumber := uint64(3)
+
+		mockSupport := &mockmultichannel.ConsenterSupport{
+			Blocks:         make(chan *cb.Block), // WriteBlock will post here
+			BlockCutterVal: mockblockcutter.NewReceiver(),
+			ChainIDVal:     mockChannel.topic(),
+			HeightVal:      lastCutBlockNumber, // Incremented during the WriteBlock call
+			SequenceVal:    uint64(1),
+			// The creator of the message has exhausted its rate limit on this orderer
+			ChargeRateLimitErr: msgprocessor.ErrRateLimited,
+			SharedConfigVal: &mockconfig.Orderer{
+				BatchTimeoutVal: longTimeout,
+			},
+		}
+		defer close(mockSupport.BlockCutterVal.Block)
+		mockSupport.BlockCutterVal.BatchTimeoutVal = longTimeout
+
+		bareMinimumChain := &chainImpl{
+			parentConsumer:  mockParentConsumer,
+			channelConsumer: mockChannelConsumer,
+
+			channel:            mockChannel,
+			support:            mockSupport,
+			lastCutBlockNumber: lastCutBlockNumber,
+
+			errorChan: errorChan,
+			haltChan:  haltChan,
+		}
+
+		var counts []uint64
+		done := make(chan struct{})
+
+		go func() {
+			counts, err = bareMinimumChain.processMessagesToBlocks()
+			done <- struct{}{}
+		}()
+
+		// This normal message was validated against config sequence 0, and
+		// is revalidated without being charged to its creator again
+		mpc.YieldMessage(newMockConsumerMessage(newNormalMessage(utils.MarshalOrPanic(newMockEnvelope("fooMessage")), uint64(0))))
+
+		mockSupport.BlockCutterVal.Block <- struct{}{} // Let the `mockblockcutter.Ordered` call return
+
+		close(haltChan) // Identical to chain.Halt()
+		<-done
+
+		assert.NoError(t, err, "Expected the processMessagesToBlocks call to return without errors")
+		assert.Equal(t, uint64(1), counts[indexProcessRegularPass], "Expected 1 REGULAR message processed")
+		assert.Len(t, mockSupport.BlockCutterVal.CurBatch, 1, "Expected the stale message to reach the blockcutter")
+	})
+
+	t.Run("ReceiveStaleConfigAndResubmit", func(t *testing.T) {
+		errorChan := make(chan struct{})
+		close(errorChan)
+		haltChan := make(chan struct{})
+		startChan := make(chan struct{})
+		close(startChan)
+
+		lastCutBlockNumber := uint64(3)
+
+		mockSupport := &mockmultichannel.ConsenterSupport{
+			Blocks:                    make(chan *cb.Block), // WriteBlock will post here
+			BlockCutterVal:            mockblockcutter.NewReceiver(),
+			ChainIDVal:                mockChannel.topic(),
+			HeightVal:                 lastCutBlockNumber, // Incremented during the WriteBlock call
+			SequenceVal:               uint64(1),
+			ConfigSeqVal:              uint64(1),
+			ProcessConfigUpdateMsgVal: newMockEnvelope("newConfig"),
+			SharedConfigVal: &mockconfig.Orderer{
+				BatchTimeoutVal: longTimeout,
+			},
+		}
+		defer close(mockSupport.BlockCutterVal.Block)
+		mockSupport.BlockCutterVal.BatchTimeoutVal = longTimeout
+
+		resubmitted := make(chan *ab.KafkaMessageRegular, 1)
+		mockProducer := mocks.NewSyncProducer(t, mockBrokerConfig)
+		mockProducer.ExpectSendMessageWithCheckerFunctionAndSucceed(func(val []byte) error {
+			msg := new(ab.KafkaMessage)
+			if err := proto.Unmarshal(val, msg); err != nil {
+				return err
+			}
+			resubmitted <- msg.GetRegular()
+			return nil
+		})
+
+		bareMinimumChain := &chainImpl{
+			producer:        mockProducer,
+			parentConsumer:  mockParentConsumer,
+			channelConsumer: mockChannelConsumer,
+
+			channel:            mockChannel,
+			support:            mockSupport,
+			lastCutBlockNumber: lastCutBlockNumber,
+
+			errorChan: errorChan,
+			haltChan:  haltChan,
+			startChan: startChan,
+		}
+
+		var counts []uint64
+		done := make(chan struct{})
+
+		go func() {
+			counts, err = bareMinimumChain.processMessagesToBlocks()
+			done <- struct{}{}
+		}()
+
+		// This config message was produced against config sequence 0
+		configOffset := mpc.HighWaterMarkOffset()
+		mpc.YieldMessage(newMockConsumerMessage(newConfigMessage(
+			utils.MarshalOrPanic(newMockEnvelope("oldConfig")),
+			utils.MarshalOrPanic(newMockEnvelope("configUpdate")),
+			uint64(0), 0)))
+
+		var regular *ab.KafkaMessageRegular
+		select {
+		case regular = <-resubmitted:
+		case <-time.After(shortTimeout):
+			t.Fatal("Expected the config message to be re-submitted")
+		}
+
+		logger.Debug("Closing haltChan to exit the infinite for-loop")
+		close(haltChan) // Identical to chain.Halt()
+		logger.Debug("haltChan closed")
+		<-done
+
+		assert.NoError(t, err, "Expected the processMessagesToBlocks call to return without errors")
+		assert.Equal(t, uint64(1), counts[indexProcessRegularPass], "Expected 1 REGULAR message processed")
+		assert.Equal(t, lastCutBlockNumber, bareMinimumChain.lastCutBlockNumber, "Expected no block to be cut for the stale config message")
+		assert.Equal(t, ab.KafkaMessageRegular_CONFIG, regular.Class)
+		assert.Equal(t, uint64(1), regular.ConfigSeq, "Expected the re-submitted message to carry the current config sequence")
+		assert.Equal(t, configOffset, regular.OriginalOffset, "Expected the re-submitted message to carry the offset of the original one")
+		assert.Equal(t, map[int64]uint64{configOffset: 1}, bareMinimumChain.pendingResubmissions, "Expected the re-submission to be pending")
+		assert.Equal(t, utils.MarshalOrPanic(newMockEnvelope("newConfig")), regular.Payload)
+		assert.Equal(t, utils.MarshalOrPanic(newMockEnvelope("configUpdate")), regular.ConfigUpdate)
+	})
+
+	t.Run("ReceiveResubmittedConfigTwice", func(t *testing.T) {
+		errorChan := make(chan struct{})
+		close(errorChan)
+		haltChan := make(chan struct{})
+
+		lastCutBlockNumber := uint64(3)
+
+		mockSupport := &mockmultichannel.ConsenterSupport{
+			Blocks:         make(chan *cb.Block), // WriteBlock will post here
+			BlockCutterVal: mockblockcutter.NewReceiver(),
+			ChainIDVal:     mockChannel.topic(),
+			HeightVal:      lastCutBlockNumber, // Incremented during the WriteBlock call
+			SequenceVal:    uint64(1),
+			SharedConfigVal: &mockconfig.Orderer{
+				BatchTimeoutVal: longTimeout,
+			},
+		}
+		defer close(mockSupport.BlockCutterVal.Block)
+		mockSupport.BlockCutterVal.BatchTimeoutVal = longTimeout
+
+		bareMinimumChain := &chainImpl{
+			parentConsumer:  mockParentConsumer,
+			channelConsumer: mockChannelConsumer,
+
+			channel:              mockChannel,
+			support:              mockSupport,
+			lastCutBlockNumber:   lastCutBlockNumber,
+			pendingResubmissions: map[int64]uint64{5: 1},
+
+			errorChan: errorChan,
+			haltChan:  haltChan,
+		}
+
+		var counts []uint64
+		done := make(chan struct{})
+
+		go func() {
+			counts, err = bareMinimumChain.processMessagesToBlocks()
+			done <- struct{}{}
+		}()
+
+		// Every orderer of the channel re-submits the stale config message
+		// found at offset 5, only the first re-submission makes it into a block
+		resubmission := newConfigMessage(
+			utils.MarshalOrPanic(newMockEnvelope("newConfig")),
+			utils.MarshalOrPanic(newMockEnvelope("configUpdate")),
+			uint64(1), int64(5))
+		mpc.YieldMessage(newMockConsumerMessage(resubmission))
+
+		var configBlk *cb.Block
+		select {
+		case configBlk = <-mockSupport.Blocks:
+		case <-time.After(shortTimeout):
+			t.Fatal("Expected a config block to be written")
+		}
+
+		mpc.YieldMessage(newMockConsumerMessage(resubmission))
+
+		logger.Debug("Closing haltChan to exit the infinite for-loop")
+		// We are guaranteed to hit the haltChan branch after hitting the REGULAR branch at least once
+		close(haltChan) // Identical to chain.Halt()
+		logger.Debug("haltChan closed")
+		<-done
+
+		assert.NoError(t, err, "Expected the processMessagesToBlocks call to return without errors")
+		assert.Equal(t, uint64(2), counts[indexProcessRegularPass], "Expected 2 REGULAR messages processed")
+		assert.Equal(t, lastCutBlockNumber+1, bareMinimumChain.lastCutBlockNumber, "Expected lastCutBlockNumber to be incremented by 1")
+
+		omd := &cb.Metadata{}
+		assert.NoError(t, proto.Unmarshal(configBlk.Metadata.Metadata[cb.BlockMetadataIndex_ORDERER], omd))
+		assert.Empty(t, getPendingResubmissions(omd.Value, mockChannel.String()), "Expected the re-submission not to be pending after the config block")
+	})
+
+	t.Run("ReceiveInterleavedResubmittedConfigs", func(t *testing.T) {
+		errorChan := make(chan struct{})
+		close(errorChan)
+		haltChan := make(chan struct{})
+		startChan := make(chan struct{})
+		close(startChan)
+
+		lastCutBlockNumber := uint64(3)
+
+		mockSupport := &mockmultichannel.ConsenterSupport{
+			Blocks:                    make(chan *cb.Block), // WriteBlock will post here
+			BlockCutterVal:            mockblockcutter.NewReceiver(),
+			ChainIDVal:                mockChannel.topic(),
+			HeightVal:                 lastCutBlockNumber, // Incremented during the WriteBlock call
+			SequenceVal:               uint64(1),
+			ConfigSeqVal:              uint64(1),
+			ProcessConfigUpdateMsgVal: newMockEnvelope("newConfig"),
+			SharedConfigVal: &mockconfig.Orderer{
+				BatchTimeoutVal: longTimeout,
+			},
+		}
+		defer close(mockSupport.BlockCutterVal.Block)
+		mockSupport.BlockCutterVal.BatchTimeoutVal = longTimeout
+
+		resubmitted := make(chan *ab.KafkaMessage, 2)
+		mockProducer := mocks.NewSyncProducer(t, mockBrokerConfig)
+		for i := 0; i < 2; i++ {
+			mockProducer.ExpectSendMessageWithCheckerFunctionAndSucceed(func(val []byte) error {
+				msg := new(ab.KafkaMessage)
+				if err := proto.Unmarshal(val, msg); err != nil {
+					return err
+				}
+				resubmitted <- msg
+				return nil
+			})
+		}
+
+		bareMinimumChain := &chainImpl{
+			producer:        mockProducer,
+			parentConsumer:  mockParentConsumer,
+			channelConsumer: mockChannelConsumer,
+
+			channel:            mockChannel,
+			support:            mockSupport,
+			lastCutBlockNumber: lastCutBlockNumber,
+
+			errorChan: errorChan,
+			haltChan:  haltChan,
+			startChan: startChan,
+		}
+
+		var counts []uint64
+		done := make(chan struct{})
+
+		go func() {
+			counts, err = bareMinimumChain.processMessagesToBlocks()
+			done <- struct{}{}
+		}()
+
+		expectResubmission := func() *ab.KafkaMessage {
+			select {
+			case msg := <-resubmitted:
+				return msg
+			case <-time.After(shortTimeout):
+				t.Fatal("Expected the config message to be re-submitted")
+				return nil
+			}
+		}
+		expectConfigBlock := func() {
+			select {
+			case <-mockSupport.Blocks:
+			case <-time.After(shortTimeout):
+				t.Fatal("Expected a config block to be written")
+			}
+		}
+
+		// Two config messages produced against config sequence 0 are
+		// re-submitted, and the re-submission of the second one is
+		// ordered first
+		firstOffset := mpc.HighWaterMarkOffset()
+		mpc.YieldMessage(newMockConsumerMessage(newConfigMessage(
+			utils.MarshalOrPanic(newMockEnvelope("firstConfig")),
+			utils.MarshalOrPanic(newMockEnvelope("firstConfigUpdate")),
+			uint64(0), 0)))
+		first := expectResubmission()
+		secondOffset := mpc.HighWaterMarkOffset()
+		mpc.YieldMessage(newMockConsumerMessage(newConfigMessage(
+			utils.MarshalOrPanic(newMockEnvelope("secondConfig")),
+			utils.MarshalOrPanic(newMockEnvelope("secondConfigUpdate")),
+			uint64(0), 0)))
+		second := expectResubmission()
+		assert.Equal(t, firstOffset, first.GetRegular().OriginalOffset)
+		assert.Equal(t, secondOffset, second.GetRegular().OriginalOffset)
+
+		// The copies posted by the other orderers are discarded
+		mpc.YieldMessage(newMockConsumerMessage(second))
+		expectConfigBlock()
+		mpc.YieldMessage(newMockConsumerMessage(second))
+		mpc.YieldMessage(newMockConsumerMessage(first))
+		expectConfigBlock()
+		mpc.YieldMessage(newMockConsumerMessage(first))
+
+		logger.Debug("Closing haltChan to exit the infinite for-loop")
+		close(haltChan) // Identical to chain.Halt()
+		logger.Debug("haltChan closed")
+		<-done
+
+		assert.NoError(t, err, "Expected the processMessagesToBlocks call to return without errors")
+		assert.Equal(t, uint64(6), counts[indexProcessRegularPass], "Expected 6 REGULAR messages processed")
+		assert.Equal(t, lastCutBlockNumber+2, bareMinimumChain.lastCutBlockNumber, "Expected a config block for each config message")
+		assert.Empty(t, bareMinimumChain.pendingResubmissions, "Expected no re-submission to be pending")
+	})
+
+	t.Run("ReceiveStaleResubmittedConfig", func(t *testing.T) {
+		errorChan := make(chan struct{})
+		close(errorChan)
+		haltChan := make(chan struct{})
+		startChan := make(chan struct{})
+		close(startChan)
+
+		lastCutBlockNumber := uint64(3)
+
+		mockSupport := &mockmultichannel.ConsenterSupport{
+			Blocks:                    make(chan *cb.Block), // WriteBlock will post here
+			BlockCutterVal:            mockblockcutter.NewReceiver(),
+			ChainIDVal:                mockChannel.topic(),
+			HeightVal:                 lastCutBlockNumber, // Incremented during the WriteBlock call
+			SequenceVal:               uint64(2),
+			ConfigSeqVal:              uint64(2),
+			ProcessConfigUpdateMsgVal: newMockEnvelope("newConfig"),
+			SharedConfigVal: &mockconfig.Orderer{
+				BatchTimeoutVal: longTimeout,
+			},
+		}
+		defer close(mockSupport.BlockCutterVal.Block)
+		mockSupport.BlockCutterVal.BatchTimeoutVal = longTimeout
+
+		// Only one re-submission is expected
+		resubmitted := make(chan *ab.KafkaMessage, 1)
+		mockProducer := mocks.NewSyncProducer(t, mockBrokerConfig)
+		mockProducer.ExpectSendMessageWithCheckerFunctionAndSucceed(func(val []byte) error {
+			msg := new(ab.KafkaMessage)
+			if err := proto.Unmarshal(val, msg); err != nil {
+				return err
+			}
+			resubmitted <- msg
+			return nil
+		})
+
+		bareMinimumChain := &chainImpl{
+			producer:        mockProducer,
+			parentConsumer:  mockParentConsumer,
+			channelConsumer: mockChannelConsumer,
+
+			channel:              mockChannel,
+			support:              mockSupport,
+			lastCutBlockNumber:   lastCutBlockNumber,
+			pendingResubmissions: map[int64]uint64{5: 1},
+
+			errorChan: errorChan,
+			haltChan:  haltChan,
+			startChan: startChan,
+		}
+
+		var counts []uint64
+		done := make(chan struct{})
+
+		go func() {
+			counts, err = bareMinimumChain.processMessagesToBlocks()
+			done <- struct{}{}
+		}()
+
+		// The config changed again before the copies of the re-submission
+		// of the message found at offset 5 were consumed
+		staleCopy := newConfigMessage(
+			utils.MarshalOrPanic(newMockEnvelope("config")),
+			utils.MarshalOrPanic(newMockEnvelope("configUpdate")),
+			uint64(1), int64(5))
+		mpc.YieldMessage(newMockConsumerMessage(staleCopy))
+
+		var resubmission *ab.KafkaMessage
+		select {
+		case resubmission = <-resubmitted:
+		case <-time.After(shortTimeout):
+			t.Fatal("Expected the config message to be re-submitted again")
+		}
+		assert.Equal(t, int64(5), resubmission.GetRegular().OriginalOffset)
+		assert.Equal(t, uint64(2), resubmission.GetRegular().ConfigSeq)
+
+		// The other copies of the earlier re-submission are not re-submitted
+		mpc.YieldMessage(newMockConsumerMessage(staleCopy))
+		mpc.YieldMessage(newMockConsumerMessage(resubmission))
+
+		select {
+		case <-mockSupport.Blocks:
+		case <-time.After(shortTimeout):
+			t.Fatal("Expected a config block to be written")
+		}
+
+		logger.Debug("Closing haltChan to exit the infinite for-loop")
+		close(haltChan) // Identical to chain.Halt()
+		logger.Debug("haltChan closed")
+		<-done
+
+		assert.NoError(t, err, "Expected the processMessagesToBlocks call to return without errors")
+		assert.Equal(t, uint64(3), counts[indexProcessRegularPass], "Expected 3 REGULAR messages processed")
+		assert.Equal(t, lastCutBlockNumber+1, bareMinimumChain.lastCutBlockNumber, "Expected lastCutBlockNumber to be incremented by 1")
+		assert.Empty(t, bareMinimumChain.pendingResubmissions, "Expected no re-submission to be pending")
+	})
 }
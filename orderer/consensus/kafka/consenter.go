@@ -7,7 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package kafka
 
 import (
+	"fmt"
+
 	"github.com/Shopify/sarama"
+	channelconfig "github.com/hyperledger/fabric/common/config/channel"
 	"github.com/hyperledger/fabric/common/flogging"
 	localconfig "github.com/hyperledger/fabric/orderer/common/localconfig"
 	"github.com/hyperledger/fabric/orderer/consensus"
@@ -50,7 +53,40 @@ type consenterImpl struct {
 // existingChains.
 func (consenter *consenterImpl) HandleChain(support consensus.ConsenterSupport, metadata *cb.Metadata) (consensus.Chain, error) {
 	lastOffsetPersisted := getLastOffsetPersisted(metadata.Value, support.ChainID())
-	return newChain(consenter, support, lastOffsetPersisted)
+	pendingResubmissions := getPendingResubmissions(metadata.Value, support.ChainID())
+	return newChain(consenter, support, lastOffsetPersisted, pendingResubmissions)
+}
+
+// ValidateMigration checks that the topic of the channel holds no messages.
+// Implements the consensus.MigrationValidator interface. A channel migrated to
+// Kafka is handed off with no Kafka metadata, so its chain consumes the topic
+// from the oldest offset: the messages left over from a previous period during
+// which the channel was ordered by Kafka would be replayed onto the chain.
+// Nothing is posted to the topic between this check and the hand off, as no
+// orderer runs the channel on Kafka in the meantime.
+func (consenter *consenterImpl) ValidateMigration(support consensus.ConsenterSupport, ordererConfig channelconfig.Orderer) error {
+	channel := newChannel(support.ChainID(), defaultPartition)
+	client, err := sarama.NewClient(ordererConfig.KafkaBrokers(), consenter.brokerConfig())
+	if err != nil {
+		return fmt.Errorf("cannot connect to the Kafka cluster: %s", err)
+	}
+	defer client.Close()
+
+	oldest, err := client.GetOffset(channel.topic(), channel.partition(), sarama.OffsetOldest)
+	if err == sarama.ErrUnknownTopicOrPartition {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot retrieve the oldest offset of %s: %s", channel.String(), err)
+	}
+	newest, err := client.GetOffset(channel.topic(), channel.partition(), sarama.OffsetNewest)
+	if err != nil {
+		return fmt.Errorf("cannot retrieve the newest offset of %s: %s", channel.String(), err)
+	}
+	if newest > oldest {
+		return fmt.Errorf("%s still holds messages at offsets %d to %d, which would be replayed onto the chain; delete the topic first", channel.String(), oldest, newest-1)
+	}
+	return nil
 }
 
 // commonConsenter allows us to retrieve the configuration options set on the
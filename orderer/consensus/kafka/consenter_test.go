@@ -98,6 +98,39 @@ func TestHandleChain(t *testing.T) {
 	assert.NoError(t, err, "Expected the HandleChain call to return without errors")
 }
 
+func TestValidateMigration(t *testing.T) {
+	consenter := New(mockLocalConfig.General.TLS, mockLocalConfig.Kafka.Retry, mockLocalConfig.Kafka.Version).(consensus.MigrationValidator)
+
+	mockChannel := newChannel(channelNameForTest(t), defaultPartition)
+	mockSupport := &mockmultichannel.ConsenterSupport{ChainIDVal: mockChannel.topic()}
+
+	validate := func(oldestOffset, newestOffset int64) error {
+		mockBroker := sarama.NewMockBroker(t, 0)
+		defer mockBroker.Close()
+		mockBroker.SetHandlerByMap(map[string]sarama.MockResponse{
+			"MetadataRequest": sarama.NewMockMetadataResponse(t).
+				SetBroker(mockBroker.Addr(), mockBroker.BrokerID()).
+				SetLeader(mockChannel.topic(), mockChannel.partition(), mockBroker.BrokerID()),
+			"OffsetRequest": sarama.NewMockOffsetResponse(t).
+				SetOffset(mockChannel.topic(), mockChannel.partition(), sarama.OffsetOldest, oldestOffset).
+				SetOffset(mockChannel.topic(), mockChannel.partition(), sarama.OffsetNewest, newestOffset),
+		})
+		return consenter.ValidateMigration(mockSupport, &mockconfig.Orderer{KafkaBrokersVal: []string{mockBroker.Addr()}})
+	}
+
+	t.Run("EmptyTopic", func(t *testing.T) {
+		assert.NoError(t, validate(0, 0), "Expected a channel with an empty topic to migrate")
+	})
+
+	t.Run("RetentionExpired", func(t *testing.T) {
+		assert.NoError(t, validate(5, 5), "Expected a channel whose leftover messages were deleted to migrate")
+	})
+
+	t.Run("LeftoverMessages", func(t *testing.T) {
+		assert.Error(t, validate(0, 5), "Expected a channel whose topic holds messages not to migrate")
+	})
+}
+
 // Test helper functions and mock objects defined here
 
 var mockConsenter commonConsenter
@@ -168,7 +201,7 @@ func setupTestLogging(logLevel string, verbose bool) {
 
 // Taken from orderer/solo/consensus_test.go
 func syncQueueMessage(message *cb.Envelope, chain *chainImpl, mockBlockcutter *mockblockcutter.Receiver) {
-	chain.enqueue(message)
+	chain.enqueue(newNormalMessage(utils.MarshalOrPanic(message), uint64(0)))
 	mockBlockcutter.Block <- struct{}{} // We'll move past this line (and the function will return) only when the mock blockcutter is about to return
 }
 
@@ -126,7 +126,7 @@ func (ch *chain) main() {
 				}
 				batches, _ := ch.support.BlockCutter().Ordered(msg.initialMsg)
 				if len(batches) == 0 && timer == nil {
-					timer = time.After(ch.support.SharedConfig().BatchTimeout())
+					timer = time.After(ch.support.BlockCutter().BatchTimeout())
 					continue
 				}
 				for _, batch := range batches {
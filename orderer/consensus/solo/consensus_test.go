@@ -20,7 +20,6 @@ import (
 	"testing"
 	"time"
 
-	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
 	mockblockcutter "github.com/hyperledger/fabric/orderer/mocks/common/blockcutter"
 	mockmultichannel "github.com/hyperledger/fabric/orderer/mocks/common/multichannel"
 	cb "github.com/hyperledger/fabric/protos/common"
@@ -65,10 +64,10 @@ func goWithWait(target func()) *waitableGo {
 func TestHaltBeforeTimeout(t *testing.T) {
 	batchTimeout, _ := time.ParseDuration("1ms")
 	support := &mockmultichannel.ConsenterSupport{
-		Blocks:          make(chan *cb.Block),
-		BlockCutterVal:  mockblockcutter.NewReceiver(),
-		SharedConfigVal: &mockconfig.Orderer{BatchTimeoutVal: batchTimeout},
+		Blocks:         make(chan *cb.Block),
+		BlockCutterVal: mockblockcutter.NewReceiver(),
 	}
+	support.BlockCutterVal.BatchTimeoutVal = batchTimeout
 	defer close(support.BlockCutterVal.Block)
 	bs := newChain(support)
 	wg := goWithWait(bs.main)
@@ -86,10 +85,10 @@ func TestHaltBeforeTimeout(t *testing.T) {
 func TestStart(t *testing.T) {
 	batchTimeout, _ := time.ParseDuration("1ms")
 	support := &mockmultichannel.ConsenterSupport{
-		Blocks:          make(chan *cb.Block),
-		BlockCutterVal:  mockblockcutter.NewReceiver(),
-		SharedConfigVal: &mockconfig.Orderer{BatchTimeoutVal: batchTimeout},
+		Blocks:         make(chan *cb.Block),
+		BlockCutterVal: mockblockcutter.NewReceiver(),
 	}
+	support.BlockCutterVal.BatchTimeoutVal = batchTimeout
 	close(support.BlockCutterVal.Block)
 	bs, _ := New().HandleChain(support, nil)
 	bs.Start()
@@ -107,10 +106,10 @@ func TestStart(t *testing.T) {
 func TestOrderAfterHalt(t *testing.T) {
 	batchTimeout, _ := time.ParseDuration("1ms")
 	support := &mockmultichannel.ConsenterSupport{
-		Blocks:          make(chan *cb.Block),
-		BlockCutterVal:  mockblockcutter.NewReceiver(),
-		SharedConfigVal: &mockconfig.Orderer{BatchTimeoutVal: batchTimeout},
+		Blocks:         make(chan *cb.Block),
+		BlockCutterVal: mockblockcutter.NewReceiver(),
 	}
+	support.BlockCutterVal.BatchTimeoutVal = batchTimeout
 	defer close(support.BlockCutterVal.Block)
 	bs := newChain(support)
 	bs.Halt()
@@ -125,10 +124,10 @@ func TestOrderAfterHalt(t *testing.T) {
 func TestBatchTimer(t *testing.T) {
 	batchTimeout, _ := time.ParseDuration("1ms")
 	support := &mockmultichannel.ConsenterSupport{
-		Blocks:          make(chan *cb.Block),
-		BlockCutterVal:  mockblockcutter.NewReceiver(),
-		SharedConfigVal: &mockconfig.Orderer{BatchTimeoutVal: batchTimeout},
+		Blocks:         make(chan *cb.Block),
+		BlockCutterVal: mockblockcutter.NewReceiver(),
 	}
+	support.BlockCutterVal.BatchTimeoutVal = batchTimeout
 	defer close(support.BlockCutterVal.Block)
 	bs := newChain(support)
 	wg := goWithWait(bs.main)
@@ -149,7 +148,7 @@ func TestBatchTimer(t *testing.T) {
 		t.Fatalf("Did not create the second batch, indicating that the timer was not appopriately reset")
 	}
 
-	support.SharedConfigVal.BatchTimeoutVal, _ = time.ParseDuration("10s")
+	support.BlockCutterVal.BatchTimeoutVal, _ = time.ParseDuration("10s")
 	syncQueueMessage(testMessage, bs, support.BlockCutterVal)
 	select {
 	case <-support.Blocks:
@@ -168,10 +167,10 @@ func TestBatchTimer(t *testing.T) {
 func TestBatchTimerHaltOnFilledBatch(t *testing.T) {
 	batchTimeout, _ := time.ParseDuration("1h")
 	support := &mockmultichannel.ConsenterSupport{
-		Blocks:          make(chan *cb.Block),
-		BlockCutterVal:  mockblockcutter.NewReceiver(),
-		SharedConfigVal: &mockconfig.Orderer{BatchTimeoutVal: batchTimeout},
+		Blocks:         make(chan *cb.Block),
+		BlockCutterVal: mockblockcutter.NewReceiver(),
 	}
+	support.BlockCutterVal.BatchTimeoutVal = batchTimeout
 	defer close(support.BlockCutterVal.Block)
 
 	bs := newChain(support)
@@ -189,7 +188,7 @@ func TestBatchTimerHaltOnFilledBatch(t *testing.T) {
 	}
 
 	// Change the batch timeout to be near instant, if the timer was not reset, it will still be waiting an hour
-	support.SharedConfigVal.BatchTimeoutVal = time.Millisecond
+	support.BlockCutterVal.BatchTimeoutVal = time.Millisecond
 
 	support.BlockCutterVal.CutNext = false
 	syncQueueMessage(testMessage, bs, support.BlockCutterVal)
@@ -211,10 +210,10 @@ func TestBatchTimerHaltOnFilledBatch(t *testing.T) {
 func TestLargeMsgStyleMultiBatch(t *testing.T) {
 	batchTimeout, _ := time.ParseDuration("1h")
 	support := &mockmultichannel.ConsenterSupport{
-		Blocks:          make(chan *cb.Block),
-		BlockCutterVal:  mockblockcutter.NewReceiver(),
-		SharedConfigVal: &mockconfig.Orderer{BatchTimeoutVal: batchTimeout},
+		Blocks:         make(chan *cb.Block),
+		BlockCutterVal: mockblockcutter.NewReceiver(),
 	}
+	support.BlockCutterVal.BatchTimeoutVal = batchTimeout
 	defer close(support.BlockCutterVal.Block)
 	bs := newChain(support)
 	wg := goWithWait(bs.main)
@@ -247,10 +246,10 @@ func TestLargeMsgStyleMultiBatch(t *testing.T) {
 func TestConfigMsg(t *testing.T) {
 	batchTimeout, _ := time.ParseDuration("1h")
 	support := &mockmultichannel.ConsenterSupport{
-		Blocks:          make(chan *cb.Block),
-		BlockCutterVal:  mockblockcutter.NewReceiver(),
-		SharedConfigVal: &mockconfig.Orderer{BatchTimeoutVal: batchTimeout},
+		Blocks:         make(chan *cb.Block),
+		BlockCutterVal: mockblockcutter.NewReceiver(),
 	}
+	support.BlockCutterVal.BatchTimeoutVal = batchTimeout
 	defer close(support.BlockCutterVal.Block)
 	bs := newChain(support)
 	wg := goWithWait(bs.main)
@@ -284,10 +283,10 @@ func TestConfigMsg(t *testing.T) {
 func TestRecoverFromError(t *testing.T) {
 	batchTimeout, _ := time.ParseDuration("1ms")
 	support := &mockmultichannel.ConsenterSupport{
-		Blocks:          make(chan *cb.Block),
-		BlockCutterVal:  mockblockcutter.NewReceiver(),
-		SharedConfigVal: &mockconfig.Orderer{BatchTimeoutVal: batchTimeout},
+		Blocks:         make(chan *cb.Block),
+		BlockCutterVal: mockblockcutter.NewReceiver(),
 	}
+	support.BlockCutterVal.BatchTimeoutVal = batchTimeout
 	defer close(support.BlockCutterVal.Block)
 	bs := newChain(support)
 	_ = goWithWait(bs.main)
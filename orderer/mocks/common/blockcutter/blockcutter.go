@@ -17,6 +17,8 @@ limitations under the License.
 package mocks
 
 import (
+	"time"
+
 	cb "github.com/hyperledger/fabric/protos/common"
 )
 
@@ -40,6 +42,12 @@ type Receiver struct {
 	// CurBatch is the currently outstanding messages in the batch
 	CurBatch []*cb.Envelope
 
+	// BatchTimeoutVal is returned as the result of BatchTimeout()
+	BatchTimeoutVal time.Duration
+
+	// Arrival is the arrival time given to the last OrderedAt call
+	Arrival time.Time
+
 	// Block is a channel which is read from before returning from Ordered, it is useful for synchronization
 	// If you do not wish synchronization for whatever reason, simply close the channel
 	Block chan struct{}
@@ -88,6 +96,12 @@ func (mbc *Receiver) Ordered(env *cb.Envelope) ([][]*cb.Envelope, bool) {
 	return nil, true
 }
 
+// OrderedAt records the arrival time and behaves as Ordered
+func (mbc *Receiver) OrderedAt(env *cb.Envelope, arrival time.Time) ([][]*cb.Envelope, bool) {
+	mbc.Arrival = arrival
+	return mbc.Ordered(env)
+}
+
 // Cut terminates the current batch, returning it
 func (mbc *Receiver) Cut() []*cb.Envelope {
 	logger.Debugf("Cutting batch")
@@ -95,3 +109,8 @@ func (mbc *Receiver) Cut() []*cb.Envelope {
 	mbc.CurBatch = nil
 	return res
 }
+
+// BatchTimeout returns the BatchTimeoutVal
+func (mbc *Receiver) BatchTimeout() time.Duration {
+	return mbc.BatchTimeoutVal
+}
@@ -19,6 +19,7 @@ package multichannel
 import (
 	"github.com/hyperledger/fabric/common/config/channel"
 	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/orderer/common/blockcutter"
 	"github.com/hyperledger/fabric/orderer/common/msgprocessor"
 	mockblockcutter "github.com/hyperledger/fabric/orderer/mocks/common/blockcutter"
@@ -36,6 +37,9 @@ type ConsenterSupport struct {
 	// SharedConfigVal is the value returned by SharedConfig()
 	SharedConfigVal *mockconfig.Orderer
 
+	// MSPManagerVal is the value returned by MSPManager()
+	MSPManagerVal msp.MSPManager
+
 	// BlockCutterVal is the value returned by BlockCutter()
 	BlockCutterVal *mockblockcutter.Receiver
 
@@ -63,6 +67,12 @@ type ConsenterSupport struct {
 	// ProcessNormalMsgErr is returned as the error for ProcessNormalMsg
 	ProcessNormalMsgErr error
 
+	// ChargeRateLimitErr is returned by ChargeRateLimit
+	ChargeRateLimitErr error
+
+	// ValidateMigrationErr is returned by ValidateMigration
+	ValidateMigrationErr error
+
 	// ProcessConfigUpdateMsgVal is returned as the error for ProcessConfigUpdateMsg
 	ProcessConfigUpdateMsgVal *cb.Envelope
 
@@ -71,6 +81,15 @@ type ConsenterSupport struct {
 
 	// SequenceVal is returned by Sequence
 	SequenceVal uint64
+
+	// LastBlockVal is returned by GetLastBlock, and replaced by AppendBlock
+	LastBlockVal *cb.Block
+
+	// AppendBlockErr is returned by AppendBlock
+	AppendBlockErr error
+
+	// ConfigBlockVal stores the block passed to the most recent ProcessConfigBlock() call
+	ConfigBlockVal *cb.Block
 }
 
 // BlockCutter returns BlockCutterVal
@@ -83,6 +102,11 @@ func (mcs *ConsenterSupport) SharedConfig() config.Orderer {
 	return mcs.SharedConfigVal
 }
 
+// MSPManager returns MSPManagerVal
+func (mcs *ConsenterSupport) MSPManager() msp.MSPManager {
+	return mcs.MSPManagerVal
+}
+
 // CreateNextBlock creates a simple block structure with the given data
 func (mcs *ConsenterSupport) CreateNextBlock(data []*cb.Envelope) *cb.Block {
 	block := cb.NewBlock(0, nil)
@@ -139,6 +163,24 @@ func (mcs *ConsenterSupport) ProcessNormalMsg(env *cb.Envelope) (configSeq uint6
 	return mcs.ConfigSeqVal, mcs.ProcessNormalMsgErr
 }
 
+// ClaimTxID returns nil
+func (mcs *ConsenterSupport) ClaimTxID(txID string) error {
+	return nil
+}
+
+// ReleaseTxID does nothing
+func (mcs *ConsenterSupport) ReleaseTxID(txID string) {}
+
+// ChargeRateLimit returns ChargeRateLimitErr
+func (mcs *ConsenterSupport) ChargeRateLimit(env *cb.Envelope) error {
+	return mcs.ChargeRateLimitErr
+}
+
+// ValidateMigration returns ValidateMigrationErr
+func (mcs *ConsenterSupport) ValidateMigration(config *cb.Envelope) error {
+	return mcs.ValidateMigrationErr
+}
+
 // ProcessConfigUpdateMsg returns ProcessConfigUpdateMsgVal, ConfigSeqVal, ProcessConfigUpdateMsgErr
 func (mcs *ConsenterSupport) ProcessConfigUpdateMsg(env *cb.Envelope) (config *cb.Envelope, configSeq uint64, err error) {
 	return mcs.ProcessConfigUpdateMsgVal, mcs.ConfigSeqVal, mcs.ProcessConfigUpdateMsgErr
@@ -148,3 +190,25 @@ func (mcs *ConsenterSupport) ProcessConfigUpdateMsg(env *cb.Envelope) (config *c
 func (mcs *ConsenterSupport) Sequence() uint64 {
 	return mcs.SequenceVal
 }
+
+// GetLastBlock returns LastBlockVal
+func (mcs *ConsenterSupport) GetLastBlock() *cb.Block {
+	return mcs.LastBlockVal
+}
+
+// AppendBlock returns AppendBlockErr if set, otherwise it records the block
+// as LastBlockVal and writes it to the Blocks channel
+func (mcs *ConsenterSupport) AppendBlock(block *cb.Block) error {
+	if mcs.AppendBlockErr != nil {
+		return mcs.AppendBlockErr
+	}
+	mcs.LastBlockVal = block
+	mcs.HeightVal++
+	mcs.Blocks <- block
+	return nil
+}
+
+// ProcessConfigBlock stores the block in ConfigBlockVal
+func (mcs *ConsenterSupport) ProcessConfigBlock(block *cb.Block) {
+	mcs.ConfigBlockVal = block
+}
@@ -40,21 +40,23 @@ var (
 )
 
 type deliverClient struct {
-	client    ab.AtomicBroadcast_DeliverClient
-	channelID string
-	signer    crypto.LocalSigner
-	quiet     bool
+	client      ab.AtomicBroadcast_DeliverClient
+	channelID   string
+	signer      crypto.LocalSigner
+	quiet       bool
+	contentType ab.SeekInfo_SeekContentType
 }
 
-func newDeliverClient(client ab.AtomicBroadcast_DeliverClient, channelID string, signer crypto.LocalSigner, quiet bool) *deliverClient {
-	return &deliverClient{client: client, channelID: channelID, signer: signer, quiet: quiet}
+func newDeliverClient(client ab.AtomicBroadcast_DeliverClient, channelID string, signer crypto.LocalSigner, quiet bool, contentType ab.SeekInfo_SeekContentType) *deliverClient {
+	return &deliverClient{client: client, channelID: channelID, signer: signer, quiet: quiet, contentType: contentType}
 }
 
 func (r *deliverClient) seekHelper(start *ab.SeekPosition, stop *ab.SeekPosition) *cb.Envelope {
 	env, err := utils.CreateSignedEnvelope(cb.HeaderType_DELIVER_SEEK_INFO, r.channelID, r.signer, &ab.SeekInfo{
-		Start:    start,
-		Stop:     stop,
-		Behavior: ab.SeekInfo_BLOCK_UNTIL_READY,
+		Start:       start,
+		Stop:        stop,
+		Behavior:    ab.SeekInfo_BLOCK_UNTIL_READY,
+		ContentType: r.contentType,
 	}, 0, 0)
 	if err != nil {
 		panic(err)
@@ -120,7 +122,16 @@ func (r *deliverClient) readUntilClose() {
 			} else {
 				fmt.Println("Received block: ", t.Block.Header.Number)
 			}
-
+		case *ab.DeliverResponse_FilteredBlock:
+			if !r.quiet {
+				fmt.Println("Received filtered block: ")
+				err := protolator.DeepMarshalJSON(os.Stdout, t.FilteredBlock)
+				if err != nil {
+					fmt.Printf("  Error pretty printing filtered block: %s\n", err)
+				}
+			} else {
+				fmt.Println("Received filtered block: ", t.FilteredBlock.Header.Number)
+			}
 		}
 	}
 }
@@ -141,10 +152,12 @@ func main() {
 	var serverAddr string
 	var seek int
 	var quiet bool
+	var content string
 
 	flag.StringVar(&serverAddr, "server", fmt.Sprintf("%s:%d", config.General.ListenAddress, config.General.ListenPort), "The RPC server to connect to.")
 	flag.StringVar(&channelID, "channelID", provisional.TestChainID, "The channel ID to deliver from.")
 	flag.BoolVar(&quiet, "quiet", false, "Only print the block number, will not attempt to print its block contents.")
+	flag.StringVar(&content, "content", ab.SeekInfo_BLOCK.String(), "The content to deliver for each block: BLOCK, FILTERED_BLOCK or HEADER_WITH_SIG.")
 	flag.IntVar(&seek, "seek", -2, "Specify the range of requested blocks."+
 		"Acceptable values:"+
 		"-2 (or -1) to start from oldest (or newest) and keep at it indefinitely."+
@@ -156,6 +169,13 @@ func main() {
 		flag.PrintDefaults()
 	}
 
+	contentType, ok := ab.SeekInfo_SeekContentType_value[content]
+	if !ok {
+		fmt.Println("Wrong content value.")
+		flag.PrintDefaults()
+		return
+	}
+
 	conn, err := grpc.Dial(serverAddr, grpc.WithInsecure())
 	if err != nil {
 		fmt.Println("Error connecting:", err)
@@ -167,7 +187,7 @@ func main() {
 		return
 	}
 
-	s := newDeliverClient(client, channelID, signer, quiet)
+	s := newDeliverClient(client, channelID, signer, quiet, ab.SeekInfo_SeekContentType(contentType))
 	switch seek {
 	case -2:
 		err = s.seekOldest()
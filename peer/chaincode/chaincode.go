@@ -73,6 +73,8 @@ var (
 	escc              string
 	vscc              string
 	policyMarhsalled  []byte
+	collectionsConfig string
+	collectionsBytes  []byte
 	orderingEndpoint  string
 	tls               bool
 	caFile            string
@@ -116,6 +118,8 @@ func resetFlags() {
 		fmt.Sprint("The name of the endorsement system chaincode to be used for this chaincode"))
 	flags.StringVarP(&vscc, "vscc", "V", common.UndefinedParamValue,
 		fmt.Sprint("The name of the verification system chaincode to be used for this chaincode"))
+	flags.StringVarP(&collectionsConfig, "collections-config", "", common.UndefinedParamValue,
+		fmt.Sprint("The file containing the collection configuration, in JSON format, of this chaincode"))
 }
 
 func attachFlags(cmd *cobra.Command, names []string) {
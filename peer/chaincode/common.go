@@ -20,9 +20,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"strings"
 
+	"github.com/golang/protobuf/jsonpb"
 	"github.com/hyperledger/fabric/common/cauthdsl"
 	"github.com/hyperledger/fabric/core/chaincode"
 	"github.com/hyperledger/fabric/core/chaincode/platforms"
@@ -195,6 +197,13 @@ func checkChaincodeCmdParams(cmd *cobra.Command) error {
 		policyMarhsalled = putils.MarshalOrPanic(p)
 	}
 
+	if collectionsConfig != common.UndefinedParamValue {
+		var err error
+		if collectionsBytes, err = getCollectionConfig(collectionsConfig); err != nil {
+			return err
+		}
+	}
+
 	// Check that non-empty chaincode parameters contain only Args as a key.
 	// Type checking is done later when the JSON is actually unmarshaled
 	// into a pb.ChaincodeInput. To better understand what's going
@@ -225,6 +234,20 @@ func checkChaincodeCmdParams(cmd *cobra.Command) error {
 	return nil
 }
 
+// getCollectionConfig reads the JSON encoded collection configuration in the
+// given file and returns it marshalled
+func getCollectionConfig(file string) ([]byte, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read the collection configuration: %s", err)
+	}
+	cconf := &pcommon.CollectionConfigPackage{}
+	if err := jsonpb.UnmarshalString(string(content), cconf); err != nil {
+		return nil, fmt.Errorf("Invalid collection configuration in %s: %s", file, err)
+	}
+	return putils.Marshal(cconf)
+}
+
 // ChaincodeCmdFactory holds the clients used by ChaincodeCmd
 type ChaincodeCmdFactory struct {
 	EndorserClient  pb.EndorserClient
@@ -34,8 +34,8 @@ import (
 
 const (
 	channelFuncName = "channel"
-	shortDes        = "Operate a channel: create|fetch|join|list|update."
-	longDes         = "Operate a channel: create|fetch|join|list|update."
+	shortDes        = "Operate a channel: create|fetch|join|list|snapshot|update."
+	longDes         = "Operate a channel: create|fetch|join|list|snapshot|update."
 )
 
 var logger = flogging.MustGetLogger("channelCmd")
@@ -62,6 +62,9 @@ var (
 	caFile                     string
 	ordererTLSHostnameOverride string
 	timeout                    int
+
+	// fetch related variables
+	seekTimestamp string
 )
 
 // Cmd returns the cobra command for Node
@@ -72,6 +75,7 @@ func Cmd(cf *ChannelCmdFactory) *cobra.Command {
 	channelCmd.AddCommand(fetchCmd(cf))
 	channelCmd.AddCommand(joinCmd(cf))
 	channelCmd.AddCommand(listCmd(cf))
+	channelCmd.AddCommand(snapshotCmd(cf))
 	channelCmd.AddCommand(updateCmd(cf))
 	channelCmd.AddCommand(signconfigtxCmd(cf))
 
@@ -102,6 +106,7 @@ func resetFlags() {
 	flags.StringVarP(&chainID, "channelID", "c", common.UndefinedParamValue, "In case of a newChain command, the channel ID to create.")
 	flags.StringVarP(&channelTxFile, "file", "f", "", "Configuration transaction file generated by a tool such as configtxgen for submitting to orderer")
 	flags.IntVarP(&timeout, "timeout", "t", 5, "Channel creation timeout")
+	flags.StringVarP(&seekTimestamp, "timestamp", "", "", "RFC3339 time at or after which the transactions of the block fetched with the timestamp target were created")
 }
 
 func attachFlags(cmd *cobra.Command, names []string) {
@@ -27,6 +27,7 @@ import (
 	"regexp"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/msp/mgmt/testtools"
@@ -126,6 +127,10 @@ func (m *mockDeliverClient) getNewestBlock() (*cb.Block, error) {
 	return m.readBlock()
 }
 
+func (m *mockDeliverClient) getBlockAtTimestamp(ts time.Time) (*cb.Block, error) {
+	return m.readBlock()
+}
+
 func (m *mockDeliverClient) Close() error {
 	return nil
 }
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/hyperledger/fabric/common/localmsp"
 	"github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
@@ -31,6 +32,7 @@ type deliverClientIntf interface {
 	getSpecifiedBlock(num uint64) (*common.Block, error)
 	getOldestBlock() (*common.Block, error)
 	getNewestBlock() (*common.Block, error)
+	getBlockAtTimestamp(ts time.Time) (*common.Block, error)
 	Close() error
 }
 
@@ -74,6 +76,11 @@ func (r *deliverClient) seekNewest() error {
 	return r.client.Send(seekHelper(r.chainID, &ab.SeekPosition{Type: &ab.SeekPosition_Newest{Newest: &ab.SeekNewest{}}}))
 }
 
+func (r *deliverClient) seekTimestamp(ts time.Time) error {
+	seekTimestamp := &ab.SeekTimestamp{Timestamp: &timestamp.Timestamp{Seconds: ts.Unix(), Nanos: int32(ts.Nanosecond())}}
+	return r.client.Send(seekHelper(r.chainID, &ab.SeekPosition{Type: &ab.SeekPosition_Timestamp{Timestamp: seekTimestamp}}))
+}
+
 func (r *deliverClient) readBlock() (*common.Block, error) {
 	msg, err := r.client.Recv()
 	if err != nil {
@@ -122,6 +129,16 @@ func (r *deliverClient) getNewestBlock() (*common.Block, error) {
 	return r.readBlock()
 }
 
+func (r *deliverClient) getBlockAtTimestamp(ts time.Time) (*common.Block, error) {
+	err := r.seekTimestamp(ts)
+	if err != nil {
+		logger.Errorf("Received error: %s", err)
+		return nil, err
+	}
+
+	return r.readBlock()
+}
+
 func (r *deliverClient) Close() error {
 	return r.conn.Close()
 }
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"strconv"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	cb "github.com/hyperledger/fabric/protos/common"
@@ -29,15 +30,19 @@ import (
 
 func fetchCmd(cf *ChannelCmdFactory) *cobra.Command {
 	fetchCmd := &cobra.Command{
-		Use:   "fetch <newest|oldest|config|(number)> [outputfile]",
+		Use:   "fetch <newest|oldest|config|timestamp|(number)> [outputfile]",
 		Short: "Fetch a block",
-		Long:  "Fetch a specified block, writing it to a file.",
+		Long: "Fetch a specified block, writing it to a file. The timestamp target fetches the first block " +
+			"with a transaction created at or after the --timestamp time. The block is searched for assuming " +
+			"that transaction timestamps, which are set by the clients, only grow along the chain, so a client " +
+			"with a skewed clock can make it fetch an earlier or a later block.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return fetch(cmd, args, cf)
 		},
 	}
 	flagList := []string{
 		"channelID",
+		"timestamp",
 	}
 	attachFlags(fetchCmd, flagList)
 
@@ -54,7 +59,7 @@ func fetch(cmd *cobra.Command, args []string, cf *ChannelCmdFactory) error {
 	}
 
 	if len(args) == 0 {
-		return fmt.Errorf("fetch target required, oldest, newest, config, or a number")
+		return fmt.Errorf("fetch target required, oldest, newest, config, timestamp, or a number")
 	}
 
 	if len(args) > 2 {
@@ -78,6 +83,15 @@ func fetch(cmd *cobra.Command, args []string, cf *ChannelCmdFactory) error {
 			return err
 		}
 		block, err = cf.DeliverClient.getSpecifiedBlock(lc)
+	case "timestamp":
+		if seekTimestamp == "" {
+			return fmt.Errorf("fetch target timestamp requires the --timestamp flag")
+		}
+		ts, err := time.Parse(time.RFC3339, seekTimestamp)
+		if err != nil {
+			return fmt.Errorf("timestamp illegal: %s", err)
+		}
+		block, err = cf.DeliverClient.getBlockAtTimestamp(ts)
 	default:
 		num, err := strconv.Atoi(args[0])
 		if err != nil {
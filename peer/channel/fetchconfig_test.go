@@ -68,3 +68,43 @@ func TestFetchChain(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestFetchTimestamp(t *testing.T) {
+	InitMSP()
+	resetFlags()
+
+	mockchain := "mockchain"
+
+	signer, err := common.GetDefaultSigner()
+	if err != nil {
+		t.Fatalf("Get default signer error: %v", err)
+	}
+
+	mockCF := &ChannelCmdFactory{
+		BroadcastFactory: mockBroadcastClientFactory,
+		Signer:           signer,
+		DeliverClient:    &mockDeliverClient{},
+	}
+
+	cmd := fetchCmd(mockCF)
+	AddFlags(cmd)
+	cmd.SetArgs([]string{"-c", mockchain, "timestamp"})
+	assert.Error(t, cmd.Execute(), "Expected the timestamp target to require the timestamp flag")
+
+	resetFlags()
+	cmd = fetchCmd(mockCF)
+	AddFlags(cmd)
+	cmd.SetArgs([]string{"-c", mockchain, "--timestamp", "yesterday", "timestamp"})
+	assert.Error(t, cmd.Execute(), "Expected an illegal timestamp to be rejected")
+
+	resetFlags()
+	cmd = fetchCmd(mockCF)
+	defer os.Remove(mockchain + "_timestamp.block")
+	AddFlags(cmd)
+	cmd.SetArgs([]string{"-c", mockchain, "--timestamp", "2017-10-16T10:00:00Z", "timestamp"})
+	assert.NoError(t, cmd.Execute(), "Fetch command expected to succeed")
+
+	if _, err := os.Stat(mockchain + "_timestamp.block"); os.IsNotExist(err) {
+		t.Error("expected the block to be fetched")
+	}
+}
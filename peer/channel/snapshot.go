@@ -0,0 +1,110 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric/core/scc/cscc"
+	"github.com/hyperledger/fabric/peer/common"
+	pcommon "github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	putils "github.com/hyperledger/fabric/protos/utils"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+func snapshotCmd(cf *ChannelCmdFactory) *cobra.Command {
+	snapshotCmd := &cobra.Command{
+		Use:   "snapshot [blockNumber]",
+		Short: "Export a snapshot of the channel ledger.",
+		Long: "Request the peer to export a snapshot of the channel ledger as of the given block, " +
+			"or of the last block if none is given. A block not committed yet is exported once it is committed.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return snapshot(cmd, args, cf)
+		},
+	}
+	flagList := []string{
+		"channelID",
+	}
+	attachFlags(snapshotCmd, flagList)
+
+	return snapshotCmd
+}
+
+func executeSnapshot(cf *ChannelCmdFactory, blockNum uint64) error {
+	input := &pb.ChaincodeInput{Args: [][]byte{[]byte(cscc.ExportSnapshot), []byte(chainID)}}
+	if blockNum > 0 {
+		input.Args = append(input.Args, []byte(strconv.FormatUint(blockNum, 10)))
+	}
+	invocation := &pb.ChaincodeInvocationSpec{
+		ChaincodeSpec: &pb.ChaincodeSpec{
+			Type:        pb.ChaincodeSpec_Type(pb.ChaincodeSpec_Type_value["GOLANG"]),
+			ChaincodeId: &pb.ChaincodeID{Name: "cscc"},
+			Input:       input,
+		},
+	}
+
+	creator, err := cf.Signer.Serialize()
+	if err != nil {
+		return fmt.Errorf("Error serializing identity for %s: %s", cf.Signer.GetIdentifier(), err)
+	}
+
+	prop, _, err := putils.CreateProposalFromCIS(pcommon.HeaderType_ENDORSER_TRANSACTION, "", invocation, creator)
+	if err != nil {
+		return fmt.Errorf("Error creating proposal for snapshot %s", err)
+	}
+
+	signedProp, err := putils.GetSignedProposal(prop, cf.Signer)
+	if err != nil {
+		return fmt.Errorf("Error creating signed proposal %s", err)
+	}
+
+	proposalResp, err := cf.EndorserClient.ProcessProposal(context.Background(), signedProp)
+	if err != nil {
+		return ProposalFailedErr(err.Error())
+	}
+
+	if proposalResp == nil || proposalResp.Response == nil {
+		return ProposalFailedErr("nil proposal response")
+	}
+
+	if proposalResp.Response.Status != 200 {
+		return ProposalFailedErr(fmt.Sprintf("bad proposal response %d: %s", proposalResp.Response.Status, proposalResp.Response.Message))
+	}
+	logger.Infof("Snapshot of channel %s requested", chainID)
+	return nil
+}
+
+func snapshot(cmd *cobra.Command, args []string, cf *ChannelCmdFactory) error {
+	if chainID == common.UndefinedParamValue {
+		return errors.New("Must supply channel ID")
+	}
+
+	if len(args) > 1 {
+		return fmt.Errorf("trailing args detected")
+	}
+
+	var blockNum uint64
+	if len(args) == 1 {
+		var err error
+		if blockNum, err = strconv.ParseUint(args[0], 10, 64); err != nil {
+			return fmt.Errorf("block number must be a number, got %s", args[0])
+		}
+	}
+
+	var err error
+	if cf == nil {
+		cf, err = InitCmdFactory(EndorserRequired, OrdererNotRequired)
+		if err != nil {
+			return err
+		}
+	}
+	return executeSnapshot(cf, blockNum)
+}
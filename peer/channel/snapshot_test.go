@@ -0,0 +1,56 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	InitMSP()
+
+	signer, err := common.GetDefaultSigner()
+	assert.NoError(t, err, "Get default signer error: %v", err)
+
+	newCmd := func(status int32) *ChannelCmdFactory {
+		mockResponse := &pb.ProposalResponse{
+			Response:    &pb.Response{Status: status, Message: "snapshot failed"},
+			Endorsement: &pb.Endorsement{},
+		}
+		return &ChannelCmdFactory{
+			EndorserClient:   common.GetMockEndorserClient(mockResponse, nil),
+			BroadcastFactory: mockBroadcastClientFactory,
+			Signer:           signer,
+		}
+	}
+
+	execute := func(cf *ChannelCmdFactory, args ...string) error {
+		resetFlags()
+		cmd := snapshotCmd(cf)
+		AddFlags(cmd)
+		cmd.SetArgs(args)
+		return cmd.Execute()
+	}
+
+	assert.NoError(t, execute(newCmd(200), "-c", "mychannel"))
+	assert.NoError(t, execute(newCmd(200), "-c", "mychannel", "10"))
+
+	err = execute(newCmd(200), "10")
+	assert.EqualError(t, err, "Must supply channel ID")
+	err = execute(newCmd(200), "-c", "mychannel", "last")
+	assert.EqualError(t, err, "block number must be a number, got last")
+	err = execute(newCmd(200), "-c", "mychannel", "10", "11")
+	assert.EqualError(t, err, "trailing args detected")
+
+	err = execute(newCmd(500), "-c", "mychannel", "10")
+	assert.IsType(t, ProposalFailedErr(""), err)
+	assert.Contains(t, err.Error(), "snapshot failed")
+}
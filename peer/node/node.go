@@ -25,8 +25,8 @@ import (
 
 const (
 	nodeFuncName = "node"
-	shortDes     = "Operate a peer node: start|status."
-	longDes      = "Operate a peer node: start|status."
+	shortDes     = "Operate a peer node: start|status|rollback|rebuild-dbs|verify."
+	longDes      = "Operate a peer node: start|status|rollback|rebuild-dbs|verify."
 )
 
 var logger = flogging.MustGetLogger("nodeCmd")
@@ -35,6 +35,9 @@ var logger = flogging.MustGetLogger("nodeCmd")
 func Cmd() *cobra.Command {
 	nodeCmd.AddCommand(startCmd())
 	nodeCmd.AddCommand(statusCmd())
+	nodeCmd.AddCommand(rollbackCmd())
+	nodeCmd.AddCommand(rebuildDBsCmd())
+	nodeCmd.AddCommand(verifyCmd())
 
 	return nodeCmd
 }
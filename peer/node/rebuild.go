@@ -0,0 +1,68 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package node
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/aclmgmt"
+	"github.com/hyperledger/fabric/core/ledger/customtx"
+	"github.com/hyperledger/fabric/core/ledger/kvledger"
+	"github.com/hyperledger/fabric/core/scc"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/spf13/cobra"
+)
+
+func rebuildDBsCmd() *cobra.Command {
+	var channelID string
+	nodeRebuildDBsCmd := &cobra.Command{
+		Use:   "rebuild-dbs",
+		Short: "Rebuilds the state and history databases of a channel.",
+		Long: `Drops the state and history databases of the ledger of a channel and rebuilds them ` +
+			`from its block store. The peer must be stopped.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkLedgerToolArgs(args, channelID); err != nil {
+				return err
+			}
+			return rebuildDBs(channelID)
+		},
+	}
+	flags := nodeRebuildDBsCmd.Flags()
+	flags.StringVarP(&channelID, "channelID", "c", "", "Channel whose databases are rebuilt")
+	return nodeRebuildDBsCmd
+}
+
+func rebuildDBs(channelID string) error {
+	fmt.Printf("Rebuilding the state and history databases of channel [%s]\n", channelID)
+	initLedgerTxProcessors()
+	if err := kvledger.RebuildDBs(channelID); err != nil {
+		return fmt.Errorf("Error rebuilding the databases of channel [%s]: %s", channelID, err)
+	}
+	fmt.Printf("Rebuilt the state and history databases of channel [%s]\n", channelID)
+	return nil
+}
+
+// checkLedgerToolArgs checks the arguments common to the commands which
+// operate on the ledger of a channel while the peer is stopped
+func checkLedgerToolArgs(args []string, channelID string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("trailing args detected: %v", args)
+	}
+	if channelID == "" {
+		return errors.New("Must supply channel ID")
+	}
+	return nil
+}
+
+// initLedgerTxProcessors registers the processors of the config transactions,
+// as the peer does on start, for the blocks which are recommitted to the
+// rebuilt databases
+func initLedgerTxProcessors() {
+	customtx.Initialize(customtx.Processors{cb.HeaderType_CONFIG: aclmgmt.GetConfigTxProcessor()})
+	scc.RegisterSysCCs()
+}
@@ -0,0 +1,49 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package node
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger"
+	"github.com/spf13/cobra"
+)
+
+func rollbackCmd() *cobra.Command {
+	var channelID string
+	var blockNumber uint64
+	nodeRollbackCmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Rolls back the ledger of a channel to a block.",
+		Long: `Removes the blocks after the given block from the ledger of a channel, truncating ` +
+			`its block files and indexes, and rebuilds its state and history databases. The peer must be stopped.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkLedgerToolArgs(args, channelID); err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("blockNumber") {
+				return errors.New("Must supply the block number to roll back to")
+			}
+			return rollback(channelID, blockNumber)
+		},
+	}
+	flags := nodeRollbackCmd.Flags()
+	flags.StringVarP(&channelID, "channelID", "c", "", "Channel whose ledger is rolled back")
+	flags.Uint64VarP(&blockNumber, "blockNumber", "b", 0, "Number of the block to roll back to, which is kept")
+	return nodeRollbackCmd
+}
+
+func rollback(channelID string, blockNumber uint64) error {
+	fmt.Printf("Rolling back the ledger of channel [%s] to block [%d]\n", channelID, blockNumber)
+	initLedgerTxProcessors()
+	if err := kvledger.RollbackLedger(channelID, blockNumber); err != nil {
+		return fmt.Errorf("Error rolling back the ledger of channel [%s]: %s", channelID, err)
+	}
+	fmt.Printf("Rolled back the ledger of channel [%s] to block [%d]\n", channelID, blockNumber)
+	return nil
+}
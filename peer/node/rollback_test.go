@@ -0,0 +1,75 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package node
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	configtxtest "github.com/hyperledger/fabric/common/configtx/test"
+	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLedgerToolCmdsArgs(t *testing.T) {
+	for _, newCmd := range []func() *cobra.Command{rollbackCmd, rebuildDBsCmd, verifyCmd} {
+		cmd := newCmd()
+		cmd.SetArgs([]string{})
+		assert.EqualError(t, cmd.Execute(), "Must supply channel ID")
+
+		cmd = newCmd()
+		cmd.SetArgs([]string{"-c", "mychannel", "extra"})
+		assert.EqualError(t, cmd.Execute(), "trailing args detected: [extra]")
+	}
+
+	cmd := rollbackCmd()
+	cmd.SetArgs([]string{"-c", "mychannel"})
+	assert.EqualError(t, cmd.Execute(), "Must supply the block number to roll back to")
+}
+
+func TestLedgerToolCmds(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "ledgertools")
+	assert.NoError(t, err)
+	defer os.RemoveAll(testDir)
+	viper.Set("peer.fileSystemPath", testDir)
+	defer viper.Set("peer.fileSystemPath", "")
+
+	ledgermgmt.InitializeTestEnv()
+	gb, err := configtxtest.MakeGenesisBlock("mychannel")
+	assert.NoError(t, err)
+	_, err = ledgermgmt.CreateLedger(gb)
+	assert.NoError(t, err)
+
+	// The ledger is held by the running peer
+	cmd := verifyCmd()
+	cmd.SetArgs([]string{"-c", "mychannel"})
+	assert.Error(t, cmd.Execute())
+	ledgermgmt.Close()
+
+	cmd = verifyCmd()
+	cmd.SetArgs([]string{"-c", "unknownchannel"})
+	assert.Error(t, cmd.Execute())
+
+	cmd = verifyCmd()
+	cmd.SetArgs([]string{"-c", "mychannel"})
+	assert.NoError(t, cmd.Execute())
+
+	cmd = rebuildDBsCmd()
+	cmd.SetArgs([]string{"-c", "mychannel"})
+	assert.NoError(t, cmd.Execute())
+
+	cmd = rollbackCmd()
+	cmd.SetArgs([]string{"-c", "mychannel", "-b", "1"})
+	assert.Error(t, cmd.Execute())
+
+	cmd = rollbackCmd()
+	cmd.SetArgs([]string{"-c", "mychannel", "-b", "0"})
+	assert.NoError(t, cmd.Execute())
+}
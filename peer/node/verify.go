@@ -0,0 +1,42 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package node
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger"
+	"github.com/spf13/cobra"
+)
+
+func verifyCmd() *cobra.Command {
+	var channelID string
+	nodeVerifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verifies the hash chain of the blocks of a channel.",
+		Long: `Verifies the data hash and the previous hash in the header of every block of the ` +
+			`ledger of a channel, from the first block to the last one. The peer must be stopped.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkLedgerToolArgs(args, channelID); err != nil {
+				return err
+			}
+			return verify(channelID)
+		},
+	}
+	flags := nodeVerifyCmd.Flags()
+	flags.StringVarP(&channelID, "channelID", "c", "", "Channel whose blocks are verified")
+	return nodeVerifyCmd
+}
+
+func verify(channelID string) error {
+	fmt.Printf("Verifying the blocks of channel [%s]\n", channelID)
+	if err := kvledger.VerifyLedger(channelID); err != nil {
+		return fmt.Errorf("Error verifying the blocks of channel [%s]: %s", channelID, err)
+	}
+	fmt.Printf("Verified the blocks of channel [%s]\n", channelID)
+	return nil
+}
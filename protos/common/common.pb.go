@@ -72,6 +72,7 @@ const (
 	Status_BAD_REQUEST              Status = 400
 	Status_FORBIDDEN                Status = 403
 	Status_NOT_FOUND                Status = 404
+	Status_CONFLICT                 Status = 409
 	Status_REQUEST_ENTITY_TOO_LARGE Status = 413
 	Status_INTERNAL_SERVER_ERROR    Status = 500
 	Status_SERVICE_UNAVAILABLE      Status = 503
@@ -83,6 +84,7 @@ var Status_name = map[int32]string{
 	400: "BAD_REQUEST",
 	403: "FORBIDDEN",
 	404: "NOT_FOUND",
+	409: "CONFLICT",
 	413: "REQUEST_ENTITY_TOO_LARGE",
 	500: "INTERNAL_SERVER_ERROR",
 	503: "SERVICE_UNAVAILABLE",
@@ -93,6 +95,7 @@ var Status_value = map[string]int32{
 	"BAD_REQUEST":              400,
 	"FORBIDDEN":                403,
 	"NOT_FOUND":                404,
+	"CONFLICT":                 409,
 	"REQUEST_ENTITY_TOO_LARGE": 413,
 	"INTERNAL_SERVER_ERROR":    500,
 	"SERVICE_UNAVAILABLE":      503,
@@ -544,62 +547,62 @@ func init() {
 func init() { proto.RegisterFile("common/common.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 900 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x84, 0x55, 0xdf, 0x6e, 0xe3, 0xc4,
-	0x1b, 0xad, 0xe3, 0xfc, 0x69, 0xbe, 0x34, 0xad, 0x3b, 0xd9, 0xfe, 0xd6, 0xbf, 0xc2, 0x6a, 0x23,
-	0xc3, 0xa2, 0xd2, 0x4a, 0x89, 0x28, 0x37, 0x70, 0xe9, 0xd8, 0x93, 0xd6, 0x6a, 0xd6, 0x2e, 0x63,
-	0x67, 0x11, 0xbb, 0x48, 0x96, 0x93, 0x4c, 0x13, 0x8b, 0xc4, 0x8e, 0x6c, 0xa7, 0x6a, 0x5f, 0x02,
-	0x21, 0xc1, 0x0d, 0x17, 0xbc, 0x00, 0x4f, 0xc2, 0x5b, 0xf0, 0x12, 0x48, 0xdc, 0xa2, 0xf1, 0x8c,
-	0xbd, 0x49, 0x59, 0x89, 0xab, 0xcc, 0x39, 0x73, 0x3c, 0xdf, 0x99, 0xf3, 0x7d, 0xb1, 0xa1, 0x33,
-	0x8d, 0x57, 0xab, 0x38, 0xea, 0xf3, 0x9f, 0xde, 0x3a, 0x89, 0xb3, 0x18, 0xd5, 0x39, 0x3a, 0x7d,
-	0x39, 0x8f, 0xe3, 0xf9, 0x92, 0xf6, 0x73, 0x76, 0xb2, 0xb9, 0xeb, 0x67, 0xe1, 0x8a, 0xa6, 0x59,
-	0xb0, 0x5a, 0x73, 0xa1, 0xa6, 0x01, 0x8c, 0x82, 0x34, 0x33, 0xe2, 0xe8, 0x2e, 0x9c, 0xa3, 0x67,
-	0x50, 0x0b, 0xa3, 0x19, 0x7d, 0x50, 0xa5, 0xae, 0x74, 0x56, 0x25, 0x1c, 0x68, 0xef, 0x60, 0xff,
-	0x35, 0xcd, 0x82, 0x59, 0x90, 0x05, 0x4c, 0x71, 0x1f, 0x2c, 0x37, 0x34, 0x57, 0x1c, 0x10, 0x0e,
-	0xd0, 0xd7, 0x00, 0x69, 0x38, 0x8f, 0x82, 0x6c, 0x93, 0xd0, 0x54, 0xad, 0x74, 0xe5, 0xb3, 0xd6,
-	0xe5, 0xff, 0x7b, 0xc2, 0x51, 0xf1, 0xac, 0x5b, 0x28, 0xc8, 0x96, 0x58, 0xfb, 0x1e, 0x8e, 0xff,
-	0x25, 0x40, 0x9f, 0x83, 0x52, 0x4a, 0xfc, 0x05, 0x0d, 0x66, 0x34, 0x11, 0x05, 0x8f, 0x4a, 0xfe,
-	0x3a, 0xa7, 0xd1, 0xc7, 0xd0, 0x2c, 0x29, 0xb5, 0x92, 0x6b, 0xde, 0x13, 0xda, 0x5b, 0xa8, 0x0b,
-	0xdd, 0x2b, 0x38, 0x9c, 0x2e, 0x82, 0x28, 0xa2, 0xcb, 0xdd, 0x03, 0xdb, 0x82, 0x15, 0xb2, 0x0f,
-	0x55, 0xae, 0x7c, 0xb0, 0xb2, 0xf6, 0xa7, 0x04, 0x6d, 0x63, 0xe7, 0x61, 0x04, 0xd5, 0xec, 0x71,
-	0xcd, 0xb3, 0xa9, 0x91, 0x7c, 0x8d, 0x54, 0x68, 0xdc, 0xd3, 0x24, 0x0d, 0xe3, 0x28, 0x3f, 0xa7,
-	0x46, 0x0a, 0x88, 0xbe, 0x82, 0x66, 0xd9, 0x0d, 0x55, 0xee, 0x4a, 0x67, 0xad, 0xcb, 0xd3, 0x1e,
-	0xef, 0x57, 0xaf, 0xe8, 0x57, 0xcf, 0x2b, 0x14, 0xe4, 0xbd, 0x18, 0xbd, 0x00, 0x28, 0xee, 0x12,
-	0xce, 0xd4, 0x6a, 0x57, 0x3a, 0x6b, 0x92, 0xa6, 0x60, 0xac, 0x19, 0xea, 0x40, 0x2d, 0x7b, 0x60,
-	0x3b, 0xb5, 0x7c, 0xa7, 0x9a, 0x3d, 0x58, 0x33, 0xd6, 0x38, 0xba, 0x8e, 0xa7, 0x0b, 0xb5, 0xce,
-	0x5b, 0x9b, 0x03, 0x96, 0x1e, 0x7d, 0xc8, 0x68, 0x94, 0xfb, 0x6b, 0xf0, 0xf4, 0x4a, 0x42, 0xd3,
-	0xe1, 0xc8, 0x7d, 0x12, 0xb7, 0x0a, 0x8d, 0x69, 0x42, 0x83, 0x2c, 0x2e, 0xf2, 0x2b, 0x20, 0x2b,
-	0x10, 0xc5, 0xd1, 0xb4, 0x68, 0x02, 0x07, 0x1a, 0x86, 0xc6, 0x6d, 0xf0, 0xb8, 0x8c, 0x83, 0x19,
-	0xfa, 0x0c, 0xea, 0x5b, 0xc9, 0xb7, 0x2e, 0x0f, 0x8b, 0x01, 0xe1, 0x47, 0x13, 0xb1, 0xcb, 0x52,
-	0x64, 0xd3, 0x20, 0xce, 0xc9, 0xd7, 0xda, 0x00, 0xf6, 0x71, 0x74, 0x4f, 0x97, 0x31, 0x4f, 0x74,
-	0xcd, 0x8f, 0x2c, 0x2c, 0x08, 0xf8, 0x1f, 0xb3, 0xf0, 0xa3, 0x04, 0xb5, 0xc1, 0x32, 0x9e, 0xfe,
-	0x80, 0x2e, 0x9e, 0x38, 0xe9, 0x14, 0x4e, 0xf2, 0xed, 0x27, 0x76, 0x5e, 0x6d, 0xd9, 0x69, 0x5d,
-	0x1e, 0xef, 0x48, 0xcd, 0x20, 0x0b, 0xb8, 0x43, 0xf4, 0x05, 0xec, 0xaf, 0xc4, 0x1c, 0x8b, 0x66,
-	0x9e, 0xec, 0x48, 0x8b, 0x21, 0x27, 0xa5, 0x4c, 0x9b, 0x43, 0x6b, 0xab, 0x20, 0xfa, 0x1f, 0xd4,
-	0xa3, 0xcd, 0x6a, 0x22, 0x5c, 0x55, 0x89, 0x40, 0xe8, 0x13, 0x68, 0xaf, 0x13, 0x7a, 0x1f, 0xc6,
-	0x9b, 0xd4, 0x5f, 0x04, 0xe9, 0x42, 0xdc, 0xec, 0xa0, 0x20, 0xaf, 0x83, 0x74, 0x81, 0x3e, 0x82,
-	0x26, 0x3b, 0x93, 0x0b, 0xe4, 0x5c, 0xb0, 0xcf, 0x08, 0xb6, 0xa9, 0xbd, 0x84, 0x66, 0x69, 0xb7,
-	0x8c, 0x57, 0xea, 0xca, 0x65, 0xbc, 0x17, 0xd0, 0xde, 0x31, 0x89, 0x4e, 0xb7, 0x6e, 0xc3, 0x85,
-	0x25, 0x3e, 0xff, 0x5d, 0x82, 0xba, 0x9b, 0x05, 0xd9, 0x26, 0x45, 0x2d, 0x68, 0x8c, 0xed, 0x1b,
-	0xdb, 0xf9, 0xd6, 0x56, 0xf6, 0xd0, 0x01, 0x34, 0xdc, 0xb1, 0x61, 0x60, 0xd7, 0x55, 0xfe, 0x90,
-	0x90, 0x02, 0xad, 0x81, 0x6e, 0xfa, 0x04, 0x7f, 0x33, 0xc6, 0xae, 0xa7, 0xfc, 0x24, 0xa3, 0x43,
-	0x68, 0x0e, 0x1d, 0x32, 0xb0, 0x4c, 0x13, 0xdb, 0xca, 0xcf, 0x39, 0xb6, 0x1d, 0xcf, 0x1f, 0x3a,
-	0x63, 0xdb, 0x54, 0x7e, 0x91, 0xd1, 0x0b, 0x50, 0x85, 0xda, 0xc7, 0xb6, 0x67, 0x79, 0xdf, 0xf9,
-	0x9e, 0xe3, 0xf8, 0x23, 0x9d, 0x5c, 0x61, 0xe5, 0x37, 0x19, 0x9d, 0xc2, 0x89, 0x65, 0x7b, 0x98,
-	0xd8, 0xfa, 0xc8, 0x77, 0x31, 0x79, 0x83, 0x89, 0x8f, 0x09, 0x71, 0x88, 0xf2, 0x97, 0x8c, 0x54,
-	0xe8, 0x30, 0xca, 0x32, 0xb0, 0x3f, 0xb6, 0xf5, 0x37, 0xba, 0x35, 0xd2, 0x07, 0x23, 0xac, 0xfc,
-	0x2d, 0x9f, 0xff, 0x2a, 0x01, 0xf0, 0x7c, 0x3d, 0xf6, 0x6f, 0x6c, 0x41, 0xe3, 0x35, 0x76, 0x5d,
-	0xfd, 0x0a, 0x2b, 0x7b, 0x08, 0xa0, 0x6e, 0x38, 0xf6, 0xd0, 0xba, 0x52, 0x24, 0x74, 0x0c, 0x6d,
-	0xbe, 0xf6, 0xc7, 0xb7, 0xa6, 0xee, 0x61, 0xa5, 0x82, 0x54, 0x78, 0x86, 0x6d, 0xd3, 0x21, 0x2e,
-	0x26, 0xbe, 0x47, 0x74, 0xdb, 0xd5, 0x0d, 0xcf, 0x72, 0x6c, 0x45, 0x46, 0xcf, 0xa1, 0xe3, 0x10,
-	0x13, 0x93, 0x27, 0x1b, 0x55, 0x74, 0x02, 0xc7, 0x26, 0x1e, 0x59, 0xcc, 0x9b, 0x8b, 0xf1, 0x8d,
-	0x6f, 0xd9, 0x43, 0x47, 0xa9, 0x31, 0xda, 0xb8, 0xd6, 0x2d, 0xdb, 0x70, 0x4c, 0xec, 0xdf, 0xea,
-	0xc6, 0x0d, 0xab, 0x5f, 0x3f, 0x7f, 0x07, 0x68, 0x27, 0x75, 0x8b, 0xbd, 0x6d, 0xd1, 0x21, 0x80,
-	0x6b, 0x5d, 0xd9, 0xba, 0x37, 0x26, 0xd8, 0x55, 0xf6, 0xd0, 0x11, 0xb4, 0x46, 0xba, 0xeb, 0xf9,
-	0xa5, 0xd5, 0xe7, 0xd0, 0xd9, 0xaa, 0xea, 0xfa, 0x43, 0x6b, 0xe4, 0x61, 0xa2, 0x54, 0xd8, 0xe5,
-	0x84, 0x2d, 0x45, 0x1e, 0xb8, 0xf0, 0x69, 0x9c, 0xcc, 0x7b, 0x8b, 0xc7, 0x35, 0x4d, 0x96, 0x74,
-	0x36, 0xa7, 0x49, 0xef, 0x2e, 0x98, 0x24, 0xe1, 0x94, 0xbf, 0x5b, 0x52, 0x31, 0x9c, 0x6f, 0x2f,
-	0xe6, 0x61, 0xb6, 0xd8, 0x4c, 0x18, 0xec, 0x6f, 0x89, 0xfb, 0x5c, 0xcc, 0x3f, 0x1c, 0xa9, 0xf8,
-	0xb8, 0x4c, 0xea, 0x39, 0xfc, 0xf2, 0x9f, 0x00, 0x00, 0x00, 0xff, 0xff, 0xa1, 0xcb, 0xe1, 0xb4,
-	0x74, 0x06, 0x00, 0x00,
+	// 906 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x55, 0xd1, 0x6e, 0xe3, 0x44,
+	0x14, 0xad, 0xe3, 0xc4, 0x49, 0x6e, 0x9a, 0x76, 0x3a, 0xd9, 0xb2, 0xa6, 0xb0, 0xda, 0xc8, 0xb0,
+	0xa8, 0xb4, 0x52, 0x22, 0xca, 0x0b, 0x3c, 0x3a, 0xf6, 0xa4, 0xb5, 0x9a, 0xb5, 0xcb, 0xd8, 0x59,
+	0xc4, 0x2e, 0x92, 0xe5, 0x24, 0xd3, 0xc4, 0x22, 0xb1, 0x23, 0xdb, 0xa9, 0xda, 0x9f, 0x40, 0x48,
+	0xf0, 0xb2, 0x0f, 0x7c, 0x08, 0x7f, 0xc0, 0x5f, 0xf0, 0x13, 0x48, 0xbc, 0x22, 0x7b, 0x6c, 0x6f,
+	0x52, 0x2a, 0xf1, 0x14, 0x9f, 0x33, 0x67, 0xe6, 0x9e, 0x39, 0xf7, 0xc6, 0x86, 0xce, 0x34, 0x5c,
+	0xad, 0xc2, 0xa0, 0xcf, 0x7f, 0x7a, 0xeb, 0x28, 0x4c, 0x42, 0x2c, 0x71, 0x74, 0xf2, 0x72, 0x1e,
+	0x86, 0xf3, 0x25, 0xeb, 0x67, 0xec, 0x64, 0x73, 0xdb, 0x4f, 0xfc, 0x15, 0x8b, 0x13, 0x6f, 0xb5,
+	0xe6, 0x42, 0x45, 0x01, 0x18, 0x79, 0x71, 0xa2, 0x85, 0xc1, 0xad, 0x3f, 0xc7, 0xcf, 0xa0, 0xe6,
+	0x07, 0x33, 0x76, 0x2f, 0x0b, 0x5d, 0xe1, 0xb4, 0x4a, 0x39, 0x50, 0xde, 0x41, 0xe3, 0x35, 0x4b,
+	0xbc, 0x99, 0x97, 0x78, 0xa9, 0xe2, 0xce, 0x5b, 0x6e, 0x58, 0xa6, 0xd8, 0xa7, 0x1c, 0xe0, 0x6f,
+	0x01, 0x62, 0x7f, 0x1e, 0x78, 0xc9, 0x26, 0x62, 0xb1, 0x5c, 0xe9, 0x8a, 0xa7, 0xad, 0x8b, 0x8f,
+	0x7b, 0xb9, 0xa3, 0x62, 0xaf, 0x5d, 0x28, 0xe8, 0x96, 0x58, 0xf9, 0x11, 0x8e, 0xfe, 0x23, 0xc0,
+	0x5f, 0x02, 0x2a, 0x25, 0xee, 0x82, 0x79, 0x33, 0x16, 0xe5, 0x05, 0x0f, 0x4b, 0xfe, 0x2a, 0xa3,
+	0xf1, 0xa7, 0xd0, 0x2c, 0x29, 0xb9, 0x92, 0x69, 0x3e, 0x10, 0xca, 0x5b, 0x90, 0x72, 0xdd, 0x2b,
+	0x38, 0x98, 0x2e, 0xbc, 0x20, 0x60, 0xcb, 0xdd, 0x03, 0xdb, 0x39, 0x9b, 0xcb, 0x9e, 0xaa, 0x5c,
+	0x79, 0xb2, 0xb2, 0xf2, 0x97, 0x00, 0x6d, 0x6d, 0x67, 0x33, 0x86, 0x6a, 0xf2, 0xb0, 0xe6, 0xd9,
+	0xd4, 0x68, 0xf6, 0x8c, 0x65, 0xa8, 0xdf, 0xb1, 0x28, 0xf6, 0xc3, 0x20, 0x3b, 0xa7, 0x46, 0x0b,
+	0x88, 0xbf, 0x81, 0x66, 0xd9, 0x0d, 0x59, 0xec, 0x0a, 0xa7, 0xad, 0x8b, 0x93, 0x1e, 0xef, 0x57,
+	0xaf, 0xe8, 0x57, 0xcf, 0x29, 0x14, 0xf4, 0x83, 0x18, 0xbf, 0x00, 0x28, 0xee, 0xe2, 0xcf, 0xe4,
+	0x6a, 0x57, 0x38, 0x6d, 0xd2, 0x66, 0xce, 0x18, 0x33, 0xdc, 0x81, 0x5a, 0x72, 0x9f, 0xae, 0xd4,
+	0xb2, 0x95, 0x6a, 0x72, 0x6f, 0xcc, 0xd2, 0xc6, 0xb1, 0x75, 0x38, 0x5d, 0xc8, 0x12, 0x6f, 0x6d,
+	0x06, 0xd2, 0xf4, 0xd8, 0x7d, 0xc2, 0x82, 0xcc, 0x5f, 0x9d, 0xa7, 0x57, 0x12, 0x8a, 0x0a, 0x87,
+	0xf6, 0xa3, 0xb8, 0x65, 0xa8, 0x4f, 0x23, 0xe6, 0x25, 0x61, 0x91, 0x5f, 0x01, 0xd3, 0x02, 0x41,
+	0x18, 0x4c, 0x8b, 0x26, 0x70, 0xa0, 0x10, 0xa8, 0xdf, 0x78, 0x0f, 0xcb, 0xd0, 0x9b, 0xe1, 0x2f,
+	0x40, 0xda, 0x4a, 0xbe, 0x75, 0x71, 0x50, 0x0c, 0x08, 0x3f, 0x9a, 0x4a, 0x8b, 0x32, 0xc5, 0x74,
+	0x1a, 0xf2, 0x73, 0xb2, 0x67, 0x65, 0x00, 0x0d, 0x12, 0xdc, 0xb1, 0x65, 0xc8, 0x13, 0x5d, 0xf3,
+	0x23, 0x0b, 0x0b, 0x39, 0xfc, 0x9f, 0x59, 0xf8, 0x59, 0x80, 0xda, 0x60, 0x19, 0x4e, 0x7f, 0xc2,
+	0xe7, 0x8f, 0x9c, 0x74, 0x0a, 0x27, 0xd9, 0xf2, 0x23, 0x3b, 0xaf, 0xb6, 0xec, 0xb4, 0x2e, 0x8e,
+	0x76, 0xa4, 0xba, 0x97, 0x78, 0xdc, 0x21, 0xfe, 0x0a, 0x1a, 0xab, 0x7c, 0x8e, 0xf3, 0x66, 0x1e,
+	0xef, 0x48, 0x8b, 0x21, 0xa7, 0xa5, 0x4c, 0x99, 0x43, 0x6b, 0xab, 0x20, 0xfe, 0x08, 0xa4, 0x60,
+	0xb3, 0x9a, 0xe4, 0xae, 0xaa, 0x34, 0x47, 0xf8, 0x33, 0x68, 0xaf, 0x23, 0x76, 0xe7, 0x87, 0x9b,
+	0xd8, 0x5d, 0x78, 0xf1, 0x22, 0xbf, 0xd9, 0x7e, 0x41, 0x5e, 0x79, 0xf1, 0x02, 0x7f, 0x02, 0xcd,
+	0xf4, 0x4c, 0x2e, 0x10, 0x33, 0x41, 0x23, 0x25, 0xd2, 0x45, 0xe5, 0x25, 0x34, 0x4b, 0xbb, 0x65,
+	0xbc, 0x42, 0x57, 0x2c, 0xe3, 0x3d, 0x87, 0xf6, 0x8e, 0x49, 0x7c, 0xb2, 0x75, 0x1b, 0x2e, 0x2c,
+	0xf1, 0xd9, 0x1f, 0x02, 0x48, 0x76, 0xe2, 0x25, 0x9b, 0x18, 0xb7, 0xa0, 0x3e, 0x36, 0xaf, 0x4d,
+	0xeb, 0x7b, 0x13, 0xed, 0xe1, 0x7d, 0xa8, 0xdb, 0x63, 0x4d, 0x23, 0xb6, 0x8d, 0xfe, 0x14, 0x30,
+	0x82, 0xd6, 0x40, 0xd5, 0x5d, 0x4a, 0xbe, 0x1b, 0x13, 0xdb, 0x41, 0xbf, 0x88, 0xf8, 0x00, 0x9a,
+	0x43, 0x8b, 0x0e, 0x0c, 0x5d, 0x27, 0x26, 0xfa, 0x35, 0xc3, 0xa6, 0xe5, 0xb8, 0x43, 0x6b, 0x6c,
+	0xea, 0xe8, 0x37, 0x11, 0xb7, 0xa1, 0xa1, 0x59, 0xe6, 0x70, 0x64, 0x68, 0x0e, 0x7a, 0x2f, 0xe2,
+	0x17, 0x20, 0xe7, 0x9b, 0x5d, 0x62, 0x3a, 0x86, 0xf3, 0x83, 0xeb, 0x58, 0x96, 0x3b, 0x52, 0xe9,
+	0x25, 0x41, 0xbf, 0x8b, 0xf8, 0x04, 0x8e, 0x0d, 0xd3, 0x21, 0xd4, 0x54, 0x47, 0xae, 0x4d, 0xe8,
+	0x1b, 0x42, 0x5d, 0x42, 0xa9, 0x45, 0xd1, 0xdf, 0x22, 0x96, 0xa1, 0x93, 0x52, 0x86, 0x46, 0xdc,
+	0xb1, 0xa9, 0xbe, 0x51, 0x8d, 0x91, 0x3a, 0x18, 0x11, 0xf4, 0x8f, 0x78, 0xf6, 0x5e, 0x00, 0xe0,
+	0x71, 0x3b, 0xe9, 0x9f, 0xb3, 0x05, 0xf5, 0xd7, 0xc4, 0xb6, 0xd5, 0x4b, 0x82, 0xf6, 0x30, 0x80,
+	0x94, 0xd6, 0x37, 0x2e, 0x91, 0x80, 0x8f, 0xa0, 0xcd, 0x9f, 0xdd, 0xf1, 0x8d, 0xae, 0x3a, 0x04,
+	0x55, 0xb0, 0x0c, 0xcf, 0x88, 0xa9, 0x5b, 0xd4, 0x26, 0xd4, 0x75, 0xa8, 0x6a, 0xda, 0xaa, 0xe6,
+	0x18, 0x96, 0x89, 0x44, 0xfc, 0x1c, 0x3a, 0x16, 0xd5, 0x09, 0x7d, 0xb4, 0x50, 0xc5, 0xc7, 0x70,
+	0xa4, 0x93, 0x91, 0x91, 0x7a, 0xb3, 0x09, 0xb9, 0x76, 0x0d, 0x73, 0x68, 0xa1, 0x5a, 0x4a, 0x6b,
+	0x57, 0xaa, 0x61, 0x6a, 0x96, 0x4e, 0xdc, 0x1b, 0x55, 0xbb, 0x4e, 0xeb, 0x4b, 0x67, 0xef, 0x00,
+	0xef, 0x34, 0xc1, 0x48, 0x5f, 0xbe, 0xf8, 0x00, 0xc0, 0x36, 0x2e, 0x4d, 0xd5, 0x19, 0x53, 0x62,
+	0xa3, 0x3d, 0x7c, 0x08, 0xad, 0x91, 0x6a, 0x3b, 0x6e, 0x69, 0xf5, 0x39, 0x74, 0xb6, 0xaa, 0xda,
+	0xee, 0xd0, 0x18, 0x39, 0x84, 0xa2, 0x4a, 0x7a, 0xb9, 0xdc, 0x16, 0x12, 0x07, 0x36, 0x7c, 0x1e,
+	0x46, 0xf3, 0xde, 0xe2, 0x61, 0xcd, 0xa2, 0x25, 0x9b, 0xcd, 0x59, 0xd4, 0xbb, 0xf5, 0x26, 0x91,
+	0x3f, 0xe5, 0xaf, 0x9a, 0x38, 0x9f, 0xd5, 0xb7, 0xe7, 0x73, 0x3f, 0x59, 0x6c, 0x26, 0x29, 0xec,
+	0x6f, 0x89, 0xfb, 0x5c, 0xcc, 0xbf, 0x23, 0x71, 0xfe, 0xad, 0x99, 0x48, 0x19, 0xfc, 0xfa, 0xdf,
+	0x01, 0x00, 0xb4, 0x01, 0x81, 0x08, 0x83, 0x06, 0x00, 0x00,
 }
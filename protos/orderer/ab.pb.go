@@ -5,28 +5,44 @@
 Package orderer is a generated protocol buffer package.
 
 It is generated from these files:
+
 	orderer/ab.proto
+	orderer/admin.proto
+	orderer/bftsmart.proto
 	orderer/configuration.proto
 	orderer/kafka.proto
 
 It has these top-level messages:
+
 	BroadcastResponse
 	SeekNewest
 	SeekOldest
 	SeekSpecified
+	SeekTimestamp
 	SeekPosition
 	SeekInfo
 	DeliverResponse
+	FilteredBlock
+	FilteredTransaction
+	ChannelInfo
+	ChannelList
+	ChannelConfigRequest
+	ChannelConfigResponse
+	BFTsmartMetadata
 	ConsensusType
 	BatchSize
 	BatchTimeout
 	KafkaBrokers
 	ChannelRestrictions
+	RateLimits
+	RateLimit
+	BatchCutting
 	KafkaMessage
 	KafkaMessageRegular
 	KafkaMessageTimeToCut
 	KafkaMessageConnect
 	KafkaMetadata
+	KafkaResubmission
 */
 package orderer
 
@@ -34,6 +50,7 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import common "github.com/hyperledger/fabric/protos/common"
+import google_protobuf "github.com/golang/protobuf/ptypes/timestamp"
 
 import (
 	context "golang.org/x/net/context"
@@ -70,7 +87,35 @@ var SeekInfo_SeekBehavior_value = map[string]int32{
 func (x SeekInfo_SeekBehavior) String() string {
 	return proto.EnumName(SeekInfo_SeekBehavior_name, int32(x))
 }
-func (SeekInfo_SeekBehavior) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{5, 0} }
+func (SeekInfo_SeekBehavior) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{6, 0} }
+
+// SeekContentType selects what is delivered for each block.  BLOCK delivers
+// the blocks themselves, FILTERED_BLOCK delivers a FilteredBlock carrying the
+// header, the signatures and a summary of each transaction, HEADER_WITH_SIG
+// delivers a FilteredBlock without the transaction summaries.
+type SeekInfo_SeekContentType int32
+
+const (
+	SeekInfo_BLOCK           SeekInfo_SeekContentType = 0
+	SeekInfo_FILTERED_BLOCK  SeekInfo_SeekContentType = 1
+	SeekInfo_HEADER_WITH_SIG SeekInfo_SeekContentType = 2
+)
+
+var SeekInfo_SeekContentType_name = map[int32]string{
+	0: "BLOCK",
+	1: "FILTERED_BLOCK",
+	2: "HEADER_WITH_SIG",
+}
+var SeekInfo_SeekContentType_value = map[string]int32{
+	"BLOCK":           0,
+	"FILTERED_BLOCK":  1,
+	"HEADER_WITH_SIG": 2,
+}
+
+func (x SeekInfo_SeekContentType) String() string {
+	return proto.EnumName(SeekInfo_SeekContentType_name, int32(x))
+}
+func (SeekInfo_SeekContentType) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{6, 1} }
 
 type BroadcastResponse struct {
 	// Status code, which may be used to programatically respond to success/failure
@@ -130,18 +175,40 @@ func (m *SeekSpecified) GetNumber() uint64 {
 	return 0
 }
 
+// SeekTimestamp resolves to the first block with a transaction at or after the
+// given time.  Transaction timestamps are set by the clients and are not checked
+// by the orderer, and the block is found by a binary search which assumes that
+// they only grow along the chain.  A client whose clock is skewed can make the
+// position resolve to an earlier or a later block than the expected one
+type SeekTimestamp struct {
+	Timestamp *google_protobuf.Timestamp `protobuf:"bytes,1,opt,name=timestamp" json:"timestamp,omitempty"`
+}
+
+func (m *SeekTimestamp) Reset()                    { *m = SeekTimestamp{} }
+func (m *SeekTimestamp) String() string            { return proto.CompactTextString(m) }
+func (*SeekTimestamp) ProtoMessage()               {}
+func (*SeekTimestamp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *SeekTimestamp) GetTimestamp() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
 type SeekPosition struct {
 	// Types that are valid to be assigned to Type:
 	//	*SeekPosition_Newest
 	//	*SeekPosition_Oldest
 	//	*SeekPosition_Specified
+	//	*SeekPosition_Timestamp
 	Type isSeekPosition_Type `protobuf_oneof:"Type"`
 }
 
 func (m *SeekPosition) Reset()                    { *m = SeekPosition{} }
 func (m *SeekPosition) String() string            { return proto.CompactTextString(m) }
 func (*SeekPosition) ProtoMessage()               {}
-func (*SeekPosition) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+func (*SeekPosition) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
 
 type isSeekPosition_Type interface {
 	isSeekPosition_Type()
@@ -156,10 +223,14 @@ type SeekPosition_Oldest struct {
 type SeekPosition_Specified struct {
 	Specified *SeekSpecified `protobuf:"bytes,3,opt,name=specified,oneof"`
 }
+type SeekPosition_Timestamp struct {
+	Timestamp *SeekTimestamp `protobuf:"bytes,4,opt,name=timestamp,oneof"`
+}
 
 func (*SeekPosition_Newest) isSeekPosition_Type()    {}
 func (*SeekPosition_Oldest) isSeekPosition_Type()    {}
 func (*SeekPosition_Specified) isSeekPosition_Type() {}
+func (*SeekPosition_Timestamp) isSeekPosition_Type() {}
 
 func (m *SeekPosition) GetType() isSeekPosition_Type {
 	if m != nil {
@@ -189,12 +260,20 @@ func (m *SeekPosition) GetSpecified() *SeekSpecified {
 	return nil
 }
 
+func (m *SeekPosition) GetTimestamp() *SeekTimestamp {
+	if x, ok := m.GetType().(*SeekPosition_Timestamp); ok {
+		return x.Timestamp
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*SeekPosition) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _SeekPosition_OneofMarshaler, _SeekPosition_OneofUnmarshaler, _SeekPosition_OneofSizer, []interface{}{
 		(*SeekPosition_Newest)(nil),
 		(*SeekPosition_Oldest)(nil),
 		(*SeekPosition_Specified)(nil),
+		(*SeekPosition_Timestamp)(nil),
 	}
 }
 
@@ -217,6 +296,11 @@ func _SeekPosition_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.Specified); err != nil {
 			return err
 		}
+	case *SeekPosition_Timestamp:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Timestamp); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("SeekPosition.Type has unexpected type %T", x)
@@ -251,6 +335,14 @@ func _SeekPosition_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.B
 		err := b.DecodeMessage(msg)
 		m.Type = &SeekPosition_Specified{msg}
 		return true, err
+	case 4: // Type.timestamp
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(SeekTimestamp)
+		err := b.DecodeMessage(msg)
+		m.Type = &SeekPosition_Timestamp{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -275,6 +367,11 @@ func _SeekPosition_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(3<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *SeekPosition_Timestamp:
+		s := proto.Size(x.Timestamp)
+		n += proto.SizeVarint(4<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -291,15 +388,16 @@ func _SeekPosition_OneofSizer(msg proto.Message) (n int) {
 // as they are created, behavior should be set to BLOCK_UNTIL_READY and the stop should be set to
 // specified with a number of MAX_UINT64
 type SeekInfo struct {
-	Start    *SeekPosition         `protobuf:"bytes,1,opt,name=start" json:"start,omitempty"`
-	Stop     *SeekPosition         `protobuf:"bytes,2,opt,name=stop" json:"stop,omitempty"`
-	Behavior SeekInfo_SeekBehavior `protobuf:"varint,3,opt,name=behavior,enum=orderer.SeekInfo_SeekBehavior" json:"behavior,omitempty"`
+	Start       *SeekPosition            `protobuf:"bytes,1,opt,name=start" json:"start,omitempty"`
+	Stop        *SeekPosition            `protobuf:"bytes,2,opt,name=stop" json:"stop,omitempty"`
+	Behavior    SeekInfo_SeekBehavior    `protobuf:"varint,3,opt,name=behavior,enum=orderer.SeekInfo_SeekBehavior" json:"behavior,omitempty"`
+	ContentType SeekInfo_SeekContentType `protobuf:"varint,4,opt,name=content_type,json=contentType,enum=orderer.SeekInfo_SeekContentType" json:"content_type,omitempty"`
 }
 
 func (m *SeekInfo) Reset()                    { *m = SeekInfo{} }
 func (m *SeekInfo) String() string            { return proto.CompactTextString(m) }
 func (*SeekInfo) ProtoMessage()               {}
-func (*SeekInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (*SeekInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
 
 func (m *SeekInfo) GetStart() *SeekPosition {
 	if m != nil {
@@ -322,17 +420,25 @@ func (m *SeekInfo) GetBehavior() SeekInfo_SeekBehavior {
 	return SeekInfo_BLOCK_UNTIL_READY
 }
 
+func (m *SeekInfo) GetContentType() SeekInfo_SeekContentType {
+	if m != nil {
+		return m.ContentType
+	}
+	return SeekInfo_BLOCK
+}
+
 type DeliverResponse struct {
 	// Types that are valid to be assigned to Type:
 	//	*DeliverResponse_Status
 	//	*DeliverResponse_Block
+	//	*DeliverResponse_FilteredBlock
 	Type isDeliverResponse_Type `protobuf_oneof:"Type"`
 }
 
 func (m *DeliverResponse) Reset()                    { *m = DeliverResponse{} }
 func (m *DeliverResponse) String() string            { return proto.CompactTextString(m) }
 func (*DeliverResponse) ProtoMessage()               {}
-func (*DeliverResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+func (*DeliverResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
 
 type isDeliverResponse_Type interface {
 	isDeliverResponse_Type()
@@ -344,9 +450,13 @@ type DeliverResponse_Status struct {
 type DeliverResponse_Block struct {
 	Block *common.Block `protobuf:"bytes,2,opt,name=block,oneof"`
 }
+type DeliverResponse_FilteredBlock struct {
+	FilteredBlock *FilteredBlock `protobuf:"bytes,3,opt,name=filtered_block,json=filteredBlock,oneof"`
+}
 
-func (*DeliverResponse_Status) isDeliverResponse_Type() {}
-func (*DeliverResponse_Block) isDeliverResponse_Type()  {}
+func (*DeliverResponse_Status) isDeliverResponse_Type()        {}
+func (*DeliverResponse_Block) isDeliverResponse_Type()         {}
+func (*DeliverResponse_FilteredBlock) isDeliverResponse_Type() {}
 
 func (m *DeliverResponse) GetType() isDeliverResponse_Type {
 	if m != nil {
@@ -369,11 +479,19 @@ func (m *DeliverResponse) GetBlock() *common.Block {
 	return nil
 }
 
+func (m *DeliverResponse) GetFilteredBlock() *FilteredBlock {
+	if x, ok := m.GetType().(*DeliverResponse_FilteredBlock); ok {
+		return x.FilteredBlock
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*DeliverResponse) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _DeliverResponse_OneofMarshaler, _DeliverResponse_OneofUnmarshaler, _DeliverResponse_OneofSizer, []interface{}{
 		(*DeliverResponse_Status)(nil),
 		(*DeliverResponse_Block)(nil),
+		(*DeliverResponse_FilteredBlock)(nil),
 	}
 }
 
@@ -389,6 +507,11 @@ func _DeliverResponse_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.Block); err != nil {
 			return err
 		}
+	case *DeliverResponse_FilteredBlock:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.FilteredBlock); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("DeliverResponse.Type has unexpected type %T", x)
@@ -414,6 +537,14 @@ func _DeliverResponse_OneofUnmarshaler(msg proto.Message, tag, wire int, b *prot
 		err := b.DecodeMessage(msg)
 		m.Type = &DeliverResponse_Block{msg}
 		return true, err
+	case 3: // Type.filtered_block
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(FilteredBlock)
+		err := b.DecodeMessage(msg)
+		m.Type = &DeliverResponse_FilteredBlock{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -431,6 +562,11 @@ func _DeliverResponse_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(2<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *DeliverResponse_FilteredBlock:
+		s := proto.Size(x.FilteredBlock)
+		n += proto.SizeVarint(3<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -438,15 +574,88 @@ func _DeliverResponse_OneofSizer(msg proto.Message) (n int) {
 	return n
 }
 
+// FilteredBlock is what is delivered for a block when FILTERED_BLOCK or
+// HEADER_WITH_SIG content is requested
+type FilteredBlock struct {
+	Header *common.BlockHeader `protobuf:"bytes,1,opt,name=header" json:"header,omitempty"`
+	// The SIGNATURES metadata of the block, which signs its header
+	Signatures *common.Metadata `protobuf:"bytes,2,opt,name=signatures" json:"signatures,omitempty"`
+	// The summary of each transaction of the block, in order
+	FilteredTransactions []*FilteredTransaction `protobuf:"bytes,3,rep,name=filtered_transactions,json=filteredTransactions" json:"filtered_transactions,omitempty"`
+}
+
+func (m *FilteredBlock) Reset()                    { *m = FilteredBlock{} }
+func (m *FilteredBlock) String() string            { return proto.CompactTextString(m) }
+func (*FilteredBlock) ProtoMessage()               {}
+func (*FilteredBlock) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *FilteredBlock) GetHeader() *common.BlockHeader {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func (m *FilteredBlock) GetSignatures() *common.Metadata {
+	if m != nil {
+		return m.Signatures
+	}
+	return nil
+}
+
+func (m *FilteredBlock) GetFilteredTransactions() []*FilteredTransaction {
+	if m != nil {
+		return m.FilteredTransactions
+	}
+	return nil
+}
+
+// FilteredTransaction summarizes a transaction of a FilteredBlock
+type FilteredTransaction struct {
+	Txid      string            `protobuf:"bytes,1,opt,name=txid" json:"txid,omitempty"`
+	Type      common.HeaderType `protobuf:"varint,2,opt,name=type,enum=common.HeaderType" json:"type,omitempty"`
+	ChannelId string            `protobuf:"bytes,3,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+}
+
+func (m *FilteredTransaction) Reset()                    { *m = FilteredTransaction{} }
+func (m *FilteredTransaction) String() string            { return proto.CompactTextString(m) }
+func (*FilteredTransaction) ProtoMessage()               {}
+func (*FilteredTransaction) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *FilteredTransaction) GetTxid() string {
+	if m != nil {
+		return m.Txid
+	}
+	return ""
+}
+
+func (m *FilteredTransaction) GetType() common.HeaderType {
+	if m != nil {
+		return m.Type
+	}
+	return common.HeaderType_MESSAGE
+}
+
+func (m *FilteredTransaction) GetChannelId() string {
+	if m != nil {
+		return m.ChannelId
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*BroadcastResponse)(nil), "orderer.BroadcastResponse")
 	proto.RegisterType((*SeekNewest)(nil), "orderer.SeekNewest")
 	proto.RegisterType((*SeekOldest)(nil), "orderer.SeekOldest")
 	proto.RegisterType((*SeekSpecified)(nil), "orderer.SeekSpecified")
+	proto.RegisterType((*SeekTimestamp)(nil), "orderer.SeekTimestamp")
 	proto.RegisterType((*SeekPosition)(nil), "orderer.SeekPosition")
 	proto.RegisterType((*SeekInfo)(nil), "orderer.SeekInfo")
 	proto.RegisterType((*DeliverResponse)(nil), "orderer.DeliverResponse")
+	proto.RegisterType((*FilteredBlock)(nil), "orderer.FilteredBlock")
+	proto.RegisterType((*FilteredTransaction)(nil), "orderer.FilteredTransaction")
 	proto.RegisterEnum("orderer.SeekInfo_SeekBehavior", SeekInfo_SeekBehavior_name, SeekInfo_SeekBehavior_value)
+	proto.RegisterEnum("orderer.SeekInfo_SeekContentType", SeekInfo_SeekContentType_name, SeekInfo_SeekContentType_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -625,37 +834,55 @@ var _AtomicBroadcast_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("orderer/ab.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 505 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x7c, 0x93, 0xdf, 0x6e, 0x12, 0x51,
-	0x10, 0xc6, 0x59, 0xa5, 0xb4, 0x8c, 0x2d, 0xa5, 0xa7, 0x69, 0x43, 0xb8, 0x30, 0xcd, 0x26, 0x55,
-	0x8c, 0xba, 0x6b, 0x30, 0xf1, 0x42, 0x4d, 0x0c, 0x6b, 0xdb, 0x40, 0x24, 0x60, 0x16, 0x7a, 0xa1,
-	0x37, 0x64, 0x77, 0x19, 0x60, 0xed, 0xb2, 0x67, 0x73, 0xce, 0x01, 0xd3, 0xa7, 0xf0, 0x45, 0x7c,
-	0x24, 0x1f, 0xc6, 0x9c, 0x3f, 0xbb, 0x48, 0xd5, 0x5e, 0xb1, 0x33, 0xf3, 0xfb, 0xce, 0x7c, 0x33,
-	0x19, 0xa0, 0x4e, 0xd9, 0x14, 0x19, 0x32, 0x37, 0x08, 0x9d, 0x8c, 0x51, 0x41, 0xc9, 0xae, 0xc9,
-	0x34, 0x8f, 0x23, 0xba, 0x5c, 0xd2, 0xd4, 0xd5, 0x3f, 0xba, 0x6a, 0x0f, 0xe1, 0xc8, 0x63, 0x34,
-	0x98, 0x46, 0x01, 0x17, 0x3e, 0xf2, 0x8c, 0xa6, 0x1c, 0xc9, 0x13, 0xa8, 0x70, 0x11, 0x88, 0x15,
-	0x6f, 0x58, 0x67, 0x56, 0xab, 0xd6, 0xae, 0x39, 0x46, 0x33, 0x52, 0x59, 0xdf, 0x54, 0x09, 0x81,
-	0x72, 0x9c, 0xce, 0x68, 0xe3, 0xc1, 0x99, 0xd5, 0xaa, 0xfa, 0xea, 0xdb, 0xde, 0x07, 0x18, 0x21,
-	0xde, 0x0c, 0xf0, 0x3b, 0x72, 0x91, 0x47, 0xc3, 0x64, 0x2a, 0xa3, 0xa7, 0x70, 0x20, 0xa3, 0x51,
-	0x86, 0x51, 0x3c, 0x8b, 0x71, 0x4a, 0x4e, 0xa1, 0x92, 0xae, 0x96, 0x21, 0x32, 0xd5, 0xa8, 0xec,
-	0x9b, 0xc8, 0xfe, 0x69, 0xc1, 0xbe, 0x24, 0x3f, 0x53, 0x1e, 0x8b, 0x98, 0xa6, 0xe4, 0x25, 0x54,
-	0x52, 0xf5, 0xa2, 0x02, 0x1f, 0xb5, 0x8f, 0x1d, 0x33, 0x95, 0xb3, 0x69, 0xd6, 0x2d, 0xf9, 0x06,
-	0x92, 0x38, 0x55, 0x2d, 0x95, 0xb5, 0xbb, 0xb8, 0x76, 0x23, 0x71, 0x0d, 0x91, 0x37, 0x50, 0xe5,
-	0xb9, 0xa7, 0xc6, 0x43, 0xa5, 0x38, 0xdd, 0x52, 0x14, 0x8e, 0xbb, 0x25, 0x7f, 0x83, 0x7a, 0x15,
-	0x28, 0x8f, 0x6f, 0x33, 0xb4, 0x7f, 0x59, 0xb0, 0x27, 0xb1, 0x5e, 0x3a, 0xa3, 0xe4, 0x39, 0xec,
-	0x70, 0x11, 0xb0, 0xdc, 0xe9, 0xc9, 0xd6, 0x43, 0xf9, 0x40, 0xbe, 0x66, 0xc8, 0x33, 0x28, 0x73,
-	0x41, 0x33, 0x63, 0xf3, 0x3f, 0xac, 0x42, 0xc8, 0x5b, 0xd8, 0x0b, 0x71, 0x11, 0xac, 0x63, 0xca,
-	0x94, 0xc7, 0x5a, 0xfb, 0xf1, 0x16, 0x2e, 0x9b, 0xab, 0x0f, 0xcf, 0x50, 0x7e, 0xc1, 0xdb, 0xef,
-	0xf5, 0x3a, 0xf3, 0x0a, 0x39, 0x81, 0x23, 0xaf, 0x3f, 0xfc, 0xf8, 0x69, 0x72, 0x3d, 0x18, 0xf7,
-	0xfa, 0x13, 0xff, 0xb2, 0x73, 0xf1, 0xa5, 0x5e, 0x92, 0xe9, 0xab, 0x4e, 0xaf, 0x3f, 0xe9, 0x5d,
-	0x4d, 0x06, 0xc3, 0xb1, 0x49, 0x5b, 0xf6, 0x37, 0x38, 0xbc, 0xc0, 0x24, 0x5e, 0x23, 0x2b, 0x2e,
-	0xa4, 0x75, 0xff, 0x85, 0xc8, 0xdd, 0x9a, 0x1b, 0x39, 0x87, 0x9d, 0x30, 0xa1, 0xd1, 0x8d, 0x19,
-	0xf1, 0x20, 0x07, 0x3d, 0x99, 0xec, 0x96, 0x7c, 0x5d, 0xcd, 0x57, 0xd9, 0xfe, 0x61, 0xc1, 0x61,
-	0x47, 0xd0, 0x65, 0x1c, 0x15, 0x67, 0x49, 0x3e, 0x40, 0x75, 0x13, 0xd4, 0xf3, 0x07, 0x2e, 0xd3,
-	0x35, 0x26, 0x34, 0xc3, 0x66, 0xb3, 0x58, 0xc3, 0x5f, 0x97, 0x6c, 0x97, 0x5a, 0xd6, 0x2b, 0x8b,
-	0xbc, 0x83, 0x5d, 0x33, 0xc0, 0x3f, 0xe4, 0x8d, 0x42, 0x7e, 0x67, 0x48, 0x2d, 0xf6, 0xae, 0xe1,
-	0x9c, 0xb2, 0xb9, 0xb3, 0xb8, 0xcd, 0x90, 0x25, 0x38, 0x9d, 0x23, 0x73, 0x66, 0x41, 0xc8, 0xe2,
-	0x48, 0xff, 0x83, 0x78, 0x2e, 0xff, 0xfa, 0x62, 0x1e, 0x8b, 0xc5, 0x2a, 0x94, 0x0d, 0xdc, 0x3f,
-	0x68, 0x57, 0xd3, 0xae, 0xa6, 0x5d, 0x43, 0x87, 0x15, 0x15, 0xbf, 0xfe, 0x1d, 0x00, 0x00, 0xff,
-	0xff, 0x4b, 0x88, 0xa4, 0x39, 0xb1, 0x03, 0x00, 0x00,
+	// 788 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x95, 0xdd, 0x6e, 0xe3, 0x44,
+	0x14, 0xc7, 0xe3, 0x34, 0xcd, 0x6e, 0x4e, 0xdb, 0x34, 0x3b, 0xa5, 0xab, 0x28, 0xe2, 0xa3, 0x58,
+	0xda, 0x25, 0x68, 0xc1, 0x59, 0x05, 0x09, 0x21, 0x40, 0x5a, 0x35, 0x9b, 0x94, 0x58, 0x94, 0x16,
+	0xa6, 0x59, 0x21, 0xb8, 0xb1, 0xc6, 0xf6, 0x38, 0xb1, 0xd6, 0xf1, 0x58, 0x33, 0x93, 0x42, 0x9f,
+	0x82, 0x07, 0xe1, 0x29, 0x78, 0x17, 0x6e, 0x78, 0x0b, 0x34, 0x1f, 0x76, 0x92, 0x36, 0xea, 0x55,
+	0x7d, 0xce, 0xfc, 0xfe, 0x67, 0xce, 0x7f, 0x7a, 0x66, 0x02, 0x1d, 0xc6, 0x63, 0xca, 0x29, 0x1f,
+	0x90, 0xd0, 0x2b, 0x38, 0x93, 0x0c, 0x3d, 0xb1, 0x99, 0xde, 0x49, 0xc4, 0x96, 0x4b, 0x96, 0x0f,
+	0xcc, 0x1f, 0xb3, 0xda, 0xfb, 0x64, 0xce, 0xd8, 0x3c, 0xa3, 0x03, 0x1d, 0x85, 0xab, 0x64, 0x20,
+	0xd3, 0x25, 0x15, 0x92, 0x2c, 0x0b, 0x03, 0xb8, 0xd7, 0xf0, 0x6c, 0xc4, 0x19, 0x89, 0x23, 0x22,
+	0x24, 0xa6, 0xa2, 0x60, 0xb9, 0xa0, 0xe8, 0x25, 0x34, 0x85, 0x24, 0x72, 0x25, 0xba, 0xce, 0x99,
+	0xd3, 0x6f, 0x0f, 0xdb, 0x9e, 0x2d, 0x7a, 0xa3, 0xb3, 0xd8, 0xae, 0x22, 0x04, 0x8d, 0x34, 0x4f,
+	0x58, 0xb7, 0x7e, 0xe6, 0xf4, 0x5b, 0x58, 0x7f, 0xbb, 0x87, 0x00, 0x37, 0x94, 0xbe, 0xbf, 0xa2,
+	0x7f, 0x50, 0x21, 0xcb, 0xe8, 0x3a, 0x8b, 0x55, 0xf4, 0x19, 0x1c, 0xa9, 0xe8, 0xa6, 0xa0, 0x51,
+	0x9a, 0xa4, 0x34, 0x46, 0xcf, 0xa1, 0x99, 0xaf, 0x96, 0x21, 0xe5, 0x7a, 0xa3, 0x06, 0xb6, 0x91,
+	0xeb, 0x1b, 0x70, 0x56, 0x36, 0x8b, 0xbe, 0x81, 0x56, 0xd5, 0xb9, 0x66, 0x0f, 0x86, 0x3d, 0xcf,
+	0x78, 0xf3, 0x4a, 0x6f, 0x5e, 0x85, 0xe3, 0x35, 0xec, 0xfe, 0xeb, 0xc0, 0xa1, 0xaa, 0xf5, 0x33,
+	0x13, 0xa9, 0x4c, 0x59, 0x8e, 0xbe, 0x84, 0x66, 0xae, 0x9b, 0xb3, 0x75, 0x4e, 0x3c, 0x7b, 0x82,
+	0xde, 0xba, 0xef, 0x69, 0x0d, 0x5b, 0x48, 0xe1, 0x4c, 0x77, 0xdf, 0xad, 0xef, 0xc0, 0x8d, 0x31,
+	0x85, 0x1b, 0x08, 0x7d, 0x0d, 0x2d, 0x51, 0xda, 0xeb, 0xee, 0x69, 0xc5, 0xf3, 0x2d, 0x45, 0x65,
+	0x7e, 0x5a, 0xc3, 0x6b, 0x54, 0xe9, 0xd6, 0x06, 0x1b, 0x3b, 0x74, 0x95, 0x39, 0xa5, 0xab, 0xd0,
+	0x51, 0x13, 0x1a, 0xb3, 0xbb, 0x82, 0xba, 0xff, 0xd5, 0xe1, 0xa9, 0xc2, 0xfc, 0x3c, 0x61, 0xe8,
+	0x15, 0xec, 0x0b, 0x49, 0x78, 0xe9, 0xf0, 0x74, 0xab, 0x50, 0x79, 0x10, 0xd8, 0x30, 0xe8, 0x73,
+	0x68, 0x08, 0xc9, 0x8a, 0x6e, 0xfd, 0x31, 0x56, 0x23, 0xe8, 0x5b, 0x78, 0x1a, 0xd2, 0x05, 0xb9,
+	0x4d, 0x19, 0xd7, 0xde, 0xda, 0xc3, 0x8f, 0xb7, 0x70, 0xb5, 0xb9, 0xfe, 0x18, 0x59, 0x0a, 0x57,
+	0x3c, 0x1a, 0xc3, 0x61, 0xc4, 0x72, 0x49, 0x73, 0x19, 0xc8, 0xbb, 0x82, 0x6a, 0x8f, 0xed, 0xe1,
+	0xa7, 0xbb, 0xf5, 0x6f, 0x0d, 0xa9, 0x9c, 0xe1, 0x83, 0x68, 0x1d, 0xb8, 0xdf, 0xc3, 0xe1, 0x66,
+	0x7d, 0x74, 0x0a, 0xcf, 0x46, 0x97, 0xd7, 0x6f, 0x7f, 0x0c, 0xde, 0x5d, 0xcd, 0xfc, 0xcb, 0x00,
+	0x4f, 0xce, 0xc7, 0xbf, 0x75, 0x6a, 0x2a, 0x7d, 0x71, 0xee, 0x5f, 0x06, 0xfe, 0x45, 0x70, 0x75,
+	0x3d, 0xb3, 0x69, 0xc7, 0x9d, 0xc0, 0xf1, 0xbd, 0xea, 0xa8, 0x05, 0xfb, 0xba, 0x40, 0xa7, 0x86,
+	0x10, 0xb4, 0x2f, 0xfc, 0xcb, 0xd9, 0x04, 0x4f, 0xc6, 0x81, 0xc9, 0x39, 0xe8, 0x04, 0x8e, 0xa7,
+	0x93, 0xf3, 0xf1, 0x04, 0x07, 0xbf, 0xfa, 0xb3, 0x69, 0x70, 0xe3, 0xff, 0xd0, 0xa9, 0xbb, 0x7f,
+	0x3b, 0x70, 0x3c, 0xa6, 0x59, 0x7a, 0x4b, 0x79, 0x75, 0x65, 0xfa, 0x8f, 0x5f, 0x19, 0x35, 0x21,
+	0xf6, 0xd2, 0xbc, 0x80, 0xfd, 0x30, 0x63, 0xd1, 0x7b, 0x7b, 0xe0, 0x47, 0x25, 0x38, 0x52, 0xc9,
+	0x69, 0x0d, 0x9b, 0x55, 0xf4, 0x06, 0xda, 0x49, 0x9a, 0x49, 0xca, 0x69, 0x1c, 0x18, 0xfe, 0xfe,
+	0x34, 0x5d, 0xd8, 0xe5, 0x52, 0x78, 0x94, 0x6c, 0x26, 0xaa, 0xc9, 0xf8, 0xc7, 0x81, 0xa3, 0x2d,
+	0x14, 0xbd, 0x82, 0xe6, 0x82, 0x92, 0xd8, 0xde, 0x3a, 0x35, 0xd2, 0x5b, 0x2d, 0xe8, 0x25, 0x6c,
+	0x11, 0xf4, 0x1a, 0x40, 0xa4, 0xf3, 0x9c, 0xc8, 0x15, 0xa7, 0xc2, 0xf6, 0xdc, 0x29, 0x05, 0x3f,
+	0x51, 0x49, 0x62, 0x22, 0x09, 0xde, 0x60, 0xd0, 0x2f, 0x70, 0x5a, 0x75, 0x2e, 0x39, 0xc9, 0x05,
+	0x89, 0xd4, 0x0c, 0x89, 0xee, 0xde, 0xd9, 0x5e, 0xff, 0x60, 0xf8, 0xe1, 0x03, 0x03, 0xb3, 0x35,
+	0x84, 0x3f, 0x48, 0x1e, 0x26, 0x85, 0x5b, 0xc0, 0xc9, 0x0e, 0x58, 0xbd, 0x3f, 0xf2, 0xcf, 0x34,
+	0xd6, 0x36, 0x5a, 0x58, 0x7f, 0xa3, 0x97, 0xd0, 0xd0, 0xf3, 0x55, 0xd7, 0xff, 0x06, 0x54, 0x76,
+	0x6a, 0x5c, 0xe9, 0x81, 0xd2, 0xeb, 0xe8, 0x23, 0x80, 0x68, 0x41, 0xf2, 0x9c, 0x66, 0x41, 0x6a,
+	0x6e, 0x6a, 0x0b, 0xb7, 0x6c, 0xc6, 0x8f, 0x87, 0x7f, 0x39, 0x70, 0x7c, 0x2e, 0xd9, 0x32, 0x8d,
+	0xaa, 0xe7, 0x11, 0xbd, 0x81, 0xd6, 0x3a, 0xa8, 0xce, 0x60, 0x92, 0xdf, 0xd2, 0x8c, 0x15, 0xb4,
+	0xd7, 0xab, 0x8c, 0x3d, 0x78, 0x51, 0xdd, 0x5a, 0xdf, 0x79, 0xed, 0xa0, 0xef, 0xe0, 0x89, 0x9d,
+	0x9b, 0x1d, 0xf2, 0x6e, 0x25, 0xbf, 0x37, 0x5b, 0x46, 0x3c, 0x7a, 0x07, 0x2f, 0x18, 0x9f, 0x7b,
+	0x8b, 0xbb, 0x82, 0xf2, 0x8c, 0xc6, 0x73, 0xca, 0xbd, 0x84, 0x84, 0x3c, 0x8d, 0xcc, 0x03, 0x28,
+	0x4a, 0xf9, 0xef, 0x5f, 0xcc, 0x53, 0xb9, 0x58, 0x85, 0x6a, 0x83, 0xc1, 0x06, 0x3d, 0x30, 0xb4,
+	0xf9, 0x29, 0x10, 0x03, 0x4b, 0x87, 0x4d, 0x1d, 0x7f, 0xf5, 0xff, 0x00, 0xe7, 0x30, 0x45, 0xc0,
+	0x5a, 0x06, 0x00, 0x00,
 }
@@ -109,6 +109,10 @@ func (docv *DynamicOrdererConfigValue) VariablyOpaqueFieldProto(name string) (pr
 		return &KafkaBrokers{}, nil
 	case "ChannelRestrictions":
 		return &ChannelRestrictions{}, nil
+	case "RateLimits":
+		return &RateLimits{}, nil
+	case "BatchCutting":
+		return &BatchCutting{}, nil
 	default:
 		return nil, fmt.Errorf("unknown Orderer ConfigValue name: %s", docv.name)
 	}
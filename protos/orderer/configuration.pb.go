@@ -12,14 +12,59 @@ var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
+type ConsensusType_State int32
+
+const (
+	ConsensusType_STATE_NORMAL      ConsensusType_State = 0
+	ConsensusType_STATE_MAINTENANCE ConsensusType_State = 1
+)
+
+var ConsensusType_State_name = map[int32]string{
+	0: "STATE_NORMAL",
+	1: "STATE_MAINTENANCE",
+}
+var ConsensusType_State_value = map[string]int32{
+	"STATE_NORMAL":      0,
+	"STATE_MAINTENANCE": 1,
+}
+
+func (x ConsensusType_State) String() string {
+	return proto.EnumName(ConsensusType_State_name, int32(x))
+}
+func (ConsensusType_State) EnumDescriptor() ([]byte, []int) { return fileDescriptor3, []int{0, 0} }
+
+type BatchCutting_Strategy int32
+
+const (
+	BatchCutting_FIXED    BatchCutting_Strategy = 0
+	BatchCutting_ADAPTIVE BatchCutting_Strategy = 1
+)
+
+var BatchCutting_Strategy_name = map[int32]string{
+	0: "FIXED",
+	1: "ADAPTIVE",
+}
+var BatchCutting_Strategy_value = map[string]int32{
+	"FIXED":    0,
+	"ADAPTIVE": 1,
+}
+
+func (x BatchCutting_Strategy) String() string {
+	return proto.EnumName(BatchCutting_Strategy_name, int32(x))
+}
+func (BatchCutting_Strategy) EnumDescriptor() ([]byte, []int) { return fileDescriptor3, []int{7, 0} }
+
 type ConsensusType struct {
 	Type string `protobuf:"bytes,1,opt,name=type" json:"type,omitempty"`
+	// The consensus type may only be changed while the channel is, and
+	// remains, in maintenance
+	State ConsensusType_State `protobuf:"varint,2,opt,name=state,enum=orderer.ConsensusType_State" json:"state,omitempty"`
 }
 
 func (m *ConsensusType) Reset()                    { *m = ConsensusType{} }
 func (m *ConsensusType) String() string            { return proto.CompactTextString(m) }
 func (*ConsensusType) ProtoMessage()               {}
-func (*ConsensusType) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{0} }
+func (*ConsensusType) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{0} }
 
 func (m *ConsensusType) GetType() string {
 	if m != nil {
@@ -28,6 +73,13 @@ func (m *ConsensusType) GetType() string {
 	return ""
 }
 
+func (m *ConsensusType) GetState() ConsensusType_State {
+	if m != nil {
+		return m.State
+	}
+	return ConsensusType_STATE_NORMAL
+}
+
 type BatchSize struct {
 	// Simply specified as number of messages for now, in the future
 	// we may want to allow this to be specified by size in bytes
@@ -43,7 +95,7 @@ type BatchSize struct {
 func (m *BatchSize) Reset()                    { *m = BatchSize{} }
 func (m *BatchSize) String() string            { return proto.CompactTextString(m) }
 func (*BatchSize) ProtoMessage()               {}
-func (*BatchSize) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{1} }
+func (*BatchSize) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{1} }
 
 func (m *BatchSize) GetMaxMessageCount() uint32 {
 	if m != nil {
@@ -75,7 +127,7 @@ type BatchTimeout struct {
 func (m *BatchTimeout) Reset()                    { *m = BatchTimeout{} }
 func (m *BatchTimeout) String() string            { return proto.CompactTextString(m) }
 func (*BatchTimeout) ProtoMessage()               {}
-func (*BatchTimeout) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{2} }
+func (*BatchTimeout) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{2} }
 
 func (m *BatchTimeout) GetTimeout() string {
 	if m != nil {
@@ -95,7 +147,7 @@ type KafkaBrokers struct {
 func (m *KafkaBrokers) Reset()                    { *m = KafkaBrokers{} }
 func (m *KafkaBrokers) String() string            { return proto.CompactTextString(m) }
 func (*KafkaBrokers) ProtoMessage()               {}
-func (*KafkaBrokers) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{3} }
+func (*KafkaBrokers) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{3} }
 
 func (m *KafkaBrokers) GetBrokers() []string {
 	if m != nil {
@@ -112,7 +164,7 @@ type ChannelRestrictions struct {
 func (m *ChannelRestrictions) Reset()                    { *m = ChannelRestrictions{} }
 func (m *ChannelRestrictions) String() string            { return proto.CompactTextString(m) }
 func (*ChannelRestrictions) ProtoMessage()               {}
-func (*ChannelRestrictions) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{4} }
+func (*ChannelRestrictions) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{4} }
 
 func (m *ChannelRestrictions) GetMaxCount() uint64 {
 	if m != nil {
@@ -121,36 +173,159 @@ func (m *ChannelRestrictions) GetMaxCount() uint64 {
 	return 0
 }
 
+// RateLimits carries the token bucket limits applied to the messages
+// broadcast on the channel, keyed by the MSP ID of their creator
+type RateLimits struct {
+	// The limits, at most one per MSP ID. The limit with an empty MSP ID,
+	// if any, applies to the MSPs which have no limit of their own
+	Limits []*RateLimit `protobuf:"bytes,1,rep,name=limits" json:"limits,omitempty"`
+	// Whether each identity of an MSP has a bucket of its own, rather than
+	// sharing the bucket of its MSP
+	PerIdentity bool `protobuf:"varint,2,opt,name=per_identity,json=perIdentity" json:"per_identity,omitempty"`
+}
+
+func (m *RateLimits) Reset()                    { *m = RateLimits{} }
+func (m *RateLimits) String() string            { return proto.CompactTextString(m) }
+func (*RateLimits) ProtoMessage()               {}
+func (*RateLimits) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{5} }
+
+func (m *RateLimits) GetLimits() []*RateLimit {
+	if m != nil {
+		return m.Limits
+	}
+	return nil
+}
+
+func (m *RateLimits) GetPerIdentity() bool {
+	if m != nil {
+		return m.PerIdentity
+	}
+	return false
+}
+
+type RateLimit struct {
+	MspId string `protobuf:"bytes,1,opt,name=msp_id,json=mspId" json:"msp_id,omitempty"`
+	// The number of messages per second the bucket is refilled with,
+	// a value of 0 indicates no limit
+	Rate uint32 `protobuf:"varint,2,opt,name=rate" json:"rate,omitempty"`
+	// The number of messages the bucket holds at most
+	Burst uint32 `protobuf:"varint,3,opt,name=burst" json:"burst,omitempty"`
+}
+
+func (m *RateLimit) Reset()                    { *m = RateLimit{} }
+func (m *RateLimit) String() string            { return proto.CompactTextString(m) }
+func (*RateLimit) ProtoMessage()               {}
+func (*RateLimit) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{6} }
+
+func (m *RateLimit) GetMspId() string {
+	if m != nil {
+		return m.MspId
+	}
+	return ""
+}
+
+func (m *RateLimit) GetRate() uint32 {
+	if m != nil {
+		return m.Rate
+	}
+	return 0
+}
+
+func (m *RateLimit) GetBurst() uint32 {
+	if m != nil {
+		return m.Burst
+	}
+	return 0
+}
+
+// BatchCutting selects the strategy used to cut the batches of the channel
+type BatchCutting struct {
+	Strategy BatchCutting_Strategy `protobuf:"varint,1,opt,name=strategy,enum=orderer.BatchCutting_Strategy" json:"strategy,omitempty"`
+	// The lower bounds of the effective message count and timeout of the
+	// ADAPTIVE strategy, whose upper bounds are the MaxMessageCount of the
+	// BatchSize and the BatchTimeout
+	MinMessageCount uint32 `protobuf:"varint,2,opt,name=min_message_count,json=minMessageCount" json:"min_message_count,omitempty"`
+	// Any duration string parseable by ParseDuration()
+	MinTimeout string `protobuf:"bytes,3,opt,name=min_timeout,json=minTimeout" json:"min_timeout,omitempty"`
+}
+
+func (m *BatchCutting) Reset()                    { *m = BatchCutting{} }
+func (m *BatchCutting) String() string            { return proto.CompactTextString(m) }
+func (*BatchCutting) ProtoMessage()               {}
+func (*BatchCutting) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{7} }
+
+func (m *BatchCutting) GetStrategy() BatchCutting_Strategy {
+	if m != nil {
+		return m.Strategy
+	}
+	return BatchCutting_FIXED
+}
+
+func (m *BatchCutting) GetMinMessageCount() uint32 {
+	if m != nil {
+		return m.MinMessageCount
+	}
+	return 0
+}
+
+func (m *BatchCutting) GetMinTimeout() string {
+	if m != nil {
+		return m.MinTimeout
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*ConsensusType)(nil), "orderer.ConsensusType")
 	proto.RegisterType((*BatchSize)(nil), "orderer.BatchSize")
 	proto.RegisterType((*BatchTimeout)(nil), "orderer.BatchTimeout")
 	proto.RegisterType((*KafkaBrokers)(nil), "orderer.KafkaBrokers")
 	proto.RegisterType((*ChannelRestrictions)(nil), "orderer.ChannelRestrictions")
+	proto.RegisterType((*RateLimits)(nil), "orderer.RateLimits")
+	proto.RegisterType((*RateLimit)(nil), "orderer.RateLimit")
+	proto.RegisterType((*BatchCutting)(nil), "orderer.BatchCutting")
+	proto.RegisterEnum("orderer.ConsensusType_State", ConsensusType_State_name, ConsensusType_State_value)
+	proto.RegisterEnum("orderer.BatchCutting_Strategy", BatchCutting_Strategy_name, BatchCutting_Strategy_value)
 }
 
-func init() { proto.RegisterFile("orderer/configuration.proto", fileDescriptor1) }
-
-var fileDescriptor1 = []byte{
-	// 313 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x4c, 0xd0, 0xcd, 0x4a, 0xc3, 0x40,
-	0x10, 0x07, 0x70, 0x62, 0x8b, 0xb5, 0x8b, 0x45, 0xbb, 0xbd, 0x04, 0x7a, 0x29, 0x11, 0xa1, 0x48,
-	0x49, 0x40, 0xdf, 0x20, 0x3d, 0x4a, 0x2f, 0xb1, 0x5e, 0xbc, 0x94, 0x4d, 0x3a, 0x49, 0x96, 0x36,
-	0x3b, 0x61, 0x76, 0x03, 0x89, 0xef, 0xe1, 0xfb, 0xca, 0x6e, 0x52, 0xed, 0x6d, 0x3e, 0x7e, 0x0b,
-	0xb3, 0x7f, 0xb6, 0x44, 0x3a, 0x02, 0x01, 0x45, 0x19, 0xaa, 0x5c, 0x16, 0x0d, 0x09, 0x23, 0x51,
-	0x85, 0x35, 0xa1, 0x41, 0x3e, 0x19, 0x96, 0xc1, 0x13, 0x9b, 0x6d, 0x51, 0x69, 0x50, 0xba, 0xd1,
-	0xfb, 0xae, 0x06, 0xce, 0xd9, 0xd8, 0x74, 0x35, 0xf8, 0xde, 0xca, 0x5b, 0x4f, 0x13, 0x57, 0x07,
-	0x3f, 0x1e, 0x9b, 0xc6, 0xc2, 0x64, 0xe5, 0x87, 0xfc, 0x06, 0xfe, 0xc2, 0xe6, 0x95, 0x68, 0x0f,
-	0x15, 0x68, 0x2d, 0x0a, 0x38, 0x64, 0xd8, 0x28, 0xe3, 0xf8, 0x2c, 0x79, 0xa8, 0x44, 0xbb, 0xeb,
-	0xe7, 0x5b, 0x3b, 0xe6, 0x1b, 0xc6, 0x45, 0xaa, 0xf1, 0xdc, 0x18, 0x38, 0xd8, 0x47, 0x69, 0x67,
-	0x40, 0xfb, 0x37, 0x0e, 0x3f, 0x5e, 0x36, 0x3b, 0xd1, 0xc6, 0x76, 0xce, 0x43, 0xb6, 0xa8, 0x09,
-	0x72, 0x20, 0x82, 0xe3, 0x15, 0x1f, 0x39, 0x3e, 0xff, 0x5b, 0x5d, 0x7c, 0xb0, 0x66, 0xf7, 0xee,
-	0xac, 0xbd, 0xac, 0x00, 0x1b, 0xc3, 0x7d, 0x36, 0x31, 0x7d, 0x39, 0x9c, 0x7f, 0x69, 0xad, 0x7c,
-	0x17, 0xf9, 0x49, 0xc4, 0x84, 0x27, 0x20, 0x6d, 0x65, 0xda, 0x97, 0xbe, 0xb7, 0x1a, 0x59, 0x39,
-	0xb4, 0xc1, 0x2b, 0x5b, 0x6c, 0x4b, 0xa1, 0x14, 0x9c, 0x13, 0xd0, 0x86, 0x64, 0x66, 0x53, 0xd3,
-	0x7c, 0xc9, 0xa6, 0xf6, 0xa0, 0xff, 0xcf, 0x8e, 0x93, 0xbb, 0x4a, 0xb4, 0xee, 0x97, 0xf1, 0x27,
-	0x7b, 0x46, 0x2a, 0xc2, 0xb2, 0xab, 0x81, 0xce, 0x70, 0x2c, 0x80, 0xc2, 0x5c, 0xa4, 0x24, 0xb3,
-	0x3e, 0x6d, 0x1d, 0x0e, 0x69, 0x7f, 0x6d, 0x0a, 0x69, 0xca, 0x26, 0x0d, 0x33, 0xac, 0xa2, 0x2b,
-	0x1d, 0xf5, 0x3a, 0xea, 0x75, 0x34, 0xe8, 0xf4, 0xd6, 0xf5, 0x6f, 0xbf, 0x01, 0x00, 0x00, 0xff,
-	0xff, 0x0c, 0x47, 0xa2, 0x55, 0xca, 0x01, 0x00, 0x00,
+func init() { proto.RegisterFile("orderer/configuration.proto", fileDescriptor3) }
+
+var fileDescriptor3 = []byte{
+	// 561 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x5c, 0x93, 0xd1, 0x6e, 0x1a, 0x3b,
+	0x10, 0x86, 0xb3, 0x49, 0x48, 0x60, 0x02, 0x39, 0xc4, 0x39, 0x91, 0x90, 0x52, 0xb5, 0xe9, 0x56,
+	0x95, 0xa2, 0x28, 0x5a, 0x2a, 0x7a, 0xd7, 0x3b, 0x20, 0x54, 0x42, 0x0d, 0xb4, 0x32, 0xb4, 0xaa,
+	0xda, 0x0b, 0xe4, 0x85, 0x61, 0xb1, 0xc2, 0xda, 0x2b, 0xdb, 0x2b, 0x41, 0xdf, 0xa0, 0x0f, 0xd0,
+	0xb7, 0xe9, 0xc3, 0x55, 0xf6, 0x7a, 0x69, 0xd2, 0xbb, 0x99, 0xf1, 0x37, 0xde, 0xdf, 0x33, 0xff,
+	0xc2, 0xa5, 0x54, 0x0b, 0x54, 0xa8, 0xda, 0x73, 0x29, 0x96, 0x3c, 0xc9, 0x15, 0x33, 0x5c, 0x8a,
+	0x28, 0x53, 0xd2, 0x48, 0x72, 0xec, 0x0f, 0xc3, 0x9f, 0x01, 0x34, 0xfa, 0x52, 0x68, 0x14, 0x3a,
+	0xd7, 0xd3, 0x6d, 0x86, 0x84, 0xc0, 0xa1, 0xd9, 0x66, 0xd8, 0x0a, 0xae, 0x82, 0xeb, 0x1a, 0x75,
+	0x31, 0xe9, 0x40, 0x45, 0x1b, 0x66, 0xb0, 0xb5, 0x7f, 0x15, 0x5c, 0x9f, 0x76, 0x9e, 0x45, 0xbe,
+	0x3d, 0x7a, 0xd2, 0x1a, 0x4d, 0x2c, 0x43, 0x0b, 0x34, 0x7c, 0x03, 0x15, 0x97, 0x93, 0x26, 0xd4,
+	0x27, 0xd3, 0xee, 0x74, 0x30, 0x1b, 0x7f, 0xa4, 0xa3, 0xee, 0x7d, 0x73, 0x8f, 0x5c, 0xc0, 0x59,
+	0x51, 0x19, 0x75, 0x87, 0xe3, 0xe9, 0x60, 0xdc, 0x1d, 0xf7, 0x07, 0xcd, 0x20, 0xfc, 0x15, 0x40,
+	0xad, 0xc7, 0xcc, 0x7c, 0x35, 0xe1, 0x3f, 0x90, 0xdc, 0xc0, 0x59, 0xca, 0x36, 0xb3, 0x14, 0xb5,
+	0x66, 0x09, 0xce, 0xe6, 0x32, 0x17, 0xc6, 0x89, 0x6a, 0xd0, 0xff, 0x52, 0xb6, 0x19, 0x15, 0xf5,
+	0xbe, 0x2d, 0x93, 0x5b, 0x20, 0x2c, 0xd6, 0x72, 0x9d, 0x1b, 0x9c, 0xd9, 0xa6, 0x78, 0x6b, 0x50,
+	0x3b, 0xb1, 0x0d, 0xda, 0x2c, 0x4f, 0x46, 0x6c, 0xd3, 0xb3, 0x75, 0x12, 0xc1, 0x79, 0xa6, 0x70,
+	0x89, 0x4a, 0xe1, 0xe2, 0x11, 0x7e, 0xe0, 0xf0, 0xb3, 0xdd, 0x51, 0xc9, 0x87, 0xd7, 0x50, 0x77,
+	0xb2, 0xa6, 0x3c, 0x45, 0x99, 0x1b, 0xd2, 0x82, 0x63, 0x53, 0x84, 0x7e, 0x48, 0x65, 0x6a, 0xc9,
+	0x0f, 0x6c, 0xf9, 0xc0, 0x7a, 0x4a, 0x3e, 0xa0, 0xd2, 0x96, 0x8c, 0x8b, 0xb0, 0x15, 0x5c, 0x1d,
+	0x58, 0xd2, 0xa7, 0x61, 0x07, 0xce, 0xfb, 0x2b, 0x26, 0x04, 0xae, 0x29, 0x6a, 0xa3, 0xf8, 0xdc,
+	0x2e, 0x47, 0x93, 0x4b, 0xa8, 0x59, 0x41, 0x7f, 0x1f, 0x7b, 0x48, 0xab, 0x29, 0xdb, 0xb8, 0x57,
+	0x86, 0xdf, 0x01, 0x28, 0x33, 0x78, 0xcf, 0x53, 0x6e, 0x34, 0xb9, 0x81, 0xa3, 0xb5, 0x8b, 0xdc,
+	0xd5, 0x27, 0x1d, 0xb2, 0x5b, 0xca, 0x0e, 0xa2, 0x9e, 0x20, 0x2f, 0xa1, 0x9e, 0xa1, 0x9a, 0xf1,
+	0x05, 0x0a, 0xc3, 0xcd, 0xd6, 0x4d, 0xa6, 0x4a, 0x4f, 0x32, 0x54, 0x43, 0x5f, 0x0a, 0xef, 0xa1,
+	0xb6, 0xeb, 0x23, 0x17, 0x70, 0x94, 0xea, 0x6c, 0xc6, 0x17, 0xfe, 0x81, 0x95, 0x54, 0x67, 0xc3,
+	0x85, 0xb5, 0x86, 0x2a, 0x5d, 0xd0, 0xa0, 0x2e, 0x26, 0xff, 0x43, 0x25, 0xce, 0x95, 0x36, 0x7e,
+	0x7c, 0x45, 0x12, 0xfe, 0x0e, 0xfc, 0xcc, 0xfa, 0xb9, 0x31, 0x5c, 0x24, 0xe4, 0x1d, 0x54, 0xb5,
+	0xb1, 0x0d, 0xc9, 0xd6, 0xdd, 0x79, 0xda, 0x79, 0xbe, 0xd3, 0xfb, 0x18, 0x8c, 0x26, 0x9e, 0xa2,
+	0x3b, 0xde, 0x39, 0x81, 0x8b, 0x7f, 0x9c, 0xb0, 0xef, 0x9d, 0xc0, 0xc5, 0x13, 0x27, 0xbc, 0x80,
+	0x13, 0xcb, 0x96, 0xfb, 0x39, 0x70, 0xf2, 0x21, 0xe5, 0xc2, 0x2f, 0x2f, 0x7c, 0x05, 0xd5, 0xf2,
+	0x13, 0xa4, 0x06, 0x95, 0xf7, 0xc3, 0xaf, 0x83, 0xbb, 0xe6, 0x1e, 0xa9, 0x43, 0xb5, 0x7b, 0xd7,
+	0xfd, 0x34, 0x1d, 0x7e, 0x19, 0x34, 0x83, 0xde, 0x67, 0x78, 0x2d, 0x55, 0x12, 0xad, 0xb6, 0x19,
+	0xaa, 0x35, 0x2e, 0x12, 0x54, 0xd1, 0x92, 0xc5, 0x8a, 0xcf, 0x8b, 0xdf, 0x47, 0x97, 0xd2, 0xbf,
+	0xdd, 0x26, 0xdc, 0xac, 0xf2, 0x38, 0x9a, 0xcb, 0xb4, 0xfd, 0x88, 0x6e, 0x17, 0x74, 0xbb, 0xa0,
+	0xdb, 0x9e, 0x8e, 0x8f, 0x5c, 0xfe, 0xf6, 0xcf, 0x00, 0x0a, 0xaa, 0x31, 0xd5, 0x9b, 0x03, 0x00,
+	0x00,
 }
@@ -12,6 +12,32 @@ var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
+type KafkaMessageRegular_Class int32
+
+const (
+	KafkaMessageRegular_UNKNOWN KafkaMessageRegular_Class = 0
+	KafkaMessageRegular_NORMAL  KafkaMessageRegular_Class = 1
+	KafkaMessageRegular_CONFIG  KafkaMessageRegular_Class = 2
+)
+
+var KafkaMessageRegular_Class_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "NORMAL",
+	2: "CONFIG",
+}
+var KafkaMessageRegular_Class_value = map[string]int32{
+	"UNKNOWN": 0,
+	"NORMAL":  1,
+	"CONFIG":  2,
+}
+
+func (x KafkaMessageRegular_Class) String() string {
+	return proto.EnumName(KafkaMessageRegular_Class_name, int32(x))
+}
+func (KafkaMessageRegular_Class) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor4, []int{1, 0}
+}
+
 // KafkaMessage is a wrapper type for the messages
 // that the Kafka-based orderer deals with.
 type KafkaMessage struct {
@@ -25,7 +51,7 @@ type KafkaMessage struct {
 func (m *KafkaMessage) Reset()                    { *m = KafkaMessage{} }
 func (m *KafkaMessage) String() string            { return proto.CompactTextString(m) }
 func (*KafkaMessage) ProtoMessage()               {}
-func (*KafkaMessage) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{0} }
+func (*KafkaMessage) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{0} }
 
 type isKafkaMessage_Type interface {
 	isKafkaMessage_Type()
@@ -169,12 +195,24 @@ func _KafkaMessage_OneofSizer(msg proto.Message) (n int) {
 // KafkaMessageRegular wraps a marshalled envelope.
 type KafkaMessageRegular struct {
 	Payload []byte `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	// The config sequence the envelope was validated against
+	ConfigSeq uint64 `protobuf:"varint,2,opt,name=config_seq,json=configSeq" json:"config_seq,omitempty"`
+	// Whether the envelope is a normal or a config message. Messages
+	// posted by orderers which do not set it are UNKNOWN.
+	Class KafkaMessageRegular_Class `protobuf:"varint,3,opt,name=class,enum=orderer.KafkaMessageRegular_Class" json:"class,omitempty"`
+	// For config messages, the config update the envelope was
+	// computed from, so that it can be computed again if the config
+	// of the channel changes before the envelope is processed
+	ConfigUpdate []byte `protobuf:"bytes,4,opt,name=config_update,json=configUpdate,proto3" json:"config_update,omitempty"`
+	// For config messages re-submitted because the config of the
+	// channel changed, the offset of the message first submitted
+	OriginalOffset int64 `protobuf:"varint,5,opt,name=original_offset,json=originalOffset" json:"original_offset,omitempty"`
 }
 
 func (m *KafkaMessageRegular) Reset()                    { *m = KafkaMessageRegular{} }
 func (m *KafkaMessageRegular) String() string            { return proto.CompactTextString(m) }
 func (*KafkaMessageRegular) ProtoMessage()               {}
-func (*KafkaMessageRegular) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{1} }
+func (*KafkaMessageRegular) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{1} }
 
 func (m *KafkaMessageRegular) GetPayload() []byte {
 	if m != nil {
@@ -183,6 +221,34 @@ func (m *KafkaMessageRegular) GetPayload() []byte {
 	return nil
 }
 
+func (m *KafkaMessageRegular) GetConfigSeq() uint64 {
+	if m != nil {
+		return m.ConfigSeq
+	}
+	return 0
+}
+
+func (m *KafkaMessageRegular) GetClass() KafkaMessageRegular_Class {
+	if m != nil {
+		return m.Class
+	}
+	return KafkaMessageRegular_UNKNOWN
+}
+
+func (m *KafkaMessageRegular) GetConfigUpdate() []byte {
+	if m != nil {
+		return m.ConfigUpdate
+	}
+	return nil
+}
+
+func (m *KafkaMessageRegular) GetOriginalOffset() int64 {
+	if m != nil {
+		return m.OriginalOffset
+	}
+	return 0
+}
+
 // KafkaMessageTimeToCut is used to signal to the orderers
 // that it is time to cut block <block_number>.
 type KafkaMessageTimeToCut struct {
@@ -192,7 +258,7 @@ type KafkaMessageTimeToCut struct {
 func (m *KafkaMessageTimeToCut) Reset()                    { *m = KafkaMessageTimeToCut{} }
 func (m *KafkaMessageTimeToCut) String() string            { return proto.CompactTextString(m) }
 func (*KafkaMessageTimeToCut) ProtoMessage()               {}
-func (*KafkaMessageTimeToCut) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{2} }
+func (*KafkaMessageTimeToCut) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{2} }
 
 func (m *KafkaMessageTimeToCut) GetBlockNumber() uint64 {
 	if m != nil {
@@ -212,7 +278,7 @@ type KafkaMessageConnect struct {
 func (m *KafkaMessageConnect) Reset()                    { *m = KafkaMessageConnect{} }
 func (m *KafkaMessageConnect) String() string            { return proto.CompactTextString(m) }
 func (*KafkaMessageConnect) ProtoMessage()               {}
-func (*KafkaMessageConnect) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{3} }
+func (*KafkaMessageConnect) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{3} }
 
 func (m *KafkaMessageConnect) GetPayload() []byte {
 	if m != nil {
@@ -226,12 +292,16 @@ func (m *KafkaMessageConnect) GetPayload() []byte {
 // of the Kafka-based orderer.
 type KafkaMetadata struct {
 	LastOffsetPersisted int64 `protobuf:"varint,1,opt,name=last_offset_persisted,json=lastOffsetPersisted" json:"last_offset_persisted,omitempty"`
+	// The config messages re-submitted because the config of the channel
+	// changed, and not processed yet, so that their duplicates and their
+	// outdated copies are discarded
+	PendingResubmissions []*KafkaResubmission `protobuf:"bytes,2,rep,name=pending_resubmissions,json=pendingResubmissions" json:"pending_resubmissions,omitempty"`
 }
 
 func (m *KafkaMetadata) Reset()                    { *m = KafkaMetadata{} }
 func (m *KafkaMetadata) String() string            { return proto.CompactTextString(m) }
 func (*KafkaMetadata) ProtoMessage()               {}
-func (*KafkaMetadata) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{4} }
+func (*KafkaMetadata) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{4} }
 
 func (m *KafkaMetadata) GetLastOffsetPersisted() int64 {
 	if m != nil {
@@ -240,36 +310,84 @@ func (m *KafkaMetadata) GetLastOffsetPersisted() int64 {
 	return 0
 }
 
+func (m *KafkaMetadata) GetPendingResubmissions() []*KafkaResubmission {
+	if m != nil {
+		return m.PendingResubmissions
+	}
+	return nil
+}
+
+// KafkaResubmission records a config message re-submitted because the
+// config of the channel changed.
+type KafkaResubmission struct {
+	// The offset of the message first submitted
+	OriginalOffset int64 `protobuf:"varint,1,opt,name=original_offset,json=originalOffset" json:"original_offset,omitempty"`
+	// The config sequence the latest re-submission was validated against
+	ConfigSeq uint64 `protobuf:"varint,2,opt,name=config_seq,json=configSeq" json:"config_seq,omitempty"`
+}
+
+func (m *KafkaResubmission) Reset()                    { *m = KafkaResubmission{} }
+func (m *KafkaResubmission) String() string            { return proto.CompactTextString(m) }
+func (*KafkaResubmission) ProtoMessage()               {}
+func (*KafkaResubmission) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{5} }
+
+func (m *KafkaResubmission) GetOriginalOffset() int64 {
+	if m != nil {
+		return m.OriginalOffset
+	}
+	return 0
+}
+
+func (m *KafkaResubmission) GetConfigSeq() uint64 {
+	if m != nil {
+		return m.ConfigSeq
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*KafkaMessage)(nil), "orderer.KafkaMessage")
 	proto.RegisterType((*KafkaMessageRegular)(nil), "orderer.KafkaMessageRegular")
 	proto.RegisterType((*KafkaMessageTimeToCut)(nil), "orderer.KafkaMessageTimeToCut")
 	proto.RegisterType((*KafkaMessageConnect)(nil), "orderer.KafkaMessageConnect")
 	proto.RegisterType((*KafkaMetadata)(nil), "orderer.KafkaMetadata")
+	proto.RegisterType((*KafkaResubmission)(nil), "orderer.KafkaResubmission")
+	proto.RegisterEnum("orderer.KafkaMessageRegular_Class", KafkaMessageRegular_Class_name, KafkaMessageRegular_Class_value)
 }
 
-func init() { proto.RegisterFile("orderer/kafka.proto", fileDescriptor2) }
-
-var fileDescriptor2 = []byte{
-	// 316 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x7c, 0x91, 0x4f, 0x6b, 0xc2, 0x40,
-	0x10, 0xc5, 0xb5, 0x8a, 0xd2, 0xd1, 0x5e, 0x22, 0x42, 0x0e, 0xa5, 0xb4, 0x42, 0xa1, 0x87, 0x92,
-	0x80, 0xbd, 0x94, 0x9e, 0x8a, 0x5e, 0x84, 0xd2, 0x3f, 0x2c, 0xf6, 0xd2, 0x4b, 0xd8, 0x6c, 0x26,
-	0x31, 0x98, 0xb8, 0x61, 0x77, 0x72, 0xf0, 0x3b, 0xf6, 0x43, 0x95, 0xec, 0x6e, 0x40, 0x4a, 0xf0,
-	0x38, 0xf3, 0x7e, 0x8f, 0xf7, 0x76, 0x16, 0x66, 0x52, 0x25, 0xa8, 0x50, 0x85, 0x7b, 0x9e, 0xee,
-	0x79, 0x50, 0x29, 0x49, 0xd2, 0x1b, 0xbb, 0xe5, 0xe2, 0xb7, 0x0f, 0xd3, 0xb7, 0x46, 0x78, 0x47,
-	0xad, 0x79, 0x86, 0xde, 0x33, 0x8c, 0x15, 0x66, 0x75, 0xc1, 0x95, 0xdf, 0xbf, 0xed, 0x3f, 0x4c,
-	0x96, 0xd7, 0x81, 0x63, 0x83, 0x53, 0x8e, 0x59, 0x66, 0xd3, 0x63, 0x2d, 0xee, 0xbd, 0xc2, 0x84,
-	0xf2, 0x12, 0x23, 0x92, 0x91, 0xa8, 0xc9, 0xbf, 0x30, 0xee, 0x9b, 0x4e, 0xf7, 0x36, 0x2f, 0x71,
-	0x2b, 0xd7, 0x35, 0x6d, 0x7a, 0xec, 0x92, 0xda, 0xa1, 0xc9, 0x16, 0xf2, 0x70, 0x40, 0x41, 0xfe,
-	0xe0, 0x4c, 0xf6, 0xda, 0x32, 0x4d, 0xb6, 0xc3, 0x57, 0x23, 0x18, 0x6e, 0x8f, 0x15, 0x2e, 0x42,
-	0x98, 0x75, 0xb4, 0xf4, 0x7c, 0x18, 0x57, 0xfc, 0x58, 0x48, 0x9e, 0x98, 0x47, 0x4d, 0x59, 0x3b,
-	0x2e, 0x5e, 0x60, 0xde, 0x59, 0xcc, 0xbb, 0x83, 0x69, 0x5c, 0x48, 0xb1, 0x8f, 0x0e, 0x75, 0x19,
-	0xa3, 0x3d, 0xc6, 0x90, 0x4d, 0xcc, 0xee, 0xc3, 0xac, 0xfe, 0x87, 0xb9, 0x5a, 0x67, 0xc2, 0xd6,
-	0x70, 0xe5, 0x0c, 0xc4, 0x13, 0x4e, 0xdc, 0x5b, 0xc2, 0xbc, 0xe0, 0x9a, 0x22, 0x99, 0xa6, 0x1a,
-	0x29, 0xaa, 0x50, 0xe9, 0x5c, 0x13, 0x5a, 0xe3, 0x80, 0xcd, 0x1a, 0xf1, 0xd3, 0x68, 0x5f, 0xad,
-	0xb4, 0xfa, 0x86, 0x7b, 0xa9, 0xb2, 0x60, 0x77, 0xac, 0x50, 0x15, 0x98, 0x64, 0xa8, 0x82, 0x94,
-	0xc7, 0x2a, 0x17, 0xf6, 0x6b, 0x75, 0x7b, 0xb2, 0x9f, 0xc7, 0x2c, 0xa7, 0x5d, 0x1d, 0x07, 0x42,
-	0x96, 0xe1, 0x09, 0x1d, 0x5a, 0x3a, 0xb4, 0x74, 0xe8, 0xe8, 0x78, 0x64, 0xe6, 0xa7, 0xbf, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0x15, 0x78, 0x4d, 0xd8, 0x2f, 0x02, 0x00, 0x00,
+func init() { proto.RegisterFile("orderer/kafka.proto", fileDescriptor4) }
+
+var fileDescriptor4 = []byte{
+	// 491 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x93, 0xdf, 0x8a, 0xd3, 0x40,
+	0x14, 0xc6, 0x9b, 0xfe, 0x65, 0x4f, 0xbb, 0x6b, 0x9d, 0x5a, 0x08, 0xa2, 0x52, 0x23, 0x62, 0x2f,
+	0x96, 0x04, 0xea, 0xcd, 0xe2, 0x95, 0x6e, 0x41, 0x57, 0xd6, 0x4d, 0x65, 0x6c, 0x11, 0xf4, 0x22,
+	0x4c, 0x92, 0x69, 0x76, 0x68, 0x9a, 0xc9, 0xce, 0x4c, 0x2e, 0x7a, 0xef, 0x23, 0xf8, 0x58, 0x3e,
+	0x94, 0x64, 0x26, 0x81, 0x8a, 0x71, 0xbd, 0xcb, 0x7c, 0xe7, 0xf7, 0xcd, 0x39, 0xe7, 0x4b, 0x02,
+	0x13, 0x2e, 0x62, 0x2a, 0xa8, 0xf0, 0x76, 0x64, 0xbb, 0x23, 0x6e, 0x2e, 0xb8, 0xe2, 0x68, 0x50,
+	0x89, 0xce, 0x2f, 0x0b, 0x46, 0xd7, 0x65, 0xe1, 0x86, 0x4a, 0x49, 0x12, 0x8a, 0x2e, 0x60, 0x20,
+	0x68, 0x52, 0xa4, 0x44, 0xd8, 0xd6, 0xcc, 0x9a, 0x0f, 0x17, 0x4f, 0xdc, 0x8a, 0x75, 0x8f, 0x39,
+	0x6c, 0x98, 0xab, 0x16, 0xae, 0x71, 0xf4, 0x16, 0x86, 0x8a, 0xed, 0x69, 0xa0, 0x78, 0x10, 0x15,
+	0xca, 0x6e, 0x6b, 0xf7, 0xb3, 0x46, 0xf7, 0x9a, 0xed, 0xe9, 0x9a, 0x2f, 0x0b, 0x75, 0xd5, 0xc2,
+	0x27, 0xaa, 0x3e, 0x94, 0xbd, 0x23, 0x9e, 0x65, 0x34, 0x52, 0x76, 0xe7, 0x9e, 0xde, 0x4b, 0xc3,
+	0x94, 0xbd, 0x2b, 0xfc, 0xb2, 0x0f, 0xdd, 0xf5, 0x21, 0xa7, 0xce, 0x8f, 0x36, 0x4c, 0x1a, 0xc6,
+	0x44, 0x36, 0x0c, 0x72, 0x72, 0x48, 0x39, 0x89, 0xf5, 0x56, 0x23, 0x5c, 0x1f, 0xd1, 0x53, 0x80,
+	0x88, 0x67, 0x5b, 0x96, 0x04, 0x92, 0xde, 0xe9, 0xa1, 0xbb, 0xf8, 0xc4, 0x28, 0x5f, 0xe8, 0x1d,
+	0xba, 0x80, 0x5e, 0x94, 0x12, 0x29, 0xf5, 0x40, 0x67, 0x0b, 0xe7, 0xbe, 0x30, 0xdc, 0x65, 0x49,
+	0x62, 0x63, 0x40, 0x2f, 0xe0, 0xb4, 0xba, 0xb8, 0xc8, 0x63, 0xa2, 0xa8, 0xdd, 0xd5, 0x8d, 0x47,
+	0x46, 0xdc, 0x68, 0x0d, 0xbd, 0x82, 0x07, 0x5c, 0xb0, 0x84, 0x65, 0x24, 0x0d, 0xf8, 0x76, 0x2b,
+	0xa9, 0xb2, 0x7b, 0x33, 0x6b, 0xde, 0xc1, 0x67, 0xb5, 0xbc, 0xd2, 0xaa, 0x73, 0x0e, 0x3d, 0x7d,
+	0x3b, 0x1a, 0xc2, 0x60, 0xe3, 0x5f, 0xfb, 0xab, 0xaf, 0xfe, 0xb8, 0x85, 0x00, 0xfa, 0xfe, 0x0a,
+	0xdf, 0xbc, 0xfb, 0x34, 0xb6, 0xca, 0xe7, 0xe5, 0xca, 0x7f, 0xff, 0xf1, 0xc3, 0xb8, 0xed, 0xbc,
+	0x81, 0x69, 0x63, 0xdc, 0xe8, 0x39, 0x8c, 0xc2, 0x94, 0x47, 0xbb, 0x20, 0x2b, 0xf6, 0x21, 0x35,
+	0xaf, 0xb8, 0x8b, 0x87, 0x5a, 0xf3, 0xb5, 0xe4, 0x78, 0x30, 0x69, 0x08, 0xfb, 0xdf, 0x09, 0x3a,
+	0x3f, 0x2d, 0x38, 0xad, 0x1c, 0x8a, 0xc4, 0x44, 0x11, 0xb4, 0x80, 0x69, 0x4a, 0xa4, 0xaa, 0x36,
+	0x0a, 0x72, 0x2a, 0x24, 0x93, 0x8a, 0x1a, 0x67, 0x07, 0x4f, 0xca, 0xa2, 0xd9, 0xeb, 0x73, 0x5d,
+	0x42, 0x2b, 0x98, 0xe6, 0x34, 0x8b, 0x59, 0x96, 0x04, 0x82, 0xca, 0x22, 0xdc, 0x33, 0x29, 0x19,
+	0xcf, 0xa4, 0xdd, 0x9e, 0x75, 0xe6, 0xc3, 0xc5, 0xe3, 0x3f, 0x83, 0xc7, 0x47, 0x08, 0x7e, 0x54,
+	0x19, 0x8f, 0x45, 0xe9, 0x7c, 0x87, 0x87, 0x7f, 0xa1, 0x4d, 0x79, 0x5b, 0x4d, 0x79, 0xff, 0xe7,
+	0xb3, 0xb8, 0xdc, 0xc0, 0x4b, 0x2e, 0x12, 0xf7, 0xf6, 0x90, 0x53, 0x91, 0xd2, 0x38, 0xa1, 0xc2,
+	0xdd, 0x92, 0x50, 0xb0, 0xc8, 0xfc, 0x5f, 0xb2, 0x9e, 0xf6, 0xdb, 0x79, 0xc2, 0xd4, 0x6d, 0x11,
+	0xba, 0x11, 0xdf, 0x7b, 0x47, 0xb4, 0x67, 0x68, 0xcf, 0xd0, 0x5e, 0x45, 0x87, 0x7d, 0x7d, 0x7e,
+	0xfd, 0x7b, 0x00, 0x2e, 0xdb, 0xb9, 0xec, 0xb4, 0x03, 0x00, 0x00,
 }